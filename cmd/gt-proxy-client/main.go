@@ -2,6 +2,10 @@
 // When GT_PROXY_URL, GT_PROXY_CERT, GT_PROXY_KEY, and GT_PROXY_CA are all set, it forwards
 // os.Args[1:] to the proxy server over mTLS and proxies the response.
 // Otherwise it execs the real binary at /usr/local/bin/gt.real (or the path in GT_REAL_BIN).
+//
+// When the client cert has less than a third of its lifetime left, the client
+// renews it through the proxy and writes the new cert and key over
+// GT_PROXY_CERT and GT_PROXY_KEY before forwarding the command.
 package main
 
 import (
@@ -28,6 +32,24 @@ type execResponse struct {
 	ExitCode int    `json:"exitCode"`
 }
 
+// deniedResponse is the body of a 403 from the server's exec policy.
+type deniedResponse struct {
+	Error  string `json:"error"`
+	Rule   string `json:"rule"`
+	Reason string `json:"reason"`
+}
+
+func (d deniedResponse) message() string {
+	msg := d.Error
+	if d.Rule != "" {
+		msg += fmt.Sprintf(" (rule %q)", d.Rule)
+	}
+	if d.Reason != "" {
+		msg += ": " + d.Reason
+	}
+	return msg
+}
+
 func main() {
 	// Required environment variables:
 	//   GT_PROXY_URL  — proxy base URL (e.g. https://172.17.0.1:9876)
@@ -36,6 +58,7 @@ func main() {
 	//   GT_PROXY_CA   — path to PEM proxy CA cert (used to verify server cert)
 	// Optional:
 	//   GT_REAL_BIN   — fallback binary path (default /usr/local/bin/gt.real)
+	//   GT_PROXY_NO_RENEW — if set, never renew the client cert
 	proxyURL := os.Getenv("GT_PROXY_URL")
 	certFile := os.Getenv("GT_PROXY_CERT")
 	keyFile := os.Getenv("GT_PROXY_KEY")
@@ -50,13 +73,6 @@ func main() {
 		return
 	}
 
-	// Build mTLS client.
-	clientCert, err := tls.LoadX509KeyPair(certFile, keyFile)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "gt-proxy-client: load client cert: %v\n", err)
-		os.Exit(1)
-	}
-
 	caPEM, err := os.ReadFile(caFile) //nolint:gosec // caFile is from trusted env var GT_PROXY_CA
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "gt-proxy-client: read CA: %v\n", err)
@@ -68,6 +84,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Build mTLS client, renewing the client cert first if it is close to expiry.
+	clientCert, leaf, err := loadClientCert(certFile, keyFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gt-proxy-client: load client cert: %v\n", err)
+		os.Exit(1)
+	}
+	clientCert = maybeRenew(proxyURL, certFile, keyFile, pool, clientCert, leaf)
+
 	tlsCfg := &tls.Config{
 		Certificates: []tls.Certificate{clientCert},
 		RootCAs:      pool,
@@ -99,6 +123,11 @@ func main() {
 
 	if resp.StatusCode != http.StatusOK {
 		msg, _ := io.ReadAll(resp.Body)
+		var denied deniedResponse
+		if resp.StatusCode == http.StatusForbidden && json.Unmarshal(msg, &denied) == nil && denied.Error != "" {
+			fmt.Fprintf(os.Stderr, "gt-proxy-client: %s\n", denied.message())
+			os.Exit(1)
+		}
 		fmt.Fprintf(os.Stderr, "gt-proxy-client: server error %d: %s\n", resp.StatusCode, msg)
 		os.Exit(1)
 	}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+// renewResponse is the subset of the /v1/cert/renew response the client uses.
+type renewResponse struct {
+	Cert      string `json:"cert"`
+	Key       string `json:"key"`
+	Serial    string `json:"serial"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// needsRenewal reports whether leaf has less than a third of its lifetime
+// left. Renewing that early leaves time to retry on later invocations if the
+// proxy is briefly unreachable.
+func needsRenewal(leaf *x509.Certificate, now time.Time) bool {
+	lifetime := leaf.NotAfter.Sub(leaf.NotBefore)
+	return leaf.NotAfter.Sub(now) < lifetime/3
+}
+
+// lockCertFiles takes a file lock next to certFile: shared for loading the
+// cert/key pair, exclusive for replacing it, so that concurrent invocations
+// never load a cert with the other one's key. If the lock file cannot be
+// created (e.g. a read-only mount, where renewal is impossible anyway) it
+// returns a no-op unlock.
+func lockCertFiles(certFile string, exclusive bool) (unlock func()) {
+	lock := flock.New(certFile + ".lock")
+	var err error
+	if exclusive {
+		err = lock.Lock()
+	} else {
+		err = lock.RLock()
+	}
+	if err != nil {
+		return func() {}
+	}
+	return func() { _ = lock.Unlock() }
+}
+
+// loadClientCert loads the client cert/key pair and parses its leaf.
+func loadClientCert(certFile, keyFile string) (tls.Certificate, *x509.Certificate, error) {
+	unlock := lockCertFiles(certFile, false)
+	defer unlock()
+	return loadKeyPair(certFile, keyFile)
+}
+
+func loadKeyPair(certFile, keyFile string) (tls.Certificate, *x509.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	return cert, leaf, nil
+}
+
+// maybeRenew renews the client cert through the proxy if it is close to
+// expiry, writes the new pair over certFile and keyFile, and returns it.
+// Renewal failures are reported on stderr and the current cert is returned:
+// it is still valid, and a later invocation will try again.
+func maybeRenew(proxyURL, certFile, keyFile string, pool *x509.CertPool, cert tls.Certificate, leaf *x509.Certificate) tls.Certificate {
+	if os.Getenv("GT_PROXY_NO_RENEW") != "" || !needsRenewal(leaf, time.Now()) {
+		return cert
+	}
+
+	unlock := lockCertFiles(certFile, true)
+	defer unlock()
+
+	// Another invocation may have renewed while we waited for the lock.
+	if fresh, freshLeaf, err := loadKeyPair(certFile, keyFile); err == nil && !needsRenewal(freshLeaf, time.Now()) {
+		return fresh
+	}
+
+	renewed, err := renewCert(proxyURL, pool, cert)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gt-proxy-client: cert renewal failed (cert expires %s): %v\n",
+			leaf.NotAfter.UTC().Format(time.RFC3339), err)
+		return cert
+	}
+	newCert, err := tls.X509KeyPair([]byte(renewed.Cert), []byte(renewed.Key))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gt-proxy-client: renewed cert is invalid: %v\n", err)
+		return cert
+	}
+	if err := writeCertPair(certFile, keyFile, []byte(renewed.Cert), []byte(renewed.Key)); err != nil {
+		// Use the new cert for this invocation; the next one renews again.
+		fmt.Fprintf(os.Stderr, "gt-proxy-client: save renewed cert: %v\n", err)
+	}
+	return newCert
+}
+
+// renewCert asks the proxy for a new cert, authenticating with the current one.
+func renewCert(proxyURL string, pool *x509.CertPool, cert tls.Certificate) (*renewResponse, error) {
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			RootCAs:      pool,
+		}},
+	}
+	resp, err := client.Post(proxyURL+"/v1/cert/renew", "application/json", nil) //nolint:gosec // proxyURL is from trusted env var GT_PROXY_URL
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck // best-effort close on response body
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return nil, fmt.Errorf("server error %d: %s", resp.StatusCode, msg)
+	}
+	var result renewResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return &result, nil
+}
+
+// writeCertPair replaces certFile and keyFile. Each file is written to a
+// *.tmp sibling and renamed into place, so a crash never leaves a truncated
+// file; callers hold the exclusive lock so readers never see a mixed pair.
+func writeCertPair(certFile, keyFile string, certPEM, keyPEM []byte) error {
+	for _, f := range []struct {
+		path string
+		data []byte
+		mode os.FileMode
+	}{
+		{keyFile, keyPEM, 0600},
+		{certFile, certPEM, 0644},
+	} {
+		tmp := filepath.Join(filepath.Dir(f.path), "."+filepath.Base(f.path)+".tmp")
+		if err := os.WriteFile(tmp, f.data, f.mode); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, f.path); err != nil {
+			_ = os.Remove(tmp)
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNeedsRenewal(t *testing.T) {
+	issued := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	leaf := &x509.Certificate{NotBefore: issued, NotAfter: issued.Add(24 * time.Hour)}
+
+	assert.False(t, needsRenewal(leaf, issued.Add(time.Hour)), "fresh cert")
+	assert.False(t, needsRenewal(leaf, issued.Add(15*time.Hour)), "9h of 24h left")
+	assert.True(t, needsRenewal(leaf, issued.Add(17*time.Hour)), "7h of 24h left")
+	assert.True(t, needsRenewal(leaf, issued.Add(25*time.Hour)), "expired")
+}
+
+func TestWriteCertPair(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "polecat.crt")
+	keyFile := filepath.Join(dir, "polecat.key")
+	require.NoError(t, os.WriteFile(certFile, []byte("old cert"), 0644))
+	require.NoError(t, os.WriteFile(keyFile, []byte("old key"), 0600))
+
+	require.NoError(t, writeCertPair(certFile, keyFile, []byte("new cert"), []byte("new key")))
+
+	got, err := os.ReadFile(certFile)
+	require.NoError(t, err)
+	assert.Equal(t, "new cert", string(got))
+	got, err = os.ReadFile(keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, "new key", string(got))
+
+	info, err := os.Stat(keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "no temp files left behind")
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/proxy"
+)
+
+const (
+	// certStoreFile is the cert store's file name within the CA directory.
+	certStoreFile = "certs.json"
+
+	// defaultCertTTL is the default validity of issued polecat certs.
+	// gt-proxy-client renews certs well before they expire, so they can be
+	// short-lived without interrupting polecats.
+	defaultCertTTL = 24 * time.Hour
+)
+
+// runCerts implements "gt-proxy-server certs": it lists the polecat certs
+// recorded in the cert store with serial, CN, expiry and revocation status.
+// It reads the store file directly, so it works whether or not the server is
+// running; GET /v1/admin/certs returns the same records from a live server.
+func runCerts(args []string) int {
+	fs := flag.NewFlagSet("certs", flag.ContinueOnError)
+	configFile := fs.String("config", "", "path to config file (default: ~/gt/.runtime/proxy/config.json)")
+	caDir := fs.String("ca-dir", "", "directory for CA cert/key (default: ~/gt/.runtime/ca)")
+	asJSON := fs.Bool("json", false, "output as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	dir, err := resolveCADir(*configFile, *caDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gt-proxy-server certs: %v\n", err)
+		return 1
+	}
+	store, err := proxy.OpenCertStore(filepath.Join(dir, certStoreFile))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gt-proxy-server certs: %v\n", err)
+		return 1
+	}
+
+	records := store.List()
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(records); err != nil {
+			fmt.Fprintf(os.Stderr, "gt-proxy-server certs: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	printCerts(os.Stdout, records, time.Now())
+	return 0
+}
+
+// resolveCADir returns the CA directory the server would use: the --ca-dir
+// flag, else ca_dir from the config file, else ~/gt/.runtime/ca.
+func resolveCADir(configFile, caDir string) (string, error) {
+	if caDir != "" {
+		return caDir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home dir: %w", err)
+	}
+	if configFile == "" {
+		configFile = filepath.Join(home, "gt", ".runtime", "proxy", "config.json")
+	}
+	cfg, err := loadConfig(configFile)
+	if err != nil {
+		return "", fmt.Errorf("load config %s: %w", configFile, err)
+	}
+	if cfg.CADir != "" {
+		return cfg.CADir, nil
+	}
+	return filepath.Join(home, "gt", ".runtime", "ca"), nil
+}
+
+// printCerts writes records as a table, one cert per line.
+func printCerts(w io.Writer, records []proxy.CertRecord, now time.Time) {
+	if len(records) == 0 {
+		fmt.Fprintln(w, "No certificates recorded.")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SERIAL\tCN\tEXPIRES\tSTATUS")
+	for _, rec := range records {
+		cn, expires := rec.CN, "-"
+		if cn == "" {
+			cn = "-"
+		}
+		if !rec.NotAfter.IsZero() {
+			expires = rec.NotAfter.UTC().Format(time.RFC3339)
+		}
+		status := rec.Status(now)
+		if rec.RevokedAt != nil {
+			status += " " + rec.RevokedAt.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", rec.Serial, cn, expires, status)
+	}
+	_ = tw.Flush()
+}
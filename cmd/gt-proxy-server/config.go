@@ -56,6 +56,16 @@ type ProxyConfig struct {
 	//   - A split-horizon DNS entry that resolves to the proxy IP
 	//   - A mDNS name (e.g. "macbook.local")
 	ExtraSANHosts []string `json:"extra_san_hosts"`
+
+	// CertTTL is the validity of polecat certs issued via the admin API when
+	// the request gives no TTL, as a Go duration (e.g. "24h"). It also caps
+	// the validity of certs renewed by gt-proxy-client. Defaults to 24h.
+	CertTTL string `json:"cert_ttl"`
+
+	// PolicyFile is the argument-level exec policy (JSON).
+	// Defaults to ~/gt/.runtime/proxy/policy.json; no policy is applied if
+	// the file does not exist.
+	PolicyFile string `json:"policy_file"`
 }
 
 // loadConfig reads the config file at path and returns a ProxyConfig.
@@ -1,6 +1,9 @@
 // gt-proxy-server is the mTLS proxy server for sandboxed polecat execution.
 // It runs on the host and allows containers to call gt/bd and access git repos
 // via authenticated, authorized HTTP endpoints.
+//
+// "gt-proxy-server certs" lists the polecat certs the server has issued, and
+// "gt-proxy-server policy test" evaluates the exec policy offline.
 package main
 
 import (
@@ -14,6 +17,7 @@ import (
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/steveyegge/gastown/internal/proxy"
 )
@@ -25,6 +29,15 @@ const defaultAllowedSubcmds = "" +
 	"bd:create,update,close,show,list,ready,dep,export,prime,stats,blocked,doctor"
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "certs":
+			os.Exit(runCerts(os.Args[2:]))
+		case "policy":
+			os.Exit(runPolicy(os.Args[2:]))
+		}
+	}
+
 	var (
 		configFile     = flag.String("config", "", "path to config file (default: ~/gt/.runtime/proxy/config.json)")
 		listen         = flag.String("listen", "0.0.0.0:9876", "address to listen on")
@@ -34,6 +47,8 @@ func main() {
 		allowedSubcmds = flag.String("allowed-subcmds", discoverAllowedSubcmds(),
 			`semicolon-separated list of "cmd:sub1,sub2,..." subcommand allowlists`)
 		townRoot = flag.String("town-root", "", "Gas Town root directory (default: $GT_TOWN or ~/gt)")
+		certTTL  = flag.Duration("cert-ttl", defaultCertTTL, "default validity of issued polecat certs; also caps renewed certs")
+		policy   = flag.String("policy", "", "exec policy file (default: ~/gt/.runtime/proxy/policy.json if present)")
 	)
 	flag.Parse()
 
@@ -77,6 +92,17 @@ func main() {
 	if !explicitFlags["allowed-subcmds"] && len(fileCfg.AllowedSubcommands) > 0 {
 		*allowedSubcmds = buildAllowedSubcmds(fileCfg.AllowedSubcommands)
 	}
+	if !explicitFlags["policy"] && fileCfg.PolicyFile != "" {
+		*policy = fileCfg.PolicyFile
+	}
+	if !explicitFlags["cert-ttl"] && fileCfg.CertTTL != "" {
+		d, err := time.ParseDuration(fileCfg.CertTTL)
+		if err != nil || d <= 0 {
+			slog.Error("invalid cert_ttl in config file", "value", fileCfg.CertTTL)
+			os.Exit(1)
+		}
+		*certTTL = d
+	}
 
 	if *caDir == "" {
 		*caDir = filepath.Join(home, "gt", ".runtime", "ca")
@@ -126,6 +152,20 @@ func main() {
 		}
 	}
 
+	// An explicitly configured policy file must exist; the default one is optional.
+	policyPath, policyRequired := *policy, *policy != ""
+	if policyPath == "" {
+		policyPath = filepath.Join(home, "gt", ".runtime", "proxy", "policy.json")
+	}
+	execPolicy, err := loadPolicyFile(policyPath, policyRequired)
+	if err != nil {
+		slog.Error("failed to load policy", "path", policyPath, "err", err)
+		os.Exit(1)
+	}
+	if execPolicy != nil {
+		slog.Info("exec policy loaded", "path", policyPath, "rules", len(execPolicy.Rules))
+	}
+
 	cfg := proxy.Config{
 		ListenAddr:         *listen,
 		AdminListenAddr:    *adminListen,
@@ -134,6 +174,10 @@ func main() {
 		TownRoot:           *townRoot,
 		ExtraSANIPs:        extraSANIPs,
 		ExtraSANHosts:      extraSANHosts,
+		CertStorePath:      filepath.Join(*caDir, certStoreFile),
+		PolecatCertTTL:     *certTTL,
+		Policy:             execPolicy,
+		RigPrefix:          rigPrefixFunc(*townRoot),
 	}
 
 	srv, err := proxy.New(cfg, ca)
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/proxy"
+)
+
+// loadPolicyFile loads the exec policy at path. A missing file is an error
+// only if required; otherwise it means no policy.
+func loadPolicyFile(path string, required bool) (*proxy.Policy, error) {
+	p, err := proxy.LoadPolicy(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return nil, nil
+	}
+	return p, err
+}
+
+// rigPrefixFunc resolves ${prefix} in policy patterns from the town's
+// rigs.json, so rules can refer to "beads in my rig".
+func rigPrefixFunc(townRoot string) func(rig string) string {
+	return func(rig string) string {
+		return config.GetRigPrefix(townRoot, rig)
+	}
+}
+
+// runPolicy implements "gt-proxy-server policy test":
+//
+//	gt-proxy-server policy test [flags]                          # run the policy's tests
+//	gt-proxy-server policy test [flags] --as <rig>/<name> <argv...>  # evaluate one command
+//
+// It exits 0 if every test passes (or the command is allowed), 1 if a test
+// fails (or the command is denied), and 2 on usage or load errors.
+func runPolicy(args []string) int {
+	if len(args) == 0 || args[0] != "test" {
+		fmt.Fprintln(os.Stderr, "usage: gt-proxy-server policy test [--policy FILE] [--as RIG/NAME] [ARGV...]")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("policy test", flag.ContinueOnError)
+	configFile := fs.String("config", "", "path to config file (default: ~/gt/.runtime/proxy/config.json)")
+	policyFile := fs.String("policy", "", "policy file (default: policy_file from config, or ~/gt/.runtime/proxy/policy.json)")
+	townRoot := fs.String("town-root", "", "Gas Town root directory, for ${prefix} (default: $GT_TOWN or ~/gt)")
+	as := fs.String("as", "", "caller identity <rig>/<name> when evaluating a command")
+	asJSON := fs.Bool("json", false, "output the decision as JSON")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gt-proxy-server policy: cannot determine home dir: %v\n", err)
+		return 2
+	}
+	cfgPath := *configFile
+	if cfgPath == "" {
+		cfgPath = filepath.Join(home, "gt", ".runtime", "proxy", "config.json")
+	}
+	fileCfg, err := loadConfig(cfgPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gt-proxy-server policy: load config %s: %v\n", cfgPath, err)
+		return 2
+	}
+	path := *policyFile
+	if path == "" {
+		path = fileCfg.PolicyFile
+	}
+	if path == "" {
+		path = filepath.Join(home, "gt", ".runtime", "proxy", "policy.json")
+	}
+	root := *townRoot
+	if root == "" {
+		root = fileCfg.TownRoot
+	}
+	if root == "" {
+		root = os.Getenv("GT_TOWN")
+	}
+	if root == "" {
+		root = filepath.Join(home, "gt")
+	}
+
+	p, err := loadPolicyFile(path, true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gt-proxy-server policy: %v\n", err)
+		return 2
+	}
+	prefix := rigPrefixFunc(root)
+
+	if fs.NArg() == 0 {
+		return runPolicyTests(os.Stdout, p, prefix)
+	}
+
+	if *as != "" && !strings.Contains(*as, "/") {
+		fmt.Fprintf(os.Stderr, "gt-proxy-server policy: --as must be <rig>/<name>, got %q\n", *as)
+		return 2
+	}
+	d := p.Evaluate(policyRequest(*as, fs.Args(), prefix))
+	if *asJSON {
+		_ = json.NewEncoder(os.Stdout).Encode(d)
+	} else {
+		printDecision(os.Stdout, d)
+	}
+	if d.Effect == proxy.EffectDeny {
+		return 1
+	}
+	return 0
+}
+
+func policyRequest(identity string, argv []string, prefix func(string) string) proxy.PolicyRequest {
+	req := proxy.PolicyRequest{Identity: identity, Argv: argv}
+	if rig, _, ok := strings.Cut(identity, "/"); ok {
+		req.Prefix = prefix(rig)
+	}
+	return req
+}
+
+// runPolicyTests evaluates the policy's embedded tests and reports each one.
+func runPolicyTests(w io.Writer, p *proxy.Policy, prefix func(string) string) int {
+	if len(p.Tests) == 0 {
+		fmt.Fprintln(w, "Policy is valid; it has no tests. Pass --as <rig>/<name> and a command to evaluate one.")
+		return 0
+	}
+	failed := 0
+	for i, tc := range p.Tests {
+		d := p.Evaluate(policyRequest(tc.As, tc.Argv, prefix))
+		status := "PASS"
+		if d.Effect != tc.Expect {
+			status = "FAIL"
+			failed++
+		}
+		fmt.Fprintf(w, "%s  %d. %s %s → %s", status, i+1, tc.As, strings.Join(tc.Argv, " "), d.Effect)
+		if d.Rule != "" {
+			fmt.Fprintf(w, " (%s)", d.Rule)
+		}
+		if d.Effect != tc.Expect {
+			fmt.Fprintf(w, ", expected %s", tc.Expect)
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "\n%d passed, %d failed\n", len(p.Tests)-failed, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func printDecision(w io.Writer, d proxy.Decision) {
+	rule := d.Rule
+	if rule == "" {
+		rule = "default"
+	}
+	fmt.Fprintf(w, "%s (rule: %s)\n", d.Effect, rule)
+	if d.Reason != "" {
+		fmt.Fprintf(w, "reason: %s\n", d.Reason)
+	}
+	if len(d.Audit) > 0 {
+		fmt.Fprintf(w, "audited by: %s\n", strings.Join(d.Audit, ", "))
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/proxy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPolicyTests(t *testing.T) {
+	p := &proxy.Policy{
+		Rules: []proxy.PolicyRule{{
+			Name:   "own-beads",
+			Effect: proxy.EffectDeny,
+			Argv:   []string{"bd", "close", "!${prefix}-*"},
+		}},
+		Tests: []proxy.PolicyTest{
+			{As: "gastown/toast", Argv: []string{"bd", "close", "gt-1"}, Expect: proxy.EffectAllow},
+			{As: "gastown/toast", Argv: []string{"bd", "close", "hq-1"}, Expect: proxy.EffectDeny},
+		},
+	}
+	require.NoError(t, p.Validate())
+	prefix := func(rig string) string { return "gt" }
+
+	var out bytes.Buffer
+	assert.Equal(t, 0, runPolicyTests(&out, p, prefix))
+	assert.Contains(t, out.String(), "2 passed, 0 failed")
+	assert.Contains(t, out.String(), "deny (own-beads)")
+
+	p.Tests[0].Expect = proxy.EffectDeny
+	out.Reset()
+	assert.Equal(t, 1, runPolicyTests(&out, p, prefix))
+	assert.Contains(t, out.String(), "FAIL  1.")
+	assert.Contains(t, out.String(), "expected deny")
+}
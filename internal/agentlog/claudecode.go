@@ -19,7 +19,7 @@ const (
 	// watchPollInterval is how often we poll for new JSONL content or files.
 	watchPollInterval = 500 * time.Millisecond
 
-	// watchFileTimeout is how long we wait for a JSONL file to appear after startup.
+	// watchFileTimeout is how long we wait for a log file to appear after startup.
 	watchFileTimeout = 30 * time.Second
 )
 
@@ -55,43 +55,56 @@ func (a *ClaudeCodeAdapter) Watch(ctx context.Context, sessionID, workDir string
 		return nil, fmt.Errorf("resolving project dir: %w", err)
 	}
 
+	find := func(since time.Time) (string, bool) { return newestJSONLIn(projectDir, since) }
 	ch := make(chan AgentEvent, 64)
 	go func() {
 		defer close(ch)
+		followLogs(ctx, since, find, func(path string, since time.Time) {
+			nativeID := nativeSessionIDFromPath(path)
+			tailJSONL(ctx, path, func() (string, bool) { return find(since) }, func(line string) []AgentEvent {
+				return parseClaudeCodeLine(line, sessionID, a.AgentType(), nativeID)
+			}, ch)
+		})
+	}()
+	return ch, nil
+}
+
+// logFinder returns the newest qualifying log file modified at or after since
+// (any file when since is zero).
+type logFinder func(since time.Time) (string, bool)
 
-		// Loop indefinitely: find the active JSONL file, tail it, then switch when a newer
-		// one appears (new Claude session). ctx cancellation is the only exit.
-		// Timeouts from waitForNewestJSONL are retried so that agent restarts or late
-		// session starts (JSONL file appearing after the 30s window) are picked up.
-		var currentPath string
-		for {
+// followLogs runs the find-then-tail loop shared by the file-based adapters.
+// tail is called for the active log file and must return when a newer file
+// appears or ctx is done; followLogs then switches to the newer file.
+// ctx cancellation is the only exit.
+//
+// Timeouts from waitForNewest are retried so that agent restarts or late
+// session starts (a log file appearing after the 30s window) are picked up.
+func followLogs(ctx context.Context, since time.Time, find logFinder, tail func(path string, since time.Time)) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		path, err := waitForNewest(ctx, find, since)
+		if err != nil {
+			// ctx was canceled — clean exit.
 			if ctx.Err() != nil {
 				return
 			}
-			jsonlPath, err := waitForNewestJSONL(ctx, projectDir, since)
-			if err != nil {
-				// ctx was canceled — clean exit.
-				if ctx.Err() != nil {
-					return
-				}
-				// Timeout: no JSONL appeared in 30s. Claude may not have started yet
-				// or the agent restarted. Reset `since` so we pick up any new file.
-				since = time.Now().Add(-watchPollInterval)
-				continue
-			}
-
-			currentPath = jsonlPath
+			// Timeout: no log appeared in 30s. The agent may not have started
+			// yet or it restarted. Reset `since` so we pick up any new file.
+			since = time.Now().Add(-watchPollInterval)
+			continue
+		}
 
-			// Tail the file; returns when a newer file appears or ctx is done.
-			tailJSONL(ctx, currentPath, projectDir, since, sessionID, a.AgentType(), ch)
+		// Tail the file; returns when a newer file appears or ctx is done.
+		tail(path, since)
 
-			if ctx.Err() != nil {
-				return
-			}
-			// A newer file was detected — loop immediately to pick it up.
+		if ctx.Err() != nil {
+			return
 		}
-	}()
-	return ch, nil
+		// A newer file was detected — loop immediately to pick it up.
+	}
 }
 
 // claudeProjectDirFor returns the Claude Code project directory for workDir.
@@ -119,17 +132,16 @@ func claudeProjectDirFor(workDir string) (string, error) {
 	return filepath.Join(home, claudeProjectsDir, hash), nil
 }
 
-// waitForNewestJSONL polls projectDir until a qualifying .jsonl file appears.
-// "Qualifying" means mod time >= since (or any file if since is zero).
-// Returns the path of the most recently modified qualifying file.
-func waitForNewestJSONL(ctx context.Context, projectDir string, since time.Time) (string, error) {
+// waitForNewest polls find until a qualifying log file appears and returns
+// its path, giving up after watchFileTimeout.
+func waitForNewest(ctx context.Context, find logFinder, since time.Time) (string, error) {
 	deadline := time.Now().Add(watchFileTimeout)
 	for {
-		if path, ok := newestJSONLIn(projectDir, since); ok {
+		if path, ok := find(since); ok {
 			return path, nil
 		}
 		if time.Now().After(deadline) {
-			return "", fmt.Errorf("timeout: no JSONL file appeared in %s within %s", projectDir, watchFileTimeout)
+			return "", fmt.Errorf("timeout: no log file appeared within %s", watchFileTimeout)
 		}
 		select {
 		case <-ctx.Done():
@@ -176,17 +188,16 @@ func nativeSessionIDFromPath(path string) string {
 	return strings.TrimSuffix(base, ".jsonl")
 }
 
-// tailJSONL reads all existing lines in path then polls for new ones, emitting
-// AgentEvents on ch. It returns (without closing ch) when:
-//   - a newer JSONL file appears in projectDir (new Claude session detected), or
+// tailJSONL reads all existing lines in path then polls for new ones, passing
+// each complete line to parse and emitting the resulting AgentEvents on ch.
+// It returns (without closing ch) when:
+//   - newest reports a different file (new agent session detected), or
 //   - ctx is canceled.
 //
-// Callers loop back to waitForNewestJSONL after this returns to pick up the
-// new session file. This handles Claude instances that are created and destroyed
+// Callers loop back to waitForNewest after this returns to pick up the
+// new session file. This handles agent instances that are created and destroyed
 // frequently: no events are lost because the file is tailed until we switch.
-func tailJSONL(ctx context.Context, path, projectDir string, since time.Time, sessionID, agentType string, ch chan<- AgentEvent) {
-	nativeID := nativeSessionIDFromPath(path)
-
+func tailJSONL(ctx context.Context, path string, newest func() (string, bool), parse func(line string) []AgentEvent, ch chan<- AgentEvent) {
 	f, err := os.Open(path)
 	if err != nil {
 		return
@@ -211,7 +222,7 @@ func tailJSONL(ctx context.Context, path, projectDir string, since time.Time, se
 			fullLine := strings.TrimRight(partial.String(), "\r\n")
 			partial.Reset()
 			if fullLine != "" {
-				for _, ev := range parseClaudeCodeLine(fullLine, sessionID, agentType, nativeID) {
+				for _, ev := range parse(fullLine) {
 					select {
 					case ch <- ev:
 					case <-ctx.Done():
@@ -222,9 +233,9 @@ func tailJSONL(ctx context.Context, path, projectDir string, since time.Time, se
 		}
 		if err == io.EOF {
 			// At EOF: check every poll whether a newer file has appeared.
-			// This detects new Claude sessions within one poll interval (500ms).
-			if newer, ok := newestJSONLIn(projectDir, since); ok && newer != path {
-				return // newer Claude session detected — caller switches
+			// This detects new agent sessions within one poll interval (500ms).
+			if newer, ok := newest(); ok && newer != path {
+				return // newer session detected — caller switches
 			}
 			select {
 			case <-ctx.Done():
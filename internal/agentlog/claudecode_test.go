@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
 )
 
 func TestClaudeProjectDirFor(t *testing.T) {
@@ -95,6 +97,9 @@ func TestNewAdapter(t *testing.T) {
 		{"claudecode", "claudecode", false, "claudecode"},
 		{"empty defaults to claudecode", "", false, "claudecode"},
 		{"opencode", "opencode", false, "opencode"},
+		{"codex", "codex", false, "codex"},
+		{"gemini", "gemini", false, "gemini"},
+		{"copilot", "copilot", false, "copilot"},
 		{"unknown", "kiro", true, ""},
 	}
 	for _, tt := range tests {
@@ -115,3 +120,12 @@ func TestNewAdapter(t *testing.T) {
 		})
 	}
 }
+
+func TestPresetLogFormatsHaveAdapters(t *testing.T) {
+	for _, name := range config.ListAgentPresets() {
+		format := config.GetLogFormat(name)
+		if format != "" && NewAdapter(format) == nil {
+			t.Errorf("preset %q declares log format %q with no adapter", name, format)
+		}
+	}
+}
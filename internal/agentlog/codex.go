@@ -0,0 +1,287 @@
+package agentlog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// codexSessionsDir is the path under $CODEX_HOME (default ~/.codex) where
+// Codex CLI writes rollout files.
+const codexSessionsDir = "sessions"
+
+// CodexAdapter watches Codex CLI rollout files.
+//
+// Codex writes one JSONL rollout per session at:
+//
+//	$CODEX_HOME/sessions/YYYY/MM/DD/rollout-<timestamp>-<session-uuid>.jsonl
+//
+// Rollouts from every working directory share this tree, so the adapter reads
+// the session_meta header on each file's first line and only follows sessions
+// whose cwd is workDir.
+type CodexAdapter struct{}
+
+func (a *CodexAdapter) AgentType() string { return "codex" }
+
+// Watch starts tailing the newest Codex rollout for workDir modified at or
+// after since, switching to a newer rollout when Codex restarts.
+func (a *CodexAdapter) Watch(ctx context.Context, sessionID, workDir string, since time.Time) (<-chan AgentEvent, error) {
+	root, err := codexSessionsRoot()
+	if err != nil {
+		return nil, err
+	}
+	absWorkDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving absolute path: %w", err)
+	}
+
+	headers := newHeaderCache(parseCodexHeader)
+	find := func(since time.Time) (string, bool) {
+		return newestOf(codexRollouts(root, since), since, func(path string) bool {
+			return headers.inWorkDir(path, absWorkDir)
+		})
+	}
+	ch := make(chan AgentEvent, 64)
+	go func() {
+		defer close(ch)
+		followLogs(ctx, since, find, func(path string, since time.Time) {
+			nativeID := codexSessionIDFromPath(path)
+			if h, ok := headers.get(path); ok && h.NativeID != "" {
+				nativeID = h.NativeID
+			}
+			tailJSONL(ctx, path, func() (string, bool) { return find(since) }, func(line string) []AgentEvent {
+				return parseCodexLine(line, sessionID, a.AgentType(), nativeID)
+			}, ch)
+		})
+	}()
+	return ch, nil
+}
+
+// codexSessionsRoot returns $CODEX_HOME/sessions, defaulting to ~/.codex.
+func codexSessionsRoot() (string, error) {
+	if codexHome := os.Getenv("CODEX_HOME"); codexHome != "" {
+		return filepath.Join(codexHome, codexSessionsDir), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home dir: %w", err)
+	}
+	return filepath.Join(home, ".codex", codexSessionsDir), nil
+}
+
+// codexRollouts lists rollout files under root. With a non-zero since only the
+// day directories from the day before since through tomorrow are listed, so a
+// long rollout history is not walked on every poll; the extra day on each side
+// absorbs local-time vs UTC differences in Codex's directory naming.
+func codexRollouts(root string, since time.Time) []string {
+	now := time.Now()
+	if since.IsZero() || now.Sub(since) > 7*24*time.Hour {
+		matches, _ := filepath.Glob(filepath.Join(root, "*", "*", "*", "rollout-*.jsonl"))
+		return matches
+	}
+	var paths []string
+	end := now.AddDate(0, 0, 1)
+	for day := since.AddDate(0, 0, -1); !day.After(end); day = day.AddDate(0, 0, 1) {
+		dir := filepath.Join(root, day.Format("2006"), day.Format("01"), day.Format("02"))
+		matches, _ := filepath.Glob(filepath.Join(dir, "rollout-*.jsonl"))
+		paths = append(paths, matches...)
+	}
+	return paths
+}
+
+// codexSessionIDFromPath extracts the session UUID from a rollout filename
+// (rollout-2026-02-23T10-00-00-<uuid>.jsonl). Used when the header has no id.
+func codexSessionIDFromPath(path string) string {
+	base := strings.TrimSuffix(filepath.Base(path), ".jsonl")
+	const uuidLen = 36
+	if len(base) > uuidLen {
+		return base[len(base)-uuidLen:]
+	}
+	return base
+}
+
+// ── Codex rollout structures ──────────────────────────────────────────────────
+
+// codexLine is a top-level line in a Codex rollout file.
+type codexLine struct {
+	Timestamp string          `json:"timestamp,omitempty"`
+	Type      string          `json:"type"` // session_meta, response_item, event_msg, turn_context
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// codexPayload covers the payload fields used from session_meta,
+// response_item and event_msg lines.
+type codexPayload struct {
+	Type string `json:"type"`
+
+	// session_meta
+	ID  string `json:"id,omitempty"`
+	Cwd string `json:"cwd,omitempty"`
+
+	// response_item: message
+	Role    string         `json:"role,omitempty"`
+	Content []codexContent `json:"content,omitempty"`
+
+	// response_item: reasoning
+	Summary []codexContent `json:"summary,omitempty"`
+
+	// response_item: function_call / custom_tool_call
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+	Input     string `json:"input,omitempty"`
+
+	// response_item: function_call_output / custom_tool_call_output
+	Output json.RawMessage `json:"output,omitempty"`
+
+	// event_msg: token_count
+	Info *codexTokenInfo `json:"info,omitempty"`
+}
+
+// codexContent is one content or summary part.
+type codexContent struct {
+	Type string `json:"type"` // input_text, output_text, summary_text
+	Text string `json:"text,omitempty"`
+}
+
+// codexTokenInfo is the info field of a token_count event.
+type codexTokenInfo struct {
+	LastTokenUsage *codexUsage `json:"last_token_usage,omitempty"`
+}
+
+// codexUsage holds OpenAI token counts for one model response. InputTokens
+// includes CachedInputTokens and OutputTokens includes reasoning tokens.
+type codexUsage struct {
+	InputTokens       int `json:"input_tokens"`
+	CachedInputTokens int `json:"cached_input_tokens"`
+	OutputTokens      int `json:"output_tokens"`
+}
+
+// parseCodexHeader reads the session_meta header line of a rollout.
+func parseCodexHeader(line string) (sessionHeader, bool) {
+	var l codexLine
+	if err := json.Unmarshal([]byte(line), &l); err != nil || l.Type != "session_meta" {
+		return sessionHeader{}, false
+	}
+	var p codexPayload
+	if err := json.Unmarshal(l.Payload, &p); err != nil {
+		return sessionHeader{}, false
+	}
+	h := sessionHeader{NativeID: p.ID, Cwd: p.Cwd}
+	if t, err := time.Parse(time.RFC3339, l.Timestamp); err == nil {
+		h.Started = t
+	}
+	return h, true
+}
+
+// codexInjectedPrefixes mark user messages Codex writes itself (environment
+// context, AGENTS.md instructions) rather than prompts sent to the agent.
+var codexInjectedPrefixes = []string{"<environment_context>", "<user_instructions>"}
+
+// parseCodexLine parses one rollout line and returns 0 or more AgentEvents.
+func parseCodexLine(line, sessionID, agentType, nativeSessionID string) []AgentEvent {
+	var l codexLine
+	if err := json.Unmarshal([]byte(line), &l); err != nil {
+		return nil
+	}
+	if l.Type != "response_item" && l.Type != "event_msg" {
+		return nil
+	}
+	var p codexPayload
+	if err := json.Unmarshal(l.Payload, &p); err != nil {
+		return nil
+	}
+
+	ts := time.Now()
+	if l.Timestamp != "" {
+		if t, err := time.Parse(time.RFC3339, l.Timestamp); err == nil {
+			ts = t
+		}
+	}
+	event := func(eventType, role, content string) AgentEvent {
+		return AgentEvent{
+			AgentType:       agentType,
+			SessionID:       sessionID,
+			NativeSessionID: nativeSessionID,
+			EventType:       eventType,
+			Role:            role,
+			Content:         content,
+			Timestamp:       ts,
+		}
+	}
+
+	// event_msg lines mostly repeat response items for the TUI; only token
+	// counts carry information the response items lack.
+	if l.Type == "event_msg" {
+		if p.Type != "token_count" || p.Info == nil || p.Info.LastTokenUsage == nil {
+			return nil
+		}
+		u := p.Info.LastTokenUsage
+		if u.InputTokens == 0 && u.OutputTokens == 0 {
+			return nil
+		}
+		ev := event("usage", "assistant", "")
+		ev.InputTokens = u.InputTokens - u.CachedInputTokens
+		ev.OutputTokens = u.OutputTokens
+		ev.CacheReadTokens = u.CachedInputTokens
+		return []AgentEvent{ev}
+	}
+
+	var events []AgentEvent
+	switch p.Type {
+	case "message":
+		if p.Role != "user" && p.Role != "assistant" {
+			return nil // developer/system prompts
+		}
+		for _, c := range p.Content {
+			if c.Text == "" || hasAnyPrefix(c.Text, codexInjectedPrefixes) {
+				continue
+			}
+			events = append(events, event("text", p.Role, c.Text))
+		}
+	case "reasoning":
+		var parts []string
+		for _, c := range p.Summary {
+			if c.Text != "" {
+				parts = append(parts, c.Text)
+			}
+		}
+		if len(parts) > 0 {
+			events = append(events, event("thinking", "assistant", strings.Join(parts, "\n\n")))
+		}
+	case "function_call":
+		events = append(events, event("tool_use", "assistant", p.Name+": "+p.Arguments))
+	case "custom_tool_call":
+		events = append(events, event("tool_use", "assistant", p.Name+": "+p.Input))
+	case "function_call_output", "custom_tool_call_output":
+		if out := rawString(p.Output); out != "" {
+			events = append(events, event("tool_result", "user", out))
+		}
+	}
+	return events
+}
+
+// rawString returns a JSON string value unquoted, or any other JSON value as-is.
+func rawString(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	s = strings.TrimSpace(s)
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
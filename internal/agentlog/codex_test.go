@@ -0,0 +1,178 @@
+package agentlog
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readFixtureEvents parses every line of a JSONL fixture with parse.
+func readFixtureEvents(t *testing.T, name string, parse func(line string) []AgentEvent) []AgentEvent {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var events []AgentEvent
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		events = append(events, parse(sc.Text())...)
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+// eventTypes returns "type/role" for each event, for compact comparisons.
+func eventTypes(events []AgentEvent) []string {
+	var out []string
+	for _, ev := range events {
+		out = append(out, ev.EventType+"/"+ev.Role)
+	}
+	return out
+}
+
+// collectEvents reads from ch until n events arrive or the timeout expires.
+func collectEvents(t *testing.T, ch <-chan AgentEvent, n int, timeout time.Duration) []AgentEvent {
+	t.Helper()
+	var events []AgentEvent
+	deadline := time.After(timeout)
+	for len(events) < n {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				t.Fatalf("channel closed after %d events, want %d", len(events), n)
+			}
+			events = append(events, ev)
+		case <-deadline:
+			t.Fatalf("timed out after %d events, want %d: %v", len(events), n, eventTypes(events))
+		}
+	}
+	return events
+}
+
+func TestParseCodexLine_Fixture(t *testing.T) {
+	events := readFixtureEvents(t, "codex_rollout.jsonl", func(line string) []AgentEvent {
+		return parseCodexLine(line, "gt-gastown-toast", "codex", "native-1")
+	})
+
+	want := []string{
+		"text/user",          // prompt (environment_context is skipped)
+		"thinking/assistant", // reasoning summary
+		"tool_use/assistant", // shell
+		"usage/assistant",
+		"tool_result/user",
+		"tool_use/assistant", // apply_patch
+		"tool_result/user",
+		"text/assistant",
+		"usage/assistant",
+	}
+	if got := eventTypes(events); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("events = %v\nwant %v", got, want)
+	}
+
+	if events[0].Content != "Run gt prime and begin work." {
+		t.Errorf("prompt content = %q", events[0].Content)
+	}
+	if events[2].Content != `shell: {"command":["gt","prime"]}` {
+		t.Errorf("tool_use content = %q", events[2].Content)
+	}
+	if !strings.Contains(events[4].Content, "Primed as gastown/polecats/toast") {
+		t.Errorf("tool_result content = %q", events[4].Content)
+	}
+	if events[6].Content != "Success." {
+		t.Errorf("custom tool output = %q", events[6].Content)
+	}
+
+	// last_token_usage is per response; cached input is split out of input.
+	u := events[3]
+	if u.InputTokens != 2000 || u.CacheReadTokens != 3000 || u.OutputTokens != 200 {
+		t.Errorf("first usage = in %d cache %d out %d, want 2000/3000/200", u.InputTokens, u.CacheReadTokens, u.OutputTokens)
+	}
+	u = events[8]
+	if u.InputTokens != 1000 || u.CacheReadTokens != 5000 || u.OutputTokens != 60 {
+		t.Errorf("second usage = in %d cache %d out %d, want 1000/5000/60", u.InputTokens, u.CacheReadTokens, u.OutputTokens)
+	}
+
+	for _, ev := range events {
+		if ev.AgentType != "codex" || ev.SessionID != "gt-gastown-toast" || ev.NativeSessionID != "native-1" {
+			t.Errorf("event tags = %q/%q/%q", ev.AgentType, ev.SessionID, ev.NativeSessionID)
+		}
+	}
+	want0 := time.Date(2026, 2, 23, 10, 0, 1, 0, time.UTC)
+	if !events[0].Timestamp.Equal(want0) {
+		t.Errorf("Timestamp = %v, want %v", events[0].Timestamp, want0)
+	}
+}
+
+func TestParseCodexHeader(t *testing.T) {
+	line, ok := readFirstLine(filepath.Join("testdata", "codex_rollout.jsonl"))
+	if !ok {
+		t.Fatal("reading fixture header")
+	}
+	h, ok := parseCodexHeader(line)
+	if !ok {
+		t.Fatal("parseCodexHeader rejected session_meta")
+	}
+	if h.NativeID != "0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b" || h.Cwd != "/work/gastown/polecats/toast" {
+		t.Errorf("header = %+v", h)
+	}
+	if _, ok := parseCodexHeader(`{"type":"response_item","payload":{}}`); ok {
+		t.Error("parseCodexHeader accepted a non-header line")
+	}
+}
+
+func TestCodexSessionIDFromPath(t *testing.T) {
+	path := "/x/rollout-2026-02-23T10-00-00-0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b.jsonl"
+	if got := codexSessionIDFromPath(path); got != "0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b" {
+		t.Errorf("codexSessionIDFromPath = %q", got)
+	}
+}
+
+func TestCodexAdapter_WatchFollowsWorkDir(t *testing.T) {
+	codexHome := t.TempDir()
+	t.Setenv("CODEX_HOME", codexHome)
+	workDir := t.TempDir()
+	otherDir := t.TempDir()
+
+	day := filepath.Join(codexHome, codexSessionsDir, time.Now().Format("2006/01/02"))
+	if err := os.MkdirAll(day, 0755); err != nil {
+		t.Fatal(err)
+	}
+	fixture, err := os.ReadFile(filepath.Join("testdata", "codex_rollout.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The other work dir's rollout is newer; it must still be ignored.
+	ours := strings.ReplaceAll(string(fixture), "/work/gastown/polecats/toast", workDir)
+	theirs := strings.ReplaceAll(string(fixture), "/work/gastown/polecats/toast", otherDir)
+	oursPath := filepath.Join(day, "rollout-2026-02-23T10-00-00-0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b.jsonl")
+	if err := os.WriteFile(oursPath, []byte(ours), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Minute)
+	_ = os.Chtimes(oursPath, old, old)
+	if err := os.WriteFile(filepath.Join(day, "rollout-2026-02-23T10-05-00-ffffffff-ffff-ffff-ffff-ffffffffffff.jsonl"), []byte(theirs), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := (&CodexAdapter{}).Watch(ctx, "gt-gastown-toast", workDir, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := collectEvents(t, ch, 9, 5*time.Second)
+	for _, ev := range events {
+		if ev.NativeSessionID != "0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b" {
+			t.Fatalf("followed the wrong rollout: native ID %q", ev.NativeSessionID)
+		}
+	}
+}
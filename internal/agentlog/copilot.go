@@ -0,0 +1,224 @@
+package agentlog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// copilotSessionStateDir is the path under $COPILOT_HOME (default ~/.copilot)
+// where Copilot CLI writes session event logs.
+const copilotSessionStateDir = "session-state"
+
+// CopilotAdapter watches Copilot CLI session event logs.
+//
+// Copilot CLI writes one JSONL event log per session at either of:
+//
+//	$COPILOT_HOME/session-state/<session-uuid>.jsonl
+//	$COPILOT_HOME/session-state/<session-uuid>/events.jsonl
+//
+// Sessions from every working directory share the directory, so the adapter
+// reads the session.start event on each file's first line and only follows
+// sessions whose cwd is workDir.
+type CopilotAdapter struct{}
+
+func (a *CopilotAdapter) AgentType() string { return "copilot" }
+
+// Watch starts tailing the newest Copilot session log for workDir modified at
+// or after since, switching to a newer log when Copilot restarts.
+func (a *CopilotAdapter) Watch(ctx context.Context, sessionID, workDir string, since time.Time) (<-chan AgentEvent, error) {
+	dir, err := copilotStateDir()
+	if err != nil {
+		return nil, err
+	}
+	absWorkDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving absolute path: %w", err)
+	}
+
+	headers := newHeaderCache(parseCopilotHeader)
+	find := func(since time.Time) (string, bool) {
+		return newestOf(copilotSessionLogs(dir), since, func(path string) bool {
+			return headers.inWorkDir(path, absWorkDir)
+		})
+	}
+	ch := make(chan AgentEvent, 64)
+	go func() {
+		defer close(ch)
+		followLogs(ctx, since, find, func(path string, since time.Time) {
+			nativeID := copilotSessionIDFromPath(path)
+			if h, ok := headers.get(path); ok && h.NativeID != "" {
+				nativeID = h.NativeID
+			}
+			tailJSONL(ctx, path, func() (string, bool) { return find(since) }, func(line string) []AgentEvent {
+				return parseCopilotLine(line, sessionID, a.AgentType(), nativeID)
+			}, ch)
+		})
+	}()
+	return ch, nil
+}
+
+// copilotStateDir returns $COPILOT_HOME/session-state, defaulting to ~/.copilot.
+func copilotStateDir() (string, error) {
+	if copilotHome := os.Getenv("COPILOT_HOME"); copilotHome != "" {
+		return filepath.Join(copilotHome, copilotSessionStateDir), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home dir: %w", err)
+	}
+	return filepath.Join(home, ".copilot", copilotSessionStateDir), nil
+}
+
+// copilotSessionLogs lists session event logs in both on-disk layouts.
+func copilotSessionLogs(dir string) []string {
+	flat, _ := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	nested, _ := filepath.Glob(filepath.Join(dir, "*", "events.jsonl"))
+	return append(flat, nested...)
+}
+
+// copilotSessionIDFromPath returns the session UUID encoded in a log path.
+func copilotSessionIDFromPath(path string) string {
+	if filepath.Base(path) == "events.jsonl" {
+		return filepath.Base(filepath.Dir(path))
+	}
+	return strings.TrimSuffix(filepath.Base(path), ".jsonl")
+}
+
+// ── Copilot event structures ──────────────────────────────────────────────────
+
+// copilotEvent is one line of a Copilot session event log.
+type copilotEvent struct {
+	Type      string          `json:"type"`
+	Timestamp string          `json:"timestamp,omitempty"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// copilotData covers the data fields used from the event types we read.
+type copilotData struct {
+	// session.start
+	SessionID string `json:"sessionId,omitempty"`
+	Context   *struct {
+		Cwd string `json:"cwd,omitempty"`
+	} `json:"context,omitempty"`
+
+	// user.message, assistant.message
+	Content       string               `json:"content,omitempty"`
+	ReasoningText string               `json:"reasoningText,omitempty"`
+	ToolRequests  []copilotToolRequest `json:"toolRequests,omitempty"`
+
+	// tool.execution_complete
+	Result *struct {
+		Content string `json:"content,omitempty"`
+	} `json:"result,omitempty"`
+	Error *struct {
+		Message string `json:"message,omitempty"`
+	} `json:"error,omitempty"`
+
+	// assistant.usage
+	InputTokens      int `json:"inputTokens,omitempty"`
+	OutputTokens     int `json:"outputTokens,omitempty"`
+	CacheReadTokens  int `json:"cacheReadTokens,omitempty"`
+	CacheWriteTokens int `json:"cacheWriteTokens,omitempty"`
+}
+
+// copilotToolRequest is a tool call requested in an assistant.message.
+type copilotToolRequest struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// parseCopilotHeader reads the session.start event on a log's first line.
+func parseCopilotHeader(line string) (sessionHeader, bool) {
+	var ev copilotEvent
+	if err := json.Unmarshal([]byte(line), &ev); err != nil || ev.Type != "session.start" {
+		return sessionHeader{}, false
+	}
+	var d copilotData
+	if err := json.Unmarshal(ev.Data, &d); err != nil {
+		return sessionHeader{}, false
+	}
+	h := sessionHeader{NativeID: d.SessionID}
+	if d.Context != nil {
+		h.Cwd = d.Context.Cwd
+	}
+	if t, err := time.Parse(time.RFC3339, ev.Timestamp); err == nil {
+		h.Started = t
+	}
+	return h, true
+}
+
+// parseCopilotLine parses one event line and returns 0 or more AgentEvents.
+func parseCopilotLine(line, sessionID, agentType, nativeSessionID string) []AgentEvent {
+	var ev copilotEvent
+	if err := json.Unmarshal([]byte(line), &ev); err != nil {
+		return nil
+	}
+	var d copilotData
+	if len(ev.Data) > 0 {
+		if err := json.Unmarshal(ev.Data, &d); err != nil {
+			return nil
+		}
+	}
+
+	ts := time.Now()
+	if ev.Timestamp != "" {
+		if t, err := time.Parse(time.RFC3339, ev.Timestamp); err == nil {
+			ts = t
+		}
+	}
+	event := func(eventType, role, content string) AgentEvent {
+		return AgentEvent{
+			AgentType:       agentType,
+			SessionID:       sessionID,
+			NativeSessionID: nativeSessionID,
+			EventType:       eventType,
+			Role:            role,
+			Content:         content,
+			Timestamp:       ts,
+		}
+	}
+
+	var events []AgentEvent
+	switch ev.Type {
+	case "user.message":
+		if d.Content != "" {
+			events = append(events, event("text", "user", d.Content))
+		}
+	case "assistant.message":
+		if d.ReasoningText != "" {
+			events = append(events, event("thinking", "assistant", d.ReasoningText))
+		}
+		if d.Content != "" {
+			events = append(events, event("text", "assistant", d.Content))
+		}
+		for _, tr := range d.ToolRequests {
+			events = append(events, event("tool_use", "assistant", tr.Name+": "+string(tr.Arguments)))
+		}
+	case "tool.execution_complete":
+		content := ""
+		if d.Result != nil {
+			content = d.Result.Content
+		}
+		if content == "" && d.Error != nil {
+			content = d.Error.Message
+		}
+		if content != "" {
+			events = append(events, event("tool_result", "user", content))
+		}
+	case "assistant.usage":
+		if d.InputTokens > 0 || d.OutputTokens > 0 || d.CacheReadTokens > 0 || d.CacheWriteTokens > 0 {
+			usage := event("usage", "assistant", "")
+			usage.InputTokens = d.InputTokens
+			usage.OutputTokens = d.OutputTokens
+			usage.CacheReadTokens = d.CacheReadTokens
+			usage.CacheCreationTokens = d.CacheWriteTokens
+			events = append(events, usage)
+		}
+	}
+	return events
+}
@@ -0,0 +1,97 @@
+package agentlog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCopilotLine_Fixture(t *testing.T) {
+	events := readFixtureEvents(t, "copilot_events.jsonl", func(line string) []AgentEvent {
+		return parseCopilotLine(line, "gt-gastown-toast", "copilot", "native-1")
+	})
+
+	want := []string{
+		"text/user",
+		"thinking/assistant",
+		"text/assistant",
+		"tool_use/assistant",
+		"usage/assistant",
+		"tool_result/user",
+		"tool_result/user", // failed tool: error message
+		"text/assistant",
+	}
+	if got := eventTypes(events); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("events = %v\nwant %v", got, want)
+	}
+
+	if events[0].Content != "Run gt prime and begin work." {
+		t.Errorf("user content = %q (want the untransformed prompt)", events[0].Content)
+	}
+	if !strings.HasPrefix(events[3].Content, "bash: {") || !strings.Contains(events[3].Content, `"command":"gt prime"`) {
+		t.Errorf("tool_use content = %q", events[3].Content)
+	}
+	if events[6].Content != "command not found: bd" {
+		t.Errorf("failed tool content = %q", events[6].Content)
+	}
+	u := events[4]
+	if u.InputTokens != 1200 || u.OutputTokens != 80 || u.CacheReadTokens != 9000 || u.CacheCreationTokens != 400 {
+		t.Errorf("usage = %+v", u)
+	}
+}
+
+func TestParseCopilotHeader(t *testing.T) {
+	line, ok := readFirstLine(filepath.Join("testdata", "copilot_events.jsonl"))
+	if !ok {
+		t.Fatal("reading fixture header")
+	}
+	h, ok := parseCopilotHeader(line)
+	if !ok {
+		t.Fatal("parseCopilotHeader rejected session.start")
+	}
+	if h.NativeID != "5f0e1d2c-3b4a-4958-8776-a5b4c3d2e1f0" || h.Cwd != "/work/gastown/polecats/toast" {
+		t.Errorf("header = %+v", h)
+	}
+}
+
+func TestCopilotSessionIDFromPath(t *testing.T) {
+	if got := copilotSessionIDFromPath("/s/abc-123.jsonl"); got != "abc-123" {
+		t.Errorf("flat layout = %q", got)
+	}
+	if got := copilotSessionIDFromPath("/s/abc-123/events.jsonl"); got != "abc-123" {
+		t.Errorf("nested layout = %q", got)
+	}
+}
+
+func TestCopilotAdapter_WatchNestedLayout(t *testing.T) {
+	copilotHome := t.TempDir()
+	t.Setenv("COPILOT_HOME", copilotHome)
+	workDir := t.TempDir()
+
+	sessionDir := filepath.Join(copilotHome, copilotSessionStateDir, "5f0e1d2c-3b4a-4958-8776-a5b4c3d2e1f0")
+	if err := os.MkdirAll(sessionDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	fixture, err := os.ReadFile(filepath.Join("testdata", "copilot_events.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := strings.ReplaceAll(string(fixture), "/work/gastown/polecats/toast", workDir)
+	if err := os.WriteFile(filepath.Join(sessionDir, "events.jsonl"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := (&CopilotAdapter{}).Watch(ctx, "gt-gastown-toast", workDir, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := collectEvents(t, ch, 8, 5*time.Second)
+	if events[0].NativeSessionID != "5f0e1d2c-3b4a-4958-8776-a5b4c3d2e1f0" {
+		t.Errorf("NativeSessionID = %q", events[0].NativeSessionID)
+	}
+}
@@ -2,8 +2,9 @@
 // and emitting normalized OTEL telemetry events.
 //
 // Design: AgentAdapter is the extension point. Adding support for a new agent
-// (OpenCode, Kiro, etc.) means implementing this interface. The gt agent-log command
-// selects the adapter via --agent flag and defaults to "claudecode".
+// (OpenCode, Kiro, etc.) means implementing this interface and declaring its name
+// as the preset's LogFormat in config. The gt agent-log command selects the adapter
+// via --agent flag and defaults to "claudecode".
 package agentlog
 
 import (
@@ -14,9 +15,9 @@ import (
 // AgentEvent is a normalized event extracted from an AI agent's conversation log.
 // All adapters emit this type so downstream telemetry is agent-agnostic.
 type AgentEvent struct {
-	AgentType       string    // "claudecode", "opencode", …
+	AgentType       string    // "claudecode", "codex", "gemini", "copilot", …
 	SessionID       string    // Gas Town tmux session name (e.g. "hq-mayor", "gt-wyvern-toast")
-	NativeSessionID string    // agent-native session UUID (e.g. Claude Code session UUID from JSONL filename)
+	NativeSessionID string    // agent-native session ID (e.g. Claude Code session UUID from JSONL filename)
 	EventType       string    // "text", "tool_use", "tool_result", "thinking", "usage"
 	Role            string    // "assistant" or "user"
 	Content         string    // text content; empty for "usage" events
@@ -24,8 +25,9 @@ type AgentEvent struct {
 
 	// Token usage fields — non-zero only for EventType == "usage".
 	// One "usage" event is emitted per assistant turn (not per content block).
+	// Adapters normalize to Claude API semantics: InputTokens excludes cache reads.
 	InputTokens         int // input_tokens from Claude API usage
-	OutputTokens        int // output_tokens from Claude API usage
+	OutputTokens        int // output_tokens from Claude API usage (including reasoning)
 	CacheReadTokens     int // cache_read_input_tokens
 	CacheCreationTokens int // cache_creation_input_tokens
 }
@@ -53,7 +55,18 @@ func NewAdapter(agentType string) AgentAdapter {
 		return &ClaudeCodeAdapter{}
 	case "opencode":
 		return &OpenCodeAdapter{}
+	case "codex":
+		return &CodexAdapter{}
+	case "gemini":
+		return &GeminiAdapter{}
+	case "copilot":
+		return &CopilotAdapter{}
 	default:
 		return nil
 	}
 }
+
+// AdapterNames lists the agent types NewAdapter accepts.
+func AdapterNames() []string {
+	return []string{"claudecode", "codex", "copilot", "gemini", "opencode"}
+}
@@ -0,0 +1,24 @@
+package agentlog
+
+import (
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// Every built-in agent with an adapter must name it as its log format, or
+// agent-log streaming, seance indexing and thrash detection skip the agent.
+func TestPresetsWithAdapterSetLogFormat(t *testing.T) {
+	for _, name := range config.ListAgentPresets() {
+		agentType := name
+		if name == string(config.AgentClaude) {
+			agentType = "claudecode"
+		}
+		if NewAdapter(agentType) == nil {
+			continue
+		}
+		if got := config.GetLogFormat(name); got != agentType {
+			t.Errorf("GetLogFormat(%q) = %q, want %q", name, got, agentType)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package agentlog
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// newestOf returns the most recently modified of paths whose modification
+// time is >= since (skip if since is zero) and for which accept returns true.
+// accept may be nil.
+func newestOf(paths []string, since time.Time, accept func(path string) bool) (string, bool) {
+	var bestPath string
+	var bestTime time.Time
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if !since.IsZero() && info.ModTime().Before(since) {
+			continue
+		}
+		if bestPath != "" && !info.ModTime().After(bestTime) {
+			continue
+		}
+		if accept != nil && !accept(path) {
+			continue
+		}
+		bestPath = path
+		bestTime = info.ModTime()
+	}
+	return bestPath, bestPath != ""
+}
+
+// readFirstLine returns the first line of path without its newline. Session
+// headers (Codex session_meta, Copilot session.start) live on the first line.
+func readFirstLine(path string) (string, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	if !sc.Scan() {
+		return "", false
+	}
+	return sc.Text(), true
+}
+
+// sessionHeader is the identifying metadata of a session log file.
+type sessionHeader struct {
+	NativeID string    // agent-native session ID; empty if not recorded
+	Cwd      string    // agent working directory; empty if not recorded
+	Started  time.Time // time of the header entry; zero if not recorded
+}
+
+// headerCache parses and remembers session headers so the finders do not
+// re-read every candidate file on each poll. A file whose header line has not
+// been written yet is retried on the next lookup.
+type headerCache struct {
+	parse func(line string) (sessionHeader, bool)
+
+	mu      sync.Mutex
+	headers map[string]sessionHeader
+}
+
+func newHeaderCache(parse func(line string) (sessionHeader, bool)) *headerCache {
+	return &headerCache{parse: parse, headers: make(map[string]sessionHeader)}
+}
+
+func (c *headerCache) get(path string) (sessionHeader, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if h, ok := c.headers[path]; ok {
+		return h, true
+	}
+	line, ok := readFirstLine(path)
+	if !ok {
+		return sessionHeader{}, false
+	}
+	h, ok := c.parse(line)
+	if !ok {
+		return sessionHeader{}, false
+	}
+	c.headers[path] = h
+	return h, true
+}
+
+// inWorkDir reports whether the session in path ran in workDir. Sessions that
+// do not record a working directory are accepted.
+func (c *headerCache) inWorkDir(path, workDir string) bool {
+	h, ok := c.get(path)
+	if !ok {
+		return false
+	}
+	return h.Cwd == "" || sameDir(h.Cwd, workDir)
+}
+
+// sameDir reports whether a and b name the same directory, resolving
+// symlinks (e.g. /var → /private/var on macOS) when both exist.
+func sameDir(a, b string) bool {
+	a, b = filepath.Clean(a), filepath.Clean(b)
+	if a == b {
+		return true
+	}
+	ra, errA := filepath.EvalSymlinks(a)
+	rb, errB := filepath.EvalSymlinks(b)
+	return errA == nil && errB == nil && ra == rb
+}
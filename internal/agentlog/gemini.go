@@ -0,0 +1,281 @@
+package agentlog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// geminiTmpDir is the path under $HOME where Gemini CLI keeps per-project state.
+	geminiTmpDir = ".gemini/tmp"
+
+	// geminiSettleInterval is how long the last message of a chat must stay
+	// unchanged before it is emitted. Gemini CLI rewrites the chat file as a
+	// turn progresses (tokens, then tool calls are attached to the message).
+	geminiSettleInterval = 2 * time.Second
+)
+
+// GeminiAdapter watches Gemini CLI chat recordings.
+//
+// Gemini CLI records each session as a single JSON document at:
+//
+//	~/.gemini/tmp/<project-hash>/chats/session-<timestamp>-<id>.json
+//
+// where <project-hash> is the hex SHA-256 of the project root (workDir).
+// The file is rewritten on every update rather than appended to, so the
+// adapter re-reads it on change and emits messages it has not emitted yet.
+// A message is emitted once a later message follows it, or once it has been
+// left unchanged for geminiSettleInterval.
+type GeminiAdapter struct{}
+
+func (a *GeminiAdapter) AgentType() string { return "gemini" }
+
+// Watch starts following the newest Gemini chat for workDir modified at or
+// after since, switching to a newer chat when Gemini restarts.
+func (a *GeminiAdapter) Watch(ctx context.Context, sessionID, workDir string, since time.Time) (<-chan AgentEvent, error) {
+	chatsDir, err := geminiChatsDirFor(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving chats dir: %w", err)
+	}
+
+	find := func(since time.Time) (string, bool) {
+		paths, _ := filepath.Glob(filepath.Join(chatsDir, "session-*.json"))
+		return newestOf(paths, since, nil)
+	}
+	ch := make(chan AgentEvent, 64)
+	go func() {
+		defer close(ch)
+		followLogs(ctx, since, find, func(path string, since time.Time) {
+			followGeminiChat(ctx, path, func() (string, bool) { return find(since) }, sessionID, a.AgentType(), ch)
+		})
+	}()
+	return ch, nil
+}
+
+// geminiChatsDirFor returns the Gemini CLI chats directory for workDir.
+func geminiChatsDirFor(workDir string) (string, error) {
+	abs, err := filepath.Abs(workDir)
+	if err != nil {
+		return "", fmt.Errorf("resolving absolute path: %w", err)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home dir: %w", err)
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(home, geminiTmpDir, hex.EncodeToString(sum[:]), "chats"), nil
+}
+
+// followGeminiChat polls the chat file at path, emitting new messages on ch.
+// Like tailJSONL, it returns (without closing ch) when newest reports a
+// different file or ctx is canceled; the pending last message is flushed
+// before switching so a session's final turn is not lost.
+func followGeminiChat(ctx context.Context, path string, newest func() (string, bool), sessionID, agentType string, ch chan<- AgentEvent) {
+	emitted := make(map[string]bool)
+	var lastMod time.Time
+	var lastSize int64
+	var changedAt time.Time
+	var chat *geminiChat
+
+	emit := func(flushLast bool) bool {
+		if chat == nil {
+			return true
+		}
+		for i, msg := range chat.Messages {
+			if emitted[msg.key(i)] {
+				continue
+			}
+			if i == len(chat.Messages)-1 && !flushLast {
+				break
+			}
+			emitted[msg.key(i)] = true
+			for _, ev := range parseGeminiMessage(msg, sessionID, agentType, chat.SessionID) {
+				select {
+				case ch <- ev:
+				case <-ctx.Done():
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	for {
+		if info, err := os.Stat(path); err == nil && (!info.ModTime().Equal(lastMod) || info.Size() != lastSize) {
+			if parsed, err := readGeminiChat(path); err == nil {
+				chat = parsed
+				lastMod, lastSize = info.ModTime(), info.Size()
+				changedAt = time.Now()
+			}
+		}
+		if !emit(!changedAt.IsZero() && time.Since(changedAt) >= geminiSettleInterval) {
+			return
+		}
+
+		if newer, ok := newest(); ok && newer != path {
+			emit(true)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(watchPollInterval):
+		}
+	}
+}
+
+// ── Gemini chat structures ────────────────────────────────────────────────────
+
+// geminiChat is a Gemini CLI chat recording.
+type geminiChat struct {
+	SessionID string          `json:"sessionId"`
+	Messages  []geminiMessage `json:"messages"`
+}
+
+// geminiMessage is one message in a chat recording. Type is "user", "gemini",
+// "info", "error" or "warning".
+type geminiMessage struct {
+	ID        string           `json:"id"`
+	Timestamp string           `json:"timestamp,omitempty"`
+	Type      string           `json:"type"`
+	Content   json.RawMessage  `json:"content,omitempty"`
+	Thoughts  []geminiThought  `json:"thoughts,omitempty"`
+	ToolCalls []geminiToolCall `json:"toolCalls,omitempty"`
+	Tokens    *geminiTokens    `json:"tokens,omitempty"`
+}
+
+// key identifies a message for de-duplication across re-reads.
+func (m geminiMessage) key(index int) string {
+	if m.ID != "" {
+		return m.ID
+	}
+	return fmt.Sprintf("#%d", index)
+}
+
+// geminiThought is a reasoning summary recorded on a gemini message.
+type geminiThought struct {
+	Subject     string `json:"subject,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// geminiToolCall is a tool invocation recorded on a gemini message.
+type geminiToolCall struct {
+	Name          string          `json:"name"`
+	Args          json.RawMessage `json:"args,omitempty"`
+	ResultDisplay json.RawMessage `json:"resultDisplay,omitempty"`
+}
+
+// geminiTokens holds Gemini API token counts for one response. Input includes
+// Cached; Thoughts are billed as output.
+type geminiTokens struct {
+	Input    int `json:"input"`
+	Output   int `json:"output"`
+	Cached   int `json:"cached"`
+	Thoughts int `json:"thoughts"`
+	Tool     int `json:"tool"`
+}
+
+// readGeminiChat reads and decodes a chat file. A file caught mid-rewrite
+// fails to decode and is retried on the next poll.
+func readGeminiChat(path string) (*geminiChat, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var chat geminiChat
+	if err := json.Unmarshal(data, &chat); err != nil {
+		return nil, err
+	}
+	return &chat, nil
+}
+
+// geminiContentText returns the text of a message's content, which is either
+// a string or a list of parts ({"text": ...}).
+func geminiContentText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var parts []struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return ""
+	}
+	var texts []string
+	for _, p := range parts {
+		if p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// parseGeminiMessage converts one chat message into 0 or more AgentEvents.
+func parseGeminiMessage(msg geminiMessage, sessionID, agentType, nativeSessionID string) []AgentEvent {
+	var role string
+	switch msg.Type {
+	case "user":
+		role = "user"
+	case "gemini":
+		role = "assistant"
+	default:
+		return nil // info/error/warning are CLI notices, not conversation turns
+	}
+
+	ts := time.Now()
+	if msg.Timestamp != "" {
+		if t, err := time.Parse(time.RFC3339, msg.Timestamp); err == nil {
+			ts = t
+		}
+	}
+	event := func(eventType, role, content string) AgentEvent {
+		return AgentEvent{
+			AgentType:       agentType,
+			SessionID:       sessionID,
+			NativeSessionID: nativeSessionID,
+			EventType:       eventType,
+			Role:            role,
+			Content:         content,
+			Timestamp:       ts,
+		}
+	}
+
+	var events []AgentEvent
+	for _, th := range msg.Thoughts {
+		content := th.Description
+		if th.Subject != "" {
+			content = th.Subject + ": " + th.Description
+		}
+		if content != "" {
+			events = append(events, event("thinking", role, content))
+		}
+	}
+	if text := geminiContentText(msg.Content); text != "" {
+		events = append(events, event("text", role, text))
+	}
+	for _, tc := range msg.ToolCalls {
+		events = append(events, event("tool_use", role, tc.Name+": "+string(tc.Args)))
+		if result := rawString(tc.ResultDisplay); result != "" {
+			events = append(events, event("tool_result", "user", result))
+		}
+	}
+	if t := msg.Tokens; t != nil && (t.Input > 0 || t.Output > 0 || t.Cached > 0) {
+		usage := event("usage", "assistant", "")
+		usage.InputTokens = t.Input - t.Cached
+		usage.OutputTokens = t.Output + t.Thoughts
+		usage.CacheReadTokens = t.Cached
+		events = append(events, usage)
+	}
+	return events
+}
@@ -0,0 +1,125 @@
+package agentlog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseGeminiMessage_Fixture(t *testing.T) {
+	chat, err := readGeminiChat(filepath.Join("testdata", "gemini_chat.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []AgentEvent
+	for _, msg := range chat.Messages {
+		events = append(events, parseGeminiMessage(msg, "gt-gastown-toast", "gemini", chat.SessionID)...)
+	}
+
+	want := []string{
+		"text/user",
+		"thinking/assistant",
+		"text/assistant",
+		"tool_use/assistant",
+		"tool_result/user",
+		"usage/assistant",
+		// info message skipped
+		"text/assistant",
+		"usage/assistant",
+	}
+	if got := eventTypes(events); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("events = %v\nwant %v", got, want)
+	}
+
+	if events[1].Content != "Loading context: I should run gt prime before anything else." {
+		t.Errorf("thinking content = %q", events[1].Content)
+	}
+	if events[3].Content != `run_shell_command: {"command": "gt prime"}` {
+		t.Errorf("tool_use content = %q", events[3].Content)
+	}
+	if events[6].Content != "Primed and \non the hook." {
+		t.Errorf("parts content = %q", events[6].Content)
+	}
+	// Cached input is split out of input; thoughts are billed as output.
+	u := events[5]
+	if u.InputTokens != 2000 || u.CacheReadTokens != 6000 || u.OutputTokens != 80 {
+		t.Errorf("usage = in %d cache %d out %d, want 2000/6000/80", u.InputTokens, u.CacheReadTokens, u.OutputTokens)
+	}
+	if events[0].NativeSessionID != "8c7b6a59-4837-4261-9504-f3e2d1c0b9a8" {
+		t.Errorf("NativeSessionID = %q", events[0].NativeSessionID)
+	}
+}
+
+func TestGeminiChatsDirFor(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte("/some/work/dir"))
+	want := filepath.Join(home, geminiTmpDir, hex.EncodeToString(sum[:]), "chats")
+	got, err := geminiChatsDirFor("/some/work/dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("geminiChatsDirFor = %q, want %q", got, want)
+	}
+}
+
+func TestGeminiAdapter_WatchRewrittenChat(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	workDir := t.TempDir()
+	chatsDir, err := geminiChatsDirFor(workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(chatsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	fixture, err := os.ReadFile(filepath.Join("testdata", "gemini_chat.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chatPath := filepath.Join(chatsDir, "session-2026-02-23T10-00-8c7b6a59.json")
+
+	// Start with only the first message, then rewrite the whole file the way
+	// Gemini CLI does as the session progresses.
+	first, err := readGeminiChat(filepath.Join("testdata", "gemini_chat.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	partial := `{"sessionId":"` + first.SessionID + `","messages":[{"id":"msg-1","timestamp":"2026-02-23T10:00:01.000Z","type":"user","content":"Run gt prime and begin work."}]}`
+	if err := os.WriteFile(chatPath, []byte(partial), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := (&GeminiAdapter{}).Watch(ctx, "gt-gastown-toast", workDir, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The lone user message settles and is emitted.
+	events := collectEvents(t, ch, 1, 5*time.Second)
+	if events[0].EventType != "text" || events[0].Role != "user" {
+		t.Fatalf("first event = %s/%s", events[0].EventType, events[0].Role)
+	}
+
+	if err := os.WriteFile(chatPath, fixture, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Everything after msg-1, without re-emitting it.
+	events = collectEvents(t, ch, 7, 10*time.Second)
+	if events[0].EventType != "thinking" {
+		t.Errorf("re-emitted earlier messages: first new event is %s/%s", events[0].EventType, events[0].Role)
+	}
+	if last := events[len(events)-1]; last.EventType != "usage" || last.InputTokens != 200 {
+		t.Errorf("last event = %+v", last)
+	}
+}
@@ -0,0 +1,213 @@
+package agentlog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// lineParsers parses one line of each line-oriented (JSONL) log format,
+// given the native session ID derived from the log's path.
+var lineParsers = map[string]struct {
+	nativeID func(path string) string
+	parse    func(line, nativeSessionID string) []AgentEvent
+}{
+	"claudecode": {nativeSessionIDFromPath, func(line, id string) []AgentEvent {
+		return parseClaudeCodeLine(line, "", "claudecode", id)
+	}},
+	"codex": {codexSessionIDFromPath, func(line, id string) []AgentEvent {
+		return parseCodexLine(line, "", "codex", id)
+	}},
+	"copilot": {copilotSessionIDFromPath, func(line, id string) []AgentEvent {
+		return parseCopilotLine(line, "", "copilot", id)
+	}},
+}
+
+// ReadLog parses a finished or growing JSONL agent log from byte offset and
+// returns its events with the offset just past the last complete line, so
+// callers (e.g. the seance search index) can read a log incrementally. A
+// trailing partial line is left for the next call. SessionID is left empty
+// on the returned events.
+func ReadLog(agentType, path string, offset int64) ([]AgentEvent, int64, error) {
+	p, ok := lineParsers[agentType]
+	if !ok {
+		return nil, offset, fmt.Errorf("agent log format %q cannot be read incrementally", agentType)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, offset, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, err
+	}
+
+	nativeID := p.nativeID(path)
+	var events []AgentEvent
+	r := bufio.NewReaderSize(f, 64*1024)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			return events, offset, nil // partial (or no) line: wait for more
+		}
+		if err != nil {
+			return events, offset, err
+		}
+		offset += int64(len(line))
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			events = append(events, p.parse(string(line), nativeID)...)
+		}
+	}
+}
+
+// ReadsIncrementally reports whether ReadLog can read agentType's logs.
+func ReadsIncrementally(agentType string) bool {
+	_, ok := lineParsers[agentType]
+	return ok
+}
+
+// sessionLogMatchWindow bounds how far a log's first entry may be from a
+// session's recorded start for FindSessionLog to take it as that session's.
+const sessionLogMatchWindow = 10 * time.Minute
+
+// FindSessionLog returns the log agentType wrote for a session that ran in
+// workDir: the log recording nativeID, else the log in workDir whose first
+// entry is nearest to started. gt rarely learns a Codex or Copilot session's
+// own ID, so the start time usually decides. Claude Code logs are named by
+// session ID and looked up in the default config dir only.
+func FindSessionLog(agentType, nativeID, workDir string, started time.Time) (string, bool, error) {
+	workDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return "", false, fmt.Errorf("resolving absolute path: %w", err)
+	}
+	var paths []string
+	var headers *headerCache
+	switch agentType {
+	case "claudecode", "":
+		if nativeID == "" {
+			return "", false, nil
+		}
+		dir, err := claudeProjectDirFor(workDir)
+		if err != nil {
+			return "", false, err
+		}
+		path := filepath.Join(dir, nativeID+".jsonl")
+		if _, err := os.Stat(path); err != nil {
+			return "", false, nil
+		}
+		return path, true, nil
+	case "codex":
+		root, err := codexSessionsRoot()
+		if err != nil {
+			return "", false, err
+		}
+		var since time.Time
+		if !started.IsZero() {
+			since = started.Add(-sessionLogMatchWindow)
+		}
+		paths = codexRollouts(root, since)
+		headers = newHeaderCache(parseCodexHeader)
+	case "copilot":
+		dir, err := copilotStateDir()
+		if err != nil {
+			return "", false, err
+		}
+		paths = copilotSessionLogs(dir)
+		headers = newHeaderCache(parseCopilotHeader)
+	default:
+		return "", false, fmt.Errorf("agent log format %q cannot be read incrementally", agentType)
+	}
+
+	best, bestDiff := "", sessionLogMatchWindow
+	for _, path := range paths {
+		h, ok := headers.get(path)
+		if !ok {
+			continue
+		}
+		if nativeID != "" && h.NativeID == nativeID {
+			return path, true, nil
+		}
+		if started.IsZero() || h.Started.IsZero() || h.Cwd == "" || !sameDir(h.Cwd, workDir) {
+			continue
+		}
+		if diff := h.Started.Sub(started).Abs(); diff <= bestDiff {
+			best, bestDiff = path, diff
+		}
+	}
+	return best, best != "", nil
+}
+
+// recentLogTail bounds how much of a log RecentEvents reads. Long sessions
+// write logs of hundreds of megabytes; the recent past is at the end.
+const recentLogTail = 4 * 1024 * 1024
+
+// RecentEvents returns the events at or after since from the newest log the
+// agent wrote for workDir. Only the tail of the log is read; a line cut in
+// half by the seek fails to parse and is dropped. A workDir without any log
+// yields no events and no error.
+func RecentEvents(agentType, workDir string, since time.Time) ([]AgentEvent, error) {
+	path, ok, err := newestLogFor(agentType, workDir, since)
+	if err != nil || !ok {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	offset := max(info.Size()-recentLogTail, 0)
+	events, _, err := ReadLog(agentType, path, offset)
+	if err != nil {
+		return nil, err
+	}
+	recent := events[:0]
+	for _, ev := range events {
+		if !ev.Timestamp.Before(since) {
+			recent = append(recent, ev)
+		}
+	}
+	return recent, nil
+}
+
+// newestLogFor finds the newest log of agentType for workDir modified at or
+// after since, using the same lookup as the agent's adapter.
+func newestLogFor(agentType, workDir string, since time.Time) (string, bool, error) {
+	workDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return "", false, fmt.Errorf("resolving absolute path: %w", err)
+	}
+	switch agentType {
+	case "claudecode", "":
+		dir, err := claudeProjectDirFor(workDir)
+		if err != nil {
+			return "", false, err
+		}
+		path, ok := newestJSONLIn(dir, since)
+		return path, ok, nil
+	case "codex":
+		root, err := codexSessionsRoot()
+		if err != nil {
+			return "", false, err
+		}
+		headers := newHeaderCache(parseCodexHeader)
+		path, ok := newestOf(codexRollouts(root, since), since, func(path string) bool {
+			return headers.inWorkDir(path, workDir)
+		})
+		return path, ok, nil
+	case "copilot":
+		dir, err := copilotStateDir()
+		if err != nil {
+			return "", false, err
+		}
+		headers := newHeaderCache(parseCopilotHeader)
+		path, ok := newestOf(copilotSessionLogs(dir), since, func(path string) bool {
+			return headers.inWorkDir(path, workDir)
+		})
+		return path, ok, nil
+	default:
+		return "", false, fmt.Errorf("agent log format %q cannot be read incrementally", agentType)
+	}
+}
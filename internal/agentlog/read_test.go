@@ -0,0 +1,121 @@
+package agentlog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadLog_Incremental(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "0b0e6a4c-1111-2222-3333-444455556666.jsonl")
+	first := `{"type":"assistant","timestamp":"2026-03-01T10:00:00Z","message":{"role":"assistant","content":[{"type":"text","text":"hello"}]}}` + "\n"
+	partial := `{"type":"assistant","timestamp":"2026-03-01T10:01:00Z","message":{"role":"assistant","content":[{"type":"text","text":"world"}]}}`
+	if err := os.WriteFile(path, []byte(first+partial), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	events, offset, err := ReadLog("claudecode", path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Content != "hello" || offset != int64(len(first)) {
+		t.Fatalf("first read = %d events, offset %d; want 1 (hello), %d", len(events), offset, len(first))
+	}
+	if events[0].NativeSessionID != "0b0e6a4c-1111-2222-3333-444455556666" {
+		t.Errorf("NativeSessionID = %q", events[0].NativeSessionID)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString("\n")
+	f.Close()
+
+	events, offset2, err := ReadLog("claudecode", path, offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Content != "world" {
+		t.Fatalf("second read = %+v, want world", events)
+	}
+	if info, _ := os.Stat(path); offset2 != info.Size() {
+		t.Errorf("offset = %d, want file size %d", offset2, info.Size())
+	}
+}
+
+func TestReadLog_UnsupportedFormat(t *testing.T) {
+	if _, _, err := ReadLog("gemini", "/nonexistent", 0); err == nil {
+		t.Error("expected error for whole-file gemini format")
+	}
+}
+
+func TestRecentEvents(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	workDir := filepath.Join(home, "rig", "polecats", "toast")
+	projectDir, err := claudeProjectDirFor(workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	log := `{"type":"assistant","timestamp":"2026-03-01T09:00:00Z","message":{"role":"assistant","content":[{"type":"text","text":"old"}]}}` + "\n" +
+		`{"type":"assistant","timestamp":"2026-03-01T10:00:00Z","message":{"role":"assistant","content":[{"type":"text","text":"new"}]}}` + "\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "0b0e6a4c-1111-2222-3333-444455556666.jsonl"), []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	since := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	events, err := RecentEvents("claudecode", workDir, time.Time{})
+	if err != nil || len(events) != 2 {
+		t.Fatalf("all events = %d, %v; want 2", len(events), err)
+	}
+	events, err = RecentEvents("claudecode", workDir, since)
+	if err != nil || len(events) != 1 || events[0].Content != "new" {
+		t.Fatalf("recent events = %+v, %v; want only new", events, err)
+	}
+
+	events, err = RecentEvents("claudecode", filepath.Join(home, "elsewhere"), since)
+	if err != nil || len(events) != 0 {
+		t.Errorf("no log: events = %d, err = %v; want none", len(events), err)
+	}
+}
+
+func TestFindSessionLog_Codex(t *testing.T) {
+	codexHome := t.TempDir()
+	t.Setenv("CODEX_HOME", codexHome)
+	workDir := t.TempDir()
+	dayDir := filepath.Join(codexHome, codexSessionsDir, "2026", "03", "01")
+	if err := os.MkdirAll(dayDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	rollout := func(name, id, ts, cwd string) string {
+		path := filepath.Join(dayDir, name)
+		header := `{"timestamp":"` + ts + `","type":"session_meta","payload":{"id":"` + id + `","cwd":"` + cwd + `"}}` + "\n"
+		if err := os.WriteFile(path, []byte(header), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	morning := rollout("rollout-a.jsonl", "aaaa", "2026-03-01T09:00:00Z", workDir)
+	noon := rollout("rollout-b.jsonl", "bbbb", "2026-03-01T12:00:00Z", workDir)
+	rollout("rollout-c.jsonl", "cccc", "2026-03-01T12:00:30Z", filepath.Join(workDir, "other"))
+
+	if path, ok, err := FindSessionLog("codex", "aaaa", workDir, time.Time{}); err != nil || !ok || path != morning {
+		t.Errorf("by ID = %q, %v, %v; want %q", path, ok, err, morning)
+	}
+	started := time.Date(2026, 3, 1, 12, 0, 20, 0, time.UTC)
+	if path, ok, err := FindSessionLog("codex", "gt-session-123", workDir, started); err != nil || !ok || path != noon {
+		t.Errorf("by start = %q, %v, %v; want %q", path, ok, err, noon)
+	}
+	if _, ok, _ := FindSessionLog("codex", "", workDir, started.Add(2*time.Hour)); ok {
+		t.Error("a log outside the match window was accepted")
+	}
+	if _, _, err := FindSessionLog("gemini", "x", workDir, started); err == nil {
+		t.Error("expected error for whole-file gemini format")
+	}
+}
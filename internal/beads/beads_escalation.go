@@ -27,6 +27,7 @@ type EscalationFields struct {
 	ReescalationCount  int    // Number of times this has been re-escalated
 	LastReescalatedAt  string // When last re-escalated (empty if never)
 	LastReescalatedBy  string // Who last re-escalated (empty if never)
+	Incidents          string // Comma-separated incident notifiers with a remote incident open
 }
 
 
@@ -96,6 +97,9 @@ func FormatEscalationDescription(title string, fields *EscalationFields) string
 	} else {
 		lines = append(lines, "last_reescalated_by: null")
 	}
+	if fields.Incidents != "" {
+		lines = append(lines, fmt.Sprintf("incidents: %s", fields.Incidents))
+	}
 
 	return strings.Join(lines, "\n")
 }
@@ -152,6 +156,8 @@ func ParseEscalationFields(description string) *EscalationFields {
 			fields.LastReescalatedAt = value
 		case "last_reescalated_by":
 			fields.LastReescalatedBy = value
+		case "incidents":
+			fields.Incidents = value
 		}
 	}
 
@@ -264,6 +270,53 @@ func (b *Beads) CloseEscalation(id, closedBy, reason string) error {
 	return err
 }
 
+// IncidentNotifiers returns the incident notifier names recorded on the escalation.
+func (f *EscalationFields) IncidentNotifiers() []string {
+	var names []string
+	for _, name := range strings.Split(f.Incidents, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// AddEscalationIncidents records incident notifiers that opened a remote
+// incident for the escalation, so ack and close can update them.
+func (b *Beads) AddEscalationIncidents(id string, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	issue, err := b.Show(id)
+	if err != nil {
+		return err
+	}
+
+	fields := ParseEscalationFields(issue.Description)
+	existing := fields.IncidentNotifiers()
+	changed := false
+	for _, name := range names {
+		found := false
+		for _, e := range existing {
+			if e == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			existing = append(existing, name)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	fields.Incidents = strings.Join(existing, ",")
+
+	description := FormatEscalationDescription(issue.Title, fields)
+	return b.Update(id, UpdateOptions{Description: &description})
+}
+
 // GetEscalationBead retrieves an escalation bead by ID.
 // Returns nil if not found.
 func (b *Beads) GetEscalationBead(id string) (*Issue, *EscalationFields, error) {
@@ -257,6 +257,7 @@ func TestEscalationFieldsRoundTrip(t *testing.T) {
 		ReescalationCount: 1,
 		LastReescalatedAt: "2024-06-15T11:30:00Z",
 		LastReescalatedBy: "deacon",
+		Incidents:         "pager,ops",
 	}
 
 	formatted := FormatEscalationDescription("Escalation: Agent stuck", original)
@@ -298,6 +299,9 @@ func TestEscalationFieldsRoundTrip(t *testing.T) {
 	if parsed.LastReescalatedBy != original.LastReescalatedBy {
 		t.Errorf("LastReescalatedBy: got %q, want %q", parsed.LastReescalatedBy, original.LastReescalatedBy)
 	}
+	if got := parsed.IncidentNotifiers(); len(got) != 2 || got[0] != "pager" || got[1] != "ops" {
+		t.Errorf("IncidentNotifiers: got %v, want [pager ops]", got)
+	}
 }
 
 func TestBumpSeverity(t *testing.T) {
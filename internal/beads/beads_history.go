@@ -0,0 +1,331 @@
+package beads
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Time travel over a beads database's Dolt history.
+//
+// Every bd write is a Dolt commit, so the issues, labels and dependencies
+// tables can be read as they were at any past commit with AS OF, and the
+// dolt_diff_* system tables record every change. Wisps live in
+// dolt_ignored tables and have no history; these queries only see the
+// issues table.
+
+// DoltCommit is a commit in a beads database's Dolt history.
+type DoltCommit struct {
+	Hash      string `json:"commit_hash"`
+	Committer string `json:"committer"`
+	Date      string `json:"date"`
+	Message   string `json:"message"`
+}
+
+// ShortHash returns the first 8 characters of the commit hash.
+func (c *DoltCommit) ShortHash() string {
+	if len(c.Hash) > 8 {
+		return c.Hash[:8]
+	}
+	return c.Hash
+}
+
+// doltTimeLayout is the layout Dolt uses for DATETIME literals.
+const doltTimeLayout = "2006-01-02 15:04:05"
+
+// sqlQuote returns s as a single-quoted SQL string literal. Dolt, like
+// MySQL, treats backslash as an escape character inside string literals, so
+// backslashes are doubled as well as quotes; otherwise a trailing `\` would
+// escape the closing quote.
+func sqlQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// sqlRows runs a query through bd sql --json and returns the rows as maps.
+// An empty result set may come back as no output at all.
+func (b *Beads) sqlRows(query string) ([]map[string]any, error) {
+	out, err := b.run("sql", "--json", query)
+	if err != nil {
+		return nil, err
+	}
+	if !isJSONBytes(out) {
+		return nil, nil
+	}
+	var rows []map[string]any
+	if err := json.Unmarshal(out, &rows); err != nil {
+		return nil, fmt.Errorf("parsing bd sql output: %w", err)
+	}
+	return rows, nil
+}
+
+// sqlString renders a bd sql --json value as a string. NULL becomes "".
+func sqlString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// CommitAt returns the latest commit at or before t in this database's
+// history. Returns ErrNotFound if the database has no commit that old.
+func (b *Beads) CommitAt(t time.Time) (*DoltCommit, error) {
+	rows, err := b.sqlRows(fmt.Sprintf(
+		"SELECT commit_hash, committer, date, message FROM dolt_log WHERE date <= %s ORDER BY date DESC LIMIT 1",
+		sqlQuote(t.UTC().Format(doltTimeLayout))))
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrNotFound
+	}
+	return &DoltCommit{
+		Hash:      sqlString(rows[0]["commit_hash"]),
+		Committer: sqlString(rows[0]["committer"]),
+		Date:      sqlString(rows[0]["date"]),
+		Message:   sqlString(rows[0]["message"]),
+	}, nil
+}
+
+// AsOfFilter selects issues for ListAsOf. Empty fields match everything.
+type AsOfFilter struct {
+	Status   string // exact status, e.g. "open", "hooked"
+	Label    string // issues carrying this label
+	Assignee string
+}
+
+// asOfIssueColumns are the issue columns read by ListAsOf and ShowAsOf.
+const asOfIssueColumns = "i.id, i.title, i.description, i.status, i.priority, i.issue_type, " +
+	"i.assignee, i.created_at, i.updated_at, i.closed_at"
+
+// buildListAsOfQuery builds the ListAsOf query. Split out for testing.
+func buildListAsOfQuery(commit string, f AsOfFilter) string {
+	asOf := sqlQuote(commit)
+	var where []string
+	join := ""
+	if f.Label != "" {
+		join = fmt.Sprintf("JOIN labels AS OF %s AS fl ON fl.issue_id = i.id AND fl.label = %s ", asOf, sqlQuote(f.Label))
+	}
+	if f.Status != "" {
+		where = append(where, "i.status = "+sqlQuote(f.Status))
+	}
+	if f.Assignee != "" {
+		where = append(where, "i.assignee = "+sqlQuote(f.Assignee))
+	}
+	query := fmt.Sprintf("SELECT %s, GROUP_CONCAT(al.label) AS labels_csv "+
+		"FROM issues AS OF %s AS i %s"+
+		"LEFT JOIN labels AS OF %s AS al ON al.issue_id = i.id ",
+		asOfIssueColumns, asOf, join, asOf)
+	if len(where) > 0 {
+		query += "WHERE " + strings.Join(where, " AND ") + " "
+	}
+	return query + "GROUP BY " + asOfIssueColumns + " ORDER BY i.priority, i.id"
+}
+
+// ListAsOf returns the issues matching f as they were at commit, with labels.
+func (b *Beads) ListAsOf(commit string, f AsOfFilter) ([]*Issue, error) {
+	rows, err := b.sqlRows(buildListAsOfQuery(commit, f))
+	if err != nil {
+		return nil, err
+	}
+	issues := make([]*Issue, 0, len(rows))
+	for _, row := range rows {
+		issues = append(issues, issueFromRow(row))
+	}
+	return issues, nil
+}
+
+// CountByStatusAsOf returns the number of issues in each status at commit.
+func (b *Beads) CountByStatusAsOf(commit string) (map[string]int, error) {
+	rows, err := b.sqlRows(fmt.Sprintf(
+		"SELECT status, COUNT(*) AS n FROM issues AS OF %s GROUP BY status", sqlQuote(commit)))
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		n, _ := strconv.Atoi(sqlString(row["n"]))
+		counts[sqlString(row["status"])] = n
+	}
+	return counts, nil
+}
+
+// ShowAsOf returns issue id as it was at commit, with labels, dependencies
+// (issues it depends on) and dependents. Dependency entries carry only the
+// ID and dependency type. Returns ErrNotFound if the issue did not exist.
+func (b *Beads) ShowAsOf(commit, id string) (*Issue, error) {
+	asOf := sqlQuote(commit)
+	rows, err := b.sqlRows(fmt.Sprintf(
+		"SELECT %s, GROUP_CONCAT(al.label) AS labels_csv "+
+			"FROM issues AS OF %s AS i LEFT JOIN labels AS OF %s AS al ON al.issue_id = i.id "+
+			"WHERE i.id = %s GROUP BY %s",
+		asOfIssueColumns, asOf, asOf, sqlQuote(id), asOfIssueColumns))
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrNotFound
+	}
+	issue := issueFromRow(rows[0])
+
+	deps, err := b.sqlRows(fmt.Sprintf(
+		"SELECT issue_id, depends_on_id, type FROM dependencies AS OF %s WHERE issue_id = %s OR depends_on_id = %s ORDER BY type, issue_id, depends_on_id",
+		asOf, sqlQuote(id), sqlQuote(id)))
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deps {
+		typ := sqlString(d["type"])
+		if from := sqlString(d["issue_id"]); from == id {
+			issue.Dependencies = append(issue.Dependencies, IssueDep{ID: sqlString(d["depends_on_id"]), DependencyType: typ})
+		} else {
+			issue.Dependents = append(issue.Dependents, IssueDep{ID: from, DependencyType: typ})
+		}
+	}
+	return issue, nil
+}
+
+func issueFromRow(row map[string]any) *Issue {
+	priority, _ := strconv.Atoi(sqlString(row["priority"]))
+	issue := &Issue{
+		ID:          sqlString(row["id"]),
+		Title:       sqlString(row["title"]),
+		Description: sqlString(row["description"]),
+		Status:      sqlString(row["status"]),
+		Priority:    priority,
+		Type:        sqlString(row["issue_type"]),
+		Assignee:    sqlString(row["assignee"]),
+		CreatedAt:   sqlString(row["created_at"]),
+		UpdatedAt:   sqlString(row["updated_at"]),
+		ClosedAt:    sqlString(row["closed_at"]),
+	}
+	if csv := sqlString(row["labels_csv"]); csv != "" {
+		issue.Labels = strings.Split(csv, ",")
+		sort.Strings(issue.Labels)
+	}
+	return issue
+}
+
+// HistoryFields are the issue columns whose changes History reports.
+var HistoryFields = []string{
+	"title", "status", "priority", "issue_type", "assignee", "owner",
+	"description", "design", "acceptance_criteria", "notes", "close_reason",
+}
+
+// FieldChange is one field's change within a commit. For labels, Field is
+// "labels" and exactly one of From (removed) or To (added) is set.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+}
+
+// HistoryEntry is one commit that changed an issue.
+type HistoryEntry struct {
+	Commit    string        `json:"commit"`
+	Date      string        `json:"date"`
+	Committer string        `json:"committer,omitempty"`
+	Message   string        `json:"message,omitempty"`
+	Action    string        `json:"action"` // "created", "updated", "deleted"
+	Changes   []FieldChange `json:"changes"`
+}
+
+// History returns every committed change to issue id, oldest first: field
+// changes from dolt_diff_issues and label changes from dolt_diff_labels,
+// grouped by commit. Uncommitted changes appear with commit "WORKING".
+func (b *Beads) History(id string) ([]HistoryEntry, error) {
+	var cols []string
+	for _, f := range HistoryFields {
+		cols = append(cols, "d.from_"+f, "d.to_"+f)
+	}
+	issueRows, err := b.sqlRows(fmt.Sprintf(
+		"SELECT d.to_commit, d.to_commit_date, d.diff_type, l.committer, l.message, %s "+
+			"FROM dolt_diff_issues d LEFT JOIN dolt_log l ON l.commit_hash = d.to_commit "+
+			"WHERE d.to_id = %s OR d.from_id = %s ORDER BY d.to_commit_date",
+		strings.Join(cols, ", "), sqlQuote(id), sqlQuote(id)))
+	if err != nil {
+		return nil, err
+	}
+	labelRows, err := b.sqlRows(fmt.Sprintf(
+		"SELECT d.to_commit, d.to_commit_date, d.diff_type, l.committer, l.message, d.from_label, d.to_label "+
+			"FROM dolt_diff_labels d LEFT JOIN dolt_log l ON l.commit_hash = d.to_commit "+
+			"WHERE d.to_issue_id = %s OR d.from_issue_id = %s ORDER BY d.to_commit_date",
+		sqlQuote(id), sqlQuote(id)))
+	if err != nil {
+		return nil, err
+	}
+	return buildHistory(issueRows, labelRows), nil
+}
+
+// buildHistory merges dolt_diff_issues and dolt_diff_labels rows into one
+// entry per commit, oldest first.
+func buildHistory(issueRows, labelRows []map[string]any) []HistoryEntry {
+	byCommit := make(map[string]*HistoryEntry)
+	var order []string
+	entry := func(row map[string]any) *HistoryEntry {
+		commit := sqlString(row["to_commit"])
+		e := byCommit[commit]
+		if e == nil {
+			e = &HistoryEntry{
+				Commit:    commit,
+				Date:      sqlString(row["to_commit_date"]),
+				Committer: sqlString(row["committer"]),
+				Message:   sqlString(row["message"]),
+				Action:    "updated",
+			}
+			byCommit[commit] = e
+			order = append(order, commit)
+		}
+		return e
+	}
+
+	for _, row := range issueRows {
+		e := entry(row)
+		switch sqlString(row["diff_type"]) {
+		case "added":
+			e.Action = "created"
+		case "removed":
+			e.Action = "deleted"
+		}
+		for _, f := range HistoryFields {
+			from, to := sqlString(row["from_"+f]), sqlString(row["to_"+f])
+			if from != to {
+				e.Changes = append(e.Changes, FieldChange{Field: f, From: from, To: to})
+			}
+		}
+	}
+	for _, row := range labelRows {
+		e := entry(row)
+		from, to := sqlString(row["from_label"]), sqlString(row["to_label"])
+		if from != to {
+			e.Changes = append(e.Changes, FieldChange{Field: "labels", From: from, To: to})
+		}
+	}
+
+	entries := make([]HistoryEntry, 0, len(order))
+	for _, c := range order {
+		entries = append(entries, *byCommit[c])
+	}
+	// Stable so that a commit's issue and label rows keep their relative order
+	// when dates tie; WORKING (no commit date) sorts last.
+	sort.SliceStable(entries, func(i, j int) bool {
+		di, dj := entries[i].Date, entries[j].Date
+		if (di == "") != (dj == "") {
+			return dj == ""
+		}
+		return di < dj
+	})
+	return entries
+}
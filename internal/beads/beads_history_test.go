@@ -0,0 +1,115 @@
+package beads
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSQLString(t *testing.T) {
+	tests := []struct {
+		in   any
+		want string
+	}{
+		{nil, ""},
+		{"open", "open"},
+		{float64(2), "2"},
+		{1.5, "1.5"},
+		{true, "true"},
+	}
+	for _, tt := range tests {
+		if got := sqlString(tt.in); got != tt.want {
+			t.Errorf("sqlString(%#v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSQLQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"gt-abc", `'gt-abc'`},
+		{"o'brien", `'o''brien'`},
+		// A trailing backslash must not escape the closing quote.
+		{`x\`, `'x\\'`},
+		{`\' OR 1=1 -- `, `'\\'' OR 1=1 -- '`},
+	}
+	for _, tt := range tests {
+		if got := sqlQuote(tt.in); got != tt.want {
+			t.Errorf("sqlQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBuildListAsOfQuery(t *testing.T) {
+	q := buildListAsOfQuery("abc123", AsOfFilter{Status: "open", Label: "gt:merge-request", Assignee: "o'brien"})
+
+	for _, want := range []string{
+		"FROM issues AS OF 'abc123' AS i",
+		"JOIN labels AS OF 'abc123' AS fl ON fl.issue_id = i.id AND fl.label = 'gt:merge-request'",
+		"LEFT JOIN labels AS OF 'abc123' AS al",
+		"i.status = 'open' AND i.assignee = 'o''brien'",
+	} {
+		if !strings.Contains(q, want) {
+			t.Errorf("query missing %q:\n%s", want, q)
+		}
+	}
+
+	plain := buildListAsOfQuery("abc123", AsOfFilter{})
+	if strings.Contains(plain, "WHERE") || strings.Contains(plain, " fl ") {
+		t.Errorf("unfiltered query has filters:\n%s", plain)
+	}
+}
+
+func TestBuildHistory(t *testing.T) {
+	issueRows := []map[string]any{
+		{
+			"to_commit": "c2", "to_commit_date": "2026-03-01 11:00:00", "diff_type": "modified",
+			"committer": "gastown/polecats/Toast", "message": "bd: update",
+			"from_title": "Fix auth", "to_title": "Fix auth",
+			"from_status": "open", "to_status": "in_progress",
+			"from_priority": float64(2), "to_priority": float64(1),
+			"from_assignee": nil, "to_assignee": "gastown/polecats/Toast",
+		},
+		{
+			"to_commit": "c1", "to_commit_date": "2026-03-01 10:00:00", "diff_type": "added",
+			"committer": "mayor", "message": "bd: create",
+			"to_title": "Fix auth", "to_status": "open", "to_priority": float64(2),
+		},
+		{
+			"to_commit": "WORKING", "to_commit_date": nil, "diff_type": "modified",
+			"from_status": "in_progress", "to_status": "closed",
+		},
+	}
+	labelRows := []map[string]any{
+		{"to_commit": "c2", "to_commit_date": "2026-03-01 11:00:00", "diff_type": "added", "to_label": "urgent"},
+		{"to_commit": "c3", "to_commit_date": "2026-03-01 12:00:00", "diff_type": "removed", "from_label": "urgent"},
+	}
+
+	got := buildHistory(issueRows, labelRows)
+
+	var commits []string
+	for _, e := range got {
+		commits = append(commits, e.Commit)
+	}
+	if want := []string{"c1", "c2", "c3", "WORKING"}; !reflect.DeepEqual(commits, want) {
+		t.Fatalf("commits = %v, want %v", commits, want)
+	}
+
+	if got[0].Action != "created" || got[0].Committer != "mayor" {
+		t.Errorf("c1 = %+v, want created by mayor", got[0])
+	}
+	wantC2 := []FieldChange{
+		{Field: "status", From: "open", To: "in_progress"},
+		{Field: "priority", From: "2", To: "1"},
+		{Field: "assignee", To: "gastown/polecats/Toast"},
+		{Field: "labels", To: "urgent"},
+	}
+	if got[1].Action != "updated" || !reflect.DeepEqual(got[1].Changes, wantC2) {
+		t.Errorf("c2 changes = %+v, want %+v", got[1].Changes, wantC2)
+	}
+	if want := []FieldChange{{Field: "labels", From: "urgent"}}; !reflect.DeepEqual(got[2].Changes, want) {
+		t.Errorf("c3 changes = %+v, want %+v", got[2].Changes, want)
+	}
+}
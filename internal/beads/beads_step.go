@@ -0,0 +1,163 @@
+// Package beads provides molecule step execution-control fields.
+package beads
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Step outcomes recorded on molecule step beads that did not complete normally.
+const (
+	StepOutcomeSkipped  = "skipped"   // when/unless excluded the step
+	StepOutcomeFailed   = "failed"    // retries exhausted
+	StepOutcomeTimedOut = "timed_out" // final attempt exceeded the timeout
+)
+
+// StepFieldsHeader opens the block of execution-control fields on a step
+// bead description. The block runs to the next blank line; lines outside it
+// are instructions, never fields, even when they look like "When: ...".
+const StepFieldsHeader = "[step-controls]"
+
+// StepFields holds execution-control fields for a molecule step bead.
+// These are stored as "key: value" lines in a block headed by
+// StepFieldsHeader at the end of the description.
+type StepFields struct {
+	When      string // Condition over formula vars; step runs only if true
+	Unless    string // Condition over formula vars; step is skipped if true
+	Retries   int    // Extra attempts allowed after a failure or timeout
+	Timeout   string // Per-attempt time limit (Go duration, e.g. "45m")
+	Attempts  int    // Failed or timed-out attempts so far
+	StartedAt string // RFC 3339 start of the current attempt
+	Outcome   string // skipped, failed, timed_out (empty for normal completion)
+}
+
+// HasControls reports whether any when/unless/retries/timeout control is set.
+func (f *StepFields) HasControls() bool {
+	return f != nil && (f.When != "" || f.Unless != "" || f.Retries > 0 || f.Timeout != "")
+}
+
+// splitStepFieldsBlock splits a description into the lines outside the step
+// fields block and the lines inside it (header excluded).
+func splitStepFieldsBlock(description string) (other, block []string) {
+	inBlock := false
+	for _, line := range strings.Split(description, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == StepFieldsHeader:
+			inBlock = true
+		case inBlock && trimmed == "":
+			inBlock = false
+			other = append(other, line)
+		case inBlock:
+			block = append(block, trimmed)
+		default:
+			other = append(other, line)
+		}
+	}
+	return other, block
+}
+
+// ParseStepFields extracts step execution fields from the step fields block
+// of a step bead description. Always returns a non-nil value; missing fields
+// are zero.
+func ParseStepFields(description string) *StepFields {
+	fields := &StepFields{}
+	_, block := splitStepFieldsBlock(description)
+	for _, line := range block {
+		colonIdx := strings.Index(line, ":")
+		if colonIdx == -1 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(line[:colonIdx]))
+		value := strings.TrimSpace(line[colonIdx+1:])
+		if value == "" || value == "null" {
+			continue
+		}
+		switch key {
+		case "when":
+			fields.When = value
+		case "unless":
+			fields.Unless = value
+		case "retries":
+			if n, err := strconv.Atoi(value); err == nil {
+				fields.Retries = n
+			}
+		case "timeout":
+			fields.Timeout = value
+		case "attempts":
+			if n, err := strconv.Atoi(value); err == nil {
+				fields.Attempts = n
+			}
+		case "started_at":
+			fields.StartedAt = value
+		case "outcome":
+			fields.Outcome = value
+		}
+	}
+	return fields
+}
+
+// FormatStepFields formats StepFields as a block: StepFieldsHeader followed
+// by "key: value" lines. Only non-empty fields are included; returns "" if
+// there are none.
+func FormatStepFields(fields *StepFields) string {
+	if fields == nil {
+		return ""
+	}
+	var lines []string
+	if fields.When != "" {
+		lines = append(lines, "when: "+fields.When)
+	}
+	if fields.Unless != "" {
+		lines = append(lines, "unless: "+fields.Unless)
+	}
+	if fields.Retries > 0 {
+		lines = append(lines, fmt.Sprintf("retries: %d", fields.Retries))
+	}
+	if fields.Timeout != "" {
+		lines = append(lines, "timeout: "+fields.Timeout)
+	}
+	if fields.Attempts > 0 {
+		lines = append(lines, fmt.Sprintf("attempts: %d", fields.Attempts))
+	}
+	if fields.StartedAt != "" {
+		lines = append(lines, "started_at: "+fields.StartedAt)
+	}
+	if fields.Outcome != "" {
+		lines = append(lines, "outcome: "+fields.Outcome)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return StepFieldsHeader + "\n" + strings.Join(lines, "\n")
+}
+
+// SetStepFields returns issue's description with its step fields block
+// replaced by fields. Instructions and other lines are preserved; the block
+// is written at the end.
+func SetStepFields(issue *Issue, fields *StepFields) string {
+	var otherLines []string
+	if issue != nil && issue.Description != "" {
+		otherLines, _ = splitStepFieldsBlock(issue.Description)
+	}
+
+	for len(otherLines) > 0 && strings.TrimSpace(otherLines[len(otherLines)-1]) == "" {
+		otherLines = otherLines[:len(otherLines)-1]
+	}
+
+	formatted := FormatStepFields(fields)
+	if formatted == "" {
+		return strings.Join(otherLines, "\n")
+	}
+	if len(otherLines) == 0 {
+		return formatted
+	}
+	return strings.Join(otherLines, "\n") + "\n\n" + formatted
+}
+
+// UpdateStepFields rewrites the step field lines of a step bead.
+func (b *Beads) UpdateStepFields(issue *Issue, fields *StepFields) error {
+	description := SetStepFields(issue, fields)
+	return b.Update(issue.ID, UpdateOptions{Description: &description})
+}
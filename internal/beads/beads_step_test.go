@@ -0,0 +1,104 @@
+package beads
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseStepFields(t *testing.T) {
+	desc := `Run the flaky integration suite.
+
+instantiated_from: gt-mol-abc
+step: integration
+
+[step-controls]
+when: e2e == "true"
+retries: 2
+timeout: 45m
+attempts: 1
+started_at: 2026-01-02T12:00:00Z
+outcome: null`
+
+	fields := ParseStepFields(desc)
+	if fields.When != `e2e == "true"` {
+		t.Errorf("When = %q", fields.When)
+	}
+	if fields.Retries != 2 || fields.Attempts != 1 {
+		t.Errorf("Retries/Attempts = %d/%d, want 2/1", fields.Retries, fields.Attempts)
+	}
+	if fields.Timeout != "45m" || fields.StartedAt != "2026-01-02T12:00:00Z" {
+		t.Errorf("Timeout/StartedAt = %q/%q", fields.Timeout, fields.StartedAt)
+	}
+	if fields.Outcome != "" {
+		t.Errorf("Outcome = %q, want empty for null", fields.Outcome)
+	}
+	if !fields.HasControls() {
+		t.Error("HasControls() = false, want true")
+	}
+	if (&StepFields{Attempts: 3}).HasControls() {
+		t.Error("HasControls() = true for runtime-only fields")
+	}
+}
+
+func TestSetStepFields_RoundTrip(t *testing.T) {
+	issue := &Issue{
+		ID: "gt-abc.1",
+		Description: `Build everything.
+
+instantiated_from: gt-mol
+
+[step-controls]
+retries: 1
+attempts: 0`,
+	}
+
+	fields := ParseStepFields(issue.Description)
+	fields.Attempts = 2
+	fields.Outcome = StepOutcomeFailed
+	got := SetStepFields(issue, fields)
+
+	if !strings.HasPrefix(got, "Build everything.\n\ninstantiated_from: gt-mol\n\n[step-controls]\n") {
+		t.Errorf("instructions/provenance not preserved:\n%s", got)
+	}
+	if strings.Count(got, "retries:") != 1 || strings.Count(got, "attempts:") != 1 {
+		t.Errorf("step field lines duplicated:\n%s", got)
+	}
+	parsed := ParseStepFields(got)
+	if parsed.Retries != 1 || parsed.Attempts != 2 || parsed.Outcome != StepOutcomeFailed {
+		t.Errorf("round trip = %+v", parsed)
+	}
+}
+
+func TestSetStepFields_EmptyFieldsStripsLines(t *testing.T) {
+	issue := &Issue{Description: "Do it.\n\n[step-controls]\nstarted_at: 2026-01-02T12:00:00Z"}
+	if got := SetStepFields(issue, &StepFields{}); got != "Do it." {
+		t.Errorf("SetStepFields = %q, want %q", got, "Do it.")
+	}
+}
+
+func TestStepFields_InstructionsLookingLikeFields(t *testing.T) {
+	issue := &Issue{Description: `Check the deploy window.
+When: the window is open, deploy.
+Timeout: ask the on-call if unsure.
+
+[step-controls]
+timeout: 30m`}
+
+	fields := ParseStepFields(issue.Description)
+	if fields.When != "" || fields.Timeout != "30m" {
+		t.Errorf("ParseStepFields = %+v, want only timeout 30m", fields)
+	}
+
+	fields.StartedAt = "2026-01-02T12:00:00Z"
+	got := SetStepFields(issue, fields)
+	want := `Check the deploy window.
+When: the window is open, deploy.
+Timeout: ask the on-call if unsure.
+
+[step-controls]
+timeout: 30m
+started_at: 2026-01-02T12:00:00Z`
+	if got != want {
+		t.Errorf("SetStepFields =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -768,6 +768,28 @@ func TestMRFieldsRoundTrip(t *testing.T) {
 	}
 }
 
+func TestMRFieldsSpeculationRoundTrip(t *testing.T) {
+	original := &MRFields{
+		Branch:             "polecat/Nux/gt-xyz",
+		Target:             "main",
+		SpeculationDepth:   3,
+		SpeculationResult:  "passed",
+		SpeculationElapsed: "19m32s",
+		SpeculatedAt:       "2026-01-02T03:04:05Z",
+	}
+
+	issue := &Issue{Description: "Some notes\n\nspeculation_result: failed"}
+	issue.Description = SetMRFields(issue, original)
+	if strings.Count(issue.Description, "speculation_result") != 1 {
+		t.Errorf("stale speculation_result line not replaced:\n%s", issue.Description)
+	}
+
+	parsed := ParseMRFields(issue)
+	if !reflect.DeepEqual(parsed, original) {
+		t.Errorf("round-trip mismatch:\ngot  %+v\nwant %+v", parsed, original)
+	}
+}
+
 // TestParseMRFieldsFromDesignDoc tests the example from the design doc.
 func TestParseMRFieldsFromDesignDoc(t *testing.T) {
 	// Example from docs/merge-queue-design.md
@@ -594,6 +594,13 @@ type MRFields struct {
 	PreVerified     bool   // Polecat ran full gates after rebasing onto target
 	PreVerifiedAt   string // ISO 8601 timestamp when verification completed
 	PreVerifiedBase string // Target branch SHA at verification time
+
+	// Speculative pipeline fields: the refinery's most recent gate run on the
+	// speculative stack ending at this MR (target ← MR1 ← ... ← this MR).
+	SpeculationDepth   int    // Position of this MR in the speculative stack (1-based)
+	SpeculationResult  string // passed, failed, or error
+	SpeculationElapsed string // Gate wall-clock time (Go duration, e.g. "19m32s")
+	SpeculatedAt       string // ISO 8601 timestamp when the gates finished
 }
 
 // ParseMRFields extracts structured merge-request fields from an issue's description.
@@ -680,6 +687,20 @@ func ParseMRFields(issue *Issue) *MRFields {
 		case "pre_verified_base", "pre-verified-base", "preverifiedbase":
 			fields.PreVerifiedBase = value
 			hasFields = true
+		case "speculation_depth", "speculation-depth", "speculationdepth":
+			if n, err := parseIntField(value); err == nil {
+				fields.SpeculationDepth = n
+				hasFields = true
+			}
+		case "speculation_result", "speculation-result", "speculationresult":
+			fields.SpeculationResult = value
+			hasFields = true
+		case "speculation_elapsed", "speculation-elapsed", "speculationelapsed":
+			fields.SpeculationElapsed = value
+			hasFields = true
+		case "speculated_at", "speculated-at", "speculatedat":
+			fields.SpeculatedAt = value
+			hasFields = true
 		}
 	}
 
@@ -756,6 +777,18 @@ func FormatMRFields(fields *MRFields) string {
 	if fields.PreVerifiedBase != "" {
 		lines = append(lines, "pre_verified_base: "+fields.PreVerifiedBase)
 	}
+	if fields.SpeculationDepth > 0 {
+		lines = append(lines, fmt.Sprintf("speculation_depth: %d", fields.SpeculationDepth))
+	}
+	if fields.SpeculationResult != "" {
+		lines = append(lines, "speculation_result: "+fields.SpeculationResult)
+	}
+	if fields.SpeculationElapsed != "" {
+		lines = append(lines, "speculation_elapsed: "+fields.SpeculationElapsed)
+	}
+	if fields.SpeculatedAt != "" {
+		lines = append(lines, "speculated_at: "+fields.SpeculatedAt)
+	}
 
 	return strings.Join(lines, "\n")
 }
@@ -770,47 +803,59 @@ func SetMRFields(issue *Issue, fields *MRFields) string {
 
 	// Known MR field keys (lowercase)
 	mrKeys := map[string]bool{
-		"branch":             true,
-		"target":             true,
-		"source_issue":       true,
-		"source-issue":       true,
-		"sourceissue":        true,
-		"worker":             true,
-		"rig":                true,
-		"merge_commit":       true,
-		"merge-commit":       true,
-		"mergecommit":        true,
-		"close_reason":       true,
-		"close-reason":       true,
-		"closereason":        true,
-		"agent_bead":         true,
-		"agent-bead":         true,
-		"agentbead":          true,
-		"retry_count":        true,
-		"retry-count":        true,
-		"retrycount":         true,
-		"last_conflict_sha":  true,
-		"last-conflict-sha":  true,
-		"lastconflictsha":    true,
-		"conflict_task_id":   true,
-		"conflict-task-id":   true,
-		"conflicttaskid":     true,
-		"convoy_id":          true,
-		"convoy-id":          true,
-		"convoyid":           true,
-		"convoy":             true,
-		"convoy_created_at":  true,
-		"convoy-created-at":  true,
-		"convoycreatedat":    true,
-		"pre_verified":       true,
-		"pre-verified":       true,
-		"preverified":        true,
-		"pre_verified_at":    true,
-		"pre-verified-at":    true,
-		"preverifiedat":      true,
-		"pre_verified_base":  true,
-		"pre-verified-base":  true,
-		"preverifiedbase":    true,
+		"branch":              true,
+		"target":              true,
+		"source_issue":        true,
+		"source-issue":        true,
+		"sourceissue":         true,
+		"worker":              true,
+		"rig":                 true,
+		"merge_commit":        true,
+		"merge-commit":        true,
+		"mergecommit":         true,
+		"close_reason":        true,
+		"close-reason":        true,
+		"closereason":         true,
+		"agent_bead":          true,
+		"agent-bead":          true,
+		"agentbead":           true,
+		"retry_count":         true,
+		"retry-count":         true,
+		"retrycount":          true,
+		"last_conflict_sha":   true,
+		"last-conflict-sha":   true,
+		"lastconflictsha":     true,
+		"conflict_task_id":    true,
+		"conflict-task-id":    true,
+		"conflicttaskid":      true,
+		"convoy_id":           true,
+		"convoy-id":           true,
+		"convoyid":            true,
+		"convoy":              true,
+		"convoy_created_at":   true,
+		"convoy-created-at":   true,
+		"convoycreatedat":     true,
+		"pre_verified":        true,
+		"pre-verified":        true,
+		"preverified":         true,
+		"pre_verified_at":     true,
+		"pre-verified-at":     true,
+		"preverifiedat":       true,
+		"pre_verified_base":   true,
+		"pre-verified-base":   true,
+		"preverifiedbase":     true,
+		"speculation_depth":   true,
+		"speculation-depth":   true,
+		"speculationdepth":    true,
+		"speculation_result":  true,
+		"speculation-result":  true,
+		"speculationresult":   true,
+		"speculation_elapsed": true,
+		"speculation-elapsed": true,
+		"speculationelapsed":  true,
+		"speculated_at":       true,
+		"speculated-at":       true,
+		"speculatedat":        true,
 	}
 
 	// Collect non-MR lines from existing description
@@ -21,6 +21,10 @@ type MoleculeStep struct {
 	Tier         string         // Optional tier hint: haiku, sonnet, opus
 	Type         string         // Step type: "task" (default), "wait", etc.
 	Backoff      *BackoffConfig // Backoff configuration for wait-type steps
+	When         string         // Run only if this condition over formula vars holds
+	Unless       string         // Skip if this condition over formula vars holds
+	Retries      int            // Extra attempts allowed after a failure or timeout
+	Timeout      string         // Per-attempt time limit (e.g., "45m")
 }
 
 // BackoffConfig defines exponential backoff parameters for wait-type steps.
@@ -52,6 +56,16 @@ var typeLineRegex = regexp.MustCompile(`(?i)^Type:\s*(\w+)\s*$`)
 // Parses backoff configuration for wait-type steps.
 var backoffLineRegex = regexp.MustCompile(`(?i)^Backoff:\s*(.+)$`)
 
+// whenLineRegex / unlessLineRegex match "When: <expr>" and "Unless: <expr>" lines.
+var whenLineRegex = regexp.MustCompile(`(?i)^When:\s*(.+)$`)
+var unlessLineRegex = regexp.MustCompile(`(?i)^Unless:\s*(.+)$`)
+
+// retriesLineRegex matches "Retries: N" lines.
+var retriesLineRegex = regexp.MustCompile(`(?i)^Retries:\s*(\d+)\s*$`)
+
+// timeoutLineRegex matches "Timeout: 45m" lines (Go duration syntax).
+var timeoutLineRegex = regexp.MustCompile(`(?i)^Timeout:\s*(\S+)\s*$`)
+
 // templateVarRegex matches {{variable}} placeholders.
 var templateVarRegex = regexp.MustCompile(`\{\{(\w+)\}\}`)
 
@@ -65,6 +79,10 @@ var templateVarRegex = regexp.MustCompile(`\{\{(\w+)\}\}`)
 //	Tier: haiku|sonnet|opus  # optional
 //	Type: task|wait  # optional, default is "task"
 //	Backoff: base=30s, multiplier=2, max=10m  # optional, for wait-type steps
+//	When: <condition>  # optional, run only if the condition holds
+//	Unless: <condition>  # optional, skip if the condition holds
+//	Retries: 2  # optional, extra attempts after a failure
+//	Timeout: 45m  # optional, per-attempt time limit
 //
 // Returns an empty slice if no steps are found.
 func ParseMoleculeSteps(description string) ([]MoleculeStep, error) {
@@ -130,6 +148,28 @@ func ParseMoleculeSteps(description string) ([]MoleculeStep, error) {
 				continue
 			}
 
+			// Check for When:/Unless: lines
+			if matches := whenLineRegex.FindStringSubmatch(trimmed); matches != nil {
+				currentStep.When = strings.TrimSpace(matches[1])
+				continue
+			}
+			if matches := unlessLineRegex.FindStringSubmatch(trimmed); matches != nil {
+				currentStep.Unless = strings.TrimSpace(matches[1])
+				continue
+			}
+
+			// Check for Retries: line
+			if matches := retriesLineRegex.FindStringSubmatch(trimmed); matches != nil {
+				currentStep.Retries, _ = strconv.Atoi(matches[1])
+				continue
+			}
+
+			// Check for Timeout: line
+			if matches := timeoutLineRegex.FindStringSubmatch(trimmed); matches != nil {
+				currentStep.Timeout = matches[1]
+				continue
+			}
+
 			// Regular instruction line
 			instructionLines = append(instructionLines, line)
 		}
@@ -411,6 +451,14 @@ func (b *Beads) instantiateFromMarkdown(ctx context.Context, mol *Issue, parent
 		if step.Tier != "" {
 			description += fmt.Sprintf("\ntier: %s", step.Tier)
 		}
+		if controls := FormatStepFields(&StepFields{
+			When:    step.When,
+			Unless:  step.Unless,
+			Retries: step.Retries,
+			Timeout: step.Timeout,
+		}); controls != "" {
+			description += "\n\n" + controls
+		}
 
 		// Create the child issue
 		childOpts := CreateOptions{
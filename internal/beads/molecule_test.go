@@ -143,6 +143,40 @@ Tier: opus`
 	}
 }
 
+func TestParseMoleculeSteps_WithExecutionControls(t *testing.T) {
+	desc := `## Step: flaky-build
+Build the project.
+Retries: 2
+Timeout: 45m
+
+## Step: lint
+Run the linter.
+Needs: flaky-build
+When: lint && env != "dev"
+Unless: skip_lint`
+
+	steps, err := ParseMoleculeSteps(desc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(steps) != 2 {
+		t.Fatalf("expected 2 steps, got %d", len(steps))
+	}
+
+	if steps[0].Retries != 2 || steps[0].Timeout != "45m" {
+		t.Errorf("step[0] retries/timeout = %d/%q, want 2/45m", steps[0].Retries, steps[0].Timeout)
+	}
+	if steps[0].Instructions != "Build the project." {
+		t.Errorf("step[0].Instructions = %q, control lines should be stripped", steps[0].Instructions)
+	}
+	if steps[1].When != `lint && env != "dev"` {
+		t.Errorf("step[1].When = %q", steps[1].When)
+	}
+	if steps[1].Unless != "skip_lint" {
+		t.Errorf("step[1].Unless = %q", steps[1].Unless)
+	}
+}
+
 func TestParseMoleculeSteps_WithWaitsFor(t *testing.T) {
 	desc := `## Step: survey
 Discover work items.
@@ -15,7 +15,6 @@ import (
 	"github.com/gofrs/flock"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/session"
-	"github.com/steveyegge/gastown/internal/tmux"
 )
 
 // MarkerFileName is the lock file for Boot startup coordination.
@@ -38,7 +37,7 @@ type Boot struct {
 	townRoot   string
 	bootDir    string // ~/gt/deacon/dogs/boot/
 	deaconDir  string // ~/gt/deacon/
-	tmux       *tmux.Tmux
+	sessions   session.SessionBackend
 	degraded   bool
 	lockHandle *flock.Flock // held during triage execution
 }
@@ -49,7 +48,7 @@ func New(townRoot string) *Boot {
 		townRoot:  townRoot,
 		bootDir:   filepath.Join(townRoot, "deacon", "dogs", "boot"),
 		deaconDir: filepath.Join(townRoot, "deacon"),
-		tmux:      tmux.NewTmux(),
+		sessions:  session.NewBackend(townRoot),
 		degraded:  os.Getenv("GT_DEGRADED") == "true",
 	}
 }
@@ -75,9 +74,9 @@ func (b *Boot) IsRunning() bool {
 	return b.IsSessionAlive()
 }
 
-// IsSessionAlive checks if the Boot tmux session exists.
+// IsSessionAlive checks if the Boot session exists.
 func (b *Boot) IsSessionAlive() bool {
-	has, err := b.tmux.HasSession(session.BootSessionName())
+	has, err := b.sessions.HasSession(session.BootSessionName())
 	return err == nil && has
 }
 
@@ -169,7 +168,7 @@ func (b *Boot) Spawn(agentOverride string) error {
 func (b *Boot) spawnTmux(agentOverride string) error {
 	// Kill any stale session first (Boot is ephemeral).
 	if b.IsSessionAlive() {
-		_ = b.tmux.KillSessionWithProcesses(session.BootSessionName())
+		_ = b.sessions.KillSessionWithProcesses(session.BootSessionName())
 	}
 
 	// Ensure boot directory exists (it should have CLAUDE.md with Boot context)
@@ -178,7 +177,7 @@ func (b *Boot) spawnTmux(agentOverride string) error {
 	}
 
 	// Use unified session lifecycle for config → settings → command → create → env.
-	_, err := session.StartSession(b.tmux, session.SessionConfig{
+	_, err := session.StartSession(b.sessions, session.SessionConfig{
 		SessionID: session.BootSessionName(),
 		WorkDir:   b.bootDir,
 		Role:      "boot",
@@ -229,7 +228,7 @@ func (b *Boot) DeaconDir() string {
 	return b.deaconDir
 }
 
-// Tmux returns the tmux manager.
-func (b *Boot) Tmux() *tmux.Tmux {
-	return b.tmux
+// Sessions returns the session backend Boot runs on.
+func (b *Boot) Sessions() session.SessionBackend {
+	return b.sessions
 }
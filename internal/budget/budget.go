@@ -0,0 +1,223 @@
+// Package budget evaluates daily spend against the cost budgets configured
+// in town and rig settings.
+//
+// Evaluation is pure: callers collect spend samples (from the costs log and
+// live session transcripts) and the configured limits, and Evaluate reports
+// where each limit stands. Enforcement lives with the caller (gt costs budget
+// check); the results are persisted in State so the scheduler and gt vitals
+// can read them without recomputing.
+package budget
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+)
+
+// Scope is what a budget limit applies to.
+type Scope string
+
+const (
+	ScopeRig    Scope = "rig"
+	ScopeRole   Scope = "role"
+	ScopeConvoy Scope = "convoy"
+	ScopeBead   Scope = "bead"
+)
+
+// Level is where spend stands against a limit.
+type Level string
+
+const (
+	LevelOK   Level = "ok"
+	LevelWarn Level = "warn" // at or above the soft limit
+	LevelOver Level = "over" // at or above the hard limit
+)
+
+// rank orders levels so escalation can be detected.
+func (l Level) rank() int {
+	switch l {
+	case LevelWarn:
+		return 1
+	case LevelOver:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Limit is one configured budget.
+type Limit struct {
+	Scope Scope  `json:"scope"`
+	Key   string `json:"key"` // rig name, role, convoy ID or bead ID
+
+	// Rig narrows a role limit to one rig (role limits from rig settings).
+	Rig string `json:"rig,omitempty"`
+
+	SoftUSD    float64 `json:"soft_usd,omitempty"`
+	HardUSD    float64 `json:"hard_usd,omitempty"`
+	HardAction string  `json:"hard_action"`
+}
+
+// ID identifies the limit in state and output, e.g. "rig:gastown",
+// "role:polecat", "role:gastown/polecat" or "bead:gt-abc".
+func (l Limit) ID() string {
+	if l.Rig != "" {
+		return fmt.Sprintf("%s:%s/%s", l.Scope, l.Rig, l.Key)
+	}
+	return fmt.Sprintf("%s:%s", l.Scope, l.Key)
+}
+
+// Spend is the cost of one session today.
+type Spend struct {
+	Session string  `json:"session"`
+	Rig     string  `json:"rig,omitempty"`
+	Role    string  `json:"role"`
+	Bead    string  `json:"bead,omitempty"` // work item the session was on
+	USD     float64 `json:"usd"`
+	Live    bool    `json:"live,omitempty"` // session is still running
+}
+
+// Status is where spend stands against one limit.
+type Status struct {
+	Limit
+	SpentUSD float64 `json:"spent_usd"`
+	Level    Level   `json:"level"`
+
+	// Sessions lists the live sessions charged to this limit; they are the
+	// ones wound down at a hard limit.
+	Sessions []string `json:"sessions,omitempty"`
+}
+
+// Limits flattens town and per-rig budget settings into individual limits.
+// rigs maps every rig name to its budgets (nil when it has none), so the
+// town's default rig limit reaches rigs without their own settings.
+func Limits(town *config.BudgetsConfig, rigs map[string]*config.BudgetsConfig) []Limit {
+	if town == nil {
+		town = &config.BudgetsConfig{}
+	}
+	townAction := hardAction(town.HardAction, config.BudgetActionHandoff)
+
+	var limits []Limit
+	add := func(scope Scope, key, rig string, l *config.BudgetLimit, action string) {
+		if l == nil || (l.SoftUSD <= 0 && l.HardUSD <= 0) {
+			return
+		}
+		limits = append(limits, Limit{
+			Scope: scope, Key: key, Rig: rig,
+			SoftUSD: max(l.SoftUSD, 0), HardUSD: max(l.HardUSD, 0),
+			HardAction: action,
+		})
+	}
+
+	for role, l := range town.Roles {
+		add(ScopeRole, role, "", l, townAction)
+	}
+	for id, l := range town.Convoys {
+		add(ScopeConvoy, id, "", l, townAction)
+	}
+	for id, l := range town.Beads {
+		add(ScopeBead, id, "", l, townAction)
+	}
+
+	for rig, rb := range rigs {
+		if rb == nil {
+			rb = &config.BudgetsConfig{}
+		}
+		action := hardAction(rb.HardAction, townAction)
+		rigLimit := town.Rig
+		if rb.Rig != nil {
+			rigLimit = rb.Rig
+		}
+		add(ScopeRig, rig, "", rigLimit, action)
+		for role, l := range rb.Roles {
+			add(ScopeRole, role, rig, l, action)
+		}
+		// Convoy and bead IDs are town-wide; town settings win on conflict.
+		for id, l := range rb.Convoys {
+			if town.Convoys[id] == nil {
+				add(ScopeConvoy, id, "", l, action)
+			}
+		}
+		for id, l := range rb.Beads {
+			if town.Beads[id] == nil {
+				add(ScopeBead, id, "", l, action)
+			}
+		}
+	}
+
+	sort.Slice(limits, func(i, j int) bool { return limits[i].ID() < limits[j].ID() })
+	return limits
+}
+
+func hardAction(action, fallback string) string {
+	if action == "" {
+		return fallback
+	}
+	return action
+}
+
+// Evaluate totals spend against each limit. convoys maps convoy IDs to the
+// beads they track; a convoy is charged for sessions on any of them.
+func Evaluate(limits []Limit, spends []Spend, convoys map[string][]string) []Status {
+	statuses := make([]Status, 0, len(limits))
+	for _, l := range limits {
+		st := Status{Limit: l, Level: LevelOK}
+		var tracked map[string]bool
+		if l.Scope == ScopeConvoy {
+			tracked = make(map[string]bool, len(convoys[l.Key]))
+			for _, id := range convoys[l.Key] {
+				tracked[id] = true
+			}
+		}
+		for _, s := range spends {
+			if !l.charges(s, tracked) {
+				continue
+			}
+			st.SpentUSD += s.USD
+			if s.Live {
+				st.Sessions = append(st.Sessions, s.Session)
+			}
+		}
+		switch {
+		case l.HardUSD > 0 && st.SpentUSD >= l.HardUSD:
+			st.Level = LevelOver
+		case l.SoftUSD > 0 && st.SpentUSD >= l.SoftUSD:
+			st.Level = LevelWarn
+		}
+		sort.Strings(st.Sessions)
+		statuses = append(statuses, st)
+	}
+	return statuses
+}
+
+// charges reports whether a session's spend counts against the limit.
+func (l Limit) charges(s Spend, tracked map[string]bool) bool {
+	switch l.Scope {
+	case ScopeRig:
+		return s.Rig == l.Key
+	case ScopeRole:
+		return s.Role == l.Key && (l.Rig == "" || s.Rig == l.Rig)
+	case ScopeBead:
+		return s.Bead != "" && s.Bead == l.Key
+	case ScopeConvoy:
+		return s.Bead != "" && tracked[s.Bead]
+	}
+	return false
+}
+
+// BlocksDispatch reports whether an over-budget status stops new polecats
+// from being dispatched to rig.
+func (s Status) BlocksDispatch(rig string) bool {
+	if s.Level != LevelOver {
+		return false
+	}
+	switch s.Scope {
+	case ScopeRig:
+		return s.Key == rig
+	case ScopeRole:
+		return s.Key == constants.RolePolecat && (s.Rig == "" || s.Rig == rig)
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestLimits(t *testing.T) {
+	town := &config.BudgetsConfig{
+		Rig:        &config.BudgetLimit{SoftUSD: 50, HardUSD: 100},
+		Roles:      map[string]*config.BudgetLimit{"polecat": {HardUSD: 200}},
+		Beads:      map[string]*config.BudgetLimit{"gt-1": {HardUSD: 5}},
+		HardAction: config.BudgetActionHandoff,
+	}
+	rigs := map[string]*config.BudgetsConfig{
+		"gastown": {
+			Rig:        &config.BudgetLimit{HardUSD: 20},
+			Roles:      map[string]*config.BudgetLimit{"crew": {SoftUSD: 10}},
+			Beads:      map[string]*config.BudgetLimit{"gt-1": {HardUSD: 99}},
+			HardAction: config.BudgetActionPark,
+		},
+		"beads": nil,
+	}
+
+	got := map[string]Limit{}
+	for _, l := range Limits(town, rigs) {
+		got[l.ID()] = l
+	}
+	want := map[string]struct {
+		soft, hard float64
+		action     string
+	}{
+		"rig:beads":         {50, 100, config.BudgetActionHandoff},
+		"rig:gastown":       {0, 20, config.BudgetActionPark},
+		"role:polecat":      {0, 200, config.BudgetActionHandoff},
+		"role:gastown/crew": {10, 0, config.BudgetActionPark},
+		"bead:gt-1":         {0, 5, config.BudgetActionHandoff},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Limits() = %v, want %d limits", got, len(want))
+	}
+	for id, w := range want {
+		l, ok := got[id]
+		if !ok {
+			t.Errorf("missing limit %s", id)
+			continue
+		}
+		if l.SoftUSD != w.soft || l.HardUSD != w.hard || l.HardAction != w.action {
+			t.Errorf("%s = soft %.0f hard %.0f %s, want soft %.0f hard %.0f %s",
+				id, l.SoftUSD, l.HardUSD, l.HardAction, w.soft, w.hard, w.action)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	limits := []Limit{
+		{Scope: ScopeRig, Key: "gastown", SoftUSD: 10, HardUSD: 20},
+		{Scope: ScopeRole, Key: "polecat", Rig: "beads", HardUSD: 5},
+		{Scope: ScopeBead, Key: "gt-1", SoftUSD: 3},
+		{Scope: ScopeConvoy, Key: "hq-cv1", HardUSD: 8},
+	}
+	spends := []Spend{
+		{Session: "gt-toast", Rig: "gastown", Role: "polecat", Bead: "gt-1", USD: 12, Live: true},
+		{Session: "gt-nux", Rig: "gastown", Role: "polecat", Bead: "gt-2", USD: 1},
+		{Session: "bd-max", Rig: "beads", Role: "polecat", Bead: "bd-9", USD: 2, Live: true},
+		{Session: "bd-crew-joe", Rig: "beads", Role: "crew", USD: 40, Live: true},
+	}
+	convoys := map[string][]string{"hq-cv1": {"gt-2", "bd-9"}}
+
+	got := Evaluate(limits, spends, convoys)
+	want := []struct {
+		spent    float64
+		level    Level
+		sessions int
+	}{
+		{13, LevelWarn, 1},
+		{2, LevelOK, 1},
+		{12, LevelWarn, 1},
+		{3, LevelOK, 1},
+	}
+	for i, w := range want {
+		if got[i].SpentUSD != w.spent || got[i].Level != w.level || len(got[i].Sessions) != w.sessions {
+			t.Errorf("%s = $%.2f %s %v, want $%.2f %s with %d sessions",
+				got[i].ID(), got[i].SpentUSD, got[i].Level, got[i].Sessions, w.spent, w.level, w.sessions)
+		}
+	}
+
+	spends[0].USD = 30
+	if got := Evaluate(limits[:1], spends, nil); got[0].Level != LevelOver {
+		t.Errorf("rig at $31 of $20 hard = %s, want over", got[0].Level)
+	}
+}
+
+func TestBlocksDispatch(t *testing.T) {
+	tests := []struct {
+		st   Status
+		rig  string
+		want bool
+	}{
+		{Status{Limit: Limit{Scope: ScopeRig, Key: "gastown"}, Level: LevelOver}, "gastown", true},
+		{Status{Limit: Limit{Scope: ScopeRig, Key: "gastown"}, Level: LevelOver}, "beads", false},
+		{Status{Limit: Limit{Scope: ScopeRig, Key: "gastown"}, Level: LevelWarn}, "gastown", false},
+		{Status{Limit: Limit{Scope: ScopeRole, Key: "polecat"}, Level: LevelOver}, "beads", true},
+		{Status{Limit: Limit{Scope: ScopeRole, Key: "polecat", Rig: "gastown"}, Level: LevelOver}, "beads", false},
+		{Status{Limit: Limit{Scope: ScopeRole, Key: "crew"}, Level: LevelOver}, "beads", false},
+		{Status{Limit: Limit{Scope: ScopeBead, Key: "gt-1"}, Level: LevelOver}, "gastown", false},
+	}
+	for _, tt := range tests {
+		if got := tt.st.BlocksDispatch(tt.rig); got != tt.want {
+			t.Errorf("%s %s BlocksDispatch(%s) = %v, want %v", tt.st.ID(), tt.st.Level, tt.rig, got, tt.want)
+		}
+	}
+}
+
+func TestStateRecord(t *testing.T) {
+	day1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+	warn := Status{Limit: Limit{Scope: ScopeRig, Key: "gastown"}, Level: LevelWarn}
+	over := Status{Limit: Limit{Scope: ScopeRig, Key: "gastown"}, Level: LevelOver}
+
+	s := &State{}
+	if raised := s.Record(day1, []Status{warn}); len(raised) != 1 {
+		t.Fatalf("first warning raised %d, want 1", len(raised))
+	}
+	if raised := s.Record(day1.Add(time.Minute), []Status{warn}); len(raised) != 0 {
+		t.Errorf("repeated warning raised %d, want 0", len(raised))
+	}
+	if raised := s.Record(day1.Add(2*time.Minute), []Status{over}); len(raised) != 1 {
+		t.Errorf("warn -> over raised %d, want 1", len(raised))
+	}
+	if _, blocked := s.DispatchBlocked("gastown", day1); !blocked {
+		t.Error("over-budget rig not blocked")
+	}
+	if !s.MarkWoundDown("gt-toast", "rig:gastown") || s.MarkWoundDown("gt-toast", "rig:gastown") {
+		t.Error("MarkWoundDown should succeed once per session")
+	}
+
+	day2 := day1.AddDate(0, 0, 1)
+	if _, blocked := s.DispatchBlocked("gastown", day2); blocked {
+		t.Error("yesterday's state blocked today's dispatch")
+	}
+	if raised := s.Record(day2, []Status{warn}); len(raised) != 1 {
+		t.Errorf("new day warning raised %d, want 1", len(raised))
+	}
+	if !s.MarkWoundDown("gt-toast", "rig:gastown") {
+		t.Error("wound-down sessions should reset on a new day")
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	townRoot := t.TempDir()
+	s, err := LoadState(townRoot)
+	if err != nil || s.Day != "" {
+		t.Fatalf("LoadState(empty) = %+v, %v", s, err)
+	}
+	now := time.Now()
+	s.Record(now, []Status{{Limit: Limit{Scope: ScopeBead, Key: "gt-1", HardUSD: 5}, SpentUSD: 6, Level: LevelOver}})
+	if err := SaveState(townRoot, s); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadState(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Current(now) || len(loaded.Statuses) != 1 || loaded.Notified["bead:gt-1"] != LevelOver {
+		t.Errorf("round trip = %+v", loaded)
+	}
+}
@@ -0,0 +1,138 @@
+package budget
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// dayFormat keys state by local day; budgets reset at local midnight.
+const dayFormat = "2006-01-02"
+
+// State is the result of the last budget check.
+// Stored at <townRoot>/.runtime/budget-state.json.
+type State struct {
+	Day       string    `json:"day"`
+	CheckedAt time.Time `json:"checked_at"`
+	Statuses  []Status  `json:"statuses,omitempty"`
+
+	// Notified records the highest level already acted on today, per limit
+	// ID, so a warning is sent once rather than every heartbeat.
+	Notified map[string]Level `json:"notified,omitempty"`
+
+	// WoundDown records sessions already asked to stop today, so sessions
+	// that start later in an over-budget scope are still reached.
+	WoundDown map[string]string `json:"wound_down,omitempty"`
+}
+
+func stateFile(townRoot string) string {
+	return filepath.Join(townRoot, ".runtime", "budget-state.json")
+}
+
+// LoadState loads the last budget check, returning an empty state if no
+// check has run.
+func LoadState(townRoot string) (*State, error) {
+	data, err := os.ReadFile(stateFile(townRoot)) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &State{}, nil
+		}
+		return nil, err
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// SaveState writes the budget state atomically.
+func SaveState(townRoot string, state *State) error {
+	path := stateFile(townRoot)
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".budget-state-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// Current reports whether the state describes now's day. Yesterday's
+// over-budget results never block today's dispatch.
+func (s *State) Current(now time.Time) bool {
+	return s.Day == now.Format(dayFormat)
+}
+
+// Record stores a fresh evaluation and returns the statuses whose level rose
+// above what was already acted on today. A new day starts from scratch.
+func (s *State) Record(now time.Time, statuses []Status) []Status {
+	if !s.Current(now) {
+		s.Day = now.Format(dayFormat)
+		s.Notified = nil
+		s.WoundDown = nil
+	}
+	if s.Notified == nil {
+		s.Notified = make(map[string]Level)
+	}
+	s.CheckedAt = now
+	s.Statuses = statuses
+
+	var raised []Status
+	for _, st := range statuses {
+		id := st.ID()
+		if st.Level.rank() > s.Notified[id].rank() {
+			raised = append(raised, st)
+			s.Notified[id] = st.Level
+		}
+	}
+	return raised
+}
+
+// MarkWoundDown records that session was asked to stop for limitID and
+// reports whether it had not been already.
+func (s *State) MarkWoundDown(session, limitID string) bool {
+	if _, done := s.WoundDown[session]; done {
+		return false
+	}
+	if s.WoundDown == nil {
+		s.WoundDown = make(map[string]string)
+	}
+	s.WoundDown[session] = limitID
+	return true
+}
+
+// DispatchBlocked returns the over-budget limit that stops new polecats from
+// being dispatched to rig today, if any.
+func (s *State) DispatchBlocked(rig string, now time.Time) (string, bool) {
+	if !s.Current(now) {
+		return "", false
+	}
+	for _, st := range s.Statuses {
+		if st.BlocksDispatch(rig) {
+			return st.ID(), true
+		}
+	}
+	return "", false
+}
@@ -1,8 +1,10 @@
-// Package channelevents provides file-based event emission for named channels.
+// Package channelevents provides event emission for named channels.
 //
-// Channel events are JSON files written to ~/gt/events/<channel>/*.event
-// and consumed by await-event subscribers (e.g., the refinery watching for
-// MERGE_READY events). This is distinct from the activity feed events in
+// Channel events are published on the daemon's event bus as
+// channel.<name> and consumed by await-event subscribers (e.g., the refinery
+// watching for MERGE_READY events). When the daemon is not running they are
+// written as JSON files to ~/gt/events/<channel>/*.event instead, which
+// await-event also drains. This is distinct from the activity feed events in
 // the events package (~/gt/.events.jsonl).
 package channelevents
 
@@ -16,6 +18,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/steveyegge/gastown/internal/eventbus"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -26,8 +29,10 @@ var ValidChannelName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
 // time.Now().UnixNano() has low resolution.
 var emitSeq atomic.Uint64
 
-// Emit creates an event file in the channel directory, resolving the town
-// root from the current working directory.
+// Emit emits an event on the channel, resolving the town root from the
+// current working directory. It returns where the event went: a bus
+// reference of the form eventbus:channel.<name>#<seq>, or the path of the
+// event file written when the bus is not running.
 func Emit(channel, eventType string, payloadPairs []string) (string, error) {
 	if !ValidChannelName.MatchString(channel) {
 		return "", fmt.Errorf("invalid channel name %q: must match [a-zA-Z0-9_-]", channel)
@@ -38,34 +43,32 @@ func Emit(channel, eventType string, payloadPairs []string) (string, error) {
 		home, _ := os.UserHomeDir()
 		townRoot = filepath.Join(home, "gt")
 	}
-	eventDir := filepath.Join(townRoot, "events", channel)
-	if err := os.MkdirAll(eventDir, 0755); err != nil {
-		return "", fmt.Errorf("creating event directory: %w", err)
-	}
-
-	return emitToDir(eventDir, channel, eventType, payloadPairs)
+	return EmitToTown(townRoot, channel, eventType, payloadPairs)
 }
 
-// EmitToTown creates an event file using an explicit town root.
+// EmitToTown emits an event using an explicit town root.
 // Used by internal callers that already know the town root.
 func EmitToTown(townRoot, channel, eventType string, payloadPairs []string) (string, error) {
 	if !ValidChannelName.MatchString(channel) {
 		return "", fmt.Errorf("invalid channel name %q: must match [a-zA-Z0-9_-]", channel)
 	}
 
+	now := time.Now()
+	event := newEvent(channel, eventType, payloadPairs, now)
+	topic := eventbus.ChannelTopic(channel)
+	if seq, err := eventbus.NewClient(eventbus.SocketPath(townRoot)).Publish(topic, event); err == nil {
+		return fmt.Sprintf("eventbus:%s#%d", topic, seq), nil
+	}
+
 	eventDir := filepath.Join(townRoot, "events", channel)
 	if err := os.MkdirAll(eventDir, 0755); err != nil {
 		return "", fmt.Errorf("creating event directory: %w", err)
 	}
-	return emitToDir(eventDir, channel, eventType, payloadPairs)
+	return emitToDir(eventDir, event, now)
 }
 
-// emitToDir writes an event file to the given directory.
-func emitToDir(eventDir, channel, eventType string, payloadPairs []string) (string, error) {
-	if !ValidChannelName.MatchString(channel) {
-		return "", fmt.Errorf("invalid channel name %q: must match [a-zA-Z0-9_-]", channel)
-	}
-
+// newEvent builds the JSON object for a channel event.
+func newEvent(channel, eventType string, payloadPairs []string, now time.Time) map[string]interface{} {
 	payload := make(map[string]string)
 	for _, pair := range payloadPairs {
 		key, val, found := strings.Cut(pair, "=")
@@ -74,14 +77,16 @@ func emitToDir(eventDir, channel, eventType string, payloadPairs []string) (stri
 		}
 	}
 
-	now := time.Now()
-	event := map[string]interface{}{
+	return map[string]interface{}{
 		"type":      eventType,
 		"channel":   channel,
 		"timestamp": now.Format(time.RFC3339),
 		"payload":   payload,
 	}
+}
 
+// emitToDir writes an event file to the given directory.
+func emitToDir(eventDir string, event map[string]interface{}, now time.Time) (string, error) {
 	data, err := json.MarshalIndent(event, "", "  ")
 	if err != nil {
 		return "", fmt.Errorf("marshaling event: %w", err)
@@ -1,11 +1,15 @@
 package channelevents
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/eventbus"
 )
 
 func TestEmitToTown(t *testing.T) {
@@ -113,3 +117,51 @@ func TestEmitToTown_CreatesDirectory(t *testing.T) {
 		t.Errorf("channel dir should exist after emit: %v", err)
 	}
 }
+
+func TestEmitToTown_PublishesToRunningBus(t *testing.T) {
+	// Unix socket paths are limited to ~100 bytes, so avoid t.TempDir().
+	townRoot, err := os.MkdirTemp("", "gtchan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(townRoot)
+
+	bus, err := eventbus.Open(eventbus.Dir(townRoot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bus.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = eventbus.Serve(ctx, bus, eventbus.SocketPath(townRoot)) }()
+
+	var ref string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		if ref, err = EmitToTown(townRoot, "refinery", "MERGE_READY", []string{"polecat=nux"}); err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasPrefix(ref, "eventbus:") {
+			break
+		}
+		_ = os.RemoveAll(filepath.Join(townRoot, "events"))
+	}
+	if ref != "eventbus:channel.refinery#1" {
+		t.Fatalf("ref = %q, want eventbus:channel.refinery#1", ref)
+	}
+	if _, err := os.Stat(filepath.Join(townRoot, "events", "refinery")); !os.IsNotExist(err) {
+		t.Error("no event file should be written when the bus takes the event")
+	}
+
+	msgs, err := bus.Subscribe(ctx, eventbus.SubscribeOptions{From: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := <-msgs
+	var event map[string]interface{}
+	if err := json.Unmarshal(m.Data, &event); err != nil {
+		t.Fatal(err)
+	}
+	if event["type"] != "MERGE_READY" || event["payload"].(map[string]interface{})["polecat"] != "nux" {
+		t.Errorf("event = %v", event)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -29,7 +30,7 @@ var agentLogCmd = &cobra.Command{
 func init() {
 	agentLogCmd.Flags().StringVar(&agentLogSession, "session", "", "Gas Town tmux session name (used as log tag)")
 	agentLogCmd.Flags().StringVar(&agentLogWorkDir, "work-dir", "", "Agent working directory (used to locate conversation log files)")
-	agentLogCmd.Flags().StringVar(&agentLogAgentType, "agent", "claudecode", "Agent type (claudecode, opencode)")
+	agentLogCmd.Flags().StringVar(&agentLogAgentType, "agent", "claudecode", "Log format of the agent (claudecode, codex, copilot, gemini, opencode)")
 	agentLogCmd.Flags().StringVar(&agentLogSince, "since", "", "Only watch JSONL files modified at or after this RFC3339 timestamp (filters out pre-existing Claude sessions)")
 	agentLogCmd.Flags().StringVar(&agentLogRunID, "run-id", "", "GASTA run identifier (GT_RUN); injected into every agent.event for waterfall correlation")
 	_ = agentLogCmd.MarkFlagRequired("session")
@@ -73,7 +74,7 @@ func runAgentLog(cmd *cobra.Command, args []string) error {
 
 	adapter := agentlog.NewAdapter(agentLogAgentType)
 	if adapter == nil {
-		return fmt.Errorf("unknown agent type %q; supported: claudecode, opencode", agentLogAgentType)
+		return fmt.Errorf("unknown agent type %q; supported: %s", agentLogAgentType, strings.Join(agentlog.AdapterNames(), ", "))
 	}
 
 	ch, err := adapter.Watch(ctx, agentLogSession, agentLogWorkDir, since)
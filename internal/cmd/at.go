@@ -0,0 +1,447 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var atJSON bool
+
+var atCmd = &cobra.Command{
+	Use:     "at <time> <command> [args]",
+	GroupID: GroupDiag,
+	Short:   "Show town state as it was at a past time",
+	Long: `Show town state as it was at a past time, read from Dolt history.
+
+Every bd write is a Dolt commit, so each beads database can be read as of
+any past commit. gt at finds, for each database involved, the last commit
+at or before <time> and runs a read-only view against it.
+
+Supported commands:
+  status                  Bead counts per database and what was on each hook
+  convoy status <id>      A convoy and the state of its tracked issues
+  mq list <rig>           Merge requests in a rig's queue
+  hook status <agent>     What was on an agent's hook
+  bead show <id>          A bead's fields, labels and dependencies
+
+<time> is an RFC 3339 timestamp, "YYYY-MM-DD HH:MM", "YYYY-MM-DD" (local
+time), or a duration ago ("90m", "6h", "2d").
+
+Wisps (ephemeral beads, including most merge requests and hooked molecule
+steps) are not versioned in Dolt, so they do not appear in past state.
+
+Examples:
+  gt at 2h status
+  gt at "2026-03-01 14:00" convoy status hq-cv-abc
+  gt at 1d mq list gastown
+  gt at 30m hook status gastown/polecats/Toast
+  gt at 2026-03-01 bead show gt-abc123 --json
+
+See also: gt bead history <id>`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runAt,
+}
+
+func init() {
+	atCmd.Flags().BoolVar(&atJSON, "json", false, "Output as JSON")
+	rootCmd.AddCommand(atCmd)
+}
+
+// parseAtTime parses the <time> argument of gt at.
+func parseAtTime(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if d, err := parseDuration(s); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use RFC 3339, \"YYYY-MM-DD HH:MM\", YYYY-MM-DD, or a duration ago (6h, 2d)", s)
+}
+
+// atSnapshot resolves each beads database to its commit at a point in time.
+type atSnapshot struct {
+	townRoot string
+	at       time.Time
+	commits  map[string]*beads.DoltCommit // by workDir; nil = no history that old
+}
+
+func newAtSnapshot(townRoot string, at time.Time) *atSnapshot {
+	return &atSnapshot{townRoot: townRoot, at: at, commits: make(map[string]*beads.DoltCommit)}
+}
+
+// commit returns the commit of the database at workDir as of s.at, or nil
+// if the database has no history that old.
+func (s *atSnapshot) commit(workDir string) (*beads.DoltCommit, error) {
+	if c, ok := s.commits[workDir]; ok {
+		return c, nil
+	}
+	c, err := beads.New(workDir).CommitAt(s.at)
+	if errors.Is(err, beads.ErrNotFound) {
+		c, err = nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading Dolt history in %s: %w", workDir, err)
+	}
+	s.commits[workDir] = c
+	return c, nil
+}
+
+// dirForBead returns the workDir of the database that owns beadID.
+func (s *atSnapshot) dirForBead(beadID string) string {
+	return beads.ResolveHookDir(s.townRoot, beadID, s.townRoot)
+}
+
+// dirForRig returns the workDir of a rig's beads database.
+func (s *atSnapshot) dirForRig(rig string) string {
+	if rig == "" || rig == "mayor" || rig == "deacon" {
+		return s.townRoot
+	}
+	prefix := beads.GetPrefixForRig(s.townRoot, rig)
+	if dir := beads.GetRigPathForPrefix(s.townRoot, prefix+"-"); dir != "" {
+		return dir
+	}
+	return filepath.Join(s.townRoot, rig, "mayor", "rig")
+}
+
+// show returns beadID as of s.at, or nil if it did not exist yet.
+func (s *atSnapshot) show(beadID string) (*beads.Issue, *beads.DoltCommit, error) {
+	dir := s.dirForBead(beadID)
+	c, err := s.commit(dir)
+	if err != nil || c == nil {
+		return nil, c, err
+	}
+	issue, err := beads.New(dir).ShowAsOf(c.Hash, beadID)
+	if errors.Is(err, beads.ErrNotFound) {
+		return nil, c, nil
+	}
+	return issue, c, err
+}
+
+func runAt(cmd *cobra.Command, args []string) error {
+	at, err := parseAtTime(args[0], time.Now())
+	if err != nil {
+		return err
+	}
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return err
+	}
+	s := newAtSnapshot(townRoot, at)
+
+	rest := args[1:]
+	switch {
+	case len(rest) == 1 && rest[0] == "status":
+		return runAtStatus(s)
+	case len(rest) == 3 && rest[0] == "convoy" && rest[1] == "status":
+		return runAtConvoyStatus(s, rest[2])
+	case len(rest) == 3 && rest[0] == "mq" && rest[1] == "list":
+		return runAtMQList(s, rest[2])
+	case len(rest) == 3 && rest[0] == "hook" && (rest[1] == "status" || rest[1] == "show"):
+		return runAtHookStatus(s, rest[2])
+	case len(rest) == 3 && rest[0] == "bead" && (rest[1] == "show" || rest[1] == "read"):
+		return runAtBeadShow(s, rest[2])
+	}
+	return fmt.Errorf("gt at does not support %q\n\nSupported: status, convoy status <id>, mq list <rig>, hook status <agent>, bead show <id>",
+		strings.Join(rest, " "))
+}
+
+// printAtHeader prints which point in history a view shows.
+func printAtHeader(s *atSnapshot, what string, c *beads.DoltCommit) {
+	fmt.Printf("%s as of %s\n", style.Bold.Render(what), s.at.Local().Format("2006-01-02 15:04:05 MST"))
+	if c != nil {
+		fmt.Printf("%s\n\n", style.Dim.Render(fmt.Sprintf("Dolt commit %s (%s, %s)", c.ShortHash(), c.Date, c.Committer)))
+	} else {
+		fmt.Println()
+	}
+}
+
+func encodeAtJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// atDatabase is one beads database's state in gt at status.
+type atDatabase struct {
+	Prefix string            `json:"prefix"`
+	Path   string            `json:"path"`
+	Commit *beads.DoltCommit `json:"commit,omitempty"`
+	Counts map[string]int    `json:"counts,omitempty"`
+	Hooked []*beads.Issue    `json:"hooked,omitempty"`
+}
+
+func runAtStatus(s *atSnapshot) error {
+	routes, err := beads.LoadRoutes(beads.GetTownBeadsPath(s.townRoot))
+	if err != nil {
+		return fmt.Errorf("loading routes: %w", err)
+	}
+	if len(routes) == 0 {
+		routes = []beads.Route{{Prefix: "hq-", Path: "."}}
+	}
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Path < routes[j].Path })
+
+	var dbs []atDatabase
+	for _, r := range routes {
+		dir := filepath.Join(s.townRoot, r.Path)
+		db := atDatabase{Prefix: r.Prefix, Path: r.Path}
+		c, err := s.commit(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s %v\n", style.Warning.Render("⚠"), err)
+			continue
+		}
+		db.Commit = c
+		if c != nil {
+			b := beads.New(dir)
+			if db.Counts, err = b.CountByStatusAsOf(c.Hash); err != nil {
+				return err
+			}
+			if db.Hooked, err = b.ListAsOf(c.Hash, beads.AsOfFilter{Status: beads.StatusHooked}); err != nil {
+				return err
+			}
+		}
+		dbs = append(dbs, db)
+	}
+
+	if atJSON {
+		return encodeAtJSON(struct {
+			At        time.Time    `json:"at"`
+			Databases []atDatabase `json:"databases"`
+		}{s.at, dbs})
+	}
+
+	printAtHeader(s, "Town status", nil)
+	for _, db := range dbs {
+		if db.Commit == nil {
+			fmt.Printf("  %-6s %-28s %s\n", db.Prefix, db.Path, style.Dim.Render("(no history that old)"))
+			continue
+		}
+		fmt.Printf("  %-6s %-28s %s  open %d, in progress %d, hooked %d, closed %d\n",
+			db.Prefix, db.Path, style.Dim.Render(db.Commit.ShortHash()),
+			db.Counts["open"], db.Counts["in_progress"], db.Counts[beads.StatusHooked], db.Counts["closed"])
+	}
+
+	var hooked []*beads.Issue
+	for _, db := range dbs {
+		hooked = append(hooked, db.Hooked...)
+	}
+	if len(hooked) > 0 {
+		sort.Slice(hooked, func(i, j int) bool { return hooked[i].Assignee < hooked[j].Assignee })
+		fmt.Printf("\n%s\n", style.Bold.Render("Hooks"))
+		for _, h := range hooked {
+			fmt.Printf("  %-32s %s  %s\n", h.Assignee, h.ID, h.Title)
+		}
+	}
+	return nil
+}
+
+// atTracked is one convoy-tracked issue in gt at convoy status.
+type atTracked struct {
+	ID       string `json:"id"`
+	Title    string `json:"title,omitempty"`
+	Status   string `json:"status"`
+	Assignee string `json:"assignee,omitempty"`
+}
+
+func runAtConvoyStatus(s *atSnapshot, convoyID string) error {
+	convoy, c, err := s.show(convoyID)
+	if err != nil {
+		return err
+	}
+	if convoy == nil {
+		return fmt.Errorf("convoy %s did not exist at %s", convoyID, s.at.Format(time.RFC3339))
+	}
+
+	var tracked []atTracked
+	closed := 0
+	for _, dep := range convoy.Dependencies {
+		if dep.DependencyType != "tracks" {
+			continue
+		}
+		t := atTracked{ID: dep.ID, Status: "missing"}
+		issue, _, err := s.show(dep.ID)
+		if err != nil {
+			return err
+		}
+		if issue != nil {
+			t.Title, t.Status, t.Assignee = issue.Title, issue.Status, issue.Assignee
+		}
+		if t.Status == "closed" {
+			closed++
+		}
+		tracked = append(tracked, t)
+	}
+
+	if atJSON {
+		return encodeAtJSON(struct {
+			At      time.Time         `json:"at"`
+			Commit  *beads.DoltCommit `json:"commit"`
+			ID      string            `json:"id"`
+			Title   string            `json:"title"`
+			Status  string            `json:"status"`
+			Closed  int               `json:"closed"`
+			Tracked []atTracked       `json:"tracked"`
+		}{s.at, c, convoy.ID, convoy.Title, convoy.Status, closed, tracked})
+	}
+
+	printAtHeader(s, "Convoy "+convoy.ID, c)
+	fmt.Printf("  %s [%s]\n", convoy.Title, convoy.Status)
+	fmt.Printf("  Progress: %d/%d closed\n\n", closed, len(tracked))
+	for _, t := range tracked {
+		line := fmt.Sprintf("  %-14s %-12s %s", t.ID, t.Status, t.Title)
+		if t.Assignee != "" {
+			line += style.Dim.Render("  (" + t.Assignee + ")")
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+func runAtMQList(s *atSnapshot, rig string) error {
+	dir := s.dirForRig(rig)
+	c, err := s.commit(dir)
+	if err != nil {
+		return err
+	}
+	var mrs []*beads.Issue
+	if c != nil {
+		all, err := beads.New(dir).ListAsOf(c.Hash, beads.AsOfFilter{Label: "gt:merge-request"})
+		if err != nil {
+			return err
+		}
+		for _, mr := range all {
+			if mr.Status != "closed" && mr.Status != "tombstone" {
+				mrs = append(mrs, mr)
+			}
+		}
+	}
+
+	if atJSON {
+		return encodeAtJSON(struct {
+			At     time.Time         `json:"at"`
+			Commit *beads.DoltCommit `json:"commit,omitempty"`
+			Rig    string            `json:"rig"`
+			Queue  []*beads.Issue    `json:"queue"`
+		}{s.at, c, rig, mrs})
+	}
+
+	printAtHeader(s, "Merge queue "+rig, c)
+	if len(mrs) == 0 {
+		fmt.Println("  (empty)")
+		fmt.Println(style.Dim.Render("  Merge requests submitted as wisps are not versioned and never appear here."))
+		return nil
+	}
+	for _, mr := range mrs {
+		fmt.Printf("  %-14s P%d  %-12s %s\n", mr.ID, mr.Priority, mr.Status, mr.Title)
+	}
+	return nil
+}
+
+func runAtHookStatus(s *atSnapshot, agent string) error {
+	agent = strings.TrimSuffix(agent, "/")
+	rig := ""
+	if !isTownLevelRole(agent) {
+		rig = strings.SplitN(agent, "/", 2)[0]
+	}
+
+	// Like gt hook show: the agent's rig database first, then town beads,
+	// where convoys can be hooked.
+	dirs := []string{s.dirForRig(rig)}
+	if dirs[0] != s.townRoot {
+		dirs = append(dirs, s.townRoot)
+	}
+	var hooked []*beads.Issue
+	var c *beads.DoltCommit
+	for _, dir := range dirs {
+		dc, err := s.commit(dir)
+		if err != nil {
+			return err
+		}
+		if dc == nil {
+			continue
+		}
+		if c == nil {
+			c = dc
+		}
+		found, err := beads.New(dir).ListAsOf(dc.Hash, beads.AsOfFilter{Status: beads.StatusHooked, Assignee: agent})
+		if err != nil {
+			return err
+		}
+		hooked = append(hooked, found...)
+	}
+
+	if atJSON {
+		return encodeAtJSON(struct {
+			At     time.Time         `json:"at"`
+			Commit *beads.DoltCommit `json:"commit,omitempty"`
+			Agent  string            `json:"agent"`
+			Hooked []*beads.Issue    `json:"hooked"`
+		}{s.at, c, agent, hooked})
+	}
+
+	printAtHeader(s, "Hook "+agent, c)
+	if len(hooked) == 0 {
+		fmt.Println("  (empty)")
+		return nil
+	}
+	for _, h := range hooked {
+		fmt.Printf("  %s %s: %s\n", style.Bold.Render("🪝"), h.ID, h.Title)
+	}
+	return nil
+}
+
+func runAtBeadShow(s *atSnapshot, beadID string) error {
+	issue, c, err := s.show(beadID)
+	if err != nil {
+		return err
+	}
+	if issue == nil {
+		return fmt.Errorf("bead %s did not exist at %s", beadID, s.at.Format(time.RFC3339))
+	}
+
+	if atJSON {
+		return encodeAtJSON(struct {
+			At     time.Time         `json:"at"`
+			Commit *beads.DoltCommit `json:"commit"`
+			Issue  *beads.Issue      `json:"issue"`
+		}{s.at, c, issue})
+	}
+
+	printAtHeader(s, issue.ID, c)
+	fmt.Printf("  %s\n", style.Bold.Render(issue.Title))
+	fmt.Printf("  Status:   %s\n", issue.Status)
+	fmt.Printf("  Priority: P%d\n", issue.Priority)
+	fmt.Printf("  Type:     %s\n", issue.Type)
+	if issue.Assignee != "" {
+		fmt.Printf("  Assignee: %s\n", issue.Assignee)
+	}
+	fmt.Printf("  Updated:  %s\n", issue.UpdatedAt)
+	if len(issue.Labels) > 0 {
+		fmt.Printf("  Labels:   %s\n", strings.Join(issue.Labels, ", "))
+	}
+	for _, d := range issue.Dependencies {
+		fmt.Printf("  → %s (%s)\n", d.ID, d.DependencyType)
+	}
+	for _, d := range issue.Dependents {
+		fmt.Printf("  ← %s (%s)\n", d.ID, d.DependencyType)
+	}
+	if issue.Description != "" {
+		fmt.Printf("\n%s\n", issue.Description)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+func TestParseAtTime(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"2026-03-01T14:00:00Z", time.Date(2026, 3, 1, 14, 0, 0, 0, time.UTC), false},
+		{"2026-03-01 14:00:05", time.Date(2026, 3, 1, 14, 0, 5, 0, time.Local), false},
+		{"2026-03-01 14:00", time.Date(2026, 3, 1, 14, 0, 0, 0, time.Local), false},
+		{"2026-03-01T14:00", time.Date(2026, 3, 1, 14, 0, 0, 0, time.Local), false},
+		{"2026-03-01", time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local), false},
+		{"2h", now.Add(-2 * time.Hour), false},
+		{"1d", now.Add(-24 * time.Hour), false},
+		{"yesterday", time.Time{}, true},
+		{"0s", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseAtTime(tt.input, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAtTime(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("parseAtTime(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintBeadHistory(t *testing.T) {
+	entries := []beads.HistoryEntry{
+		{Commit: "abcdef0123456789", Date: "2026-03-01 10:00:00", Committer: "mayor", Action: "created",
+			Changes: []beads.FieldChange{{Field: "title", To: "Fix auth"}}},
+		{Commit: "WORKING", Action: "updated", Changes: []beads.FieldChange{
+			{Field: "status", From: "open", To: "closed"},
+			{Field: "labels", From: "urgent"},
+			{Field: "notes", To: strings.Repeat("x", 100)},
+		}},
+	}
+	var buf bytes.Buffer
+	printBeadHistory(&buf, "gt-abc", entries)
+	out := buf.String()
+
+	for _, want := range []string{
+		"2026-03-01 10:00:00",
+		"abcdef01",
+		"created by mayor",
+		"title: Fix auth",
+		"uncommitted",
+		"status: open → closed",
+		"labels: -urgent",
+		`notes: "" → ` + strings.Repeat("x", historyValueMax-3) + "...",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -25,6 +25,7 @@ prefix-based routing.
 
 Subcommands:
   move    Move a bead from one repository to another
+  history Show every change to a bead (from Dolt history)
   show    Show details of a bead (routes by prefix)
   read    Alias for show`,
 }
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var beadHistoryJSON bool
+
+var beadHistoryCmd = &cobra.Command{
+	Use:   "history <bead-id>",
+	Short: "Show every change to a bead from Dolt history",
+	Long: `Show every committed change to a bead, oldest first.
+
+Reads the Dolt diff tables of the bead's database (routed by prefix) and
+lists, for each commit that touched the bead, the commit, its date and
+committer, and each field that changed: title, status, priority, type,
+assignee, owner, description, design, acceptance criteria, notes, close
+reason, and labels added or removed.
+
+Wisps are not versioned in Dolt and have no history.
+
+Examples:
+  gt bead history gt-abc123
+  gt bead history hq-cv-xyz --json
+
+See also: gt at <time> bead show <id>`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBeadHistory,
+}
+
+func init() {
+	beadHistoryCmd.Flags().BoolVar(&beadHistoryJSON, "json", false, "Output as JSON")
+	beadCmd.AddCommand(beadHistoryCmd)
+}
+
+func runBeadHistory(cmd *cobra.Command, args []string) error {
+	beadID := args[0]
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return err
+	}
+
+	dir := beads.ResolveHookDir(townRoot, beadID, townRoot)
+	entries, err := beads.New(dir).History(beadID)
+	if err != nil {
+		return fmt.Errorf("reading history of %s: %w", beadID, err)
+	}
+
+	if beadHistoryJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("no history for %s (unknown bead, or a wisp)", beadID)
+	}
+	printBeadHistory(os.Stdout, beadID, entries)
+	return nil
+}
+
+// historyValueMax caps how much of a long field value (e.g. description)
+// is printed per change.
+const historyValueMax = 60
+
+// printBeadHistory writes one block per commit with its field changes.
+func printBeadHistory(w io.Writer, beadID string, entries []beads.HistoryEntry) {
+	fmt.Fprintf(w, "%s (%d commits)\n", style.Bold.Render("History of "+beadID), len(entries))
+	for _, e := range entries {
+		commit := e.Commit
+		if len(commit) > 8 {
+			commit = commit[:8]
+		}
+		date := e.Date
+		if date == "" {
+			date = "uncommitted"
+		}
+		by := ""
+		if e.Committer != "" {
+			by = " by " + e.Committer
+		}
+		fmt.Fprintf(w, "\n%s  %s  %s%s\n", date, style.Dim.Render(commit), e.Action, by)
+		for _, c := range e.Changes {
+			switch {
+			case c.Field == "labels" && c.To != "":
+				fmt.Fprintf(w, "    labels: +%s\n", c.To)
+			case c.Field == "labels":
+				fmt.Fprintf(w, "    labels: -%s\n", c.From)
+			case e.Action == "created":
+				fmt.Fprintf(w, "    %s: %s\n", c.Field, historyValue(c.To))
+			default:
+				fmt.Fprintf(w, "    %s: %s → %s\n", c.Field, historyValue(c.From), historyValue(c.To))
+			}
+		}
+	}
+}
+
+// historyValue formats a field value for one line of history output.
+func historyValue(v string) string {
+	if v == "" {
+		return `""`
+	}
+	v = strings.Join(strings.Fields(v), " ")
+	if len(v) > historyValueMax {
+		v = v[:historyValueMax-3] + "..."
+	}
+	return v
+}
@@ -14,7 +14,6 @@ import (
 	"github.com/steveyegge/gastown/internal/deacon"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
-	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -290,7 +289,7 @@ func runDegradedTriage(b *boot.Boot) (action, target string, err error) {
 		return "nothing", "shutdown-in-progress", nil
 	}
 
-	tm := b.Tmux()
+	tm := b.Sessions()
 
 	// Scan and execute pending death warrants. This is a side effect that runs
 	// before the normal triage decision — warrant execution is mechanical and
@@ -332,7 +331,7 @@ func runDegradedTriage(b *boot.Boot) (action, target string, err error) {
 // It is called as a side effect during degraded triage, before the normal
 // Deacon health decision is made. Errors are non-fatal: a failed execution is
 // logged and skipped rather than aborting triage.
-func executeWarrants(warrantDir string, tm *tmux.Tmux) {
+func executeWarrants(warrantDir string, tm session.SessionBackend) {
 	entries, err := os.ReadDir(warrantDir)
 	if err != nil {
 		if !os.IsNotExist(err) {
@@ -7,9 +7,11 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"time"
 
 	"github.com/gofrs/flock"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/budget"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/scheduler/capacity"
@@ -98,7 +100,7 @@ func dispatchScheduledWork(townRoot, actor string, batchOverride int, dryRun boo
 	polecatNames := make(map[string]string)
 	cycle := &capacity.DispatchCycle{
 		AvailableCapacity: func() (int, error) {
-			active := countActivePolecats()
+			active := countActivePolecats(townRoot)
 			cap := maxPolecats - active
 			if cap <= 0 {
 				return 0, nil // No free slots — PlanDispatch treats <= 0 as no capacity
@@ -106,7 +108,11 @@ func dispatchScheduledWork(townRoot, actor string, batchOverride int, dryRun boo
 			return cap, nil
 		},
 		QueryPending: func() ([]capacity.PendingBead, error) {
-			return getReadySlingContexts(townRoot)
+			pending, err := getReadySlingContexts(townRoot)
+			if err != nil {
+				return nil, err
+			}
+			return filterOverBudgetRigs(townRoot, pending), nil
 		},
 		Execute: func(b capacity.PendingBead) error {
 			result, err := dispatchSingleBead(b, townRoot, actor)
@@ -154,6 +160,13 @@ func dispatchScheduledWork(townRoot, actor string, batchOverride int, dryRun boo
 			}
 			recordDispatchFailure(townBeads, b, err)
 		},
+		FairShare: func() (capacity.FairShare, error) {
+			return capacity.FairShare{
+				Config: schedulerCfg,
+				Active: countActivePolecatsByRig(townRoot),
+				Now:    time.Now(),
+			}, nil
+		},
 		BatchSize:  batchSize,
 		SpawnDelay: spawnDelay,
 	}
@@ -163,7 +176,7 @@ func dispatchScheduledWork(townRoot, actor string, batchOverride int, dryRun boo
 		if planErr != nil {
 			return 0, fmt.Errorf("planning dispatch: %w", planErr)
 		}
-		printDryRunPlan(plan, maxPolecats, batchSize)
+		printDryRunPlan(townRoot, plan, maxPolecats, batchSize)
 		return 0, nil
 	}
 
@@ -199,13 +212,13 @@ func dispatchScheduledWork(townRoot, actor string, batchOverride int, dryRun boo
 }
 
 // printDryRunPlan displays a dry-run dispatch plan.
-func printDryRunPlan(plan capacity.DispatchPlan, maxPolecats, batchSize int) {
+func printDryRunPlan(townRoot string, plan capacity.DispatchPlan, maxPolecats, batchSize int) {
 	if plan.Reason == "none" {
 		fmt.Println("No ready beads scheduled for dispatch")
 		return
 	}
 
-	activePolecats := countActivePolecats()
+	activePolecats := countActivePolecats(townRoot)
 	capStr := "unlimited"
 	if maxPolecats > 0 {
 		cap := maxPolecats - activePolecats
@@ -224,7 +237,7 @@ func printDryRunPlan(plan capacity.DispatchPlan, maxPolecats, batchSize int) {
 	fmt.Printf("%s Would dispatch %d bead(s) (capacity: %s, batch: %d, ready: %d, reason: %s)\n",
 		style.Bold.Render("📋"), len(plan.ToDispatch), capStr, batchSize, totalReady, plan.Reason)
 	for _, b := range plan.ToDispatch {
-		fmt.Printf("  Would dispatch: %s → %s\n", b.WorkBeadID, b.TargetRig)
+		fmt.Printf("  Would dispatch: %s → %s (P%d)\n", b.WorkBeadID, b.TargetRig, b.Priority)
 	}
 }
 
@@ -343,9 +356,9 @@ func getReadySlingContexts(townRoot string) ([]capacity.PendingBead, error) {
 		return nil, nil
 	}
 
-	// 2. Build readyWorkIDs set from bd ready across all dirs
+	// 2. Build the ready work bead set (with priorities) from bd ready across all dirs
 	// (work beads live in rig-local DBs, so we need to check all dirs)
-	readyWorkIDs, readyErr := listReadyWorkBeadIDsWithError(townRoot)
+	readyWork, readyErr := listReadyWorkBeadsWithError(townRoot)
 	if readyErr != nil {
 		return nil, readyErr
 	}
@@ -380,7 +393,8 @@ func getReadySlingContexts(townRoot string) ([]capacity.PendingBead, error) {
 		}
 
 		// Only include if work bead is ready (unblocked)
-		if !readyWorkIDs[fields.WorkBeadID] {
+		priority, ready := readyWork[fields.WorkBeadID]
+		if !ready {
 			continue
 		}
 
@@ -397,6 +411,7 @@ func getReadySlingContexts(townRoot string) ([]capacity.PendingBead, error) {
 			TargetRig:   fields.TargetRig,
 			Description: ctx.Description,
 			Labels:      ctx.Labels,
+			Priority:    priority,
 			Context:     fields,
 		})
 	}
@@ -484,10 +499,14 @@ func listAllSlingContexts(townRoot string) ([]*beads.Issue, error) {
 	return townBeads.ListOpenSlingContexts()
 }
 
-// listReadyWorkBeadIDsWithError returns a set of work bead IDs that are unblocked.
-// Returns an error only when ALL dirs fail (partial success is acceptable).
-func listReadyWorkBeadIDsWithError(townRoot string) (map[string]bool, error) {
-	readyIDs := make(map[string]bool)
+// defaultBeadPriority is assumed for ready beads that report no priority.
+const defaultBeadPriority = 2
+
+// listReadyWorkBeadsWithError returns the priority of each unblocked work bead,
+// keyed by bead ID. Returns an error only when ALL dirs fail (partial success
+// is acceptable).
+func listReadyWorkBeadsWithError(townRoot string) (map[string]int, error) {
+	ready := make(map[string]int)
 	dirs := beadsSearchDirs(townRoot)
 	failCount := 0
 	var lastErr error
@@ -506,17 +525,35 @@ func listReadyWorkBeadIDsWithError(townRoot string) (map[string]bool, error) {
 			continue
 		}
 		var readyBeads []struct {
-			ID string `json:"id"`
+			ID       string `json:"id"`
+			Priority *int   `json:"priority"`
 		}
 		if err := json.Unmarshal(readyOut, &readyBeads); err == nil {
 			for _, b := range readyBeads {
-				readyIDs[b.ID] = true
+				ready[b.ID] = defaultBeadPriority
+				if b.Priority != nil {
+					ready[b.ID] = *b.Priority
+				}
 			}
 		}
 	}
 	if failCount == len(dirs) && failCount > 0 {
 		return nil, fmt.Errorf("all %d bd ready queries failed (last: %w)", failCount, lastErr)
 	}
+	return ready, nil
+}
+
+// listReadyWorkBeadIDsWithError returns a set of work bead IDs that are unblocked.
+// Returns an error only when ALL dirs fail (partial success is acceptable).
+func listReadyWorkBeadIDsWithError(townRoot string) (map[string]bool, error) {
+	ready, err := listReadyWorkBeadsWithError(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	readyIDs := make(map[string]bool, len(ready))
+	for id := range ready {
+		readyIDs[id] = true
+	}
 	return readyIDs, nil
 }
 
@@ -529,3 +566,27 @@ func listReadyWorkBeadIDs(townRoot string) map[string]bool {
 	}
 	return ids
 }
+
+// filterOverBudgetRigs drops pending beads bound for a rig whose budget is
+// exhausted for the day (see gt costs budget). They stay queued and are
+// dispatched once the budget resets or is raised.
+func filterOverBudgetRigs(townRoot string, pending []capacity.PendingBead) []capacity.PendingBead {
+	state, err := budget.LoadState(townRoot)
+	if err != nil || len(state.Statuses) == 0 {
+		return pending
+	}
+	now := time.Now()
+	kept := pending[:0]
+	skipped := make(map[string]string)
+	for _, b := range pending {
+		if limit, blocked := state.DispatchBlocked(b.TargetRig, now); blocked {
+			skipped[b.TargetRig] = limit
+			continue
+		}
+		kept = append(kept, b)
+	}
+	for rig, limit := range skipped {
+		fmt.Printf("%s Not dispatching to %s: over budget (%s)\n", style.Warning.Render("$"), rig, limit)
+	}
+	return kept
+}
@@ -656,6 +656,10 @@ Supported keys:
   scheduler.max_polecats      Dispatch mode: -1 = direct (default), N > 0 = deferred
   scheduler.batch_size        Beads per heartbeat (default: 1)
   scheduler.spawn_delay       Delay between spawns (default: 0s)
+  scheduler.aging_interval    Wait that raises a bead's priority one level (default: 1h)
+  scheduler.rigs.<rig>.weight        Rig's relative share of max_polecats (default: 1)
+  scheduler.rigs.<rig>.min_polecats  Polecats the rig is guaranteed (default: 0)
+  scheduler.rigs.<rig>.max_polecats  Cap on the rig's polecats (default: 0 = none)
   maintenance.window          Maintenance window start time in HH:MM (e.g., "03:00")
   maintenance.interval        How often: "daily", "weekly", "monthly", or duration
   maintenance.threshold       Commit count threshold (default: 1000)
@@ -678,6 +682,7 @@ Examples:
   gt config set default_agent claude
   gt config set dolt.port 3308
   gt config set scheduler.max_polecats 5
+  gt config set scheduler.rigs.gastown.weight 2
   gt config set maintenance.window 03:00
   gt config set maintenance.interval daily
   gt config set lifecycle.reaper.delete_age 336h
@@ -700,6 +705,10 @@ Supported keys:
   scheduler.max_polecats      Dispatch mode (-1 = direct, N > 0 = deferred)
   scheduler.batch_size        Beads per heartbeat
   scheduler.spawn_delay       Delay between spawns
+  scheduler.aging_interval    Wait that raises a bead's priority one level
+  scheduler.rigs.<rig>.weight        Rig's relative share of max_polecats
+  scheduler.rigs.<rig>.min_polecats  Polecats the rig is guaranteed
+  scheduler.rigs.<rig>.max_polecats  Cap on the rig's polecats
   maintenance.window          Maintenance window start time (HH:MM)
   maintenance.interval        How often: daily, weekly, monthly, or duration
   maintenance.threshold       Commit count threshold
@@ -796,6 +805,16 @@ func runConfigSet(cmd *cobra.Command, args []string) error {
 		}
 		townSettings.Scheduler.SpawnDelay = value
 
+	case "scheduler.aging_interval":
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid value for %s: expected non-negative Go duration, e.g. 30m, 2h (0s disables aging)", key)
+		}
+		if townSettings.Scheduler == nil {
+			townSettings.Scheduler = capacity.DefaultSchedulerConfig()
+		}
+		townSettings.Scheduler.AgingInterval = value
+
 	case "maintenance.window", "maintenance.interval", "maintenance.threshold":
 		return setMaintenanceConfig(townRoot, key, value)
 
@@ -823,7 +842,16 @@ func runConfigSet(cmd *cobra.Command, args []string) error {
 		if strings.HasPrefix(key, "lifecycle.") {
 			return setLifecycleConfig(townRoot, key, value)
 		}
-		return fmt.Errorf("unknown config key: %q\n\nSupported keys:\n  convoy.notify_on_complete\n  cli_theme\n  default_agent\n  dolt.port\n  scheduler.max_polecats\n  scheduler.batch_size\n  scheduler.spawn_delay\n  maintenance.window\n  maintenance.interval\n  maintenance.threshold\n  lifecycle.reaper.*\n  lifecycle.compactor.*\n  lifecycle.doctor.*\n  lifecycle.backup.*", key)
+		if strings.HasPrefix(key, "scheduler.rigs.") {
+			if townSettings.Scheduler == nil {
+				townSettings.Scheduler = capacity.DefaultSchedulerConfig()
+			}
+			if err := setSchedulerRigConfig(townSettings.Scheduler, key, value); err != nil {
+				return err
+			}
+			break
+		}
+		return fmt.Errorf("unknown config key: %q\n\nSupported keys:\n  convoy.notify_on_complete\n  cli_theme\n  default_agent\n  dolt.port\n  scheduler.max_polecats\n  scheduler.batch_size\n  scheduler.spawn_delay\n  scheduler.aging_interval\n  scheduler.rigs.<rig>.{weight,min_polecats,max_polecats}\n  maintenance.window\n  maintenance.interval\n  maintenance.threshold\n  lifecycle.reaper.*\n  lifecycle.compactor.*\n  lifecycle.doctor.*\n  lifecycle.backup.*", key)
 	}
 
 	if err := config.SaveTownSettings(settingsPath, townSettings); err != nil {
@@ -890,6 +918,13 @@ func runConfigGet(cmd *cobra.Command, args []string) error {
 		}
 		value = scfg.GetSpawnDelay().String()
 
+	case "scheduler.aging_interval":
+		scfg := townSettings.Scheduler
+		if scfg == nil {
+			scfg = capacity.DefaultSchedulerConfig()
+		}
+		value = scfg.GetAgingInterval().String()
+
 	case "maintenance.window", "maintenance.interval", "maintenance.threshold":
 		return getMaintenanceConfig(townRoot, key)
 
@@ -908,13 +943,88 @@ func runConfigGet(cmd *cobra.Command, args []string) error {
 		if strings.HasPrefix(key, "lifecycle.") {
 			return getLifecycleConfig(townRoot, key)
 		}
-		return fmt.Errorf("unknown config key: %q\n\nSupported keys:\n  convoy.notify_on_complete\n  cli_theme\n  default_agent\n  dolt.port\n  scheduler.max_polecats\n  scheduler.batch_size\n  scheduler.spawn_delay\n  maintenance.window\n  maintenance.interval\n  maintenance.threshold\n  lifecycle.reaper.*\n  lifecycle.compactor.*\n  lifecycle.doctor.*\n  lifecycle.backup.*", key)
+		if strings.HasPrefix(key, "scheduler.rigs.") {
+			v, err := getSchedulerRigConfig(townSettings.Scheduler, key)
+			if err != nil {
+				return err
+			}
+			value = v
+			break
+		}
+		return fmt.Errorf("unknown config key: %q\n\nSupported keys:\n  convoy.notify_on_complete\n  cli_theme\n  default_agent\n  dolt.port\n  scheduler.max_polecats\n  scheduler.batch_size\n  scheduler.spawn_delay\n  scheduler.aging_interval\n  scheduler.rigs.<rig>.{weight,min_polecats,max_polecats}\n  maintenance.window\n  maintenance.interval\n  maintenance.threshold\n  lifecycle.reaper.*\n  lifecycle.compactor.*\n  lifecycle.doctor.*\n  lifecycle.backup.*", key)
 	}
 
 	fmt.Println(value)
 	return nil
 }
 
+// parseSchedulerRigKey splits "scheduler.rigs.<rig>.<field>" into rig and field.
+func parseSchedulerRigKey(key string) (rig, field string, err error) {
+	rest := strings.TrimPrefix(key, "scheduler.rigs.")
+	i := strings.LastIndex(rest, ".")
+	if i <= 0 {
+		return "", "", fmt.Errorf("invalid key %q: expected scheduler.rigs.<rig>.<weight|min_polecats|max_polecats>", key)
+	}
+	rig, field = rest[:i], rest[i+1:]
+	switch field {
+	case "weight", "min_polecats", "max_polecats":
+		return rig, field, nil
+	}
+	return "", "", fmt.Errorf("unknown scheduler rig setting %q (expected weight, min_polecats or max_polecats)", field)
+}
+
+// setSchedulerRigConfig sets a scheduler.rigs.<rig>.* key.
+func setSchedulerRigConfig(scfg *capacity.SchedulerConfig, key, value string) error {
+	rig, field, err := parseSchedulerRigKey(key)
+	if err != nil {
+		return err
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 || (field == "weight" && n == 0) {
+		if field == "weight" {
+			return fmt.Errorf("invalid value for %s: expected positive integer", key)
+		}
+		return fmt.Errorf("invalid value for %s: expected non-negative integer", key)
+	}
+	if scfg.Rigs == nil {
+		scfg.Rigs = make(map[string]*capacity.RigSchedulerConfig)
+	}
+	rc := scfg.Rigs[rig]
+	if rc == nil {
+		rc = &capacity.RigSchedulerConfig{}
+		scfg.Rigs[rig] = rc
+	}
+	switch field {
+	case "weight":
+		rc.Weight = n
+	case "min_polecats":
+		rc.MinPolecats = n
+	case "max_polecats":
+		rc.MaxPolecats = n
+	}
+	if rc.MaxPolecats > 0 && rc.MinPolecats > rc.MaxPolecats {
+		return fmt.Errorf("scheduler.rigs.%s: min_polecats (%d) exceeds max_polecats (%d)", rig, rc.MinPolecats, rc.MaxPolecats)
+	}
+	return nil
+}
+
+// getSchedulerRigConfig returns a scheduler.rigs.<rig>.* value, with defaults applied.
+func getSchedulerRigConfig(scfg *capacity.SchedulerConfig, key string) (string, error) {
+	rig, field, err := parseSchedulerRigKey(key)
+	if err != nil {
+		return "", err
+	}
+	rc := scfg.GetRig(rig)
+	switch field {
+	case "weight":
+		return strconv.Itoa(rc.Weight), nil
+	case "min_polecats":
+		return strconv.Itoa(rc.MinPolecats), nil
+	default:
+		return strconv.Itoa(rc.MaxPolecats), nil
+	}
+}
+
 // setMaintenanceConfig sets a maintenance.* key in daemon.json (patrol config).
 func setMaintenanceConfig(townRoot, key, value string) error {
 	patrolConfig := daemon.LoadPatrolConfig(townRoot)
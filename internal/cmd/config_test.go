@@ -874,6 +874,85 @@ func TestConfigSetGet(t *testing.T) {
 	})
 }
 
+func TestConfigSchedulerRigs(t *testing.T) {
+	t.Run("set per-rig share and aging interval", func(t *testing.T) {
+		townRoot := setupTestTownForConfig(t)
+		settingsPath := config.TownSettingsPath(townRoot)
+
+		originalWd, _ := os.Getwd()
+		defer os.Chdir(originalWd)
+		if err := os.Chdir(townRoot); err != nil {
+			t.Fatalf("chdir: %v", err)
+		}
+
+		cmd := &cobra.Command{}
+		for _, kv := range [][2]string{
+			{"scheduler.rigs.gastown.weight", "3"},
+			{"scheduler.rigs.gastown.min_polecats", "1"},
+			{"scheduler.rigs.beads.max_polecats", "2"},
+			{"scheduler.aging_interval", "30m"},
+		} {
+			if err := runConfigSet(cmd, kv[:]); err != nil {
+				t.Fatalf("runConfigSet(%s) failed: %v", kv[0], err)
+			}
+		}
+
+		loaded, err := config.LoadOrCreateTownSettings(settingsPath)
+		if err != nil {
+			t.Fatalf("load settings: %v", err)
+		}
+		if loaded.Scheduler == nil {
+			t.Fatal("Scheduler config is nil after set")
+		}
+		gastown := loaded.Scheduler.GetRig("gastown")
+		if gastown.Weight != 3 || gastown.MinPolecats != 1 || gastown.MaxPolecats != 0 {
+			t.Errorf("gastown = %+v, want weight 3, min 1, no max", gastown)
+		}
+		beadsRig := loaded.Scheduler.GetRig("beads")
+		if beadsRig.Weight != 1 || beadsRig.MaxPolecats != 2 {
+			t.Errorf("beads = %+v, want default weight 1, max 2", beadsRig)
+		}
+		if got := loaded.Scheduler.GetAgingInterval(); got != 30*time.Minute {
+			t.Errorf("aging interval = %v, want 30m", got)
+		}
+
+		if err := runConfigGet(cmd, []string{"scheduler.rigs.gastown.weight"}); err != nil {
+			t.Fatalf("runConfigGet failed: %v", err)
+		}
+	})
+
+	t.Run("rejects invalid values", func(t *testing.T) {
+		townRoot := setupTestTownForConfig(t)
+
+		originalWd, _ := os.Getwd()
+		defer os.Chdir(originalWd)
+		if err := os.Chdir(townRoot); err != nil {
+			t.Fatalf("chdir: %v", err)
+		}
+
+		cmd := &cobra.Command{}
+		for _, kv := range [][2]string{
+			{"scheduler.rigs.gastown.weight", "0"},
+			{"scheduler.rigs.gastown.min_polecats", "-1"},
+			{"scheduler.rigs.gastown.speed", "1"},
+			{"scheduler.rigs.weight", "1"},
+			{"scheduler.aging_interval", "soon"},
+		} {
+			if err := runConfigSet(cmd, kv[:]); err == nil {
+				t.Errorf("runConfigSet(%s, %s) should fail", kv[0], kv[1])
+			}
+		}
+
+		if err := runConfigSet(cmd, []string{"scheduler.rigs.gastown.max_polecats", "1"}); err != nil {
+			t.Fatalf("runConfigSet failed: %v", err)
+		}
+		err := runConfigSet(cmd, []string{"scheduler.rigs.gastown.min_polecats", "2"})
+		if err == nil || !strings.Contains(err.Error(), "exceeds max_polecats") {
+			t.Errorf("error = %v, want min > max rejected", err)
+		}
+	})
+}
+
 func TestConfigMaintenanceSetGet(t *testing.T) {
 	t.Run("set and get maintenance.window", func(t *testing.T) {
 		townRoot := setupTestTownForConfig(t)
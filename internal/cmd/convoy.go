@@ -19,6 +19,7 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	convoyops "github.com/steveyegge/gastown/internal/convoy"
+	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
@@ -71,27 +72,28 @@ func looksLikeIssueID(s string) bool {
 
 // Convoy command flags
 var (
-	convoyMolecule     string
-	convoyNotify       string
-	convoyOwner        string
-	convoyOwned        bool
-	convoyMerge        string
-	convoyBaseBranch   string
-	convoyStatusJSON   bool
-	convoyListJSON     bool
-	convoyListStatus   string
-	convoyListAll      bool
-	convoyListTree     bool
-	convoyInteractive  bool
-	convoyStrandedJSON bool
-	convoyCloseReason  string
-	convoyCloseNotify  string
-	convoyCloseForce   bool
-	convoyCheckDryRun  bool
-	convoyLandForce    bool
-	convoyLandKeep     bool
-	convoyLandDryRun   bool
-	convoyFromEpic     string
+	convoyMolecule       string
+	convoyNotify         string
+	convoyOwner          string
+	convoyOwned          bool
+	convoyMerge          string
+	convoyBaseBranch     string
+	convoyStatusJSON     bool
+	convoyStatusForecast bool
+	convoyListJSON       bool
+	convoyListStatus     string
+	convoyListAll        bool
+	convoyListTree       bool
+	convoyInteractive    bool
+	convoyStrandedJSON   bool
+	convoyCloseReason    string
+	convoyCloseNotify    string
+	convoyCloseForce     bool
+	convoyCheckDryRun    bool
+	convoyLandForce      bool
+	convoyLandKeep       bool
+	convoyLandDryRun     bool
+	convoyFromEpic       string
 )
 
 const (
@@ -245,8 +247,18 @@ var convoyStatusCmd = &cobra.Command{
 	Short: "Show convoy status",
 	Long: `Show detailed status for a convoy.
 
-Displays convoy metadata, tracked issues, and completion progress.
-Without an ID, shows status of all active convoys.`,
+Displays convoy metadata, tracked issues, completion progress, and the cost
+attributed to tracked issues since the convoy was created (see gt costs --by-bead).
+Without an ID, shows status of all active convoys.
+
+With --forecast, also estimates when the convoy will land. Each open bead's
+duration is the median (with an 80% interval) of similar finished beads:
+same rig, formula and labels where there are enough of them, widening to
+same rig or formula, then all beads. Durations come from the sling/hook and
+done events of closed beads. The beads are staged into waves like gt convoy
+stage and scheduled along their blockers, assuming unlimited parallelism, to
+show per-wave ETAs and the critical path: the chain of beads whose delay
+delays the whole convoy. Critical beads are marked ★ in the issue list.`,
 	Args: cobra.MaximumNArgs(1),
 	SilenceUsage: true,
 	RunE:         runConvoyStatus,
@@ -385,6 +397,7 @@ func init() {
 
 	// Status flags
 	convoyStatusCmd.Flags().BoolVar(&convoyStatusJSON, "json", false, "Output as JSON")
+	convoyStatusCmd.Flags().BoolVar(&convoyStatusForecast, "forecast", false, "Forecast ETA per wave and the critical path from historical bead durations")
 
 	// List flags
 	convoyListCmd.Flags().BoolVar(&convoyListJSON, "json", false, "Output as JSON")
@@ -948,6 +961,7 @@ func closeConvoyIfComplete(townBeads, convoyID, title string, tracked []trackedI
 	}
 
 	fmt.Printf("%s Auto-closed convoy 🚚 %s: %s\n", style.Bold.Render("✓"), convoyID, title)
+	_ = events.LogFeed(events.TypeConvoyClosed, detectActor(), events.ConvoyClosedPayload(convoyID, title, reason))
 	notifyConvoyCompletion(townBeads, convoyID, title)
 	return true, nil
 }
@@ -1111,6 +1125,7 @@ func runConvoyClose(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("%s Closed convoy 🚚 %s: %s\n", style.Bold.Render("✓"), convoyID, convoy.Title)
+	_ = events.LogFeed(events.TypeConvoyClosed, detectActor(), events.ConvoyClosedPayload(convoyID, convoy.Title, reason))
 	if convoyCloseReason != "" {
 		fmt.Printf("  Reason: %s\n", convoyCloseReason)
 	}
@@ -1833,21 +1848,45 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Cost attributed to the tracked beads since the convoy was created.
+	// Best effort: cost data is advisory and must not break status.
+	var costUSD *float64
+	trackedIDs := make([]string, 0, len(tracked))
+	for _, t := range tracked {
+		trackedIDs = append(trackedIDs, t.ID)
+	}
+	createdAt, _ := time.Parse(time.RFC3339, convoy.CreatedAt)
+	if cost, err := convoyCost(townBeads, trackedIDs, createdAt); err == nil {
+		costUSD = &cost
+	}
+
+	var forecast *convoyForecast
+	if convoyStatusForecast && len(tracked) > 0 {
+		// Advisory like cost: a convoy that cannot be forecast (no slingable
+		// beads, a dependency cycle) still shows its status.
+		forecast, err = buildConvoyForecast(townBeads, tracked, time.Now())
+		if err != nil {
+			style.PrintWarning("no forecast for %s: %v", convoyID, err)
+		}
+	}
+
 	if convoyStatusJSON {
 		lifecycle := "system-managed"
 		if isOwned {
 			lifecycle = "caller-managed"
 		}
 		type jsonStatus struct {
-			ID            string             `json:"id"`
-			Title         string             `json:"title"`
-			Status        string             `json:"status"`
-			Owned         bool               `json:"owned"`
-			Lifecycle     string             `json:"lifecycle"`
-			MergeStrategy string             `json:"merge_strategy,omitempty"`
-			Tracked       []trackedIssueInfo `json:"tracked"`
-			Completed     int                `json:"completed"`
-			Total         int                `json:"total"`
+			ID            string              `json:"id"`
+			Title         string              `json:"title"`
+			Status        string              `json:"status"`
+			Owned         bool                `json:"owned"`
+			Lifecycle     string              `json:"lifecycle"`
+			MergeStrategy string              `json:"merge_strategy,omitempty"`
+			Tracked       []trackedIssueInfo  `json:"tracked"`
+			Completed     int                 `json:"completed"`
+			Total         int                 `json:"total"`
+			CostUSD       *float64            `json:"cost_usd,omitempty"`
+			Forecast      *ConvoyForecastJSON `json:"forecast,omitempty"`
 		}
 		out := jsonStatus{
 			ID:            convoy.ID,
@@ -1859,6 +1898,10 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 			Tracked:       tracked,
 			Completed:     completed,
 			Total:         len(tracked),
+			CostUSD:       costUSD,
+		}
+		if forecast != nil {
+			out.Forecast = forecast.toJSON()
 		}
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
@@ -1879,6 +1922,9 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  Merge:     %s\n", merge)
 	}
 	fmt.Printf("  Progress:  %d/%d completed\n", completed, len(tracked))
+	if costUSD != nil {
+		fmt.Printf("  Cost:      $%.2f\n", *costUSD)
+	}
 	fmt.Printf("  Created:   %s\n", convoy.CreatedAt)
 	if convoy.ClosedAt != "" {
 		fmt.Printf("  Closed:    %s\n", convoy.ClosedAt)
@@ -1906,6 +1952,9 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 			}
 
 			line := fmt.Sprintf("    %s %s: %s [%s]", status, t.ID, t.Title, bracketContent)
+			if forecast != nil && forecast.isCritical(t.ID) {
+				line += " " + style.Warning.Render("★")
+			}
 			if t.Worker != "" {
 				workerDisplay := "@" + t.Worker
 				if t.WorkerAge != "" {
@@ -1917,6 +1966,10 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if forecast != nil {
+		printConvoyForecast(forecast)
+	}
+
 	// Hint for owned convoys when all issues are complete
 	if isOwned && completed == len(tracked) && len(tracked) > 0 && normalizeConvoyStatus(convoy.Status) == convoyStatusOpen {
 		fmt.Printf("\n  %s\n", style.Dim.Render("All issues complete. Land with: gt convoy land "+convoyID))
@@ -2218,15 +2271,9 @@ func applyFreshIssueDetails(dep *trackedDependency, details *issueDetails) {
 	dep.Labels = details.Labels
 }
 
-// getTrackedIssues gets issues tracked by a convoy with fresh cross-rig details.
-// Returns issue details including status, type, and worker info.
-//
-// Prefers raw SQL query against the dependencies table (bdDepListRawIDs) which
-// avoids the JOIN with the issues table that silently drops cross-database
-// dependencies (see GH #2624, #2832). Falls back to bd dep list and bd show
-// for older bd versions that don't support bd sql.
-// Then fetches fresh issue details via bd show with prefix routing.
-func getTrackedIssues(townBeads, convoyID string) ([]trackedIssueInfo, error) {
+// getTrackedIDs returns the IDs of the issues a convoy tracks, without
+// fetching their details.
+func getTrackedIDs(townBeads, convoyID string) ([]string, error) {
 	// Prefer raw SQL — works for cross-database deps where tracked beads
 	// live in different Dolt databases. Falls back to bd dep list if bd sql
 	// is not available (older bd versions).
@@ -2247,7 +2294,22 @@ func getTrackedIssues(townBeads, convoyID string) ([]trackedIssueInfo, error) {
 			return nil, fmt.Errorf("fallback show for tracked deps of %s: %w", convoyID, err)
 		}
 	}
+	return trackedIDs, nil
+}
 
+// getTrackedIssues gets issues tracked by a convoy with fresh cross-rig details.
+// Returns issue details including status, type, and worker info.
+//
+// Prefers raw SQL query against the dependencies table (bdDepListRawIDs) which
+// avoids the JOIN with the issues table that silently drops cross-database
+// dependencies (see GH #2624, #2832). Falls back to bd dep list and bd show
+// for older bd versions that don't support bd sql.
+// Then fetches fresh issue details via bd show with prefix routing.
+func getTrackedIssues(townBeads, convoyID string) ([]trackedIssueInfo, error) {
+	trackedIDs, err := getTrackedIDs(townBeads, convoyID)
+	if err != nil {
+		return nil, err
+	}
 	if len(trackedIDs) == 0 {
 		return nil, nil
 	}
@@ -2383,6 +2445,7 @@ type issueDetailsJSON struct {
 	BlockedBy      []string          `json:"blocked_by"`
 	BlockedByCount int               `json:"blocked_by_count"`
 	Dependencies   []issueDependency `json:"dependencies"`
+	Parent         string            `json:"parent,omitempty"`
 }
 
 func (issue issueDetailsJSON) toIssueDetails() *issueDetails {
@@ -2396,6 +2459,7 @@ func (issue issueDetailsJSON) toIssueDetails() *issueDetails {
 		BlockedBy:      issue.BlockedBy,
 		BlockedByCount: issue.BlockedByCount,
 		Dependencies:   issue.Dependencies,
+		Parent:         issue.Parent,
 	}
 }
 
@@ -2448,6 +2512,7 @@ type issueDetails struct {
 	BlockedBy      []string
 	BlockedByCount int
 	Dependencies   []issueDependency
+	Parent         string
 }
 
 func (d issueDetails) IsBlocked() bool {
@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/convoy"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// forecastHistoryLimit caps how many closed beads are read per database when
+// building duration samples for a forecast.
+const forecastHistoryLimit = 500
+
+// ConvoyForecastJSON is the forecast section of gt convoy status --forecast --json.
+type ConvoyForecastJSON struct {
+	Finish       string             `json:"finish"`      // median finish time (RFC 3339)
+	FinishLow    string             `json:"finish_low"`  // 10th percentile
+	FinishHigh   string             `json:"finish_high"` // 90th percentile
+	Samples      int                `json:"samples"`     // closed beads the estimates are drawn from
+	CriticalPath []string           `json:"critical_path"`
+	Waves        []WaveForecastJSON `json:"waves"`
+	Beads        []BeadForecastJSON `json:"beads"`
+	Gated        []string           `json:"gated,omitempty"` // tasks gated by open non-slingable beads; not forecast
+}
+
+// WaveForecastJSON is one wave's expected finish.
+type WaveForecastJSON struct {
+	Number     int      `json:"number"`
+	Tasks      []string `json:"tasks"`
+	Finish     string   `json:"finish"`
+	FinishLow  string   `json:"finish_low"`
+	FinishHigh string   `json:"finish_high"`
+}
+
+// BeadForecastJSON is one tracked bead's place in the forecast.
+type BeadForecastJSON struct {
+	ID               string `json:"id"`
+	Wave             int    `json:"wave"`
+	EstimateMinutes  int    `json:"estimate_minutes,omitempty"` // median duration of similar beads
+	RemainingMinutes int    `json:"remaining_minutes"`
+	SlackMinutes     int    `json:"slack_minutes"`
+	Finish           string `json:"finish"`
+	Basis            string `json:"basis,omitempty"` // similarity tier, e.g. "rig+formula"
+	Critical         bool   `json:"critical"`
+	Overdue          bool   `json:"overdue,omitempty"`
+}
+
+// convoyForecast is a forecast together with what was needed to build it.
+type convoyForecast struct {
+	forecast *convoy.Forecast
+	waves    []Wave
+	gated    []GatedTask
+	samples  int
+}
+
+// buildConvoyForecast forecasts the tracked beads of a convoy: it stages them
+// into waves like gt convoy stage, estimates each open bead from closed beads
+// in every database of the town, and schedules them along their blockers.
+func buildConvoyForecast(townRoot string, tracked []trackedIssueInfo, now time.Time) (*convoyForecast, error) {
+	byDir := make(map[string][]string)
+	for _, t := range tracked {
+		dir := beads.ResolveHookDir(townRoot, t.ID, townRoot)
+		byDir[dir] = append(byDir[dir], t.ID)
+	}
+	issues := make(map[string]*beads.Issue, len(tracked))
+	for dir, ids := range byDir {
+		found, err := beads.New(dir).ShowMultiple(ids)
+		if err != nil {
+			return nil, fmt.Errorf("reading tracked beads: %w", err)
+		}
+		for id, issue := range found {
+			issues[id] = issue
+		}
+	}
+
+	rigOf := func(id string) string {
+		return beads.GetRigNameForPrefix(townRoot, beads.ExtractPrefix(id))
+	}
+	var infos []BeadInfo
+	var deps []DepInfo
+	for _, issue := range issues {
+		infos = append(infos, BeadInfo{
+			ID:     issue.ID,
+			Title:  issue.Title,
+			Type:   issue.Type,
+			Status: issue.Status,
+			Rig:    rigOf(issue.ID),
+		})
+		for _, d := range issue.Dependencies {
+			deps = append(deps, DepInfo{IssueID: issue.ID, DependsOnID: d.ID, Type: d.DependencyType})
+		}
+	}
+	dag := buildConvoyDAG(infos, deps)
+	if cycle := detectCycles(dag); cycle != nil {
+		return nil, fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " → "))
+	}
+	waves, gated, err := computeWaves(dag)
+	if err != nil {
+		return nil, err
+	}
+
+	spans, err := convoy.LoadWorkSpans(townRoot)
+	if err != nil {
+		return nil, fmt.Errorf("reading events log: %w", err)
+	}
+	samples := forecastSamples(townRoot, spans, rigOf)
+
+	var tasks []convoy.Task
+	waveIDs := make([][]string, len(waves))
+	for i, w := range waves {
+		waveIDs[i] = w.Tasks
+		for _, id := range w.Tasks {
+			issue, node := issues[id], dag.Nodes[id]
+			task := convoy.Task{
+				ID:        id,
+				Rig:       node.Rig,
+				Labels:    issue.Labels,
+				Status:    issue.Status,
+				BlockedBy: node.BlockedBy,
+			}
+			if fields := beads.ParseAttachmentFields(issue); fields != nil {
+				task.Formula = fields.AttachedFormula
+			}
+			if issue.Status == "in_progress" || issue.Status == beads.StatusHooked {
+				task.StartedAt = spans[id].Start
+			}
+			tasks = append(tasks, task)
+		}
+	}
+
+	fc, err := convoy.ForecastTasks(tasks, waveIDs, convoy.NewEstimator(samples), now)
+	if err != nil {
+		return nil, err
+	}
+	return &convoyForecast{forecast: fc, waves: waves, gated: gated, samples: len(samples)}, nil
+}
+
+// forecastSamples collects duration samples from the closed beads of every
+// beads database in the town. Unreadable databases are skipped: the forecast
+// is advisory and falls back to a default estimate.
+func forecastSamples(townRoot string, spans map[string]convoy.WorkSpan, rigOf func(string) string) []convoy.Sample {
+	seen := make(map[string]bool)
+	var closed []*beads.Issue
+	for _, dir := range beadsSearchDirs(townRoot) {
+		list, err := beads.New(dir).List(beads.ListOptions{Status: "closed", Priority: -1, Limit: forecastHistoryLimit})
+		if err != nil {
+			continue
+		}
+		for _, issue := range list {
+			if !seen[issue.ID] {
+				seen[issue.ID] = true
+				closed = append(closed, issue)
+			}
+		}
+	}
+	return convoy.SamplesFromIssues(closed, spans, rigOf)
+}
+
+// toJSON renders the forecast for gt convoy status --json.
+func (cf *convoyForecast) toJSON() *ConvoyForecastJSON {
+	fc := cf.forecast
+	out := &ConvoyForecastJSON{
+		Finish:       fc.Finish.Format(time.RFC3339),
+		FinishLow:    fc.FinishLow.Format(time.RFC3339),
+		FinishHigh:   fc.FinishHigh.Format(time.RFC3339),
+		Samples:      cf.samples,
+		CriticalPath: fc.CriticalPath,
+		Waves:        []WaveForecastJSON{},
+		Beads:        []BeadForecastJSON{},
+	}
+	if out.CriticalPath == nil {
+		out.CriticalPath = []string{}
+	}
+	for _, w := range fc.Waves {
+		out.Waves = append(out.Waves, WaveForecastJSON{
+			Number:     w.Number,
+			Tasks:      w.Tasks,
+			Finish:     w.Finish.Format(time.RFC3339),
+			FinishLow:  w.FinishLow.Format(time.RFC3339),
+			FinishHigh: w.FinishHigh.Format(time.RFC3339),
+		})
+		for _, id := range w.Tasks {
+			tf := fc.Tasks[id]
+			out.Beads = append(out.Beads, BeadForecastJSON{
+				ID:               id,
+				Wave:             w.Number,
+				EstimateMinutes:  int(tf.Estimate.P50.Minutes()),
+				RemainingMinutes: int(tf.Remaining.Minutes()),
+				SlackMinutes:     int(tf.Slack.Minutes()),
+				Finish:           tf.Finish.Format(time.RFC3339),
+				Basis:            tf.Estimate.Basis,
+				Critical:         tf.Critical,
+				Overdue:          tf.Overdue,
+			})
+		}
+	}
+	for _, g := range cf.gated {
+		out.Gated = append(out.Gated, g.TaskID)
+	}
+	return out
+}
+
+// isCritical reports whether id is on the forecast's critical path.
+func (cf *convoyForecast) isCritical(id string) bool {
+	tf := cf.forecast.Tasks[id]
+	return tf != nil && tf.Critical
+}
+
+// printConvoyForecast writes the human-readable forecast section.
+func printConvoyForecast(cf *convoyForecast) {
+	fc := cf.forecast
+	fmt.Printf("\n  %s\n", style.Bold.Render("Forecast:"))
+	if !fc.Finish.After(fc.Now) {
+		fmt.Printf("    All forecast work is done.\n")
+	} else {
+		fmt.Printf("    ETA:      %s  %s\n", formatForecastETA(fc.Now, fc.Finish),
+			style.Dim.Render(fmt.Sprintf("(80%%: %s – %s)",
+				formatForecastSpan(fc.FinishLow.Sub(fc.Now)), formatForecastSpan(fc.FinishHigh.Sub(fc.Now)))))
+	}
+	if cf.samples == 0 {
+		fmt.Printf("    %s\n", style.Dim.Render("No finished beads with work events yet; using default estimates."))
+	} else {
+		fmt.Printf("    %s\n", style.Dim.Render(fmt.Sprintf("Estimated from %d finished beads.", cf.samples)))
+	}
+	if len(fc.CriticalPath) > 0 {
+		fmt.Printf("    Critical: %s\n", strings.Join(fc.CriticalPath, " → "))
+	}
+
+	fmt.Println()
+	for _, w := range fc.Waves {
+		open := 0
+		for _, id := range w.Tasks {
+			if fc.Tasks[id].Remaining > 0 {
+				open++
+			}
+		}
+		eta := style.Dim.Render("done")
+		if w.Finish.After(fc.Now) {
+			eta = fmt.Sprintf("%s  %s", formatForecastETA(fc.Now, w.Finish),
+				style.Dim.Render(fmt.Sprintf("(80%%: %s – %s)",
+					formatForecastSpan(w.FinishLow.Sub(fc.Now)), formatForecastSpan(w.FinishHigh.Sub(fc.Now)))))
+		}
+		fmt.Printf("    Wave %d  %d/%d open  %s\n", w.Number, open, len(w.Tasks), eta)
+	}
+
+	var overdue []string
+	for _, w := range fc.Waves {
+		for _, id := range w.Tasks {
+			if fc.Tasks[id].Overdue {
+				overdue = append(overdue, id)
+			}
+		}
+	}
+	if len(overdue) > 0 {
+		sort.Strings(overdue)
+		fmt.Printf("    %s\n", style.Warning.Render("Overdue (past 90th percentile): "+strings.Join(overdue, ", ")))
+	}
+	if len(cf.gated) > 0 {
+		ids := make([]string, len(cf.gated))
+		for i, g := range cf.gated {
+			ids[i] = g.TaskID
+		}
+		fmt.Printf("    %s\n", style.Dim.Render("Not forecast (gated by open decisions/epics): "+strings.Join(ids, ", ")))
+	}
+}
+
+// formatForecastETA renders t as "in 2h 10m (Mar 2 15:40)".
+func formatForecastETA(now, t time.Time) string {
+	return fmt.Sprintf("in %s (%s)", formatForecastSpan(t.Sub(now)), t.Local().Format("Jan 2 15:04"))
+}
+
+// formatForecastSpan renders d rounded to the minute.
+func formatForecastSpan(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Minute {
+		return "<1m"
+	}
+	s := formatDuration(d)
+	return strings.TrimSuffix(strings.TrimSuffix(s, " 0s"), " 0m")
+}
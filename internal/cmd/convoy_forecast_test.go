@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/convoy"
+)
+
+func TestFormatForecastSpan(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{20 * time.Second, "<1m"},
+		{45 * time.Minute, "45m"},
+		{time.Hour, "1h"},
+		{2*time.Hour + 10*time.Minute + 20*time.Second, "2h 10m"},
+	}
+	for _, tt := range tests {
+		if got := formatForecastSpan(tt.in); got != tt.want {
+			t.Errorf("formatForecastSpan(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestConvoyForecastToJSON(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	samples := []convoy.Sample{{Duration: time.Hour}, {Duration: time.Hour}, {Duration: time.Hour}}
+	tasks := []convoy.Task{
+		{ID: "gt-a", Status: "closed"},
+		{ID: "gt-b", Status: "open", BlockedBy: []string{"gt-a"}},
+	}
+	fc, err := convoy.ForecastTasks(tasks, [][]string{{"gt-a"}, {"gt-b"}}, convoy.NewEstimator(samples), now)
+	if err != nil {
+		t.Fatalf("ForecastTasks: %v", err)
+	}
+	cf := &convoyForecast{forecast: fc, samples: 3, gated: []GatedTask{{TaskID: "gt-c"}}}
+
+	out := cf.toJSON()
+	if out.Finish != "2026-03-02T13:00:00Z" || out.Samples != 3 {
+		t.Errorf("Finish = %s, Samples = %d", out.Finish, out.Samples)
+	}
+	if len(out.CriticalPath) != 1 || out.CriticalPath[0] != "gt-b" {
+		t.Errorf("CriticalPath = %v, want [gt-b]", out.CriticalPath)
+	}
+	if len(out.Beads) != 2 || out.Beads[1].Wave != 2 || out.Beads[1].RemainingMinutes != 60 || !out.Beads[1].Critical {
+		t.Errorf("Beads = %+v", out.Beads)
+	}
+	if len(out.Gated) != 1 || !cf.isCritical("gt-b") || cf.isCritical("gt-a") {
+		t.Errorf("Gated = %v, critical gt-b %v gt-a %v", out.Gated, cf.isCritical("gt-b"), cf.isCritical("gt-a"))
+	}
+}
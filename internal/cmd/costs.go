@@ -12,11 +12,13 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/costs"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
@@ -24,12 +26,14 @@ import (
 )
 
 var (
-	costsJSON    bool
-	costsToday   bool
-	costsWeek    bool
-	costsByRole  bool
-	costsByRig   bool
-	costsVerbose bool
+	costsJSON     bool
+	costsToday    bool
+	costsWeek     bool
+	costsByRole   bool
+	costsByRig    bool
+	costsByBead   bool
+	costsByConvoy bool
+	costsVerbose  bool
 
 	// Record subcommand flags
 	recordSession  string
@@ -39,7 +43,6 @@ var (
 	digestYesterday bool
 	digestDate      string
 	digestDryRun    bool
-
 )
 
 var costsCmd = &cobra.Command{
@@ -52,12 +55,29 @@ Costs are calculated from Claude Code transcript files in
 $CLAUDE_CONFIG_DIR/projects/ (defaults to ~/.claude/projects/) by summing
 token usage from assistant messages and applying model-specific pricing.
 
+Prices (USD per million tokens) default to a built-in table and can be
+overridden or extended per model, or model ID prefix, under "pricing" in
+settings/config.json:
+
+  "pricing": {
+    "claude-opus-4-5": {"input": 5, "output": 25, "cache_read": 0.5, "cache_write": 6.25},
+    "default":         {"input": 3, "output": 15, "cache_read": 0.3, "cache_write": 3.75}
+  }
+
+--by-bead and --by-convoy attribute session costs to work. A session is
+charged to its --work-item when recorded, otherwise to the bead its agent had
+hooked when the session ended (from sling/hook events). Bead costs roll up to
+parent epics, merged MRs and open convoys. Without --today or --week they
+cover all digests, the costs log and live sessions.
+
 Examples:
   gt costs              # Live costs from running sessions
   gt costs --today      # Today's costs from log file (not yet digested)
   gt costs --week       # This week's costs from digest beads + today's log
   gt costs --by-role    # Breakdown by role (polecat, witness, etc.)
   gt costs --by-rig     # Breakdown by rig
+  gt costs --by-bead    # Cost per bead, with merged MRs and epic rollups
+  gt costs --by-convoy --week  # This week's cost per open convoy
   gt costs --json       # Output as JSON
   gt costs -v           # Show debug output for failures
 
@@ -84,7 +104,10 @@ permanent "Cost Report YYYY-MM-DD" bead for audit purposes.
 
 Examples:
   gt costs record --session gt-gastown-toast
-  gt costs record --session gt-gastown-toast --work-item gt-abc123`,
+  gt costs record --session gt-gastown-toast --work-item gt-abc123
+
+Without --work-item, polecat and crew sessions are recorded against the bead
+their agent has hooked, taken from the town events log.`,
 	RunE: runCostsRecord,
 }
 
@@ -114,6 +137,8 @@ func init() {
 	costsCmd.Flags().BoolVar(&costsWeek, "week", false, "Show this week's total from session events")
 	costsCmd.Flags().BoolVar(&costsByRole, "by-role", false, "Show breakdown by role")
 	costsCmd.Flags().BoolVar(&costsByRig, "by-rig", false, "Show breakdown by rig")
+	costsCmd.Flags().BoolVar(&costsByBead, "by-bead", false, "Show cost per bead (work item), merged MR and epic")
+	costsCmd.Flags().BoolVar(&costsByConvoy, "by-convoy", false, "Show cost per open convoy")
 	costsCmd.Flags().BoolVarP(&costsVerbose, "verbose", "v", false, "Show debug output for failures")
 
 	// Add record subcommand
@@ -168,13 +193,14 @@ type TranscriptMessage struct {
 	Type      string                 `json:"type"`
 	SessionID string                 `json:"sessionId"`
 	CWD       string                 `json:"cwd"`
+	Timestamp time.Time              `json:"timestamp"`
 	Message   *TranscriptMessageBody `json:"message,omitempty"`
 }
 
 // TranscriptMessageBody contains the message content and usage info.
 type TranscriptMessageBody struct {
-	Model string          `json:"model"`
-	Role  string          `json:"role"`
+	Model string           `json:"model"`
+	Role  string           `json:"role"`
 	Usage *TranscriptUsage `json:"usage,omitempty"`
 }
 
@@ -188,32 +214,44 @@ type TranscriptUsage struct {
 
 // TokenUsage aggregates token usage across a session.
 type TokenUsage struct {
-	Model                    string
+	Model                    string // first model seen
 	InputTokens              int
 	CacheCreationInputTokens int
 	CacheReadInputTokens     int
 	OutputTokens             int
+
+	// ByModel splits the totals by model, so sessions that switch models
+	// (e.g. /model, or Haiku subagents) are priced per model.
+	ByModel map[string]*costs.Usage
 }
 
-// Model pricing per million tokens (as of Jan 2025).
-// See: https://www.anthropic.com/pricing
-var modelPricing = map[string]struct {
-	InputPerMillion       float64
-	OutputPerMillion      float64
-	CacheReadPerMillion   float64 // 90% discount on input price
-	CacheCreatePerMillion float64 // 25% premium on input price
-}{
-	// Claude Opus 4.5
-	"claude-opus-4-5-20251101": {15.0, 75.0, 1.5, 18.75},
-	// Claude Sonnet 4
-	"claude-sonnet-4-20250514": {3.0, 15.0, 0.3, 3.75},
-	// Claude Haiku 3.5
-	"claude-3-5-haiku-20241022": {1.0, 5.0, 0.1, 1.25},
-	// Fallback for unknown models (use Sonnet pricing)
-	"default": {3.0, 15.0, 0.3, 3.75},
+var (
+	costPricingOnce  sync.Once
+	costPricingTable costs.Pricing
+)
+
+// costPricing returns the token price table: the compiled-in prices overlaid
+// with the "pricing" section of town settings. It is loaded once per process.
+func costPricing() costs.Pricing {
+	costPricingOnce.Do(func() {
+		var overrides map[string]*config.ModelPrice
+		if townRoot, err := workspace.FindFromCwdOrError(); err == nil {
+			if ts, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot)); err == nil {
+				overrides = ts.Pricing
+			} else if costsVerbose {
+				fmt.Fprintf(os.Stderr, "[costs] could not load pricing from town settings: %v\n", err)
+			}
+		}
+		costPricingTable = costs.NewPricing(overrides)
+	})
+	return costPricingTable
 }
 
 func runCosts(cmd *cobra.Command, args []string) error {
+	if costsByBead || costsByConvoy {
+		return runCostsByWork()
+	}
+
 	// If querying ledger, use ledger functions
 	if costsToday || costsWeek || costsByRole || costsByRig {
 		return runCostsFromLedger()
@@ -396,7 +434,8 @@ type SessionPayload struct {
 
 // EventListItem represents an event from bd list (minimal fields).
 type EventListItem struct {
-	ID string `json:"id"`
+	ID    string `json:"id"`
+	Title string `json:"title"`
 }
 
 // querySessionEvents queries beads for session.ended events and converts them to CostEntry.
@@ -540,6 +579,39 @@ func querySessionEventsFromLocation(location string) ([]CostEntry, error) {
 
 // queryDigestBeads queries costs.digest events from the past N days and extracts session entries.
 func queryDigestBeads(days int) ([]CostEntry, error) {
+	digests, err := queryCostDigests(days)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []CostEntry
+	for _, digest := range digests {
+		// If the digest has per-session data (old format), use it directly.
+		// Otherwise, synthesize entries from the aggregate ByRole data.
+		if len(digest.Sessions) > 0 {
+			entries = append(entries, digest.Sessions...)
+		} else {
+			digestDate, _ := time.Parse("2006-01-02", digest.Date)
+			for role, cost := range digest.ByRole {
+				entries = append(entries, CostEntry{
+					SessionID: fmt.Sprintf("digest-%s-%s", digest.Date, role),
+					Role:      role,
+					CostUSD:   cost,
+					EndedAt:   digestDate,
+				})
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// costDigestTitlePrefix starts the title of every daily digest bead.
+const costDigestTitlePrefix = "Cost Report "
+
+// queryCostDigests returns the daily cost digests dated within the past N
+// days, or all digests when days is 0.
+func queryCostDigests(days int) ([]CostDigest, error) {
 	// Get list of event IDs
 	listArgs := []string{
 		"list",
@@ -560,16 +632,34 @@ func queryDigestBeads(days int) ([]CostEntry, error) {
 		return nil, fmt.Errorf("parsing event list: %w", err)
 	}
 
-	if len(listItems) == 0 {
-		return nil, nil
+	// Calculate date range
+	now := time.Now()
+	cutoff := now.AddDate(0, 0, -days)
+	inRange := func(date string) bool {
+		d, err := time.Parse("2006-01-02", date)
+		return err == nil && (days <= 0 || !d.Before(cutoff))
 	}
 
-	// Get full details for all events
-	showArgs := []string{"show", "--json"}
+	// Skip events whose title shows they are not a digest in range, so
+	// bd show is not asked for every event in the town.
+	var ids []string
 	for _, item := range listItems {
-		showArgs = append(showArgs, item.ID)
+		if item.Title != "" {
+			date, ok := strings.CutPrefix(item.Title, costDigestTitlePrefix)
+			if !ok || !inRange(date) {
+				continue
+			}
+		}
+		ids = append(ids, item.ID)
 	}
 
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	// Get full details for the events
+	showArgs := append([]string{"show", "--json"}, ids...)
+
 	showCmd := exec.Command("bd", showArgs...)
 	showOutput, err := showCmd.Output()
 	if err != nil {
@@ -581,11 +671,7 @@ func queryDigestBeads(days int) ([]CostEntry, error) {
 		return nil, fmt.Errorf("parsing event details: %w", err)
 	}
 
-	// Calculate date range
-	now := time.Now()
-	cutoff := now.AddDate(0, 0, -days)
-
-	var entries []CostEntry
+	var digests []CostDigest
 	for _, event := range events {
 		// Filter for costs.digest events only
 		if event.EventKind != "costs.digest" {
@@ -601,31 +687,13 @@ func queryDigestBeads(days int) ([]CostEntry, error) {
 		}
 
 		// Check date is within range
-		digestDate, err := time.Parse("2006-01-02", digest.Date)
-		if err != nil {
+		if !inRange(digest.Date) {
 			continue
 		}
-		if digestDate.Before(cutoff) {
-			continue
-		}
-
-		// If the digest has per-session data (old format), use it directly.
-		// Otherwise, synthesize entries from the aggregate ByRole data.
-		if len(digest.Sessions) > 0 {
-			entries = append(entries, digest.Sessions...)
-		} else {
-			for role, cost := range digest.ByRole {
-				entries = append(entries, CostEntry{
-					SessionID: fmt.Sprintf("digest-%s-%s", digest.Date, role),
-					Role:      role,
-					CostUSD:   cost,
-					EndedAt:   digestDate,
-				})
-			}
-		}
+		digests = append(digests, digest)
 	}
 
-	return entries, nil
+	return digests, nil
 }
 
 // parseSessionName extracts role, rig, and worker from a session name.
@@ -726,6 +794,13 @@ func findLatestTranscript(projectDir string) (string, error) {
 
 // parseTranscriptUsage reads a transcript file and sums token usage from assistant messages.
 func parseTranscriptUsage(transcriptPath string) (*TokenUsage, error) {
+	return parseTranscriptUsageSince(transcriptPath, time.Time{})
+}
+
+// parseTranscriptUsageSince is parseTranscriptUsage restricted to messages
+// recorded at or after since. The zero time counts every message; messages
+// without a timestamp are always counted.
+func parseTranscriptUsageSince(transcriptPath string, since time.Time) (*TokenUsage, error) {
 	file, err := os.Open(transcriptPath)
 	if err != nil {
 		return nil, err
@@ -753,18 +828,34 @@ func parseTranscriptUsage(transcriptPath string) (*TokenUsage, error) {
 		if msg.Type != "assistant" || msg.Message == nil || msg.Message.Usage == nil {
 			continue
 		}
+		if !msg.Timestamp.IsZero() && msg.Timestamp.Before(since) {
+			continue
+		}
 
-		// Capture the model (use first one found, they should all be the same)
-		if usage.Model == "" && msg.Message.Model != "" {
-			usage.Model = msg.Message.Model
+		model := msg.Message.Model
+		if usage.Model == "" && model != "" {
+			usage.Model = model
 		}
 
-		// Sum token usage
+		// Sum token usage, overall and per model
 		u := msg.Message.Usage
 		usage.InputTokens += u.InputTokens
 		usage.CacheCreationInputTokens += u.CacheCreationInputTokens
 		usage.CacheReadInputTokens += u.CacheReadInputTokens
 		usage.OutputTokens += u.OutputTokens
+
+		m := usage.ByModel[model]
+		if m == nil {
+			if usage.ByModel == nil {
+				usage.ByModel = make(map[string]*costs.Usage)
+			}
+			m = &costs.Usage{Model: model}
+			usage.ByModel[model] = m
+		}
+		m.InputTokens += u.InputTokens
+		m.CacheWriteTokens += u.CacheCreationInputTokens
+		m.CacheReadTokens += u.CacheReadInputTokens
+		m.OutputTokens += u.OutputTokens
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -774,30 +865,38 @@ func parseTranscriptUsage(transcriptPath string) (*TokenUsage, error) {
 	return usage, nil
 }
 
-// calculateCost converts token usage to USD cost based on model pricing.
+// calculateCost converts token usage to USD cost, pricing each model's tokens
+// from the town pricing table.
 func calculateCost(usage *TokenUsage) float64 {
 	if usage == nil {
 		return 0.0
 	}
-
-	// Look up pricing for the model
-	pricing, ok := modelPricing[usage.Model]
-	if !ok {
-		pricing = modelPricing["default"]
+	pricing := costPricing()
+	if len(usage.ByModel) == 0 {
+		return pricing.Cost(costs.Usage{
+			Model:            usage.Model,
+			InputTokens:      usage.InputTokens,
+			OutputTokens:     usage.OutputTokens,
+			CacheReadTokens:  usage.CacheReadInputTokens,
+			CacheWriteTokens: usage.CacheCreationInputTokens,
+		})
 	}
-
-	// Calculate cost (prices are per million tokens)
-	inputCost := float64(usage.InputTokens) / 1_000_000 * pricing.InputPerMillion
-	cacheReadCost := float64(usage.CacheReadInputTokens) / 1_000_000 * pricing.CacheReadPerMillion
-	cacheCreateCost := float64(usage.CacheCreationInputTokens) / 1_000_000 * pricing.CacheCreatePerMillion
-	outputCost := float64(usage.OutputTokens) / 1_000_000 * pricing.OutputPerMillion
-
-	return inputCost + cacheReadCost + cacheCreateCost + outputCost
+	var total float64
+	for _, u := range usage.ByModel {
+		total += pricing.Cost(*u)
+	}
+	return total
 }
 
 // extractCostFromWorkDir extracts cost from Claude Code transcript for a working directory.
 // This reads the most recent transcript file and sums all token usage.
 func extractCostFromWorkDir(workDir string) (float64, error) {
+	return extractCostFromWorkDirSince(workDir, time.Time{})
+}
+
+// extractCostFromWorkDirSince is extractCostFromWorkDir counting only usage
+// recorded at or after since.
+func extractCostFromWorkDirSince(workDir string, since time.Time) (float64, error) {
 	projectDir, err := getClaudeProjectDir(workDir)
 	if err != nil {
 		return 0, fmt.Errorf("getting project dir: %w", err)
@@ -808,7 +907,7 @@ func extractCostFromWorkDir(workDir string) (float64, error) {
 		return 0, fmt.Errorf("finding transcript: %w", err)
 	}
 
-	usage, err := parseTranscriptUsage(transcriptPath)
+	usage, err := parseTranscriptUsageSince(transcriptPath, since)
 	if err != nil {
 		return 0, fmt.Errorf("parsing transcript: %w", err)
 	}
@@ -981,6 +1080,15 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 
 	// Parse session name
 	role, rig, worker := parseSessionName(session)
+	endedAt := time.Now()
+
+	// Without --work-item, charge the session to the bead its agent has
+	// hooked. This reads the town events log rather than beads, so recording
+	// still never depends on the database.
+	workItem := recordWorkItem
+	if workItem == "" {
+		workItem = hookedWorkItem(role, rig, worker, endedAt)
+	}
 
 	// Build log entry
 	entry := CostLogEntry{
@@ -989,8 +1097,8 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 		Rig:       rig,
 		Worker:    worker,
 		CostUSD:   cost,
-		EndedAt:   time.Now(),
-		WorkItem:  recordWorkItem,
+		EndedAt:   endedAt,
+		WorkItem:  workItem,
 	}
 
 	// Marshal to JSON
@@ -1023,10 +1131,10 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 	}
 
 	// Output confirmation (silent if cost is zero and no work item)
-	if cost > 0 || recordWorkItem != "" {
+	if cost > 0 || workItem != "" {
 		fmt.Printf("%s Recorded $%.2f for %s", style.Success.Render("✓"), cost, session)
-		if recordWorkItem != "" {
-			fmt.Printf(" (work: %s)", recordWorkItem)
+		if workItem != "" {
+			fmt.Printf(" (work: %s)", workItem)
 		}
 		fmt.Println()
 	}
@@ -1116,6 +1224,7 @@ type CostDigest struct {
 	Sessions     []CostEntry        `json:"sessions,omitempty"`
 	ByRole       map[string]float64 `json:"by_role"`
 	ByRig        map[string]float64 `json:"by_rig,omitempty"`
+	ByBead       map[string]float64 `json:"by_bead,omitempty"`
 }
 
 // CostDigestPayload is the compact payload stored in the bead.
@@ -1126,6 +1235,7 @@ type CostDigestPayload struct {
 	SessionCount int                `json:"session_count"`
 	ByRole       map[string]float64 `json:"by_role"`
 	ByRig        map[string]float64 `json:"by_rig,omitempty"`
+	ByBead       map[string]float64 `json:"by_bead,omitempty"`
 }
 
 // runCostsDigest aggregates session cost entries into a daily digest bead.
@@ -1158,12 +1268,19 @@ func runCostsDigest(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Attribute sessions recorded without a work item to the bead their
+	// agent had hooked, so the digest keeps per-bead totals.
+	if townRoot, err := workspace.FindFromCwdOrError(); err == nil {
+		attributeCostEntries(townRoot, costEntries)
+	}
+
 	// Build digest
 	digest := CostDigest{
 		Date:     dateStr,
 		Sessions: costEntries,
 		ByRole:   make(map[string]float64),
 		ByRig:    make(map[string]float64),
+		ByBead:   make(map[string]float64),
 	}
 
 	for _, e := range costEntries {
@@ -1173,6 +1290,9 @@ func runCostsDigest(cmd *cobra.Command, args []string) error {
 		if e.Rig != "" {
 			digest.ByRig[e.Rig] += e.CostUSD
 		}
+		if e.WorkItem != "" {
+			digest.ByBead[e.WorkItem] += e.CostUSD
+		}
 	}
 
 	if digestDryRun {
@@ -1189,6 +1309,12 @@ func runCostsDigest(cmd *cobra.Command, args []string) error {
 				fmt.Printf("    %s: $%.2f\n", rig, cost)
 			}
 		}
+		if len(digest.ByBead) > 0 {
+			fmt.Printf("  By Bead:\n")
+			for _, bc := range digestBeadCosts(digest) {
+				fmt.Printf("    %s: $%.2f\n", bc.Bead, bc.USD)
+			}
+		}
 		return nil
 	}
 
@@ -1215,6 +1341,15 @@ func runCostsDigest(cmd *cobra.Command, args []string) error {
 
 // querySessionCostEntries reads session cost entries from the local log file for a target date.
 func querySessionCostEntries(targetDate time.Time) ([]CostEntry, error) {
+	targetDay := targetDate.Format("2006-01-02")
+	return readCostLog(func(e CostLogEntry) bool {
+		return e.EndedAt.Format("2006-01-02") == targetDay
+	})
+}
+
+// readCostLog reads the session cost entries in the local log file for which
+// keep returns true.
+func readCostLog(keep func(CostLogEntry) bool) ([]CostEntry, error) {
 	logPath := getCostsLogPath()
 
 	// Read log file
@@ -1226,7 +1361,6 @@ func querySessionCostEntries(targetDate time.Time) ([]CostEntry, error) {
 		return nil, fmt.Errorf("reading costs log: %w", err)
 	}
 
-	targetDay := targetDate.Format("2006-01-02")
 	var entries []CostEntry
 
 	// Parse each line as a CostLogEntry
@@ -1245,8 +1379,7 @@ func querySessionCostEntries(targetDate time.Time) ([]CostEntry, error) {
 			continue
 		}
 
-		// Filter by target date
-		if logEntry.EndedAt.Format("2006-01-02") != targetDay {
+		if !keep(logEntry) {
 			continue
 		}
 
@@ -1264,6 +1397,10 @@ func querySessionCostEntries(targetDate time.Time) ([]CostEntry, error) {
 	return entries, nil
 }
 
+// digestDescriptionBeads caps the beads listed in a digest bead's description;
+// the payload keeps every bead.
+const digestDescriptionBeads = 20
+
 // createCostDigestBead creates a permanent bead for the daily cost digest.
 func createCostDigestBead(digest CostDigest) (string, error) {
 	// Build description with aggregate data
@@ -1298,6 +1435,19 @@ func createCostDigestBead(digest CostDigest) (string, error) {
 		desc.WriteString("\n")
 	}
 
+	if len(digest.ByBead) > 0 {
+		desc.WriteString("## By Bead\n")
+		beadCosts := digestBeadCosts(digest)
+		for i, bc := range beadCosts {
+			if i == digestDescriptionBeads {
+				desc.WriteString(fmt.Sprintf("- ... %d more (see payload)\n", len(beadCosts)-i))
+				break
+			}
+			desc.WriteString(fmt.Sprintf("- %s: $%.2f\n", bc.Bead, bc.USD))
+		}
+		desc.WriteString("\n")
+	}
+
 	// Build compact payload (aggregate only, no per-session details).
 	// Per-session details can be thousands of records and exceed Dolt column limits.
 	compactPayload := CostDigestPayload{
@@ -1306,6 +1456,7 @@ func createCostDigestBead(digest CostDigest) (string, error) {
 		SessionCount: digest.SessionCount,
 		ByRole:       digest.ByRole,
 		ByRig:        digest.ByRig,
+		ByBead:       digest.ByBead,
 	}
 	payloadJSON, err := json.Marshal(compactPayload)
 	if err != nil {
@@ -1397,4 +1548,3 @@ func deleteSessionCostEntries(targetDate time.Time) (int, error) {
 
 	return deletedCount, nil
 }
-
@@ -0,0 +1,435 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/costs"
+	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// epicAncestorDepth bounds the parent chain walked when rolling bead costs up
+// to epics.
+const epicAncestorDepth = 5
+
+// CostsByWorkOutput is the JSON output of gt costs --by-bead / --by-convoy.
+type CostsByWorkOutput struct {
+	Period          string            `json:"period"`
+	TotalUSD        float64           `json:"total_usd"`
+	UnattributedUSD float64           `json:"unattributed_usd"`
+	Beads           []BeadCostRow     `json:"beads,omitempty"`
+	Epics           []costs.GroupCost `json:"epics,omitempty"`
+	Convoys         []costs.GroupCost `json:"convoys,omitempty"`
+}
+
+// BeadCostRow is the cost attributed to one bead, with the MR that merged it.
+type BeadCostRow struct {
+	costs.BeadCost
+	Title string `json:"title,omitempty"`
+	MR    string `json:"mr,omitempty"`
+}
+
+// liveSessionCost is the running cost of a live Gas Town session.
+type liveSessionCost struct {
+	Session string
+	Role    string
+	Rig     string
+	Worker  string
+	USD     float64
+	Bead    string // hooked bead; set only when hooks were requested
+}
+
+// liveSessionCosts measures every live Gas Town session from its transcript,
+// counting usage recorded at or after since (everything when zero). Sessions
+// are listed through the town's session backend, so headless sessions count
+// too. With withHooks, polecat and crew sessions carry the bead on their hook.
+func liveSessionCosts(townRoot string, since time.Time, withHooks bool) []liveSessionCost {
+	var live []liveSessionCost
+	backend := session.NewBackend(townRoot)
+	sessions, _ := backend.ListSessions()
+	for _, sess := range sessions {
+		if !session.IsKnownSession(sess) {
+			continue
+		}
+		role, rig, worker := parseSessionName(sess)
+		workDir, err := backend.GetPaneWorkDir(sess)
+		if err != nil {
+			continue
+		}
+		cost, _ := extractCostFromWorkDirSince(workDir, since)
+		lc := liveSessionCost{Session: sess, Role: role, Rig: rig, Worker: worker, USD: cost}
+		if agent := costs.AgentForSession(role, rig, worker); withHooks && agent != "" {
+			rigBeads := beads.New(beads.ResolveBeadsDir(filepath.Join(townRoot, rig)))
+			lc.Bead = findHookedBeadForAgent(rigBeads, agent)
+		}
+		live = append(live, lc)
+	}
+	return live
+}
+
+// hookedWorkItem returns the bead a polecat or crew session's agent had
+// hooked at t, from the town events log, or "" if unknown.
+func hookedWorkItem(role, rig, worker string, t time.Time) string {
+	agent := costs.AgentForSession(role, rig, worker)
+	if agent == "" {
+		return ""
+	}
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return ""
+	}
+	history, err := costs.LoadHistory(townRoot)
+	if err != nil {
+		if costsVerbose {
+			fmt.Fprintf(os.Stderr, "[costs] could not read hook history: %v\n", err)
+		}
+		return ""
+	}
+	return history.BeadAt(agent, t)
+}
+
+// attributeCostEntries fills in the work item of entries recorded without
+// one from the hook history in townRoot's events log.
+func attributeCostEntries(townRoot string, entries []CostEntry) {
+	history, err := costs.LoadHistory(townRoot)
+	if err != nil {
+		if costsVerbose {
+			fmt.Fprintf(os.Stderr, "[costs] could not read hook history: %v\n", err)
+		}
+		return
+	}
+	ce := toCostsEntries(entries)
+	costs.Attribute(ce, history)
+	for i := range entries {
+		entries[i].WorkItem = ce[i].WorkItem
+	}
+}
+
+func toCostsEntries(entries []CostEntry) []costs.Entry {
+	out := make([]costs.Entry, len(entries))
+	for i, e := range entries {
+		out[i] = costs.Entry{
+			Session:  e.SessionID,
+			Agent:    costs.AgentForSession(e.Role, e.Rig, e.Worker),
+			WorkItem: e.WorkItem,
+			USD:      e.CostUSD,
+			EndedAt:  e.EndedAt,
+		}
+	}
+	return out
+}
+
+// digestBeadCosts returns a digest's per-bead totals, most expensive first.
+func digestBeadCosts(digest CostDigest) []costs.BeadCost {
+	beadCosts := make([]costs.BeadCost, 0, len(digest.ByBead))
+	for bead, usd := range digest.ByBead {
+		beadCosts = append(beadCosts, costs.BeadCost{Bead: bead, USD: usd})
+	}
+	return costs.MergeBeadCosts(beadCosts)
+}
+
+// collectBeadCosts returns the cost attributed to each bead since since (all
+// time when since is zero): daily digests, then the costs log, then live
+// sessions, which replace today's log records for the same session.
+func collectBeadCosts(townRoot string, since time.Time) (beadCosts []costs.BeadCost, total, unattributed float64, err error) {
+	now := time.Now()
+	today := now.Format("2006-01-02")
+
+	var sets [][]costs.BeadCost
+	if since.IsZero() || since.Format("2006-01-02") != today {
+		days := 0
+		if !since.IsZero() {
+			days = int(now.Sub(since).Hours()/24) + 1
+		}
+		digests, err := queryCostDigests(days)
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("querying digest beads: %w", err)
+		}
+		for _, digest := range digests {
+			if !since.IsZero() && digest.Date < since.Format("2006-01-02") {
+				continue
+			}
+			if len(digest.Sessions) > 0 {
+				// Old-format digest: attribute its sessions like log entries.
+				attributeCostEntries(townRoot, digest.Sessions)
+				beads, un := costs.ByBead(toCostsEntries(digest.Sessions))
+				sets = append(sets, beads)
+				total += digest.TotalUSD
+				unattributed += un
+				continue
+			}
+			beads := digestBeadCosts(digest)
+			attributed := 0.0
+			for _, bc := range beads {
+				attributed += bc.USD
+			}
+			sets = append(sets, beads)
+			total += digest.TotalUSD
+			unattributed += digest.TotalUSD - attributed
+		}
+	}
+
+	live := liveSessionCosts(townRoot, since, true)
+	liveIDs := make(map[string]bool, len(live))
+	for _, lc := range live {
+		liveIDs[lc.Session] = true
+	}
+	logged, err := readCostLog(func(e CostLogEntry) bool {
+		if !since.IsZero() && e.EndedAt.Before(since) {
+			return false
+		}
+		return !(liveIDs[e.SessionID] && e.EndedAt.Format("2006-01-02") == today)
+	})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	for _, lc := range live {
+		logged = append(logged, CostEntry{
+			SessionID: lc.Session,
+			Role:      lc.Role,
+			Rig:       lc.Rig,
+			Worker:    lc.Worker,
+			CostUSD:   lc.USD,
+			EndedAt:   now,
+			WorkItem:  lc.Bead,
+		})
+	}
+	attributeCostEntries(townRoot, logged)
+	beads, un := costs.ByBead(toCostsEntries(logged))
+	sets = append(sets, beads)
+	unattributed += un
+	for _, e := range logged {
+		total += e.CostUSD
+	}
+
+	return costs.MergeBeadCosts(sets...), total, unattributed, nil
+}
+
+// costsPeriodStart returns the start of the --today/--week period and its
+// label; the zero time means all recorded costs.
+func costsPeriodStart(now time.Time) (time.Time, string) {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch {
+	case costsToday:
+		return midnight, "today"
+	case costsWeek:
+		return midnight.AddDate(0, 0, -7), "this week"
+	default:
+		return time.Time{}, "all time"
+	}
+}
+
+// runCostsByWork reports costs attributed to beads, epics, merged MRs and
+// convoys.
+func runCostsByWork() error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	since, period := costsPeriodStart(time.Now())
+	beadCosts, total, unattributed, err := collectBeadCosts(townRoot, since)
+	if err != nil {
+		return err
+	}
+
+	output := CostsByWorkOutput{
+		Period:          period,
+		TotalUSD:        total,
+		UnattributedUSD: unattributed,
+	}
+	if costsByBead {
+		output.Beads, output.Epics = beadCostRows(townRoot, beadCosts)
+	}
+	if costsByConvoy {
+		output.Convoys, err = convoyCosts(townRoot, beadCosts)
+		if err != nil {
+			return err
+		}
+	}
+
+	if costsJSON {
+		return outputJSON(output)
+	}
+	printCostsByWork(output)
+	return nil
+}
+
+// beadCostRows adds titles and merged MRs to bead costs and rolls them up to
+// the epics above them.
+func beadCostRows(townRoot string, beadCosts []costs.BeadCost) ([]BeadCostRow, []costs.GroupCost) {
+	ids := make([]string, 0, len(beadCosts))
+	for _, bc := range beadCosts {
+		ids = append(ids, bc.Bead)
+	}
+	details := getIssueDetailsBatch(ids)
+	mrs := mergedMRsBySource(townRoot)
+
+	rows := make([]BeadCostRow, 0, len(beadCosts))
+	for _, bc := range beadCosts {
+		row := BeadCostRow{BeadCost: bc, MR: mrs[bc.Bead]}
+		if d := details[bc.Bead]; d != nil {
+			row.Title = d.Title
+		}
+		rows = append(rows, row)
+	}
+
+	// Walk up the parent chain, fetching each level's parents in one batch.
+	parent := make(map[string]string)
+	isEpic := make(map[string]bool)
+	for depth := 0; depth < epicAncestorDepth && len(details) > 0; depth++ {
+		var next []string
+		for id, d := range details {
+			p := issueParent(d)
+			if p == "" {
+				continue
+			}
+			if _, seen := parent[id]; !seen {
+				parent[id] = p
+				next = append(next, p)
+			}
+		}
+		details = getIssueDetailsBatch(next)
+		for id, d := range details {
+			isEpic[id] = d.IssueType == "epic"
+		}
+	}
+	epics := costs.RollupAncestors(beadCosts, parent, func(id string) bool { return isEpic[id] })
+	return rows, epics
+}
+
+// issueParent returns an issue's parent, from its parent field or its
+// parent-child dependency.
+func issueParent(d *issueDetails) string {
+	if d.Parent != "" {
+		return d.Parent
+	}
+	for _, dep := range d.Dependencies {
+		if dep.DependencyType == "parent-child" {
+			return dep.ID
+		}
+	}
+	return ""
+}
+
+// mergedMRsBySource maps each work bead to the merge-request bead that merged
+// it, across all rigs. Failures are skipped: MRs only annotate the report.
+func mergedMRsBySource(townRoot string) map[string]string {
+	mrs := make(map[string]string)
+	for _, rigName := range discoverRigs(townRoot) {
+		rigBeads := beads.New(beads.ResolveBeadsDir(filepath.Join(townRoot, rigName)))
+		issues, err := rigBeads.ListMergeRequests(beads.ListOptions{
+			Status:   "closed",
+			Label:    "gt:merge-request",
+			Priority: -1,
+		})
+		if err != nil {
+			if costsVerbose {
+				fmt.Fprintf(os.Stderr, "[costs] listing merge requests in %s: %v\n", rigName, err)
+			}
+			continue
+		}
+		for _, issue := range issues {
+			fields := beads.ParseMRFields(issue)
+			if fields == nil || fields.SourceIssue == "" {
+				continue
+			}
+			if fields.CloseReason != "" && fields.CloseReason != "merged" {
+				continue
+			}
+			mrs[fields.SourceIssue] = issue.ID
+		}
+	}
+	return mrs
+}
+
+// convoyCosts rolls bead costs up to every open convoy.
+func convoyCosts(townRoot string, beadCosts []costs.BeadCost) ([]costs.GroupCost, error) {
+	out, err := runBdJSON(townRoot, "list", "--type=convoy", "--status=open", "--json")
+	if err != nil {
+		return nil, fmt.Errorf("listing convoys: %w", err)
+	}
+	var convoys []struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(out, &convoys); err != nil {
+		return nil, fmt.Errorf("parsing convoy list: %w", err)
+	}
+
+	members := make(map[string][]string, len(convoys))
+	for _, c := range convoys {
+		ids, err := getTrackedIDs(townRoot, c.ID)
+		if err != nil {
+			if costsVerbose {
+				fmt.Fprintf(os.Stderr, "[costs] %v\n", err)
+			}
+			continue
+		}
+		members[c.ID] = ids
+	}
+	return costs.Rollup(beadCosts, members), nil
+}
+
+// convoyCost returns the cost attributed to the beads a convoy tracks since
+// the convoy was created.
+func convoyCost(townRoot string, trackedIDs []string, createdAt time.Time) (float64, error) {
+	beadCosts, _, _, err := collectBeadCosts(townRoot, createdAt)
+	if err != nil {
+		return 0, err
+	}
+	groups := costs.Rollup(beadCosts, map[string][]string{"": trackedIDs})
+	return groups[0].USD, nil
+}
+
+func printCostsByWork(output CostsByWorkOutput) {
+	fmt.Printf("\n%s Cost by Work (%s)\n\n", style.Bold.Render("📊"), output.Period)
+	fmt.Printf("%s $%.2f", style.Bold.Render("Total:"), output.TotalUSD)
+	if output.UnattributedUSD > 0.005 {
+		fmt.Printf("  %s", style.Dim.Render(fmt.Sprintf("($%.2f not attributed to a bead)", output.UnattributedUSD)))
+	}
+	fmt.Println()
+
+	if costsByBead {
+		fmt.Printf("\n%s\n", style.Bold.Render("By Bead:"))
+		if len(output.Beads) == 0 {
+			fmt.Printf("  %s\n", style.Dim.Render("(no costs attributed to beads)"))
+		}
+		for _, row := range output.Beads {
+			line := fmt.Sprintf("  %-14s $%8.2f  %s", row.Bead, row.USD, truncateCostTitle(row.Title))
+			if row.MR != "" {
+				line += "  " + style.Dim.Render("merged by "+row.MR)
+			}
+			fmt.Println(line)
+		}
+		if len(output.Epics) > 0 {
+			fmt.Printf("\n%s\n", style.Bold.Render("By Epic:"))
+			for _, g := range output.Epics {
+				fmt.Printf("  %-14s $%8.2f  %s\n", g.ID, g.USD, style.Dim.Render(fmt.Sprintf("%d beads", g.Beads)))
+			}
+		}
+	}
+
+	if costsByConvoy {
+		fmt.Printf("\n%s\n", style.Bold.Render("By Convoy:"))
+		if len(output.Convoys) == 0 {
+			fmt.Printf("  %s\n", style.Dim.Render("(no open convoys)"))
+		}
+		for _, g := range output.Convoys {
+			fmt.Printf("  🚚 %-14s $%8.2f  %s\n", g.ID, g.USD, style.Dim.Render(fmt.Sprintf("%d beads", g.Beads)))
+		}
+	}
+}
+
+func truncateCostTitle(title string) string {
+	const max = 50
+	if len(title) <= max {
+		return title
+	}
+	return strings.TrimSpace(title[:max-3]) + "..."
+}
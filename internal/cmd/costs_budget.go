@@ -0,0 +1,351 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/budget"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/nudge"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// budgetSender is the From address on budget warnings and nudges.
+const budgetSender = "gt-budget"
+
+var (
+	budgetJSON   bool
+	budgetDryRun bool
+)
+
+var costsBudgetCmd = &cobra.Command{
+	Use:   "budget",
+	Short: "Show spend against today's cost budgets",
+	Long: `Show today's spend against the budgets in town and rig settings.
+
+Budgets are daily USD limits per rig, role, convoy and bead, set under
+"budgets" in settings/config.json (town) or <rig>/settings/config.json:
+
+  "budgets": {
+    "rig":     {"soft_usd": 40, "hard_usd": 60},
+    "roles":   {"polecat": {"hard_usd": 150}},
+    "convoys": {"hq-cv-abc": {"soft_usd": 25}},
+    "beads":   {"gt-xyz": {"hard_usd": 10}},
+    "hard_action": "handoff"
+  }
+
+In town settings "rig" is the default for every rig and "roles" are
+town-wide; in rig settings they apply to that rig only. Spend is today's
+recorded sessions (gt costs record) plus live session transcripts.
+
+The daemon runs 'gt costs budget check' every heartbeat. At a soft limit the
+mayor is mailed once a day. At a hard limit an escalation is raised, the
+scheduler stops dispatching polecats to the rig (rig and polecat budgets),
+and live sessions in scope are asked to hand off and stop. With
+"hard_action": "park" an over-budget rig is also parked.
+
+Examples:
+  gt costs budget
+  gt costs budget --json
+  gt costs budget check --dry-run`,
+	RunE: runCostsBudget,
+}
+
+var costsBudgetCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Evaluate budgets and enforce limits (run by the daemon)",
+	Long: `Evaluate today's spend against the budgets, record the result for the
+scheduler and gt vitals, and act on limits reached since the last check.
+
+Warnings and escalations are sent once per limit per day; each live session
+is asked to wind down once per day.`,
+	Args: cobra.NoArgs,
+	RunE: runCostsBudgetCheck,
+}
+
+func init() {
+	costsBudgetCmd.PersistentFlags().BoolVar(&budgetJSON, "json", false, "Output as JSON")
+	costsBudgetCheckCmd.Flags().BoolVar(&budgetDryRun, "dry-run", false, "Show what would be done without acting or saving state")
+	costsBudgetCmd.AddCommand(costsBudgetCheckCmd)
+	costsCmd.AddCommand(costsBudgetCmd)
+}
+
+func runCostsBudget(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	statuses, err := evaluateBudgets(townRoot, time.Now())
+	if err != nil {
+		return err
+	}
+	if budgetJSON {
+		return outputJSON(statuses)
+	}
+	printBudgetStatuses(statuses)
+	return nil
+}
+
+func runCostsBudgetCheck(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	now := time.Now()
+	statuses, err := evaluateBudgets(townRoot, now)
+	if err != nil {
+		return err
+	}
+
+	state, err := budget.LoadState(townRoot)
+	if err != nil {
+		return fmt.Errorf("loading budget state: %w", err)
+	}
+	if len(statuses) == 0 && len(state.Statuses) == 0 {
+		return nil // No budgets configured
+	}
+	raised := state.Record(now, statuses)
+
+	for _, st := range raised {
+		switch st.Level {
+		case budget.LevelWarn:
+			warnBudget(townRoot, st)
+		case budget.LevelOver:
+			escalateBudget(townRoot, st)
+		}
+	}
+	for _, st := range statuses {
+		if st.Level == budget.LevelOver {
+			windDownBudget(townRoot, st, state)
+		}
+	}
+
+	if !budgetDryRun {
+		if err := budget.SaveState(townRoot, state); err != nil {
+			return fmt.Errorf("saving budget state: %w", err)
+		}
+	}
+	if budgetJSON {
+		return outputJSON(statuses)
+	}
+	return nil
+}
+
+// evaluateBudgets computes today's spend against every configured budget.
+func evaluateBudgets(townRoot string, now time.Time) ([]budget.Status, error) {
+	limits, err := loadBudgetLimits(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	if len(limits) == 0 {
+		return nil, nil
+	}
+
+	needBeads := false
+	convoys := make(map[string][]string)
+	for _, l := range limits {
+		switch l.Scope {
+		case budget.ScopeBead:
+			needBeads = true
+		case budget.ScopeConvoy:
+			needBeads = true
+			tracked, err := getTrackedIssues(townRoot, l.Key)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: budget for convoy %s: %v\n", l.Key, err)
+				continue
+			}
+			for _, t := range tracked {
+				convoys[l.Key] = append(convoys[l.Key], t.ID)
+			}
+		}
+	}
+
+	spends, err := collectBudgetSpend(townRoot, now, needBeads)
+	if err != nil {
+		return nil, err
+	}
+	return budget.Evaluate(limits, spends, convoys), nil
+}
+
+// loadBudgetLimits reads budgets from town settings and every rig's settings.
+func loadBudgetLimits(townRoot string) ([]budget.Limit, error) {
+	townSettings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil {
+		return nil, fmt.Errorf("loading town settings: %w", err)
+	}
+	rigs := make(map[string]*config.BudgetsConfig)
+	for _, rigName := range discoverRigs(townRoot) {
+		rigSettings, err := config.LoadRigSettings(config.RigSettingsPath(filepath.Join(townRoot, rigName)))
+		if errors.Is(err, config.ErrNotFound) {
+			rigs[rigName] = nil
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("loading settings for rig %s: %w", rigName, err)
+		}
+		rigs[rigName] = rigSettings.Budgets
+	}
+	return budget.Limits(townSettings.Budgets, rigs), nil
+}
+
+// collectBudgetSpend returns today's spend per session: sessions recorded in
+// the costs log, with live sessions counted from their transcript instead of
+// their earlier records. Only transcript usage since the start of now's day
+// counts. With needBeads, live polecat and crew sessions are attributed to
+// the bead on their hook.
+func collectBudgetSpend(townRoot string, now time.Time, needBeads bool) ([]budget.Spend, error) {
+	entries, err := querySessionCostEntries(now)
+	if err != nil {
+		return nil, err
+	}
+
+	var live []budget.Spend
+	liveIDs := make(map[string]bool)
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for _, lc := range liveSessionCosts(townRoot, dayStart, needBeads) {
+		live = append(live, budget.Spend{Session: lc.Session, Rig: lc.Rig, Role: lc.Role, Bead: lc.Bead, USD: lc.USD, Live: true})
+		liveIDs[lc.Session] = true
+	}
+
+	spends := live
+	for _, e := range entries {
+		if liveIDs[e.SessionID] {
+			continue
+		}
+		spends = append(spends, budget.Spend{
+			Session: e.SessionID,
+			Rig:     e.Rig,
+			Role:    e.Role,
+			Bead:    e.WorkItem,
+			USD:     e.CostUSD,
+		})
+	}
+	return spends, nil
+}
+
+// warnBudget mails the mayor that a soft limit was reached.
+func warnBudget(townRoot string, st budget.Status) {
+	subject := fmt.Sprintf("Budget warning: %s at $%.2f of $%.2f", st.ID(), st.SpentUSD, st.SoftUSD)
+	if budgetDryRun {
+		fmt.Printf("Would mail mayor/: %s\n", subject)
+		return
+	}
+	body := fmt.Sprintf("Today's spend for %s has reached its soft limit.\n\nSpent: $%.2f\nSoft limit: $%.2f\n",
+		st.ID(), st.SpentUSD, st.SoftUSD)
+	if st.HardUSD > 0 {
+		body += fmt.Sprintf("Hard limit: $%.2f (then: %s)\n", st.HardUSD, st.HardAction)
+	}
+	body += "\nSee: gt costs budget"
+
+	router := mail.NewRouter(townRoot)
+	defer router.WaitPendingNotifications()
+	if err := router.Send(&mail.Message{
+		From:     budgetSender,
+		To:       "mayor/",
+		Subject:  subject,
+		Body:     body,
+		Priority: mail.PriorityHigh,
+		Type:     mail.TypeNotification,
+	}); err != nil {
+		style.PrintWarning("could not send budget warning for %s: %v", st.ID(), err)
+	}
+}
+
+// escalateBudget raises an escalation for a hard limit and parks the rig when
+// the limit's hard action asks for it.
+func escalateBudget(townRoot string, st budget.Status) {
+	desc := fmt.Sprintf("Budget exceeded: %s at $%.2f of $%.2f", st.ID(), st.SpentUSD, st.HardUSD)
+	parkRig := ""
+	if st.HardAction == config.BudgetActionPark {
+		switch {
+		case st.Scope == budget.ScopeRig:
+			parkRig = st.Key
+		case st.Rig != "":
+			parkRig = st.Rig
+		}
+	}
+
+	if budgetDryRun {
+		fmt.Printf("Would escalate: %s\n", desc)
+		if parkRig != "" {
+			fmt.Printf("Would park rig %s\n", parkRig)
+		}
+		return
+	}
+	if err := exec.Command("gt", "escalate", "--severity", "high", "--source", "budget:"+st.ID(), desc).Run(); err != nil { //nolint:gosec // G204: args are constructed internally
+		style.PrintWarning("could not escalate %s: %v", st.ID(), err)
+	}
+	if parkRig != "" && !IsRigParked(townRoot, parkRig) {
+		if err := parkOneRig(parkRig); err != nil {
+			style.PrintWarning("could not park over-budget rig %s: %v", parkRig, err)
+		}
+	}
+}
+
+// windDownBudget asks each live session charged to an over-budget limit to
+// hand off and stop, once per session per day.
+func windDownBudget(townRoot string, st budget.Status, state *budget.State) {
+	for _, sess := range st.Sessions {
+		if !state.MarkWoundDown(sess, st.ID()) {
+			continue
+		}
+		if budgetDryRun {
+			fmt.Printf("Would ask %s to hand off (%s)\n", sess, st.ID())
+			continue
+		}
+		msg := fmt.Sprintf("Budget hard limit reached (%s: $%.2f of $%.2f). Wrap up now: commit and push your work, "+
+			"record where you stopped with gt handoff --auto -s \"Budget limit\" -m \"<state>\", then stop. Do not start new work today.",
+			st.ID(), st.SpentUSD, st.HardUSD)
+		if err := nudge.Enqueue(townRoot, sess, nudge.QueuedNudge{
+			Sender:   budgetSender,
+			Message:  msg,
+			Priority: nudge.PriorityUrgent,
+		}); err != nil {
+			style.PrintWarning("could not nudge %s: %v", sess, err)
+		}
+	}
+}
+
+func printBudgetStatuses(statuses []budget.Status) {
+	if len(statuses) == 0 {
+		fmt.Printf("%s\n", style.Dim.Render("(no budgets configured)"))
+		return
+	}
+	sort.SliceStable(statuses, func(i, j int) bool { return statuses[i].ID() < statuses[j].ID() })
+	for _, st := range statuses {
+		fmt.Printf("  %s %-28s $%8.2f  %s\n", budgetLevelIcon(st.Level), st.ID(), st.SpentUSD, describeBudgetLimit(st.Limit))
+	}
+}
+
+func describeBudgetLimit(l budget.Limit) string {
+	var s string
+	if l.SoftUSD > 0 {
+		s = fmt.Sprintf("soft $%.2f", l.SoftUSD)
+	}
+	if l.HardUSD > 0 {
+		if s != "" {
+			s += ", "
+		}
+		s += fmt.Sprintf("hard $%.2f (%s)", l.HardUSD, l.HardAction)
+	}
+	return style.Dim.Render(s)
+}
+
+func budgetLevelIcon(level budget.Level) string {
+	switch level {
+	case budget.LevelOver:
+		return style.Error.Render("●")
+	case budget.LevelWarn:
+		return style.Warning.Render("●")
+	default:
+		return style.Success.Render("●")
+	}
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/budget"
+	"github.com/steveyegge/gastown/internal/scheduler/capacity"
+)
+
+func TestFilterOverBudgetRigs(t *testing.T) {
+	townRoot := t.TempDir()
+	pending := []capacity.PendingBead{
+		{ID: "ctx-1", WorkBeadID: "gt-1", TargetRig: "gastown"},
+		{ID: "ctx-2", WorkBeadID: "bd-1", TargetRig: "beads"},
+	}
+
+	// No budget state: nothing is filtered.
+	if got := filterOverBudgetRigs(townRoot, append([]capacity.PendingBead(nil), pending...)); len(got) != 2 {
+		t.Fatalf("without budget state kept %d, want 2", len(got))
+	}
+
+	state := &budget.State{}
+	state.Record(time.Now(), []budget.Status{
+		{Limit: budget.Limit{Scope: budget.ScopeRig, Key: "gastown", HardUSD: 10}, SpentUSD: 12, Level: budget.LevelOver},
+		{Limit: budget.Limit{Scope: budget.ScopeRig, Key: "beads", HardUSD: 10}, SpentUSD: 9, Level: budget.LevelOK},
+	})
+	if err := budget.SaveState(townRoot, state); err != nil {
+		t.Fatal(err)
+	}
+
+	got := filterOverBudgetRigs(townRoot, append([]capacity.PendingBead(nil), pending...))
+	if len(got) != 1 || got[0].TargetRig != "beads" {
+		t.Errorf("kept %+v, want only the beads rig", got)
+	}
+}
@@ -0,0 +1,87 @@
+//go:build linux
+
+package cmd
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/ptyhost"
+)
+
+// Live costs must see headless sessions and, for budgets, count only the
+// transcript usage recorded inside the window.
+func TestLiveSessionCosts_HeadlessSessionWithinWindow(t *testing.T) {
+	// Unix socket paths are length-limited, so avoid t.TempDir's long names.
+	townRoot, err := os.MkdirTemp("", "costs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(townRoot) })
+
+	settings := config.NewTownSettings()
+	settings.SessionBackend = config.SessionBackendHeadless
+	if err := config.SaveTownSettings(config.TownSettingsPath(townRoot), settings); err != nil {
+		t.Fatal(err)
+	}
+
+	h := ptyhost.NewHost()
+	t.Cleanup(h.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	socket := ptyhost.SocketPath(townRoot)
+	go func() { _ = ptyhost.Serve(ctx, h, socket) }()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(socket); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for ptyhost socket")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	workDir := filepath.Join(townRoot, "mayor")
+	if err := os.MkdirAll(workDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.NewSession("hq-mayor", workDir, "sleep 30"); err != nil {
+		t.Fatal(err)
+	}
+
+	// One million opus input tokens yesterday and again today.
+	claudeDir := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", claudeDir)
+	projectDir := filepath.Join(claudeDir, "projects", strings.ReplaceAll(workDir, "/", "-"))
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	entry := func(at time.Time) string {
+		return `{"type":"assistant","timestamp":"` + at.UTC().Format(time.RFC3339) +
+			`","message":{"model":"claude-opus-4-5-20251101","role":"assistant","usage":{"input_tokens":1000000}}}`
+	}
+	transcript := entry(now.Add(-24*time.Hour)) + "\n" + entry(now) + "\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "session.jsonl"), []byte(transcript), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	all := liveSessionCosts(townRoot, time.Time{}, false)
+	if len(all) != 1 || all[0].Session != "hq-mayor" {
+		t.Fatalf("liveSessionCosts = %+v, want the headless hq-mayor session", all)
+	}
+	windowed := liveSessionCosts(townRoot, now.Add(-time.Hour), false)
+	if len(windowed) != 1 {
+		t.Fatalf("windowed liveSessionCosts = %+v, want one session", windowed)
+	}
+	if got, want := windowed[0].USD, all[0].USD/2; want == 0 || math.Abs(got-want) > 1e-9 {
+		t.Errorf("windowed cost = %v, want %v (today's usage only)", got, want)
+	}
+}
@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"encoding/json"
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -324,3 +326,29 @@ func TestGetClaudeProjectDir_RespectsEnvVar(t *testing.T) {
 		t.Errorf("getClaudeProjectDir() = %q, want %q", got, want)
 	}
 }
+
+func TestCalculateCost_PricesEachModel(t *testing.T) {
+	lines := []string{
+		`{"type":"assistant","message":{"model":"claude-opus-4-5-20251101","role":"assistant","usage":{"input_tokens":1000000,"output_tokens":100000}}}`,
+		`{"type":"user","message":{"role":"user"}}`,
+		`{"type":"assistant","message":{"model":"claude-haiku-4-5-20251001","role":"assistant","usage":{"input_tokens":1000000,"cache_read_input_tokens":1000000}}}`,
+		`not json`,
+	}
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	usage, err := parseTranscriptUsage(path)
+	if err != nil {
+		t.Fatalf("parseTranscriptUsage: %v", err)
+	}
+	if usage.Model != "claude-opus-4-5-20251101" || usage.InputTokens != 2000000 || len(usage.ByModel) != 2 {
+		t.Fatalf("usage = %+v, want opus first, 2M input, 2 models", usage)
+	}
+
+	// Opus 4.5: $15 input + $7.50 output; Haiku 4.5: $1 input + $0.10 cache read.
+	if got, want := calculateCost(usage), 23.60; math.Abs(got-want) > 1e-9 {
+		t.Errorf("calculateCost = %v, want %v", got, want)
+	}
+}
@@ -88,7 +88,7 @@ func runDashboard(cmd *cobra.Command, args []string) error {
 			fmt.Fprintf(cmd.ErrOrStderr(), "warning: loading town settings: %v (using defaults)\n", loadErr)
 		}
 
-		handler, err = web.NewDashboardMux(fetcher, webCfg)
+		handler, err = web.NewDashboardMux(fetcher, townRoot, webCfg)
 		if err != nil {
 			return fmt.Errorf("creating dashboard handler: %w", err)
 		}
@@ -985,6 +985,9 @@ func runDogDispatch(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("finding plugin: %w", err)
 	}
+	if err := plugin.Verify(townRoot, p); err != nil {
+		return err
+	}
 
 	// Get dog manager (reuse rigsConfig from above)
 	mgr := dog.NewManager(townRoot, rigsConfig)
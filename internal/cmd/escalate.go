@@ -44,8 +44,12 @@ WORKFLOW:
 
 CONFIGURATION:
   Routing is configured in ~/gt/settings/escalation.json:
-  - routes: Map severity to action lists (bead, mail:mayor, email:human, sms:human)
+  - routes: Map severity to action lists (bead, mail:mayor, email:human, sms:human,
+    webhook:<name>, incident:<name>)
   - contacts: Human email/SMS for external notifications
+  - notifiers: Named HTTP endpoints (url, headers, body template) for webhook:
+    and incident: actions. Incidents are keyed on the escalation ID, so ack and
+    close acknowledge and resolve the remote incident too.
   - stale_threshold: When unacked escalations are re-escalated (default: 4h)
   - max_reescalations: How many times to bump severity (default: 2)
 
@@ -91,6 +91,8 @@ func runEscalate(cmd *cobra.Command, args []string) error {
 		EscalatedBy: agentID,
 		EscalatedAt: time.Now().Format(time.RFC3339),
 		RelatedBead: escalateRelatedBead,
+		// Recorded up front so ack/close can update remote incidents
+		Incidents: strings.Join(config.IncidentNotifiers(escalationConfig.GetRouteForSeverity(severity)), ","),
 	}
 
 	issue, err := bd.CreateEscalationBead(description, fields)
@@ -163,6 +165,10 @@ func runEscalate(cmd *cobra.Command, args []string) error {
 	// Process external notification actions (email:, sms:, slack, log)
 	statuses = append(statuses, executeExternalActions(actions, escalationConfig, issue.ID, severity, description, townRoot)...)
 
+	// Process notifier actions (webhook:<name>, incident:<name>)
+	notice := newEscalationNotice(config.NotifierEventTrigger, issue.ID, description, fields)
+	statuses = append(statuses, executeNotifierActions(actions, escalationConfig, notice)...)
+
 	// Log to activity feed
 	payload := events.EscalationPayload(issue.ID, agentID, strings.Join(targets, ","), description)
 	payload["severity"] = severity
@@ -336,6 +342,7 @@ func runEscalateAck(cmd *cobra.Command, args []string) error {
 	})
 
 	fmt.Printf("%s Escalation acknowledged: %s\n", style.Bold.Render("✓"), escalationID)
+	notifyEscalationIncidents(bd, townRoot, escalationID, config.NotifierEventAcknowledge, ackedBy, "")
 	return nil
 }
 
@@ -367,6 +374,7 @@ func runEscalateClose(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("%s Escalation closed: %s\n", style.Bold.Render("✓"), escalationID)
 	fmt.Printf("  Reason: %s\n", escalateCloseReason)
+	notifyEscalationIncidents(bd, townRoot, escalationID, config.NotifierEventResolve, closedBy, escalateCloseReason)
 	return nil
 }
 
@@ -480,6 +488,18 @@ func runEscalateStale(cmd *cobra.Command, args []string) error {
 				}
 			}
 
+			// Trigger notifiers on the new route; incidents keep the same
+			// dedup key, so an already-open remote incident is updated.
+			if incidents := config.IncidentNotifiers(actions); len(incidents) > 0 {
+				if err := bd.AddEscalationIncidents(result.ID, incidents); err != nil {
+					style.PrintWarning("failed to record incidents on %s: %v", result.ID, err)
+				}
+			}
+			notice := newEscalationNotice(config.NotifierEventTrigger, result.ID, result.Title, beads.ParseEscalationFields(issue.Description))
+			notice.Severity = result.NewSeverity
+			notice.Actor = reescalatedBy
+			_ = executeNotifierActions(actions, escalationConfig, notice)
+
 			// Log to activity feed
 			_ = events.LogFeed(events.TypeEscalationSent, reescalatedBy, map[string]interface{}{
 				"escalation_id":    result.ID,
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// defaultNotifierTimeout bounds a notifier request when none is configured.
+const defaultNotifierTimeout = 10 * time.Second
+
+// escalationNotice is the data notifier templates are rendered from.
+type escalationNotice struct {
+	Event       string `json:"event"`
+	ID          string `json:"id"`
+	DedupKey    string `json:"dedup_key"`
+	Title       string `json:"title"`
+	Severity    string `json:"severity"`
+	Reason      string `json:"reason,omitempty"`
+	Source      string `json:"source,omitempty"`
+	EscalatedBy string `json:"escalated_by,omitempty"`
+	RelatedBead string `json:"related_bead,omitempty"`
+	Actor       string `json:"actor,omitempty"`
+	Note        string `json:"note,omitempty"`
+	Time        string `json:"time"`
+}
+
+// escalationDedupKey is the remote incident key for an escalation. It is
+// derived from the escalation ID so ack and close reach the same incident
+// without storing anything remote-specific.
+func escalationDedupKey(escalationID string) string {
+	return "gastown-" + escalationID
+}
+
+// newEscalationNotice builds template data for an escalation event.
+func newEscalationNotice(event, id, title string, fields *beads.EscalationFields) escalationNotice {
+	n := escalationNotice{
+		Event:    event,
+		ID:       id,
+		DedupKey: escalationDedupKey(id),
+		Title:    title,
+		Time:     time.Now().UTC().Format(time.RFC3339),
+	}
+	if fields != nil {
+		n.Severity = fields.Severity
+		n.Reason = fields.Reason
+		n.Source = fields.Source
+		n.EscalatedBy = fields.EscalatedBy
+		n.RelatedBead = fields.RelatedBead
+	}
+	return n
+}
+
+// executeNotifierActions delivers webhook:<name> and incident:<name> route
+// actions. Other actions are ignored. Failed deliveries are also recorded in
+// the activity feed.
+func executeNotifierActions(actions []string, cfg *config.EscalationConfig, notice escalationNotice) []deliveryStatus {
+	statuses := []deliveryStatus{}
+	for _, action := range actions {
+		kind, name, ok := strings.Cut(action, ":")
+		if !ok || (kind != "webhook" && kind != "incident") {
+			continue
+		}
+		status := deliveryStatus{Channel: kind, Target: name, Severity: notice.Severity}
+		n := cfg.Notifiers[name]
+		if n == nil {
+			status.Warning = fmt.Sprintf("notifier %q not defined", name)
+			style.PrintWarning("%s action skipped: notifier '%s' not defined in settings/escalation.json", action, name)
+			recordNotifierFailure(action, notice, status.Warning)
+			statuses = append(statuses, status)
+			continue
+		}
+		if err := sendNotifier(n, notice); err != nil {
+			status.Error = err.Error()
+			style.PrintWarning("%s delivery failed: %v", action, err)
+			recordNotifierFailure(action, notice, status.Error)
+		} else {
+			status.RuntimeNotified = true
+			fmt.Printf("  🔔 Notified %s\n", action)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// notifyEscalationIncidents sends an acknowledge or resolve event to every
+// incident notifier recorded on the escalation. Delivery problems are
+// warnings, recorded in the activity feed: the local ack/close has already
+// succeeded.
+func notifyEscalationIncidents(bd *beads.Beads, townRoot, escalationID, event, actor, note string) {
+	issue, fields, err := bd.GetEscalationBead(escalationID)
+	if err != nil || issue == nil {
+		return
+	}
+	names := fields.IncidentNotifiers()
+	if len(names) == 0 {
+		return
+	}
+	cfg, err := config.LoadOrCreateEscalationConfig(config.EscalationConfigPath(townRoot))
+	if err != nil {
+		style.PrintWarning("could not load escalation config to update incidents: %v", err)
+		return
+	}
+
+	notice := newEscalationNotice(event, issue.ID, issue.Title, fields)
+	notice.Actor = actor
+	notice.Note = note
+	for _, name := range names {
+		action := "incident:" + name
+		n := cfg.Notifiers[name]
+		if n == nil {
+			style.PrintWarning("%s not updated: notifier no longer defined in settings/escalation.json", action)
+			recordNotifierFailure(action, notice, fmt.Sprintf("notifier %q not defined", name))
+			continue
+		}
+		if err := sendNotifier(n, notice); err != nil {
+			style.PrintWarning("%s %s failed: %v", action, event, err)
+			recordNotifierFailure(action, notice, err.Error())
+			continue
+		}
+		fmt.Printf("  🔔 Sent %s to incident:%s\n", event, name)
+	}
+}
+
+// sendNotifier renders and sends one notifier request.
+func sendNotifier(n *config.EscalationNotifier, notice escalationNotice) error {
+	req, err := buildNotifierRequest(n, notice)
+	if err != nil {
+		return err
+	}
+
+	resp, err := (&http.Client{Timeout: notifierTimeout(n)}).Do(req)
+	if err != nil {
+		return fmt.Errorf("sending to notifier: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("notifier returned %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// notifierTimeout returns the notifier's request timeout: the configured one
+// if valid, capped at config.MaxNotifierTimeout, and the default otherwise.
+// A zero timeout would let http.Client wait forever.
+func notifierTimeout(n *config.EscalationNotifier) time.Duration {
+	d, err := time.ParseDuration(n.Timeout)
+	switch {
+	case err != nil || d <= 0:
+		return defaultNotifierTimeout
+	case d > config.MaxNotifierTimeout:
+		return config.MaxNotifierTimeout
+	}
+	return d
+}
+
+// recordNotifierFailure logs a failed notifier delivery to the activity
+// feed, so it outlives the command's output.
+func recordNotifierFailure(action string, notice escalationNotice, errMsg string) {
+	actor := notice.Actor
+	if actor == "" {
+		actor = notice.EscalatedBy
+	}
+	_ = events.LogFeed(events.TypeEscalationNotifyFailed, actor,
+		events.EscalationNotifyFailedPayload(notice.ID, action, notice.Event, errMsg))
+}
+
+// buildNotifierRequest renders the notifier's URL and body for an event.
+func buildNotifierRequest(n *config.EscalationNotifier, notice escalationNotice) (*http.Request, error) {
+	url, err := renderNotifierTemplate("url", n.URL, notice)
+	if err != nil {
+		return nil, err
+	}
+
+	bodyTmpl := n.Body
+	switch notice.Event {
+	case config.NotifierEventAcknowledge:
+		if n.AckBody != "" {
+			bodyTmpl = n.AckBody
+		}
+	case config.NotifierEventResolve:
+		if n.ResolveBody != "" {
+			bodyTmpl = n.ResolveBody
+		}
+	}
+	var body []byte
+	if bodyTmpl == "" {
+		body, err = json.Marshal(notice)
+	} else {
+		var rendered string
+		rendered, err = renderNotifierTemplate("body", bodyTmpl, notice)
+		body = []byte(rendered)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := "application/json"
+	for k, v := range n.Headers {
+		if strings.EqualFold(k, "Content-Type") {
+			contentType = v
+		}
+	}
+	if strings.Contains(contentType, "json") && !json.Valid(body) {
+		return nil, fmt.Errorf("rendered body is not valid JSON: %s", body)
+	}
+
+	method := n.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequest(strings.ToUpper(method), url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("building notifier request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range n.Headers {
+		req.Header.Set(k, v)
+	}
+	return req, nil
+}
+
+func renderNotifierTemplate(name, text string, notice escalationNotice) (string, error) {
+	tmpl, err := template.New(name).Funcs(config.NotifierTemplateFuncs()).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parsing %s template: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, notice); err != nil {
+		return "", fmt.Errorf("rendering %s template: %w", name, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// notifierStandIn records requests like a webhook or incident API would.
+type notifierStandIn struct {
+	mu       sync.Mutex
+	requests []recordedRequest
+	status   int
+}
+
+type recordedRequest struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   string
+}
+
+func newNotifierStandIn(t *testing.T) (*notifierStandIn, *httptest.Server) {
+	t.Helper()
+	s := &notifierStandIn{status: http.StatusAccepted}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.requests = append(s.requests, recordedRequest{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone(), Body: string(body)})
+		status := s.status
+		s.mu.Unlock()
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+	t.Cleanup(srv.Close)
+	return s, srv
+}
+
+func testNotice(event string) escalationNotice {
+	return newEscalationNotice(event, "hq-esc1", `Build "main" failing`, &beads.EscalationFields{
+		Severity:    config.SeverityCritical,
+		Reason:      "CI blocked",
+		EscalatedBy: "gastown/witness",
+	})
+}
+
+func TestExecuteNotifierActions_Webhook(t *testing.T) {
+	standIn, srv := newNotifierStandIn(t)
+	cfg := &config.EscalationConfig{
+		Notifiers: map[string]*config.EscalationNotifier{
+			"oncall": {
+				URL:     srv.URL + "/hooks/{{.ID}}",
+				Headers: map[string]string{"Authorization": "Bearer token"},
+				Body:    `{"content": {{json .Title}}, "severity": {{json .Severity}}}`,
+			},
+		},
+	}
+
+	statuses := executeNotifierActions([]string{"bead", "mail:mayor", "webhook:oncall"}, cfg, testNotice(config.NotifierEventTrigger))
+	if len(statuses) != 1 || !statuses[0].RuntimeNotified || statuses[0].Channel != "webhook" {
+		t.Fatalf("statuses = %+v, want one delivered webhook", statuses)
+	}
+	if len(standIn.requests) != 1 {
+		t.Fatalf("stand-in saw %d requests, want 1", len(standIn.requests))
+	}
+	req := standIn.requests[0]
+	if req.Method != http.MethodPost || req.Path != "/hooks/hq-esc1" {
+		t.Errorf("request = %s %s", req.Method, req.Path)
+	}
+	if req.Header.Get("Authorization") != "Bearer token" || req.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("headers = %v", req.Header)
+	}
+	var body map[string]string
+	if err := json.Unmarshal([]byte(req.Body), &body); err != nil {
+		t.Fatalf("body is not JSON: %v\n%s", err, req.Body)
+	}
+	if body["content"] != `Build "main" failing` || body["severity"] != "critical" {
+		t.Errorf("body = %v", body)
+	}
+}
+
+func TestExecuteNotifierActions_Failures(t *testing.T) {
+	standIn, srv := newNotifierStandIn(t)
+	standIn.status = http.StatusInternalServerError
+	cfg := &config.EscalationConfig{
+		Notifiers: map[string]*config.EscalationNotifier{
+			"down":   {URL: srv.URL},
+			"broken": {URL: srv.URL, Body: `{"text": {{.Title}}}`},
+		},
+	}
+
+	statuses := executeNotifierActions([]string{"webhook:down", "webhook:broken", "incident:missing"}, cfg, testNotice(config.NotifierEventTrigger))
+	if len(statuses) != 3 {
+		t.Fatalf("statuses = %+v", statuses)
+	}
+	if !strings.Contains(statuses[0].Error, "returned 500") {
+		t.Errorf("down error = %q", statuses[0].Error)
+	}
+	if !strings.Contains(statuses[1].Error, "not valid JSON") {
+		t.Errorf("broken error = %q", statuses[1].Error)
+	}
+	if statuses[2].Warning == "" || statuses[2].RuntimeNotified {
+		t.Errorf("missing notifier status = %+v", statuses[2])
+	}
+	// The unquoted template never reaches the network.
+	if len(standIn.requests) != 1 {
+		t.Errorf("stand-in saw %d requests, want 1", len(standIn.requests))
+	}
+}
+
+func TestExecuteNotifierActions_RecordsFailures(t *testing.T) {
+	standIn, srv := newNotifierStandIn(t)
+	standIn.status = http.StatusBadGateway
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(townRoot, "mayor", "town.json"), []byte(`{"name":"test"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(townRoot)
+	cfg := &config.EscalationConfig{
+		Notifiers: map[string]*config.EscalationNotifier{"down": {URL: srv.URL}},
+	}
+
+	executeNotifierActions([]string{"webhook:down", "incident:missing"}, cfg, testNotice(config.NotifierEventTrigger))
+
+	data, err := os.ReadFile(filepath.Join(townRoot, events.EventsFile))
+	if err != nil {
+		t.Fatalf("no failures recorded: %v", err)
+	}
+	var recorded []events.Event
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var ev events.Event
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatalf("bad event %q: %v", line, err)
+		}
+		recorded = append(recorded, ev)
+	}
+	if len(recorded) != 2 {
+		t.Fatalf("recorded %d events, want 2: %s", len(recorded), data)
+	}
+	for i, action := range []string{"webhook:down", "incident:missing"} {
+		ev := recorded[i]
+		if ev.Type != events.TypeEscalationNotifyFailed || ev.Payload["action"] != action || ev.Payload["escalation_id"] != "hq-esc1" {
+			t.Errorf("event %d = %+v, want %s failure for hq-esc1", i, ev, action)
+		}
+	}
+	if errMsg, _ := recorded[0].Payload["error"].(string); !strings.Contains(errMsg, "returned 502") {
+		t.Errorf("recorded error = %q", errMsg)
+	}
+}
+
+func TestNotifierTimeout(t *testing.T) {
+	tests := []struct {
+		timeout string
+		want    time.Duration
+	}{
+		{"", defaultNotifierTimeout},
+		{"3s", 3 * time.Second},
+		{"0s", defaultNotifierTimeout},
+		{"-1s", defaultNotifierTimeout},
+		{"soon", defaultNotifierTimeout},
+		{"1h", config.MaxNotifierTimeout},
+	}
+	for _, tt := range tests {
+		if got := notifierTimeout(&config.EscalationNotifier{Timeout: tt.timeout}); got != tt.want {
+			t.Errorf("notifierTimeout(%q) = %s, want %s", tt.timeout, got, tt.want)
+		}
+	}
+}
+
+func TestIncidentLifecycleUsesEscalationDedupKey(t *testing.T) {
+	standIn, srv := newNotifierStandIn(t)
+	n := &config.EscalationNotifier{
+		URL: srv.URL + "/v2/enqueue",
+		Body: `{"routing_key": "R1", "event_action": {{json .Event}}, "dedup_key": {{json .DedupKey}},
+			"payload": {"summary": {{json .Title}}, "severity": {{json .Severity}}, "source": "gastown"}}`,
+		ResolveBody: `{"routing_key": "R1", "event_action": "resolve", "dedup_key": {{json .DedupKey}}, "note": {{json .Note}}}`,
+	}
+
+	for _, event := range []string{config.NotifierEventTrigger, config.NotifierEventAcknowledge, config.NotifierEventResolve} {
+		notice := testNotice(event)
+		notice.Note = "fixed"
+		if err := sendNotifier(n, notice); err != nil {
+			t.Fatalf("%s: %v", event, err)
+		}
+	}
+
+	if len(standIn.requests) != 3 {
+		t.Fatalf("stand-in saw %d requests, want 3", len(standIn.requests))
+	}
+	wantActions := []string{"trigger", "acknowledge", "resolve"}
+	for i, req := range standIn.requests {
+		var body map[string]interface{}
+		if err := json.Unmarshal([]byte(req.Body), &body); err != nil {
+			t.Fatalf("request %d body is not JSON: %v", i, err)
+		}
+		if body["event_action"] != wantActions[i] {
+			t.Errorf("request %d event_action = %v, want %s", i, body["event_action"], wantActions[i])
+		}
+		if body["dedup_key"] != escalationDedupKey("hq-esc1") {
+			t.Errorf("request %d dedup_key = %v", i, body["dedup_key"])
+		}
+	}
+	if !strings.Contains(standIn.requests[2].Body, `"note": "fixed"`) {
+		t.Errorf("resolve body should use resolve_body: %s", standIn.requests[2].Body)
+	}
+}
+
+func TestBuildNotifierRequest_DefaultBody(t *testing.T) {
+	req, err := buildNotifierRequest(&config.EscalationNotifier{URL: "http://example.invalid/hook", Method: "put"}, testNotice(config.NotifierEventTrigger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != http.MethodPut {
+		t.Errorf("method = %s, want PUT", req.Method)
+	}
+	body, _ := io.ReadAll(req.Body)
+	var notice escalationNotice
+	if err := json.Unmarshal(body, &notice); err != nil {
+		t.Fatalf("default body is not JSON: %v", err)
+	}
+	if notice.ID != "hq-esc1" || notice.Event != "trigger" || notice.DedupKey != "gastown-hq-esc1" || notice.Reason != "CI blocked" {
+		t.Errorf("default body = %+v", notice)
+	}
+}
@@ -62,7 +62,8 @@ Problems View (--problems/-p):
   - Press 'p' to toggle between activity and problems view
 
 The feed combines multiple event sources:
-  - GT events: Agent activity like patrol, sling, handoff (from .events.jsonl)
+  - GT events: Agent activity like patrol, sling, handoff (from the daemon's
+    event bus, or .events.jsonl when the daemon is not running)
   - Beads activity: Issue creates, updates, completions (from bd activity, when available)
   - Convoy status: In-progress and recently-landed convoys (refreshes every 10s)
 
@@ -251,9 +252,12 @@ func runFeedTUI(workDir string, problemsView bool) error {
 		sources = append(sources, mqSource)
 	}
 
-	// Create GT events source (optional - don't fail if not available)
-	gtSource, err := feed.NewGtEventsSource(townRoot)
-	if err == nil {
+	// Create GT events source from the daemon's event bus, or by tailing
+	// .events.jsonl when the daemon is not running (optional - don't fail
+	// if neither is available)
+	if busSource, err := feed.NewBusEventsSource(townRoot); err == nil {
+		sources = append(sources, busSource)
+	} else if gtSource, err := feed.NewGtEventsSource(townRoot); err == nil {
 		sources = append(sources, gtSource)
 	}
 
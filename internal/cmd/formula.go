@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"crypto/rand"
 	"encoding/base32"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -25,14 +26,15 @@ import (
 
 // Formula command flags
 var (
-	formulaListJSON   bool
-	formulaShowJSON   bool
-	formulaRunPR      int
-	formulaRunRig     string
-	formulaRunDryRun  bool
-	formulaRunAgent   string
-	formulaRunFiles   []string
-	formulaCreateType string
+	formulaListJSON     bool
+	formulaShowJSON     bool
+	formulaShowResolved bool
+	formulaRunPR        int
+	formulaRunRig       string
+	formulaRunDryRun    bool
+	formulaRunAgent     string
+	formulaRunFiles     []string
+	formulaCreateType   string
 )
 
 var formulaCmd = &cobra.Command{
@@ -52,6 +54,7 @@ Commands:
   show    Display formula details (steps, variables, composition)
   run     Execute a formula (pour and dispatch)
   create  Create a new formula template
+  test    Run *.formula.test.toml cases against a simulated execution
 
 Search paths (in order):
   1. .beads/formulas/ (project)
@@ -92,9 +95,13 @@ Shows:
   - Steps with dependencies
   - Composition rules (extends, aspects)
 
+Use --resolved to apply extends, compose.expand and compose.aspects locally
+and show the final step graph, including steps woven in by aspect advice.
+
 Examples:
   gt formula show shiny
-  gt formula show rule-of-five --json`,
+  gt formula show rule-of-five --json
+  gt formula show shiny-secure --resolved`,
 	Args: cobra.ExactArgs(1),
 	RunE: runFormulaShow,
 }
@@ -164,6 +171,7 @@ func init() {
 
 	// Show flags
 	formulaShowCmd.Flags().BoolVar(&formulaShowJSON, "json", false, "Output as JSON")
+	formulaShowCmd.Flags().BoolVar(&formulaShowResolved, "resolved", false, "Resolve extends/compose rules and show the final steps")
 
 	// Run flags
 	formulaRunCmd.Flags().IntVar(&formulaRunPR, "pr", 0, "GitHub PR number to run formula on")
@@ -200,6 +208,9 @@ func runFormulaList(cmd *cobra.Command, args []string) error {
 // runFormulaShow delegates to bd formula show
 func runFormulaShow(cmd *cobra.Command, args []string) error {
 	formulaName := args[0]
+	if formulaShowResolved {
+		return showResolvedFormula(formulaName)
+	}
 	bdArgs := []string{"formula", "show", formulaName}
 	if formulaShowJSON {
 		bdArgs = append(bdArgs, "--json")
@@ -691,9 +702,74 @@ func executeConvoyFormula(f *formula.Formula, formulaName, targetRig string) err
 	return nil
 }
 
-// findFormulaFile searches for a formula file by name
-func findFormulaFile(name string) (string, error) {
-	// Search paths in order
+// showResolvedFormula resolves a formula's composition rules and prints the
+// resulting steps in dependency order.
+func showResolvedFormula(name string) error {
+	searchPaths := formulaSearchPaths()
+
+	var f *formula.Formula
+	if path, err := findFormulaFile(name); err == nil {
+		f, err = parseFormulaFile(path)
+		if err != nil {
+			return fmt.Errorf("parsing formula: %w", err)
+		}
+	} else {
+		data, embedErr := formula.GetEmbeddedFormulaContent(name)
+		if embedErr != nil {
+			return err
+		}
+		f, err = formula.Parse(data)
+		if err != nil {
+			return fmt.Errorf("parsing formula: %w", err)
+		}
+	}
+
+	resolved, err := formula.Resolve(f, searchPaths)
+	if err != nil {
+		return fmt.Errorf("resolving formula: %w", err)
+	}
+
+	if formulaShowJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(resolved)
+	}
+
+	fmt.Printf("%s %s (%s)\n", style.Bold.Render("Formula:"), resolved.Name, resolved.Type)
+	if resolved.Description != "" {
+		fmt.Printf("  %s\n", resolved.Description)
+	}
+	if len(f.Extends) > 0 {
+		fmt.Printf("%s %s\n", style.Dim.Render("Extends:"), strings.Join(f.Extends, ", "))
+	}
+	if f.Compose != nil && len(f.Compose.Aspects) > 0 {
+		fmt.Printf("%s %s\n", style.Dim.Render("Aspects:"), strings.Join(f.Compose.Aspects, ", "))
+	}
+	if resolved.Type != formula.TypeWorkflow {
+		return nil
+	}
+
+	order, err := resolved.TopologicalSort()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\n%s\n", style.Bold.Render("Steps:"))
+	for i, id := range order {
+		step := resolved.GetStep(id)
+		line := fmt.Sprintf("  %2d. %s", i+1, id)
+		if step.Title != "" {
+			line += " " + style.Dim.Render("- "+step.Title)
+		}
+		if len(step.Needs) > 0 {
+			line += " " + style.Dim.Render("(needs: "+strings.Join(step.Needs, ", ")+")")
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// formulaSearchPaths returns the on-disk formula directories in lookup order.
+func formulaSearchPaths() []string {
 	searchPaths := []string{}
 
 	// 1. Project .beads/formulas/
@@ -711,6 +787,13 @@ func findFormulaFile(name string) (string, error) {
 		searchPaths = append(searchPaths, filepath.Join(home, ".beads", "formulas"))
 	}
 
+	return searchPaths
+}
+
+// findFormulaFile searches for a formula file by name
+func findFormulaFile(name string) (string, error) {
+	searchPaths := formulaSearchPaths()
+
 	// Try each path with common extensions
 	extensions := []string{".formula.toml", ".formula.json"}
 	for _, basePath := range searchPaths {
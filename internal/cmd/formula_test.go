@@ -1,6 +1,10 @@
 package cmd
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
 
 func TestResolveFormulaLegAgent_Precedence(t *testing.T) {
 	t.Parallel()
@@ -32,3 +36,45 @@ func TestResolveFormulaLegAgent_Precedence(t *testing.T) {
 		})
 	}
 }
+
+func TestRunFormulaTestFile_LocalFormulaExtendsEmbedded(t *testing.T) {
+	dir := t.TempDir()
+	local := `formula = "shiny-docs"
+extends = ["shiny"]
+type = "workflow"
+
+[[steps]]
+id = "docs"
+title = "Document {{feature}}"
+`
+	test := `formula = "shiny-docs"
+
+[vars]
+feature = "login"
+
+[[cases]]
+name = "docs come last"
+trace = ["design", "implement", "review", "test", "submit", "docs"]
+contains = { docs = "Document login" }
+`
+	if err := os.WriteFile(filepath.Join(dir, "shiny-docs.formula.toml"), []byte(local), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "shiny-docs.formula.test.toml")
+	if err := os.WriteFile(path, []byte(test), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	res := runFormulaTestFile(path)
+	if res.Error != "" {
+		t.Fatalf("Error = %s", res.Error)
+	}
+	if len(res.Cases) != 1 || !res.Cases[0].Passed() {
+		t.Fatalf("Cases = %+v", res.Cases)
+	}
+
+	files, err := formulaTestFiles([]string{path})
+	if err != nil || len(files) != 1 || files[0] != path {
+		t.Errorf("formulaTestFiles = %v, %v", files, err)
+	}
+}
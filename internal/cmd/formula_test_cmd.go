@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/formula"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var (
+	formulaTestJSON    bool
+	formulaTestVerbose bool
+)
+
+var formulaTestCmd = &cobra.Command{
+	Use:   "test [name | file.formula.test.toml]...",
+	Short: "Run formula tests against a simulated execution",
+	Long: `Run formula tests: resolve a formula, simulate agents working through it,
+and check the execution against expectations in a *.formula.test.toml file.
+
+Each test file names a formula and holds one or more cases. The formula is
+resolved (extends, compose.expand, compose.aspects), the test's overlay and
+vars are applied, and the steps are driven through the same ready-step
+scheduling polecats use. Each case scripts step outcomes (ok, fail, timeout)
+per attempt and asserts the resulting trace, rounds, skipped steps, result,
+or substituted step text.
+
+Arguments are test file paths or formula names. A name runs
+<name>.formula.test.toml from the formula search paths. With no arguments,
+every test file in the search paths is run. The formula itself is looked up
+next to the test file first, then in the search paths, then in the embedded
+formulas.
+
+Exits non-zero if any case fails, so it can run in CI.
+
+Test file format:
+  formula = "shiny"
+
+  [vars]
+  feature = "login"
+
+  [[step-overrides]]            # optional overlay, as in formula-overlays/
+  step_id = "review"
+  mode = "skip"
+
+  [[cases]]
+  name = "failing tests stop the run"
+  script = { test = ["fail"] }  # outcomes per attempt; later attempts succeed
+  trace = ["design", "implement", "test:fail"]
+  result = "failed"             # complete, failed, or stuck
+  skipped = []                  # steps excluded by when/unless
+  contains = { design = "Design login" }
+
+Examples:
+  gt formula test shiny
+  gt formula test formulas/release.formula.test.toml
+  gt formula test --json`,
+	RunE:         runFormulaTest,
+	SilenceUsage: true,
+}
+
+func init() {
+	formulaTestCmd.Flags().BoolVar(&formulaTestJSON, "json", false, "Output as JSON")
+	formulaTestCmd.Flags().BoolVarP(&formulaTestVerbose, "verbose", "v", false, "Show the trace of passing cases too")
+
+	formulaCmd.AddCommand(formulaTestCmd)
+}
+
+// FormulaTestJSON is the result of one test file in gt formula test --json.
+type FormulaTestJSON struct {
+	File    string               `json:"file"`
+	Formula string               `json:"formula"`
+	Error   string               `json:"error,omitempty"` // the file or formula could not be loaded
+	Cases   []formula.CaseResult `json:"cases,omitempty"`
+}
+
+func runFormulaTest(cmd *cobra.Command, args []string) error {
+	files, err := formulaTestFiles(args)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no %s files found in formula search paths", formula.TestFileSuffix)
+	}
+
+	var results []FormulaTestJSON
+	failed := 0
+	for _, path := range files {
+		res := runFormulaTestFile(path)
+		if res.Error != "" {
+			failed++
+		}
+		for _, c := range res.Cases {
+			if !c.Passed() {
+				failed++
+			}
+		}
+		results = append(results, res)
+	}
+
+	if formulaTestJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		printFormulaTestResults(results)
+	}
+	if failed > 0 {
+		cmd.SilenceErrors = true // the failures were reported above
+		return NewSilentExit(1)
+	}
+	return nil
+}
+
+// formulaTestFiles maps arguments to test file paths. With no arguments it
+// returns every test file in the formula search paths.
+func formulaTestFiles(args []string) ([]string, error) {
+	searchPaths := formulaSearchPaths()
+	if len(args) == 0 {
+		seen := make(map[string]bool)
+		var files []string
+		for _, dir := range searchPaths {
+			matches, _ := filepath.Glob(filepath.Join(dir, "*"+formula.TestFileSuffix))
+			sort.Strings(matches)
+			for _, m := range matches {
+				if name := filepath.Base(m); !seen[name] {
+					seen[name] = true
+					files = append(files, m)
+				}
+			}
+		}
+		return files, nil
+	}
+
+	var files []string
+	for _, arg := range args {
+		if strings.HasSuffix(arg, formula.TestFileSuffix) {
+			files = append(files, arg)
+			continue
+		}
+		found := ""
+		for _, dir := range searchPaths {
+			path := filepath.Join(dir, arg+formula.TestFileSuffix)
+			if _, err := os.Stat(path); err == nil {
+				found = path
+				break
+			}
+		}
+		if found == "" {
+			return nil, fmt.Errorf("no %s%s in formula search paths", arg, formula.TestFileSuffix)
+		}
+		files = append(files, found)
+	}
+	return files, nil
+}
+
+// runFormulaTestFile loads and resolves the formula a test file names and
+// runs its cases.
+func runFormulaTestFile(path string) FormulaTestJSON {
+	res := FormulaTestJSON{File: path}
+	tf, err := formula.ParseTestFile(path)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Formula = tf.Formula
+
+	dir := filepath.Dir(path)
+	searchPaths := append([]string{dir}, formulaSearchPaths()...)
+	var f *formula.Formula
+	if local := filepath.Join(dir, tf.Formula+".formula.toml"); pathExists(local) {
+		f, err = parseFormulaFile(local)
+	} else if found, findErr := findFormulaFile(tf.Formula); findErr == nil {
+		f, err = parseFormulaFile(found)
+	} else {
+		data, embedErr := formula.GetEmbeddedFormulaContent(tf.Formula)
+		if embedErr != nil {
+			res.Error = findErr.Error()
+			return res
+		}
+		f, err = formula.Parse(data)
+	}
+	if err != nil {
+		res.Error = fmt.Sprintf("parsing formula: %v", err)
+		return res
+	}
+	resolved, err := formula.Resolve(f, searchPaths)
+	if err != nil {
+		res.Error = fmt.Sprintf("resolving formula: %v", err)
+		return res
+	}
+
+	res.Cases = tf.Run(resolved)
+	return res
+}
+
+func printFormulaTestResults(results []FormulaTestJSON) {
+	passed, failed := 0, 0
+	for _, r := range results {
+		name := r.Formula
+		if name == "" {
+			name = filepath.Base(r.File)
+		}
+		fmt.Printf("%s %s\n", style.Bold.Render("Formula:"), name)
+		if r.Error != "" {
+			fmt.Printf("  %s %s\n", style.Error.Render("✗"), r.Error)
+			failed++
+			continue
+		}
+		for _, c := range r.Cases {
+			if c.Passed() {
+				passed++
+				fmt.Printf("  %s %s\n", style.Success.Render("✓"), c.Name)
+				if formulaTestVerbose && c.Simulation != nil {
+					fmt.Printf("    %s\n", style.Dim.Render(strings.Join(c.Simulation.TraceStrings(), " → ")))
+				}
+				continue
+			}
+			failed++
+			fmt.Printf("  %s %s\n", style.Error.Render("✗"), c.Name)
+			for _, msg := range c.Failures {
+				fmt.Printf("    %s\n", msg)
+			}
+		}
+	}
+	fmt.Println()
+	if failed > 0 {
+		fmt.Printf("%s %d passed, %d failed\n", style.Error.Render("FAIL"), passed, failed)
+	} else {
+		fmt.Printf("%s %d passed\n", style.Success.Render("ok"), passed)
+	}
+}
@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/connection"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// reservedMachineNames cannot be machines: they are address prefixes that
+// already mean something to gt mail and gt nudge.
+var reservedMachineNames = map[string]bool{
+	"local": true, "list": true, "queue": true, "announce": true,
+	"channel": true, "group": true, "rig": true, "hq": true,
+}
+
+var (
+	machineKeyPath  string
+	machineTownPath string
+	machineHostKey  string
+	machineListJSON bool
+)
+
+var machineCmd = &cobra.Command{
+	Use:     "machine",
+	GroupID: GroupWorkspace,
+	Short:   "Manage remote machines for machine:rig/agent addresses",
+	Long: `Manage the machines this town can reach over SSH.
+
+A registered machine can be used as an address prefix: "vm:gastown/Toast"
+is the Toast polecat in the gastown rig of the town on machine "vm".
+gt peek, gt nudge, gt mail send and gt session list run the command on the
+remote town over SSH when the address names a machine.
+
+Machines are stored in mayor/machines.json. Host keys are checked against
+the pinned --host-key, or ~/.ssh/known_hosts when none is pinned.
+
+Examples:
+  gt machine add vm deploy@10.0.0.5 --key ~/.ssh/id_ed25519 --town-path /home/deploy/gt
+  gt machine test vm
+  gt peek vm:gastown/Toast`,
+	RunE: requireSubcommand,
+}
+
+var machineAddCmd = &cobra.Command{
+	Use:   "add <name> <[user@]host[:port]>",
+	Short: "Register or update an SSH machine",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runMachineAdd,
+}
+
+var machineListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List registered machines",
+	Args:  cobra.NoArgs,
+	RunE:  runMachineList,
+}
+
+var machineTestCmd = &cobra.Command{
+	Use:   "test <name>",
+	Short: "Check that a machine is reachable and has a usable town",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runMachineTest,
+}
+
+var machineRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a machine from the registry",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runMachineRemove,
+}
+
+func init() {
+	machineAddCmd.Flags().StringVar(&machineKeyPath, "key", "", "SSH private key (default: use the SSH agent)")
+	machineAddCmd.Flags().StringVar(&machineTownPath, "town-path", "", "Town root on the remote machine")
+	machineAddCmd.Flags().StringVar(&machineHostKey, "host-key", "", "Pin the host key (authorized_keys format, e.g. from ssh-keyscan)")
+	machineListCmd.Flags().BoolVar(&machineListJSON, "json", false, "Output as JSON")
+
+	machineCmd.AddCommand(machineAddCmd)
+	machineCmd.AddCommand(machineListCmd)
+	machineCmd.AddCommand(machineTestCmd)
+	machineCmd.AddCommand(machineRemoveCmd)
+	rootCmd.AddCommand(machineCmd)
+}
+
+func loadMachineRegistry() (*connection.MachineRegistry, error) {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return nil, fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	return connection.NewMachineRegistry(connection.RegistryPath(townRoot))
+}
+
+func runMachineAdd(cmd *cobra.Command, args []string) error {
+	name, host := args[0], args[1]
+	if reservedMachineNames[name] {
+		return fmt.Errorf("%q is reserved and cannot be used as a machine name", name)
+	}
+	reg, err := loadMachineRegistry()
+	if err != nil {
+		return err
+	}
+	m := &connection.Machine{
+		Name:     name,
+		Type:     "ssh",
+		Host:     host,
+		KeyPath:  machineKeyPath,
+		TownPath: machineTownPath,
+		HostKey:  strings.TrimSpace(machineHostKey),
+	}
+	// Validate host, key and host key before saving.
+	if _, err := connection.NewSSHConnection(m); err != nil {
+		return err
+	}
+	if err := reg.Add(m); err != nil {
+		return err
+	}
+	fmt.Printf("%s Machine %s → %s\n", style.Success.Render("✓"), style.Bold.Render(name), host)
+	fmt.Printf("  Check it with: %s\n", style.Dim.Render("gt machine test "+name))
+	return nil
+}
+
+func runMachineList(cmd *cobra.Command, args []string) error {
+	reg, err := loadMachineRegistry()
+	if err != nil {
+		return err
+	}
+	machines := reg.List()
+	sort.Slice(machines, func(i, j int) bool { return machines[i].Name < machines[j].Name })
+
+	if machineListJSON {
+		return outputJSON(machines)
+	}
+	for _, m := range machines {
+		if m.Type == "local" {
+			fmt.Printf("  %s  %s\n", style.Bold.Render(m.Name), style.Dim.Render("(this machine)"))
+			continue
+		}
+		fmt.Printf("  %s  %s %s", style.Bold.Render(m.Name), m.Type, m.Host)
+		if m.TownPath != "" {
+			fmt.Printf("  town %s", m.TownPath)
+		}
+		if m.HostKey != "" {
+			fmt.Printf("  %s", style.Dim.Render("(host key pinned)"))
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+func runMachineTest(cmd *cobra.Command, args []string) error {
+	reg, err := loadMachineRegistry()
+	if err != nil {
+		return err
+	}
+	m, err := reg.Get(args[0])
+	if err != nil {
+		return err
+	}
+	conn, err := reg.Connection(m.Name)
+	if err != nil {
+		return err
+	}
+	defer closeConnection(conn)
+
+	failed := false
+	check := func(label string, fn func() (string, error)) {
+		detail, err := fn()
+		if err != nil {
+			failed = true
+			fmt.Printf("  %s %s: %v\n", style.Error.Render("✗"), label, err)
+			return
+		}
+		fmt.Printf("  %s %s  %s\n", style.Success.Render("✓"), label, style.Dim.Render(detail))
+	}
+
+	fmt.Printf("Testing %s (%s)\n", style.Bold.Render(m.Name), m.Host)
+	check("connect", func() (string, error) {
+		out, err := conn.Exec("uname", "-sn")
+		return strings.TrimSpace(string(out)), err
+	})
+	if failed {
+		return fmt.Errorf("machine %s is not reachable", m.Name)
+	}
+	check("tmux", func() (string, error) {
+		out, err := conn.Exec("tmux", "-V")
+		return strings.TrimSpace(string(out)), err
+	})
+	check("gt", func() (string, error) {
+		out, err := conn.Exec("gt", "version")
+		return firstLine(string(out)), err
+	})
+	if m.TownPath != "" {
+		check("town", func() (string, error) {
+			ok, err := conn.Exists(m.TownPath + "/mayor")
+			if err == nil && !ok {
+				err = fmt.Errorf("%s is not a town root (no mayor/)", m.TownPath)
+			}
+			return m.TownPath, err
+		})
+	}
+	if failed {
+		return fmt.Errorf("machine %s has problems", m.Name)
+	}
+	return nil
+}
+
+func runMachineRemove(cmd *cobra.Command, args []string) error {
+	reg, err := loadMachineRegistry()
+	if err != nil {
+		return err
+	}
+	if err := reg.Remove(args[0]); err != nil {
+		return err
+	}
+	fmt.Printf("%s Removed machine %s\n", style.Success.Render("✓"), args[0])
+	return nil
+}
+
+// remoteMachine is a registered machine reached over its Connection.
+type remoteMachine struct {
+	machine *connection.Machine
+	conn    connection.Connection
+}
+
+// resolveMachineAddress splits a "machine:rest" address when machine is a
+// registered remote machine. For any other address (including mail prefixes
+// like "list:" and "local:") it returns nil and the address to use locally.
+func resolveMachineAddress(addr string) (*remoteMachine, string, error) {
+	name, rest, ok := strings.Cut(addr, ":")
+	if !ok || name == "" || strings.Contains(name, "/") {
+		return nil, addr, nil
+	}
+	if name == "local" {
+		return nil, rest, nil
+	}
+	if reservedMachineNames[name] {
+		return nil, addr, nil
+	}
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		return nil, addr, nil
+	}
+	path := connection.RegistryPath(townRoot)
+	if _, err := os.Stat(path); err != nil {
+		return nil, addr, nil
+	}
+	reg, err := connection.NewMachineRegistry(path)
+	if err != nil {
+		return nil, "", err
+	}
+	m, err := reg.Get(name)
+	if err != nil {
+		return nil, addr, nil // Not a machine
+	}
+	conn, err := reg.Connection(name)
+	if err != nil {
+		return nil, "", err
+	}
+	return &remoteMachine{machine: m, conn: conn}, rest, nil
+}
+
+// gt runs a gt command in the remote machine's town and returns its output.
+func (r *remoteMachine) gt(args ...string) ([]byte, error) {
+	var out []byte
+	var err error
+	if r.machine.TownPath != "" {
+		out, err = r.conn.ExecDir(r.machine.TownPath, "gt", args...)
+	} else {
+		out, err = r.conn.Exec("gt", args...)
+	}
+	if err != nil {
+		var connErr *connection.ConnectionError
+		if errors.As(err, &connErr) {
+			return nil, err
+		}
+		return out, fmt.Errorf("gt %s on %s failed: %s", args[0], r.machine.Name, strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
+
+// run runs a gt command remotely and copies its output to w.
+func (r *remoteMachine) run(w io.Writer, args ...string) error {
+	out, err := r.gt(args...)
+	if err != nil {
+		return err
+	}
+	_, _ = w.Write(out)
+	return nil
+}
+
+func (r *remoteMachine) Close() {
+	closeConnection(r.conn)
+}
+
+func closeConnection(conn connection.Connection) {
+	if c, ok := conn.(io.Closer); ok {
+		_ = c.Close()
+	}
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
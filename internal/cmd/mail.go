@@ -20,6 +20,8 @@ var (
 	mailTo            string   // --to flag (alternative to positional arg)
 	mailSendSelf      bool
 	mailCC            []string // CC recipients
+	mailSendAt        string   // --at: deliver at a clock time
+	mailSendIn        string   // --in: deliver after a delay
 	mailInboxJSON     bool
 	mailReadJSON      bool
 	mailInboxUnread   bool
@@ -105,6 +107,8 @@ Addresses:
   <rig>/<polecat>  - Send to a specific polecat
   <rig>/           - Broadcast to a rig
   list:<name>      - Send to a mailing list (fans out to all members)
+  <machine>:<addr> - Send to an address in the town on a registered machine
+                     (see gt machine); the message is created there
 
 Mailing lists are defined in ~/gt/config/messaging.json and allow
 sending to multiple recipients at once. Each recipient gets their
@@ -125,6 +129,10 @@ Priority levels:
 
 Use --urgent as shortcut for --priority 0.
 
+Scheduled delivery (--at/--in) holds the message back: it stays out of the
+recipient's inbox until due, then the daemon delivers it and wakes the
+recipient. Scheduled mail is always permanent (never a wisp).
+
 Examples:
   gt mail send greenplace/Toast -s "Status check" -m "How's that bug fix going?"
   gt mail send mayor/ -s "Work complete" -m "Finished gt-abc"
@@ -135,6 +143,8 @@ Examples:
   gt mail send --self -s "Handoff" -m "Context for next session"
   gt mail send greenplace/Toast -s "Update" -m "Progress report" --cc overseer
   gt mail send list:oncall -s "Alert" -m "System down"
+  gt mail send gastown/witness -s "Reminder" -m "Check the queue" --in 2h
+  gt mail send mayor/ -s "Standup" -m "Post status" --at 09:00
 
   # Read body from stdin (avoids shell quoting issues):
   gt mail send mayor/ -s "Update" --stdin <<'BODY'
@@ -472,6 +482,9 @@ func init() {
 	mailSendCmd.Flags().StringVar(&mailTo, "to", "", "Recipient address (alternative to positional argument)")
 	mailSendCmd.Flags().BoolVar(&mailSendSelf, "self", false, "Send to self (auto-detect from cwd)")
 	mailSendCmd.Flags().StringArrayVar(&mailCC, "cc", nil, "CC recipients (can be used multiple times)")
+	mailSendCmd.Flags().StringVar(&mailSendAt, "at", "", "Deliver at a time (15:04, \"2006-01-02 15:04\" or RFC3339)")
+	mailSendCmd.Flags().StringVar(&mailSendIn, "in", "", "Deliver after a delay (e.g. 30m, 2h, 1d)")
+	mailSendCmd.MarkFlagsMutuallyExclusive("at", "in")
 	_ = mailSendCmd.MarkFlagRequired("subject") // cobra flags: error only at runtime if missing
 
 	// Inbox flags
@@ -533,6 +546,8 @@ func init() {
 	mailCmd.AddCommand(mailSearchCmd)
 	mailCmd.AddCommand(mailAnnouncesCmd)
 	mailCmd.AddCommand(mailDrainCmd)
+	mailCmd.AddCommand(mailSnoozeCmd)
+	mailCmd.AddCommand(mailScheduledCmd)
 
 	rootCmd.AddCommand(mailCmd)
 }
@@ -132,6 +132,23 @@ func runMailInbox(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// resolveMailRef resolves a message ID or 1-based inbox index to a message ID.
+func resolveMailRef(mailbox *mail.Mailbox, msgRef string) (string, error) {
+	idx, err := strconv.Atoi(msgRef)
+	if err != nil || idx <= 0 {
+		return msgRef, nil
+	}
+	// Numeric index: resolve to message ID by listing inbox
+	messages, err := mailbox.List()
+	if err != nil {
+		return "", fmt.Errorf("listing messages: %w", err)
+	}
+	if idx > len(messages) {
+		return "", fmt.Errorf("index %d out of range (inbox has %d messages)", idx, len(messages))
+	}
+	return messages[idx-1].ID, nil
+}
+
 func runMailRead(cmd *cobra.Command, args []string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("message ID or index required\n\nRun 'gt mail inbox' to list messages and their IDs")
@@ -146,20 +163,9 @@ func runMailRead(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Check if the argument is a numeric index (1-based)
-	var msgID string
-	if idx, err := strconv.Atoi(msgRef); err == nil && idx > 0 {
-		// Numeric index: resolve to message ID by listing inbox
-		messages, err := mailbox.List()
-		if err != nil {
-			return fmt.Errorf("listing messages: %w", err)
-		}
-		if idx > len(messages) {
-			return fmt.Errorf("index %d out of range (inbox has %d messages)", idx, len(messages))
-		}
-		msgID = messages[idx-1].ID
-	} else {
-		msgID = msgRef
+	msgID, err := resolveMailRef(mailbox, msgRef)
+	if err != nil {
+		return err
 	}
 
 	msg, err := mailbox.Get(msgID)
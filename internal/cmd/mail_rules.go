@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var (
+	mailRulesJSON     bool
+	mailRulesTestJSON bool
+)
+
+var mailRulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Inspect server-side mail rules",
+	Long: `Inspect the mail rules configured in config/messaging.json.
+
+Rules are applied by the router when a message is delivered, before it
+reaches the recipient's inbox. Each rule selects messages for a recipient
+(identity, '*' matches one path segment) by sender, subject (regexp),
+type, priority and labels, and applies one action:
+
+  archive    deliver straight to the archive, without notifying
+  forward    also deliver a copy to target
+  priority   re-prioritize the message
+  nudge      deliver as a nudge instead of mail
+  hook       attach the message to the recipient's hook if it is empty
+
+Rules run in order; a matching rule with "stop": true ends evaluation.
+
+Example config/messaging.json:
+  "rules": [
+    {"name": "patrol-noise", "identity": "mayor/",
+     "match": {"from": "*/witness", "subject": "^patrol"}, "action": "archive"},
+    {"name": "page-oncall", "match": {"labels": ["gt:escalation"]},
+     "action": "forward", "target": "list:oncall"}
+  ]
+
+Examples:
+  gt mail rules list
+  gt mail rules test hq-abc123`,
+	RunE: requireSubcommand,
+}
+
+var mailRulesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configured mail rules",
+	Args:  cobra.NoArgs,
+	RunE:  runMailRulesList,
+}
+
+var mailRulesTestCmd = &cobra.Command{
+	Use:   "test <msg-id>",
+	Short: "Show which rules would apply to a message",
+	Long: `Evaluate the mail rules against a message in your inbox without changing it.
+
+Shows the rules that match and what they would do if the message were
+delivered to you now. Accepts a message ID or an inbox index.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMailRulesTest,
+}
+
+func init() {
+	mailRulesListCmd.Flags().BoolVar(&mailRulesJSON, "json", false, "Output as JSON")
+	mailRulesTestCmd.Flags().BoolVar(&mailRulesTestJSON, "json", false, "Output as JSON")
+	mailRulesCmd.AddCommand(mailRulesListCmd)
+	mailRulesCmd.AddCommand(mailRulesTestCmd)
+	mailCmd.AddCommand(mailRulesCmd)
+}
+
+func runMailRulesList(cmd *cobra.Command, args []string) error {
+	townRoot, err := findMailWorkDir()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	cfg, err := config.LoadOrCreateMessagingConfig(config.MessagingConfigPath(townRoot))
+	if err != nil {
+		return fmt.Errorf("loading messaging config: %w", err)
+	}
+
+	if mailRulesJSON {
+		rules := cfg.Rules
+		if rules == nil {
+			rules = []config.MailRule{}
+		}
+		return outputJSON(rules)
+	}
+
+	if len(cfg.Rules) == 0 {
+		fmt.Printf("%s\n", style.Dim.Render("(no mail rules configured)"))
+		return nil
+	}
+	for i, rule := range cfg.Rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		identity := rule.Identity
+		if identity == "" {
+			identity = "(everyone)"
+		}
+		fmt.Printf("%s %s  %s\n", style.Bold.Render(name), style.Dim.Render("for"), identity)
+		fmt.Printf("  match:  %s\n", describeMailRuleMatch(rule.Match))
+		fmt.Printf("  action: %s\n", describeMailRuleAction(rule))
+	}
+	return nil
+}
+
+func describeMailRuleMatch(m config.MailRuleMatch) string {
+	var parts []string
+	if m.From != "" {
+		parts = append(parts, "from="+m.From)
+	}
+	if m.Subject != "" {
+		parts = append(parts, fmt.Sprintf("subject=/%s/", m.Subject))
+	}
+	if m.Type != "" {
+		parts = append(parts, "type="+m.Type)
+	}
+	if m.Priority != "" {
+		parts = append(parts, "priority="+m.Priority)
+	}
+	if len(m.Labels) > 0 {
+		parts = append(parts, "labels="+strings.Join(m.Labels, ","))
+	}
+	if len(parts) == 0 {
+		return "(all messages)"
+	}
+	return strings.Join(parts, " ")
+}
+
+func describeMailRuleAction(rule config.MailRule) string {
+	action := rule.Action
+	switch rule.Action {
+	case config.MailRuleForward:
+		action += " → " + rule.Target
+	case config.MailRulePriority:
+		action += " → " + rule.Priority
+	}
+	if rule.Stop {
+		action += " (stop)"
+	}
+	return action
+}
+
+func runMailRulesTest(cmd *cobra.Command, args []string) error {
+	address := detectSender()
+	mailbox, err := getMailbox(address)
+	if err != nil {
+		return err
+	}
+	msgID, err := resolveMailRef(mailbox, args[0])
+	if err != nil {
+		return err
+	}
+	msg, err := mailbox.Get(msgID)
+	if err != nil {
+		return fmt.Errorf("getting message: %w", err)
+	}
+	if msg.To == "" {
+		msg.To = address
+	}
+
+	workDir, err := findMailWorkDir()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	outcome, err := mail.NewRouter(workDir).EvaluateRules(msg)
+	if err != nil {
+		return err
+	}
+
+	if mailRulesTestJSON {
+		return outputJSON(outcome)
+	}
+
+	fmt.Printf("%s %s\n", style.Bold.Render("Message:"), msg.Subject)
+	fmt.Printf("  from %s to %s, %s priority, type %s\n", msg.From, msg.To, msg.Priority, msg.Type)
+	if len(outcome.Matched) == 0 {
+		fmt.Printf("\n%s\n", style.Dim.Render("No rules match: the message would be delivered normally."))
+		return nil
+	}
+	fmt.Printf("\n%s %s\n", style.Bold.Render("Matching rules:"), strings.Join(outcome.Matched, ", "))
+	fmt.Printf("%s\n", style.Bold.Render("Would:"))
+	if outcome.Priority != "" && outcome.Priority != msg.Priority {
+		fmt.Printf("  • re-prioritize to %s\n", outcome.Priority)
+	}
+	if outcome.Nudge {
+		fmt.Printf("  • deliver as a nudge instead of mail\n")
+	} else {
+		if outcome.Hook {
+			fmt.Printf("  • attach to the hook if it is empty\n")
+		}
+		if outcome.Archive {
+			fmt.Printf("  • archive on arrival, without notifying\n")
+		}
+	}
+	for _, target := range outcome.Forward {
+		fmt.Printf("  • forward a copy to %s\n", target)
+	}
+	return nil
+}
@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
@@ -65,6 +67,17 @@ func runMailSend(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("address required (use positional arg, --to, or --self)")
 	}
 
+	// machine:address sends through the remote town's own gt mail.
+	remote, rest, err := resolveMachineAddress(to)
+	if err != nil {
+		return err
+	}
+	if remote != nil {
+		defer remote.Close()
+		return sendRemoteMail(remote, rest)
+	}
+	to = rest
+
 	// All mail uses town beads (two-level architecture)
 	workDir, err := findMailWorkDir()
 	if err != nil {
@@ -99,6 +112,15 @@ func runMailSend(cmd *cobra.Command, args []string) error {
 	// Set CC recipients
 	msg.CC = mailCC
 
+	// Scheduled delivery: the router holds the message until it is due.
+	if mailSendAt != "" || mailSendIn != "" {
+		deliverAt, err := parseDeliverAt(mailSendAt, mailSendIn, time.Now())
+		if err != nil {
+			return err
+		}
+		msg.DeliverAt = &deliverAt
+	}
+
 	// Suppress router-side notification when --no-notify is passed.
 	// Otherwise the router handles idle-aware notification per-recipient,
 	// which also works correctly for fan-out (groups, lists, channels).
@@ -223,6 +245,9 @@ func runMailSend(cmd *cobra.Command, args []string) error {
 	if msg.Type != mail.TypeNotification {
 		fmt.Printf("  Type: %s\n", msg.Type)
 	}
+	if msg.DeliverAt != nil {
+		fmt.Printf("  Deliver at: %s\n", msg.DeliverAt.Local().Format("2006-01-02 15:04"))
+	}
 
 	return nil
 }
@@ -233,3 +258,64 @@ func generateThreadID() string {
 	_, _ = rand.Read(b) // crypto/rand.Read only fails on broken system
 	return "thread-" + hex.EncodeToString(b)
 }
+
+// sendRemoteMail forwards a message to an address in a remote town. The
+// remote gt sees its own identity as the sender, so the real sender is
+// noted at the end of the body.
+func sendRemoteMail(remote *remoteMachine, to string) error {
+	remoteArgs, err := remoteMailArgs(remote.machine.Name, to, detectSender(), time.Now())
+	if err != nil {
+		return err
+	}
+	return remote.run(os.Stdout, remoteArgs...)
+}
+
+// remoteMailArgs builds the remote "gt mail send" arguments from the send
+// flags. CC addresses are passed through for the remote town to resolve.
+// --reply-to names a message in this town, which the remote town cannot
+// thread against, so it is rejected.
+func remoteMailArgs(machine, to, from string, now time.Time) ([]string, error) {
+	if mailReplyTo != "" {
+		return nil, fmt.Errorf("--reply-to is not supported for remote address %s:%s (message IDs are local to a town)", machine, to)
+	}
+
+	priority := mailPriority
+	if mailUrgent {
+		priority = 0
+	} else if mailNotify && priority == 2 {
+		priority = 1
+	}
+	body := mailBody
+	if body != "" {
+		body += "\n\n"
+	}
+	body += fmt.Sprintf("[via %s from %s]", machine, from)
+
+	args := []string{"mail", "send", to,
+		"-s", mailSubject,
+		"-m", body,
+		"--priority", strconv.Itoa(priority),
+		"--type", mailType,
+	}
+	for _, cc := range mailCC {
+		args = append(args, "--cc", cc)
+	}
+	if mailPermanent {
+		args = append(args, "--permanent")
+	}
+	if mailPinned {
+		args = append(args, "--pinned")
+	}
+	if mailNoNotify {
+		args = append(args, "--no-notify")
+	}
+	// Resolve the delivery time here: the remote clock and zone may differ.
+	if mailSendAt != "" || mailSendIn != "" {
+		deliverAt, err := parseDeliverAt(mailSendAt, mailSendIn, now)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--at", deliverAt.UTC().Format(time.RFC3339))
+	}
+	return args, nil
+}
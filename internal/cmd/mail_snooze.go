@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var mailScheduledJSON bool
+
+var mailSnoozeCmd = &cobra.Command{
+	Use:   "snooze <message-id> <duration>",
+	Short: "Hide a message until later",
+	Long: `Hide a message from your inbox for a while.
+
+The message comes back as unread when the snooze expires, and the daemon
+nudges you that it is back. Snoozing an already snoozed message replaces the
+snooze time. Accepts a message ID or an inbox index.
+
+Durations accept Go syntax (30m, 2h) and days (1d).
+
+Examples:
+  gt mail snooze hq-abc123 2h        # Until after the merge
+  gt mail snooze 1 1d                # First inbox message, until tomorrow`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMailSnooze,
+}
+
+var mailScheduledCmd = &cobra.Command{
+	Use:   "scheduled",
+	Short: "List snoozed and scheduled messages",
+	Long: `List messages held back from your inbox: mail sent to you with
+--at/--in that is not yet due, and messages you snoozed.`,
+	Args: cobra.NoArgs,
+	RunE: runMailScheduled,
+}
+
+func init() {
+	mailScheduledCmd.Flags().BoolVar(&mailScheduledJSON, "json", false, "Output as JSON")
+}
+
+func runMailSnooze(cmd *cobra.Command, args []string) error {
+	d, err := parseDuration(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", args[1], err)
+	}
+	if d <= 0 {
+		return fmt.Errorf("snooze duration must be positive")
+	}
+
+	mailbox, err := getMailbox(detectSender())
+	if err != nil {
+		return err
+	}
+	msgID, err := resolveMailRef(mailbox, args[0])
+	if err != nil {
+		return err
+	}
+
+	until := time.Now().Add(d)
+	if err := mailbox.Snooze(msgID, until); err != nil {
+		return fmt.Errorf("snoozing message: %w", err)
+	}
+	fmt.Printf("%s Snoozed %s until %s\n", style.Bold.Render("💤"), msgID, until.Format("2006-01-02 15:04"))
+	return nil
+}
+
+func runMailScheduled(cmd *cobra.Command, args []string) error {
+	mailbox, err := getMailbox(detectSender())
+	if err != nil {
+		return err
+	}
+	messages, err := mailbox.ListScheduled()
+	if err != nil {
+		return fmt.Errorf("listing scheduled mail: %w", err)
+	}
+
+	if mailScheduledJSON {
+		return outputJSON(messages)
+	}
+	if len(messages) == 0 {
+		fmt.Printf("%s\n", style.Dim.Render("(no snoozed or scheduled messages)"))
+		return nil
+	}
+	for _, msg := range messages {
+		kind := "scheduled"
+		if msg.SnoozedUntil != nil && msg.HiddenUntil().Equal(*msg.SnoozedUntil) {
+			kind = "snoozed"
+		}
+		fmt.Printf("  %s %s\n", style.Bold.Render(msg.ID), msg.Subject)
+		fmt.Printf("      %s until %s, from %s\n",
+			kind, msg.HiddenUntil().Local().Format("2006-01-02 15:04"), msg.From)
+	}
+	return nil
+}
+
+// parseDeliverAt resolves the --at/--in send flags to a delivery time.
+// --at accepts a clock time (the next occurrence of 15:04), a local
+// "2006-01-02 15:04" timestamp or RFC3339. --in accepts a duration.
+func parseDeliverAt(at, in string, now time.Time) (time.Time, error) {
+	if in != "" {
+		d, err := parseDuration(in)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid --in %q: %w", in, err)
+		}
+		if d <= 0 {
+			return time.Time{}, fmt.Errorf("--in must be positive")
+		}
+		return now.Add(d), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, at); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04", at, now.Location()); err == nil {
+		return t, nil
+	}
+	if clock, err := time.ParseInLocation("15:04", at, now.Location()); err == nil {
+		t := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+		if !t.After(now) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --at %q (want 15:04, \"2006-01-02 15:04\" or RFC3339)", at)
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDeliverAt(t *testing.T) {
+	now := time.Date(2026, 3, 10, 14, 30, 0, 0, time.Local)
+	tests := []struct {
+		name    string
+		at, in  string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "in hours", in: "2h", want: now.Add(2 * time.Hour)},
+		{name: "in days", in: "1d", want: now.Add(24 * time.Hour)},
+		{name: "clock later today", at: "16:00", want: time.Date(2026, 3, 10, 16, 0, 0, 0, time.Local)},
+		{name: "clock rolls to tomorrow", at: "09:00", want: time.Date(2026, 3, 11, 9, 0, 0, 0, time.Local)},
+		{name: "local timestamp", at: "2026-03-12 08:15", want: time.Date(2026, 3, 12, 8, 15, 0, 0, time.Local)},
+		{name: "rfc3339", at: "2026-03-12T08:15:00Z", want: time.Date(2026, 3, 12, 8, 15, 0, 0, time.UTC)},
+		{name: "negative delay", in: "-1h", wantErr: true},
+		{name: "garbage", at: "tomorrowish", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDeliverAt(tt.at, tt.in, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDeliverAt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("parseDeliverAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoteMailArgs(t *testing.T) {
+	oldSubject, oldBody, oldPriority, oldType := mailSubject, mailBody, mailPriority, mailType
+	oldCC, oldReplyTo, oldNoNotify, oldIn := mailCC, mailReplyTo, mailNoNotify, mailSendIn
+	t.Cleanup(func() {
+		mailSubject, mailBody, mailPriority, mailType = oldSubject, oldBody, oldPriority, oldType
+		mailCC, mailReplyTo, mailNoNotify, mailSendIn = oldCC, oldReplyTo, oldNoNotify, oldIn
+	})
+	mailSubject, mailBody, mailPriority, mailType = "Hi", "Body", 1, "task"
+	mailCC, mailNoNotify, mailSendIn = []string{"mayor/", "gastown/witness"}, true, "2h"
+
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	args, err := remoteMailArgs("laptop", "gastown/Toast", "mayor/", now)
+	if err != nil {
+		t.Fatalf("remoteMailArgs: %v", err)
+	}
+	got := strings.Join(args, " ")
+	for _, want := range []string{
+		"--priority 1", "--type task", "--cc mayor/ --cc gastown/witness",
+		"--no-notify", "--at 2026-03-01T11:00:00Z", "[via laptop from mayor/]",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("args %q missing %q", got, want)
+		}
+	}
+
+	mailReplyTo = "hq-123"
+	if _, err := remoteMailArgs("laptop", "gastown/Toast", "mayor/", now); err == nil {
+		t.Error("--reply-to to a remote address should be rejected")
+	}
+}
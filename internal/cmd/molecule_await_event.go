@@ -13,6 +13,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/channelevents"
+	"github.com/steveyegge/gastown/internal/eventbus"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -33,26 +34,30 @@ var validChannelName = channelevents.ValidChannelName
 
 var moleculeAwaitEventCmd = &cobra.Command{
 	Use:   "await-event",
-	Short: "Wait for a file-based event on a named channel",
-	Long: `Wait for event files to appear in ~/gt/events/<channel>/, with optional backoff.
+	Short: "Wait for an event on a named channel",
+	Long: `Wait for events on a named channel, with optional backoff.
 
-Unlike await-signal (which subscribes to the generic beads activity feed),
-await-event watches a dedicated event channel directory for .event files.
-Events are emitted via "gt mol step emit-event" or programmatically.
+Unlike await-signal (which wakes on any activity), await-event waits for
+events on a dedicated channel. Events are emitted via "gt mol step
+emit-event" or programmatically, and published on the daemon's event bus as
+channel.<channel>. When the daemon is not running they are written as files
+to ~/gt/events/<channel>/ instead.
 
 Channels are single-consumer: only one process should watch a given channel
-at a time. If multiple consumers watch the same channel with --cleanup,
-events may be deleted before all consumers read them.
+at a time. The channel's position on the bus is a durable cursor
+(await-event.<channel>) shared by all consumers of the channel.
 
 EVENT FORMAT:
-Events are JSON files in ~/gt/events/<channel>/*.event:
   {"type": "...", "channel": "...", "timestamp": "...", "payload": {...}}
 
 BEHAVIOR:
-1. Check for already-pending events (return immediately if found)
-2. If none, poll the directory until a new .event file appears or timeout
-3. On wake, return all pending event file paths and contents
-4. With --cleanup, delete processed event files automatically
+1. Return any pending event files immediately
+2. Otherwise subscribe to the bus from the channel's cursor, so events
+   published since the last --cleanup are returned right away, and wait
+   for new ones (polling the directory if the daemon is not running)
+3. On wake, return all pending events
+4. With --cleanup, mark the events processed: delete the event files and
+   advance the channel's cursor past the bus events
 
 BACKOFF MODE:
 Same as await-signal: base * multiplier^idle_cycles, capped at max.
@@ -84,9 +89,11 @@ type AwaitEventResult struct {
 	IdleCycles int           `json:"idle_cycles,omitempty"` // current idle cycle count
 }
 
-// EventFile represents a single event file.
+// EventFile represents a single event: a file in the channel directory, or
+// a message from the event bus.
 type EventFile struct {
-	Path    string          `json:"path"`
+	Path    string          `json:"path,omitempty"` // event file path (file events)
+	Seq     uint64          `json:"seq,omitempty"`  // bus sequence number (bus events)
 	Content json.RawMessage `json:"content"`
 }
 
@@ -197,7 +204,7 @@ func runMoleculeAwaitEvent(cmd *cobra.Command, args []string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	result, err := waitForEventFiles(ctx, eventDir)
+	result, err := waitForChannelEvents(ctx, townRoot, awaitEventChannel, eventDir)
 	if err != nil {
 		return fmt.Errorf("event watch failed: %w", err)
 	}
@@ -231,10 +238,21 @@ func runMoleculeAwaitEvent(cmd *cobra.Command, args []string) error {
 		_ = clearAgentBackoffUntil(awaitEventAgentBead, beadsDir)
 	}
 
-	// Cleanup event files if requested
+	// Cleanup event files and advance the bus cursor if requested
 	if awaitEventCleanup && result.Reason == "event" {
+		var lastSeq uint64
 		for _, ef := range result.Events {
-			_ = os.Remove(ef.Path)
+			if ef.Path != "" {
+				_ = os.Remove(ef.Path)
+			}
+			lastSeq = max(lastSeq, ef.Seq)
+		}
+		if lastSeq > 0 {
+			client := eventbus.NewClient(eventbus.SocketPath(townRoot))
+			if ackErr := client.Ack(awaitEventCursor(awaitEventChannel), lastSeq); ackErr != nil && !awaitEventQuiet {
+				fmt.Printf("%s Failed to mark bus events processed: %v\n",
+					style.Dim.Render("⚠"), ackErr)
+			}
 		}
 	}
 
@@ -303,6 +321,67 @@ func calculateEventTimeout(idleCycles int) (time.Duration, error) {
 	return time.ParseDuration(awaitEventTimeout)
 }
 
+// awaitEventCursor names the durable bus cursor that tracks which events on
+// a channel have been processed.
+func awaitEventCursor(channel string) string {
+	return "await-event." + channel
+}
+
+// busBatchWindow is how long to keep collecting after the first bus event,
+// so a backlog replayed from the cursor is returned as one batch.
+const busBatchWindow = 100 * time.Millisecond
+
+// waitForChannelEvents returns pending event files if there are any, and
+// otherwise waits on the event bus from the channel's cursor. If the bus is
+// not running, or goes away while waiting, it polls eventDir instead.
+func waitForChannelEvents(ctx context.Context, townRoot, channel, eventDir string) (*AwaitEventResult, error) {
+	pending, err := readPendingEvents(eventDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) > 0 {
+		return &AwaitEventResult{Reason: "event", Events: pending}, nil
+	}
+
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	msgs, err := eventbus.NewClient(eventbus.SocketPath(townRoot)).Subscribe(subCtx, eventbus.SubscribeOptions{
+		Topics: []string{eventbus.ChannelTopic(channel)},
+		Cursor: awaitEventCursor(channel),
+	})
+	if err != nil {
+		return waitForEventFiles(ctx, eventDir)
+	}
+
+	var received []EventFile
+	var batch <-chan time.Time
+	for {
+		select {
+		case m, ok := <-msgs:
+			if !ok {
+				if len(received) > 0 {
+					return &AwaitEventResult{Reason: "event", Events: received}, nil
+				}
+				if ctx.Err() != nil {
+					return &AwaitEventResult{Reason: "timeout"}, nil
+				}
+				return waitForEventFiles(ctx, eventDir)
+			}
+			received = append(received, EventFile{Seq: m.Seq, Content: m.Data})
+			if batch == nil {
+				batch = time.After(busBatchWindow)
+			}
+		case <-batch:
+			return &AwaitEventResult{Reason: "event", Events: received}, nil
+		case <-ctx.Done():
+			if len(received) > 0 {
+				return &AwaitEventResult{Reason: "event", Events: received}, nil
+			}
+			return &AwaitEventResult{Reason: "timeout"}, nil
+		}
+	}
+}
+
 // waitForEventFiles checks for pending events, then polls until events appear or timeout.
 // Uses a polling loop instead of inotifywait for cross-platform compatibility.
 func waitForEventFiles(ctx context.Context, eventDir string) (*AwaitEventResult, error) {
@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/eventbus"
 )
 
 func TestCalculateEventTimeout(t *testing.T) {
@@ -398,3 +400,72 @@ func TestEventFileStruct(t *testing.T) {
 		t.Errorf("type = %v, want MQ_SUBMIT", parsed["type"])
 	}
 }
+
+func TestWaitForChannelEventsFromBusCursor(t *testing.T) {
+	// Unix socket paths are limited to ~100 bytes, so avoid t.TempDir().
+	townRoot, err := os.MkdirTemp("", "gtawait")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(townRoot)
+	eventDir := filepath.Join(townRoot, "events", "refinery")
+
+	bus, err := eventbus.Open(eventbus.Dir(townRoot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bus.Close()
+	srvCtx, stop := context.WithCancel(context.Background())
+	defer stop()
+	go func() { _ = eventbus.Serve(srvCtx, bus, eventbus.SocketPath(townRoot)) }()
+
+	// Events published before anyone waits are replayed from the cursor.
+	for _, typ := range []string{"MERGE_READY", "PATROL_WAKE"} {
+		if _, err := bus.Publish(eventbus.ChannelTopic("refinery"), json.RawMessage(`{"type":"`+typ+`"}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := bus.Publish(eventbus.ChannelTopic("witness"), json.RawMessage(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(20 * time.Millisecond) {
+		if _, err := os.Stat(eventbus.SocketPath(townRoot)); err == nil {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatal("bus never came up")
+		}
+	}
+	wait := func() *AwaitEventResult {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		result, err := waitForChannelEvents(ctx, townRoot, "refinery", eventDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	result := wait()
+	if result.Reason != "event" || len(result.Events) != 2 || result.Events[1].Seq != 2 {
+		t.Fatalf("result = %+v", result)
+	}
+
+	// Without an ack the same events come back; after one they do not.
+	if again := wait(); len(again.Events) != 2 {
+		t.Fatalf("unacked replay = %+v", again)
+	}
+	if err := eventbus.NewClient(eventbus.SocketPath(townRoot)).Ack(awaitEventCursor("refinery"), 2); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	result, err = waitForChannelEvents(ctx, townRoot, "refinery", eventDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Reason != "timeout" {
+		t.Errorf("after ack: result = %+v", result)
+	}
+}
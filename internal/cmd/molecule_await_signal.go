@@ -14,6 +14,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/eventbus"
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -33,9 +34,11 @@ var moleculeAwaitSignalCmd = &cobra.Command{
 	Short: "Wait for activity feed signal with timeout",
 	Long: `Wait for any activity on the events feed, with optional backoff.
 
-This command is the primary wake mechanism for patrol agents. It tails
-~/gt/.events.jsonl and returns immediately when a new event is appended
-(indicating Gas Town activity such as slings, nudges, mail, spawns, etc.).
+This command is the primary wake mechanism for patrol agents. It subscribes
+to activity events on the daemon's event bus and returns immediately when a
+new event is published (indicating Gas Town activity such as slings, nudges,
+mail, spawns, etc.). When the daemon is not running it tails
+~/gt/.events.jsonl instead.
 
 If no activity occurs within the timeout, the command returns with exit code 0
 but sets the AWAIT_SIGNAL_REASON environment variable to "timeout".
@@ -341,11 +344,28 @@ func calculateEffectiveTimeout(idleCycles int) (time.Duration, error) {
 	return time.ParseDuration(awaitSignalTimeout)
 }
 
-// waitForActivitySignal tails the events file for new activity.
-// townRoot is the Gas Town workspace root; the events file is at
-// <townRoot>/.events.jsonl. Returns immediately when a new event line is
-// appended, or when context is canceled.
+// waitForActivitySignal waits for new activity on the town's event bus.
+// townRoot is the Gas Town workspace root. Returns immediately when a new
+// event is published, or when context is canceled. Falls back to tailing
+// <townRoot>/.events.jsonl when the bus is unreachable or drops the
+// subscription.
 func waitForActivitySignal(ctx context.Context, townRoot string) (*AwaitSignalResult, error) {
+	msgs, err := eventbus.NewClient(eventbus.SocketPath(townRoot)).Subscribe(ctx, eventbus.SubscribeOptions{
+		Topics: []string{eventbus.TopicActivity + ".>"},
+	})
+	if err == nil {
+		select {
+		case m, ok := <-msgs:
+			if ok {
+				return &AwaitSignalResult{Reason: "signal", Signal: string(m.Data)}, nil
+			}
+			if ctx.Err() != nil {
+				return &AwaitSignalResult{Reason: "timeout"}, nil
+			}
+		case <-ctx.Done():
+			return &AwaitSignalResult{Reason: "timeout"}, nil
+		}
+	}
 	return waitForEventsFile(ctx, filepath.Join(townRoot, events.EventsFile))
 }
 
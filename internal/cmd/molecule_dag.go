@@ -6,6 +6,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
@@ -45,6 +46,9 @@ Shows the dependency structure with execution tiers and status:
   ⧖ in_progress - Step being worked on
   ○ ready       - Step ready to execute (all deps met)
   ◌ blocked     - Step waiting on dependencies
+  ⊘ skipped     - Step excluded by its when/unless condition
+  ⏱ timed_out   - Step exceeded its timeout with no retries left
+  ✗ failed      - Step failed with no retries left
 
 Examples:
   gt mol dag gs-wisp-abc     # Show DAG for molecule
@@ -145,6 +149,7 @@ func buildDAG(b *beads.Beads, root *beads.Issue, children []*beads.Issue) (*DAGI
 	}
 
 	// Create nodes
+	now := time.Now()
 	for _, child := range children {
 		step := stepsMap[child.ID]
 		if step == nil {
@@ -170,8 +175,11 @@ func buildDAG(b *beads.Beads, root *beads.Issue, children []*beads.Issue) (*DAGI
 			node.Parallel = true
 		}
 
-		// Compute ready status for open steps
-		if child.Status == "open" {
+		// Execution-control outcomes override the raw status
+		if outcome := classifyStepOutcome(step, now); outcome != "" {
+			node.Status = outcome
+		} else if child.Status == "open" {
+			// Compute ready status for open steps
 			allDepsClosed := true
 			for _, depID := range node.Dependencies {
 				if !closedIDs[depID] {
@@ -329,8 +337,7 @@ func outputDAGTree(dag *DAGInfo) error {
 
 	// Legend
 	fmt.Println()
-	fmt.Printf("   %s done  %s in_progress  %s ready  %s blocked\n",
-		style.Bold.Render("✓"), style.Bold.Render("⧖"), style.Bold.Render("○"), style.Dim.Render("◌"))
+	printDAGLegend()
 
 	return nil
 }
@@ -353,18 +360,7 @@ func printNode(dag *DAGInfo, id, prefix string, isLast bool, visited map[string]
 		connector = "└─"
 	}
 
-	// Status icon
-	var icon string
-	switch node.Status {
-	case "closed":
-		icon = style.Bold.Render("✓")
-	case "in_progress":
-		icon = style.Bold.Render("⧖")
-	case "ready":
-		icon = style.Bold.Render("○")
-	default:
-		icon = style.Dim.Render("◌")
-	}
+	icon := dagStatusIcon(node.Status)
 
 	// Parallel marker
 	parallelMark := ""
@@ -412,18 +408,7 @@ func outputDAGTiers(dag *DAGInfo) error {
 				continue
 			}
 
-			// Status icon
-			var icon string
-			switch node.Status {
-			case "closed":
-				icon = style.Bold.Render("✓")
-			case "in_progress":
-				icon = style.Bold.Render("⧖")
-			case "ready":
-				icon = style.Bold.Render("○")
-			default:
-				icon = style.Dim.Render("◌")
-			}
+			icon := dagStatusIcon(node.Status)
 
 			// Parallel marker
 			parallelMark := ""
@@ -449,8 +434,34 @@ func outputDAGTiers(dag *DAGInfo) error {
 
 	// Legend
 	fmt.Println()
-	fmt.Printf("   %s done  %s in_progress  %s ready  %s blocked\n",
-		style.Bold.Render("✓"), style.Bold.Render("⧖"), style.Bold.Render("○"), style.Dim.Render("◌"))
+	printDAGLegend()
 
 	return nil
 }
+
+// dagStatusIcon returns the display icon for a DAG node status.
+func dagStatusIcon(status string) string {
+	switch status {
+	case "closed":
+		return style.Bold.Render("✓")
+	case "in_progress":
+		return style.Bold.Render("⧖")
+	case "ready":
+		return style.Bold.Render("○")
+	case beads.StepOutcomeSkipped:
+		return style.Dim.Render("⊘")
+	case beads.StepOutcomeTimedOut:
+		return style.Error.Render("⏱")
+	case beads.StepOutcomeFailed:
+		return style.Error.Render("✗")
+	default:
+		return style.Dim.Render("◌")
+	}
+}
+
+// printDAGLegend prints the status icon legend.
+func printDAGLegend() {
+	fmt.Printf("   %s done  %s in_progress  %s ready  %s blocked  %s skipped  %s timed_out  %s failed\n",
+		dagStatusIcon("closed"), dagStatusIcon("in_progress"), dagStatusIcon("ready"), dagStatusIcon("blocked"),
+		dagStatusIcon(beads.StepOutcomeSkipped), dagStatusIcon(beads.StepOutcomeTimedOut), dagStatusIcon(beads.StepOutcomeFailed))
+}
@@ -17,14 +17,16 @@ var (
 
 var moleculeEmitEventCmd = &cobra.Command{
 	Use:   "emit-event",
-	Short: "Emit a file-based event on a named channel",
-	Long: `Emit an event file to ~/gt/events/<channel>/ for subscribers to pick up.
+	Short: "Emit an event on a named channel",
+	Long: `Emit an event on a named channel for await-event subscribers to pick up
+(e.g., the refinery watching for MERGE_READY events).
 
-This is the Go counterpart to emit-event.sh. Events are JSON files consumed
-by await-event subscribers (e.g., the refinery watching for MERGE_READY events).
+The event is published on the daemon's event bus as channel.<channel>. If
+the daemon is not running, it is written as a file to ~/gt/events/<channel>/
+instead, which await-event also picks up. Prints where the event went:
+eventbus:channel.<channel>#<seq>, or the event file path.
 
 EVENT FORMAT:
-Creates a JSON file at ~/gt/events/<channel>/<timestamp>.event:
   {"type": "...", "channel": "...", "timestamp": "...", "payload": {...}}
 
 EXAMPLES:
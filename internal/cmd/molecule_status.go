@@ -114,6 +114,9 @@ type MoleculeProgressInfo struct {
 	InProgress   int      `json:"in_progress_steps"`
 	ReadySteps   []string `json:"ready_steps"`
 	BlockedSteps []string `json:"blocked_steps"`
+	SkippedSteps []string `json:"skipped_steps,omitempty"`   // Closed because when/unless excluded them
+	FailedSteps  []string `json:"failed_steps,omitempty"`    // Retries exhausted
+	TimedOut     []string `json:"timed_out_steps,omitempty"` // Exceeded their timeout
 	Percent      int      `json:"percent_complete"`
 	Complete     bool     `json:"complete"`
 }
@@ -216,9 +219,14 @@ func runMoleculeProgress(cmd *cobra.Command, args []string) error {
 	}
 
 	// Categorize steps
+	now := time.Now()
 	for _, child := range children {
 		progress.TotalSteps++
 
+		if progress.recordOutcome(child, now) {
+			continue
+		}
+
 		switch child.Status {
 		case "closed":
 			progress.DoneSteps++
@@ -295,6 +303,15 @@ func runMoleculeProgress(cmd *cobra.Command, args []string) error {
 	}
 	fmt.Println()
 	fmt.Printf("  Blocked:     %d\n", len(progress.BlockedSteps))
+	if len(progress.SkippedSteps) > 0 {
+		fmt.Printf("  Skipped:     %d (%s)\n", len(progress.SkippedSteps), strings.Join(progress.SkippedSteps, ", "))
+	}
+	if len(progress.TimedOut) > 0 {
+		fmt.Printf("  Timed out:   %d (%s)\n", len(progress.TimedOut), strings.Join(progress.TimedOut, ", "))
+	}
+	if len(progress.FailedSteps) > 0 {
+		fmt.Printf("  Failed:      %d (%s)\n", len(progress.FailedSteps), strings.Join(progress.FailedSteps, ", "))
+	}
 
 	if progress.Complete {
 		fmt.Printf("\n  %s\n", style.Bold.Render("✓ Molecule complete!"))
@@ -303,6 +320,25 @@ func runMoleculeProgress(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// recordOutcome files a step under skipped, failed or timed-out if its
+// execution controls say so. Skipped steps still count as done. Returns true
+// if the step needs no further categorization.
+func (p *MoleculeProgressInfo) recordOutcome(step *beads.Issue, now time.Time) bool {
+	switch classifyStepOutcome(step, now) {
+	case beads.StepOutcomeSkipped:
+		p.SkippedSteps = append(p.SkippedSteps, step.ID)
+		p.DoneSteps++
+		return true
+	case beads.StepOutcomeFailed:
+		p.FailedSteps = append(p.FailedSteps, step.ID)
+		return true
+	case beads.StepOutcomeTimedOut:
+		p.TimedOut = append(p.TimedOut, step.ID)
+		return true
+	}
+	return false
+}
+
 // extractMoleculeID extracts the molecule ID from an issue's description.
 func extractMoleculeID(description string) string {
 	lines := strings.Split(description, "\n")
@@ -630,9 +666,14 @@ func getMoleculeProgressInfo(b *beads.Beads, moleculeRootID string) (*MoleculePr
 	}
 
 	// Categorize steps
+	now := time.Now()
 	for _, child := range children {
 		progress.TotalSteps++
 
+		if progress.recordOutcome(child, now) {
+			continue
+		}
+
 		switch child.Status {
 		case "closed":
 			progress.DoneSteps++
@@ -799,6 +840,9 @@ func outputMoleculeStatus(status MoleculeStatusInfo) error {
 		}
 		fmt.Println()
 		fmt.Printf("  Blocked:     %d\n", len(status.Progress.BlockedSteps))
+		if n := len(status.Progress.TimedOut) + len(status.Progress.FailedSteps); n > 0 {
+			fmt.Printf("  Failed:      %d (timed out: %d)\n", n, len(status.Progress.TimedOut))
+		}
 
 		if status.Progress.Complete {
 			fmt.Printf("\n%s\n", style.Bold.Render("✓ Molecule complete!"))
@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
@@ -34,21 +35,30 @@ This command handles the step-to-step transition for polecats:
    - Sends POLECAT_DONE to witness
    - Exits the session
 
+Execution controls recorded on step beads are enforced here:
+  - when/unless: ready steps whose condition is false are closed as skipped
+  - timeout:     a step finished after its timeout counts as a timed-out attempt
+  - retries:     a failed or timed-out attempt re-pins the same step until
+                 its retries are used up; then the step is marked failed
+
 IMPORTANT: This is the canonical way to complete molecule steps. Do NOT manually
 close steps with 'bd close' - it skips the auto-continuation logic.
 
 Example:
-  gt mol step done gt-abc.1    # Complete step 1 of molecule gt-abc`,
+  gt mol step done gt-abc.1           # Complete step 1 of molecule gt-abc
+  gt mol step done gt-abc.2 --failed  # Record a failed attempt (retries if allowed)`,
 	Args: cobra.ExactArgs(1),
 	RunE: runMoleculeStepDone,
 }
 
 var (
 	moleculeStepDryRun bool
+	moleculeStepFailed bool
 )
 
 func init() {
 	moleculeStepDoneCmd.Flags().BoolVarP(&moleculeStepDryRun, "dry-run", "n", false, "Show what would be done without executing")
+	moleculeStepDoneCmd.Flags().BoolVar(&moleculeStepFailed, "failed", false, "Record a failed attempt instead of completing the step")
 	moleculeStepDoneCmd.Flags().BoolVar(&moleculeJSON, "json", false, "Output as JSON")
 }
 
@@ -60,8 +70,11 @@ type StepDoneResult struct {
 	NextStepID    string   `json:"next_step_id,omitempty"`
 	NextStepTitle string   `json:"next_step_title,omitempty"`
 	ParallelSteps []string `json:"parallel_steps,omitempty"` // Multiple ready steps for fan-out
+	SkippedSteps  []string `json:"skipped_steps,omitempty"`  // Steps closed because when/unless excluded them
+	Attempt       int      `json:"attempt,omitempty"`        // Failed attempts recorded on the step
+	Outcome       string   `json:"outcome,omitempty"`        // "failed" or "timed_out" when retries are exhausted
 	Complete      bool     `json:"complete"`
-	Action        string   `json:"action"` // "continue", "parallel", "done", "no_more_ready"
+	Action        string   `json:"action"` // "continue", "parallel", "retry", "failed", "done", "no_more_ready"
 }
 
 func runMoleculeStepDone(cmd *cobra.Command, args []string) error {
@@ -112,7 +125,15 @@ func runMoleculeStepDone(cmd *cobra.Command, args []string) error {
 		MoleculeID: moleculeID,
 	}
 
-	// Step 3: Close the step
+	// Step 3: Enforce retries/timeout. A failed or timed-out attempt either
+	// re-runs the same step or marks it failed; it is never closed.
+	fields := beads.ParseStepFields(step.Description)
+	timedOut := stepTimedOut(fields, time.Now())
+	if moleculeStepFailed || timedOut {
+		return handleStepAttemptFailed(cwd, townRoot, b, step, fields, result, timedOut)
+	}
+
+	// Step 4: Close the step
 	if moleculeStepDryRun {
 		fmt.Printf("[dry-run] Would close step: %s\n", stepID)
 		result.StepClosed = true
@@ -124,11 +145,16 @@ func runMoleculeStepDone(cmd *cobra.Command, args []string) error {
 		fmt.Printf("%s Closed step %s: %s\n", style.Bold.Render("✓"), stepID, step.Title)
 	}
 
-	// Step 4: Find all ready steps (supports fan-out pattern)
+	// Step 5: Find all ready steps (supports fan-out pattern), skipping any
+	// whose when/unless conditions exclude them.
 	readySteps, allComplete, err := findAllReadySteps(b, moleculeID)
 	if err != nil {
 		return fmt.Errorf("finding next steps: %w", err)
 	}
+	readySteps, allComplete, result.SkippedSteps, err = skipConditionalSteps(b, moleculeID, readySteps, allComplete, moleculeStepDryRun)
+	if err != nil {
+		return fmt.Errorf("skipping conditional steps: %w", err)
+	}
 
 	if allComplete {
 		result.Complete = true
@@ -156,12 +182,20 @@ func runMoleculeStepDone(cmd *cobra.Command, args []string) error {
 		return enc.Encode(result)
 	}
 
-	// Step 5: Handle next action
+	// Step 6: Handle next action
 	switch result.Action {
 	case "continue":
+		if !moleculeStepDryRun {
+			markStepStarted(b, readySteps[0].ID)
+		}
 		return handleStepContinue(cwd, townRoot, readySteps[0], moleculeStepDryRun)
 
 	case "parallel":
+		if !moleculeStepDryRun {
+			for _, s := range readySteps {
+				markStepStarted(b, s.ID)
+			}
+		}
 		return handleParallelSteps(cwd, townRoot, workDir, readySteps, moleculeStepDryRun)
 
 	case "done":
@@ -177,6 +211,46 @@ func runMoleculeStepDone(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// handleStepAttemptFailed records a failed or timed-out attempt. If retries
+// remain, the same step is re-pinned and the session respawned; otherwise the
+// step is left open with a failed/timed_out outcome, blocking its dependents.
+func handleStepAttemptFailed(cwd, townRoot string, b *beads.Beads, step *beads.Issue, fields *beads.StepFields, result StepDoneResult, timedOut bool) error {
+	retry, err := recordStepFailure(b, step, fields, timedOut, moleculeStepDryRun)
+	if err != nil {
+		return err
+	}
+	result.Attempt = fields.Attempts
+	if retry {
+		result.Action = "retry"
+		result.NextStepID = step.ID
+		result.NextStepTitle = step.Title
+	} else {
+		result.Action = "failed"
+		result.Outcome = fields.Outcome
+	}
+
+	if moleculeJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+
+	reason := "failed"
+	if timedOut {
+		reason = fmt.Sprintf("timed out (limit %s)", fields.Timeout)
+	}
+	if retry {
+		fmt.Printf("%s Step %s %s - retrying (attempt %d of %d)\n",
+			style.Bold.Render("↻"), step.ID, reason, fields.Attempts+1, fields.Retries+1)
+		return handleStepContinue(cwd, townRoot, step, moleculeStepDryRun)
+	}
+
+	fmt.Printf("%s Step %s %s after %d attempt(s) - no retries left\n",
+		style.Bold.Render("✗"), step.ID, reason, fields.Attempts)
+	fmt.Printf("Dependent steps stay blocked. Escalate with: gt escalate \"Step %s %s\"\n", step.ID, fields.Outcome)
+	return nil
+}
+
 // extractMoleculeIDFromStep extracts the molecule ID from a step ID.
 // Step IDs have format: mol-id.N where N is the step number.
 // Examples:
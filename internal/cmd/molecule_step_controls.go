@@ -0,0 +1,320 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/formula"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// maxSkipPasses bounds how many times conditional skipping re-queries ready
+// steps, so a malformed molecule can't loop forever.
+const maxSkipPasses = 32
+
+// moleculeStepVars returns the formula vars in effect for a molecule: the
+// attached formula's defaults overlaid with the --var values recorded on the
+// bead the molecule is attached to.
+func moleculeStepVars(b *beads.Beads, moleculeID string) map[string]string {
+	attachment := findMoleculeAttachment(b, moleculeID)
+	if attachment == nil {
+		return map[string]string{}
+	}
+
+	overrides := parseVarAssignments(attachment.AttachedVars)
+	for _, line := range strings.Split(attachment.FormulaVars, "\n") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(line), "="); ok {
+			if _, set := overrides[k]; !set {
+				overrides[k] = v
+			}
+		}
+	}
+
+	if attachment.AttachedFormula != "" {
+		if data, err := formula.GetEmbeddedFormulaContent(attachment.AttachedFormula); err == nil {
+			if f, err := formula.Parse(data); err == nil {
+				return f.VarValues(overrides)
+			}
+		}
+	}
+	return overrides
+}
+
+// findMoleculeAttachment locates the attachment fields that reference a
+// molecule: the molecule root itself, or a hooked/pinned bead it is attached to.
+func findMoleculeAttachment(b *beads.Beads, moleculeID string) *beads.AttachmentFields {
+	if root, err := b.Show(moleculeID); err == nil {
+		if attachment := beads.ParseAttachmentFields(root); attachment != nil &&
+			(attachment.AttachedMolecule == "" || attachment.AttachedMolecule == moleculeID) {
+			return attachment
+		}
+	}
+	for _, status := range []string{beads.StatusHooked, beads.StatusPinned} {
+		issues, err := b.List(beads.ListOptions{Status: status, Priority: -1})
+		if err != nil {
+			continue
+		}
+		for _, issue := range issues {
+			if attachment := beads.ParseAttachmentFields(issue); attachment != nil &&
+				attachment.AttachedMolecule == moleculeID {
+				return attachment
+			}
+		}
+	}
+	return nil
+}
+
+// parseVarAssignments turns ["key=value", ...] into a map.
+func parseVarAssignments(vars []string) map[string]string {
+	values := make(map[string]string, len(vars))
+	for _, v := range vars {
+		if k, val, ok := strings.Cut(v, "="); ok {
+			values[strings.TrimSpace(k)] = strings.TrimSpace(val)
+		}
+	}
+	return values
+}
+
+// stepShouldRun evaluates a step bead's when/unless fields against vars.
+// Steps with malformed conditions run, so a typo never silently drops work.
+func stepShouldRun(fields *beads.StepFields, vars map[string]string) bool {
+	step := formula.Step{When: fields.When, Unless: fields.Unless}
+	run, err := step.ShouldRun(vars)
+	if err != nil {
+		style.PrintWarning("ignoring invalid step condition: %v", err)
+		return true
+	}
+	return run
+}
+
+// stepTimedOut reports whether the step's current attempt exceeded its timeout.
+func stepTimedOut(fields *beads.StepFields, now time.Time) bool {
+	if fields.Timeout == "" || fields.StartedAt == "" {
+		return false
+	}
+	timeout, err := time.ParseDuration(fields.Timeout)
+	if err != nil || timeout <= 0 {
+		return false
+	}
+	started, err := time.Parse(time.RFC3339, fields.StartedAt)
+	if err != nil {
+		return false
+	}
+	return now.Sub(started) > timeout
+}
+
+// classifyStepOutcome returns the outcome to report for a step bead:
+// skipped, failed, timed_out, or "" for a normal step. Open steps whose
+// current attempt has run past its timeout are reported as timed out even
+// before the outcome is recorded.
+func classifyStepOutcome(step *beads.Issue, now time.Time) string {
+	fields := beads.ParseStepFields(step.Description)
+	if fields.Outcome != "" {
+		return fields.Outcome
+	}
+	if step.Status != "closed" && stepTimedOut(fields, now) {
+		return beads.StepOutcomeTimedOut
+	}
+	return ""
+}
+
+// recordStepFailure records a failed or timed-out attempt on a step bead.
+// Returns true if the step has retries left and should run again.
+func recordStepFailure(b *beads.Beads, step *beads.Issue, fields *beads.StepFields, timedOut, dryRun bool) (bool, error) {
+	fields.Attempts++
+	retry := fields.Attempts <= fields.Retries
+	if retry {
+		fields.StartedAt = time.Now().UTC().Format(time.RFC3339)
+		fields.Outcome = ""
+	} else if timedOut {
+		fields.Outcome = beads.StepOutcomeTimedOut
+	} else {
+		fields.Outcome = beads.StepOutcomeFailed
+	}
+
+	if dryRun {
+		fmt.Printf("[dry-run] Would record attempt %d/%d for %s\n", fields.Attempts, fields.Retries+1, step.ID)
+		return retry, nil
+	}
+	if err := b.UpdateStepFields(step, fields); err != nil {
+		return false, fmt.Errorf("recording step attempt: %w", err)
+	}
+	return retry, nil
+}
+
+// markStepStarted stamps started_at on a step with a timeout so the next
+// gt mol step done can enforce it.
+func markStepStarted(b *beads.Beads, stepID string) {
+	step, err := b.Show(stepID)
+	if err != nil {
+		return
+	}
+	fields := beads.ParseStepFields(step.Description)
+	if fields.Timeout == "" {
+		return
+	}
+	fields.StartedAt = time.Now().UTC().Format(time.RFC3339)
+	if err := b.UpdateStepFields(step, fields); err != nil {
+		style.PrintWarning("could not record start time for %s: %v", stepID, err)
+	}
+}
+
+// loadStepControlFormula parses a formula by file path, by name from the
+// formula search paths, or from the embedded formulas, and resolves its
+// extends and compose rules so the steps match what bd poured.
+func loadStepControlFormula(name string) (*formula.Formula, error) {
+	searchPaths := formulaSearchPaths()
+	var f *formula.Formula
+	var err error
+	if _, statErr := os.Stat(name); statErr == nil {
+		searchPaths = append([]string{filepath.Dir(name)}, searchPaths...)
+		f, err = formula.ParseFile(name)
+	} else if path, findErr := findFormulaFile(name); findErr == nil {
+		f, err = formula.ParseFile(path)
+	} else {
+		data, embedErr := formula.GetEmbeddedFormulaContent(name)
+		if embedErr != nil {
+			return nil, embedErr
+		}
+		f, err = formula.Parse(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return formula.Resolve(f, searchPaths)
+}
+
+// applyFormulaStepControls copies when/unless/retries/timeout from a
+// formula's resolved steps onto the step beads poured from them, skips the
+// ready steps whose conditions exclude them, then stamps the start time of
+// the steps that are ready now so their timeouts are enforced. bd pours
+// steps without these fields.
+func applyFormulaStepControls(b *beads.Beads, moleculeID, formulaName string) error {
+	f, err := loadStepControlFormula(formulaName)
+	if err != nil {
+		return fmt.Errorf("loading formula %s: %w", formulaName, err)
+	}
+	var controlled []formula.Step
+	for _, step := range f.Steps {
+		if step.When != "" || step.Unless != "" || step.Retries > 0 || step.Timeout != "" {
+			controlled = append(controlled, step)
+		}
+	}
+	if len(controlled) == 0 {
+		return nil
+	}
+
+	children, err := b.List(beads.ListOptions{Parent: moleculeID, Status: "all", Priority: -1})
+	if err != nil {
+		return fmt.Errorf("listing molecule steps: %w", err)
+	}
+	for _, step := range controlled {
+		child := matchPouredStep(children, step)
+		if child == nil {
+			style.PrintWarning("no poured step for %s in %s; its controls are not applied", step.ID, moleculeID)
+			continue
+		}
+		fields := beads.ParseStepFields(child.Description)
+		fields.When, fields.Unless = step.When, step.Unless
+		fields.Retries, fields.Timeout = step.Retries, step.Timeout
+		if err := b.UpdateStepFields(child, fields); err != nil {
+			return fmt.Errorf("recording controls on %s: %w", child.ID, err)
+		}
+	}
+
+	ready, allComplete, err := findAllReadySteps(b, moleculeID)
+	if err != nil {
+		return err
+	}
+	ready, _, _, err = skipConditionalSteps(b, moleculeID, ready, allComplete, false)
+	if err != nil {
+		return err
+	}
+	for _, step := range ready {
+		markStepStarted(b, step.ID)
+	}
+	return nil
+}
+
+// matchPouredStep finds the step bead poured from a formula step by its step
+// ID: an ID suffix or a "step:" provenance line. Titles are not unique
+// across a formula, so they are never used to match.
+func matchPouredStep(children []*beads.Issue, step formula.Step) *beads.Issue {
+	for _, child := range children {
+		if strings.HasSuffix(child.ID, "."+step.ID) {
+			return child
+		}
+	}
+	for _, child := range children {
+		for _, line := range strings.Split(child.Description, "\n") {
+			if strings.TrimSpace(line) == "step: "+step.ID {
+				return child
+			}
+		}
+	}
+	return nil
+}
+
+// skipConditionalSteps closes ready steps whose when/unless conditions exclude
+// them and re-queries readiness until only runnable steps remain.
+// Returns the runnable ready steps, whether the molecule is complete, and the
+// IDs of skipped steps.
+func skipConditionalSteps(b *beads.Beads, moleculeID string, ready []*beads.Issue, allComplete, dryRun bool) ([]*beads.Issue, bool, []string, error) {
+	var vars map[string]string
+	var skipped []string
+
+	for pass := 0; pass < maxSkipPasses && !allComplete; pass++ {
+		var runnable []*beads.Issue
+		var toSkip []*beads.Issue
+		for _, step := range ready {
+			fields := beads.ParseStepFields(step.Description)
+			if fields.When == "" && fields.Unless == "" {
+				runnable = append(runnable, step)
+				continue
+			}
+			if vars == nil {
+				vars = moleculeStepVars(b, moleculeID)
+			}
+			if stepShouldRun(fields, vars) {
+				runnable = append(runnable, step)
+			} else {
+				toSkip = append(toSkip, step)
+			}
+		}
+		if len(toSkip) == 0 {
+			return runnable, false, skipped, nil
+		}
+
+		for _, step := range toSkip {
+			skipped = append(skipped, step.ID)
+			if dryRun {
+				fmt.Printf("[dry-run] Would skip step %s (condition not met)\n", step.ID)
+				continue
+			}
+			fields := beads.ParseStepFields(step.Description)
+			fields.Outcome = beads.StepOutcomeSkipped
+			if err := b.UpdateStepFields(step, fields); err != nil {
+				return nil, false, skipped, fmt.Errorf("marking %s skipped: %w", step.ID, err)
+			}
+			if err := b.CloseWithReason("skipped: condition not met", step.ID); err != nil {
+				return nil, false, skipped, fmt.Errorf("closing skipped step %s: %w", step.ID, err)
+			}
+			fmt.Printf("%s Skipped step %s: %s\n", style.Dim.Render("⊘"), step.ID, step.Title)
+		}
+		if dryRun {
+			// Nothing was closed, so re-querying would return the same set.
+			return runnable, false, skipped, nil
+		}
+
+		var err error
+		ready, allComplete, err = findAllReadySteps(b, moleculeID)
+		if err != nil {
+			return nil, false, skipped, err
+		}
+	}
+	return ready, allComplete, skipped, nil
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/formula"
+)
+
+func TestParseVarAssignments(t *testing.T) {
+	got := parseVarAssignments([]string{"feature=auth", " env = prod ", "novalue"})
+	if got["feature"] != "auth" || got["env"] != "prod" {
+		t.Errorf("parseVarAssignments = %v", got)
+	}
+	if _, ok := got["novalue"]; ok {
+		t.Error("entries without '=' should be ignored")
+	}
+}
+
+func TestStepShouldRun(t *testing.T) {
+	vars := map[string]string{"lint": "true", "env": "dev"}
+	tests := []struct {
+		name   string
+		fields beads.StepFields
+		want   bool
+	}{
+		{"no conditions", beads.StepFields{}, true},
+		{"when true", beads.StepFields{When: "lint"}, true},
+		{"when false", beads.StepFields{When: "e2e"}, false},
+		{"unless true", beads.StepFields{Unless: "env == dev"}, false},
+		{"unless false", beads.StepFields{Unless: "env == prod"}, true},
+		{"invalid runs", beads.StepFields{When: "&&"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stepShouldRun(&tt.fields, vars); got != tt.want {
+				t.Errorf("stepShouldRun(%+v) = %v, want %v", tt.fields, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStepTimedOut(t *testing.T) {
+	now := time.Date(2026, 1, 2, 13, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		fields beads.StepFields
+		want   bool
+	}{
+		{"no timeout", beads.StepFields{StartedAt: "2026-01-02T10:00:00Z"}, false},
+		{"not started", beads.StepFields{Timeout: "45m"}, false},
+		{"within limit", beads.StepFields{Timeout: "2h", StartedAt: "2026-01-02T12:00:00Z"}, false},
+		{"past limit", beads.StepFields{Timeout: "45m", StartedAt: "2026-01-02T12:00:00Z"}, true},
+		{"bad duration", beads.StepFields{Timeout: "soon", StartedAt: "2026-01-02T12:00:00Z"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stepTimedOut(&tt.fields, now); got != tt.want {
+				t.Errorf("stepTimedOut = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoleculeProgressRecordOutcome(t *testing.T) {
+	now := time.Date(2026, 1, 2, 13, 0, 0, 0, time.UTC)
+	steps := []*beads.Issue{
+		{ID: "gt-m.1", Status: "closed", Description: "[step-controls]\noutcome: skipped"},
+		{ID: "gt-m.2", Status: "open", Description: "[step-controls]\nretries: 1\nattempts: 2\noutcome: failed"},
+		{ID: "gt-m.3", Status: "in_progress", Description: "[step-controls]\ntimeout: 30m\nstarted_at: 2026-01-02T12:00:00Z"},
+		{ID: "gt-m.4", Status: "open", Description: "Plain step."},
+	}
+
+	var p MoleculeProgressInfo
+	for _, s := range steps {
+		handled := p.recordOutcome(s, now)
+		if want := s.ID != "gt-m.4"; handled != want {
+			t.Errorf("recordOutcome(%s) = %v, want %v", s.ID, handled, want)
+		}
+	}
+	if len(p.SkippedSteps) != 1 || p.DoneSteps != 1 {
+		t.Errorf("skipped = %v, done = %d; skipped steps should count as done", p.SkippedSteps, p.DoneSteps)
+	}
+	if len(p.FailedSteps) != 1 || p.FailedSteps[0] != "gt-m.2" {
+		t.Errorf("FailedSteps = %v", p.FailedSteps)
+	}
+	if len(p.TimedOut) != 1 || p.TimedOut[0] != "gt-m.3" {
+		t.Errorf("TimedOut = %v", p.TimedOut)
+	}
+}
+
+func TestMatchPouredStep(t *testing.T) {
+	children := []*beads.Issue{
+		{ID: "gt-wisp-a1", Title: "Build"},
+		{ID: "gt-wisp-a2", Title: "Run e2e for {{feature}}", Description: "Run it.\n\nstep: e2e"},
+		{ID: "gt-mol.deploy", Title: "Ship it"},
+	}
+	tests := []struct {
+		step formula.Step
+		want string
+	}{
+		{formula.Step{ID: "deploy", Title: "Deploy"}, "gt-mol.deploy"},
+		{formula.Step{ID: "e2e", Title: "Run e2e"}, "gt-wisp-a2"},
+		// Titles repeat across formulas, so a title alone never matches.
+		{formula.Step{ID: "build", Title: "Build"}, ""},
+		{formula.Step{ID: "lint", Title: "Lint"}, ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if child := matchPouredStep(children, tt.step); child != nil {
+			got = child.ID
+		}
+		if got != tt.want {
+			t.Errorf("matchPouredStep(%s) = %q, want %q", tt.step.ID, got, tt.want)
+		}
+	}
+}
+
+func TestLoadStepControlFormula_ResolvesExtends(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		path := filepath.Join(dir, name+".formula.toml")
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	write("controls-base", `formula = "controls-base"
+
+[[steps]]
+id = "e2e"
+title = "Run e2e"
+when = "e2e"
+retries = 2
+`)
+	path := write("controls-child", `formula = "controls-child"
+extends = ["controls-base"]
+
+[[steps]]
+id = "ship"
+title = "Ship"
+`)
+
+	f, err := loadStepControlFormula(path)
+	if err != nil {
+		t.Fatalf("loadStepControlFormula: %v", err)
+	}
+	step := f.GetStep("e2e")
+	if step == nil {
+		t.Fatalf("inherited step e2e missing from resolved formula")
+	}
+	if step.When != "e2e" || step.Retries != 2 {
+		t.Errorf("e2e controls = when %q, retries %d; want e2e, 2", step.When, step.Retries)
+	}
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var mqGatesJSON bool
+
+var mqGatesCmd = &cobra.Command{
+	Use:   "gates <rig>",
+	Short: "Show quality gate pass/fail history and flakiness",
+	Long: `Show the refinery's recorded quality gate history for a rig.
+
+Every gate run is recorded with the commit it ran on. A gate that both
+failed and passed on the same commit flaked: the code did not change, so
+the failure was noise. Every gate failure is retried once so a first flake
+is caught; failures of gates with a flake history are retried
+merge_queue.gate_flake_retries times before the MR is sent back for fixes.
+Gates whose flake rate reaches merge_queue.gate_quarantine_rate are
+quarantined: their failures are reported but do not block merges.
+
+Output format:
+  GATE    RUNS  PASS%  FLAKY  BROKEN  FLAKE%  AVG    STATUS       LAST
+  build     42   100%      0       0      0%  38s    stable       5m
+  test      42    88%      3       2      7%  2m14s  flaky        5m
+
+Examples:
+  gt mq gates greenplace
+  gt mq gates greenplace --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMQGates,
+}
+
+func init() {
+	mqGatesCmd.Flags().BoolVar(&mqGatesJSON, "json", false, "Output as JSON")
+	mqCmd.AddCommand(mqGatesCmd)
+}
+
+// mqGateReport is the JSON form of one gate's history.
+type mqGateReport struct {
+	refinery.GateStats
+	FlakeRate float64 `json:"flake_rate"`
+	Status    string  `json:"status"`
+}
+
+func runMQGates(cmd *cobra.Command, args []string) error {
+	rigName := args[0]
+
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	e := refinery.NewEngineer(r)
+	if err := e.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+	stats, err := e.GateReport()
+	if err != nil {
+		return fmt.Errorf("reading gate history: %w", err)
+	}
+
+	if mqGatesJSON {
+		report := make([]mqGateReport, 0, len(stats))
+		for _, s := range stats {
+			report = append(report, mqGateReport{GateStats: s, FlakeRate: s.FlakeRate(), Status: s.Status()})
+		}
+		return outputJSON(report)
+	}
+
+	fmt.Printf("%s Quality gates for '%s':\n\n", style.Bold.Render("🚦"), rigName)
+	if len(stats) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("(no gate runs recorded)"))
+		return nil
+	}
+
+	table := style.NewTable(
+		style.Column{Name: "GATE", Width: 20},
+		style.Column{Name: "RUNS", Width: 5, Align: style.AlignRight},
+		style.Column{Name: "PASS%", Width: 6, Align: style.AlignRight},
+		style.Column{Name: "FLAKY", Width: 6, Align: style.AlignRight},
+		style.Column{Name: "BROKEN", Width: 7, Align: style.AlignRight},
+		style.Column{Name: "FLAKE%", Width: 7, Align: style.AlignRight},
+		style.Column{Name: "AVG", Width: 8},
+		style.Column{Name: "STATUS", Width: 12},
+		style.Column{Name: "LAST", Width: 6, Align: style.AlignRight},
+	)
+	quarantined := 0
+	for _, s := range stats {
+		status := s.Status()
+		switch status {
+		case "quarantined":
+			quarantined++
+			status = style.Error.Render(status)
+		case "flaky":
+			status = style.Warning.Render(status)
+		default:
+			status = style.Success.Render(status)
+		}
+		passRate := 0.0
+		if s.Runs > 0 {
+			passRate = float64(s.Passes) / float64(s.Runs) * 100
+		}
+		table.AddRow(
+			s.Gate,
+			fmt.Sprintf("%d", s.Runs),
+			fmt.Sprintf("%.0f%%", passRate),
+			fmt.Sprintf("%d", s.FlakyCommits),
+			fmt.Sprintf("%d", s.BrokenCommits),
+			fmt.Sprintf("%.0f%%", s.FlakeRate()*100),
+			s.AvgElapsed.Round(time.Second).String(),
+			status,
+			style.Dim.Render(formatMRAge(s.LastRun.Format(time.RFC3339))),
+		)
+	}
+	fmt.Print(table.Render())
+
+	if quarantined > 0 {
+		fmt.Printf("\n  %s %d gate(s) quarantined: failures are reported but do not block merges\n",
+			style.Warning.Render("⚠"), quarantined)
+	}
+	return nil
+}
@@ -34,11 +34,23 @@ type MRStatusOutput struct {
 	MergeCommit string `json:"merge_commit,omitempty"`
 	CloseReason string `json:"close_reason,omitempty"`
 
+	// Speculative pipeline timing (refinery speculative mode)
+	Speculation *MRSpeculationInfo `json:"speculation,omitempty"`
+
 	// Dependencies
 	DependsOn []DependencyInfo `json:"depends_on,omitempty"`
 	Blocks    []DependencyInfo `json:"blocks,omitempty"`
 }
 
+// MRSpeculationInfo is the refinery's last speculative gate run on the stack
+// ending at this MR.
+type MRSpeculationInfo struct {
+	Depth   int    `json:"depth"`
+	Result  string `json:"result"`
+	Elapsed string `json:"elapsed,omitempty"`
+	At      string `json:"at,omitempty"`
+}
+
 // DependencyInfo represents a dependency or blocker.
 type DependencyInfo struct {
 	ID       string `json:"id"`
@@ -95,6 +107,14 @@ func runMqStatus(cmd *cobra.Command, args []string) error {
 		output.Rig = mrFields.Rig
 		output.MergeCommit = mrFields.MergeCommit
 		output.CloseReason = mrFields.CloseReason
+		if mrFields.SpeculationDepth > 0 {
+			output.Speculation = &MRSpeculationInfo{
+				Depth:   mrFields.SpeculationDepth,
+				Result:  mrFields.SpeculationResult,
+				Elapsed: mrFields.SpeculationElapsed,
+				At:      mrFields.SpeculatedAt,
+			}
+		}
 	}
 
 	// Add dependency info from the issue's Dependencies field
@@ -186,6 +206,19 @@ func printMqStatus(issue *beads.Issue, mrFields *beads.MRFields) error {
 		}
 	}
 
+	// Speculative pipeline timing
+	if mrFields != nil && mrFields.SpeculationDepth > 0 {
+		fmt.Printf("\n%s\n", style.Bold.Render("Speculation"))
+		fmt.Printf("   Stack:   %d MR(s) deep\n", mrFields.SpeculationDepth)
+		fmt.Printf("   Result:  %s\n", mrFields.SpeculationResult)
+		if mrFields.SpeculationElapsed != "" {
+			fmt.Printf("   Gates:   %s\n", mrFields.SpeculationElapsed)
+		}
+		if mrFields.SpeculatedAt != "" {
+			fmt.Printf("   Ran:     %s %s\n", mrFields.SpeculatedAt, formatTimeAgo(mrFields.SpeculatedAt))
+		}
+	}
+
 	// Dependencies (what this MR is waiting on)
 	if len(issue.Dependencies) > 0 {
 		fmt.Printf("\n%s\n", style.Bold.Render("Waiting On"))
@@ -332,6 +365,11 @@ func getDescriptionWithoutMRFields(description string) string {
 		"close-reason": true,
 		"closereason":  true,
 		"type":         true,
+
+		"speculation_depth":   true,
+		"speculation_result":  true,
+		"speculation_elapsed": true,
+		"speculated_at":       true,
 	}
 
 	var lines []string
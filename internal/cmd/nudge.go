@@ -108,6 +108,10 @@ Channel syntax:
                   ~/gt/config/messaging.json under "nudge_channels".
                   Patterns like "gastown/polecats/*" are expanded.
 
+Remote machines:
+  <machine>:<target>  Nudges a worker in the town on a machine registered
+                      with gt machine add (e.g. vm:gastown/furiosa).
+
 DND (Do Not Disturb):
   If the target has DND enabled (gt dnd on), the nudge is skipped.
   Use --force to override DND and send anyway.
@@ -169,6 +173,12 @@ func deliverNudge(t *tmux.Tmux, sessionName, message, sender string) error {
 	// FormatForInjection adds the prefix, so we must NOT double-prefix.
 	prefixedMessage := fmt.Sprintf("[from %s] %s", sender, message)
 
+	// Headless sessions have no tmux pane: type the nudge straight into the
+	// session's PTY via the daemon. Queue mode still works as usual.
+	if mode != NudgeModeQueue && session.BackendName(townRoot) == config.SessionBackendHeadless {
+		return session.NewBackend(townRoot).NudgeSession(sessionName, prefixedMessage)
+	}
+
 	switch mode {
 	case NudgeModeQueue:
 		if townRoot == "" {
@@ -422,6 +432,22 @@ func runNudge(cmd *cobra.Command, args []string) (retErr error) {
 		}
 	}
 
+	// machine:target nudges an agent in a remote town. The remote gt applies
+	// its own DND and delivery rules.
+	remote, remoteTarget, err := resolveMachineAddress(target)
+	if err != nil {
+		return err
+	}
+	if remote != nil {
+		defer remote.Close()
+		remoteArgs := []string{"nudge", remoteTarget, "-m", fmt.Sprintf("[from %s] %s", sender, message),
+			"--mode", nudgeModeFlag, "--priority", nudgePriorityFlag}
+		if nudgeForceFlag {
+			remoteArgs = append(remoteArgs, "--force")
+		}
+		return remote.run(os.Stdout, remoteArgs...)
+	}
+
 	// Handle channel syntax: channel:<name>
 	if strings.HasPrefix(target, "channel:") {
 		channelName := strings.TrimPrefix(target, "channel:")
@@ -447,6 +473,8 @@ func runNudge(cmd *cobra.Command, args []string) (retErr error) {
 	}
 
 	t := tmux.NewTmux()
+	// Session existence checks go through the town's configured backend.
+	sessions := session.ResolveBackend(townRoot, t)
 
 	// Expand role shortcuts to session names
 	// These shortcuts let users type "mayor" instead of "gt-mayor"
@@ -477,7 +505,7 @@ func runNudge(cmd *cobra.Command, args []string) (retErr error) {
 		hasACP := hasACPSessionByName(townRoot, deaconSession)
 		exists := false
 		if !hasACP {
-			exists, _ = t.HasSession(deaconSession)
+			exists, _ = sessions.HasSession(deaconSession)
 		}
 
 		if !hasACP && !exists {
@@ -529,7 +557,7 @@ func runNudge(cmd *cobra.Command, args []string) (retErr error) {
 			// Try crew first (matches mail system's addressToSessionIDs pattern),
 			// then fall back to polecat.
 			crewSession := crewSessionName(rigName, polecatName)
-			if exists, _ := t.HasSession(crewSession); exists {
+			if exists, _ := sessions.HasSession(crewSession); exists {
 				sessionName = crewSession
 			} else {
 				mgr, _, err := getSessionManager(rigName)
@@ -545,7 +573,7 @@ func runNudge(cmd *cobra.Command, args []string) (retErr error) {
 		// the file is written but never drained.
 		// ACP sessions are always allowed as they use queue mode.
 		if nudgeModeFlag != NudgeModeImmediate && !hasACPSessionByName(townRoot, sessionName) {
-			exists, err := t.HasSession(sessionName)
+			exists, err := sessions.HasSession(sessionName)
 			if err != nil {
 				return fmt.Errorf("checking session: %w", err)
 			}
@@ -572,7 +600,7 @@ func runNudge(cmd *cobra.Command, args []string) (retErr error) {
 		hasACP := hasACPSessionByName(townRoot, target)
 
 		if !hasACP {
-			exists, err := t.HasSession(target)
+			exists, err := sessions.HasSession(target)
 			if err != nil {
 				return fmt.Errorf("checking session: %w", err)
 			}
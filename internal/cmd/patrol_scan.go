@@ -23,7 +23,7 @@ var (
 
 var patrolScanCmd = &cobra.Command{
 	Use:   "scan",
-	Short: "Scan polecats for zombies, stalls, and completions",
+	Short: "Scan polecats for zombies, stalls, thrashing, and completions",
 	Long: `Run proactive detection across all polecats in a rig.
 
 This command bridges the witness library detection functions to the CLI,
@@ -33,11 +33,16 @@ Detections:
   - Zombies: Dead sessions with active agent state, dead agent processes,
     stuck done-intent, closed beads with live sessions
   - Stalls: Agents stuck at startup prompts
+  - Thrashing: Busy agents going in circles — the same tool call over and
+    over, the same error again and again, or edits that undo earlier edits
+    (read from the agent's conversation log over witness thrash_window)
   - Completions: Agent bead metadata indicating gt done was called
 
 Actions taken automatically:
   - Zombie restart: Sessions are restarted (not nuked) to preserve worktrees
   - Cleanup wisps: Created for dirty state tracking
+  - Thrashing: The polecat is nudged with the evidence; if it keeps
+    thrashing after the nudge, the next scan escalates it
   - Completion routing: MR cleanup wisps created, refinery nudged
 
 Use --notify to send mail when zombies with active work are detected.
@@ -65,6 +70,7 @@ type PatrolScanOutput struct {
 	Timestamp   string                    `json:"timestamp"`
 	Zombies     *PatrolScanZombieOutput   `json:"zombies"`
 	Stalls      *PatrolScanStallOutput    `json:"stalls,omitempty"`
+	Thrash      *PatrolScanThrashOutput   `json:"thrash,omitempty"`
 	Completions *PatrolScanCompleteOutput `json:"completions,omitempty"`
 	Receipts    []witness.PatrolReceipt   `json:"receipts,omitempty"`
 }
@@ -104,6 +110,22 @@ type PatrolScanStallItem struct {
 	Error     string `json:"error,omitempty"`
 }
 
+// PatrolScanThrashOutput holds thrash detection results.
+type PatrolScanThrashOutput struct {
+	Checked   int                    `json:"checked"`
+	Found     int                    `json:"found"`
+	Thrashing []PatrolScanThrashItem `json:"thrashing,omitempty"`
+	Errors    []string               `json:"errors,omitempty"`
+}
+
+// PatrolScanThrashItem is a single thrashing polecat in scan output.
+type PatrolScanThrashItem struct {
+	Polecat  string                  `json:"polecat"`
+	Findings []witness.ThrashFinding `json:"findings"`
+	Action   string                  `json:"action"`
+	Error    string                  `json:"error,omitempty"`
+}
+
 // PatrolScanCompleteOutput holds completion discovery results.
 type PatrolScanCompleteOutput struct {
 	Checked   int                       `json:"checked"`
@@ -148,16 +170,18 @@ func runPatrolScan(cmd *cobra.Command, args []string) error {
 
 	timestamp := time.Now().UTC().Format(time.RFC3339)
 
-	// Run all three detection passes.
+	// Run all detection passes.
 	// Note: DetectZombiePolecats takes a router param but does NOT send mail
 	// internally — it only uses the router for workspace context. Notifications
 	// are sent exclusively below via --notify, avoiding double-send.
 	zombieResult := witness.DetectZombiePolecats(bd, workDir, rigName, router)
 	stallResult := witness.DetectStalledPolecats(workDir, rigName)
+	thrashResult := witness.DetectThrashingPolecats(workDir, rigName)
 	completionResult := witness.DiscoverCompletions(bd, workDir, rigName, router)
 
-	// Build patrol receipts for zombies
+	// Build patrol receipts for zombies and thrashing polecats
 	receipts := witness.BuildPatrolReceipts(rigName, zombieResult)
+	receipts = append(receipts, witness.BuildThrashReceipts(rigName, thrashResult)...)
 
 	// Send notifications only when explicitly requested via --notify.
 	// The library detection functions do not send mail themselves.
@@ -169,10 +193,10 @@ func runPatrolScan(cmd *cobra.Command, args []string) error {
 	}
 
 	if patrolScanJSON {
-		return outputPatrolScanJSON(rigName, timestamp, zombieResult, stallResult, completionResult, receipts)
+		return outputPatrolScanJSON(rigName, timestamp, zombieResult, stallResult, thrashResult, completionResult, receipts)
 	}
 
-	return outputPatrolScanHuman(rigName, zombieResult, stallResult, completionResult, receipts)
+	return outputPatrolScanHuman(rigName, zombieResult, stallResult, thrashResult, completionResult, receipts)
 }
 
 func countActiveWorkZombies(result *witness.DetectZombiePolecatsResult) int {
@@ -214,7 +238,7 @@ func sendZombieNotification(router *mail.Router, rigName string, result *witness
 	_ = router.Send(msg)
 }
 
-func outputPatrolScanJSON(rigName, timestamp string, zombieResult *witness.DetectZombiePolecatsResult, stallResult *witness.DetectStalledPolecatsResult, completionResult *witness.DiscoverCompletionsResult, receipts []witness.PatrolReceipt) error {
+func outputPatrolScanJSON(rigName, timestamp string, zombieResult *witness.DetectZombiePolecatsResult, stallResult *witness.DetectStalledPolecatsResult, thrashResult *witness.DetectThrashingPolecatsResult, completionResult *witness.DiscoverCompletionsResult, receipts []witness.PatrolReceipt) error {
 	output := PatrolScanOutput{
 		Rig:       rigName,
 		Timestamp: timestamp,
@@ -268,6 +292,29 @@ func outputPatrolScanJSON(rigName, timestamp string, zombieResult *witness.Detec
 		output.Stalls = so
 	}
 
+	// Thrashing
+	if thrashResult != nil {
+		to := &PatrolScanThrashOutput{
+			Checked: thrashResult.Checked,
+			Found:   len(thrashResult.Thrashing),
+		}
+		for _, th := range thrashResult.Thrashing {
+			item := PatrolScanThrashItem{
+				Polecat:  th.PolecatName,
+				Findings: th.Findings,
+				Action:   th.Action,
+			}
+			if th.Error != nil {
+				item.Error = th.Error.Error()
+			}
+			to.Thrashing = append(to.Thrashing, item)
+		}
+		for _, e := range thrashResult.Errors {
+			to.Errors = append(to.Errors, e.Error())
+		}
+		output.Thrash = to
+	}
+
 	// Completions
 	if completionResult != nil {
 		co := &PatrolScanCompleteOutput{
@@ -295,7 +342,7 @@ func outputPatrolScanJSON(rigName, timestamp string, zombieResult *witness.Detec
 	return enc.Encode(output)
 }
 
-func outputPatrolScanHuman(rigName string, zombieResult *witness.DetectZombiePolecatsResult, stallResult *witness.DetectStalledPolecatsResult, completionResult *witness.DiscoverCompletionsResult, _ []witness.PatrolReceipt) error {
+func outputPatrolScanHuman(rigName string, zombieResult *witness.DetectZombiePolecatsResult, stallResult *witness.DetectStalledPolecatsResult, thrashResult *witness.DetectThrashingPolecatsResult, completionResult *witness.DiscoverCompletionsResult, _ []witness.PatrolReceipt) error {
 	fmt.Printf("%s Patrol scan: %s\n\n", style.Bold.Render("🔍"), rigName)
 
 	// Zombies
@@ -358,6 +405,34 @@ func outputPatrolScanHuman(rigName string, zombieResult *witness.DetectZombiePol
 		fmt.Println()
 	}
 
+	// Thrashing
+	if thrashResult != nil && (len(thrashResult.Thrashing) > 0 || patrolScanVerbose) {
+		fmt.Printf("%s Thrash Detection: checked %d polecat(s)\n",
+			style.Bold.Render("🔁"), thrashResult.Checked)
+
+		if len(thrashResult.Thrashing) == 0 {
+			fmt.Printf("  %s\n", style.Dim.Render("No thrashing detected"))
+		} else {
+			for _, th := range thrashResult.Thrashing {
+				fmt.Printf("  ⚠ %s: thrashing → %s\n", th.PolecatName, th.Action)
+				for _, f := range th.Findings {
+					fmt.Printf("    %s: %s\n", f.Kind, f.String())
+				}
+				if th.Error != nil {
+					fmt.Printf("    %s\n", style.Dim.Render(fmt.Sprintf("Error: %v", th.Error)))
+				}
+			}
+		}
+
+		if len(thrashResult.Errors) > 0 && patrolScanVerbose {
+			fmt.Printf("  Errors: %d\n", len(thrashResult.Errors))
+			for _, e := range thrashResult.Errors {
+				fmt.Printf("    - %v\n", e)
+			}
+		}
+		fmt.Println()
+	}
+
 	// Completions
 	if completionResult != nil && (len(completionResult.Discovered) > 0 || patrolScanVerbose) {
 		fmt.Printf("%s Completion Discovery: checked %d polecat(s)\n",
@@ -392,16 +467,20 @@ func outputPatrolScanHuman(rigName string, zombieResult *witness.DetectZombiePol
 	if stallResult != nil {
 		stallCount = len(stallResult.Stalled)
 	}
+	thrashCount := 0
+	if thrashResult != nil {
+		thrashCount = len(thrashResult.Thrashing)
+	}
 	completionCount := 0
 	if completionResult != nil {
 		completionCount = len(completionResult.Discovered)
 	}
 
-	if zombieCount == 0 && stallCount == 0 && completionCount == 0 {
+	if zombieCount == 0 && stallCount == 0 && thrashCount == 0 && completionCount == 0 {
 		fmt.Printf("%s All clear — no issues detected\n", style.Success.Render("✓"))
 	} else {
-		fmt.Printf("Summary: %d zombie(s) (%d active-work), %d stall(s), %d completion(s)\n",
-			zombieCount, activeCount, stallCount, completionCount)
+		fmt.Printf("Summary: %d zombie(s) (%d active-work), %d stall(s), %d thrashing, %d completion(s)\n",
+			zombieCount, activeCount, stallCount, thrashCount, completionCount)
 	}
 
 	return nil
@@ -2,12 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/session"
-	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -43,7 +43,8 @@ Examples:
   gt peek beads/crew/dave            # Crew: last 100 lines
   gt peek beads/crew/dave -n 200     # Crew: last 200 lines
   gt peek mayor                      # Mayor: last 100 lines
-  gt peek deacon -n 50               # Deacon: last 50 lines`,
+  gt peek deacon -n 50               # Deacon: last 50 lines
+  gt peek vm:gastown/furiosa         # Polecat on remote machine "vm" (see gt machine)`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: runPeek,
 }
@@ -61,6 +62,16 @@ func runPeek(cmd *cobra.Command, args []string) error {
 		lines = n
 	}
 
+	// machine:rig/name peeks at an agent in a remote town.
+	remote, address, err := resolveMachineAddress(address)
+	if err != nil {
+		return err
+	}
+	if remote != nil {
+		defer remote.Close()
+		return remote.run(os.Stdout, "peek", address, "-n", strconv.Itoa(lines))
+	}
+
 	// Handle town-level agents: mayor, deacon, boot
 	// These use session names like "hq-mayor", "hq-deacon" but have no rig.
 	townAgentSessions := map[string]string{
@@ -72,12 +83,11 @@ func runPeek(cmd *cobra.Command, args []string) error {
 		"hq/boot":   "hq-boot",
 	}
 	if sessionName, ok := townAgentSessions[address]; ok {
-		_, err := workspace.FindFromCwdOrError()
+		townRoot, err := workspace.FindFromCwdOrError()
 		if err != nil {
 			return fmt.Errorf("not in a Gas Town workspace: %w", err)
 		}
-		t := tmux.NewTmux()
-		output, err := t.CapturePane(sessionName, lines)
+		output, err := session.NewBackend(townRoot).CapturePane(sessionName, lines)
 		if err != nil {
 			return fmt.Errorf("capturing %s: %w", address, err)
 		}
@@ -41,13 +41,30 @@ PLUGIN LOCATIONS:
   ~/gt/plugins/           Town-level plugins (universal, apply everywhere)
   <rig>/plugins/          Rig-level plugins (project-specific)
 
+Plugins can be installed from git and pinned with 'gt plugin install'.
+
 GATE TYPES:
   cooldown    Run if enough time has passed (e.g., 1h)
   cron        Run on a schedule (e.g., "0 9 * * *")
   condition   Run if a check command returns exit 0
-  event       Run on events (e.g., startup)
+  event       Run when a Gas Town event fires (e.g., startup, done, convoy_closed)
   manual      Never auto-run, trigger explicitly
 
+EVENT GATES:
+  The daemon dispatches event-gated plugins to dogs as matching events are
+  published on its event bus. run.sh receives the triggering event as JSON
+  on stdin and in the file named by $GT_PLUGIN_EVENT.
+
+    [gate]
+    type = "event"
+    on = ["merged", "convoy_closed"]   # event types or bus topics (channel.refinery)
+    debounce = "30s"                   # coalesce bursts; run once things are quiet
+    max_wait = "5m"                    # but no later than this after the first event
+    max_concurrent = 1                 # dogs that may run this plugin at once
+
+    [gate.match]                       # payload filters (globs)
+    rig = "gastown"
+
 Examples:
   gt plugin list                    # List all discovered plugins
   gt plugin show <name>             # Show plugin details
@@ -299,7 +316,7 @@ func printPluginSummary(p *plugin.Plugin) {
 func runPluginShow(cmd *cobra.Command, args []string) error {
 	name := args[0]
 
-	scanner, _, err := getPluginScanner()
+	scanner, townRoot, err := getPluginScanner()
 	if err != nil {
 		return err
 	}
@@ -313,7 +330,7 @@ func runPluginShow(cmd *cobra.Command, args []string) error {
 		return outputPluginShowJSON(p)
 	}
 
-	return outputPluginShowText(p)
+	return outputPluginShowText(p, townRoot)
 }
 
 func outputPluginShowJSON(p *plugin.Plugin) error {
@@ -322,7 +339,7 @@ func outputPluginShowJSON(p *plugin.Plugin) error {
 	return enc.Encode(p)
 }
 
-func outputPluginShowText(p *plugin.Plugin) error {
+func outputPluginShowText(p *plugin.Plugin, townRoot string) error {
 	fmt.Printf("%s %s\n", style.Bold.Render("Plugin:"), p.Name)
 	fmt.Printf("%s %s\n", style.Bold.Render("Path:"), p.Path)
 
@@ -353,8 +370,27 @@ func outputPluginShowText(p *plugin.Plugin) error {
 		if p.Gate.Check != "" {
 			fmt.Printf("  Check: %s\n", p.Gate.Check)
 		}
-		if p.Gate.On != "" {
-			fmt.Printf("  On: %s\n", p.Gate.On)
+		if len(p.Gate.On) > 0 {
+			fmt.Printf("  On: %s\n", strings.Join(p.Gate.On, ", "))
+		}
+		if len(p.Gate.Match) > 0 {
+			keys := make([]string, 0, len(p.Gate.Match))
+			for k := range p.Gate.Match {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				fmt.Printf("  Match: %s = %s\n", k, p.Gate.Match[k])
+			}
+		}
+		if p.Gate.Debounce != "" {
+			fmt.Printf("  Debounce: %s\n", p.Gate.Debounce)
+		}
+		if p.Gate.MaxWait != "" {
+			fmt.Printf("  Max wait: %s\n", p.Gate.MaxWait)
+		}
+		if p.Gate.MaxConcurrent > 0 {
+			fmt.Printf("  Max concurrent: %d\n", p.Gate.MaxConcurrent)
 		}
 	} else {
 		fmt.Printf("  Type: manual (no gate section)\n")
@@ -389,6 +425,41 @@ func outputPluginShowText(p *plugin.Plugin) error {
 		}
 	}
 
+	// Permissions
+	fmt.Println()
+	fmt.Printf("%s\n", style.Bold.Render("Permissions:"))
+	if p.Permissions == nil {
+		fmt.Printf("  %s\n", style.Dim.Render("none declared"))
+	} else {
+		rigs, commands := "none declared", "none declared"
+		if len(p.Permissions.Rigs) > 0 {
+			rigs = strings.Join(p.Permissions.Rigs, ", ")
+		}
+		if len(p.Permissions.Commands) > 0 {
+			commands = strings.Join(p.Permissions.Commands, ", ")
+		}
+		fmt.Printf("  Rigs: %s\n", rigs)
+		fmt.Printf("  Commands: %s\n", commands)
+	}
+
+	// Pin from plugins.lock
+	if lock, err := plugin.LoadLock(townRoot); err == nil {
+		if e := lock.Get(p.RigName, p.Name); e != nil {
+			fmt.Println()
+			fmt.Printf("%s\n", style.Bold.Render("Installed from:"))
+			fmt.Printf("  Source: %s\n", e.Source)
+			if e.Ref != "" {
+				fmt.Printf("  Ref: %s\n", e.Ref)
+			}
+			fmt.Printf("  Commit: %s\n", e.Commit)
+			if err := plugin.Verify(townRoot, p); err != nil {
+				fmt.Printf("  Hash: %s\n", style.Error.Render("modified since install (will not be dispatched)"))
+			} else {
+				fmt.Printf("  Hash: %s\n", style.Success.Render("verified"))
+			}
+		}
+	}
+
 	// Instructions preview
 	if p.Instructions != "" {
 		fmt.Println()
@@ -527,6 +598,7 @@ func runPluginSync(cmd *cobra.Command, args []string) error {
 
 		if !report.HasDrift() && len(report.Extra) == 0 {
 			fmt.Printf("  %s All plugins up to date\n", style.Success.Render("✓"))
+			printPinnedSkipped(report.Pinned)
 			return nil
 		}
 
@@ -541,6 +613,7 @@ func runPluginSync(cmd *cobra.Command, args []string) error {
 				fmt.Printf("  %s %s (would be removed)\n", style.Error.Render("-"), name)
 			}
 		}
+		printPinnedSkipped(report.Pinned)
 		return nil
 	}
 
@@ -552,6 +625,7 @@ func runPluginSync(cmd *cobra.Command, args []string) error {
 	if len(result.Copied) == 0 && len(result.Removed) == 0 {
 		fmt.Printf("%s Plugins already up to date (%d checked)\n",
 			style.Success.Render("✓"), len(result.Skipped))
+		printPinnedSkipped(result.Pinned)
 		return nil
 	}
 
@@ -566,6 +640,7 @@ func runPluginSync(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  %s %d plugin(s) already current\n",
 			style.Dim.Render("·"), len(result.Skipped))
 	}
+	printPinnedSkipped(result.Pinned)
 	for _, e := range result.Errors {
 		fmt.Fprintf(os.Stderr, "  %s %s\n", style.Error.Render("!"), e)
 	}
@@ -573,6 +648,15 @@ func runPluginSync(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// printPinnedSkipped notes plugins sync left alone because plugins.lock
+// pins them to a git source.
+func printPinnedSkipped(pinned []string) {
+	if len(pinned) > 0 {
+		fmt.Printf("  %s %s pinned in %s, not synced (use gt plugin update)\n",
+			style.Dim.Render("·"), strings.Join(pinned, ", "), plugin.LockFileName)
+	}
+}
+
 func runPluginHistory(cmd *cobra.Command, args []string) error {
 	name := args[0]
 
@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/plugin"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	pluginInstallRig   string
+	pluginInstallPath  string
+	pluginOutdatedJSON bool
+)
+
+var pluginInstallCmd = &cobra.Command{
+	Use:   "install <git-url>[@ref]",
+	Short: "Install plugins from a git repository",
+	Long: `Install plugins from a git repository and pin them in plugins.lock.
+
+The ref may be a branch, tag or commit; without one the default branch is
+used. If the repository (or --path within it) holds a plugin.md, that plugin
+is installed; otherwise every plugin directory directly under it is.
+
+plugins.lock (~/gt/plugins/plugins.lock) records the source, ref, resolved
+commit and a content hash for each installed plugin. The daemon and
+'gt dog dispatch' verify the hash before every dispatch and refuse plugins
+that were edited in place. Installing over an existing pin re-pins it.
+
+Review the permissions each plugin declares before it runs.
+
+Examples:
+  gt plugin install https://github.com/acme/gt-plugins.git
+  gt plugin install https://github.com/acme/gt-plugins.git@v1.2.0
+  gt plugin install git@github.com:acme/gt-plugins.git@main --path plugins/notify
+  gt plugin install /srv/git/plugins.git --rig gastown`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPluginInstall,
+}
+
+var pluginUpdateCmd = &cobra.Command{
+	Use:   "update [name...]",
+	Short: "Update plugins installed from git",
+	Long: `Re-resolve the ref of installed plugins and reinstall any whose commit
+moved. A plugin whose installed copy was modified is restored from its
+pinned source. With no names, updates every plugin in plugins.lock.
+
+Plugins pinned to a commit only change when restored.
+
+Examples:
+  gt plugin update              # Update everything in plugins.lock
+  gt plugin update notify       # Update one plugin`,
+	RunE: runPluginUpdate,
+}
+
+var pluginOutdatedCmd = &cobra.Command{
+	Use:   "outdated",
+	Short: "List installed plugins with newer commits or local changes",
+	Long: `Check every plugin in plugins.lock against its source without cloning.
+
+A plugin is outdated when its branch or tag now resolves to a different
+commit, and modified when its installed files no longer match the pinned
+hash (modified plugins are not dispatched). Run 'gt plugin update' to fix
+either.
+
+Examples:
+  gt plugin outdated
+  gt plugin outdated --json`,
+	RunE: runPluginOutdated,
+}
+
+func init() {
+	pluginInstallCmd.Flags().StringVar(&pluginInstallRig, "rig", "", "Install into <rig>/plugins/ instead of the town plugins directory")
+	pluginInstallCmd.Flags().StringVar(&pluginInstallPath, "path", "", "Directory within the repository to install from")
+
+	pluginOutdatedCmd.Flags().BoolVar(&pluginOutdatedJSON, "json", false, "Output as JSON")
+
+	pluginCmd.AddCommand(pluginInstallCmd)
+	pluginCmd.AddCommand(pluginUpdateCmd)
+	pluginCmd.AddCommand(pluginOutdatedCmd)
+}
+
+func runPluginInstall(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	source, ref := plugin.ParseSource(args[0])
+	installed, err := plugin.Install(townRoot, source, ref, plugin.InstallOptions{
+		Rig:    pluginInstallRig,
+		Subdir: pluginInstallPath,
+	})
+	for _, inst := range installed {
+		printInstalled(inst)
+	}
+	return err
+}
+
+func runPluginUpdate(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	entries, err := selectLockEntries(townRoot, args)
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	for _, e := range entries {
+		inst, err := plugin.Update(townRoot, e)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  %s %s: %v\n", style.Error.Render("!"), e.Name, err)
+			failed++
+			continue
+		}
+		if inst == nil {
+			fmt.Printf("  %s %s %s\n", style.Dim.Render("·"), e.Name, style.Dim.Render("up to date @ "+shortHash(e.Commit)))
+			continue
+		}
+		printInstalled(inst)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d plugin(s) failed to update", failed)
+	}
+	return nil
+}
+
+func runPluginOutdated(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	entries, err := selectLockEntries(townRoot, nil)
+	if err != nil {
+		return err
+	}
+
+	type outdatedJSON struct {
+		Name     string `json:"name"`
+		Rig      string `json:"rig,omitempty"`
+		Source   string `json:"source"`
+		Ref      string `json:"ref,omitempty"`
+		Commit   string `json:"commit"`
+		Latest   string `json:"latest"`
+		Modified bool   `json:"modified"`
+	}
+	results := []outdatedJSON{}
+	var failed int
+	for _, e := range entries {
+		o, err := plugin.CheckOutdated(townRoot, e)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  %s %s: %v\n", style.Error.Render("!"), e.Name, err)
+			failed++
+			continue
+		}
+		if o == nil {
+			continue
+		}
+		results = append(results, outdatedJSON{
+			Name: e.Name, Rig: e.Rig, Source: e.Source, Ref: e.Ref,
+			Commit: e.Commit, Latest: o.Latest, Modified: o.Modified,
+		})
+	}
+
+	if pluginOutdatedJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	} else if len(results) == 0 && failed == 0 {
+		fmt.Printf("%s All %d installed plugin(s) up to date\n", style.Success.Render("✓"), len(entries))
+	} else {
+		for _, r := range results {
+			var notes []string
+			if r.Latest != r.Commit {
+				notes = append(notes, fmt.Sprintf("%s → %s", shortHash(r.Commit), shortHash(r.Latest)))
+			}
+			if r.Modified {
+				notes = append(notes, style.Warning.Render("modified locally"))
+			}
+			fmt.Printf("  %s %s %s\n", style.Warning.Render("~"), style.Bold.Render(r.Name), strings.Join(notes, ", "))
+		}
+		if len(results) > 0 {
+			fmt.Printf("\n  Run %s to update.\n", style.Bold.Render("gt plugin update"))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("could not check %d plugin(s)", failed)
+	}
+	return nil
+}
+
+// selectLockEntries returns the plugins.lock entries named in names, or all
+// of them when names is empty.
+func selectLockEntries(townRoot string, names []string) ([]*plugin.LockEntry, error) {
+	lock, err := plugin.LoadLock(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	entries := lock.Entries()
+	if len(names) == 0 {
+		if len(entries) == 0 {
+			fmt.Printf("%s No plugins installed from git (see 'gt plugin install')\n", style.Dim.Render("○"))
+		}
+		return entries, nil
+	}
+
+	var selected []*plugin.LockEntry
+	for _, name := range names {
+		var found bool
+		for _, e := range entries {
+			if e.Name == name {
+				selected = append(selected, e)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("plugin %q is not in %s", name, plugin.LockFileName)
+		}
+	}
+	return selected, nil
+}
+
+func printInstalled(inst *plugin.Installed) {
+	e := inst.Entry
+	verb := "Installed"
+	if inst.Previous != nil {
+		verb = "Updated"
+	}
+	at := shortHash(e.Commit)
+	if e.Ref != "" {
+		at = e.Ref + " (" + at + ")"
+	}
+	fmt.Printf("%s %s %s @ %s\n", style.Success.Render("✓"), verb, style.Bold.Render(e.Name), at)
+	if inst.Previous != nil && inst.Previous.Commit != e.Commit {
+		fmt.Printf("  %s\n", style.Dim.Render(fmt.Sprintf("was %s", shortHash(inst.Previous.Commit))))
+	}
+	fmt.Printf("  Path: %s\n", inst.Plugin.Path)
+	fmt.Printf("  Permissions: %s\n", inst.Plugin.Permissions.Describe())
+}
@@ -103,7 +103,7 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 	// path. For configurable capacity gating, use scheduler.max_polecats in town settings
 	// (see internal/scheduler/capacity/).
 	const defaultMaxActivePolecats = 25
-	activeCount := countActivePolecats()
+	activeCount := countActivePolecats(townRoot)
 	if activeCount >= defaultMaxActivePolecats {
 		return nil, fmt.Errorf("polecat cap reached: %d active polecats (max %d). "+
 			"This is a safety limit to prevent spawn storms. "+
@@ -232,8 +232,12 @@ func emitSessionEvent(ctx RoleContext) {
 		topic = "patrol"
 	}
 
-	// Emit the event
+	// Emit the event. The agent tells gt seance search how to read the
+	// session's transcript.
 	payload := events.SessionPayload(sessionID, actor, topic, ctx.WorkDir)
+	if agent := os.Getenv("GT_AGENT"); agent != "" {
+		payload["agent"] = agent
+	}
 	_ = events.LogFeed(events.TypeSessionStart, actor, payload)
 }
 
@@ -3,16 +3,19 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/scheduler/capacity"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
-	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -40,7 +43,17 @@ Subcommands:
 
 Config:
   gt config set scheduler.max_polecats 5    # Enable deferred dispatch
-  gt config set scheduler.max_polecats -1   # Direct dispatch (default)`,
+  gt config set scheduler.max_polecats -1   # Direct dispatch (default)
+
+Fair share:
+  Free slots are shared between rigs by weight. Within a rig, beads run in
+  priority order; a bead's priority rises one level per aging_interval it
+  waits, so low-priority work eventually runs.
+
+  gt config set scheduler.rigs.gastown.weight 3        # 3x the default share
+  gt config set scheduler.rigs.gastown.min_polecats 1  # Keep a slot free for gastown
+  gt config set scheduler.rigs.beads.max_polecats 2    # Never more than 2
+  gt config set scheduler.aging_interval 30m           # Default: 1h`,
 	RunE: requireSubcommand,
 }
 
@@ -142,23 +155,45 @@ func runSchedulerStatus(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("listing scheduled beads: %w", err)
 	}
 
-	activePolecats := countActivePolecats()
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil {
+		return fmt.Errorf("loading town settings: %w", err)
+	}
+	schedulerCfg := settings.Scheduler
+	if schedulerCfg == nil {
+		schedulerCfg = capacity.DefaultSchedulerConfig()
+	}
+
+	activeByRig := countActivePolecatsByRig(townRoot)
+	activePolecats := 0
+	for _, n := range activeByRig {
+		activePolecats += n
+	}
+	queuedByRig := make(map[string]int)
+	for _, b := range scheduled {
+		queuedByRig[b.TargetRig]++
+	}
+	rigs := capacity.RigUsages(schedulerCfg, activeByRig, queuedByRig)
 
 	if schedulerStatusJSON {
 		out := struct {
-			Paused         bool               `json:"paused"`
-			PausedBy       string             `json:"paused_by,omitempty"`
-			ScheduledTotal int                `json:"queued_total"`
-			ScheduledReady int                `json:"queued_ready"`
-			ActivePolecats int                `json:"active_polecats"`
-			LastDispatchAt string             `json:"last_dispatch_at,omitempty"`
+			Paused         bool                `json:"paused"`
+			PausedBy       string              `json:"paused_by,omitempty"`
+			ScheduledTotal int                 `json:"queued_total"`
+			ScheduledReady int                 `json:"queued_ready"`
+			ActivePolecats int                 `json:"active_polecats"`
+			MaxPolecats    int                 `json:"max_polecats"`
+			LastDispatchAt string              `json:"last_dispatch_at,omitempty"`
+			Rigs           []capacity.RigUsage `json:"rigs,omitempty"`
 			Beads          []scheduledBeadInfo `json:"beads"`
 		}{
 			Paused:         state.Paused,
 			PausedBy:       state.PausedBy,
 			ScheduledTotal: len(scheduled),
 			ActivePolecats: activePolecats,
+			MaxPolecats:    schedulerCfg.GetMaxPolecats(),
 			LastDispatchAt: state.LastDispatchAt,
+			Rigs:           rigs,
 			Beads:          scheduled,
 		}
 		for _, b := range scheduled {
@@ -190,9 +225,34 @@ func runSchedulerStatus(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  Last dispatch: %s (%d beads)\n", state.LastDispatchAt, state.LastDispatchCount)
 	}
 
+	if schedulerCfg.IsDeferred() && len(rigs) > 0 {
+		fmt.Printf("\n%s (max %d polecats)\n", style.Bold.Render("Rig Shares"), schedulerCfg.GetMaxPolecats())
+		printRigUsages(os.Stdout, rigs)
+	}
+
 	return nil
 }
 
+// printRigUsages writes one line per rig: its weight, min/max, fair share
+// of max_polecats, and running and queued polecats.
+func printRigUsages(w io.Writer, rigs []capacity.RigUsage) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  RIG\tWEIGHT\tMIN\tMAX\tSHARE\tACTIVE\tQUEUED")
+	for _, u := range rigs {
+		maxStr := "-"
+		if u.MaxPolecats > 0 {
+			maxStr = strconv.Itoa(u.MaxPolecats)
+		}
+		active := strconv.Itoa(u.Active)
+		if u.Active > 0 && float64(u.Active) > u.Share {
+			active += " (over)"
+		}
+		fmt.Fprintf(tw, "  %s\t%d\t%d\t%s\t%.1f\t%s\t%d\n",
+			u.Rig, u.Weight, u.MinPolecats, maxStr, u.Share, active, u.Queued)
+	}
+	_ = tw.Flush()
+}
+
 func runSchedulerList(cmd *cobra.Command, args []string) error {
 	townRoot, err := workspace.FindFromCwdOrError()
 	if err != nil {
@@ -482,25 +542,34 @@ func beadsSearchDirs(townRoot string) []string {
 }
 
 // countActivePolecats counts all running polecats across all rigs in the town.
-func countActivePolecats() int {
-	listCmd := tmux.BuildCommand("list-sessions", "-F", "#{session_name}")
-	out, err := listCmd.Output()
+func countActivePolecats(townRoot string) int {
+	count := 0
+	for _, n := range countActivePolecatsByRig(townRoot) {
+		count += n
+	}
+	return count
+}
+
+// countActivePolecatsByRig counts running polecats per rig, from the
+// sessions of the town's configured session backend.
+func countActivePolecatsByRig(townRoot string) map[string]int {
+	counts := make(map[string]int)
+	sessions, err := session.NewBackend(townRoot).ListSessions()
 	if err != nil {
-		return 0
+		return counts
 	}
 
-	count := 0
-	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
-		if line == "" {
+	for _, name := range sessions {
+		if name == "" {
 			continue
 		}
-		identity, err := session.ParseSessionName(line)
+		identity, err := session.ParseSessionName(name)
 		if err != nil {
 			continue
 		}
 		if identity.Role == session.RolePolecat {
-			count++
+			counts[identity.Rig]++
 		}
 	}
-	return count
+	return counts
 }
@@ -50,6 +50,10 @@ DISCOVERY:
   gt seance --rig gastown       # Filter by rig
   gt seance --recent 10         # Last N sessions
 
+SEARCH (find the session that did the work):
+  gt seance search "auth middleware" --since 7d
+  gt seance search "auth middleware" --talk 1   # Talk to the best hit
+
 THE SEANCE (talk to predecessor):
   gt seance --talk <session-id>              # Interactive conversation
   gt seance --talk <id> -p "Where is X?"     # One-shot question
@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/agentlog"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/costs"
+	"github.com/steveyegge/gastown/internal/seance"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	seanceSearchRig      string
+	seanceSearchRole     string
+	seanceSearchBead     string
+	seanceSearchAgent    string
+	seanceSearchSince    string
+	seanceSearchLimit    int
+	seanceSearchTalk     int
+	seanceSearchPrompt   string
+	seanceSearchJSON     bool
+	seanceSearchNoUpdate bool
+)
+
+var seanceSearchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search predecessor session transcripts",
+	Long: `Full-text search across the transcripts of predecessor sessions.
+
+Every term must appear in a transcript message (case-insensitive); quote a
+phrase to match it exactly. Results show the best-matching message of each
+session, best first, with the bead the agent had hooked at the time.
+
+The index lives in <town>/.runtime/seance-index/ and is brought up to date
+before each search: only transcript lines written since the last search are
+read. Sessions are found from session_start events, like gt seance. Claude
+Code, Codex and Copilot transcripts are indexed; agents without a
+line-oriented log (OpenCode, Gemini) are skipped. Messages older than 90
+days are dropped from the index.
+
+Examples:
+  gt seance search "auth middleware"
+  gt seance search auth middleware --rig gastown --role polecat --since 7d
+  gt seance search "rate limit" --bead gt-abc12
+  gt seance search "auth middleware" --talk 1             # Talk to the best hit
+  gt seance search flaky test -t 2 -p "Which test was it?"`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSeanceSearch,
+}
+
+func init() {
+	seanceSearchCmd.Flags().StringVar(&seanceSearchRig, "rig", "", "Only sessions in this rig")
+	seanceSearchCmd.Flags().StringVar(&seanceSearchRole, "role", "", "Only sessions with this role (polecat, crew, witness, ...)")
+	seanceSearchCmd.Flags().StringVar(&seanceSearchBead, "bead", "", "Only messages written while this bead was hooked")
+	seanceSearchCmd.Flags().StringVar(&seanceSearchAgent, "agent", "", "Only agents whose address contains this (e.g. toast)")
+	seanceSearchCmd.Flags().StringVar(&seanceSearchSince, "since", "", "Only messages since a duration ago (7d, 48h) or date (YYYY-MM-DD)")
+	seanceSearchCmd.Flags().IntVarP(&seanceSearchLimit, "limit", "n", 10, "Maximum sessions to show")
+	seanceSearchCmd.Flags().IntVarP(&seanceSearchTalk, "talk", "t", 0, "Talk to hit N (1 = best match) instead of listing")
+	seanceSearchCmd.Flags().StringVarP(&seanceSearchPrompt, "prompt", "p", "", "One-shot prompt (with --talk)")
+	seanceSearchCmd.Flags().BoolVar(&seanceSearchJSON, "json", false, "Output as JSON")
+	seanceSearchCmd.Flags().BoolVar(&seanceSearchNoUpdate, "no-update", false, "Search the index as is, without indexing new transcript lines")
+
+	seanceCmd.AddCommand(seanceSearchCmd)
+}
+
+func runSeanceSearch(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		return fmt.Errorf("not in a Gas Town workspace")
+	}
+
+	q := seance.Query{
+		Terms: seance.ParseTerms(strings.Join(args, " ")),
+		Rig:   seanceSearchRig,
+		Role:  seanceSearchRole,
+		Bead:  seanceSearchBead,
+		Agent: seanceSearchAgent,
+		Limit: seanceSearchLimit,
+	}
+	if len(q.Terms) == 0 {
+		return fmt.Errorf("empty query")
+	}
+	if seanceSearchSince != "" {
+		if q.Since, err = parseSeanceSince(seanceSearchSince, time.Now()); err != nil {
+			return err
+		}
+	}
+	if seanceSearchTalk > 0 && q.Limit > 0 && seanceSearchTalk > q.Limit {
+		q.Limit = seanceSearchTalk
+	}
+
+	ix := seance.Open(townRoot)
+	if !seanceSearchNoUpdate {
+		if err := updateSeanceIndex(townRoot, ix); err != nil {
+			return err
+		}
+	}
+
+	hits, err := ix.Search(q)
+	if err != nil {
+		return err
+	}
+
+	if seanceSearchTalk > 0 {
+		if seanceSearchTalk > len(hits) {
+			return fmt.Errorf("no hit %d: the search found %d session(s)", seanceSearchTalk, len(hits))
+		}
+		return runSeanceTalk(hits[seanceSearchTalk-1].SessionID, seanceSearchPrompt)
+	}
+
+	if seanceSearchJSON {
+		return outputJSON(hits)
+	}
+	printSeanceHits(hits)
+	return nil
+}
+
+// updateSeanceIndex indexes new lines of every discoverable session's
+// transcript, keyed by the bead its agent had hooked at the time.
+func updateSeanceIndex(townRoot string, ix *seance.Index) error {
+	sessions, err := discoverSessions(townRoot)
+	if err != nil {
+		return fmt.Errorf("discovering sessions: %w", err)
+	}
+
+	configDirs := claudeConfigDirs(townRoot)
+	seen := make(map[string]bool)
+	var transcripts []seance.Transcript
+	for _, s := range sessions {
+		id := getPayloadString(s.Payload, "session_id")
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		agent := s.Actor
+		if agent == "" {
+			agent = getPayloadString(s.Payload, "role")
+		}
+		role, rig, _ := parseRoleString(agent)
+		format := sessionLogFormat(townRoot, getPayloadString(s.Payload, "agent"), string(role), rig)
+		if !agentlog.ReadsIncrementally(format) {
+			continue // e.g. OpenCode and Gemini keep no line-oriented log
+		}
+		path := findSessionTranscript(configDirs, format, id, getPayloadString(s.Payload, "cwd"), parseEventTime(s.Timestamp))
+		if path == "" {
+			continue
+		}
+		transcripts = append(transcripts, seance.Transcript{
+			SessionID: id,
+			Agent:     agent,
+			Rig:       rig,
+			Role:      string(role),
+			Format:    format,
+			Path:      path,
+		})
+	}
+
+	var beadAt func(string, time.Time) string
+	if history, err := costs.LoadHistory(townRoot); err == nil {
+		beadAt = history.BeadAt
+	}
+	if _, err := ix.Update(transcripts, beadAt); err != nil {
+		return fmt.Errorf("updating seance index: %w", err)
+	}
+	return nil
+}
+
+// claudeConfigDirs returns the Claude config directories a session may have
+// run under: the current one and every account's.
+func claudeConfigDirs(townRoot string) []string {
+	var dirs []string
+	if dir, err := config.ClaudeConfigDir(); err == nil {
+		dirs = append(dirs, dir)
+	}
+	if cfg, err := config.LoadAccountsConfig(constants.MayorAccountsPath(townRoot)); err == nil {
+		home, _ := os.UserHomeDir()
+		for _, acct := range cfg.Accounts {
+			dir := acct.ConfigDir
+			if dir == "" {
+				continue
+			}
+			if strings.HasPrefix(dir, "~/") {
+				dir = filepath.Join(home, dir[2:])
+			}
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// sessionLogFormat returns the agentlog format of a session's transcript:
+// that of the agent recorded in its session_start event, or else of the
+// agent its role is configured to run.
+func sessionLogFormat(townRoot, agentName, role, rig string) string {
+	if agentName == "" {
+		rigPath := ""
+		if rig != "" {
+			rigPath = filepath.Join(townRoot, rig)
+		}
+		agentName, _ = config.ResolveRoleAgentName(role, townRoot, rigPath)
+	}
+	return config.GetLogFormat(agentName)
+}
+
+// findSessionTranscript returns the transcript of a session started in cwd
+// at started, or "" if it cannot be found. Claude Code transcripts are
+// looked up in each of configDirs; other agents' in their own log stores.
+func findSessionTranscript(configDirs []string, format, sessionID, cwd string, started time.Time) string {
+	if cwd == "" {
+		return ""
+	}
+	if format != "claudecode" {
+		path, _, _ := agentlog.FindSessionLog(format, sessionID, cwd, started)
+		return path
+	}
+	projectName := strings.ReplaceAll(cwd, "/", "-")
+	for _, dir := range configDirs {
+		path := filepath.Join(dir, "projects", projectName, sessionID+".jsonl")
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// parseEventTime parses an events-log timestamp, or returns the zero time.
+func parseEventTime(ts string) time.Time {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// parseSeanceSince parses --since as a duration before now (7d, 48h) or a
+// local date (YYYY-MM-DD).
+func parseSeanceSince(s string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	d, err := parseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since %q: use a duration (7d, 48h) or YYYY-MM-DD", s)
+	}
+	return now.Add(-d), nil
+}
+
+func printSeanceHits(hits []seance.Hit) {
+	if len(hits) == 0 {
+		fmt.Println("No matching sessions.")
+		fmt.Println(style.Dim.Render("Only Claude Code, Codex and Copilot sessions discovered from session_start events are indexed."))
+		return
+	}
+
+	for i, h := range hits {
+		id := h.SessionID
+		if len(id) > 12 {
+			id = id[:11] + "…"
+		}
+		who := h.Agent
+		if who == "" {
+			who = "-"
+		}
+		meta := h.Time.Local().Format("2006-01-02 15:04")
+		if h.Bead != "" {
+			meta += "  " + h.Bead
+		}
+		matches := "1 match"
+		if h.Matches != 1 {
+			matches = fmt.Sprintf("%d matches", h.Matches)
+		}
+		fmt.Printf("%s %s  %s  %s  %s\n",
+			style.Bold.Render(fmt.Sprintf("%2d.", i+1)), id, who, meta, style.Dim.Render(matches))
+		fmt.Printf("    %s %s\n", style.Dim.Render("["+h.Kind+"]"), h.Snippet)
+	}
+
+	fmt.Printf("\n%s\n", style.Bold.Render("Talk to a hit:"))
+	fmt.Printf("  gt seance search <query> --talk <N>\n")
+	fmt.Printf("  gt seance --talk <session-id>\n")
+}
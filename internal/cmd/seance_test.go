@@ -8,6 +8,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/events"
@@ -502,3 +503,78 @@ func TestResolveSessionPrefix(t *testing.T) {
 		}
 	})
 }
+
+func TestFindSessionTranscript(t *testing.T) {
+	current := t.TempDir()
+	account := t.TempDir()
+	cwd := "/home/user/gt/gastown/polecats/toast"
+	projectDir := filepath.Join(account, "projects", strings.ReplaceAll(cwd, "/", "-"))
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(projectDir, "sess-1.jsonl")
+	if err := os.WriteFile(want, []byte("{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dirs := []string{current, account}
+	if got := findSessionTranscript(dirs, "claudecode", "sess-1", cwd, time.Time{}); got != want {
+		t.Errorf("findSessionTranscript = %q, want %q", got, want)
+	}
+	if got := findSessionTranscript(dirs, "claudecode", "sess-2", cwd, time.Time{}); got != "" {
+		t.Errorf("missing session: got %q, want empty", got)
+	}
+	if got := findSessionTranscript(dirs, "claudecode", "sess-1", "", time.Time{}); got != "" {
+		t.Errorf("no cwd: got %q, want empty", got)
+	}
+
+	// Codex rollouts are found in CODEX_HOME by the session's start time.
+	codexHome := t.TempDir()
+	t.Setenv("CODEX_HOME", codexHome)
+	dayDir := filepath.Join(codexHome, "sessions", "2026", "03", "01")
+	if err := os.MkdirAll(dayDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	rollout := filepath.Join(dayDir, "rollout-2026-03-01T12-00-00-0b0e6a4c-1111-2222-3333-444455556666.jsonl")
+	header := `{"timestamp":"2026-03-01T12:00:00Z","type":"session_meta","payload":{"id":"0b0e6a4c-1111-2222-3333-444455556666","cwd":"` + cwd + `"}}` + "\n"
+	if err := os.WriteFile(rollout, []byte(header), 0644); err != nil {
+		t.Fatal(err)
+	}
+	started := time.Date(2026, 3, 1, 12, 0, 5, 0, time.UTC)
+	if got := findSessionTranscript(dirs, "codex", "mayor-1234", cwd, started); got != rollout {
+		t.Errorf("codex transcript = %q, want %q", got, rollout)
+	}
+}
+
+func TestSessionLogFormat(t *testing.T) {
+	townRoot := t.TempDir()
+	tests := []struct {
+		agent, role, want string
+	}{
+		{"codex", "polecat", "codex"},
+		{"copilot", "crew", "copilot"},
+		{"opencode", "polecat", ""},
+		{"", "mayor", "claudecode"}, // default agent
+	}
+	for _, tt := range tests {
+		if got := sessionLogFormat(townRoot, tt.agent, tt.role, ""); got != tt.want {
+			t.Errorf("sessionLogFormat(%q, %q) = %q, want %q", tt.agent, tt.role, got, tt.want)
+		}
+	}
+}
+
+func TestParseSeanceSince(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	got, err := parseSeanceSince("7d", now)
+	if err != nil || !got.Equal(now.Add(-7*24*time.Hour)) {
+		t.Errorf("7d = %v, %v", got, err)
+	}
+	got, err = parseSeanceSince("2026-03-01", now)
+	if err != nil || got.Year() != 2026 || got.Month() != 3 || got.Day() != 1 {
+		t.Errorf("date = %v, %v", got, err)
+	}
+	if _, err := parseSeanceSince("last week", now); err == nil {
+		t.Error("expected error for invalid --since")
+	}
+}
@@ -31,6 +31,7 @@ var (
 	sessionFile       string
 	sessionRigFilter  string
 	sessionListJSON   bool
+	sessionMachine    string
 	sessionStatusJSON bool
 )
 
@@ -91,7 +92,8 @@ var sessionListCmd = &cobra.Command{
 	Short: "List all sessions",
 	Long: `List all running polecat sessions.
 
-Shows session status, rig, and polecat name. Use --rig to filter by rig.`,
+Shows session status, rig, and polecat name. Use --rig to filter by rig.
+Use --machine (or --rig machine:rig) to list a remote town's sessions.`,
 	RunE: runSessionList,
 }
 
@@ -178,6 +180,7 @@ func init() {
 	// List flags
 	sessionListCmd.Flags().StringVar(&sessionRigFilter, "rig", "", "Filter by rig name")
 	sessionListCmd.Flags().BoolVar(&sessionListJSON, "json", false, "Output as JSON")
+	sessionListCmd.Flags().StringVar(&sessionMachine, "machine", "", "List sessions on a remote machine (see gt machine)")
 
 	// Capture flags
 	sessionCaptureCmd.Flags().IntVarP(&sessionLines, "lines", "n", 100, "Number of lines to capture")
@@ -342,6 +345,7 @@ func runSessionAttach(cmd *cobra.Command, args []string) error {
 
 // SessionListItem represents a session in list output.
 type SessionListItem struct {
+	Machine   string `json:"machine,omitempty"`
 	Rig       string `json:"rig"`
 	Polecat   string `json:"polecat"`
 	SessionID string `json:"session_id"`
@@ -349,6 +353,16 @@ type SessionListItem struct {
 }
 
 func runSessionList(cmd *cobra.Command, args []string) error {
+	// A machine (--machine vm, or --rig vm:gastown) lists a remote town.
+	machineName, rigFilter := sessionMachine, sessionRigFilter
+	if name, rest, ok := strings.Cut(rigFilter, ":"); ok {
+		machineName, rigFilter = name, rest
+	}
+	if machineName != "" && machineName != "local" {
+		return runRemoteSessionList(machineName, rigFilter)
+	}
+	sessionRigFilter = rigFilter
+
 	// Find town root
 	townRoot, err := workspace.FindFromCwdOrError()
 	if err != nil {
@@ -402,7 +416,39 @@ func runSessionList(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Output
+	return printSessionList(allSessions)
+}
+
+// runRemoteSessionList lists sessions in the town on a registered machine.
+func runRemoteSessionList(machineName, rigFilter string) error {
+	remote, _, err := resolveMachineAddress(machineName + ":")
+	if err != nil {
+		return err
+	}
+	if remote == nil {
+		return fmt.Errorf("unknown machine %q (see gt machine list)", machineName)
+	}
+	defer remote.Close()
+
+	remoteArgs := []string{"session", "list", "--json"}
+	if rigFilter != "" {
+		remoteArgs = append(remoteArgs, "--rig", rigFilter)
+	}
+	out, err := remote.gt(remoteArgs...)
+	if err != nil {
+		return err
+	}
+	var sessions []SessionListItem
+	if err := json.Unmarshal(out, &sessions); err != nil {
+		return fmt.Errorf("parsing session list from %s: %w", machineName, err)
+	}
+	for i := range sessions {
+		sessions[i].Machine = machineName
+	}
+	return printSessionList(sessions)
+}
+
+func printSessionList(allSessions []SessionListItem) error {
 	if sessionListJSON {
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
@@ -420,7 +466,11 @@ func runSessionList(cmd *cobra.Command, args []string) error {
 		if !s.Running {
 			status = style.Dim.Render("○")
 		}
-		fmt.Printf("  %s %s/%s\n", status, s.Rig, s.Polecat)
+		machine := ""
+		if s.Machine != "" {
+			machine = s.Machine + ":"
+		}
+		fmt.Printf("  %s %s%s/%s\n", status, machine, s.Rig, s.Polecat)
 		fmt.Printf("    %s\n", style.Dim.Render(s.SessionID))
 	}
 
@@ -175,6 +175,9 @@ func runSlingFormula(ctx context.Context, args []string) error {
 	telemetry.RecordMolWisp(ctx, formulaName, wispRootID, "", nil)
 
 	fmt.Printf("%s Wisp created: %s\n", style.Bold.Render("✓"), wispRootID)
+	if err := applyFormulaStepControls(beads.New(formulaWorkDir), wispRootID, formulaName); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not apply step controls: %v\n", err)
+	}
 
 	// Step 3: Hook the wisp bead with retry and verification.
 	// See: https://github.com/steveyegge/gastown/issues/148
@@ -685,11 +685,22 @@ type FormulaOnBeadResult struct {
 //   - extraVars: additional --var values supplied by the user
 //
 // Returns the wisp root ID which should be hooked.
-func InstantiateFormulaOnBead(ctx context.Context, formulaName, beadID, title, hookWorkDir, townRoot string, skipCook bool, extraVars []string) (_ *FormulaOnBeadResult, retErr error) {
+func InstantiateFormulaOnBead(ctx context.Context, formulaName, beadID, title, hookWorkDir, townRoot string, skipCook bool, extraVars []string) (result *FormulaOnBeadResult, retErr error) {
 	defer func() { telemetry.RecordFormulaInstantiate(ctx, formulaName, beadID, retErr) }()
 	// Route bd mutations (wisp/bond) to the correct beads context for the target bead.
 	formulaWorkDir := beads.ResolveHookDir(townRoot, beadID, hookWorkDir)
 
+	// Poured step beads don't carry the formula's step controls; add them
+	// once the molecule root is known.
+	defer func() {
+		if retErr != nil || result == nil {
+			return
+		}
+		if err := applyFormulaStepControls(beads.New(formulaWorkDir), result.WispRootID, formulaName); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not apply step controls: %v\n", err)
+		}
+	}()
+
 	// Step 1: Cook the formula (ensures proto exists)
 	// If cook fails, retry with the embedded formula extracted to a temp file.
 	// This handles non-gastown rigs that don't have formulas provisioned on disk.
@@ -23,6 +23,9 @@ Available guards:
   bd-init            - Block bd init in wrong directories
   mol-patrol         - Block mol patrol from agent contexts
   dangerous-command  - Block rm -rf, force push, hard reset, git clean
+  rules              - Enforce configured rules (settings/guard-rules.json)
+
+Check which rule fires for a command with 'gt tap guard test'.
 
 External guards (standalone scripts, not compiled into gt):
   context-budget   - scripts/guards/context-budget-guard.sh
@@ -57,7 +60,9 @@ The guard blocks in two scenarios:
   1. Running as a Gas Town agent (crew, polecat, witness, etc.)
   2. Origin remote is steveyegge/gastown (maintainer should push directly)
 
-Humans running outside Gas Town with a fork origin can still use PRs.`,
+Humans running outside Gas Town with a fork origin can still use PRs.
+A configured guard rule with action "allow" matching the PR command lifts
+the block (see 'gt tap guard rules').`,
 	RunE: runTapGuardPRWorkflow,
 }
 
@@ -67,6 +72,10 @@ func init() {
 }
 
 func runTapGuardPRWorkflow(cmd *cobra.Command, args []string) error {
+	if guardRulesLift(readHookInput(), isPRWorkflowCommand) {
+		return nil
+	}
+
 	// Check if we're in a Gas Town agent context
 	if isGasTownAgentContext() {
 		fmt.Fprintln(os.Stderr, "")
@@ -105,6 +114,21 @@ func runTapGuardPRWorkflow(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// prWorkflowCommands are the command prefixes the pr-workflow hook fires on.
+var prWorkflowCommands = []string{"gh pr create", "git checkout -b", "git switch -c"}
+
+// isPRWorkflowCommand reports whether a single command is one the
+// pr-workflow guard blocks.
+func isPRWorkflowCommand(command string) bool {
+	normalized := strings.Join(strings.Fields(command), " ")
+	for _, prefix := range prWorkflowCommands {
+		if normalized == prefix || strings.HasPrefix(normalized, prefix+" ") {
+			return true
+		}
+	}
+	return false
+}
+
 // isGasTownAgentContext returns true if we're running as a Gas Town managed agent.
 func isGasTownAgentContext() bool {
 	// Check environment variables set by Gas Town session management
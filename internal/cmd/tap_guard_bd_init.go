@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -21,7 +22,10 @@ This guard blocks:
 
 Exit codes:
   0 - Operation allowed (in HQ root or not in Gas Town context)
-  2 - Operation BLOCKED (in a rig worktree or other non-HQ directory)`,
+  2 - Operation BLOCKED (in a rig worktree or other non-HQ directory)
+
+A configured guard rule with action "allow" matching the bd init command
+lifts the block (see 'gt tap guard rules').`,
 	RunE: runTapGuardBdInit,
 }
 
@@ -44,7 +48,7 @@ func runTapGuardBdInit(cmd *cobra.Command, args []string) error {
 		return nil // not in a town — allow
 	}
 
-	if cwd == townRoot {
+	if cwd == townRoot || guardRulesLift(readHookInput(), isBdInitCommand) {
 		return nil
 	}
 
@@ -63,3 +67,9 @@ func runTapGuardBdInit(cmd *cobra.Command, args []string) error {
 	fmt.Fprintln(os.Stderr, "")
 	return NewSilentExit(2)
 }
+
+// isBdInitCommand reports whether a single command is a bd init.
+func isBdInitCommand(command string) bool {
+	fields := strings.Fields(command)
+	return len(fields) >= 2 && fields[0] == "bd" && fields[1] == "init"
+}
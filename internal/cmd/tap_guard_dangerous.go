@@ -24,7 +24,9 @@ This guard blocks operations that could cause irreversible damage:
   - truncate table
 
 The guard reads the tool input from stdin (Claude Code hook protocol)
-and exits with code 2 to block dangerous operations.
+and exits with code 2 to block dangerous operations. A configured guard
+rule with action "allow" lets a command through when it matches every
+dangerous command of the call (see 'gt tap guard rules').
 
 Exit codes:
   0 - Operation allowed
@@ -69,27 +71,35 @@ func runTapGuardDangerous(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	reason := dangerousCommandReason(command)
+	if reason == "" || guardRulesLift(input, isDangerousCommand) {
+		return nil
+	}
+	printDangerousBlock(reason, command)
+	return NewSilentExit(2)
+}
+
+// dangerousCommandReason returns why the command is blocked, or "" if it
+// is allowed.
+func dangerousCommandReason(command string) string {
 	lower := strings.ToLower(command)
 
 	// Check special patterns that need smarter matching
 	if reason := matchesDangerousRmRf(lower); reason != "" {
-		printDangerousBlock(reason, command)
-		return NewSilentExit(2)
+		return reason
 	}
 	if reason := matchesDangerousGitPush(lower); reason != "" {
-		printDangerousBlock(reason, command)
-		return NewSilentExit(2)
+		return reason
 	}
 
 	// Check simple fragment patterns
 	for _, pattern := range fragmentPatterns {
 		if matchesAllFragments(lower, pattern.contains) {
-			printDangerousBlock(pattern.reason, command)
-			return NewSilentExit(2)
+			return pattern.reason
 		}
 	}
 
-	return nil
+	return ""
 }
 
 // printDangerousBlock prints the standard block banner to stderr.
@@ -0,0 +1,371 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/guard"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	tapGuardTestTool string
+	tapGuardTestRig  string
+	tapGuardTestRole string
+	tapGuardTestCwd  string
+)
+
+var tapGuardRulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Enforce configured guard rules (settings/guard-rules.json)",
+	Long: `Enforce the guard rules configured for this town, rig and role.
+
+Rules files are merged from least to most specific:
+  <town>/settings/guard-rules.json
+  <town>/settings/guard-rules/<role>.json
+  <town>/<rig>/settings/guard-rules.json
+  <town>/<rig>/settings/guard-rules/<role>.json
+
+A rule with the same name as one in a less specific file replaces it, and
+"disabled": true removes it. Each command of a Bash call ("a && b", "a | b",
+...) is decided on its own: when several rules match a command, the last one
+wins, so a rig or role can carve exceptions out of town rules. The call then
+gets the most restrictive of its commands' decisions.
+
+Rule fields:
+  name     - Identifies the rule (required, unique per file)
+  tool     - Tool name, "|"-separated, * and ? wildcards (default: Bash)
+  pattern  - Glob matched against each command of a Bash call, or the
+             file path of other tools
+  paths    - Only apply at or below these directories (relative to the
+             town root); the cwd for Bash, the file path for other tools
+  action   - block, warn, require-escalation or allow
+  message  - Shown to the agent when the rule fires
+
+Actions:
+  block               - Exit 2 (BLOCK) with the rule's message
+  warn                - Allow, printing the message to stderr
+  require-escalation  - Exit 2 and tell the agent to 'gt escalate'
+  allow               - Allow; also lifts the built-in guards
+                        (dangerous-command, pr-workflow, bd-init) for the
+                        commands it matches, not the rest of the call
+
+Example settings/guard-rules.json:
+  {
+    "type": "guard-rules",
+    "version": 1,
+    "rules": [
+      {"name": "terraform-apply", "pattern": "terraform apply*", "action": "require-escalation",
+       "message": "Infra changes go through the deploy pipeline"},
+      {"name": "npm-publish", "pattern": "npm publish*", "action": "block"},
+      {"name": "secrets", "tool": "Write|Edit", "pattern": "*.env", "action": "block"}
+    ]
+  }
+
+The guard reads the tool input from stdin (Claude Code hook protocol). A
+missing or invalid rules file never blocks; use 'gt tap guard test' to check
+rules before relying on them.
+
+Exit codes:
+  0 - Operation allowed
+  2 - Operation BLOCKED`,
+	RunE:         runTapGuardRules,
+	SilenceUsage: true,
+}
+
+var tapGuardTestCmd = &cobra.Command{
+	Use:   "test <command>",
+	Short: "Show which guard rule fires for a command",
+	Long: `Evaluate the configured guard rules and the built-in dangerous-command
+checks against a command without running it.
+
+The rig and role default to the current agent; use --rig and --role to
+check another agent's rules. For tools other than Bash, pass the file path
+instead of a command.
+
+Examples:
+  gt tap guard test "terraform apply -auto-approve"
+  gt tap guard test "cd infra && kubectl delete ns staging" --rig gastown --role polecat
+  gt tap guard test --tool Write gastown/crew/max/.env`,
+	Args:         cobra.ExactArgs(1),
+	RunE:         runTapGuardTest,
+	SilenceUsage: true,
+}
+
+func init() {
+	tapGuardTestCmd.Flags().StringVar(&tapGuardTestTool, "tool", "Bash", "Tool name to evaluate (Bash, Write, Edit, ...)")
+	tapGuardTestCmd.Flags().StringVar(&tapGuardTestRig, "rig", "", "Rig whose rules apply (default: current rig)")
+	tapGuardTestCmd.Flags().StringVar(&tapGuardTestRole, "role", "", "Role whose rules apply (default: current role)")
+	tapGuardTestCmd.Flags().StringVar(&tapGuardTestCwd, "cwd", "", "Working directory of the call (default: current directory)")
+
+	tapGuardCmd.AddCommand(tapGuardRulesCmd)
+	tapGuardCmd.AddCommand(tapGuardTestCmd)
+}
+
+func runTapGuardRules(cmd *cobra.Command, args []string) error {
+	call := guard.ParseHookInput(readHookInput())
+	if call == nil {
+		return nil
+	}
+	townRoot, d := evaluateGuardRules(call)
+	if d == nil || d.Rule == nil {
+		return nil
+	}
+
+	r := d.Rule
+	subject := call.Command
+	if call.Tool != "Bash" {
+		subject = call.Path
+	}
+	switch r.Action {
+	case guard.ActionWarn:
+		fmt.Fprintf(os.Stderr, "⚠ guard rule %s (%s): %s\n", r.Name, guardRuleSource(townRoot, r), guardRuleMessage(r))
+		return nil
+	case guard.ActionBlock:
+		printGuardRuleBlock("❌ BLOCKED BY GUARD RULE", r, subject,
+			"If this is intentional, ask the user to run it manually.")
+		return NewSilentExit(2)
+	case guard.ActionEscalate:
+		printGuardRuleBlock("⚠ ESCALATION REQUIRED", r, subject,
+			"This operation needs human approval. Escalate it with:")
+		fmt.Fprintf(os.Stderr, "  gt escalate %q --reason %q\n\n", "Approval needed: "+r.Name, subject)
+		return NewSilentExit(2)
+	}
+	return nil
+}
+
+func runTapGuardTest(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	scope := currentGuardScope(townRoot)
+	if cmd.Flags().Changed("rig") {
+		scope.Rig = tapGuardTestRig
+	}
+	if cmd.Flags().Changed("role") {
+		scope.Role = tapGuardTestRole
+	}
+
+	call := &guard.Call{Tool: tapGuardTestTool, Cwd: tapGuardTestCwd}
+	if call.Tool == "Bash" {
+		call.Command = args[0]
+	} else {
+		call.Path = args[0]
+		if !filepath.IsAbs(call.Path) {
+			call.Path = filepath.Join(townRoot, call.Path)
+		}
+	}
+	if call.Cwd == "" {
+		call.Cwd, _ = os.Getwd()
+	} else if !filepath.IsAbs(call.Cwd) {
+		call.Cwd = filepath.Join(townRoot, call.Cwd)
+	}
+
+	rules, found, err := guard.Load(scope)
+	if err != nil {
+		return err
+	}
+
+	who := scope.Role
+	if scope.Rig != "" {
+		who = scope.Rig + "/" + who
+	}
+	if who == "" {
+		who = "town"
+	}
+	fmt.Printf("%s\n", style.Bold.Render("Guard rules for "+who))
+	for _, path := range scope.Files() {
+		rel, _ := filepath.Rel(townRoot, path)
+		if slices.Contains(found, path) {
+			fmt.Printf("  %s %s\n", style.Success.Render("✓"), rel)
+		} else {
+			fmt.Printf("  %s %s\n", style.Dim.Render("○"), style.Dim.Render(rel+" (not found)"))
+		}
+	}
+	fmt.Printf("  %d rule(s) after merge\n\n", len(rules.Rules))
+
+	d := rules.Evaluate(townRoot, call)
+	if len(d.Matched) == 0 {
+		fmt.Printf("%s No configured rule matches\n", style.Dim.Render("○"))
+	}
+	for _, r := range d.Matched {
+		marker := style.Dim.Render("·")
+		note := style.Dim.Render("(overridden by a later rule)")
+		if r == d.Rule {
+			marker = style.Bold.Render("→")
+			note = ""
+		} else if decides := guardRuleCommands(d, r); len(decides) > 0 {
+			note = style.Dim.Render("(decides " + strings.Join(decides, ", ") + ")")
+		}
+		fmt.Printf("%s %s %s %s %s\n", marker, style.Bold.Render(r.Name), guardActionLabel(r.Action),
+			style.Dim.Render(guardRuleSource(townRoot, r)), note)
+		if r.Message != "" {
+			fmt.Printf("    %s\n", r.Message)
+		}
+	}
+
+	var builtin string
+	if call.Tool == "Bash" {
+		builtin = dangerousCommandReason(call.Command)
+		if builtin != "" {
+			if d.Lifts(isDangerousCommand) {
+				fmt.Printf("%s built-in dangerous-command: %s %s\n", style.Dim.Render("·"), builtin,
+					style.Dim.Render("(lifted by allow rules)"))
+				builtin = ""
+			} else {
+				fmt.Printf("%s built-in dangerous-command: %s\n", style.Bold.Render("→"), builtin)
+			}
+		}
+	}
+
+	fmt.Println()
+	switch {
+	case d.Action().Blocks():
+		fmt.Printf("Result: %s by %s\n", guardActionLabel(d.Action()), d.Rule.Name)
+	case builtin != "":
+		fmt.Printf("Result: %s by dangerous-command\n", guardActionLabel(guard.ActionBlock))
+	case d.Action() == guard.ActionAllow:
+		fmt.Printf("Result: %s by %s\n", style.Success.Render("ALLOWED"), d.Rule.Name)
+	case d.Action() == guard.ActionWarn:
+		fmt.Printf("Result: %s by %s (allowed)\n", guardActionLabel(guard.ActionWarn), d.Rule.Name)
+	default:
+		fmt.Printf("Result: %s\n", style.Success.Render("ALLOWED"))
+	}
+	return nil
+}
+
+// readHookInput reads the hook input JSON from stdin. It returns nil when
+// stdin is a terminal, so guards run by hand don't wait for input.
+func readHookInput() []byte {
+	if fi, err := os.Stdin.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		return nil
+	}
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil
+	}
+	return input
+}
+
+// currentGuardScope returns the rules scope for the current agent. Outside
+// an agent context only town rules apply.
+func currentGuardScope(townRoot string) guard.Scope {
+	scope := guard.Scope{TownRoot: townRoot}
+	if info, err := GetRole(); err == nil && info.Role != RoleUnknown {
+		scope.Rig = info.Rig
+		scope.Role = string(info.Role)
+	}
+	return scope
+}
+
+// evaluateGuardRules evaluates the configured guard rules against a tool
+// call. It fails open: outside a town, or if a rules file can't be loaded,
+// it returns a nil decision (after warning about the bad file).
+func evaluateGuardRules(call *guard.Call) (string, *guard.Decision) {
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		return "", nil
+	}
+	rules, _, err := guard.Load(currentGuardScope(townRoot))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring guard rules: %v\n", err)
+		return townRoot, nil
+	}
+	if len(rules.Rules) == 0 {
+		return townRoot, nil
+	}
+	if call.Cwd == "" {
+		call.Cwd, _ = os.Getwd()
+	}
+	return townRoot, rules.Evaluate(townRoot, call)
+}
+
+// guardRulesLift reports whether configured allow rules lift a built-in
+// guard for the call in the hook input. guarded picks out the commands the
+// guard objects to, and each of them must be decided by an allow rule, so
+// allowing one command never lets another through (see Decision.Lifts).
+func guardRulesLift(input []byte, guarded func(command string) bool) bool {
+	call := guard.ParseHookInput(input)
+	if call == nil {
+		return false
+	}
+	_, d := evaluateGuardRules(call)
+	return d.Lifts(guarded)
+}
+
+// isDangerousCommand reports whether the dangerous-command guard objects
+// to a single command.
+func isDangerousCommand(command string) bool {
+	return dangerousCommandReason(command) != ""
+}
+
+// guardRuleCommands returns the commands of a Bash call that r decides.
+func guardRuleCommands(d *guard.Decision, r *guard.Rule) []string {
+	var commands []string
+	for _, cd := range d.Commands {
+		if cd.Rule == r {
+			commands = append(commands, strconv.Quote(cd.Command))
+		}
+	}
+	return commands
+}
+
+// printGuardRuleBlock prints the block banner for a configured rule.
+func printGuardRuleBlock(title string, r *guard.Rule, subject, footer string) {
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "╔══════════════════════════════════════════════════════════════════╗")
+	fmt.Fprintf(os.Stderr, "║  %-63s ║\n", title)
+	fmt.Fprintln(os.Stderr, "╠══════════════════════════════════════════════════════════════════╣")
+	fmt.Fprintf(os.Stderr, "║  Rule:    %-54s ║\n", truncateStr(r.Name, 54))
+	fmt.Fprintf(os.Stderr, "║  Command: %-54s ║\n", truncateStr(subject, 54))
+	fmt.Fprintf(os.Stderr, "║  Reason:  %-54s ║\n", truncateStr(guardRuleMessage(r), 54))
+	fmt.Fprintln(os.Stderr, "║                                                                  ║")
+	fmt.Fprintf(os.Stderr, "║  %-63s ║\n", footer)
+	fmt.Fprintln(os.Stderr, "╚══════════════════════════════════════════════════════════════════╝")
+	fmt.Fprintln(os.Stderr, "")
+	// The banner truncates; repeat a long message in full for the agent.
+	if msg := guardRuleMessage(r); len(msg) > 54 {
+		fmt.Fprintln(os.Stderr, msg)
+	}
+}
+
+func guardRuleMessage(r *guard.Rule) string {
+	if r.Message != "" {
+		return r.Message
+	}
+	if r.Pattern != "" {
+		return fmt.Sprintf("matches guard rule pattern %q", r.Pattern)
+	}
+	return "matches guard rule " + r.Name
+}
+
+// guardRuleSource returns the rule's file relative to the town root.
+func guardRuleSource(townRoot string, r *guard.Rule) string {
+	if rel, err := filepath.Rel(townRoot, r.Source); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return r.Source
+}
+
+func guardActionLabel(a guard.Action) string {
+	switch a {
+	case guard.ActionBlock:
+		return style.Error.Render("BLOCKED")
+	case guard.ActionEscalate:
+		return style.Error.Render("REQUIRES ESCALATION")
+	case guard.ActionWarn:
+		return style.Warning.Render("WARN")
+	case guard.ActionAllow:
+		return style.Success.Render("ALLOW")
+	}
+	return string(a)
+}
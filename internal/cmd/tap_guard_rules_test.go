@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/guard"
+)
+
+func TestDangerousCommandReason(t *testing.T) {
+	if dangerousCommandReason("git reset --hard HEAD~1") == "" {
+		t.Error("expected git reset --hard to be dangerous")
+	}
+	if reason := dangerousCommandReason("git reset --soft HEAD~1"); reason != "" {
+		t.Errorf("git reset --soft reported dangerous: %s", reason)
+	}
+}
+
+func TestGuardRulesLift(t *testing.T) {
+	townRoot := t.TempDir()
+	for _, dir := range []string{"mayor", "settings"} {
+		if err := os.MkdirAll(filepath.Join(townRoot, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(townRoot, "mayor", "town.json"), []byte(`{"name":"test"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rules := `{"rules": [
+  {"name": "npm-publish", "pattern": "npm publish*", "action": "block"},
+  {"name": "scratch-reset", "pattern": "git reset --hard*", "action": "allow", "paths": ["scratch"]},
+  {"name": "feature-branches", "pattern": "git checkout -b *", "action": "allow"}
+]}`
+	if err := os.WriteFile(filepath.Join(townRoot, "settings", "guard-rules.json"), []byte(rules), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvGTRole, "")
+	t.Chdir(townRoot)
+
+	bash := func(cwd, command string) string {
+		return `{"tool_name":"Bash","cwd":"` + cwd + `","tool_input":{"command":"` + command + `"}}`
+	}
+	scratch := filepath.Join(townRoot, "scratch")
+	tests := []struct {
+		name    string
+		input   string
+		guarded func(string) bool
+		want    bool
+	}{
+		{"allowed in scope", bash(scratch, "git reset --hard"), isDangerousCommand, true},
+		{"allowed with harmless commands", bash(scratch, "git status && git reset --hard"), isDangerousCommand, true},
+		{"out of scope", bash(townRoot, "git reset --hard"), isDangerousCommand, false},
+		{"chained dangerous command", bash(scratch, "git reset --hard && rm -rf /"), isDangerousCommand, false},
+		{"block rule is not allow", bash(townRoot, "npm publish"), isDangerousCommand, false},
+		{"pr guard lifted", bash(townRoot, "git checkout -b fix"), isPRWorkflowCommand, true},
+		{"pr guard with chained pr", bash(townRoot, "git checkout -b fix && gh pr create"), isPRWorkflowCommand, false},
+		{"no input", ``, isDangerousCommand, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := guardRulesLift([]byte(tt.input), tt.guarded); got != tt.want {
+				t.Errorf("guardRulesLift() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// The chained block rule still decides the call, whatever the allow.
+	_, d := evaluateGuardRules(&guard.Call{Tool: "Bash", Cwd: scratch, Command: "git reset --hard && npm publish"})
+	if d.Action() != guard.ActionBlock || d.Rule.Name != "npm-publish" {
+		t.Errorf("chained call decided by %v, want npm-publish block", d.Rule)
+	}
+}
+
+func TestGuardCommandPredicates(t *testing.T) {
+	for command, want := range map[string]bool{
+		"gh pr create --fill": true,
+		"git  checkout -b x":  true,
+		"git checkout main":   false,
+		"git switch -c x":     true,
+		"gh pr view":          false,
+	} {
+		if got := isPRWorkflowCommand(command); got != want {
+			t.Errorf("isPRWorkflowCommand(%q) = %v, want %v", command, got, want)
+		}
+	}
+	if !isBdInitCommand("bd init --prefix gt") || isBdInitCommand("bd list") {
+		t.Error("isBdInitCommand misclassifies commands")
+	}
+}
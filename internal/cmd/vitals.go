@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/budget"
 	"github.com/steveyegge/gastown/internal/doltserver"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -35,6 +36,8 @@ func runVitals(cmd *cobra.Command, args []string) error {
 	printVitalsDatabases(townRoot)
 	fmt.Println()
 	printVitalsBackups(townRoot)
+	fmt.Println()
+	printVitalsBudgets(townRoot)
 	return nil
 }
 
@@ -248,6 +251,35 @@ func printVitalsBackups(townRoot string) {
 	fmt.Println()
 }
 
+// printVitalsBudgets reports the daemon's last budget check. Limits at a soft
+// or hard threshold are listed; the rest are summarized.
+func printVitalsBudgets(townRoot string) {
+	fmt.Println(style.Bold.Render("Budgets"))
+	state, err := budget.LoadState(townRoot)
+	if err != nil || len(state.Statuses) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("none configured"))
+		return
+	}
+	if !state.Current(time.Now()) {
+		fmt.Printf("  %s\n", style.Dim.Render("not checked today (last "+state.Day+")"))
+		return
+	}
+
+	ok := 0
+	for _, st := range state.Statuses {
+		if st.Level == budget.LevelOK {
+			ok++
+			continue
+		}
+		line := fmt.Sprintf("  %s %-28s $%.2f  %s", budgetLevelIcon(st.Level), st.ID(), st.SpentUSD, describeBudgetLimit(st.Limit))
+		if st.BlocksDispatch(st.Key) || (st.Rig != "" && st.BlocksDispatch(st.Rig)) {
+			line += "  " + style.Warning.Render("dispatch stopped")
+		}
+		fmt.Println(line)
+	}
+	fmt.Printf("  %d within budget  %s\n", ok, style.Dim.Render("checked "+state.CheckedAt.Format("15:04")))
+}
+
 func vitalsFormatCount(n int) string {
 	if n < 1000 {
 		return fmt.Sprintf("%d", n)
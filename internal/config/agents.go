@@ -152,6 +152,12 @@ type AgentPresetInfo struct {
 	// keystroke and the 600ms readline timeout that follows it.
 	EscapeCancelsRequest bool `json:"escape_cancels_request,omitempty"`
 
+	// LogFormat names the agentlog adapter that reads this agent's on-disk
+	// conversation log (e.g., "claudecode", "codex", "gemini", "copilot").
+	// Custom agents set "log_format" in their registry entry.
+	// Empty means gt agent-log has nothing to stream for this agent.
+	LogFormat string `json:"log_format,omitempty"`
+
 	// ACP is the configuration for ACP (Agent Communication Protocol) support.
 	// nil means the agent does not support ACP.
 	ACP *ACPConfig `json:"acp,omitempty"`
@@ -237,6 +243,7 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 		InstructionsFile:       "CLAUDE.md",
 		EmitsPermissionWarning: true,
 		HasTurnBoundaryDrain:   true,
+		LogFormat:              "claudecode",
 	},
 	AgentGemini: {
 		Name:                AgentGemini,
@@ -261,6 +268,7 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 		ReadyDelayMs:         5000,
 		InstructionsFile:     "AGENTS.md",
 		EscapeCancelsRequest: true, // Gemini CLI uses Escape to abort active generation
+		LogFormat:            "gemini",
 	},
 	AgentCodex: {
 		Name:                AgentCodex,
@@ -281,6 +289,7 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 		ReadyPromptPrefix: "› ",
 		ReadyDelayMs:      3000,
 		InstructionsFile:  "AGENTS.md",
+		LogFormat:         "codex",
 	},
 	AgentCursor: {
 		Name:                AgentCursor,
@@ -358,6 +367,7 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 		HooksSettingsFile: "gastown.js",
 		ReadyDelayMs:      8000,
 		InstructionsFile:  "AGENTS.md",
+		LogFormat:         "opencode",
 		// ACP support
 		ACP: &ACPConfig{
 			Command: "acp",
@@ -388,6 +398,7 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 		ReadyPromptPrefix:  "",   // GA: no ❯ prompt; Copilot uses hint text, not a detectable prefix
 		ReadyDelayMs:       5000, // Delay-based readiness detection (no prompt prefix)
 		InstructionsFile:   "AGENTS.md",
+		LogFormat:          "copilot",
 	},
 	AgentPi: {
 		Name:                AgentPi,
@@ -642,6 +653,17 @@ func GetSessionIDEnvVar(agentName string) string {
 	return info.SessionIDEnv
 }
 
+// GetLogFormat returns the agentlog adapter name for an agent's conversation
+// logs. Unknown agents default to "claudecode"; known agents without a log
+// format return "".
+func GetLogFormat(agentName string) string {
+	info := GetAgentPresetByName(agentName)
+	if info == nil {
+		return "claudecode"
+	}
+	return info.LogFormat
+}
+
 // GetProcessNames returns the process names used to detect if an agent is running.
 // Used by tmux.IsAgentRunning to check pane_current_command.
 // Returns ["node"] for Claude (default) if agent is not found or has no ProcessNames.
@@ -601,6 +601,31 @@ func TestGetSessionIDEnvVar(t *testing.T) {
 	}
 }
 
+func TestGetLogFormat(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		agentName string
+		want      string
+	}{
+		{"claude", "claudecode"},
+		{"codex", "codex"},
+		{"gemini", "gemini"},
+		{"copilot", "copilot"},
+		{"opencode", "opencode"},
+		{"cursor", ""},     // No conversation log adapter
+		{"", "claudecode"}, // No GT_AGENT: Claude default
+		{"unknown", "claudecode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.agentName, func(t *testing.T) {
+			if got := GetLogFormat(tt.agentName); got != tt.want {
+				t.Errorf("GetLogFormat(%q) = %q, want %q", tt.agentName, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestGetProcessNames(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
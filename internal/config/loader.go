@@ -7,9 +7,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/constants"
@@ -219,6 +221,54 @@ func validateRigSettings(c *RigSettings) error {
 			return err
 		}
 	}
+	if c.Budgets != nil {
+		if err := validateBudgetsConfig(c.Budgets); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateBudgetsConfig validates spend limits and the hard-limit action.
+func validateBudgetsConfig(c *BudgetsConfig) error {
+	if c.HardAction != "" && c.HardAction != BudgetActionHandoff && c.HardAction != BudgetActionPark {
+		return fmt.Errorf("budgets: invalid hard_action %q (want %q or %q)", c.HardAction, BudgetActionHandoff, BudgetActionPark)
+	}
+	check := func(scope string, l *BudgetLimit) error {
+		if l == nil {
+			return nil
+		}
+		if l.SoftUSD < 0 || l.HardUSD < 0 {
+			return fmt.Errorf("budgets: %s: limits must not be negative", scope)
+		}
+		if l.SoftUSD > 0 && l.HardUSD > 0 && l.SoftUSD > l.HardUSD {
+			return fmt.Errorf("budgets: %s: soft_usd %.2f exceeds hard_usd %.2f", scope, l.SoftUSD, l.HardUSD)
+		}
+		return nil
+	}
+	if err := check("rig", c.Rig); err != nil {
+		return err
+	}
+	for kind, limits := range map[string]map[string]*BudgetLimit{"roles": c.Roles, "convoys": c.Convoys, "beads": c.Beads} {
+		for key, l := range limits {
+			if err := check(kind+"."+key, l); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validatePricing checks that model prices are present and not negative.
+func validatePricing(pricing map[string]*ModelPrice) error {
+	for model, p := range pricing {
+		if p == nil {
+			return fmt.Errorf("pricing: %s: missing prices", model)
+		}
+		if p.Input < 0 || p.Output < 0 || p.CacheRead < 0 || p.CacheWrite < 0 {
+			return fmt.Errorf("pricing: %s: prices must not be negative", model)
+		}
+	}
 	return nil
 }
 
@@ -1020,6 +1070,46 @@ func validateMessagingConfig(c *MessagingConfig) error {
 		}
 	}
 
+	for i, rule := range c.Rules {
+		if err := validateMailRule(rule); err != nil {
+			name := rule.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i+1)
+			}
+			return fmt.Errorf("mail rule %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// mailRulePriorities are the priorities a mail rule may match or set.
+var mailRulePriorities = map[string]bool{"low": true, "normal": true, "high": true, "urgent": true}
+
+func validateMailRule(rule MailRule) error {
+	switch rule.Action {
+	case MailRuleArchive, MailRuleNudge, MailRuleHook:
+	case MailRuleForward:
+		if rule.Target == "" {
+			return fmt.Errorf("%w: forward rule needs a target", ErrMissingField)
+		}
+	case MailRulePriority:
+		if !mailRulePriorities[rule.Priority] {
+			return fmt.Errorf("invalid priority %q (want low, normal, high or urgent)", rule.Priority)
+		}
+	case "":
+		return fmt.Errorf("%w: action", ErrMissingField)
+	default:
+		return fmt.Errorf("unknown action %q (want archive, forward, priority, nudge or hook)", rule.Action)
+	}
+	if rule.Match.Priority != "" && !mailRulePriorities[rule.Match.Priority] {
+		return fmt.Errorf("invalid match priority %q", rule.Match.Priority)
+	}
+	if rule.Match.Subject != "" {
+		if _, err := regexp.Compile("(?i)" + rule.Match.Subject); err != nil {
+			return fmt.Errorf("invalid subject pattern: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -1075,6 +1165,14 @@ func SaveTownSettings(path string, settings *TownSettings) error {
 	if settings.Version > CurrentTownSettingsVersion {
 		return fmt.Errorf("%w: got %d, max supported %d", ErrInvalidVersion, settings.Version, CurrentTownSettingsVersion)
 	}
+	if settings.Budgets != nil {
+		if err := validateBudgetsConfig(settings.Budgets); err != nil {
+			return err
+		}
+	}
+	if err := validatePricing(settings.Pricing); err != nil {
+		return err
+	}
 
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return fmt.Errorf("creating directory: %w", err)
@@ -2716,9 +2814,65 @@ func validateEscalationConfig(c *EscalationConfig) error {
 		return fmt.Errorf("%w: max_reescalations must be non-negative", ErrMissingField)
 	}
 
+	for name, n := range c.Notifiers {
+		if n == nil || n.URL == "" {
+			return fmt.Errorf("%w: notifier '%s' needs a url", ErrMissingField, name)
+		}
+		if n.Timeout != "" {
+			d, err := time.ParseDuration(n.Timeout)
+			if err != nil {
+				return fmt.Errorf("invalid timeout for notifier '%s': %w", name, err)
+			}
+			if d <= 0 || d > MaxNotifierTimeout {
+				return fmt.Errorf("invalid timeout for notifier '%s': must be between 0 and %s", name, MaxNotifierTimeout)
+			}
+		}
+		for field, text := range map[string]string{"url": n.URL, "body": n.Body, "ack_body": n.AckBody, "resolve_body": n.ResolveBody} {
+			if _, err := template.New(name).Funcs(NotifierTemplateFuncs()).Parse(text); err != nil {
+				return fmt.Errorf("invalid %s template for notifier '%s': %w", field, name, err)
+			}
+		}
+	}
+
+	// Validate that notifier actions reference defined notifiers
+	for severity, actions := range c.Routes {
+		for _, action := range actions {
+			kind, name, ok := strings.Cut(action, ":")
+			if !ok || (kind != "webhook" && kind != "incident") {
+				continue
+			}
+			if _, defined := c.Notifiers[name]; !defined {
+				return fmt.Errorf("%w: route '%s' action '%s' references undefined notifier '%s'", ErrMissingField, severity, action, name)
+			}
+		}
+	}
+
 	return nil
 }
 
+// NotifierTemplateFuncs returns the functions available in escalation
+// notifier templates.
+func NotifierTemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}
+}
+
+// IncidentNotifiers returns the notifier names used by incident:<name>
+// actions in a route, in order.
+func IncidentNotifiers(actions []string) []string {
+	var names []string
+	for _, action := range actions {
+		if name, ok := strings.CutPrefix(action, "incident:"); ok && name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 // GetStaleThreshold returns the stale threshold as a time.Duration.
 // Returns 4 hours if not configured or invalid.
 func (c *EscalationConfig) GetStaleThreshold() time.Duration {
@@ -334,6 +334,39 @@ func TestRigSettingsValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid budgets",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Budgets: &BudgetsConfig{
+					Rig:        &BudgetLimit{SoftUSD: 40, HardUSD: 60},
+					Roles:      map[string]*BudgetLimit{"polecat": {HardUSD: 30}},
+					HardAction: BudgetActionPark,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "budget soft above hard",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Budgets: &BudgetsConfig{
+					Beads: map[string]*BudgetLimit{"gt-abc": {SoftUSD: 20, HardUSD: 10}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "budget invalid hard_action",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Budgets: &BudgetsConfig{HardAction: "kill"},
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid on_conflict",
 			settings: &RigSettings{
@@ -908,6 +941,50 @@ func TestMessagingConfigValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid mail rules",
+			config: &MessagingConfig{
+				Version: 1,
+				Rules: []MailRule{
+					{Identity: "mayor/", Match: MailRuleMatch{From: "*/witness", Subject: "^patrol"}, Action: MailRuleArchive},
+					{Match: MailRuleMatch{Labels: []string{"gt:escalation"}}, Action: MailRuleForward, Target: "list:oncall"},
+					{Match: MailRuleMatch{Type: "task"}, Action: MailRulePriority, Priority: "high", Stop: true},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "mail rule with unknown action",
+			config: &MessagingConfig{
+				Version: 1,
+				Rules:   []MailRule{{Action: "delete"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "forward rule without target",
+			config: &MessagingConfig{
+				Version: 1,
+				Rules:   []MailRule{{Action: MailRuleForward}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "priority rule with invalid priority",
+			config: &MessagingConfig{
+				Version: 1,
+				Rules:   []MailRule{{Action: MailRulePriority, Priority: "p0"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mail rule with invalid subject regexp",
+			config: &MessagingConfig{
+				Version: 1,
+				Rules:   []MailRule{{Match: MailRuleMatch{Subject: "([a-z"}, Action: MailRuleArchive}},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -2771,6 +2848,21 @@ func TestSaveTownSettings(t *testing.T) {
 		}
 	})
 
+	t.Run("rejects negative model pricing", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		settingsPath := filepath.Join(tmpDir, "config.json")
+
+		settings := NewTownSettings()
+		settings.Pricing = map[string]*ModelPrice{
+			"claude-sonnet-4": {Input: 3, Output: -15},
+		}
+
+		err := SaveTownSettings(settingsPath, settings)
+		if err == nil {
+			t.Error("expected error for negative pricing")
+		}
+	})
+
 	t.Run("roundtrip save and load", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		settingsPath := filepath.Join(tmpDir, "config.json")
@@ -4329,6 +4421,63 @@ func TestEscalationConfigValidation(t *testing.T) {
 			wantErr: true,
 			errMsg:  "max_reescalations must be non-negative",
 		},
+		{
+			name: "notifier routes",
+			config: &EscalationConfig{
+				Type:    "escalation",
+				Version: 1,
+				Routes: map[string][]string{
+					SeverityHigh: {"bead", "webhook:oncall", "incident:pager"},
+				},
+				Notifiers: map[string]*EscalationNotifier{
+					"oncall": {URL: "https://chat.example/hook", Body: `{"content": {{json .Title}}}`},
+					"pager":  {URL: "https://events.example/v2/enqueue", Timeout: "5s"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "undefined notifier",
+			config: &EscalationConfig{
+				Type:    "escalation",
+				Version: 1,
+				Routes: map[string][]string{
+					SeverityHigh: {"incident:pager"},
+				},
+			},
+			wantErr: true,
+			errMsg:  "undefined notifier 'pager'",
+		},
+		{
+			name: "notifier without url",
+			config: &EscalationConfig{
+				Type:      "escalation",
+				Version:   1,
+				Notifiers: map[string]*EscalationNotifier{"oncall": {}},
+			},
+			wantErr: true,
+			errMsg:  "notifier 'oncall' needs a url",
+		},
+		{
+			name: "notifier bad template",
+			config: &EscalationConfig{
+				Type:      "escalation",
+				Version:   1,
+				Notifiers: map[string]*EscalationNotifier{"oncall": {URL: "https://x", Body: "{{.Title"}},
+			},
+			wantErr: true,
+			errMsg:  "invalid body template for notifier 'oncall'",
+		},
+		{
+			name: "notifier timeout too long",
+			config: &EscalationConfig{
+				Type:      "escalation",
+				Version:   1,
+				Notifiers: map[string]*EscalationNotifier{"oncall": {URL: "https://x", Timeout: "5m"}},
+			},
+			wantErr: true,
+			errMsg:  "invalid timeout for notifier 'oncall'",
+		},
 	}
 
 	for _, tt := range tests {
@@ -111,6 +111,10 @@ const (
 	DefaultWitnessMaxBeadRespawns        = 3
 	DefaultWitnessDoneIntentStuckTimeout = 60 * time.Second
 	DefaultWitnessDoneIntentRecentGrace  = 30 * time.Second
+	DefaultWitnessThrashWindow           = 30 * time.Minute
+	DefaultWitnessThrashToolRepeats      = 8
+	DefaultWitnessThrashErrorRepeats     = 5
+	DefaultWitnessThrashEditReverts      = 2
 )
 
 // LoadOperationalConfig loads operational config from a town root.
@@ -732,3 +736,36 @@ func (wt *WitnessThresholds) DoneIntentRecentGraceD() time.Duration {
 	}
 	return DefaultWitnessDoneIntentRecentGrace
 }
+
+// ThrashWindowD returns the configured or default thrash detection window.
+// Zero disables thrash detection.
+func (wt *WitnessThresholds) ThrashWindowD() time.Duration {
+	if wt != nil {
+		return ParseDurationOrDefault(wt.ThrashWindow, DefaultWitnessThrashWindow)
+	}
+	return DefaultWitnessThrashWindow
+}
+
+// ThrashToolRepeatsV returns the configured or default tool-loop threshold.
+func (wt *WitnessThresholds) ThrashToolRepeatsV() int {
+	if wt != nil && wt.ThrashToolRepeats != nil {
+		return *wt.ThrashToolRepeats
+	}
+	return DefaultWitnessThrashToolRepeats
+}
+
+// ThrashErrorRepeatsV returns the configured or default repeated-error threshold.
+func (wt *WitnessThresholds) ThrashErrorRepeatsV() int {
+	if wt != nil && wt.ThrashErrorRepeats != nil {
+		return *wt.ThrashErrorRepeats
+	}
+	return DefaultWitnessThrashErrorRepeats
+}
+
+// ThrashEditRevertsV returns the configured or default edit/revert threshold.
+func (wt *WitnessThresholds) ThrashEditRevertsV() int {
+	if wt != nil && wt.ThrashEditReverts != nil {
+		return *wt.ThrashEditReverts
+	}
+	return DefaultWitnessThrashEditReverts
+}
@@ -361,12 +361,25 @@ func TestWitnessThresholds_Defaults(t *testing.T) {
 	if got := wit.DoneIntentRecentGraceD(); got != DefaultWitnessDoneIntentRecentGrace {
 		t.Errorf("DoneIntentRecentGrace: got %v, want %v", got, DefaultWitnessDoneIntentRecentGrace)
 	}
+	if got := wit.ThrashWindowD(); got != DefaultWitnessThrashWindow {
+		t.Errorf("ThrashWindow: got %v, want %v", got, DefaultWitnessThrashWindow)
+	}
+	if got := wit.ThrashToolRepeatsV(); got != DefaultWitnessThrashToolRepeats {
+		t.Errorf("ThrashToolRepeats: got %v, want %v", got, DefaultWitnessThrashToolRepeats)
+	}
+	if got := wit.ThrashErrorRepeatsV(); got != DefaultWitnessThrashErrorRepeats {
+		t.Errorf("ThrashErrorRepeats: got %v, want %v", got, DefaultWitnessThrashErrorRepeats)
+	}
+	if got := wit.ThrashEditRevertsV(); got != DefaultWitnessThrashEditReverts {
+		t.Errorf("ThrashEditReverts: got %v, want %v", got, DefaultWitnessThrashEditReverts)
+	}
 }
 
 func TestWitnessThresholds_Overrides(t *testing.T) {
 	t.Parallel()
 
 	maxRespawns := 5
+	toolRepeats := 12
 	op := &OperationalConfig{
 		Witness: &WitnessThresholds{
 			StartupStallThreshold:  "2m",
@@ -374,6 +387,8 @@ func TestWitnessThresholds_Overrides(t *testing.T) {
 			MaxBeadRespawns:        &maxRespawns,
 			DoneIntentStuckTimeout: "90s",
 			DoneIntentRecentGrace:  "15s",
+			ThrashWindow:           "0s",
+			ThrashToolRepeats:      &toolRepeats,
 		},
 	}
 
@@ -393,6 +408,12 @@ func TestWitnessThresholds_Overrides(t *testing.T) {
 	if got := wit.DoneIntentRecentGraceD(); got != 15*time.Second {
 		t.Errorf("DoneIntentRecentGrace: got %v, want 15s", got)
 	}
+	if got := wit.ThrashWindowD(); got != 0 {
+		t.Errorf("ThrashWindow: got %v, want 0 (disabled)", got)
+	}
+	if got := wit.ThrashToolRepeatsV(); got != 12 {
+		t.Errorf("ThrashToolRepeats: got %v, want 12", got)
+	}
 }
 
 func TestPressureThresholds_Defaults(t *testing.T) {
@@ -112,8 +112,36 @@ type TownSettings struct {
 	// "main_branch_test", "handler").
 	// Example: ["doctor_dog", "compactor_dog"]
 	DisabledPatrols []string `json:"disabled_patrols,omitempty"`
+
+	// SessionBackend selects how agent sessions are hosted.
+	// Values: "tmux" (default) or "headless" (PTY sessions managed in-process
+	// by the daemon, for hosts and CI containers without tmux).
+	SessionBackend string `json:"session_backend,omitempty"`
+
+	// Budgets sets daily spend limits town-wide. See BudgetsConfig.
+	Budgets *BudgetsConfig `json:"budgets,omitempty"`
+
+	// Pricing overrides or extends the compiled-in per-model token prices used
+	// by gt costs. Keys are model IDs or model ID prefixes (the longest
+	// matching prefix wins); "default" prices models that match nothing.
+	// Example: {"claude-sonnet-4": {"input": 3, "output": 15, "cache_read": 0.3, "cache_write": 3.75}}
+	Pricing map[string]*ModelPrice `json:"pricing,omitempty"`
 }
 
+// ModelPrice is a model's token pricing in USD per million tokens.
+type ModelPrice struct {
+	Input      float64 `json:"input"`
+	Output     float64 `json:"output"`
+	CacheRead  float64 `json:"cache_read,omitempty"`
+	CacheWrite float64 `json:"cache_write,omitempty"`
+}
+
+// Session backend names for TownSettings.SessionBackend.
+const (
+	SessionBackendTmux     = "tmux"
+	SessionBackendHeadless = "headless"
+)
+
 // NewTownSettings creates a new TownSettings with defaults.
 func NewTownSettings() *TownSettings {
 	return &TownSettings{
@@ -477,6 +505,23 @@ type WitnessThresholds struct {
 	// DoneIntentRecentGrace is how recently a done-intent must have been created
 	// to be considered still in progress (default "30s").
 	DoneIntentRecentGrace string `json:"done_intent_recent_grace,omitempty"`
+
+	// ThrashWindow is how far back the patrol reads a polecat's agent log when
+	// looking for tool-call loops, repeated errors and edit/revert cycles
+	// (default "30m"; "0s" disables thrash detection).
+	ThrashWindow string `json:"thrash_window,omitempty"`
+
+	// ThrashToolRepeats is how many identical tool calls in a row, with no
+	// other tool call in between, count as a tool loop (default 8).
+	ThrashToolRepeats *int `json:"thrash_tool_repeats,omitempty"`
+
+	// ThrashErrorRepeats is how many identical error results within the
+	// window count as a repeated error (default 5).
+	ThrashErrorRepeats *int `json:"thrash_error_repeats,omitempty"`
+
+	// ThrashEditReverts is how many edits that undo an earlier edit within
+	// the window count as an edit/revert cycle (default 2).
+	ThrashEditReverts *int `json:"thrash_edit_reverts,omitempty"`
 }
 
 // DefaultOperationalConfig returns an OperationalConfig with all defaults.
@@ -669,6 +714,43 @@ type RigSettings struct {
 	// Takes precedence over RoleAgents["crew"] but is overridden by explicit --agent flags.
 	// Example: {"denali": "codex", "glacier": "gemini"}
 	WorkerAgents map[string]string `json:"worker_agents,omitempty"`
+
+	// Budgets sets daily spend limits for this rig. See BudgetsConfig.
+	Budgets *BudgetsConfig `json:"budgets,omitempty"`
+}
+
+// Hard-limit actions for BudgetsConfig.HardAction.
+const (
+	// BudgetActionHandoff asks sessions over a hard limit to wrap up and hand off.
+	BudgetActionHandoff = "handoff"
+	// BudgetActionPark also parks the rig so no agents are restarted in it.
+	BudgetActionPark = "park"
+)
+
+// BudgetLimit is a daily spend limit in USD. A zero threshold is not enforced.
+type BudgetLimit struct {
+	// SoftUSD warns the mayor once spend reaches it.
+	SoftUSD float64 `json:"soft_usd,omitempty"`
+	// HardUSD stops dispatch and winds down sessions once spend reaches it.
+	HardUSD float64 `json:"hard_usd,omitempty"`
+}
+
+// BudgetsConfig sets daily spend limits, evaluated by the daemon each
+// heartbeat against recorded and live session costs. Days are local days.
+//
+// In town settings, Rig is the default limit for every rig and Roles limit
+// each role across the town. In rig settings, Rig overrides the town default
+// for that rig and Roles limit each role within the rig. Convoys and Beads are
+// keyed by ID and may appear in either.
+type BudgetsConfig struct {
+	Rig     *BudgetLimit            `json:"rig,omitempty"`
+	Roles   map[string]*BudgetLimit `json:"roles,omitempty"`
+	Convoys map[string]*BudgetLimit `json:"convoys,omitempty"`
+	Beads   map[string]*BudgetLimit `json:"beads,omitempty"`
+
+	// HardAction is what happens to sessions at a hard limit: "handoff"
+	// (default) or "park". A rig's setting overrides the town's.
+	HardAction string `json:"hard_action,omitempty"`
 }
 
 // CrewConfig represents crew workspace settings for a rig.
@@ -1509,6 +1591,77 @@ type MessagingConfig struct {
 	// Like mailing lists but for tmux send-keys instead of durable mail.
 	// Example: {"workers": ["gastown/polecats/*", "gastown/crew/*"], "witnesses": ["*/witness"]}
 	NudgeChannels map[string][]string `json:"nudge_channels,omitempty"`
+
+	// Rules are per-recipient mail filters applied when a message is routed.
+	// Rules are evaluated in order; every matching rule's action is applied
+	// until a rule with Stop matches.
+	// Example: [{"identity": "mayor/", "match": {"from": "*/witness", "subject": "^patrol"}, "action": "archive"}]
+	Rules []MailRule `json:"rules,omitempty"`
+}
+
+// Mail rule actions.
+const (
+	// MailRuleArchive delivers the message straight to the recipient's archive.
+	MailRuleArchive = "archive"
+
+	// MailRuleForward delivers a copy to Target as well.
+	MailRuleForward = "forward"
+
+	// MailRulePriority re-prioritizes the message before delivery.
+	MailRulePriority = "priority"
+
+	// MailRuleNudge converts the message to a nudge; no mail is stored.
+	MailRuleNudge = "nudge"
+
+	// MailRuleHook attaches the message to the recipient's hook when the
+	// hook is empty.
+	MailRuleHook = "hook"
+)
+
+// MailRule is a server-side mail filter for one recipient (or a wildcard
+// set of recipients).
+type MailRule struct {
+	// Name identifies the rule in `gt mail rules` output.
+	Name string `json:"name,omitempty"`
+
+	// Identity is the recipient address the rule applies to.
+	// '*' matches any single path segment: "gastown/polecats/*".
+	// Empty applies the rule to every recipient.
+	Identity string `json:"identity,omitempty"`
+
+	// Match selects messages. All set fields must match.
+	Match MailRuleMatch `json:"match"`
+
+	// Action is one of archive, forward, priority, nudge, hook.
+	Action string `json:"action"`
+
+	// Target is the forwarding address for the forward action.
+	Target string `json:"target,omitempty"`
+
+	// Priority is the new priority for the priority action
+	// (low, normal, high, urgent).
+	Priority string `json:"priority,omitempty"`
+
+	// Stop ends rule evaluation when this rule matches.
+	Stop bool `json:"stop,omitempty"`
+}
+
+// MailRuleMatch is the message filter of a MailRule.
+type MailRuleMatch struct {
+	// From matches the sender address; '*' matches one path segment.
+	From string `json:"from,omitempty"`
+
+	// Subject is a case-insensitive regular expression matched against the subject.
+	Subject string `json:"subject,omitempty"`
+
+	// Type matches the message type (task, escalation, scavenge, notification, reply).
+	Type string `json:"type,omitempty"`
+
+	// Priority matches the message priority (low, normal, high, urgent).
+	Priority string `json:"priority,omitempty"`
+
+	// Labels must all be present on the message (e.g. "gt:escalation").
+	Labels []string `json:"labels,omitempty"`
 }
 
 // QueueConfig represents a work queue configuration.
@@ -1561,11 +1714,18 @@ type EscalationConfig struct {
 	//   - "sms:human"   → Send SMS to contacts.human_sms
 	//   - "slack"       → Post to contacts.slack_webhook
 	//   - "log"         → Write to escalation log file
+	//   - "webhook:<name>"  → POST to notifiers[name] once
+	//   - "incident:<name>" → Open an incident via notifiers[name]; ack/close
+	//     of the escalation acknowledges/resolves it
 	Routes map[string][]string `json:"routes"`
 
 	// Contacts contains contact information for external notification actions.
 	Contacts EscalationContacts `json:"contacts"`
 
+	// Notifiers defines named HTTP notifiers referenced by webhook:<name> and
+	// incident:<name> route actions.
+	Notifiers map[string]*EscalationNotifier `json:"notifiers,omitempty"`
+
 	// StaleThreshold is how long before an unacknowledged escalation
 	// is considered stale and gets re-escalated.
 	// Format: Go duration string (e.g., "4h", "30m", "24h")
@@ -1591,6 +1751,50 @@ type EscalationContacts struct {
 	SMSWebhook   string `json:"sms_webhook,omitempty"`   // webhook URL for SMS delivery (e.g. Twilio)
 }
 
+// EscalationNotifier is a named HTTP endpoint that escalations are delivered to.
+//
+// URL and Body are Go text/template strings rendered from the escalation:
+// .ID, .DedupKey, .Event ("trigger", "acknowledge" or "resolve"), .Title,
+// .Severity, .Reason, .Source, .EscalatedBy, .RelatedBead, .Actor, .Note and
+// .Time. The json function quotes a value as a JSON string, e.g.
+// {"content": {{json .Title}}}. A JSON content type requires the rendered
+// body to be valid JSON.
+type EscalationNotifier struct {
+	// URL is the endpoint. May reference template fields (e.g. a Matrix
+	// transaction ID built from {{.DedupKey}}).
+	URL string `json:"url"`
+
+	// Method is the HTTP method (default "POST").
+	Method string `json:"method,omitempty"`
+
+	// Headers are sent with every request (e.g. Authorization).
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Body is the request body template. When empty a generic JSON document
+	// describing the escalation is sent.
+	Body string `json:"body,omitempty"`
+
+	// AckBody and ResolveBody override Body for the acknowledge and resolve
+	// events of incident:<name> actions.
+	AckBody     string `json:"ack_body,omitempty"`
+	ResolveBody string `json:"resolve_body,omitempty"`
+
+	// Timeout bounds each request (Go duration, default "10s", at most
+	// MaxNotifierTimeout).
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// MaxNotifierTimeout caps EscalationNotifier.Timeout so a slow endpoint
+// cannot hold up gt escalate.
+const MaxNotifierTimeout = 60 * time.Second
+
+// Escalation notifier events, exposed to templates as .Event.
+const (
+	NotifierEventTrigger     = "trigger"
+	NotifierEventAcknowledge = "acknowledge"
+	NotifierEventResolve     = "resolve"
+)
+
 // CurrentEscalationVersion is the current schema version for EscalationConfig.
 const CurrentEscalationVersion = 1
 
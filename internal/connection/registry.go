@@ -6,6 +6,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 )
 
@@ -16,6 +17,15 @@ type Machine struct {
 	Host     string `json:"host"`      // for ssh: user@host
 	KeyPath  string `json:"key_path"`  // SSH private key path
 	TownPath string `json:"town_path"` // Path to town root on remote
+
+	// HostKey pins the remote host key (authorized_keys format). When empty,
+	// the host must be in ~/.ssh/known_hosts.
+	HostKey string `json:"host_key,omitempty"`
+}
+
+// RegistryPath returns the machine registry location for a town.
+func RegistryPath(townRoot string) string {
+	return filepath.Join(townRoot, "mayor", "machines.json")
 }
 
 // registryData is the JSON file structure.
@@ -122,6 +132,9 @@ func (r *MachineRegistry) Add(m *Machine) error {
 	if m.Name == "" {
 		return fmt.Errorf("machine name is required")
 	}
+	if strings.ContainsAny(m.Name, ":/ ") {
+		return fmt.Errorf("machine name %q must not contain ':', '/' or spaces", m.Name)
+	}
 	if m.Type == "" {
 		return fmt.Errorf("machine type is required")
 	}
@@ -176,8 +189,7 @@ func (r *MachineRegistry) Connection(name string) (Connection, error) {
 	case "local":
 		return NewLocalConnection(), nil
 	case "ssh":
-		// SSH connection not yet implemented
-		return nil, fmt.Errorf("ssh connections not yet implemented")
+		return NewSSHConnection(m)
 	default:
 		return nil, fmt.Errorf("unknown machine type: %s", m.Type)
 	}
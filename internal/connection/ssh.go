@@ -0,0 +1,515 @@
+package connection
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// DefaultSSHPort is used when a machine's host has no port.
+const DefaultSSHPort = 22
+
+// sshDialTimeout bounds connection setup, including the handshake.
+const sshDialTimeout = 10 * time.Second
+
+// SSHConnection implements Connection for a remote machine over SSH.
+//
+// Every operation runs as a shell command on the remote host, in its own SSH
+// session over one shared client connection, so the remote needs only a
+// POSIX shell, coreutils and (for tmux operations) tmux. The client is dialed
+// on first use and redialed after a transport failure.
+type SSHConnection struct {
+	name      string
+	addr      string // host:port
+	config    *ssh.ClientConfig
+	agentSock string // SSH agent socket used when the machine has no key
+
+	mu     sync.Mutex
+	client *ssh.Client
+}
+
+// NewSSHConnection creates a connection to an ssh machine. Host is
+// "[user@]host[:port]". Authentication uses the machine's key, or the SSH
+// agent when it has none. The host key is checked against the machine's
+// pinned HostKey, or ~/.ssh/known_hosts when none is pinned.
+func NewSSHConnection(m *Machine) (*SSHConnection, error) {
+	username, addr, err := parseSSHHost(m.Host)
+	if err != nil {
+		return nil, err
+	}
+	auth, agentSock, err := sshAuth(m.KeyPath)
+	if err != nil {
+		return nil, &ConnectionError{Op: "auth", Machine: m.Name, Err: err}
+	}
+	hostKeyCallback, err := sshHostKeyCallback(m.HostKey)
+	if err != nil {
+		return nil, &ConnectionError{Op: "host key", Machine: m.Name, Err: err}
+	}
+	return &SSHConnection{
+		name: m.Name,
+		addr: addr,
+		config: &ssh.ClientConfig{
+			User:            username,
+			Auth:            auth,
+			HostKeyCallback: hostKeyCallback,
+			Timeout:         sshDialTimeout,
+		},
+		agentSock: agentSock,
+	}, nil
+}
+
+// parseSSHHost splits "[user@]host[:port]" into a user and a dialable address.
+func parseSSHHost(host string) (username, addr string, err error) {
+	if host == "" {
+		return "", "", fmt.Errorf("ssh machine requires host")
+	}
+	if at := strings.LastIndex(host, "@"); at >= 0 {
+		username, host = host[:at], host[at+1:]
+	}
+	if username == "" {
+		if u, err := user.Current(); err == nil {
+			username = u.Username
+		}
+	}
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		if _, err := strconv.Atoi(p); err != nil {
+			return "", "", fmt.Errorf("invalid port in host %q", host)
+		}
+		return username, net.JoinHostPort(h, p), nil
+	}
+	return username, net.JoinHostPort(host, strconv.Itoa(DefaultSSHPort)), nil
+}
+
+// sshAuth returns the auth methods for the machine's key or, when it has
+// none, the SSH agent socket to authenticate with. The agent is dialed for
+// each connection in dial, which closes it once the handshake is done.
+func sshAuth(keyPath string) ([]ssh.AuthMethod, string, error) {
+	if keyPath != "" {
+		data, err := os.ReadFile(expandHome(keyPath)) //nolint:gosec // G304: key path is from the machine registry
+		if err != nil {
+			return nil, "", fmt.Errorf("reading key: %w", err)
+		}
+		signer, err := ssh.ParsePrivateKey(data)
+		if err != nil {
+			return nil, "", fmt.Errorf("parsing key %s: %w", keyPath, err)
+		}
+		return []ssh.AuthMethod{ssh.PublicKeys(signer)}, "", nil
+	}
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		return nil, sock, nil
+	}
+	return nil, "", fmt.Errorf("no key_path configured and no SSH agent (SSH_AUTH_SOCK) available")
+}
+
+func sshHostKeyCallback(pinned string) (ssh.HostKeyCallback, error) {
+	if pinned != "" {
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(pinned))
+		if err != nil {
+			return nil, fmt.Errorf("parsing host_key: %w", err)
+		}
+		return ssh.FixedHostKey(key), nil
+	}
+	path := expandHome("~/.ssh/known_hosts")
+	cb, err := knownhosts.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("no host_key pinned and %s unusable: %w", path, err)
+	}
+	return cb, nil
+}
+
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
+
+// Name returns the machine name.
+func (c *SSHConnection) Name() string {
+	return c.name
+}
+
+// IsLocal returns false for SSH connections.
+func (c *SSHConnection) IsLocal() bool {
+	return false
+}
+
+// Close closes the underlying SSH client, if connected.
+func (c *SSHConnection) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client == nil {
+		return nil
+	}
+	err := c.client.Close()
+	c.client = nil
+	return err
+}
+
+func (c *SSHConnection) dial() (*ssh.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client != nil {
+		return c.client, nil
+	}
+	config := c.config
+	if c.agentSock != "" {
+		// The agent is only needed to sign during the handshake.
+		agentConn, err := net.Dial("unix", c.agentSock)
+		if err != nil {
+			return nil, &ConnectionError{Op: "auth", Machine: c.name, Err: fmt.Errorf("connecting to SSH agent: %w", err)}
+		}
+		defer func() { _ = agentConn.Close() }()
+		withAgent := *c.config
+		withAgent.Auth = []ssh.AuthMethod{ssh.PublicKeysCallback(agent.NewClient(agentConn).Signers)}
+		config = &withAgent
+	}
+	client, err := ssh.Dial("tcp", c.addr, config)
+	if err != nil {
+		return nil, &ConnectionError{Op: "connect", Machine: c.name, Err: err}
+	}
+	c.client = client
+	return client, nil
+}
+
+// dropClient forgets a client whose transport failed so the next call redials.
+func (c *SSHConnection) dropClient(client *ssh.Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client == client {
+		_ = c.client.Close()
+		c.client = nil
+	}
+}
+
+// run executes a shell command on the remote host. A non-zero exit status is
+// returned as *ssh.ExitError with stdout and stderr still populated.
+func (c *SSHConnection) run(command string, stdin []byte) (stdout, stderr []byte, err error) {
+	client, err := c.dial()
+	if err != nil {
+		return nil, nil, err
+	}
+	sess, err := client.NewSession()
+	if err != nil {
+		c.dropClient(client)
+		return nil, nil, &ConnectionError{Op: "session", Machine: c.name, Err: err}
+	}
+	defer func() { _ = sess.Close() }()
+
+	var out, errOut bytes.Buffer
+	sess.Stdout = &out
+	sess.Stderr = &errOut
+	if stdin != nil {
+		sess.Stdin = bytes.NewReader(stdin)
+	}
+	err = sess.Run(command)
+	var exitErr *ssh.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		c.dropClient(client)
+		return out.Bytes(), errOut.Bytes(), &ConnectionError{Op: "exec", Machine: c.name, Err: err}
+	}
+	return out.Bytes(), errOut.Bytes(), err
+}
+
+// runFileOp runs a file command and maps failures to NotFoundError and
+// PermissionError like LocalConnection does.
+func (c *SSHConnection) runFileOp(op, path, command string, stdin []byte) ([]byte, error) {
+	out, errOut, err := c.run(command, stdin)
+	if err == nil {
+		return out, nil
+	}
+	var exitErr *ssh.ExitError
+	if !errors.As(err, &exitErr) {
+		return nil, err
+	}
+	msg := strings.TrimSpace(string(errOut))
+	switch {
+	case strings.Contains(msg, "No such file"):
+		return nil, &NotFoundError{Path: path}
+	case strings.Contains(msg, "Permission denied"):
+		return nil, &PermissionError{Path: path, Op: op}
+	}
+	return nil, fmt.Errorf("%s %s on %s: %s", op, path, c.name, msg)
+}
+
+// ReadFile reads the named file.
+func (c *SSHConnection) ReadFile(path string) ([]byte, error) {
+	return c.runFileOp("read", path, "cat -- "+shellQuote(path), nil)
+}
+
+// WriteFile writes data to the named file, replacing it atomically.
+func (c *SSHConnection) WriteFile(path string, data []byte, perm fs.FileMode) error {
+	tmp := shellQuote(path + ".gt-tmp")
+	cmd := fmt.Sprintf("cat > %s && chmod %o %s && mv -f %s %s", tmp, perm.Perm(), tmp, tmp, shellQuote(path))
+	_, err := c.runFileOp("write", path, cmd, data)
+	return err
+}
+
+// MkdirAll creates a directory and all parent directories.
+func (c *SSHConnection) MkdirAll(path string, perm fs.FileMode) error {
+	_, err := c.runFileOp("mkdir", path, fmt.Sprintf("mkdir -p -m %o -- %s", perm.Perm(), shellQuote(path)), nil)
+	return err
+}
+
+// Remove removes the named file or empty directory. A missing path is not an error.
+func (c *SSHConnection) Remove(path string) error {
+	q := shellQuote(path)
+	cmd := fmt.Sprintf("if [ -d %s ] && [ ! -L %s ]; then rmdir -- %s; else rm -f -- %s; fi", q, q, q, q)
+	_, err := c.runFileOp("remove", path, cmd, nil)
+	return err
+}
+
+// RemoveAll removes the named file or directory and any children.
+func (c *SSHConnection) RemoveAll(path string) error {
+	_, err := c.runFileOp("remove", path, "rm -rf -- "+shellQuote(path), nil)
+	return err
+}
+
+// Stat returns file info for the named file. Works with GNU and BSD stat.
+func (c *SSHConnection) Stat(path string) (FileInfo, error) {
+	q := shellQuote(path)
+	cmd := fmt.Sprintf("stat -c '%%s %%f %%Y' -- %s 2>/dev/null || stat -f '%%z %%Xp %%m' -- %s", q, q)
+	out, err := c.runFileOp("stat", path, cmd, nil)
+	if err != nil {
+		return nil, err
+	}
+	return parseStatOutput(path, string(out))
+}
+
+// parseStatOutput parses "<size> <raw mode in hex> <mtime unix>".
+func parseStatOutput(path, out string) (FileInfo, error) {
+	fields := strings.Fields(out)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("unexpected stat output for %s: %q", path, out)
+	}
+	size, err1 := strconv.ParseInt(fields[0], 10, 64)
+	raw, err2 := strconv.ParseUint(fields[1], 16, 32)
+	mtime, err3 := strconv.ParseInt(fields[2], 10, 64)
+	if err := errors.Join(err1, err2, err3); err != nil {
+		return nil, fmt.Errorf("parsing stat output for %s: %w", path, err)
+	}
+
+	const typeMask, typeDir, typeLink = 0o170000, 0o040000, 0o120000
+	mode := fs.FileMode(raw & 0o777)
+	switch raw & typeMask {
+	case typeDir:
+		mode |= fs.ModeDir
+	case typeLink:
+		mode |= fs.ModeSymlink
+	}
+	return BasicFileInfo{
+		FileName:    filepath.Base(path),
+		FileSize:    size,
+		FileMode:    mode,
+		FileModTime: time.Unix(mtime, 0),
+		FileIsDir:   mode.IsDir(),
+	}, nil
+}
+
+// Glob returns the names of all files matching the pattern, expanded by the
+// remote shell.
+func (c *SSHConnection) Glob(pattern string) ([]string, error) {
+	cmd := fmt.Sprintf(`for f in %s; do [ -e "$f" ] && printf '%%s\n' "$f"; done; true`, globQuote(pattern))
+	out, _, err := c.run(cmd, nil)
+	if err != nil {
+		return nil, err
+	}
+	matches := splitLines(string(out))
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// Exists returns true if the path exists.
+func (c *SSHConnection) Exists(path string) (bool, error) {
+	_, _, err := c.run("test -e "+shellQuote(path), nil)
+	if err == nil {
+		return true, nil
+	}
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		return false, nil
+	}
+	return false, err
+}
+
+// Exec runs a command and returns its combined output.
+func (c *SSHConnection) Exec(cmd string, args ...string) ([]byte, error) {
+	return c.execCombined(shellJoin(cmd, args))
+}
+
+// ExecDir runs a command in the specified directory.
+func (c *SSHConnection) ExecDir(dir, cmd string, args ...string) ([]byte, error) {
+	return c.execCombined("cd " + shellQuote(dir) + " && " + shellJoin(cmd, args))
+}
+
+// ExecEnv runs a command with additional environment variables.
+func (c *SSHConnection) ExecEnv(env map[string]string, cmd string, args ...string) ([]byte, error) {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	sb.WriteString("env")
+	for _, k := range keys {
+		sb.WriteString(" " + shellQuote(k+"="+env[k]))
+	}
+	return c.execCombined(sb.String() + " " + shellJoin(cmd, args))
+}
+
+// execCombined runs a command with stderr merged into stdout, like
+// exec.Cmd.CombinedOutput. Exit failures are returned with the output.
+func (c *SSHConnection) execCombined(command string) ([]byte, error) {
+	out, _, err := c.run("exec 2>&1; "+command, nil)
+	return out, err
+}
+
+// TmuxNewSession creates a new detached tmux session on the remote host.
+func (c *SSHConnection) TmuxNewSession(name, dir string) error {
+	args := []string{"new-session", "-d", "-s", name}
+	if dir != "" {
+		args = append(args, "-c", dir)
+	}
+	return c.tmuxErr(c.Exec("tmux", args...))
+}
+
+// TmuxKillSession terminates a tmux session and the processes in it. The
+// pane's process group is signalled first so agents are not left orphaned.
+func (c *SSHConnection) TmuxKillSession(name string) error {
+	q := shellQuote(name)
+	cmd := fmt.Sprintf("for p in $(tmux list-panes -s -t %s -F '#{pane_pid}' 2>/dev/null); do "+
+		"pkill -TERM -P \"$p\" 2>/dev/null; kill -TERM \"$p\" 2>/dev/null; done; tmux kill-session -t %s", q, q)
+	return c.tmuxErr(c.execCombined(cmd))
+}
+
+// TmuxSendKeys sends literal keys to a tmux session, followed by Enter.
+func (c *SSHConnection) TmuxSendKeys(session, keys string) error {
+	q := shellQuote(session)
+	cmd := fmt.Sprintf("tmux send-keys -t %s -l %s && sleep 0.1 && tmux send-keys -t %s Enter", q, shellQuote(keys), q)
+	return c.tmuxErr(c.execCombined(cmd))
+}
+
+// TmuxCapturePane captures the last N lines from a tmux pane.
+func (c *SSHConnection) TmuxCapturePane(session string, lines int) (string, error) {
+	out, err := c.Exec("tmux", "capture-pane", "-p", "-t", session, "-S", fmt.Sprintf("-%d", lines))
+	if err != nil {
+		return "", c.tmuxErr(out, err)
+	}
+	return string(out), nil
+}
+
+// TmuxHasSession returns true if the session exists. A missing session or
+// tmux server is (false, nil); any other failure is returned as an error
+// rather than read as "no session".
+func (c *SSHConnection) TmuxHasSession(name string) (bool, error) {
+	out, err := c.Exec("tmux", "has-session", "-t", "="+name)
+	if err == nil {
+		return true, nil
+	}
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitStatus() == 1 && tmuxNoSession(string(out)) {
+		return false, nil
+	}
+	return false, c.tmuxErr(out, err)
+}
+
+// TmuxListSessions returns all tmux session names. No tmux server means no
+// sessions.
+func (c *SSHConnection) TmuxListSessions() ([]string, error) {
+	out, err := c.Exec("tmux", "list-sessions", "-F", "#{session_name}")
+	if err != nil {
+		var exitErr *ssh.ExitError
+		msg := string(out)
+		if errors.As(err, &exitErr) && (strings.Contains(msg, "no server running") || strings.Contains(msg, "error connecting")) {
+			return nil, nil
+		}
+		return nil, c.tmuxErr(out, err)
+	}
+	return splitLines(string(out)), nil
+}
+
+// tmuxNoSession reports whether tmux's output says the session does not
+// exist, as opposed to tmux itself failing (not installed, bad socket).
+func tmuxNoSession(out string) bool {
+	return strings.Contains(out, "can't find session") ||
+		strings.Contains(out, "no server running") ||
+		strings.Contains(out, "error connecting")
+}
+
+// tmuxErr folds tmux's output into a failed command's error.
+func (c *SSHConnection) tmuxErr(out []byte, err error) error {
+	if err == nil {
+		return nil
+	}
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("tmux on %s: %s", c.name, strings.TrimSpace(string(out)))
+	}
+	return err
+}
+
+// shellQuote single-quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// globQuote quotes everything in a glob pattern except the wildcards, so the
+// remote shell expands the pattern but nothing else.
+func globQuote(pattern string) string {
+	var sb, lit strings.Builder
+	flush := func() {
+		if lit.Len() > 0 {
+			sb.WriteString(shellQuote(lit.String()))
+			lit.Reset()
+		}
+	}
+	for _, r := range pattern {
+		switch r {
+		case '*', '?', '[', ']':
+			flush()
+			sb.WriteRune(r)
+		default:
+			lit.WriteRune(r)
+		}
+	}
+	flush()
+	return sb.String()
+}
+
+func shellJoin(cmd string, args []string) string {
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, shellQuote(cmd))
+	for _, a := range args {
+		parts = append(parts, shellQuote(a))
+	}
+	return strings.Join(parts, " ")
+}
+
+func splitLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Verify SSHConnection implements Connection.
+var _ Connection = (*SSHConnection)(nil)
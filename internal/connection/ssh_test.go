@@ -0,0 +1,405 @@
+package connection
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// testSSHServer is an in-process SSH server that runs exec requests with the
+// local sh, so SSHConnection can be exercised against real files.
+type testSSHServer struct {
+	addr    string
+	hostKey string // authorized_keys line
+	keyPath string // client private key
+	env     []string
+}
+
+func newTestSSHServer(t *testing.T) *testSSHServer {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	_, hostPriv, _ := ed25519.GenerateKey(rand.Reader)
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientPub, clientPriv, _ := ed25519.GenerateKey(rand.Reader)
+	authorized, err := ssh.NewPublicKey(clientPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(clientPriv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) == string(authorized.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("unknown key")
+		},
+	}
+	cfg.AddHostKey(hostSigner)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	srv := &testSSHServer{
+		addr:    ln.Addr().String(),
+		hostKey: string(ssh.MarshalAuthorizedKey(hostSigner.PublicKey())),
+		keyPath: keyPath,
+	}
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go srv.serve(nc, cfg)
+		}
+	}()
+	return srv
+}
+
+func (s *testSSHServer) serve(nc net.Conn, cfg *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(nc, cfg)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for nch := range chans {
+		if nch.ChannelType() != "session" {
+			_ = nch.Reject(ssh.UnknownChannelType, "session only")
+			continue
+		}
+		ch, chReqs, err := nch.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			defer ch.Close()
+			for req := range chReqs {
+				if req.Type != "exec" {
+					_ = req.Reply(false, nil)
+					continue
+				}
+				var payload struct{ Command string }
+				_ = ssh.Unmarshal(req.Payload, &payload)
+				_ = req.Reply(true, nil)
+
+				cmd := exec.Command("sh", "-c", payload.Command)
+				cmd.Env = append(os.Environ(), s.env...)
+				cmd.Stdin, cmd.Stdout, cmd.Stderr = ch, ch, ch.Stderr()
+				status := uint32(0)
+				if err := cmd.Run(); err != nil {
+					status = 1
+					var exitErr *exec.ExitError
+					if errors.As(err, &exitErr) {
+						status = uint32(exitErr.ExitCode())
+					}
+				}
+				var buf [4]byte
+				binary.BigEndian.PutUint32(buf[:], status)
+				_, _ = ch.SendRequest("exit-status", false, buf[:])
+				return
+			}
+		}()
+	}
+}
+
+func (s *testSSHServer) connect(t *testing.T) *SSHConnection {
+	t.Helper()
+	c, err := NewSSHConnection(&Machine{
+		Name:    "vm",
+		Type:    "ssh",
+		Host:    "agent@" + s.addr,
+		KeyPath: s.keyPath,
+		HostKey: s.hostKey,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestSSHConnection_FileOps(t *testing.T) {
+	c := newTestSSHServer(t).connect(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sub dir", "it's.txt")
+
+	if c.IsLocal() || c.Name() != "vm" {
+		t.Errorf("Name/IsLocal = %q/%v", c.Name(), c.IsLocal())
+	}
+	if err := c.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := c.WriteFile(path, []byte("hello\nworld"), 0640); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	data, err := c.ReadFile(path)
+	if err != nil || string(data) != "hello\nworld" {
+		t.Fatalf("ReadFile = %q, %v", data, err)
+	}
+
+	fi, err := c.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if fi.Size() != 11 || fi.IsDir() || fi.Mode().Perm() != 0640 || fi.Name() != "it's.txt" {
+		t.Errorf("Stat = size %d dir %v mode %v name %q", fi.Size(), fi.IsDir(), fi.Mode(), fi.Name())
+	}
+	if fi, err := c.Stat(filepath.Dir(path)); err != nil || !fi.IsDir() {
+		t.Errorf("Stat(dir) = %v, %v", fi, err)
+	}
+
+	if ok, err := c.Exists(path); !ok || err != nil {
+		t.Errorf("Exists = %v, %v", ok, err)
+	}
+	matches, err := c.Glob(filepath.Join(dir, "sub dir", "*.txt"))
+	if err != nil || len(matches) != 1 || matches[0] != path {
+		t.Errorf("Glob = %v, %v", matches, err)
+	}
+
+	var nf *NotFoundError
+	if _, err := c.ReadFile(filepath.Join(dir, "missing")); !errors.As(err, &nf) {
+		t.Errorf("ReadFile(missing) err = %v, want NotFoundError", err)
+	}
+	if _, err := c.Stat(filepath.Join(dir, "missing")); !errors.As(err, &nf) {
+		t.Errorf("Stat(missing) err = %v, want NotFoundError", err)
+	}
+
+	if err := c.Remove(path); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if err := c.Remove(path); err != nil {
+		t.Errorf("Remove(missing) = %v, want nil", err)
+	}
+	if err := c.RemoveAll(filepath.Join(dir, "sub dir")); err != nil {
+		t.Fatalf("RemoveAll: %v", err)
+	}
+	if ok, _ := c.Exists(filepath.Join(dir, "sub dir")); ok {
+		t.Error("directory still exists after RemoveAll")
+	}
+}
+
+func TestSSHConnection_Exec(t *testing.T) {
+	c := newTestSSHServer(t).connect(t)
+	dir := t.TempDir()
+
+	out, err := c.Exec("echo", "a b", "$HOME")
+	if err != nil || string(out) != "a b $HOME\n" {
+		t.Errorf("Exec = %q, %v", out, err)
+	}
+	out, err = c.ExecDir(dir, "pwd")
+	if err != nil || strings.TrimSpace(string(out)) != dir {
+		t.Errorf("ExecDir = %q, %v", out, err)
+	}
+	out, err = c.ExecEnv(map[string]string{"GT_TEST": "x y"}, "sh", "-c", "echo $GT_TEST")
+	if err != nil || string(out) != "x y\n" {
+		t.Errorf("ExecEnv = %q, %v", out, err)
+	}
+	out, err = c.Exec("sh", "-c", "echo oops >&2; exit 3")
+	var exitErr *ssh.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitStatus() != 3 || string(out) != "oops\n" {
+		t.Errorf("failing Exec = %q, %v", out, err)
+	}
+}
+
+func TestSSHConnection_Tmux(t *testing.T) {
+	srv := newTestSSHServer(t)
+	bin := t.TempDir()
+	logPath := filepath.Join(bin, "tmux.log")
+	fakeTmux := `#!/bin/sh
+echo "$@" >> ` + logPath + `
+case "$1" in
+  list-sessions) printf 'gt-toast\nhq-mayor\n' ;;
+  capture-pane) echo "pane output" ;;
+  has-session)
+    case "$3" in
+      =gt-toast) ;;
+      =gt-broken) echo "tmux: permission denied" >&2; exit 1 ;;
+      *) echo "can't find session: ${3#=}" >&2; exit 1 ;;
+    esac ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(bin, "tmux"), []byte(fakeTmux), 0755); err != nil {
+		t.Fatal(err)
+	}
+	srv.env = []string{"PATH=" + bin + string(os.PathListSeparator) + os.Getenv("PATH")}
+	c := srv.connect(t)
+
+	if err := c.TmuxNewSession("gt-toast", "/work"); err != nil {
+		t.Fatalf("TmuxNewSession: %v", err)
+	}
+	sessions, err := c.TmuxListSessions()
+	if err != nil || len(sessions) != 2 || sessions[0] != "gt-toast" {
+		t.Errorf("TmuxListSessions = %v, %v", sessions, err)
+	}
+	if ok, err := c.TmuxHasSession("gt-toast"); !ok || err != nil {
+		t.Errorf("TmuxHasSession(gt-toast) = %v, %v", ok, err)
+	}
+	if ok, err := c.TmuxHasSession("gt-nux"); ok || err != nil {
+		t.Errorf("TmuxHasSession(gt-nux) = %v, %v", ok, err)
+	}
+	// A tmux failure is not "no session".
+	if ok, err := c.TmuxHasSession("gt-broken"); ok || err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("TmuxHasSession(gt-broken) = %v, %v; want permission error", ok, err)
+	}
+	if out, err := c.TmuxCapturePane("gt-toast", 20); err != nil || out != "pane output\n" {
+		t.Errorf("TmuxCapturePane = %q, %v", out, err)
+	}
+	if err := c.TmuxSendKeys("gt-toast", "hello 'there'"); err != nil {
+		t.Errorf("TmuxSendKeys: %v", err)
+	}
+	if err := c.TmuxKillSession("gt-toast"); err != nil {
+		t.Errorf("TmuxKillSession: %v", err)
+	}
+
+	logData, _ := os.ReadFile(logPath)
+	for _, want := range []string{
+		"new-session -d -s gt-toast -c /work",
+		"capture-pane -p -t gt-toast -S -20",
+		"send-keys -t gt-toast -l hello 'there'",
+		"send-keys -t gt-toast Enter",
+		"kill-session -t gt-toast",
+	} {
+		if !strings.Contains(string(logData), want) {
+			t.Errorf("tmux log missing %q:\n%s", want, logData)
+		}
+	}
+}
+
+func TestSSHConnection_AgentAuthClosesAgentConn(t *testing.T) {
+	srv := newTestSSHServer(t)
+	data, err := os.ReadFile(srv.keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.ParseRawPrivateKey(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring := agent.NewKeyring()
+	if err := keyring.Add(agent.AddedKey{PrivateKey: key}); err != nil {
+		t.Fatal(err)
+	}
+
+	sock := filepath.Join(t.TempDir(), "agent.sock")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	closed := make(chan struct{}, 1)
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_ = agent.ServeAgent(keyring, nc)
+				closed <- struct{}{}
+			}()
+		}
+	}()
+	t.Setenv("SSH_AUTH_SOCK", sock)
+
+	c, err := NewSSHConnection(&Machine{Name: "vm", Type: "ssh", Host: srv.addr, HostKey: srv.hostKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if out, err := c.Exec("echo", "ok"); err != nil || string(out) != "ok\n" {
+		t.Fatalf("Exec via agent = %q, %v", out, err)
+	}
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Error("agent connection still open after the handshake")
+	}
+}
+
+func TestSSHConnection_RejectsWrongHostKey(t *testing.T) {
+	srv := newTestSSHServer(t)
+	other := newTestSSHServer(t)
+	c, err := NewSSHConnection(&Machine{Name: "vm", Type: "ssh", Host: srv.addr, KeyPath: srv.keyPath, HostKey: other.hostKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	var connErr *ConnectionError
+	if _, err := c.Exec("true"); !errors.As(err, &connErr) || connErr.Op != "connect" {
+		t.Errorf("Exec with wrong host key = %v, want connect error", err)
+	}
+}
+
+func TestParseSSHHost(t *testing.T) {
+	tests := []struct {
+		host, user, addr string
+		wantErr          bool
+	}{
+		{host: "deploy@build.example.com", user: "deploy", addr: "build.example.com:22"},
+		{host: "deploy@10.0.0.5:2222", user: "deploy", addr: "10.0.0.5:2222"},
+		{host: "deploy@[::1]:2200", user: "deploy", addr: "[::1]:2200"},
+		{host: "", wantErr: true},
+		{host: "deploy@host:ssh", wantErr: true},
+	}
+	for _, tt := range tests {
+		u, addr, err := parseSSHHost(tt.host)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSSHHost(%q) err = %v", tt.host, err)
+			continue
+		}
+		if !tt.wantErr && (u != tt.user || addr != tt.addr) {
+			t.Errorf("parseSSHHost(%q) = %q, %q; want %q, %q", tt.host, u, addr, tt.user, tt.addr)
+		}
+	}
+}
+
+func TestRegistryConnectionSSH(t *testing.T) {
+	srv := newTestSSHServer(t)
+	r, err := NewMachineRegistry(filepath.Join(t.TempDir(), "machines.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Add(&Machine{Name: "bad:name", Type: "ssh", Host: srv.addr}); err == nil {
+		t.Error("Add accepted a machine name containing ':'")
+	}
+	if err := r.Add(&Machine{Name: "vm", Type: "ssh", Host: srv.addr, KeyPath: srv.keyPath, HostKey: srv.hostKey}); err != nil {
+		t.Fatal(err)
+	}
+	conn, err := r.Connection("vm")
+	if err != nil {
+		t.Fatalf("Connection(vm): %v", err)
+	}
+	if out, err := conn.Exec("echo", "ok"); err != nil || string(out) != "ok\n" {
+		t.Errorf("Exec over registry connection = %q, %v", out, err)
+	}
+	_ = conn.(*SSHConnection).Close()
+}
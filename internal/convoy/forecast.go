@@ -0,0 +1,398 @@
+package convoy
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Forecasting estimates how long a convoy's remaining work will take from the
+// measured durations of similar finished beads, then schedules the tracked
+// beads along their blocking dependencies to find the critical path and the
+// finish time of each wave. Parallelism is assumed to be unlimited: a bead
+// starts as soon as its blockers finish.
+
+// Sample is the measured duration of one finished bead.
+type Sample struct {
+	Rig      string
+	Formula  string
+	Labels   []string
+	Duration time.Duration
+}
+
+// Estimate is a bead's predicted duration: the median and an 80% interval
+// (10th to 90th percentile) of similar samples.
+type Estimate struct {
+	P10     time.Duration
+	P50     time.Duration
+	P90     time.Duration
+	Samples int    // number of samples behind the estimate; 0 for the default
+	Basis   string // which similarity tier matched, e.g. "rig+formula"
+}
+
+// DefaultEstimate is used when there are no samples at all.
+var DefaultEstimate = Estimate{
+	P10:   30 * time.Minute,
+	P50:   time.Hour,
+	P90:   4 * time.Hour,
+	Basis: "default",
+}
+
+// minSamples is how many samples a similarity tier needs before it is used.
+const minSamples = 3
+
+// Estimator predicts bead durations from historical samples.
+type Estimator struct {
+	samples []Sample
+}
+
+// NewEstimator returns an Estimator over samples. Non-positive durations are
+// dropped.
+func NewEstimator(samples []Sample) *Estimator {
+	e := &Estimator{}
+	for _, s := range samples {
+		if s.Duration > 0 {
+			e.samples = append(e.samples, s)
+		}
+	}
+	return e
+}
+
+// estimateTier is one level of similarity, most specific first.
+type estimateTier struct {
+	basis string
+	match func(s Sample, rig, formula string, labels map[string]bool) bool
+}
+
+var estimateTiers = []estimateTier{
+	{"rig+formula+label", func(s Sample, rig, formula string, labels map[string]bool) bool {
+		return s.Rig == rig && s.Formula == formula && sharesLabel(s.Labels, labels)
+	}},
+	{"rig+formula", func(s Sample, rig, formula string, _ map[string]bool) bool {
+		return s.Rig == rig && s.Formula == formula
+	}},
+	{"rig+label", func(s Sample, rig, _ string, labels map[string]bool) bool {
+		return s.Rig == rig && sharesLabel(s.Labels, labels)
+	}},
+	{"formula", func(s Sample, _, formula string, _ map[string]bool) bool {
+		return formula != "" && s.Formula == formula
+	}},
+	{"rig", func(s Sample, rig, _ string, _ map[string]bool) bool {
+		return s.Rig == rig
+	}},
+}
+
+func sharesLabel(labels []string, set map[string]bool) bool {
+	for _, l := range labels {
+		if set[l] {
+			return true
+		}
+	}
+	return false
+}
+
+// Estimate predicts the duration of a bead on rig with the given formula and
+// labels from the most specific tier with at least minSamples samples: same
+// rig, formula and a shared label; same rig and formula; same rig and a shared
+// label; same formula; same rig; and finally every sample. With no samples it
+// returns DefaultEstimate.
+func (e *Estimator) Estimate(rig, formula string, labels []string) Estimate {
+	if len(e.samples) == 0 {
+		return DefaultEstimate
+	}
+	set := make(map[string]bool, len(labels))
+	for _, l := range labels {
+		set[l] = true
+	}
+	for _, tier := range estimateTiers {
+		var durations []time.Duration
+		for _, s := range e.samples {
+			if tier.match(s, rig, formula, set) {
+				durations = append(durations, s.Duration)
+			}
+		}
+		if len(durations) >= minSamples {
+			return estimateFrom(durations, tier.basis)
+		}
+	}
+	all := make([]time.Duration, len(e.samples))
+	for i, s := range e.samples {
+		all[i] = s.Duration
+	}
+	return estimateFrom(all, "all")
+}
+
+func estimateFrom(durations []time.Duration, basis string) Estimate {
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	return Estimate{
+		P10:     quantile(durations, 0.1),
+		P50:     quantile(durations, 0.5),
+		P90:     quantile(durations, 0.9),
+		Samples: len(durations),
+		Basis:   basis,
+	}
+}
+
+// quantile returns the q-th quantile of sorted, interpolating linearly
+// between the closest ranks.
+func quantile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	pos := q * float64(len(sorted)-1)
+	lo := int(pos)
+	if lo >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	frac := pos - float64(lo)
+	return sorted[lo] + time.Duration(frac*float64(sorted[lo+1]-sorted[lo]))
+}
+
+// Task is one tracked bead to forecast.
+type Task struct {
+	ID        string
+	Rig       string
+	Formula   string
+	Labels    []string
+	Status    string
+	StartedAt time.Time // when work began; zero if not started
+	BlockedBy []string  // blocking beads; IDs outside the task set are ignored
+}
+
+func (t Task) done() bool {
+	return t.Status == "closed" || t.Status == "tombstone"
+}
+
+// TaskForecast is the schedule of one task. Finish times are absolute; a
+// closed task finishes at the forecast's Now with no remaining time.
+type TaskForecast struct {
+	ID         string
+	Estimate   Estimate
+	Remaining  time.Duration // median remaining work
+	Overdue    bool          // started and already past its 90th percentile
+	Start      time.Time
+	Finish     time.Time
+	FinishLow  time.Time
+	FinishHigh time.Time
+	Slack      time.Duration // how long the task can slip without delaying the convoy
+	Critical   bool
+}
+
+// WaveForecast is when a wave's last task is expected to finish.
+type WaveForecast struct {
+	Number     int
+	Tasks      []string
+	Finish     time.Time
+	FinishLow  time.Time
+	FinishHigh time.Time
+}
+
+// Forecast is a convoy's predicted schedule.
+type Forecast struct {
+	Now          time.Time
+	Finish       time.Time
+	FinishLow    time.Time
+	FinishHigh   time.Time
+	CriticalPath []string // the longest chain of open tasks, in execution order
+	Tasks        map[string]*TaskForecast
+	Waves        []WaveForecast
+}
+
+// ForecastTasks schedules tasks from now. Each open task's remaining time is
+// its estimate minus the time already spent on it. Finish is the median
+// schedule; FinishLow and FinishHigh rerun the schedule with every task at
+// its 10th and 90th percentile. waves groups task IDs for per-wave finish
+// times (wave i+1 is waves[i]). Returns an error if the blocking edges
+// between tasks form a cycle.
+func ForecastTasks(tasks []Task, waves [][]string, est *Estimator, now time.Time) (*Forecast, error) {
+	byID := make(map[string]*Task, len(tasks))
+	for i := range tasks {
+		byID[tasks[i].ID] = &tasks[i]
+	}
+	order, err := topoOrder(tasks, byID)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Forecast{Now: now, Tasks: make(map[string]*TaskForecast, len(tasks))}
+	var low, mid, high []time.Duration
+	for _, id := range order {
+		t := byID[id]
+		tf := &TaskForecast{ID: id}
+		var rl, rm, rh time.Duration
+		if !t.done() {
+			tf.Estimate = est.Estimate(t.Rig, t.Formula, t.Labels)
+			var elapsed time.Duration
+			if !t.StartedAt.IsZero() && now.After(t.StartedAt) {
+				elapsed = now.Sub(t.StartedAt)
+			}
+			rl, rm, rh = remaining(tf.Estimate.P10, elapsed), remaining(tf.Estimate.P50, elapsed), remaining(tf.Estimate.P90, elapsed)
+			tf.Overdue = elapsed > 0 && elapsed > tf.Estimate.P90
+		}
+		tf.Remaining = rm
+		f.Tasks[id] = tf
+		low, mid, high = append(low, rl), append(mid, rm), append(high, rh)
+	}
+
+	efLow := earliestFinish(order, byID, low)
+	efMid := earliestFinish(order, byID, mid)
+	efHigh := earliestFinish(order, byID, high)
+
+	var makespan, makespanLow, makespanHigh time.Duration
+	for i := range order {
+		makespan = max(makespan, efMid[i])
+		makespanLow = max(makespanLow, efLow[i])
+		makespanHigh = max(makespanHigh, efHigh[i])
+	}
+	f.Finish, f.FinishLow, f.FinishHigh = now.Add(makespan), now.Add(makespanLow), now.Add(makespanHigh)
+
+	// Backward pass over the median schedule: latest finish without
+	// delaying the convoy.
+	index := make(map[string]int, len(order))
+	for i, id := range order {
+		index[id] = i
+	}
+	latest := make([]time.Duration, len(order))
+	for i := range latest {
+		latest[i] = makespan
+	}
+	for i := len(order) - 1; i >= 0; i-- {
+		for _, b := range byID[order[i]].BlockedBy {
+			if j, ok := index[b]; ok {
+				latest[j] = min(latest[j], latest[i]-mid[i])
+			}
+		}
+	}
+	for i, id := range order {
+		tf := f.Tasks[id]
+		tf.Start = now.Add(efMid[i] - mid[i])
+		tf.Finish, tf.FinishLow, tf.FinishHigh = now.Add(efMid[i]), now.Add(efLow[i]), now.Add(efHigh[i])
+		tf.Slack = latest[i] - efMid[i]
+		tf.Critical = !byID[id].done() && tf.Slack == 0 && mid[i] > 0
+	}
+	f.CriticalPath = criticalPath(order, byID, f.Tasks, makespan, now)
+
+	for n, ids := range waves {
+		w := WaveForecast{Number: n + 1, Tasks: ids, Finish: now, FinishLow: now, FinishHigh: now}
+		for _, id := range ids {
+			tf := f.Tasks[id]
+			if tf == nil {
+				continue
+			}
+			w.Finish = maxTime(w.Finish, tf.Finish)
+			w.FinishLow = maxTime(w.FinishLow, tf.FinishLow)
+			w.FinishHigh = maxTime(w.FinishHigh, tf.FinishHigh)
+		}
+		f.Waves = append(f.Waves, w)
+	}
+	return f, nil
+}
+
+// remaining is the estimate left after elapsed. A task past its estimate is
+// expected to finish now.
+func remaining(estimate, elapsed time.Duration) time.Duration {
+	if elapsed >= estimate {
+		return 0
+	}
+	return estimate - elapsed
+}
+
+// topoOrder returns task IDs with every task after its blockers, breaking
+// ties by ID for deterministic output.
+func topoOrder(tasks []Task, byID map[string]*Task) ([]string, error) {
+	inDegree := make(map[string]int, len(tasks))
+	blocks := make(map[string][]string)
+	for _, t := range tasks {
+		inDegree[t.ID] += 0
+		for _, b := range t.BlockedBy {
+			if _, ok := byID[b]; ok && b != t.ID {
+				inDegree[t.ID]++
+				blocks[b] = append(blocks[b], t.ID)
+			}
+		}
+	}
+	var ready []string
+	for id, d := range inDegree {
+		if d == 0 {
+			ready = append(ready, id)
+		}
+	}
+	var order []string
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		id := ready[0]
+		ready = ready[1:]
+		order = append(order, id)
+		for _, next := range blocks[id] {
+			inDegree[next]--
+			if inDegree[next] == 0 {
+				ready = append(ready, next)
+			}
+		}
+	}
+	if len(order) != len(inDegree) {
+		return nil, fmt.Errorf("dependency cycle among %d tracked beads", len(inDegree)-len(order))
+	}
+	return order, nil
+}
+
+// earliestFinish returns, for each task in order, the time from now at which
+// it finishes if it starts once all its blockers have finished.
+func earliestFinish(order []string, byID map[string]*Task, durations []time.Duration) []time.Duration {
+	index := make(map[string]int, len(order))
+	ef := make([]time.Duration, len(order))
+	for i, id := range order {
+		var start time.Duration
+		for _, b := range byID[id].BlockedBy {
+			if j, ok := index[b]; ok {
+				start = max(start, ef[j])
+			}
+		}
+		ef[i] = start + durations[i]
+		index[id] = i
+	}
+	return ef
+}
+
+// criticalPath walks back from the open task that finishes last, through the
+// blocker that finishes last, until it reaches a task with no open blockers.
+func criticalPath(order []string, byID map[string]*Task, tasks map[string]*TaskForecast, makespan time.Duration, now time.Time) []string {
+	if makespan == 0 {
+		return nil
+	}
+	end := now.Add(makespan)
+	var cur string
+	for _, id := range order {
+		if tasks[id].Critical && tasks[id].Finish.Equal(end) {
+			cur = id
+			break
+		}
+	}
+	var path []string
+	for cur != "" {
+		path = append(path, cur)
+		start := tasks[cur].Start
+		next := ""
+		for _, b := range byID[cur].BlockedBy {
+			bf, ok := tasks[b]
+			if !ok || !bf.Critical || !bf.Finish.Equal(start) {
+				continue
+			}
+			if next == "" || b < next {
+				next = b
+			}
+		}
+		cur = next
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,109 @@
+package convoy
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// WorkSpan is when work on a bead started (first sling or hook) and finished
+// (last done), from the town events log. Either may be zero.
+type WorkSpan struct {
+	Start time.Time
+	End   time.Time
+}
+
+// LoadWorkSpans reads the work span of every bead in townRoot's events log.
+// A missing log yields no spans.
+func LoadWorkSpans(townRoot string) (map[string]WorkSpan, error) {
+	spans := make(map[string]WorkSpan)
+	f, err := os.Open(filepath.Join(townRoot, events.EventsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return spans, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var ev events.Event
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			continue
+		}
+		addWorkSpanEvent(spans, ev)
+	}
+	return spans, sc.Err()
+}
+
+func addWorkSpanEvent(spans map[string]WorkSpan, ev events.Event) {
+	bead, _ := ev.Payload["bead"].(string)
+	if bead == "" {
+		return
+	}
+	at, err := time.Parse(time.RFC3339, ev.Timestamp)
+	if err != nil {
+		return
+	}
+	span := spans[bead]
+	switch ev.Type {
+	case events.TypeSling, events.TypeHook:
+		if span.Start.IsZero() || at.Before(span.Start) {
+			span.Start = at
+		}
+	case events.TypeDone:
+		if at.After(span.End) {
+			span.End = at
+		}
+	default:
+		return
+	}
+	spans[bead] = span
+}
+
+// SamplesFromIssues turns closed issues into duration samples. A sample runs
+// from the bead's work span start to its done event, or to the issue's
+// closed_at when there is no done event. Issues that were never slung or
+// hooked have no measurable work time and are skipped. rigOf maps an issue
+// ID to its rig.
+func SamplesFromIssues(issues []*beads.Issue, spans map[string]WorkSpan, rigOf func(id string) string) []Sample {
+	var samples []Sample
+	for _, issue := range issues {
+		if issue.Status != "closed" {
+			continue
+		}
+		span, ok := spans[issue.ID]
+		if !ok || span.Start.IsZero() {
+			continue
+		}
+		end := span.End
+		if end.IsZero() {
+			closed, err := time.Parse(time.RFC3339, issue.ClosedAt)
+			if err != nil {
+				continue
+			}
+			end = closed
+		}
+		if !end.After(span.Start) {
+			continue
+		}
+		formula := ""
+		if fields := beads.ParseAttachmentFields(issue); fields != nil {
+			formula = fields.AttachedFormula
+		}
+		samples = append(samples, Sample{
+			Rig:      rigOf(issue.ID),
+			Formula:  formula,
+			Labels:   issue.Labels,
+			Duration: end.Sub(span.Start),
+		})
+	}
+	return samples
+}
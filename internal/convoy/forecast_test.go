@@ -0,0 +1,183 @@
+package convoy
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+var forecastNow = time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+func minutes(n int) time.Duration { return time.Duration(n) * time.Minute }
+
+func TestEstimator_Tiers(t *testing.T) {
+	var samples []Sample
+	add := func(n int, s Sample) {
+		for i := 0; i < n; i++ {
+			samples = append(samples, s)
+		}
+	}
+	add(3, Sample{Rig: "gastown", Formula: "mol-polecat-work", Labels: []string{"ui"}, Duration: minutes(20)})
+	add(3, Sample{Rig: "gastown", Formula: "mol-polecat-work", Labels: []string{"db"}, Duration: minutes(90)})
+	add(2, Sample{Rig: "beads", Formula: "mol-review", Duration: minutes(10)})
+	add(3, Sample{Rig: "beads", Duration: minutes(40)})
+	est := NewEstimator(samples)
+
+	tests := []struct {
+		name    string
+		rig     string
+		formula string
+		labels  []string
+		basis   string
+		p50     time.Duration
+	}{
+		{"rig, formula and label", "gastown", "mol-polecat-work", []string{"ui"}, "rig+formula+label", minutes(20)},
+		{"rig and formula", "gastown", "mol-polecat-work", []string{"docs"}, "rig+formula", minutes(55)},
+		{"too few for rig and formula", "beads", "mol-review", nil, "rig", minutes(40)},
+		{"formula on another rig", "wasteland", "mol-polecat-work", nil, "formula", minutes(55)},
+		{"nothing similar", "wasteland", "", nil, "all", minutes(40)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := est.Estimate(tt.rig, tt.formula, tt.labels)
+			if got.Basis != tt.basis || got.P50 != tt.p50 {
+				t.Errorf("Estimate = %s P50 %v, want %s P50 %v", got.Basis, got.P50, tt.basis, tt.p50)
+			}
+			if got.P10 > got.P50 || got.P50 > got.P90 {
+				t.Errorf("quantiles out of order: %v %v %v", got.P10, got.P50, got.P90)
+			}
+		})
+	}
+
+	if got := NewEstimator(nil).Estimate("gastown", "", nil); got != DefaultEstimate {
+		t.Errorf("no samples: Estimate = %+v, want DefaultEstimate", got)
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	sorted := []time.Duration{minutes(10), minutes(20), minutes(30), minutes(40), minutes(50)}
+	for q, want := range map[float64]time.Duration{0: minutes(10), 0.5: minutes(30), 0.9: minutes(46), 1: minutes(50)} {
+		if got := quantile(sorted, q); got != want {
+			t.Errorf("quantile(%v) = %v, want %v", q, got, want)
+		}
+	}
+}
+
+// fixedEstimator returns an estimator where every bead takes exactly d.
+func fixedEstimator(d time.Duration) *Estimator {
+	return NewEstimator([]Sample{{Duration: d}, {Duration: d}, {Duration: d}})
+}
+
+func TestForecastTasks_CriticalPath(t *testing.T) {
+	// a → b → d is 3h; a → c → d is also 3h but c is done, so only the
+	// chain through b is critical. e is independent and has slack.
+	tasks := []Task{
+		{ID: "a", Status: "open"},
+		{ID: "b", Status: "open", BlockedBy: []string{"a"}},
+		{ID: "c", Status: "closed", BlockedBy: []string{"a"}},
+		{ID: "d", Status: "open", BlockedBy: []string{"b", "c"}},
+		{ID: "e", Status: "open"},
+	}
+	waves := [][]string{{"a", "e"}, {"b", "c"}, {"d"}}
+	fc, err := ForecastTasks(tasks, waves, fixedEstimator(time.Hour), forecastNow)
+	if err != nil {
+		t.Fatalf("ForecastTasks: %v", err)
+	}
+
+	if want := forecastNow.Add(3 * time.Hour); !fc.Finish.Equal(want) {
+		t.Errorf("Finish = %v, want %v", fc.Finish, want)
+	}
+	if got := strings.Join(fc.CriticalPath, ","); got != "a,b,d" {
+		t.Errorf("CriticalPath = %s, want a,b,d", got)
+	}
+	if fc.Tasks["e"].Critical || fc.Tasks["e"].Slack != 2*time.Hour {
+		t.Errorf("e = critical %v slack %v, want not critical with 2h slack", fc.Tasks["e"].Critical, fc.Tasks["e"].Slack)
+	}
+	if fc.Tasks["c"].Critical || fc.Tasks["c"].Remaining != 0 {
+		t.Errorf("closed c = %+v, want no remaining time and not critical", fc.Tasks["c"])
+	}
+
+	wantWaves := []time.Duration{time.Hour, 2 * time.Hour, 3 * time.Hour}
+	for i, w := range fc.Waves {
+		if w.Number != i+1 || !w.Finish.Equal(forecastNow.Add(wantWaves[i])) {
+			t.Errorf("wave %d finishes %v, want %v", w.Number, w.Finish, forecastNow.Add(wantWaves[i]))
+		}
+	}
+}
+
+func TestForecastTasks_IntervalAndInProgress(t *testing.T) {
+	est := NewEstimator([]Sample{
+		{Duration: minutes(30)}, {Duration: minutes(60)}, {Duration: minutes(60)},
+		{Duration: minutes(120)}, {Duration: minutes(240)},
+	})
+	tasks := []Task{
+		{ID: "started", Status: "in_progress", StartedAt: forecastNow.Add(-30 * time.Minute)},
+		{ID: "stuck", Status: "hooked", StartedAt: forecastNow.Add(-10 * time.Hour)},
+		{ID: "next", Status: "open", BlockedBy: []string{"started"}},
+	}
+	fc, err := ForecastTasks(tasks, [][]string{{"started", "stuck"}, {"next"}}, est, forecastNow)
+	if err != nil {
+		t.Fatalf("ForecastTasks: %v", err)
+	}
+
+	if got := fc.Tasks["started"].Remaining; got != 30*time.Minute {
+		t.Errorf("started remaining = %v, want 30m", got)
+	}
+	if s := fc.Tasks["stuck"]; s.Remaining != 0 || !s.Overdue {
+		t.Errorf("stuck = remaining %v overdue %v, want 0 and overdue", s.Remaining, s.Overdue)
+	}
+	if !fc.FinishLow.Before(fc.Finish) || !fc.Finish.Before(fc.FinishHigh) {
+		t.Errorf("interval not ordered: %v %v %v", fc.FinishLow, fc.Finish, fc.FinishHigh)
+	}
+	// P10 42m, P50 60m: started has 12m/30m left, then next runs.
+	if want := forecastNow.Add(54 * time.Minute); !fc.FinishLow.Equal(want) {
+		t.Errorf("FinishLow = %v, want %v", fc.FinishLow, want)
+	}
+	if want := forecastNow.Add(90 * time.Minute); !fc.Finish.Equal(want) {
+		t.Errorf("Finish = %v, want %v", fc.Finish, want)
+	}
+}
+
+func TestForecastTasks_Cycle(t *testing.T) {
+	tasks := []Task{
+		{ID: "a", Status: "open", BlockedBy: []string{"b"}},
+		{ID: "b", Status: "open", BlockedBy: []string{"a"}},
+	}
+	if _, err := ForecastTasks(tasks, nil, fixedEstimator(time.Hour), forecastNow); err == nil {
+		t.Error("expected an error for a dependency cycle")
+	}
+}
+
+func TestSamplesFromIssues(t *testing.T) {
+	spans := make(map[string]WorkSpan)
+	for _, ev := range []events.Event{
+		{Timestamp: "2026-03-01T10:00:00Z", Type: events.TypeSling, Payload: map[string]interface{}{"bead": "gt-1"}},
+		{Timestamp: "2026-03-01T10:05:00Z", Type: events.TypeHook, Payload: map[string]interface{}{"bead": "gt-1"}},
+		{Timestamp: "2026-03-01T11:30:00Z", Type: events.TypeDone, Payload: map[string]interface{}{"bead": "gt-1"}},
+		{Timestamp: "2026-03-01T09:00:00Z", Type: events.TypeHook, Payload: map[string]interface{}{"bead": "gt-2"}},
+		{Timestamp: "2026-03-01T09:00:00Z", Type: events.TypeMail, Payload: map[string]interface{}{"bead": "gt-3"}},
+	} {
+		addWorkSpanEvent(spans, ev)
+	}
+
+	issues := []*beads.Issue{
+		{ID: "gt-1", Status: "closed", Labels: []string{"ui"}, Description: "attached_formula: mol-polecat-work"},
+		{ID: "gt-2", Status: "closed", ClosedAt: "2026-03-01T09:45:00Z"},
+		{ID: "gt-3", Status: "closed", ClosedAt: "2026-03-01T10:00:00Z"}, // never slung
+		{ID: "gt-4", Status: "open"},
+	}
+	samples := SamplesFromIssues(issues, spans, func(string) string { return "gastown" })
+
+	if len(samples) != 2 {
+		t.Fatalf("got %d samples, want 2: %+v", len(samples), samples)
+	}
+	if s := samples[0]; s.Duration != 90*time.Minute || s.Formula != "mol-polecat-work" || s.Rig != "gastown" {
+		t.Errorf("gt-1 sample = %+v, want 90m of mol-polecat-work on gastown", s)
+	}
+	if s := samples[1]; s.Duration != 45*time.Minute {
+		t.Errorf("gt-2 sample = %v, want 45m (hook to closed_at)", s.Duration)
+	}
+}
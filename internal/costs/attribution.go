@@ -0,0 +1,277 @@
+package costs
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// Entry is one session's cost, as recorded in the costs log or measured
+// from a live session.
+type Entry struct {
+	Session  string
+	Agent    string // agent address (e.g. "gastown/polecats/toast"); empty for town roles
+	WorkItem string // bead the session worked on; empty if unknown
+	USD      float64
+	EndedAt  time.Time
+}
+
+// AgentForSession returns the agent address that hooks work for a session,
+// or "" for roles that do not hook beads (mayor, deacon, witness, refinery).
+func AgentForSession(role, rig, worker string) string {
+	if rig == "" || worker == "" {
+		return ""
+	}
+	switch role {
+	case constants.RolePolecat:
+		return rig + "/polecats/" + worker
+	case constants.RoleCrew:
+		return rig + "/crew/" + worker
+	}
+	return ""
+}
+
+// hookEvent is a change to an agent's hook.
+type hookEvent struct {
+	bead string // empty when the hook was released
+	at   time.Time
+}
+
+// History is the hook history of each agent, oldest event first, taken from
+// the sling, hook and unhook events in the town events log.
+type History map[string][]hookEvent
+
+// LoadHistory reads hook history from townRoot's events log. A missing log
+// yields an empty history.
+func LoadHistory(townRoot string) (History, error) {
+	f, err := os.Open(filepath.Join(townRoot, events.EventsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return History{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	h := History{}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var ev events.Event
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			continue
+		}
+		h.add(ev)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	for agent := range h {
+		sort.SliceStable(h[agent], func(i, j int) bool { return h[agent][i].at.Before(h[agent][j].at) })
+	}
+	return h, nil
+}
+
+// add records ev if it changes an agent's hook.
+func (h History) add(ev events.Event) {
+	at, err := time.Parse(time.RFC3339, ev.Timestamp)
+	if err != nil {
+		return
+	}
+	bead, _ := ev.Payload["bead"].(string)
+	var agent string
+	switch ev.Type {
+	case events.TypeSling:
+		agent, _ = ev.Payload["target"].(string)
+	case events.TypeHook:
+		agent = ev.Actor
+	case events.TypeUnhook:
+		agent, bead = ev.Actor, ""
+	default:
+		return
+	}
+	agent = normalizeAgent(agent)
+	if agent == "" || (bead == "" && ev.Type != events.TypeUnhook) {
+		return
+	}
+	h[agent] = append(h[agent], hookEvent{bead: bead, at: at})
+}
+
+// normalizeAgent maps the short polecat form "rig/name" to "rig/polecats/name"
+// so sling targets and hook actors key the same agent.
+func normalizeAgent(agent string) string {
+	agent = strings.TrimSuffix(agent, "/")
+	parts := strings.Split(agent, "/")
+	if len(parts) == 2 {
+		switch parts[1] {
+		case constants.RoleWitness, constants.RoleRefinery, constants.RoleCrew, "polecats":
+			return agent
+		}
+		return parts[0] + "/polecats/" + parts[1]
+	}
+	return agent
+}
+
+// BeadAt returns the bead hooked by agent at time t, or "".
+func (h History) BeadAt(agent string, t time.Time) string {
+	bead := ""
+	for _, ev := range h[normalizeAgent(agent)] {
+		if ev.at.After(t) {
+			break
+		}
+		bead = ev.bead
+	}
+	return bead
+}
+
+// Attribute fills in the work item of entries that have none from their
+// agent's hook history at the time the session ended. A session that moved
+// between beads is charged to the one hooked when it ended. It returns the
+// number of entries attributed.
+func Attribute(entries []Entry, h History) int {
+	n := 0
+	for i := range entries {
+		e := &entries[i]
+		if e.WorkItem != "" || e.Agent == "" {
+			continue
+		}
+		if bead := h.BeadAt(e.Agent, e.EndedAt); bead != "" {
+			e.WorkItem = bead
+			n++
+		}
+	}
+	return n
+}
+
+// BeadCost is the cost attributed to one bead.
+type BeadCost struct {
+	Bead string  `json:"bead"`
+	USD  float64 `json:"cost_usd"`
+}
+
+// ByBead totals entries per work item, most expensive first, and returns the
+// cost of entries with no work item separately.
+func ByBead(entries []Entry) (beads []BeadCost, unattributed float64) {
+	idx := make(map[string]int)
+	for _, e := range entries {
+		if e.WorkItem == "" {
+			unattributed += e.USD
+			continue
+		}
+		i, ok := idx[e.WorkItem]
+		if !ok {
+			i = len(beads)
+			idx[e.WorkItem] = i
+			beads = append(beads, BeadCost{Bead: e.WorkItem})
+		}
+		beads[i].USD += e.USD
+	}
+	sortBeadCosts(beads)
+	return beads, unattributed
+}
+
+// MergeBeadCosts combines per-bead totals from several sources (daily digests,
+// the costs log and live sessions), most expensive first.
+func MergeBeadCosts(sets ...[]BeadCost) []BeadCost {
+	idx := make(map[string]int)
+	var out []BeadCost
+	for _, set := range sets {
+		for _, bc := range set {
+			i, ok := idx[bc.Bead]
+			if !ok {
+				i = len(out)
+				idx[bc.Bead] = i
+				out = append(out, BeadCost{Bead: bc.Bead})
+			}
+			out[i].USD += bc.USD
+		}
+	}
+	sortBeadCosts(out)
+	return out
+}
+
+func sortBeadCosts(beads []BeadCost) {
+	sort.SliceStable(beads, func(i, j int) bool {
+		if beads[i].USD != beads[j].USD {
+			return beads[i].USD > beads[j].USD
+		}
+		return beads[i].Bead < beads[j].Bead
+	})
+}
+
+// GroupCost is the cost rolled up to a convoy or epic.
+type GroupCost struct {
+	ID    string  `json:"id"`
+	USD   float64 `json:"cost_usd"`
+	Beads int     `json:"beads"` // member beads with attributed cost
+}
+
+// Rollup totals bead costs into groups. members maps a group ID (convoy) to
+// the beads it tracks; a bead in several groups is charged to each.
+func Rollup(beads []BeadCost, members map[string][]string) []GroupCost {
+	cost := make(map[string]BeadCost, len(beads))
+	for _, bc := range beads {
+		cost[bc.Bead] = bc
+	}
+	out := make([]GroupCost, 0, len(members))
+	for id, ids := range members {
+		g := GroupCost{ID: id}
+		seen := make(map[string]bool, len(ids))
+		for _, bead := range ids {
+			bc, ok := cost[bead]
+			if !ok || seen[bead] {
+				continue
+			}
+			seen[bead] = true
+			g.USD += bc.USD
+			g.Beads++
+		}
+		out = append(out, g)
+	}
+	sortGroupCosts(out)
+	return out
+}
+
+// RollupAncestors charges each bead's cost to every ancestor for which
+// include returns true (e.g. epics). parent maps a bead to its parent.
+func RollupAncestors(beads []BeadCost, parent map[string]string, include func(id string) bool) []GroupCost {
+	groups := make(map[string]*GroupCost)
+	for _, bc := range beads {
+		seen := map[string]bool{bc.Bead: true}
+		for id := parent[bc.Bead]; id != "" && !seen[id]; id = parent[id] {
+			seen[id] = true
+			if !include(id) {
+				continue
+			}
+			g := groups[id]
+			if g == nil {
+				g = &GroupCost{ID: id}
+				groups[id] = g
+			}
+			g.USD += bc.USD
+			g.Beads++
+		}
+	}
+	out := make([]GroupCost, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, *g)
+	}
+	sortGroupCosts(out)
+	return out
+}
+
+func sortGroupCosts(groups []GroupCost) {
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].USD != groups[j].USD {
+			return groups[i].USD > groups[j].USD
+		}
+		return groups[i].ID < groups[j].ID
+	})
+}
@@ -0,0 +1,193 @@
+package costs
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+func writeEvents(t *testing.T, evs ...events.Event) string {
+	t.Helper()
+	dir := t.TempDir()
+	var lines []string
+	for _, ev := range evs {
+		data, err := json.Marshal(ev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, string(data))
+	}
+	lines = append(lines, "not json")
+	if err := os.WriteFile(filepath.Join(dir, events.EventsFile), []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func ts(hour int) string {
+	return time.Date(2026, 3, 1, hour, 0, 0, 0, time.UTC).Format(time.RFC3339)
+}
+
+func at(hour int) time.Time {
+	return time.Date(2026, 3, 1, hour, 30, 0, 0, time.UTC)
+}
+
+func TestLoadHistory(t *testing.T) {
+	dir := writeEvents(t,
+		events.Event{Timestamp: ts(1), Type: events.TypeSling, Actor: "mayor", Payload: events.SlingPayload("gt-a", "gastown/toast")},
+		events.Event{Timestamp: ts(3), Type: events.TypeUnhook, Actor: "gastown/polecats/toast", Payload: map[string]interface{}{"bead": "gt-a"}},
+		events.Event{Timestamp: ts(4), Type: events.TypeHook, Actor: "gastown/polecats/toast", Payload: events.HookPayload("gt-b")},
+		events.Event{Timestamp: ts(2), Type: events.TypeHook, Actor: "gastown/crew/max", Payload: events.HookPayload("gt-c")},
+		events.Event{Timestamp: ts(2), Type: events.TypeDone, Actor: "gastown/polecats/toast", Payload: map[string]interface{}{"bead": "gt-a"}},
+	)
+	h, err := LoadHistory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		agent string
+		t     time.Time
+		want  string
+	}{
+		{"gastown/polecats/toast", at(0), ""},
+		{"gastown/polecats/toast", at(1), "gt-a"},
+		{"gastown/toast", at(2), "gt-a"}, // short form; done does not release the hook
+		{"gastown/polecats/toast", at(3), ""},
+		{"gastown/polecats/toast", at(5), "gt-b"},
+		{"gastown/crew/max", at(2), "gt-c"},
+		{"gastown/witness", at(5), ""},
+	}
+	for _, tt := range tests {
+		if got := h.BeadAt(tt.agent, tt.t); got != tt.want {
+			t.Errorf("BeadAt(%q, %v) = %q, want %q", tt.agent, tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestLoadHistory_MissingLog(t *testing.T) {
+	h, err := LoadHistory(t.TempDir())
+	if err != nil || len(h) != 0 {
+		t.Fatalf("LoadHistory = %v, %v; want empty, nil", h, err)
+	}
+}
+
+func TestAttributeAndByBead(t *testing.T) {
+	dir := writeEvents(t,
+		events.Event{Timestamp: ts(1), Type: events.TypeHook, Actor: "gastown/polecats/toast", Payload: events.HookPayload("gt-a")},
+	)
+	h, err := LoadHistory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := []Entry{
+		{Session: "gt-gastown-toast", Agent: AgentForSession("polecat", "gastown", "toast"), USD: 2, EndedAt: at(2)},
+		{Session: "gt-gastown-toast", Agent: "gastown/polecats/toast", WorkItem: "gt-b", USD: 1, EndedAt: at(2)},
+		{Session: "gt-gastown-toast", Agent: "gastown/polecats/toast", USD: 0.5, EndedAt: at(0)},
+		{Session: "hq-mayor", USD: 4, EndedAt: at(2)},
+		{Session: "gt-gastown-crew-max", Agent: AgentForSession("crew", "gastown", "max"), WorkItem: "gt-a", USD: 3, EndedAt: at(2)},
+	}
+	if n := Attribute(entries, h); n != 1 {
+		t.Errorf("Attribute = %d, want 1", n)
+	}
+
+	beads, unattributed := ByBead(entries)
+	if math.Abs(unattributed-4.5) > 1e-9 {
+		t.Errorf("unattributed = %v, want 4.5", unattributed)
+	}
+	want := []BeadCost{{Bead: "gt-a", USD: 5}, {Bead: "gt-b", USD: 1}}
+	if len(beads) != len(want) {
+		t.Fatalf("ByBead = %+v, want %+v", beads, want)
+	}
+	for i := range want {
+		if beads[i] != want[i] {
+			t.Errorf("ByBead[%d] = %+v, want %+v", i, beads[i], want[i])
+		}
+	}
+}
+
+func TestAgentForSession(t *testing.T) {
+	tests := []struct{ role, rig, worker, want string }{
+		{"polecat", "gastown", "toast", "gastown/polecats/toast"},
+		{"crew", "gastown", "max", "gastown/crew/max"},
+		{"witness", "gastown", "", ""},
+		{"mayor", "", "", ""},
+	}
+	for _, tt := range tests {
+		if got := AgentForSession(tt.role, tt.rig, tt.worker); got != tt.want {
+			t.Errorf("AgentForSession(%q, %q, %q) = %q, want %q", tt.role, tt.rig, tt.worker, got, tt.want)
+		}
+	}
+}
+
+func TestMergeBeadCosts(t *testing.T) {
+	got := MergeBeadCosts(
+		[]BeadCost{{Bead: "gt-a", USD: 1}},
+		[]BeadCost{{Bead: "gt-b", USD: 3}, {Bead: "gt-a", USD: 1.5}},
+	)
+	want := []BeadCost{{Bead: "gt-b", USD: 3}, {Bead: "gt-a", USD: 2.5}}
+	if len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("MergeBeadCosts = %+v, want %+v", got, want)
+	}
+}
+
+func TestRollup(t *testing.T) {
+	beads := []BeadCost{
+		{Bead: "gt-a", USD: 1},
+		{Bead: "gt-b", USD: 2},
+		{Bead: "gt-c", USD: 4},
+	}
+	got := Rollup(beads, map[string][]string{
+		"hq-cv-1": {"gt-a", "gt-b", "gt-a", "gt-missing"},
+		"hq-cv-2": {"gt-c", "gt-a"},
+		"hq-cv-3": {"gt-none"},
+	})
+	want := []GroupCost{
+		{ID: "hq-cv-2", USD: 5, Beads: 2},
+		{ID: "hq-cv-1", USD: 3, Beads: 2},
+		{ID: "hq-cv-3"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Rollup = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Rollup[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRollupAncestors(t *testing.T) {
+	beads := []BeadCost{
+		{Bead: "gt-a.1.1", USD: 1},
+		{Bead: "gt-a.2", USD: 2},
+		{Bead: "gt-loop", USD: 8},
+	}
+	parent := map[string]string{
+		"gt-a.1.1": "gt-a.1",
+		"gt-a.1":   "gt-a",
+		"gt-a.2":   "gt-a",
+		"gt-loop":  "gt-loop2",
+		"gt-loop2": "gt-loop",
+	}
+	epics := map[string]bool{"gt-a": true, "gt-a.1": true}
+	got := RollupAncestors(beads, parent, func(id string) bool { return epics[id] })
+	want := []GroupCost{
+		{ID: "gt-a", USD: 3, Beads: 2},
+		{ID: "gt-a.1", USD: 1, Beads: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("RollupAncestors = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("RollupAncestors[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
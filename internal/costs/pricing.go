@@ -0,0 +1,90 @@
+// Package costs prices agent token usage and attributes session costs to
+// units of work (beads, and through them convoys and epics).
+//
+// Like budget, the package is pure: gt costs collects cost entries, hook
+// history and bead relationships, and the functions here join and roll
+// them up.
+package costs
+
+import (
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// DefaultModel is the Pricing key used for models that match no other key.
+const DefaultModel = "default"
+
+// Pricing maps model IDs or model ID prefixes to token prices.
+type Pricing map[string]config.ModelPrice
+
+// defaultPricing is the compiled-in price list (USD per million tokens).
+// Town settings "pricing" entries override or extend it.
+var defaultPricing = Pricing{
+	// Anthropic — https://www.anthropic.com/pricing
+	"claude-opus-4-5-20251101":  {Input: 15, Output: 75, CacheRead: 1.5, CacheWrite: 18.75},
+	"claude-sonnet-4-20250514":  {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+	"claude-3-5-haiku-20241022": {Input: 1, Output: 5, CacheRead: 0.1, CacheWrite: 1.25},
+	"claude-opus-4":             {Input: 15, Output: 75, CacheRead: 1.5, CacheWrite: 18.75},
+	"claude-sonnet-4":           {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+	"claude-haiku-4-5":          {Input: 1, Output: 5, CacheRead: 0.1, CacheWrite: 1.25},
+	// OpenAI (Codex)
+	"gpt-5": {Input: 1.25, Output: 10, CacheRead: 0.125},
+	// Google (Gemini CLI)
+	"gemini-2.5-pro":   {Input: 1.25, Output: 10, CacheRead: 0.125},
+	"gemini-2.5-flash": {Input: 0.3, Output: 2.5, CacheRead: 0.03},
+	// Unknown models are priced like Sonnet.
+	DefaultModel: {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+}
+
+// NewPricing returns the compiled-in prices overlaid with overrides (from
+// TownSettings.Pricing). Nil overrides are ignored.
+func NewPricing(overrides map[string]*config.ModelPrice) Pricing {
+	p := make(Pricing, len(defaultPricing)+len(overrides))
+	for model, price := range defaultPricing {
+		p[model] = price
+	}
+	for model, price := range overrides {
+		if price != nil {
+			p[model] = *price
+		}
+	}
+	return p
+}
+
+// Lookup returns the price for model: an exact key, else the longest key that
+// is a prefix of model, else the "default" entry.
+func (p Pricing) Lookup(model string) config.ModelPrice {
+	if price, ok := p[model]; ok {
+		return price
+	}
+	best := ""
+	for key := range p {
+		if key != DefaultModel && strings.HasPrefix(model, key) && len(key) > len(best) {
+			best = key
+		}
+	}
+	if best != "" {
+		return p[best]
+	}
+	return p[DefaultModel]
+}
+
+// Usage is token usage for one model. InputTokens excludes cache reads and
+// writes, as in the Claude API.
+type Usage struct {
+	Model            string
+	InputTokens      int
+	OutputTokens     int
+	CacheReadTokens  int
+	CacheWriteTokens int
+}
+
+// Cost returns the USD cost of u at the model's price.
+func (p Pricing) Cost(u Usage) float64 {
+	price := p.Lookup(u.Model)
+	return (float64(u.InputTokens)*price.Input +
+		float64(u.OutputTokens)*price.Output +
+		float64(u.CacheReadTokens)*price.CacheRead +
+		float64(u.CacheWriteTokens)*price.CacheWrite) / 1_000_000
+}
@@ -0,0 +1,59 @@
+package costs
+
+import (
+	"math"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestPricingLookup(t *testing.T) {
+	p := NewPricing(map[string]*config.ModelPrice{
+		"claude-sonnet-4-5": {Input: 4, Output: 20},
+		"local-llama":       {},
+		"ignored":           nil,
+	})
+
+	tests := []struct {
+		model     string
+		wantInput float64
+	}{
+		{"claude-opus-4-5-20251101", 15},  // exact key
+		{"claude-opus-4-1-20250805", 15},  // falls back to claude-opus-4
+		{"claude-3-5-haiku-20241022", 1},  // exact key
+		{"claude-haiku-4-5-20251001", 1},  // prefix
+		{"claude-sonnet-4-5-20250929", 4}, // longest prefix beats claude-sonnet-4
+		{"claude-sonnet-4-20250514", 3},
+		{"local-llama", 0},
+		{"mystery-model", 3}, // default
+		{"", 3},
+	}
+	for _, tt := range tests {
+		if got := p.Lookup(tt.model).Input; got != tt.wantInput {
+			t.Errorf("Lookup(%q).Input = %v, want %v", tt.model, got, tt.wantInput)
+		}
+	}
+	if _, ok := p["ignored"]; ok {
+		t.Error("nil override should be ignored")
+	}
+	if defaultPricing["claude-sonnet-4-5"] != (config.ModelPrice{}) {
+		t.Error("NewPricing must not modify the compiled-in table")
+	}
+}
+
+func TestPricingCost(t *testing.T) {
+	p := NewPricing(map[string]*config.ModelPrice{
+		"m": {Input: 2, Output: 10, CacheRead: 0.2, CacheWrite: 2.5},
+	})
+	got := p.Cost(Usage{
+		Model:            "m",
+		InputTokens:      1_000_000,
+		OutputTokens:     500_000,
+		CacheReadTokens:  2_000_000,
+		CacheWriteTokens: 400_000,
+	})
+	want := 2 + 5 + 0.4 + 1.0
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("Cost = %v, want %v", got, want)
+	}
+}
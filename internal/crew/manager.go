@@ -799,7 +799,7 @@ func (m *Manager) Start(name string, opts StartOptions) error {
 		}
 	}
 
-	t := tmux.NewTmux()
+	t := m.sessions()
 	sessionID := m.SessionName(name)
 
 	// Check if session already exists — kill AFTER command is fully built
@@ -838,7 +838,13 @@ func (m *Manager) Start(name string, opts StartOptions) error {
 	// initial shell inherits the correct GT_ROLE (not the parent's).
 	// See: https://github.com/anthropics/gastown/issues/280 (race condition fix)
 	// See: https://github.com/steveyegge/gastown/issues/1289 (env inheritance fix)
-	if err := t.NewSessionWithCommandAndEnv(sessionID, worker.ClonePath, claudeCmd, envVars); err != nil {
+	// Backends without session-level env get the vars prefixed to the command.
+	if creator, ok := t.(session.EnvSessionCreator); ok {
+		err = creator.NewSessionWithCommandAndEnv(sessionID, worker.ClonePath, claudeCmd, envVars)
+	} else {
+		err = t.NewSessionWithCommand(sessionID, worker.ClonePath, config.PrependEnv(claudeCmd, envVars))
+	}
+	if err != nil {
 		return fmt.Errorf("creating session: %w", err)
 	}
 
@@ -848,11 +854,15 @@ func (m *Manager) Start(name string, opts StartOptions) error {
 	}
 
 	// Apply rig-based theming (non-fatal: theming failure doesn't affect operation)
-	theme := tmux.ResolveSessionTheme(townRoot, m.rig.Name, "crew")
-	_ = t.ConfigureGasTownSession(sessionID, theme, m.rig.Name, name, "crew")
+	if themer, ok := t.(session.Themer); ok {
+		theme := tmux.ResolveSessionTheme(townRoot, m.rig.Name, "crew")
+		_ = themer.ConfigureGasTownSession(sessionID, theme, m.rig.Name, name, "crew")
+	}
 
 	// Set up C-b n/p keybindings for crew session cycling (non-fatal)
-	_ = t.SetCrewCycleBindings(sessionID)
+	if binder, ok := t.(session.CycleBinder); ok {
+		_ = binder.SetCrewCycleBindings(sessionID)
+	}
 
 	// Track PID for defense-in-depth orphan cleanup (non-fatal)
 	_ = session.TrackSessionPID(townRoot, sessionID, t)
@@ -875,7 +885,9 @@ func (m *Manager) Start(name string, opts StartOptions) error {
 				// Non-fatal — agent might still start
 				style.PrintWarning("timeout waiting for agent to start: %v", err)
 			}
-			_ = t.AcceptStartupDialogs(sessionID)
+			if acceptor, ok := t.(session.DialogAcceptor); ok {
+				_ = acceptor.AcceptStartupDialogs(sessionID)
+			}
 		}
 
 		// Start background nudge-queue poller for ALL agents (gt-dgf).
@@ -894,13 +906,18 @@ func (m *Manager) Start(name string, opts StartOptions) error {
 	return nil
 }
 
-// Stop terminates a crew member's tmux session.
+// sessions returns the session backend the town is configured to use.
+func (m *Manager) sessions() session.SessionBackend {
+	return session.NewBackend(filepath.Dir(m.rig.Path))
+}
+
+// Stop terminates a crew member's session.
 func (m *Manager) Stop(name string) error {
 	if err := validateCrewName(name); err != nil {
 		return err
 	}
 
-	t := tmux.NewTmux()
+	t := m.sessions()
 	sessionID := m.SessionName(name)
 
 	// Check if session exists
@@ -931,7 +948,7 @@ func (m *Manager) Stop(name string) error {
 
 // IsRunning checks if a crew member's session is active.
 func (m *Manager) IsRunning(name string) (bool, error) {
-	t := tmux.NewTmux()
+	t := m.sessions()
 	sessionID := m.SessionName(name)
 	return t.HasSession(sessionID)
 }
@@ -93,13 +93,14 @@ func (d *Daemon) checkpointRigPolecats(rigName string) (int, int) {
 	scanned := 0
 	checkpointed := 0
 
+	t := d.sessions()
 	for _, polecatName := range polecats {
 		scanned++
 
-		// Check if tmux session is alive — only checkpoint active sessions.
+		// Check if the session is alive — only checkpoint active sessions.
 		// Dead sessions can't benefit from checkpoints.
 		sessionName := session.PolecatSessionName(session.PrefixFor(rigName), polecatName)
-		alive, err := d.tmux.HasSession(sessionName)
+		alive, err := t.HasSession(sessionName)
 		if err != nil {
 			d.logger.Printf("checkpoint_dog: error checking session %s: %v", sessionName, err)
 			continue
@@ -24,12 +24,15 @@ import (
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/deacon"
 	"github.com/steveyegge/gastown/internal/doltserver"
+	"github.com/steveyegge/gastown/internal/eventbus"
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/feed"
 	"github.com/steveyegge/gastown/internal/estop"
 	gitpkg "github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/mayor"
 	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/ptyhost"
 	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/session"
@@ -57,6 +60,20 @@ type Daemon struct {
 	doltServer *DoltServerManager
 	krcPruner  *KRCPruner
 
+	// eventBus carries activity and channel events to subscribers over
+	// daemon/eventbus.sock. Nil if it failed to open.
+	eventBus *eventbus.Bus
+
+	// pluginTriggers queues event-gated plugins fired by bus events until
+	// dispatchDueTriggersLocked hands them to dogs. pluginDispatchMu keeps
+	// the heartbeat and the trigger watcher from claiming the same idle dog.
+	pluginTriggers   *pluginTriggers
+	pluginDispatchMu sync.Mutex
+
+	// ptyHost runs agent sessions in-process when the town's session_backend
+	// is "headless". Nil for tmux towns.
+	ptyHost *ptyhost.Host
+
 	// disabledPatrols is loaded from town settings (disabled_patrols field).
 	// Provides a simple way to disable individual patrol dogs without editing
 	// mayor/daemon.json. Checked by isPatrolActive alongside patrolConfig.
@@ -361,14 +378,46 @@ func (d *Daemon) Run() error {
 
 	d.logger.Printf("Daemon running, recovery heartbeat interval %v", d.recoveryHeartbeatInterval())
 
+	// Start the event bus before its subscribers
+	if bus, err := eventbus.Open(eventbus.Dir(d.config.TownRoot)); err != nil {
+		d.logger.Printf("Warning: failed to open event bus: %v", err)
+	} else {
+		d.eventBus = bus
+		socketPath := eventbus.SocketPath(d.config.TownRoot)
+		go func() {
+			if err := eventbus.Serve(d.ctx, bus, socketPath); err != nil {
+				d.logger.Printf("Warning: event bus stopped: %v", err)
+			}
+		}()
+		d.logger.Printf("Event bus listening on %s", socketPath)
+
+		d.pluginTriggers = newPluginTriggers()
+		go d.watchPluginEvents(bus)
+	}
+
 	// Start feed curator goroutine
 	d.curator = feed.NewCurator(d.config.TownRoot)
+	if d.eventBus != nil {
+		d.curator.SetBus(d.eventBus)
+	}
 	if err := d.curator.Start(); err != nil {
 		d.logger.Printf("Warning: failed to start feed curator: %v", err)
 	} else {
 		d.logger.Println("Feed curator started")
 	}
 
+	// Host headless agent sessions when the town does not use tmux.
+	if session.BackendName(d.config.TownRoot) == config.SessionBackendHeadless {
+		d.ptyHost = ptyhost.NewHost()
+		socketPath := ptyhost.SocketPath(d.config.TownRoot)
+		go func() {
+			if err := ptyhost.Serve(d.ctx, d.ptyHost, socketPath); err != nil {
+				d.logger.Printf("Warning: headless session host stopped: %v", err)
+			}
+		}()
+		d.logger.Printf("Headless session host listening on %s", socketPath)
+	}
+
 	// Start convoy manager (event-driven + periodic stranded scan)
 	// Try opening beads stores eagerly; if Dolt isn't ready yet,
 	// pass the opener as a callback for lazy retry on each poll tick.
@@ -823,6 +872,14 @@ func (d *Daemon) heartbeat(state *State) {
 		d.dispatchQueuedWork()
 	}
 
+	// 14b. Release scheduled and snoozed mail that has come due, waking the
+	// recipients. Not pressure-gated: nudging an existing session is cheap.
+	d.deliverScheduledMail()
+
+	// 14c. Evaluate cost budgets: warn at soft limits, stop dispatch and wind
+	// down sessions at hard limits. The scheduler reads the result next cycle.
+	d.checkCostBudgets()
+
 	// 15. Rotate oversized Dolt logs (copytruncate for child process fds).
 	// daemon.log uses lumberjack for automatic rotation; this handles Dolt server logs.
 	d.rotateOversizedLogs()
@@ -837,6 +894,38 @@ func (d *Daemon) heartbeat(state *State) {
 	d.logger.Printf("Heartbeat complete (#%d)", state.HeartbeatCount)
 }
 
+// deliverScheduledMail releases mail sent with --at/--in or snoozed with
+// gt mail snooze once it is due, and notifies each recipient.
+func (d *Daemon) deliverScheduledMail() {
+	router := mail.NewRouterWithTownRoot(d.config.TownRoot, d.config.TownRoot)
+	released, err := router.DeliverDue(time.Now())
+	if err != nil {
+		d.logger.Printf("scheduled_mail: %v", err)
+	}
+	for _, msg := range released {
+		d.logger.Printf("scheduled_mail: delivered %s to %s", msg.ID, msg.To)
+	}
+}
+
+// checkCostBudgets shells out to `gt costs budget check`, which records
+// today's budget state for the scheduler and enforces limits. It is a no-op
+// when no budgets are configured.
+func (d *Daemon) checkCostBudgets() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "gt", "costs", "budget", "check")
+	cmd.Dir = d.config.TownRoot
+	cmd.Env = append(os.Environ(), "GT_DAEMON=1")
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		d.logger.Printf("budget: check timed out after 2m")
+	} else if err != nil {
+		d.logger.Printf("budget: check failed: %v (output: %s)", err, string(out))
+	} else if len(out) > 0 {
+		d.logger.Printf("budget: %s", string(out))
+	}
+}
+
 // rotateOversizedLogs checks Dolt server log files and rotates any that exceed
 // the size threshold. Uses copytruncate which is safe for logs held open by
 // child processes. Runs every heartbeat but is cheap (just stat calls).
@@ -963,6 +1052,27 @@ func (d *Daemon) getDeaconSessionName() string {
 	return session.DeaconSessionName()
 }
 
+// sessions returns the session backend the town is configured to use, so
+// health checks, kills and restarts see headless sessions as well as tmux
+// ones. Read per call so a session_backend change applies without restart.
+func (d *Daemon) sessions() session.SessionBackend {
+	townRoot := ""
+	if d.config != nil {
+		townRoot = d.config.TownRoot
+	}
+	return session.ResolveBackend(townRoot, d.tmux)
+}
+
+// isAgentRunning reports whether an agent appears to be running in the
+// session. tmux checks the pane's current command; other backends check for
+// the agent process.
+func isAgentRunning(t session.SessionBackend, name string) bool {
+	if tm, ok := t.(*tmux.Tmux); ok {
+		return tm.IsAgentRunning(name)
+	}
+	return t.IsAgentAlive(name)
+}
+
 // ensureBootRunning spawns Boot to triage the Deacon.
 // Boot is a fresh-each-tick watchdog that decides whether to start/wake/nudge
 // the Deacon, centralizing the "when to wake" decision in an agent.
@@ -985,7 +1095,8 @@ func (d *Daemon) ensureBootRunning() {
 
 	// Check for degraded mode
 	degraded := os.Getenv("GT_DEGRADED") == "true"
-	if degraded || !d.tmux.IsAvailable() {
+	headless := session.BackendName(d.config.TownRoot) == config.SessionBackendHeadless
+	if degraded || (!headless && !d.tmux.IsAvailable()) {
 		// In degraded mode, run mechanical triage directly
 		d.logger.Println("Degraded mode: running mechanical Boot triage")
 		d.runDegradedBootTriage(b)
@@ -1014,7 +1125,7 @@ func (d *Daemon) runDegradedBootTriage(b *boot.Boot) {
 	}
 
 	// Simple check: is Deacon session alive?
-	hasDeacon, err := d.tmux.HasSession(d.getDeaconSessionName())
+	hasDeacon, err := d.sessions().HasSession(d.getDeaconSessionName())
 	if err != nil {
 		d.logger.Printf("Error checking Deacon session: %v", err)
 		status.LastAction = "error"
@@ -1166,7 +1277,8 @@ func (d *Daemon) checkDeaconHeartbeat() {
 	d.logger.Printf("Deacon heartbeat is stale (%s old), checking session...", age.Round(time.Minute))
 
 	// Check if session exists
-	hasSession, err := d.tmux.HasSession(sessionName)
+	t := d.sessions()
+	hasSession, err := t.HasSession(sessionName)
 	if err != nil {
 		d.logger.Printf("Error checking Deacon session: %v", err)
 		return
@@ -1192,7 +1304,7 @@ func (d *Daemon) checkDeaconHeartbeat() {
 	} else {
 		// Stale but not very stale (5-15 min) - nudge to wake up
 		d.logger.Printf("Deacon stuck for %s - nudging session", age.Round(time.Minute))
-		if err := d.tmux.NudgeSession(sessionName, "HEALTH_CHECK: heartbeat stale, respond to confirm responsiveness"); err != nil {
+		if err := t.NudgeSession(sessionName, "HEALTH_CHECK: heartbeat stale, respond to confirm responsiveness"); err != nil {
 			d.logger.Printf("Error nudging stuck Deacon: %v", err)
 		}
 	}
@@ -1236,9 +1348,10 @@ func (d *Daemon) ensureWitnessRunning(rigName string) {
 		// Without this, sessions started before the rig was docked survive until
 		// the next explicit 'gt rig dock' command. (hq-snx61)
 		name := session.WitnessSessionName(session.PrefixFor(rigName))
-		if exists, _ := d.tmux.HasSession(name); exists {
+		t := d.sessions()
+		if exists, _ := t.HasSession(name); exists {
 			d.logger.Printf("Killing leftover witness %s (rig %s)", name, reason)
-			if err := d.tmux.KillSessionWithProcesses(name); err != nil {
+			if err := t.KillSessionWithProcesses(name); err != nil {
 				d.logger.Printf("Error killing leftover witness %s: %v", name, err)
 			}
 		}
@@ -1295,9 +1408,10 @@ func (d *Daemon) ensureRefineryRunning(rigName string) {
 		// Without this, sessions started before the rig was docked survive until
 		// the next explicit 'gt rig dock' command. (hq-snx61)
 		name := session.RefinerySessionName(session.PrefixFor(rigName))
-		if exists, _ := d.tmux.HasSession(name); exists {
+		t := d.sessions()
+		if exists, _ := t.HasSession(name); exists {
 			d.logger.Printf("Killing leftover refinery %s (rig %s)", name, reason)
-			if err := d.tmux.KillSessionWithProcesses(name); err != nil {
+			if err := t.KillSessionWithProcesses(name); err != nil {
 				d.logger.Printf("Error killing leftover refinery %s: %v", name, err)
 			}
 		}
@@ -1394,21 +1508,21 @@ func (d *Daemon) ensureMayorRunning() {
 	d.logger.Println("Mayor started successfully")
 }
 
-// isMayorAgentAlive checks if the Mayor's agent process is running in tmux.
+// isMayorAgentAlive checks if the Mayor's agent process is running.
 func (d *Daemon) isMayorAgentAlive(mgr *mayor.Manager) bool {
-	t := tmux.NewTmux()
-	return t.IsAgentAlive(mgr.SessionName())
+	return d.sessions().IsAgentAlive(mgr.SessionName())
 }
 
 // killDeaconSessions kills leftover deacon and boot tmux sessions.
 // Called when the deacon patrol is disabled to prevent stale deacons from
 // running their own patrol loops and spawning agents. (hq-2mstj)
 func (d *Daemon) killDeaconSessions() {
+	t := d.sessions()
 	for _, name := range []string{session.DeaconSessionName(), session.BootSessionName()} {
-		exists, _ := d.tmux.HasSession(name)
+		exists, _ := t.HasSession(name)
 		if exists {
 			d.logger.Printf("Killing leftover %s session (patrol disabled)", name)
-			if err := d.tmux.KillSessionWithProcesses(name); err != nil {
+			if err := t.KillSessionWithProcesses(name); err != nil {
 				d.logger.Printf("Error killing %s session: %v", name, err)
 			}
 		}
@@ -1418,12 +1532,13 @@ func (d *Daemon) killDeaconSessions() {
 // killWitnessSessions kills leftover witness tmux sessions for all rigs.
 // Called when the witness patrol is disabled. (hq-2mstj)
 func (d *Daemon) killWitnessSessions() {
+	t := d.sessions()
 	for _, rigName := range d.getKnownRigs() {
 		name := session.WitnessSessionName(session.PrefixFor(rigName))
-		exists, _ := d.tmux.HasSession(name)
+		exists, _ := t.HasSession(name)
 		if exists {
 			d.logger.Printf("Killing leftover %s session (patrol disabled)", name)
-			if err := d.tmux.KillSessionWithProcesses(name); err != nil {
+			if err := t.KillSessionWithProcesses(name); err != nil {
 				d.logger.Printf("Error killing %s session: %v", name, err)
 			}
 		}
@@ -1433,12 +1548,13 @@ func (d *Daemon) killWitnessSessions() {
 // killRefinerySessions kills leftover refinery tmux sessions for all rigs.
 // Called when the refinery patrol is disabled. (hq-2mstj)
 func (d *Daemon) killRefinerySessions() {
+	t := d.sessions()
 	for _, rigName := range d.getKnownRigs() {
 		name := session.RefinerySessionName(session.PrefixFor(rigName))
-		exists, _ := d.tmux.HasSession(name)
+		exists, _ := t.HasSession(name)
 		if exists {
 			d.logger.Printf("Killing leftover %s session (patrol disabled)", name)
-			if err := d.tmux.KillSessionWithProcesses(name); err != nil {
+			if err := t.KillSessionWithProcesses(name); err != nil {
 				d.logger.Printf("Error killing %s session: %v", name, err)
 			}
 		}
@@ -1472,12 +1588,13 @@ func (d *Daemon) killDefaultPrefixGhosts() {
 	}
 
 	// Kill ghost sessions using the default "gt" prefix for patrol roles.
+	t := d.sessions()
 	for _, role := range []string{"witness", "refinery"} {
 		ghostName := fmt.Sprintf("%s-%s", session.DefaultPrefix, role)
-		exists, _ := d.tmux.HasSession(ghostName)
+		exists, _ := t.HasSession(ghostName)
 		if exists {
 			d.logger.Printf("Killing ghost session %s (default prefix, stale registry artifact)", ghostName)
-			if err := d.tmux.KillSessionWithProcesses(ghostName); err != nil {
+			if err := t.KillSessionWithProcesses(ghostName); err != nil {
 				d.logger.Printf("Error killing ghost session %s: %v", ghostName, err)
 			}
 		}
@@ -1501,11 +1618,11 @@ func (d *Daemon) killDefaultPrefixGhosts() {
 			}
 			polecatName := entry.Name()
 			ghostName := fmt.Sprintf("%s-%s", session.DefaultPrefix, polecatName)
-			exists, _ := d.tmux.HasSession(ghostName)
+			exists, _ := t.HasSession(ghostName)
 			if exists {
 				// Verify the correct session isn't also running (avoid killing legit sessions)
 				correctName := session.PolecatSessionName(rigPrefix, polecatName)
-				correctExists, _ := d.tmux.HasSession(correctName)
+				correctExists, _ := t.HasSession(correctName)
 				if !correctExists {
 					// Ghost is the only session — it might be doing real work.
 					// Log but don't kill; the registry reload will prevent new ghosts.
@@ -1513,7 +1630,7 @@ func (d *Daemon) killDefaultPrefixGhosts() {
 				} else {
 					// Both exist — ghost is definitely a duplicate, kill it.
 					d.logger.Printf("Killing duplicate ghost polecat session %s (correct session %s exists)", ghostName, correctName)
-					if err := d.tmux.KillSessionWithProcesses(ghostName); err != nil {
+					if err := t.KillSessionWithProcesses(ghostName); err != nil {
 						d.logger.Printf("Error killing ghost session %s: %v", ghostName, err)
 					}
 				}
@@ -1703,6 +1820,12 @@ func (d *Daemon) shutdown(state *State) error { //nolint:unparam // error return
 		d.logger.Println("Feed curator stopped")
 	}
 
+	// Close the event bus after its in-process subscribers
+	if d.eventBus != nil {
+		_ = d.eventBus.Close()
+		d.logger.Println("Event bus stopped")
+	}
+
 	// Stop convoy manager (also closes beads stores)
 	if d.convoyManager != nil {
 		d.convoyManager.Stop()
@@ -1716,6 +1839,12 @@ func (d *Daemon) shutdown(state *State) error { //nolint:unparam // error return
 		d.logger.Println("KRC pruner stopped")
 	}
 
+	// Headless sessions live in this process and end with it.
+	if d.ptyHost != nil {
+		d.ptyHost.Close()
+		d.logger.Println("Headless session host stopped")
+	}
+
 	// Push Dolt remotes before stopping the server (if patrol is enabled)
 	d.pushDoltRemotes()
 
@@ -2064,11 +2193,12 @@ func listPolecatWorktrees(polecatsDir string) ([]string, error) {
 // checkPolecatHealth checks a single polecat's session health.
 // If the polecat has work-on-hook but the tmux session is dead, it's restarted.
 func (d *Daemon) checkPolecatHealth(rigName, polecatName string) {
-	// Build the expected tmux session name
+	// Build the expected session name
 	sessionName := session.PolecatSessionName(session.PrefixFor(rigName), polecatName)
 
-	// Check if tmux session exists
-	sessionAlive, err := d.tmux.HasSession(sessionName)
+	// Check if the session exists
+	t := d.sessions()
+	sessionAlive, err := t.HasSession(sessionName)
 	if err != nil {
 		d.logger.Printf("Error checking session %s: %v", sessionName, err)
 		return
@@ -2152,7 +2282,7 @@ func (d *Daemon) checkPolecatHealth(rigName, polecatName string) {
 	// TOCTOU guard: re-verify session is still dead before restarting.
 	// Between the initial check and now, the session may have been restarted
 	// by another heartbeat cycle, witness, or the polecat itself.
-	sessionRevived, err := d.tmux.HasSession(sessionName)
+	sessionRevived, err := t.HasSession(sessionName)
 	if err == nil && sessionRevived {
 		return // Session came back - no restart needed
 	}
@@ -2327,7 +2457,8 @@ func (d *Daemon) reapIdlePolecat(rigName, polecatName string, timeout time.Durat
 	sessionName := session.PolecatSessionName(session.PrefixFor(rigName), polecatName)
 
 	// Only check sessions that are actually alive
-	alive, err := d.tmux.HasSession(sessionName)
+	t := d.sessions()
+	alive, err := t.HasSession(sessionName)
 	if err != nil || !alive {
 		return
 	}
@@ -2364,7 +2495,7 @@ func (d *Daemon) reapIdlePolecat(rigName, polecatName string, timeout time.Durat
 			// If heartbeat is stale enough (2x timeout), reap anyway to prevent
 			// indefinite API burn when bead infrastructure is degraded.
 			// But first check if the agent is actually running (GH#3342).
-			if staleDuration >= timeout*2 && !d.tmux.IsAgentRunning(sessionName) {
+			if staleDuration >= timeout*2 && !isAgentRunning(t, sessionName) {
 				d.killIdlePolecat(rigName, polecatName, sessionName, staleDuration, timeout, "working-bead-lookup-failed")
 			}
 			return
@@ -2392,7 +2523,7 @@ func (d *Daemon) reapIdlePolecat(rigName, polecatName string, timeout time.Durat
 		// No hooked work + stale heartbeat — but check if the agent process
 		// is still actively running before reaping. A failed gt sling rollback
 		// can clear the hook while the agent is still working (GH#3342).
-		if d.tmux.IsAgentRunning(sessionName) {
+		if isAgentRunning(t, sessionName) {
 			return
 		}
 		d.killIdlePolecat(rigName, polecatName, sessionName, staleDuration, timeout, "working-no-hook")
@@ -2404,8 +2535,8 @@ func (d *Daemon) killIdlePolecat(rigName, polecatName, sessionName string, idleD
 	d.logger.Printf("Reaping idle polecat %s/%s (state=%s, idle %v, threshold %v)",
 		rigName, polecatName, reason, idleDuration.Truncate(time.Second), timeout)
 
-	// Kill the tmux session (and all descendant processes)
-	if err := d.tmux.KillSessionWithProcesses(sessionName); err != nil {
+	// Kill the session (and all descendant processes)
+	if err := d.sessions().KillSessionWithProcesses(sessionName); err != nil {
 		d.logger.Printf("Warning: failed to kill idle polecat session %s: %v", sessionName, err)
 		return
 	}
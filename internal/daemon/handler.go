@@ -9,7 +9,6 @@ import (
 	"github.com/steveyegge/gastown/internal/dog"
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/plugin"
-	"github.com/steveyegge/gastown/internal/tmux"
 )
 
 // Dog lifecycle defaults — now config-driven via operational.daemon thresholds.
@@ -48,8 +47,7 @@ func (d *Daemon) handleDogs() {
 	opCfg := d.loadOperationalConfig().GetDaemonConfig()
 
 	mgr := dog.NewManager(d.config.TownRoot, rigsConfig)
-	t := tmux.NewTmux()
-	sm := dog.NewSessionManager(t, d.config.TownRoot, mgr)
+	sm := dog.NewSessionManager(d.tmux, d.config.TownRoot, mgr)
 
 	d.cleanupStuckDogs(mgr, sm)
 	d.detectStaleWorkingDogs(mgr, sm, opCfg)
@@ -69,8 +67,7 @@ func (d *Daemon) handleDogsCleanupOnly() {
 	opCfg := d.loadOperationalConfig().GetDaemonConfig()
 
 	mgr := dog.NewManager(d.config.TownRoot, rigsConfig)
-	t := tmux.NewTmux()
-	sm := dog.NewSessionManager(t, d.config.TownRoot, mgr)
+	sm := dog.NewSessionManager(d.tmux, d.config.TownRoot, mgr)
 
 	d.cleanupStuckDogs(mgr, sm)
 	d.detectStaleWorkingDogs(mgr, sm, opCfg)
@@ -214,27 +211,20 @@ func (d *Daemon) reapIdleDogs(mgr *dog.Manager, sm *dog.SessionManager, daemonCf
 }
 
 // dispatchPlugins scans for plugins, evaluates cooldown gates, and dispatches
-// eligible plugins to idle dogs.
+// eligible plugins to idle dogs. Event-gated plugins are dispatched from the
+// trigger queue filled by watchPluginEvents; triggers still waiting for a dog
+// are retried here.
 func (d *Daemon) dispatchPlugins(mgr *dog.Manager, sm *dog.SessionManager, rigsConfig *config.RigsConfig) {
-	// Get rig names for scanner
-	var rigNames []string
-	if rigsConfig != nil {
-		for name := range rigsConfig.Rigs {
-			rigNames = append(rigNames, name)
-		}
-	}
+	d.pluginDispatchMu.Lock()
+	defer d.pluginDispatchMu.Unlock()
 
-	scanner := plugin.NewScanner(d.config.TownRoot, rigNames)
+	scanner := plugin.NewScanner(d.config.TownRoot, rigNamesOf(rigsConfig))
 	plugins, err := scanner.DiscoverAll()
 	if err != nil {
 		d.logger.Printf("Handler: failed to discover plugins: %v", err)
 		return
 	}
 
-	if len(plugins) == 0 {
-		return
-	}
-
 	recorder := plugin.NewRecorder(d.config.TownRoot)
 	router := mail.NewRouterWithTownRoot(d.config.TownRoot, d.config.TownRoot)
 
@@ -256,6 +246,12 @@ func (d *Daemon) dispatchPlugins(mgr *dog.Manager, sm *dog.SessionManager, rigsC
 			}
 		}
 
+		// Refuse plugins whose installed copy drifted from plugins.lock.
+		if err := plugin.Verify(d.config.TownRoot, p); err != nil {
+			d.logger.Printf("Handler: not dispatching plugin %s: %v", p.Name, err)
+			continue
+		}
+
 		// Find an idle dog.
 		idleDog, err := mgr.GetIdleDog()
 		if err != nil {
@@ -267,52 +263,77 @@ func (d *Daemon) dispatchPlugins(mgr *dog.Manager, sm *dog.SessionManager, rigsC
 			return
 		}
 
-		// Assign work and start session.
-		workDesc := fmt.Sprintf("plugin:%s", p.Name)
-		if err := mgr.AssignWork(idleDog.Name, workDesc); err != nil {
-			d.logger.Printf("Handler: failed to assign work to dog %s: %v", idleDog.Name, err)
-			continue
-		}
+		d.dispatchPluginToDog(mgr, sm, router, recorder, p, idleDog, p.FormatMailBody())
+	}
 
-		if err := sm.Start(idleDog.Name, dog.SessionStartOptions{
-			WorkDesc: workDesc,
-		}); err != nil {
-			d.logger.Printf("Handler: failed to start session for dog %s: %v", idleDog.Name, err)
-			// Roll back assignment on session start failure.
-			if clearErr := mgr.ClearWork(idleDog.Name); clearErr != nil {
-				d.logger.Printf("Handler: failed to clear work after start failure for dog %s: %v", idleDog.Name, clearErr)
-			}
-			continue
-		}
+	d.dispatchDueTriggersLocked(mgr, sm, router, recorder)
+}
+
+// dispatchPluginToDog assigns the plugin to idleDog, starts its session and
+// mails it body. Returns false if the dog could not be started.
+func (d *Daemon) dispatchPluginToDog(mgr *dog.Manager, sm *dog.SessionManager, router *mail.Router, recorder *plugin.Recorder, p *plugin.Plugin, idleDog *dog.Dog, body string) bool {
+	// Assign work and start session.
+	workDesc := pluginWorkDesc(p.Name)
+	if err := mgr.AssignWork(idleDog.Name, workDesc); err != nil {
+		d.logger.Printf("Handler: failed to assign work to dog %s: %v", idleDog.Name, err)
+		return false
+	}
 
-		// Send mail with plugin instructions.
-		msg := mail.NewMessage(
-			"daemon",
-			fmt.Sprintf("deacon/dogs/%s", idleDog.Name),
-			fmt.Sprintf("Plugin: %s", p.Name),
-			p.FormatMailBody(),
-		)
-		msg.Type = mail.TypeTask
-		msg.Timestamp = time.Now()
-		if err := router.Send(msg); err != nil {
-			d.logger.Printf("Handler: failed to send mail to dog %s: %v", idleDog.Name, err)
-			// Session is already started — dog will find no mail and idle out.
+	if err := sm.Start(idleDog.Name, dog.SessionStartOptions{
+		WorkDesc: workDesc,
+	}); err != nil {
+		d.logger.Printf("Handler: failed to start session for dog %s: %v", idleDog.Name, err)
+		// Roll back assignment on session start failure.
+		if clearErr := mgr.ClearWork(idleDog.Name); clearErr != nil {
+			d.logger.Printf("Handler: failed to clear work after start failure for dog %s: %v", idleDog.Name, clearErr)
 		}
+		return false
+	}
+
+	// Send mail with plugin instructions.
+	msg := mail.NewMessage(
+		"daemon",
+		fmt.Sprintf("deacon/dogs/%s", idleDog.Name),
+		fmt.Sprintf("Plugin: %s", p.Name),
+		body,
+	)
+	msg.Type = mail.TypeTask
+	msg.Timestamp = time.Now()
+	if err := router.Send(msg); err != nil {
+		d.logger.Printf("Handler: failed to send mail to dog %s: %v", idleDog.Name, err)
+		// Session is already started — dog will find no mail and idle out.
+	}
+
+	d.logger.Printf("Handler: dispatched plugin %s to dog %s", p.Name, idleDog.Name)
+
+	// Record the dispatch immediately so the cooldown gate is satisfied
+	// for the next 1h regardless of what the dog does. Dogs create their
+	// own completion beads but don't reliably use the label convention the
+	// gate requires, causing infinite re-dispatch loops.
+	if _, err := recorder.RecordRun(plugin.PluginRunRecord{
+		PluginName: p.Name,
+		Result:     plugin.ResultSuccess,
+		Body:       fmt.Sprintf("Dispatched to dog %s", idleDog.Name),
+	}); err != nil {
+		d.logger.Printf("Handler: failed to record dispatch for plugin %s: %v", p.Name, err)
+	}
+	return true
+}
+
+// pluginWorkDesc is the work assignment a dog running the plugin carries.
+func pluginWorkDesc(name string) string {
+	return fmt.Sprintf("plugin:%s", name)
+}
 
-		d.logger.Printf("Handler: dispatched plugin %s to dog %s", p.Name, idleDog.Name)
-
-		// Record the dispatch immediately so the cooldown gate is satisfied
-		// for the next 1h regardless of what the dog does. Dogs create their
-		// own completion beads but don't reliably use the label convention the
-		// gate requires, causing infinite re-dispatch loops.
-		if _, err := recorder.RecordRun(plugin.PluginRunRecord{
-			PluginName: p.Name,
-			Result:     plugin.ResultSuccess,
-			Body:       fmt.Sprintf("Dispatched to dog %s", idleDog.Name),
-		}); err != nil {
-			d.logger.Printf("Handler: failed to record dispatch for plugin %s: %v", p.Name, err)
+// rigNamesOf returns the rig names to scan for rig-level plugins.
+func rigNamesOf(rigsConfig *config.RigsConfig) []string {
+	var rigNames []string
+	if rigsConfig != nil {
+		for name := range rigsConfig.Rigs {
+			rigNames = append(rigNames, name)
 		}
 	}
+	return rigNames
 }
 
 // loadRigsConfig loads the rigs configuration from mayor/rigs.json.
@@ -0,0 +1,73 @@
+//go:build linux
+
+package daemon
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/ptyhost"
+	"github.com/steveyegge/gastown/internal/session"
+)
+
+// The daemon's own health checks and kills must see headless sessions, or
+// every live headless agent looks dead to it.
+func TestDaemonSessions_HeadlessBackend(t *testing.T) {
+	// Unix socket paths are length-limited, so avoid t.TempDir's long names.
+	townRoot, err := os.MkdirTemp("", "dmn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(townRoot) })
+
+	settings := config.NewTownSettings()
+	settings.SessionBackend = config.SessionBackendHeadless
+	if err := config.SaveTownSettings(config.TownSettingsPath(townRoot), settings); err != nil {
+		t.Fatal(err)
+	}
+
+	h := ptyhost.NewHost()
+	t.Cleanup(h.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	socket := ptyhost.SocketPath(townRoot)
+	go func() { _ = ptyhost.Serve(ctx, h, socket) }()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(socket); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for ptyhost socket")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if err := h.NewSession(session.DeaconSessionName(), townRoot, "sleep 30"); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &Daemon{
+		config: &Config{TownRoot: townRoot},
+		logger: log.New(io.Discard, "", 0),
+	}
+	if _, ok := d.sessions().(*ptyhost.Client); !ok {
+		t.Fatalf("sessions() = %T, want *ptyhost.Client", d.sessions())
+	}
+	if got := d.countAgentSessions(); got != 1 {
+		t.Errorf("countAgentSessions() = %d, want 1", got)
+	}
+	if alive, err := d.sessions().HasSession(session.DeaconSessionName()); err != nil || !alive {
+		t.Fatalf("HasSession(deacon) = %v, %v; want true", alive, err)
+	}
+
+	d.killDeaconSessions()
+	if alive, _ := d.sessions().HasSession(session.DeaconSessionName()); alive {
+		t.Error("deacon session still running after killDeaconSessions")
+	}
+}
@@ -174,8 +174,9 @@ func (d *Daemon) executeLifecycleAction(request *LifecycleRequest) error {
 		}
 	}
 
-	// Check if session exists (tmux detection still needed for lifecycle actions)
-	running, err := d.tmux.HasSession(sessionName)
+	// Check if session exists (session detection still needed for lifecycle actions)
+	t := d.sessions()
+	running, err := t.HasSession(sessionName)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
 	}
@@ -185,7 +186,7 @@ func (d *Daemon) executeLifecycleAction(request *LifecycleRequest) error {
 		if running {
 			// Use KillSessionWithProcesses to ensure all descendant processes are killed.
 			// This prevents orphan bash processes from Claude's Bash tool surviving session termination.
-			if err := d.tmux.KillSessionWithProcesses(sessionName); err != nil {
+			if err := t.KillSessionWithProcesses(sessionName); err != nil {
 				return fmt.Errorf("killing session: %w", err)
 			}
 			d.logger.Printf("Killed session %s", sessionName)
@@ -195,7 +196,7 @@ func (d *Daemon) executeLifecycleAction(request *LifecycleRequest) error {
 	case ActionCycle, ActionRestart:
 		if running {
 			// Kill the session first - use KillSessionWithProcesses to prevent orphan processes.
-			if err := d.tmux.KillSessionWithProcesses(sessionName); err != nil {
+			if err := t.KillSessionWithProcesses(sessionName); err != nil {
 				return fmt.Errorf("killing session: %w", err)
 			}
 			d.logger.Printf("Killed session %s for restart", sessionName)
@@ -375,8 +376,9 @@ func (d *Daemon) restartSession(sessionName, identity string) error {
 	startCmd := d.getStartCommand(config, parsed)
 
 	// Create session with command as initial process (replaces EnsureSessionFresh + SendKeys).
-	// EnsureSessionFreshWithCommand kills zombie sessions and creates a new one atomically.
-	if err := d.tmux.EnsureSessionFreshWithCommand(sessionName, workDir, startCmd); err != nil {
+	// ensureSessionFresh kills zombie sessions and creates a new one atomically.
+	t := d.sessions()
+	if err := ensureSessionFresh(t, sessionName, workDir, startCmd); err != nil {
 		if errors.Is(err, tmux.ErrSessionRunning) {
 			d.logger.Printf("Session %s already running with healthy agent, skipping restart", sessionName)
 			return nil
@@ -391,15 +393,39 @@ func (d *Daemon) restartSession(sessionName, identity string) error {
 	d.applySessionTheme(sessionName, parsed)
 
 	// Wait for Claude to start, then accept startup dialogs if they appear.
-	if err := d.tmux.WaitForCommand(sessionName, constants.SupportedShells, constants.ClaudeStartTimeout); err != nil {
+	if err := t.WaitForCommand(sessionName, constants.SupportedShells, constants.ClaudeStartTimeout); err != nil {
 		// Non-fatal - Claude might still start
 	}
-	_ = d.tmux.AcceptStartupDialogs(sessionName)
+	if acceptor, ok := t.(session.DialogAcceptor); ok {
+		_ = acceptor.AcceptStartupDialogs(sessionName)
+	}
 	time.Sleep(constants.ShutdownNotifyDelay)
 
 	return nil
 }
 
+// ensureSessionFresh creates a session running command, replacing a zombie
+// session whose agent has exited. Returns tmux.ErrSessionRunning if the
+// session already runs a healthy agent.
+func ensureSessionFresh(t session.SessionBackend, name, workDir, command string) error {
+	if tm, ok := t.(*tmux.Tmux); ok {
+		return tm.EnsureSessionFreshWithCommand(name, workDir, command)
+	}
+	running, err := t.HasSession(name)
+	if err != nil {
+		return fmt.Errorf("checking session: %w", err)
+	}
+	if running {
+		if isAgentRunning(t, name) {
+			return tmux.ErrSessionRunning
+		}
+		if err := t.KillSessionWithProcesses(name); err != nil {
+			return fmt.Errorf("killing zombie session: %w", err)
+		}
+	}
+	return t.NewSessionWithCommand(name, workDir, command)
+}
+
 // getWorkDir determines the working directory for an agent.
 // Uses role config if available, falls back to hardcoded defaults.
 func (d *Daemon) getWorkDir(config *beads.RoleConfig, parsed *ParsedIdentity) string {
@@ -556,7 +582,7 @@ func (d *Daemon) getStartCommand(roleConfig *beads.RoleConfig, parsed *ParsedIde
 	return defaultCmd
 }
 
-// setSessionEnvironment sets environment variables for the tmux session.
+// setSessionEnvironment sets environment variables for the session.
 // Uses centralized AgentEnv for consistency, plus custom env vars from role config if available.
 func (d *Daemon) setSessionEnvironment(sessionName string, roleConfig *beads.RoleConfig, parsed *ParsedIdentity) {
 	// Use centralized AgentEnv for base environment variables
@@ -567,13 +593,14 @@ func (d *Daemon) setSessionEnvironment(sessionName string, roleConfig *beads.Rol
 		TownRoot:    d.config.TownRoot,
 		SessionName: sessionName,
 	})
+	t := d.sessions()
 	for k, v := range envVars {
-		_ = d.tmux.SetEnvironment(sessionName, k, v)
+		_ = t.SetEnvironment(sessionName, k, v)
 	}
 
 	// Record agent's pane_id for ZFC-compliant liveness checks (gt-qmsx).
-	if paneID, err := d.tmux.GetPaneID(sessionName); err == nil {
-		_ = d.tmux.SetEnvironment(sessionName, "GT_PANE_ID", paneID)
+	if paneID, err := t.GetPaneID(sessionName); err == nil {
+		_ = t.SetEnvironment(sessionName, "GT_PANE_ID", paneID)
 	}
 
 	// Set any custom env vars from role config.
@@ -586,13 +613,18 @@ func (d *Daemon) setSessionEnvironment(sessionName string, roleConfig *beads.Rol
 				continue
 			}
 			expanded := beads.ExpandRolePattern(v, d.config.TownRoot, parsed.RigName, parsed.AgentName, parsed.RoleType, session.PrefixFor(parsed.RigName))
-			_ = d.tmux.SetEnvironment(sessionName, k, expanded)
+			_ = t.SetEnvironment(sessionName, k, expanded)
 		}
 	}
 }
 
-// applySessionTheme applies tmux theming to the session.
+// applySessionTheme applies tmux theming to the session, if the backend
+// supports it.
 func (d *Daemon) applySessionTheme(sessionName string, parsed *ParsedIdentity) {
+	themer, ok := d.sessions().(session.Themer)
+	if !ok {
+		return
+	}
 	rigName := parsed.RigName
 	role := parsed.RoleType
 	worker := parsed.RoleType
@@ -601,7 +633,7 @@ func (d *Daemon) applySessionTheme(sessionName string, parsed *ParsedIdentity) {
 		worker = "Mayor"
 	}
 	theme := tmux.ResolveSessionTheme(d.config.TownRoot, rigName, role)
-	_ = d.tmux.ConfigureGasTownSession(sessionName, theme, rigName, worker, role)
+	_ = themer.ConfigureGasTownSession(sessionName, theme, rigName, worker, role)
 }
 
 // syncFailureEscalationThreshold is the default number of consecutive pull failures
@@ -1074,6 +1106,7 @@ func (d *Daemon) checkRigGUPPViolations(rigName string) {
 	rigPrefix := config.GetRigPrefix(d.config.TownRoot, rigName)
 	// Pattern: <prefix>-<rig>-polecat-<name>
 	prefix := rigPrefix + "-" + rigName + "-polecat-"
+	t := d.sessions()
 	for _, agent := range agents {
 		// Only check polecats for this rig
 		if !strings.HasPrefix(agent.ID, prefix) {
@@ -1097,8 +1130,8 @@ func (d *Daemon) checkRigGUPPViolations(rigName string) {
 		polecatName := strings.TrimPrefix(agent.ID, prefix)
 		sessionName := session.PolecatSessionName(session.PrefixFor(rigName), polecatName)
 
-		// Check if tmux session exists and agent is running
-		if d.tmux.IsAgentAlive(sessionName) {
+		// Check if the session exists and agent is running
+		if t.IsAgentAlive(sessionName) {
 			// Session is alive - check if it's been stuck too long
 			updatedAt, err := time.Parse(time.RFC3339, agent.UpdatedAt)
 			if err != nil {
@@ -1184,6 +1217,7 @@ func (d *Daemon) checkRigOrphanedWork(rigName string) {
 	rigPrefix := config.GetRigPrefix(d.config.TownRoot, rigName)
 	// Pattern: <prefix>-<rig>-polecat-<name>
 	prefix := rigPrefix + "-" + rigName + "-polecat-"
+	t := d.sessions()
 	for _, agent := range agents {
 		// Only check polecats for this rig
 		if !strings.HasPrefix(agent.ID, prefix) {
@@ -1206,14 +1240,14 @@ func (d *Daemon) checkRigOrphanedWork(rigName string) {
 		sessionName := session.PolecatSessionName(session.PrefixFor(rigName), polecatName)
 
 		// Session running = not orphaned (work is being processed)
-		if d.tmux.IsAgentAlive(sessionName) {
+		if t.IsAgentAlive(sessionName) {
 			continue
 		}
 
 		// TOCTOU guard: re-verify agent state before taking action.
 		// Between the bd list above and now, the agent may have been
 		// restarted or its hook_bead cleared. Re-check both conditions.
-		if d.tmux.IsAgentAlive(sessionName) {
+		if t.IsAgentAlive(sessionName) {
 			continue
 		}
 		currentHookBead := d.getAgentHookBead(agent.ID)
@@ -0,0 +1,565 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/dog"
+	"github.com/steveyegge/gastown/internal/eventbus"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/plugin"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+const (
+	// pluginTriggersCursor is the event bus cursor for event-gated plugins.
+	// It is held back to the oldest event with an undispatched trigger, so
+	// triggers pending when the daemon stops are replayed on restart.
+	pluginTriggersCursor = "daemon.plugin-triggers"
+
+	// pluginTriggerAcksFile records how far each plugin is past the bus
+	// cursor, so a replay after restart only re-fires the plugins whose
+	// triggers were still pending.
+	pluginTriggerAcksFile = "plugin-triggers.json"
+
+	// pluginTriggerQueueLimit caps the triggers queued per plugin. Events
+	// beyond it coalesce into the newest trigger, as a debounce would.
+	pluginTriggerQueueLimit = 100
+
+	// pluginRescanInterval bounds how stale the watcher's view of the
+	// plugin directories may get.
+	pluginRescanInterval = 30 * time.Second
+
+	// pluginTriggerRetry is how soon a due trigger that found no dog (or
+	// hit its concurrency cap) is tried again.
+	pluginTriggerRetry = 30 * time.Second
+
+	// pluginEventFileTTL is how long trigger files for run.sh are kept.
+	pluginEventFileTTL = 24 * time.Hour
+)
+
+// pendingTrigger is an event-gated plugin waiting to be dispatched.
+type pendingTrigger struct {
+	plugin  *plugin.Plugin
+	trigger *plugin.Trigger
+
+	// firstSeq is the oldest event folded into the trigger (0 for
+	// synthesized events), which the bus cursor must not pass.
+	firstSeq uint64
+
+	// due is when the debounce window closes.
+	due time.Time
+
+	// deadline is the latest due may move to as events are coalesced: the
+	// first event's time plus the gate's max_wait.
+	deadline time.Time
+
+	// notBefore holds back a trigger that was due but could not be
+	// dispatched, so it is retried no sooner than pluginTriggerRetry.
+	notBefore time.Time
+}
+
+// ready returns when pt may be dispatched.
+func (pt *pendingTrigger) ready() time.Time {
+	if pt.notBefore.After(pt.due) {
+		return pt.notBefore
+	}
+	return pt.due
+}
+
+// pluginTriggerAcks records how far each plugin has seen the event bus:
+// every event up to Processed, except for the plugins in Held, which have
+// seen events only up to their entry because a trigger from the next one is
+// still pending. The bus cursor is the lowest of these.
+type pluginTriggerAcks struct {
+	Processed uint64            `json:"processed"`
+	Held      map[string]uint64 `json:"held,omitempty"`
+}
+
+// through returns the last event the named plugin has seen.
+func (a *pluginTriggerAcks) through(name string) uint64 {
+	if held, ok := a.Held[name]; ok {
+		return held
+	}
+	return a.Processed
+}
+
+// pluginTriggers queues event-gated plugin triggers, oldest first per
+// plugin.
+type pluginTriggers struct {
+	mu      sync.Mutex
+	pending map[string][]*pendingTrigger
+
+	// acks is how far each plugin has seen the bus; acksDirty is set when
+	// it changed in a way the bus cursor alone does not record.
+	acks      pluginTriggerAcks
+	acksDirty bool
+}
+
+func newPluginTriggers() *pluginTriggers {
+	return &pluginTriggers{pending: make(map[string][]*pendingTrigger)}
+}
+
+// observe queues t for every plugin whose gate fires on it, skipping
+// plugins that already saw the event before a restart. Without a debounce
+// every event gets its own trigger. With one, an event arriving while the
+// plugin's newest trigger is still inside its window replaces that trigger
+// and restarts the window, up to the gate's max_wait after the first event.
+// Once a plugin has pluginTriggerQueueLimit triggers queued, further events
+// coalesce into the newest one. Returns the names of the plugins triggered.
+func (q *pluginTriggers) observe(plugins []*plugin.Plugin, t *plugin.Trigger, now time.Time) []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var fired []string
+	for _, p := range plugins {
+		if t.Seq != 0 && t.Seq <= q.acks.through(p.Name) {
+			continue // Seen before the daemon restarted
+		}
+		if !p.Gate.Fires(t) {
+			continue
+		}
+		fired = append(fired, p.Name)
+		next := *t
+		debounce, _ := p.Gate.DebounceDuration()
+		queue := q.pending[p.Name]
+		if len(queue) > 0 {
+			prev := queue[len(queue)-1]
+			inWindow := debounce > 0 && now.Before(prev.due)
+			if inWindow || len(queue) >= pluginTriggerQueueLimit {
+				next.Coalesced = prev.trigger.Coalesced + 1
+				pt := &pendingTrigger{plugin: p, trigger: &next, firstSeq: prev.firstSeq,
+					due: prev.due, deadline: prev.deadline, notBefore: prev.notBefore}
+				if pt.firstSeq == 0 {
+					pt.firstSeq = t.Seq
+				}
+				if inWindow {
+					pt.due = now.Add(debounce)
+					if pt.due.After(pt.deadline) {
+						pt.due = pt.deadline
+					}
+				}
+				queue[len(queue)-1] = pt
+				continue
+			}
+		}
+		maxWait, _ := p.Gate.MaxWaitDuration()
+		pt := &pendingTrigger{plugin: p, trigger: &next, firstSeq: t.Seq, due: now.Add(debounce), deadline: now.Add(maxWait)}
+		q.pending[p.Name] = append(queue, pt)
+	}
+	return fired
+}
+
+// due returns the triggers that may be dispatched now, oldest first.
+func (q *pluginTriggers) due(now time.Time) []*pendingTrigger {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var out []*pendingTrigger
+	for _, queue := range q.pending {
+		for _, pt := range queue {
+			if !pt.ready().After(now) {
+				out = append(out, pt)
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].due.Before(out[j].due) })
+	return out
+}
+
+// done removes pt from the queue unless a newer trigger replaced it.
+func (q *pluginTriggers) done(pt *pendingTrigger) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	name := pt.plugin.Name
+	queue := q.pending[name]
+	for i, queued := range queue {
+		if queued == pt {
+			queue = append(queue[:i:i], queue[i+1:]...)
+			break
+		}
+	}
+	if len(queue) == 0 {
+		delete(q.pending, name)
+	} else {
+		q.pending[name] = queue
+	}
+}
+
+// deferDue holds back every trigger that is ready at now until
+// pluginTriggerRetry later. Called after a dispatch pass, it spaces out
+// retries of triggers that found no dog without delaying triggers queued
+// afterwards.
+func (q *pluginTriggers) deferDue(now time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, queue := range q.pending {
+		for _, pt := range queue {
+			if !pt.ready().After(now) {
+				pt.notBefore = now.Add(pluginTriggerRetry)
+			}
+		}
+	}
+}
+
+// nextDue returns when the earliest pending trigger may be dispatched.
+func (q *pluginTriggers) nextDue() (time.Time, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var next time.Time
+	for _, queue := range q.pending {
+		for _, pt := range queue {
+			if at := pt.ready(); next.IsZero() || at.Before(next) {
+				next = at
+			}
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// ackSeq records that every event up to processed has been observed and
+// returns how far the bus cursor may advance: not past an event that still
+// has a pending trigger. Each plugin is acked on its own, so one plugin
+// that cannot be dispatched holds back only its own triggers; the others
+// are not replayed after a restart.
+func (q *pluginTriggers) ackSeq(processed uint64) uint64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// Processed never moves back: replay after a restart starts below it.
+	acks := pluginTriggerAcks{Processed: max(processed, q.acks.Processed), Held: make(map[string]uint64)}
+	through := func(name string) uint64 {
+		// A plugin held before a restart stays held until replay passes it.
+		return min(max(processed, q.acks.through(name)), acks.Processed)
+	}
+	for name := range q.acks.Held {
+		if at := through(name); at < acks.Processed {
+			acks.Held[name] = at
+		}
+	}
+	for name, queue := range q.pending {
+		for _, pt := range queue {
+			if pt.firstSeq == 0 {
+				continue // Synthesized events never hold the cursor back
+			}
+			at, ok := acks.Held[name]
+			if !ok {
+				at = through(name)
+			}
+			acks.Held[name] = min(at, pt.firstSeq-1)
+		}
+	}
+
+	ack := acks.Processed
+	for _, at := range acks.Held {
+		ack = min(ack, at)
+	}
+	if len(acks.Held) > 0 || len(q.acks.Held) > 0 {
+		q.acksDirty = true
+	}
+	if len(acks.Held) == 0 {
+		acks.Held = nil
+	}
+	q.acks = acks
+	return ack
+}
+
+// loadAcks restores the acks saved in path. With none saved every plugin
+// starts at cursor, the bus cursor.
+func (q *pluginTriggers) loadAcks(path string, cursor uint64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.acks = pluginTriggerAcks{Processed: cursor}
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed internally
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var saved pluginTriggerAcks
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+	// The file is only rewritten while a plugin is held, so the bus cursor
+	// may have moved past it since.
+	q.acks.Processed = max(saved.Processed, cursor)
+	for name, at := range saved.Held {
+		if at >= cursor && at < q.acks.Processed {
+			if q.acks.Held == nil {
+				q.acks.Held = make(map[string]uint64)
+			}
+			q.acks.Held[name] = at
+		}
+	}
+	return nil
+}
+
+// saveAcks writes the acks to path if they changed since the last save.
+// Call it before acking the bus cursor, so the file is never behind it.
+func (q *pluginTriggers) saveAcks(path string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.acksDirty {
+		return nil
+	}
+	if err := util.AtomicWriteJSON(path, q.acks); err != nil {
+		return err
+	}
+	q.acksDirty = false
+	return nil
+}
+
+// eventGatedPlugins returns the discovered plugins with event gates.
+func (d *Daemon) eventGatedPlugins() []*plugin.Plugin {
+	rigsConfig, err := d.loadRigsConfig()
+	if err != nil {
+		rigsConfig = nil
+	}
+	plugins, err := plugin.NewScanner(d.config.TownRoot, rigNamesOf(rigsConfig)).DiscoverAll()
+	if err != nil {
+		d.logger.Printf("Plugin triggers: failed to discover plugins: %v", err)
+		return nil
+	}
+	var out []*plugin.Plugin
+	for _, p := range plugins {
+		if p.Gate == nil || p.Gate.Type != plugin.GateEvent {
+			continue
+		}
+		if len(p.Gate.On) == 0 {
+			d.logger.Printf("Plugin triggers: plugin %s has an event gate with no events, skipping", p.Name)
+			continue
+		}
+		if _, err := p.Gate.MaxWaitDuration(); err != nil {
+			d.logger.Printf("Plugin triggers: plugin %s: %v, skipping", p.Name, err)
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// watchPluginEvents feeds activity and channel events from the bus to
+// event-gated plugins and dispatches their triggers as debounce windows
+// close. Runs until the daemon stops.
+func (d *Daemon) watchPluginEvents(bus *eventbus.Bus) {
+	// Start a fresh cursor at the head of the log so the first run does not
+	// fire plugins for history.
+	if _, ok := bus.Cursor(pluginTriggersCursor); !ok {
+		if last := bus.LastSeq(); last > 0 {
+			if err := bus.Ack(pluginTriggersCursor, last); err != nil {
+				d.logger.Printf("Plugin triggers: failed to initialize cursor: %v", err)
+			}
+		}
+	}
+
+	acksPath := filepath.Join(d.config.TownRoot, "daemon", pluginTriggerAcksFile)
+	cursor, _ := bus.Cursor(pluginTriggersCursor)
+	if err := d.pluginTriggers.loadAcks(acksPath, cursor); err != nil {
+		d.logger.Printf("Plugin triggers: failed to load plugin acks, replaying for every plugin: %v", err)
+	}
+	ack := func(processed uint64) {
+		seq := d.pluginTriggers.ackSeq(processed)
+		if err := d.pluginTriggers.saveAcks(acksPath); err != nil {
+			d.logger.Printf("Plugin triggers: failed to save plugin acks: %v", err)
+			return // Keep the cursor where the saved acks expect it
+		}
+		if seq > 0 {
+			if err := bus.Ack(pluginTriggersCursor, seq); err != nil {
+				d.logger.Printf("Plugin triggers: failed to ack cursor: %v", err)
+			}
+		}
+	}
+
+	msgs, err := bus.Subscribe(d.ctx, eventbus.SubscribeOptions{
+		Topics: []string{eventbus.TopicActivity + ".>", eventbus.TopicChannel + ".>"},
+		Cursor: pluginTriggersCursor,
+	})
+	if err != nil {
+		d.logger.Printf("Plugin triggers: failed to subscribe to event bus: %v", err)
+		return
+	}
+
+	plugins := d.eventGatedPlugins()
+	scannedAt := time.Now()
+	d.pluginTriggers.observe(plugins, &plugin.Trigger{
+		Topic: "daemon." + plugin.EventStartup,
+		Type:  plugin.EventStartup,
+		Time:  time.Now().UTC(),
+		Event: json.RawMessage(`{"type":"startup","source":"daemon"}`),
+	}, time.Now())
+
+	var processed uint64
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case m, ok := <-msgs:
+			if !ok {
+				if d.ctx.Err() == nil {
+					d.logger.Printf("Plugin triggers: event bus subscription ended")
+				}
+				return
+			}
+			if time.Since(scannedAt) > pluginRescanInterval {
+				plugins = d.eventGatedPlugins()
+				scannedAt = time.Now()
+			}
+			t := plugin.NewTrigger(m)
+			if t != nil {
+				for _, name := range d.pluginTriggers.observe(plugins, t, time.Now()) {
+					d.logger.Printf("Plugin triggers: %s triggered by %s", name, t.Describe())
+				}
+			}
+			processed = m.Seq
+			ack(processed)
+		case <-timer.C:
+			d.runDueTriggers()
+			d.pluginTriggers.deferDue(time.Now())
+			if processed > 0 {
+				ack(processed)
+			}
+		}
+
+		if next, ok := d.pluginTriggers.nextDue(); ok {
+			resetTimer(timer, max(time.Until(next), 0))
+		}
+	}
+}
+
+// resetTimer stops, drains and resets t.
+func resetTimer(t *time.Timer, d time.Duration) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+	t.Reset(d)
+}
+
+// runDueTriggers dispatches due plugin triggers outside the heartbeat,
+// subject to the same patrol and pressure gates as dispatchPlugins.
+func (d *Daemon) runDueTriggers() {
+	if len(d.pluginTriggers.due(time.Now())) == 0 {
+		return
+	}
+	if !d.isPatrolActive("handler") {
+		return
+	}
+	if p := d.checkPressure("dog"); !p.OK {
+		d.logger.Printf("Plugin triggers: deferring dispatch: %s", p.Reason)
+		return
+	}
+	rigsConfig, err := d.loadRigsConfig()
+	if err != nil {
+		d.logger.Printf("Plugin triggers: failed to load rigs config: %v", err)
+		return
+	}
+
+	mgr := dog.NewManager(d.config.TownRoot, rigsConfig)
+	sm := dog.NewSessionManager(d.tmux, d.config.TownRoot, mgr)
+	recorder := plugin.NewRecorder(d.config.TownRoot)
+	router := mail.NewRouterWithTownRoot(d.config.TownRoot, d.config.TownRoot)
+
+	d.pluginDispatchMu.Lock()
+	defer d.pluginDispatchMu.Unlock()
+	d.dispatchDueTriggersLocked(mgr, sm, router, recorder)
+}
+
+// dispatchDueTriggersLocked dispatches due triggers to idle dogs, leaving
+// queued any plugin already running on its max_concurrent dogs. The caller
+// holds pluginDispatchMu.
+func (d *Daemon) dispatchDueTriggersLocked(mgr *dog.Manager, sm *dog.SessionManager, router *mail.Router, recorder *plugin.Recorder) {
+	if d.pluginTriggers == nil {
+		return
+	}
+	due := d.pluginTriggers.due(time.Now())
+	if len(due) == 0 {
+		return
+	}
+
+	dogs, err := mgr.List()
+	if err != nil {
+		d.logger.Printf("Plugin triggers: failed to list dogs: %v", err)
+		return
+	}
+	running := make(map[string]int)
+	for _, dg := range dogs {
+		if dg.State == dog.StateWorking {
+			running[dg.Work]++
+		}
+	}
+
+	for _, pt := range due {
+		p := pt.plugin
+		workDesc := pluginWorkDesc(p.Name)
+		if limit := p.Gate.ConcurrencyLimit(); running[workDesc] >= limit {
+			continue // Wait for a running copy to finish
+		}
+		if err := plugin.Verify(d.config.TownRoot, p); err != nil {
+			d.logger.Printf("Plugin triggers: dropping trigger for %s: %v", p.Name, err)
+			d.pluginTriggers.done(pt)
+			continue
+		}
+
+		idleDog, err := mgr.GetIdleDog()
+		if err != nil {
+			d.logger.Printf("Plugin triggers: error finding idle dog: %v", err)
+			return
+		}
+		if idleDog == nil {
+			d.logger.Printf("Plugin triggers: no idle dogs available, deferring %d trigger(s)", len(due))
+			return
+		}
+
+		eventFile, err := d.writePluginEventFile(p.Name, pt.trigger)
+		if err != nil {
+			d.logger.Printf("Plugin triggers: %v", err)
+			continue
+		}
+		if !d.dispatchPluginToDog(mgr, sm, router, recorder, p, idleDog, p.FormatTriggeredMailBody(pt.trigger, eventFile)) {
+			continue
+		}
+		running[workDesc]++
+		d.pluginTriggers.done(pt)
+	}
+}
+
+// writePluginEventFile saves the trigger as JSON for the plugin's run.sh
+// under daemon/plugin-events, pruning files older than a day.
+func (d *Daemon) writePluginEventFile(name string, t *plugin.Trigger) (string, error) {
+	dir := filepath.Join(d.config.TownRoot, "daemon", "plugin-events")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating plugin event directory: %w", err)
+	}
+	if entries, err := os.ReadDir(dir); err == nil {
+		for _, e := range entries {
+			if info, err := e.Info(); err == nil && time.Since(info.ModTime()) > pluginEventFileTTL {
+				_ = os.Remove(filepath.Join(dir, e.Name()))
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encoding trigger for plugin %s: %w", name, err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%d.json", name, time.Now().UnixNano()))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("writing trigger for plugin %s: %w", name, err)
+	}
+	return path, nil
+}
@@ -0,0 +1,283 @@
+package daemon
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/plugin"
+)
+
+func testEventPlugin(name, debounce string, on ...string) *plugin.Plugin {
+	return &plugin.Plugin{
+		Name: name,
+		Gate: &plugin.Gate{Type: plugin.GateEvent, On: on, Debounce: debounce},
+	}
+}
+
+func testTrigger(seq uint64, eventType string) *plugin.Trigger {
+	return &plugin.Trigger{
+		Seq:   seq,
+		Topic: "activity." + eventType,
+		Type:  eventType,
+		Event: json.RawMessage(`{"type":"` + eventType + `"}`),
+	}
+}
+
+func TestPluginTriggers_DebounceCoalesces(t *testing.T) {
+	q := newPluginTriggers()
+	plugins := []*plugin.Plugin{
+		testEventPlugin("on-done", "30s", "done"),
+		testEventPlugin("on-sling", "", "sling"),
+	}
+	now := time.Now()
+
+	if fired := q.observe(plugins, testTrigger(5, "done"), now); len(fired) != 1 || fired[0] != "on-done" {
+		t.Fatalf("fired = %v, want [on-done]", fired)
+	}
+	q.observe(plugins, testTrigger(6, "done"), now.Add(20*time.Second))
+	q.observe(plugins, testTrigger(7, "sling"), now.Add(20*time.Second))
+
+	// The sling plugin has no debounce; the done plugin waits for 30s of quiet.
+	due := q.due(now.Add(40 * time.Second))
+	if len(due) != 1 || due[0].plugin.Name != "on-sling" {
+		t.Fatalf("due at +40s = %d triggers, want on-sling only", len(due))
+	}
+	q.done(due[0])
+
+	due = q.due(now.Add(50 * time.Second))
+	if len(due) != 1 || due[0].plugin.Name != "on-done" {
+		t.Fatalf("due at +50s = %d triggers, want on-done", len(due))
+	}
+	if tr := due[0].trigger; tr.Seq != 6 || tr.Coalesced != 1 {
+		t.Errorf("trigger = seq %d coalesced %d, want seq 6 coalesced 1", tr.Seq, tr.Coalesced)
+	}
+	if due[0].firstSeq != 5 {
+		t.Errorf("firstSeq = %d, want 5", due[0].firstSeq)
+	}
+}
+
+func TestPluginTriggers_OnePerEventWithoutDebounce(t *testing.T) {
+	q := newPluginTriggers()
+	plugins := []*plugin.Plugin{testEventPlugin("on-done", "", "done")}
+	now := time.Now()
+
+	for seq := uint64(1); seq <= 3; seq++ {
+		q.observe(plugins, testTrigger(seq, "done"), now)
+	}
+	due := q.due(now)
+	if len(due) != 3 {
+		t.Fatalf("due = %d triggers, want one per event", len(due))
+	}
+	for i, pt := range due {
+		if want := uint64(i + 1); pt.trigger.Seq != want || pt.trigger.Coalesced != 0 {
+			t.Errorf("trigger %d = seq %d coalesced %d, want seq %d uncoalesced", i, pt.trigger.Seq, pt.trigger.Coalesced, want)
+		}
+	}
+}
+
+func TestPluginTriggers_MaxWaitBoundsDebounce(t *testing.T) {
+	q := newPluginTriggers()
+	p := testEventPlugin("on-done", "30s", "done")
+	p.Gate.MaxWait = "1m"
+	plugins := []*plugin.Plugin{p}
+	now := time.Now()
+
+	// An event every 20s never leaves 30s of quiet; max_wait dispatches
+	// the burst a minute after it started anyway.
+	for i := 0; i <= 2; i++ {
+		q.observe(plugins, testTrigger(uint64(i+1), "done"), now.Add(time.Duration(i)*20*time.Second))
+	}
+	due := q.due(now.Add(time.Minute))
+	if len(due) != 1 {
+		t.Fatalf("due at +1m = %d triggers, want 1", len(due))
+	}
+	if tr := due[0].trigger; tr.Seq != 3 || tr.Coalesced != 2 || due[0].firstSeq != 1 {
+		t.Errorf("trigger = seq %d coalesced %d first %d, want seq 3 coalesced 2 first 1", tr.Seq, tr.Coalesced, due[0].firstSeq)
+	}
+
+	// Once the window has closed, the next event starts a new trigger.
+	q.observe(plugins, testTrigger(4, "done"), now.Add(time.Minute))
+	q.done(due[0])
+	due = q.due(now.Add(90 * time.Second))
+	if len(due) != 1 || due[0].trigger.Seq != 4 || due[0].trigger.Coalesced != 0 {
+		t.Fatalf("due at +90s = %d triggers, want a fresh trigger for seq 4", len(due))
+	}
+}
+
+func TestPluginTriggers_DeferDueOnlyDelaysRetries(t *testing.T) {
+	q := newPluginTriggers()
+	plugins := []*plugin.Plugin{testEventPlugin("on-done", "", "done")}
+	now := time.Now()
+
+	q.observe(plugins, testTrigger(1, "done"), now)
+	q.deferDue(now) // the dispatch pass found no dog
+	q.observe(plugins, testTrigger(2, "done"), now.Add(time.Second))
+
+	due := q.due(now.Add(time.Second))
+	if len(due) != 1 || due[0].trigger.Seq != 2 {
+		t.Fatalf("due right after the pass = %d triggers, want the new one only", len(due))
+	}
+	if next, _ := q.nextDue(); !next.Equal(due[0].due) {
+		t.Errorf("nextDue = %v, want the new trigger's %v", next, due[0].due)
+	}
+	if due := q.due(now.Add(pluginTriggerRetry)); len(due) != 2 {
+		t.Errorf("due after the retry interval = %d triggers, want 2", len(due))
+	}
+}
+
+func TestPluginTriggers_AckHoldsBackPending(t *testing.T) {
+	q := newPluginTriggers()
+	plugins := []*plugin.Plugin{testEventPlugin("on-done", "", "done")}
+	now := time.Now()
+
+	if ack := q.ackSeq(3); ack != 3 {
+		t.Errorf("ack with nothing pending = %d, want 3", ack)
+	}
+	q.observe(plugins, testTrigger(4, "done"), now)
+	q.observe(plugins, testTrigger(9, "done"), now)
+	if ack := q.ackSeq(10); ack != 3 {
+		t.Errorf("ack with trigger pending since 4 = %d, want 3", ack)
+	}
+
+	// A trigger queued while others are being dispatched stays queued.
+	due := q.due(now)
+	q.observe(plugins, testTrigger(11, "done"), now)
+	q.done(due[0])
+	if ack := q.ackSeq(11); ack != 8 {
+		t.Errorf("ack after dispatching 4 = %d, want 8", ack)
+	}
+	q.done(due[1])
+	if ack := q.ackSeq(11); ack != 10 {
+		t.Errorf("ack after dispatching 9 = %d, want 10", ack)
+	}
+	q.done(q.due(now)[0])
+	if ack := q.ackSeq(11); ack != 11 {
+		t.Errorf("ack after dispatch = %d, want 11", ack)
+	}
+
+	// Synthesized events never hold the cursor back.
+	q.observe([]*plugin.Plugin{testEventPlugin("on-startup", "", plugin.EventStartup)}, testTrigger(0, plugin.EventStartup), now)
+	if ack := q.ackSeq(12); ack != 12 {
+		t.Errorf("ack with startup trigger pending = %d, want 12", ack)
+	}
+}
+
+func TestPluginTriggers_QueueLimitCoalesces(t *testing.T) {
+	q := newPluginTriggers()
+	plugins := []*plugin.Plugin{testEventPlugin("on-done", "", "done")}
+	now := time.Now()
+
+	for seq := uint64(1); seq <= pluginTriggerQueueLimit+5; seq++ {
+		q.observe(plugins, testTrigger(seq, "done"), now)
+	}
+	due := q.due(now)
+	if len(due) != pluginTriggerQueueLimit {
+		t.Fatalf("due = %d triggers, want %d", len(due), pluginTriggerQueueLimit)
+	}
+	last := due[len(due)-1]
+	if last.trigger.Seq != pluginTriggerQueueLimit+5 || last.trigger.Coalesced != 5 || last.firstSeq != pluginTriggerQueueLimit {
+		t.Errorf("last trigger = seq %d coalesced %d first %d, want seq %d coalesced 5 first %d",
+			last.trigger.Seq, last.trigger.Coalesced, last.firstSeq, pluginTriggerQueueLimit+5, pluginTriggerQueueLimit)
+	}
+}
+
+func TestPluginTriggers_ReplayOnlyHeldPlugins(t *testing.T) {
+	path := filepath.Join(t.TempDir(), pluginTriggerAcksFile)
+	plugins := []*plugin.Plugin{
+		testEventPlugin("stuck", "", "done"),
+		testEventPlugin("busy", "", "done"),
+	}
+	now := time.Now()
+
+	// "stuck" never finds a dog; "busy" is dispatched for every event.
+	q := newPluginTriggers()
+	if err := q.loadAcks(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	var cursor uint64
+	for seq := uint64(1); seq <= 3; seq++ {
+		q.observe(plugins, testTrigger(seq, "done"), now)
+		for _, pt := range q.due(now) {
+			if pt.plugin.Name == "busy" {
+				q.done(pt)
+			}
+		}
+		cursor = q.ackSeq(seq)
+		if err := q.saveAcks(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cursor != 0 {
+		t.Fatalf("cursor = %d, want 0 (held back by stuck)", cursor)
+	}
+
+	// After a restart the bus replays events 1-3: only "stuck" fires again.
+	q = newPluginTriggers()
+	if err := q.loadAcks(path, cursor); err != nil {
+		t.Fatal(err)
+	}
+	for seq := uint64(1); seq <= 3; seq++ {
+		if fired := q.observe(plugins, testTrigger(seq, "done"), now); len(fired) != 1 || fired[0] != "stuck" {
+			t.Errorf("replay of %d fired %v, want [stuck]", seq, fired)
+		}
+		q.ackSeq(seq)
+	}
+	if fired := q.observe(plugins, testTrigger(4, "done"), now); len(fired) != 2 {
+		t.Errorf("new event fired %v, want both plugins", fired)
+	}
+
+	// Once stuck is dispatched the cursor catches up and the file is cleared.
+	for _, pt := range q.due(now) {
+		q.done(pt)
+	}
+	if got := q.ackSeq(4); got != 4 {
+		t.Errorf("cursor after dispatch = %d, want 4", got)
+	}
+	if err := q.saveAcks(path); err != nil {
+		t.Fatal(err)
+	}
+	q = newPluginTriggers()
+	if err := q.loadAcks(path, 4); err != nil {
+		t.Fatal(err)
+	}
+	if len(q.acks.Held) != 0 || q.acks.Processed != 4 {
+		t.Errorf("reloaded acks = %+v, want processed 4 with none held", q.acks)
+	}
+}
+
+func TestWritePluginEventFile(t *testing.T) {
+	townRoot := t.TempDir()
+	d := testHandlerDaemon(t, townRoot)
+
+	dir := filepath.Join(townRoot, "daemon", "plugin-events")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	stale := filepath.Join(dir, "old-1.json")
+	if err := os.WriteFile(stale, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * pluginEventFileTTL)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := d.writePluginEventFile("on-done", testTrigger(4, "done"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got plugin.Trigger
+	if err := json.Unmarshal(data, &got); err != nil || got.Seq != 4 || got.Type != "done" {
+		t.Errorf("event file = %s (%v)", data, err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("expected stale event file to be pruned")
+	}
+}
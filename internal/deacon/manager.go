@@ -20,21 +20,17 @@ var (
 	ErrAlreadyRunning = errors.New("deacon already running")
 )
 
-// tmuxOps abstracts tmux operations for testing.
+// tmuxOps abstracts the session operations the deacon needs, for testing.
+// Backend extras (remain-on-exit, theming, auto-respawn, dialog acceptance)
+// are optional; see the capability interfaces in the session package.
 type tmuxOps interface {
 	HasSession(name string) (bool, error)
 	IsAgentAlive(session string) bool
 	KillSessionWithProcesses(name string) error
 	NewSessionWithCommand(name, workDir, command string) error
-	SetRemainOnExit(pane string, on bool) error
 	SetEnvironment(session, key, value string) error
 	GetPaneID(session string) (string, error)
-	ConfigureGasTownSession(session string, theme *tmux.Theme, rig, worker, role string) error
 	WaitForCommand(session string, excludeCommands []string, timeout time.Duration) error
-	SetAutoRespawnHook(session string) error
-	AcceptStartupDialogs(session string) error
-	AcceptWorkspaceTrustDialog(session string) error
-	AcceptBypassPermissionsWarning(session string) error
 	SendKeysRaw(session, keys string) error
 	GetSessionInfo(name string) (*tmux.SessionInfo, error)
 }
@@ -49,7 +45,7 @@ type Manager struct {
 func NewManager(townRoot string) *Manager {
 	return &Manager{
 		townRoot: townRoot,
-		tmux:     tmux.NewTmux(),
+		tmux:     session.NewBackend(townRoot),
 	}
 }
 
@@ -130,7 +126,9 @@ func (m *Manager) Start(agentOverride string) error {
 	// PATCH-010: Set remain-on-exit IMMEDIATELY after session creation.
 	// This ensures the pane stays if Claude exits before hooks are fully set.
 	// The pane will show "[Exited]" status but remain available for respawn.
-	_ = t.SetRemainOnExit(sessionID, true)
+	if r, ok := t.(session.RemainOnExitSetter); ok {
+		_ = r.SetRemainOnExit(sessionID, true)
+	}
 
 	// Set environment variables (non-fatal: session works without these)
 	// Use centralized AgentEnv for consistency across all role startup paths
@@ -151,8 +149,10 @@ func (m *Manager) Start(agentOverride string) error {
 	}
 
 	// Apply Deacon theming (non-fatal: theming failure doesn't affect operation)
-	theme := tmux.ResolveSessionTheme(m.townRoot, "", "deacon")
-	_ = t.ConfigureGasTownSession(sessionID, theme, "", "Deacon", "health-check")
+	if themer, ok := t.(session.Themer); ok {
+		theme := tmux.ResolveSessionTheme(m.townRoot, "", "deacon")
+		_ = themer.ConfigureGasTownSession(sessionID, theme, "", "Deacon", "health-check")
+	}
 
 	// Wait for Claude to start - fatal if Claude fails to launch
 	if err := t.WaitForCommand(sessionID, constants.SupportedShells, constants.ClaudeStartTimeout); err != nil {
@@ -162,22 +162,26 @@ func (m *Manager) Start(agentOverride string) error {
 	}
 
 	// Track PID for defense-in-depth orphan cleanup (non-fatal)
-	if realTmux, ok := t.(*tmux.Tmux); ok {
-		_ = session.TrackSessionPID(m.townRoot, sessionID, realTmux)
+	if backend, ok := t.(session.SessionBackend); ok {
+		_ = session.TrackSessionPID(m.townRoot, sessionID, backend)
 	}
 
 	// PATCH-010: Set auto-respawn hook for Deacon resilience.
 	// When Claude exits (for any reason), tmux will automatically respawn it.
 	// This prevents the crash loop where daemon repeatedly restarts Deacon.
 	// Note: SetAutoRespawnHook calls SetRemainOnExit again (harmless, already set above).
-	if err := t.SetAutoRespawnHook(sessionID); err != nil {
-		// Non-fatal: Deacon still works, just won't auto-respawn on crash
-		// Daemon will still restart it, but with a delay
-		fmt.Printf("warning: failed to set auto-respawn hook for deacon: %v\n", err)
+	if r, ok := t.(session.AutoRespawner); ok {
+		if err := r.SetAutoRespawnHook(sessionID); err != nil {
+			// Non-fatal: Deacon still works, just won't auto-respawn on crash
+			// Daemon will still restart it, but with a delay
+			fmt.Printf("warning: failed to set auto-respawn hook for deacon: %v\n", err)
+		}
 	}
 
 	// Accept startup dialogs (workspace trust + bypass permissions) if they appear.
-	_ = t.AcceptStartupDialogs(sessionID)
+	if acceptor, ok := t.(session.DialogAcceptor); ok {
+		_ = acceptor.AcceptStartupDialogs(sessionID)
+	}
 
 	time.Sleep(constants.ShutdownNotifyDelay)
 
@@ -23,17 +23,18 @@ var (
 
 // SessionManager handles dog session lifecycle.
 type SessionManager struct {
-	tmux     *tmux.Tmux
+	tmux     session.SessionBackend
 	mgr      *Manager
 	townRoot string
 }
 
 // NewSessionManager creates a new dog session manager.
 // The Manager parameter is used to sync persistent dog state (idle/working)
-// when sessions start and stop.
+// when sessions start and stop. Sessions run on t unless the town's settings
+// select another session backend.
 func NewSessionManager(t *tmux.Tmux, townRoot string, mgr *Manager) *SessionManager {
 	return &SessionManager{
-		tmux:     t,
+		tmux:     session.ResolveBackend(townRoot, t),
 		mgr:      mgr,
 		townRoot: townRoot,
 	}
@@ -0,0 +1,445 @@
+// Package eventbus is the town's publish/subscribe event bus.
+//
+// A Bus (the daemon runs one) gives every published message a sequence
+// number, appends it to a retained log, and fans it out to subscribers whose
+// topic filters match. Subscribers can replay from any retained sequence
+// number, or from a named durable cursor they advance with Ack, before
+// following live messages. Other gt processes reach the daemon's Bus through
+// a Client over a unix socket. Publishers keep their file sinks
+// (.events.jsonl, events/<channel>/) for audit and for when the daemon is
+// not running.
+package eventbus
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Topic roots. Topics are dot-separated; the second segment is the event
+// type for activity events and the channel name for channel events.
+const (
+	TopicActivity = "activity" // events.Log, as activity.<type>
+	TopicChannel  = "channel"  // channelevents.Emit, as channel.<name>
+)
+
+// ActivityTopic returns the topic activity events of eventType are published on.
+func ActivityTopic(eventType string) string {
+	return TopicActivity + "." + eventType
+}
+
+// ChannelTopic returns the topic events on a named channel are published on.
+func ChannelTopic(channel string) string {
+	return TopicChannel + "." + channel
+}
+
+const (
+	logFile     = "log.jsonl"
+	cursorsFile = "cursors.json"
+
+	// defaultMaxLogSize is the retained log size before the oldest half is
+	// dropped. Replays from a dropped sequence number start at the oldest
+	// retained message.
+	defaultMaxLogSize int64 = 64 * 1024 * 1024
+
+	// subscriberBuffer is how many live messages a subscriber may lag behind
+	// before it is disconnected. Durable subscribers resume from their cursor.
+	subscriberBuffer = 1024
+)
+
+// ErrClosed is returned by a Bus that has been closed.
+var ErrClosed = errors.New("event bus closed")
+
+// Message is one published event.
+type Message struct {
+	Seq   uint64          `json:"seq"`
+	Topic string          `json:"topic"`
+	Time  time.Time       `json:"ts"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// SubscribeOptions selects which messages a subscription receives and where
+// it starts. Cursor takes precedence over From, and From over Last; with none
+// of them set the subscription receives only messages published after it.
+type SubscribeOptions struct {
+	Topics []string `json:"topics,omitempty"` // Topic patterns (see Match); none means every topic
+	Cursor string   `json:"cursor,omitempty"` // Replay messages after this durable cursor; an unknown cursor replays the whole retained log
+	From   uint64   `json:"from,omitempty"`   // Replay from this sequence number, inclusive
+	Last   int      `json:"last,omitempty"`   // Replay the last N matching messages
+}
+
+// Match reports whether topic matches pattern. In a pattern, "*" matches
+// exactly one segment and a trailing ">" matches one or more segments. An
+// empty pattern or ">" matches every topic.
+func Match(pattern, topic string) bool {
+	if pattern == "" || pattern == ">" {
+		return true
+	}
+	ps := strings.Split(pattern, ".")
+	ts := strings.Split(topic, ".")
+	for i, p := range ps {
+		if p == ">" && i == len(ps)-1 {
+			return len(ts) > i
+		}
+		if i >= len(ts) || (p != "*" && p != ts[i]) {
+			return false
+		}
+	}
+	return len(ps) == len(ts)
+}
+
+func matchAny(patterns []string, topic string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if Match(p, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// subscriber is a live subscription registered with the Bus.
+type subscriber struct {
+	topics []string
+	live   chan Message
+}
+
+// Bus is an event bus backed by a log file in its directory.
+type Bus struct {
+	dir        string
+	maxLogSize int64
+
+	mu      sync.Mutex
+	log     *os.File
+	size    int64
+	lastSeq uint64
+	cursors map[string]uint64
+	subs    map[*subscriber]struct{}
+	closed  bool
+}
+
+// Open opens the bus stored in dir, creating it if needed, and recovers the
+// last sequence number and the durable cursors.
+func Open(dir string) (*Bus, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating event bus directory: %w", err)
+	}
+	b := &Bus{
+		dir:        dir,
+		maxLogSize: defaultMaxLogSize,
+		cursors:    make(map[string]uint64),
+		subs:       make(map[*subscriber]struct{}),
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, cursorsFile)); err == nil {
+		if err := json.Unmarshal(data, &b.cursors); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", cursorsFile, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading %s: %w", cursorsFile, err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, logFile), os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening event log: %w", err)
+	}
+	size, lastSeq, err := scanLog(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	b.log, b.size, b.lastSeq = f, size, lastSeq
+
+	// A cursor can never be ahead of the log; if it is, the log was removed
+	// and the sequence restarts after the highest acknowledged message.
+	for _, seq := range b.cursors {
+		b.lastSeq = max(b.lastSeq, seq)
+	}
+	return b, nil
+}
+
+// scanLog returns the size of the log and the last sequence number in it.
+func scanLog(f *os.File) (int64, uint64, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, 0, fmt.Errorf("reading event log: %w", err)
+	}
+	var size int64
+	var lastSeq uint64
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		size += int64(len(line))
+		if len(line) > 0 {
+			var m Message
+			if json.Unmarshal(line, &m) == nil && m.Seq > lastSeq {
+				lastSeq = m.Seq
+			}
+		}
+		if err == io.EOF {
+			return size, lastSeq, nil
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("reading event log: %w", err)
+		}
+	}
+}
+
+// Publish appends a message to the log and delivers it to matching
+// subscribers. data must be valid JSON.
+func (b *Bus) Publish(topic string, data json.RawMessage) (Message, error) {
+	if topic == "" || strings.ContainsAny(topic, "*> \n") {
+		return Message{}, fmt.Errorf("invalid topic %q", topic)
+	}
+	if len(data) == 0 {
+		data = json.RawMessage("null")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return Message{}, ErrClosed
+	}
+
+	m := Message{Seq: b.lastSeq + 1, Topic: topic, Time: time.Now().UTC(), Data: data}
+	line, err := json.Marshal(m)
+	if err != nil {
+		return Message{}, fmt.Errorf("encoding message: %w", err)
+	}
+	line = append(line, '\n')
+	if _, err := b.log.Write(line); err != nil {
+		return Message{}, fmt.Errorf("writing event log: %w", err)
+	}
+	b.lastSeq = m.Seq
+	b.size += int64(len(line))
+
+	for sub := range b.subs {
+		if !matchAny(sub.topics, topic) {
+			continue
+		}
+		select {
+		case sub.live <- m:
+		default:
+			// Too far behind: disconnect rather than block publishers.
+			delete(b.subs, sub)
+			close(sub.live)
+		}
+	}
+
+	if b.size > b.maxLogSize {
+		if err := b.compactLocked(); err != nil {
+			return m, fmt.Errorf("compacting event log: %w", err)
+		}
+	}
+	return m, nil
+}
+
+// Subscribe returns a channel that receives the replayed messages selected by
+// opts, then live messages, in sequence order. The channel is closed when ctx
+// is done, the bus is closed, or the subscriber falls too far behind.
+func (b *Bus) Subscribe(ctx context.Context, opts SubscribeOptions) (<-chan Message, error) {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil, ErrClosed
+	}
+	var from uint64
+	switch {
+	case opts.Cursor != "":
+		from = b.cursors[opts.Cursor] + 1
+	case opts.From > 0:
+		from = opts.From
+	case opts.Last > 0:
+		from = 1
+	}
+	upTo := b.lastSeq
+	sub := &subscriber{topics: opts.Topics, live: make(chan Message, subscriberBuffer)}
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+
+	out := make(chan Message)
+	go func() {
+		defer close(out)
+		defer b.unsubscribe(sub)
+
+		send := func(m Message) bool {
+			select {
+			case out <- m:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		if from > 0 && from <= upTo {
+			replay, err := b.readLog(from, upTo, opts.Topics, opts.Last)
+			if err != nil {
+				return
+			}
+			for _, m := range replay {
+				if !send(m) {
+					return
+				}
+			}
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case m, ok := <-sub.live:
+				if !ok || !send(m) {
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (b *Bus) unsubscribe(sub *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.live)
+	}
+}
+
+// readLog returns the retained messages with from <= seq <= upTo matching
+// topics. If last > 0, only the last that many are returned.
+func (b *Bus) readLog(from, upTo uint64, topics []string, last int) ([]Message, error) {
+	f, err := os.Open(filepath.Join(b.dir, logFile))
+	if err != nil {
+		return nil, fmt.Errorf("opening event log: %w", err)
+	}
+	defer f.Close()
+
+	var msgs []Message
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var m Message
+		if json.Unmarshal(scanner.Bytes(), &m) != nil {
+			continue
+		}
+		if m.Seq > upTo {
+			break
+		}
+		if m.Seq < from || !matchAny(topics, m.Topic) {
+			continue
+		}
+		msgs = append(msgs, m)
+		if last > 0 && len(msgs) > last {
+			msgs = msgs[1:]
+		}
+	}
+	return msgs, scanner.Err()
+}
+
+// compactLocked drops the oldest half of the log. Must be called with b.mu held.
+func (b *Bus) compactLocked() error {
+	path := filepath.Join(b.dir, logFile)
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	if _, err := src.Seek(b.size/2, io.SeekStart); err != nil {
+		return err
+	}
+	r := bufio.NewReader(src)
+	if _, err := r.ReadBytes('\n'); err != nil {
+		return err // skip the partial line at the cut point
+	}
+
+	tmpPath := path + ".compact.tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(tmp, r)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	_ = src.Close()
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return err
+	}
+	_ = b.log.Close()
+	b.log, b.size = f, n
+	return nil
+}
+
+// Ack advances the named durable cursor to seq. Cursors never move backwards.
+func (b *Bus) Ack(cursor string, seq uint64) error {
+	if cursor == "" {
+		return fmt.Errorf("cursor name is required")
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+	if seq > b.lastSeq {
+		return fmt.Errorf("cannot ack %d: last published sequence is %d", seq, b.lastSeq)
+	}
+	if seq <= b.cursors[cursor] {
+		return nil
+	}
+	b.cursors[cursor] = seq
+	return util.AtomicWriteJSON(filepath.Join(b.dir, cursorsFile), b.cursors)
+}
+
+// Cursor returns the position of the named durable cursor.
+func (b *Bus) Cursor(name string) (uint64, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	seq, ok := b.cursors[name]
+	return seq, ok
+}
+
+// LastSeq returns the sequence number of the most recently published message.
+func (b *Bus) LastSeq() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastSeq
+}
+
+// Close disconnects all subscribers and closes the log.
+func (b *Bus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	for sub := range b.subs {
+		delete(b.subs, sub)
+		close(sub.live)
+	}
+	return b.log.Close()
+}
@@ -0,0 +1,232 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, topic string
+		want           bool
+	}{
+		{"", "activity.sling", true},
+		{">", "channel.refinery", true},
+		{"activity.sling", "activity.sling", true},
+		{"activity.sling", "activity.done", false},
+		{"activity.*", "activity.done", true},
+		{"activity.*", "activity", false},
+		{"*.refinery", "channel.refinery", true},
+		{"channel.>", "channel.refinery", true},
+		{"channel.>", "channel", false},
+		{"activity", "activity.sling", false},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.topic); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.topic, got, tt.want)
+		}
+	}
+}
+
+func receive(t *testing.T, ch <-chan Message, n int) []Message {
+	t.Helper()
+	var got []Message
+	timeout := time.After(5 * time.Second)
+	for len(got) < n {
+		select {
+		case m, ok := <-ch:
+			if !ok {
+				t.Fatalf("subscription closed after %d of %d messages", len(got), n)
+			}
+			got = append(got, m)
+		case <-timeout:
+			t.Fatalf("timed out after %d of %d messages", len(got), n)
+		}
+	}
+	return got
+}
+
+func publish(t *testing.T, b *Bus, topic string) uint64 {
+	t.Helper()
+	m, err := b.Publish(topic, json.RawMessage(`{"topic":"`+topic+`"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m.Seq
+}
+
+func TestBus_ReplayCursorAndLive(t *testing.T) {
+	dir := t.TempDir()
+	b, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publish(t, b, "activity.sling")
+	publish(t, b, "channel.refinery")
+	publish(t, b, "activity.done")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Replay from an offset with a topic filter, then follow live.
+	ch, err := b.Subscribe(ctx, SubscribeOptions{Topics: []string{"activity.*"}, From: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := receive(t, ch, 2)
+	if got[0].Seq != 1 || got[1].Seq != 3 {
+		t.Errorf("replay seqs = %d, %d, want 1, 3", got[0].Seq, got[1].Seq)
+	}
+	publish(t, b, "channel.witness")
+	publish(t, b, "activity.hook")
+	if got := receive(t, ch, 1); got[0].Topic != "activity.hook" || got[0].Seq != 5 {
+		t.Errorf("live message = %+v", got[0])
+	}
+
+	// Last N.
+	last, err := b.Subscribe(ctx, SubscribeOptions{Last: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := receive(t, last, 2); got[0].Seq != 4 || got[1].Seq != 5 {
+		t.Errorf("last 2 seqs = %d, %d, want 4, 5", got[0].Seq, got[1].Seq)
+	}
+
+	// A durable cursor survives reopening the bus.
+	if err := b.Ack("refinery", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Ack("refinery", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Ack("refinery", 99); err == nil {
+		t.Error("expected error acking past the last sequence")
+	}
+	cancel()
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err = Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	if seq, ok := b.Cursor("refinery"); !ok || seq != 2 {
+		t.Errorf("cursor = %d, %v, want 2, true", seq, ok)
+	}
+	if next := publish(t, b, "channel.refinery"); next != 6 {
+		t.Errorf("seq after reopen = %d, want 6", next)
+	}
+	ch, err = b.Subscribe(context.Background(), SubscribeOptions{Topics: []string{"channel.refinery"}, Cursor: "refinery"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := receive(t, ch, 1); got[0].Seq != 6 {
+		t.Errorf("cursor replay seq = %d, want 6", got[0].Seq)
+	}
+}
+
+func TestBus_CompactsLog(t *testing.T) {
+	dir := t.TempDir()
+	b, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	b.maxLogSize = 2048
+	for i := 0; i < 100; i++ {
+		publish(t, b, "activity.sling")
+	}
+	info, err := os.Stat(filepath.Join(dir, logFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() > 2048 {
+		t.Errorf("log size = %d, want <= 2048", info.Size())
+	}
+
+	// Replay from a dropped offset starts at the oldest retained message
+	// and still ends at the newest.
+	ch, err := b.Subscribe(context.Background(), SubscribeOptions{From: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last Message
+	for m := range ch {
+		last = m
+		if m.Seq == 100 {
+			break
+		}
+	}
+	if last.Seq != 100 {
+		t.Errorf("last replayed seq = %d, want 100", last.Seq)
+	}
+}
+
+func TestServeAndClient(t *testing.T) {
+	// Unix socket paths are limited to ~100 bytes, so avoid t.TempDir().
+	dir, err := os.MkdirTemp("", "gtbus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b, err := Open(Dir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	socket := SocketPath(dir)
+	go func() { _ = Serve(ctx, b, socket) }()
+
+	c := NewClient(socket)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := c.Publish("activity.boot", map[string]string{"rig": "gastown"}); err == nil {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("bus never came up: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	subCtx, subCancel := context.WithCancel(ctx)
+	ch, err := c.Subscribe(subCtx, SubscribeOptions{Topics: []string{"activity.>"}, Cursor: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Publish("channel.refinery", nil); err != nil {
+		t.Fatal(err)
+	}
+	seq, err := c.Publish("activity.sling", map[string]string{"bead": "gt-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := receive(t, ch, 2)
+	if got[0].Topic != "activity.boot" || got[1].Seq != seq {
+		t.Errorf("got %+v", got)
+	}
+	var data map[string]string
+	if err := json.Unmarshal(got[1].Data, &data); err != nil || data["bead"] != "gt-1" {
+		t.Errorf("data = %s", got[1].Data)
+	}
+	subCancel()
+
+	if err := c.Ack("test", seq); err != nil {
+		t.Fatal(err)
+	}
+	if cur, _ := b.Cursor("test"); cur != seq {
+		t.Errorf("cursor = %d, want %d", cur, seq)
+	}
+
+	if _, err := NewClient(filepath.Join(dir, "nope.sock")).Publish("activity.x", nil); err != ErrBusNotRunning {
+		t.Errorf("err = %v, want ErrBusNotRunning", err)
+	}
+}
@@ -0,0 +1,125 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// ErrBusNotRunning is returned when the daemon's event bus is unreachable.
+var ErrBusNotRunning = errors.New("event bus not running (start it with 'gt daemon start')")
+
+// dialTimeout bounds how long a client waits to reach the bus. Publishing is
+// on the path of every logged event, so this is kept short.
+const dialTimeout = 500 * time.Millisecond
+
+// Client talks to the daemon's Bus over its unix socket.
+type Client struct {
+	socketPath string
+}
+
+// NewClient creates a client for the bus listening on socketPath.
+func NewClient(socketPath string) *Client {
+	return &Client{socketPath: socketPath}
+}
+
+func (c *Client) dial() (net.Conn, error) {
+	conn, err := net.DialTimeout("unix", c.socketPath, dialTimeout)
+	if err != nil {
+		return nil, ErrBusNotRunning
+	}
+	return conn, nil
+}
+
+func (c *Client) call(req request) (response, error) {
+	conn, err := c.dial()
+	if err != nil {
+		return response{}, err
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return response{}, fmt.Errorf("sending %s request: %w", req.Op, err)
+	}
+	var resp response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return response{}, fmt.Errorf("reading %s response: %w", req.Op, err)
+	}
+	if resp.Error != "" {
+		return resp, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+// Publish marshals v to JSON and publishes it on topic, returning the
+// message's sequence number.
+func (c *Client) Publish(topic string, v any) (uint64, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0, fmt.Errorf("encoding message: %w", err)
+	}
+	resp, err := c.call(request{Op: "publish", Topic: topic, Data: data})
+	return resp.Seq, err
+}
+
+// Ack advances the named durable cursor to seq.
+func (c *Client) Ack(cursor string, seq uint64) error {
+	_, err := c.call(request{Op: "ack", Cursor: cursor, Seq: seq})
+	return err
+}
+
+// Subscribe opens a subscription (see Bus.Subscribe). The returned channel
+// is closed when ctx is done or the connection to the daemon is lost; check
+// ctx.Err() to tell the two apart.
+func (c *Client) Subscribe(ctx context.Context, opts SubscribeOptions) (<-chan Message, error) {
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if err := json.NewEncoder(conn).Encode(request{Op: "subscribe", Subscribe: &opts}); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("sending subscribe request: %w", err)
+	}
+	dec := json.NewDecoder(conn)
+	var resp response
+	if err := dec.Decode(&resp); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("reading subscribe response: %w", err)
+	}
+	if resp.Error != "" {
+		_ = conn.Close()
+		return nil, errors.New(resp.Error)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	out := make(chan Message)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		_ = conn.Close()
+	}()
+	go func() {
+		defer close(out)
+		defer close(done)
+		for {
+			var r response
+			if err := dec.Decode(&r); err != nil || r.Msg == nil {
+				return
+			}
+			select {
+			case out <- *r.Msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
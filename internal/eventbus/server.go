@@ -0,0 +1,145 @@
+package eventbus
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SocketPath returns the unix socket the daemon's Bus listens on.
+func SocketPath(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "eventbus.sock")
+}
+
+// Dir returns the directory holding the daemon's event log and cursors.
+func Dir(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "eventbus")
+}
+
+// request is one client call, encoded as a JSON line. Publish and ack
+// connections carry one request and its response; a subscribe connection
+// gets a response and then one message per line until either side closes.
+type request struct {
+	Op        string            `json:"op"`
+	Topic     string            `json:"topic,omitempty"`
+	Data      json.RawMessage   `json:"data,omitempty"`
+	Cursor    string            `json:"cursor,omitempty"`
+	Seq       uint64            `json:"seq,omitempty"`
+	Subscribe *SubscribeOptions `json:"subscribe,omitempty"`
+}
+
+type response struct {
+	Error string   `json:"error,omitempty"`
+	Seq   uint64   `json:"seq,omitempty"`
+	Msg   *Message `json:"msg,omitempty"`
+}
+
+// Serve accepts client connections on socketPath until ctx is cancelled.
+// A stale socket file from a previous daemon is replaced.
+func Serve(ctx context.Context, b *Bus, socketPath string) error {
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0o755); err != nil {
+		return fmt.Errorf("creating socket directory: %w", err)
+	}
+	_ = os.Remove(socketPath)
+	ln, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", socketPath, err)
+	}
+	if err := os.Chmod(socketPath, 0o600); err != nil {
+		_ = ln.Close()
+		return fmt.Errorf("securing socket: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = ln.Close()
+		_ = os.Remove(socketPath)
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			continue
+		}
+		go b.handleConn(ctx, conn)
+	}
+}
+
+func (b *Bus) handleConn(ctx context.Context, conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	reader := bufio.NewReader(conn)
+	enc := json.NewEncoder(conn)
+	var req request
+	if err := json.NewDecoder(reader).Decode(&req); err != nil {
+		_ = enc.Encode(response{Error: "invalid request: " + err.Error()})
+		return
+	}
+
+	var resp response
+	switch req.Op {
+	case "publish":
+		m, err := b.Publish(req.Topic, req.Data)
+		if err != nil {
+			resp.Error = err.Error()
+		}
+		resp.Seq = m.Seq
+	case "ack":
+		if err := b.Ack(req.Cursor, req.Seq); err != nil {
+			resp.Error = err.Error()
+		}
+	case "subscribe":
+		b.serveSubscription(ctx, conn, req.Subscribe)
+		return
+	default:
+		resp.Error = fmt.Sprintf("unknown op %q", req.Op)
+	}
+	_ = enc.Encode(resp)
+}
+
+// serveSubscription streams messages to conn until the client disconnects,
+// the daemon stops, or the subscription ends.
+func (b *Bus) serveSubscription(ctx context.Context, conn net.Conn, opts *SubscribeOptions) {
+	if opts == nil {
+		opts = &SubscribeOptions{}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	enc := json.NewEncoder(conn)
+	msgs, err := b.Subscribe(ctx, *opts)
+	if err != nil {
+		_ = enc.Encode(response{Error: err.Error()})
+		return
+	}
+	_ = conn.SetDeadline(time.Time{})
+	if err := enc.Encode(response{Seq: b.LastSeq()}); err != nil {
+		return
+	}
+
+	// Clients send nothing after the request, so a read returning means
+	// they hung up.
+	go func() {
+		_, _ = conn.Read(make([]byte, 1))
+		cancel()
+	}()
+
+	for m := range msgs {
+		if err := enc.Encode(response{Msg: &m}); err != nil {
+			return
+		}
+	}
+}
@@ -1,7 +1,8 @@
 // Package events provides event logging for the gt activity feed.
 //
-// Events are written to ~/gt/.events.jsonl (raw audit log) and later
-// curated by the feed daemon into ~/.feed.jsonl (user-facing).
+// Events are written to ~/gt/.events.jsonl (raw audit log) and published
+// on the daemon's event bus as activity.<type>, from which the feed daemon
+// curates them into ~/.feed.jsonl (user-facing).
 package events
 
 import (
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/eventbus"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -63,12 +65,18 @@ const (
 	TypeEscalationClosed = "escalation_closed"
 	TypePatrolComplete   = "patrol_complete"
 
+	// Escalation notifier events
+	TypeEscalationNotifyFailed = "escalation_notify_failed" // Webhook or incident delivery failed
+
 	// Merge queue events (emitted by refinery)
 	TypeMergeStarted = "merge_started"
 	TypeMerged       = "merged"
 	TypeMergeFailed  = "merge_failed"
 	TypeMergeSkipped = "merge_skipped"
 
+	// Convoy events
+	TypeConvoyClosed = "convoy_closed"
+
 	// Scheduler events
 	TypeSchedulerEnqueue        = "scheduler_enqueue"         // Bead scheduled for deferred dispatch
 	TypeSchedulerDispatch       = "scheduler_dispatch"        // Bead dispatched from scheduler
@@ -80,7 +88,7 @@ const (
 const EventsFile = ".events.jsonl"
 
 // Log writes an event to the events log.
-// The event is appended to ~/gt/.events.jsonl.
+// The event is appended to ~/gt/.events.jsonl and published on the event bus.
 // Returns nil if logging fails (events are best-effort).
 func Log(eventType, actor string, payload map[string]interface{}, visibility string) error {
 	event := Event{
@@ -145,6 +153,11 @@ func write(event Event) error {
 		return fmt.Errorf("closing events file: %w", err)
 	}
 
+	// The file above is the audit sink; the bus is how live subscribers see
+	// the event. It is unreachable when the daemon is down, and subscribers
+	// then fall back to tailing the file.
+	_, _ = eventbus.NewClient(eventbus.SocketPath(townRoot)).Publish(eventbus.ActivityTopic(event.Type), event)
+
 	return nil
 }
 
@@ -269,6 +282,17 @@ func EscalationPayload(rig, target, to, reason string) map[string]interface{} {
 	}
 }
 
+// EscalationNotifyFailedPayload creates a payload for escalation notifier
+// delivery failures.
+func EscalationNotifyFailedPayload(escalationID, action, event, errMsg string) map[string]interface{} {
+	return map[string]interface{}{
+		"escalation_id": escalationID,
+		"action":        action,
+		"event":         event,
+		"error":         errMsg,
+	}
+}
+
 // UnhookPayload creates a payload for unhook events.
 func UnhookPayload(beadID string) map[string]interface{} {
 	return map[string]interface{}{
@@ -323,6 +347,15 @@ func MassDeathPayload(count int, window string, sessions []string, possibleCause
 	return p
 }
 
+// ConvoyClosedPayload creates a payload for convoy closed events.
+func ConvoyClosedPayload(convoyID, title, reason string) map[string]interface{} {
+	return map[string]interface{}{
+		"convoy": convoyID,
+		"title":  title,
+		"reason": reason,
+	}
+}
+
 // SessionPayload creates a payload for session start/end events.
 // sessionID: Claude Code session UUID
 // role: Gas Town role (e.g., "gastown/crew/joe", "deacon")
@@ -1,7 +1,8 @@
 // Package feed provides the feed daemon that curates raw events into a user-facing feed.
 //
 // The curator:
-// 1. Tails ~/gt/.events.jsonl (raw events)
+// 1. Subscribes to activity events on the daemon's event bus (or tails
+//    ~/gt/.events.jsonl when it has no bus)
 // 2. Filters by visibility tag (drops audit-only events)
 // 3. Deduplicates repeated updates (5 molecule updates → "agent active")
 // 4. Aggregates related events (3 issues closed → "batch complete")
@@ -22,6 +23,7 @@ import (
 
 	"github.com/gofrs/flock"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/eventbus"
 	"github.com/steveyegge/gastown/internal/events"
 )
 
@@ -50,6 +52,9 @@ type Curator struct {
 	startOnce sync.Once // prevents concurrent Start() calls from spawning multiple goroutines
 	startErr  error     // result of the one-shot Start; visible to all callers via sync.Once happens-before
 
+	// bus, when set, is subscribed to instead of tailing the events file.
+	bus *eventbus.Bus
+
 	// feedMu guards in-process access to the feed file. The flock in
 	// readRecentFeedEvents/writeFeedEvent coordinates across processes;
 	// this mutex coordinates goroutines within the same process.
@@ -92,10 +97,26 @@ func NewCurator(townRoot string) *Curator {
 	}
 }
 
+// SetBus makes the curator receive events from bus instead of tailing the
+// events file. Must be called before Start.
+func (c *Curator) SetBus(bus *eventbus.Bus) {
+	c.bus = bus
+}
+
 // Start begins the curator goroutine. It is safe to call concurrently;
 // only the first call starts the goroutine — subsequent calls are no-ops.
 func (c *Curator) Start() error {
 	c.startOnce.Do(func() {
+		if c.bus != nil {
+			msgs, err := c.bus.Subscribe(c.ctx, eventbus.SubscribeOptions{Topics: []string{eventbus.TopicActivity + ".>"}})
+			if err == nil {
+				c.wg.Add(1)
+				go c.runBus(msgs)
+				return
+			}
+			log.Printf("warning: subscribing to event bus, tailing events file instead: %v", err)
+		}
+
 		eventsPath := filepath.Join(c.townRoot, events.EventsFile)
 
 		// Open events file, creating if needed
@@ -152,6 +173,15 @@ func (c *Curator) run(file *os.File) {
 	}
 }
 
+// runBus is the curator loop when events come from the event bus. The
+// subscription channel closes when the curator or the bus stops.
+func (c *Curator) runBus(msgs <-chan eventbus.Message) {
+	defer c.wg.Done()
+	for m := range msgs {
+		c.processLine(string(m.Data))
+	}
+}
+
 // processLine processes a single line from the events file.
 func (c *Curator) processLine(line string) {
 	if line == "" || line == "\n" {
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/eventbus"
 	"github.com/steveyegge/gastown/internal/events"
 )
 
@@ -99,6 +100,58 @@ func TestCurator_FiltersByVisibility(t *testing.T) {
 	}
 }
 
+func TestCurator_ReadsFromBus(t *testing.T) {
+	tmpDir := t.TempDir()
+	bus, err := eventbus.Open(filepath.Join(tmpDir, "bus"))
+	if err != nil {
+		t.Fatalf("opening bus: %v", err)
+	}
+	defer bus.Close()
+
+	curator := NewCurator(tmpDir)
+	curator.SetBus(bus)
+	if err := curator.Start(); err != nil {
+		t.Fatalf("starting curator: %v", err)
+	}
+	defer curator.Stop()
+
+	publish := func(e events.Event) {
+		data, _ := json.Marshal(e)
+		if _, err := bus.Publish(eventbus.ActivityTopic(e.Type), data); err != nil {
+			t.Fatalf("publishing: %v", err)
+		}
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	publish(events.Event{Timestamp: now, Type: "internal_check", Actor: "daemon", Visibility: events.VisibilityAudit})
+	publish(events.Event{Timestamp: now, Type: events.TypeHandoff, Actor: "mayor", Visibility: events.VisibilityFeed})
+	if _, err := bus.Publish(eventbus.ChannelTopic("refinery"), []byte(`{"type":"MERGE_READY"}`)); err != nil {
+		t.Fatalf("publishing: %v", err)
+	}
+
+	feedPath := filepath.Join(tmpDir, FeedFile)
+	var feedContent []byte
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		if feedContent, _ = os.ReadFile(feedPath); len(feedContent) > 0 {
+			break
+		}
+	}
+	// Give stray events a chance to arrive before checking there is just one.
+	time.Sleep(100 * time.Millisecond)
+	feedContent, _ = os.ReadFile(feedPath)
+
+	lines := strings.Split(strings.TrimSpace(string(feedContent)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("feed has %d lines, want 1:\n%s", len(lines), feedContent)
+	}
+	var written FeedEvent
+	if err := json.Unmarshal([]byte(lines[0]), &written); err != nil {
+		t.Fatalf("parsing feed event: %v", err)
+	}
+	if written.Type != events.TypeHandoff || written.Actor != "mayor" {
+		t.Errorf("feed event = %+v", written)
+	}
+}
+
 func TestCurator_DedupesDoneEvents(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "feed-test-*")
 	if err != nil {
@@ -0,0 +1,220 @@
+package formula
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// validateAdvice checks advice rules and pointcuts on an aspect formula.
+func (f *Formula) validateAdvice() error {
+	for i, rule := range f.Advice {
+		if rule == nil {
+			continue
+		}
+		if rule.Target == "" {
+			return fmt.Errorf("advice[%d] missing required target field", i)
+		}
+		if _, err := path.Match(rule.Target, ""); err != nil {
+			return fmt.Errorf("advice[%d] has invalid target pattern %q: %w", i, rule.Target, err)
+		}
+		steps := rule.adviceSteps()
+		if len(steps) == 0 {
+			return fmt.Errorf("advice for %q defines no before, after, or around steps", rule.Target)
+		}
+		for _, s := range steps {
+			if s.ID == "" {
+				return fmt.Errorf("advice for %q has a step missing required id field", rule.Target)
+			}
+		}
+	}
+	for i, pc := range f.Pointcuts {
+		if pc == nil || pc.Glob == "" {
+			return fmt.Errorf("pointcuts[%d] missing required glob field", i)
+		}
+		if _, err := path.Match(pc.Glob, ""); err != nil {
+			return fmt.Errorf("pointcuts[%d] has invalid glob %q: %w", i, pc.Glob, err)
+		}
+	}
+	return nil
+}
+
+// adviceSteps returns every step template declared by the rule.
+func (r *AdviceRule) adviceSteps() []*AdviceStep {
+	return append(r.beforeSteps(), r.afterSteps()...)
+}
+
+// beforeSteps returns the templates to insert ahead of the target, outermost first.
+func (r *AdviceRule) beforeSteps() []*AdviceStep {
+	var steps []*AdviceStep
+	if r.Around != nil {
+		steps = append(steps, r.Around.Before...)
+	}
+	if r.Before != nil {
+		steps = append(steps, r.Before)
+	}
+	return steps
+}
+
+// afterSteps returns the templates to insert behind the target, innermost first.
+func (r *AdviceRule) afterSteps() []*AdviceStep {
+	var steps []*AdviceStep
+	if r.After != nil {
+		steps = append(steps, r.After)
+	}
+	if r.Around != nil {
+		steps = append(steps, r.Around.After...)
+	}
+	return steps
+}
+
+// matchesPointcuts reports whether stepID is allowed by the aspect's pointcuts.
+// An aspect without pointcuts allows every step.
+func (f *Formula) matchesPointcuts(stepID string) bool {
+	if len(f.Pointcuts) == 0 {
+		return true
+	}
+	for _, pc := range f.Pointcuts {
+		if ok, _ := path.Match(pc.Glob, stepID); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// applyAspect loads the named aspect formula and weaves its advice into steps.
+func applyAspect(steps []Step, name string, searchPaths []string) ([]Step, error) {
+	aspect, err := loadFormulaByName(name, searchPaths)
+	if err != nil {
+		return nil, err
+	}
+	if aspect.Type != TypeAspect {
+		return nil, fmt.Errorf("formula %q is type %q, want %q", name, aspect.Type, TypeAspect)
+	}
+	if len(aspect.Advice) == 0 {
+		return nil, fmt.Errorf("aspect formula %q has no advice rules", name)
+	}
+	return WeaveAdvice(steps, aspect), nil
+}
+
+// WeaveAdvice applies the advice rules of an aspect formula to steps and
+// returns the woven step list.
+//
+// For each step matching a rule's target (and the aspect's pointcuts), before
+// steps are chained between the target and its original needs, and after steps
+// are chained behind the target; steps that depended on the target are rewired
+// to depend on the last after step. Rules are applied in order and only match
+// steps that existed before the aspect was applied, so advice never advises
+// its own inserted steps.
+func WeaveAdvice(steps []Step, aspect *Formula) []Step {
+	original := make(map[string]bool, len(steps))
+	for _, s := range steps {
+		original[s.ID] = true
+	}
+
+	result := append([]Step(nil), steps...)
+	for _, rule := range aspect.Advice {
+		if rule == nil {
+			continue
+		}
+		// Snapshot the targets first so insertions don't shift iteration.
+		var targets []string
+		for _, s := range result {
+			if !original[s.ID] || !aspect.matchesPointcuts(s.ID) {
+				continue
+			}
+			if ok, _ := path.Match(rule.Target, s.ID); ok {
+				targets = append(targets, s.ID)
+			}
+		}
+		for _, id := range targets {
+			result = weaveStep(result, id, rule)
+		}
+	}
+	return result
+}
+
+// weaveStep inserts a single rule's advice steps around the step with targetID.
+func weaveStep(steps []Step, targetID string, rule *AdviceRule) []Step {
+	targetIdx := -1
+	for i, s := range steps {
+		if s.ID == targetID {
+			targetIdx = i
+			break
+		}
+	}
+	if targetIdx == -1 {
+		return steps
+	}
+	target := steps[targetIdx]
+
+	var before []Step
+	prev := target.Needs
+	for _, tmpl := range rule.beforeSteps() {
+		s := instantiateAdvice(tmpl, target)
+		s.Needs = append([]string(nil), prev...)
+		before = append(before, s)
+		prev = []string{s.ID}
+	}
+	if len(before) > 0 {
+		target.Needs = []string{before[len(before)-1].ID}
+	}
+
+	var after []Step
+	prev = []string{target.ID}
+	for _, tmpl := range rule.afterSteps() {
+		s := instantiateAdvice(tmpl, target)
+		s.Needs = append([]string(nil), prev...)
+		after = append(after, s)
+		prev = []string{s.ID}
+	}
+
+	result := make([]Step, 0, len(steps)+len(before)+len(after))
+	for i, step := range steps {
+		if i == targetIdx {
+			result = append(result, before...)
+			result = append(result, target)
+			result = append(result, after...)
+			continue
+		}
+		if len(after) > 0 {
+			step = rewireNeeds(step, targetID, after[len(after)-1].ID)
+		}
+		result = append(result, step)
+	}
+	return result
+}
+
+// rewireNeeds returns step with every need on from replaced by to.
+func rewireNeeds(step Step, from, to string) Step {
+	updated := false
+	for j, need := range step.Needs {
+		if need == from {
+			if !updated {
+				step.Needs = append([]string(nil), step.Needs...)
+				updated = true
+			}
+			step.Needs[j] = to
+		}
+	}
+	return step
+}
+
+// instantiateAdvice builds a concrete step from an advice template.
+func instantiateAdvice(tmpl *AdviceStep, target Step) Step {
+	return Step{
+		ID:          expandStepPlaceholders(tmpl.ID, target),
+		Title:       expandStepPlaceholders(tmpl.Title, target),
+		Description: expandStepPlaceholders(tmpl.Description, target),
+		Acceptance:  expandStepPlaceholders(tmpl.Acceptance, target),
+	}
+}
+
+// expandStepPlaceholders replaces {step.id}, {step.title} and
+// {step.description} in advice templates with the target step's values.
+func expandStepPlaceholders(s string, target Step) string {
+	s = strings.ReplaceAll(s, "{step.title}", target.Title)
+	s = strings.ReplaceAll(s, "{step.description}", target.Description)
+	s = strings.ReplaceAll(s, "{step.id}", target.ID)
+	return s
+}
@@ -0,0 +1,243 @@
+package formula
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFormula(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name+".formula.toml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParse_AspectWithAdvice(t *testing.T) {
+	f, err := Parse([]byte(`
+formula = "changelog"
+
+[[advice]]
+target = "submit"
+[advice.before]
+id = "{step.id}-changelog"
+title = "Update changelog before {step.title}"
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if f.Type != TypeAspect {
+		t.Errorf("Type = %q, want %q (inferred from advice)", f.Type, TypeAspect)
+	}
+	if len(f.Advice) != 1 || f.Advice[0].Before == nil {
+		t.Fatalf("Advice = %+v, want one rule with before step", f.Advice)
+	}
+}
+
+func TestValidate_AdviceErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "missing target",
+			content: `formula = "a"
+type = "aspect"
+[[advice]]
+[advice.before]
+id = "x"
+`,
+			wantErr: "missing required target",
+		},
+		{
+			name: "no steps",
+			content: `formula = "a"
+type = "aspect"
+[[advice]]
+target = "implement"
+`,
+			wantErr: "defines no before, after, or around steps",
+		},
+		{
+			name: "step without id",
+			content: `formula = "a"
+type = "aspect"
+[[advice]]
+target = "implement"
+[advice.after]
+title = "no id"
+`,
+			wantErr: "missing required id",
+		},
+		{
+			name: "bad pattern",
+			content: `formula = "a"
+type = "aspect"
+[[advice]]
+target = "impl[ement"
+[advice.after]
+id = "x"
+`,
+			wantErr: "invalid target pattern",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWeaveAdvice_GlobAndPointcuts(t *testing.T) {
+	steps := []Step{
+		{ID: "design"},
+		{ID: "implement.draft", Needs: []string{"design"}},
+		{ID: "implement.refine", Needs: []string{"implement.draft"}},
+		{ID: "submit", Needs: []string{"implement.refine"}},
+	}
+	aspect := &Formula{
+		Name: "telemetry",
+		Type: TypeAspect,
+		Advice: []*AdviceRule{{
+			Target: "implement.*",
+			After:  &AdviceStep{ID: "{step.id}-telemetry"},
+		}},
+		Pointcuts: []*Pointcut{{Glob: "*.refine"}},
+	}
+
+	got := WeaveAdvice(steps, aspect)
+
+	want := []string{"design", "implement.draft", "implement.refine", "implement.refine-telemetry", "submit"}
+	var ids []string
+	for _, s := range got {
+		ids = append(ids, s.ID)
+	}
+	if strings.Join(ids, " ") != strings.Join(want, " ") {
+		t.Fatalf("woven steps = %v, want %v", ids, want)
+	}
+	if needs := got[4].Needs; len(needs) != 1 || needs[0] != "implement.refine-telemetry" {
+		t.Errorf("submit.Needs = %v, want [implement.refine-telemetry]", needs)
+	}
+	// The input slice must not be mutated.
+	if steps[3].Needs[0] != "implement.refine" {
+		t.Errorf("input submit.Needs mutated to %v", steps[3].Needs)
+	}
+}
+
+func TestWeaveAdvice_AroundOrdering(t *testing.T) {
+	steps := []Step{{ID: "a"}, {ID: "b", Needs: []string{"a"}}}
+	aspect := &Formula{
+		Name: "wrap",
+		Type: TypeAspect,
+		Advice: []*AdviceRule{{
+			Target: "b",
+			Before: &AdviceStep{ID: "inner-before"},
+			After:  &AdviceStep{ID: "inner-after"},
+			Around: &AroundAdvice{
+				Before: []*AdviceStep{{ID: "outer-before"}},
+				After:  []*AdviceStep{{ID: "outer-after"}},
+			},
+		}},
+	}
+
+	got := WeaveAdvice(steps, aspect)
+
+	wantOrder := []string{"a", "outer-before", "inner-before", "b", "inner-after", "outer-after"}
+	wantNeeds := map[string]string{
+		"outer-before": "a",
+		"inner-before": "outer-before",
+		"b":            "inner-before",
+		"inner-after":  "b",
+		"outer-after":  "inner-after",
+	}
+	if len(got) != len(wantOrder) {
+		t.Fatalf("got %d steps, want %d", len(got), len(wantOrder))
+	}
+	for i, s := range got {
+		if s.ID != wantOrder[i] {
+			t.Errorf("step[%d] = %q, want %q", i, s.ID, wantOrder[i])
+		}
+		if want, ok := wantNeeds[s.ID]; ok && strings.Join(s.Needs, ",") != want {
+			t.Errorf("%s.Needs = %v, want [%s]", s.ID, s.Needs, want)
+		}
+	}
+}
+
+func TestResolve_ComposeAspectsFromSearchPath(t *testing.T) {
+	dir := t.TempDir()
+	writeFormula(t, dir, "changelog-aspect", `formula = "changelog-aspect"
+type = "aspect"
+
+[[advice]]
+target = "submit"
+[advice.before]
+id = "{step.id}-changelog"
+title = "Update CHANGELOG before {step.title}"
+`)
+
+	f, err := Parse([]byte(`formula = "with-changelog"
+extends = ["shiny"]
+
+[compose]
+aspects = ["changelog-aspect"]
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	resolved, err := Resolve(f, []string{dir})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	step := resolved.GetStep("submit-changelog")
+	if step == nil {
+		t.Fatalf("submit-changelog not woven: %v", stepIDs(resolved))
+	}
+	if step.Title != "Update CHANGELOG before Submit for merge" {
+		t.Errorf("Title = %q", step.Title)
+	}
+	if got := resolved.GetStep("submit").Needs; len(got) != 1 || got[0] != "submit-changelog" {
+		t.Errorf("submit.Needs = %v, want [submit-changelog]", got)
+	}
+}
+
+func TestResolve_ComposeAspectsErrors(t *testing.T) {
+	dir := t.TempDir()
+	// Advice that reuses an existing step ID produces a duplicate.
+	writeFormula(t, dir, "clash", `formula = "clash"
+type = "aspect"
+
+[[advice]]
+target = "implement"
+[advice.after]
+id = "review"
+`)
+
+	tests := []struct {
+		name    string
+		aspect  string
+		wantErr string
+	}{
+		{name: "duplicate step", aspect: "clash", wantErr: "duplicate step id: review"},
+		{name: "not an aspect", aspect: "shiny", wantErr: `is type "workflow"`},
+		{name: "missing", aspect: "no-such-aspect", wantErr: "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formula{
+				Name:    "composed",
+				Type:    TypeWorkflow,
+				Extends: []string{"shiny"},
+				Compose: &ComposeRules{Aspects: []string{tt.aspect}},
+			}
+			_, err := Resolve(f, []string{dir})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Resolve error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package formula
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EvalCondition evaluates a step when/unless expression against formula vars.
+//
+// The grammar is intentionally small:
+//
+//	expr    = and { "||" and }
+//	and     = atom { "&&" atom }
+//	atom    = ["!"] name | name ("==" | "!=") value
+//	value   = quoted string ("..." or '...') or bare word
+//
+// A bare name is true when the variable is set to a non-empty value other
+// than "false", "0", "no" or "off". Unknown variables are treated as empty.
+// An empty expression is always true.
+func EvalCondition(expr string, vars map[string]string) (bool, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return true, nil
+	}
+	// Every operand is evaluated (no short-circuit) so syntax errors surface
+	// regardless of var values.
+	result := false
+	for _, disjunct := range splitOperator(expr, "||") {
+		all := true
+		for _, atom := range splitOperator(disjunct, "&&") {
+			ok, err := evalAtom(atom, vars)
+			if err != nil {
+				return false, fmt.Errorf("condition %q: %w", expr, err)
+			}
+			all = all && ok
+		}
+		result = result || all
+	}
+	return result, nil
+}
+
+// ValidateCondition reports a syntax error in expr without evaluating it.
+func ValidateCondition(expr string) error {
+	_, err := EvalCondition(expr, nil)
+	return err
+}
+
+// splitOperator splits expr on op, ignoring operators inside quoted values.
+func splitOperator(expr, op string) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case strings.HasPrefix(expr[i:], op):
+			parts = append(parts, expr[start:i])
+			i += len(op) - 1
+			start = i + 1
+		}
+	}
+	return append(parts, expr[start:])
+}
+
+// evalAtom evaluates a single comparison or truthiness test.
+func evalAtom(atom string, vars map[string]string) (bool, error) {
+	atom = strings.TrimSpace(atom)
+	if atom == "" {
+		return false, fmt.Errorf("empty operand")
+	}
+
+	for _, op := range []string{"==", "!="} {
+		idx := strings.Index(atom, op)
+		if idx == -1 {
+			continue
+		}
+		name := strings.TrimSpace(atom[:idx])
+		if !isConditionName(name) {
+			return false, fmt.Errorf("invalid variable name %q", name)
+		}
+		value, err := unquoteConditionValue(strings.TrimSpace(atom[idx+len(op):]))
+		if err != nil {
+			return false, err
+		}
+		equal := vars[name] == value
+		if op == "==" {
+			return equal, nil
+		}
+		return !equal, nil
+	}
+
+	negate := false
+	if strings.HasPrefix(atom, "!") {
+		negate = true
+		atom = strings.TrimSpace(atom[1:])
+	}
+	if !isConditionName(atom) {
+		return false, fmt.Errorf("invalid variable name %q", atom)
+	}
+	return isTruthy(vars[atom]) != negate, nil
+}
+
+// unquoteConditionValue strips matching quotes from a comparison value.
+func unquoteConditionValue(v string) (string, error) {
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') {
+		if v[len(v)-1] != v[0] {
+			return "", fmt.Errorf("unterminated quoted value %s", v)
+		}
+		return v[1 : len(v)-1], nil
+	}
+	if v == "" || strings.ContainsAny(v, " \t\"'") {
+		return "", fmt.Errorf("invalid comparison value %q", v)
+	}
+	return v, nil
+}
+
+// isConditionName reports whether s is a valid variable reference.
+func isConditionName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !(c == '_' || c == '-' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// isTruthy reports whether a variable value counts as set.
+func isTruthy(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "", "false", "0", "no", "off":
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,222 @@
+package formula
+
+import (
+	"fmt"
+	"time"
+)
+
+// StepState describes where a workflow step stands during execution.
+type StepState string
+
+const (
+	// StepPending means the step is waiting on unmet needs.
+	StepPending StepState = "pending"
+	// StepReady means all needs are satisfied and the step may run (or retry).
+	StepReady StepState = "ready"
+	// StepDone means the step completed successfully.
+	StepDone StepState = "done"
+	// StepSkipped means the step's when/unless condition excluded it.
+	StepSkipped StepState = "skipped"
+	// StepFailed means the step failed and has no retries left.
+	StepFailed StepState = "failed"
+	// StepTimedOut means the step's final attempt exceeded its timeout.
+	StepTimedOut StepState = "timed_out"
+)
+
+// IsTerminal reports whether no further work will happen for a step in this state.
+func (s StepState) IsTerminal() bool {
+	switch s {
+	case StepDone, StepSkipped, StepFailed, StepTimedOut:
+		return true
+	default:
+		return false
+	}
+}
+
+// satisfiesNeeds reports whether dependents of a step in this state may run.
+func (s StepState) satisfiesNeeds() bool {
+	return s == StepDone || s == StepSkipped
+}
+
+// ExecutionState is the runtime information used to decide which workflow
+// steps can run. All fields are optional.
+type ExecutionState struct {
+	Vars      map[string]string    // Var values for when/unless (unset vars fall back to defaults)
+	Completed map[string]bool      // Steps that finished successfully
+	Failures  map[string]int       // Failed or timed-out attempts per step
+	Started   map[string]time.Time // Start time of each step's current attempt
+	Now       time.Time            // Reference time for timeouts (zero means time.Now())
+}
+
+func (s *ExecutionState) completed() map[string]bool {
+	if s == nil {
+		return nil
+	}
+	return s.Completed
+}
+
+func (s *ExecutionState) now() time.Time {
+	if s == nil || s.Now.IsZero() {
+		return time.Now()
+	}
+	return s.Now
+}
+
+// validateControls checks the when/unless/retries/timeout fields of a step.
+func (s *Step) validateControls() error {
+	if err := ValidateCondition(s.When); err != nil {
+		return fmt.Errorf("step %q when: %w", s.ID, err)
+	}
+	if err := ValidateCondition(s.Unless); err != nil {
+		return fmt.Errorf("step %q unless: %w", s.ID, err)
+	}
+	if s.Retries < 0 {
+		return fmt.Errorf("step %q retries must be >= 0, got %d", s.ID, s.Retries)
+	}
+	if s.Timeout != "" {
+		d, err := time.ParseDuration(s.Timeout)
+		if err != nil {
+			return fmt.Errorf("step %q has invalid timeout %q: %w", s.ID, s.Timeout, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("step %q timeout must be positive, got %q", s.ID, s.Timeout)
+		}
+	}
+	return nil
+}
+
+// ShouldRun evaluates the step's when/unless conditions against vars.
+func (s *Step) ShouldRun(vars map[string]string) (bool, error) {
+	if s.When != "" {
+		ok, err := EvalCondition(s.When, vars)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	if s.Unless != "" {
+		skip, err := EvalCondition(s.Unless, vars)
+		if err != nil || skip {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// MaxAttempts returns the total number of attempts allowed (1 + retries).
+func (s *Step) MaxAttempts() int {
+	if s.Retries < 0 {
+		return 1
+	}
+	return s.Retries + 1
+}
+
+// TimeoutDuration returns the parsed per-attempt timeout, or 0 for no limit.
+func (s *Step) TimeoutDuration() time.Duration {
+	if s.Timeout == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(s.Timeout)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// VarValues returns the formula's var defaults overlaid with overrides.
+func (f *Formula) VarValues(overrides map[string]string) map[string]string {
+	values := make(map[string]string, len(f.Vars)+len(overrides))
+	for name, v := range f.Vars {
+		if v.Default != "" {
+			values[name] = v.Default
+		}
+	}
+	for name, v := range overrides {
+		values[name] = v
+	}
+	return values
+}
+
+// StepStates computes the execution state of every step in a workflow formula.
+// Skipped steps satisfy their dependents' needs; failed and timed-out steps
+// block them.
+func (f *Formula) StepStates(state *ExecutionState) map[string]StepState {
+	if state == nil {
+		state = &ExecutionState{}
+	}
+	vars := f.VarValues(state.Vars)
+	now := state.now()
+
+	states := make(map[string]StepState, len(f.Steps))
+	var resolve func(id string, depth int) StepState
+	resolve = func(id string, depth int) StepState {
+		if st, ok := states[id]; ok {
+			return st
+		}
+		step := f.GetStep(id)
+		if step == nil || depth > len(f.Steps) {
+			return StepPending
+		}
+
+		st := step.ownState(state, vars, now)
+		if st == StepReady {
+			for _, need := range step.Needs {
+				if !resolve(need, depth+1).satisfiesNeeds() {
+					st = StepPending
+					break
+				}
+			}
+		}
+		states[id] = st
+		return st
+	}
+
+	for _, step := range f.Steps {
+		resolve(step.ID, 0)
+	}
+	return states
+}
+
+// ownState returns a step's state ignoring its needs: done, skipped, failed,
+// timed out, or ready.
+func (s *Step) ownState(state *ExecutionState, vars map[string]string, now time.Time) StepState {
+	if state.Completed[s.ID] {
+		return StepDone
+	}
+	if run, err := s.ShouldRun(vars); err == nil && !run {
+		return StepSkipped
+	}
+
+	failures := state.Failures[s.ID]
+	timedOut := false
+	if started, ok := state.Started[s.ID]; ok {
+		if timeout := s.TimeoutDuration(); timeout > 0 && now.Sub(started) > timeout {
+			failures++
+			timedOut = true
+		}
+	}
+	if failures >= s.MaxAttempts() {
+		if timedOut {
+			return StepTimedOut
+		}
+		return StepFailed
+	}
+	return StepReady
+}
+
+// ReadyStepsFor returns the workflow steps that may run given the execution
+// state, in declaration order. Steps excluded by when/unless are never
+// returned; steps with failed attempts are returned again until their retries
+// are used up.
+func (f *Formula) ReadyStepsFor(state *ExecutionState) []string {
+	if f.Type != TypeWorkflow {
+		return f.ReadySteps(state.completed())
+	}
+	states := f.StepStates(state)
+	var ready []string
+	for _, step := range f.Steps {
+		if states[step.ID] == StepReady {
+			ready = append(ready, step.ID)
+		}
+	}
+	return ready
+}
@@ -0,0 +1,203 @@
+package formula
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvalCondition(t *testing.T) {
+	vars := map[string]string{
+		"lint":   "true",
+		"off":    "false",
+		"env":    "prod",
+		"region": "us east",
+	}
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"", true},
+		{"lint", true},
+		{"off", false},
+		{"missing", false},
+		{"!missing", true},
+		{"!lint", false},
+		{`env == "prod"`, true},
+		{"env == prod", true},
+		{`env != 'prod'`, false},
+		{`region == "us east"`, true},
+		{"lint && env == prod", true},
+		{"lint && off", false},
+		{"off || env == prod", true},
+		{"off || missing", false},
+		{`env == "a||b" || lint`, true},
+	}
+	for _, tt := range tests {
+		got, err := EvalCondition(tt.expr, vars)
+		if err != nil {
+			t.Errorf("EvalCondition(%q) error: %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("EvalCondition(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestEvalCondition_Errors(t *testing.T) {
+	for _, expr := range []string{"&& lint", "env ==", `env == "open`, "bad name", "lint ||"} {
+		if _, err := EvalCondition(expr, nil); err == nil {
+			t.Errorf("EvalCondition(%q) expected error", expr)
+		}
+	}
+}
+
+func TestValidate_StepControls(t *testing.T) {
+	tests := []struct {
+		name    string
+		step    string
+		wantErr string
+	}{
+		{"bad when", `when = "a &&"`, "when:"},
+		{"bad unless", `unless = "=="`, "unless:"},
+		{"negative retries", `retries = -1`, "retries must be >= 0"},
+		{"bad timeout", `timeout = "soon"`, "invalid timeout"},
+		{"zero timeout", `timeout = "0s"`, "timeout must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte("formula = \"x\"\n[[steps]]\nid = \"a\"\n" + tt.step + "\n"))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func parseControlledWorkflow(t *testing.T) *Formula {
+	t.Helper()
+	f, err := Parse([]byte(`
+formula = "controlled"
+
+[vars.lint]
+default = "true"
+
+[vars.docs]
+description = "Set to build docs"
+
+[[steps]]
+id = "build"
+retries = 2
+timeout = "45m"
+
+[[steps]]
+id = "lint"
+needs = ["build"]
+when = "lint"
+
+[[steps]]
+id = "docs"
+needs = ["build"]
+when = "docs"
+
+[[steps]]
+id = "ship"
+needs = ["lint", "docs"]
+unless = "dry_run"
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return f
+}
+
+func TestReadySteps_SkipsConditionalSteps(t *testing.T) {
+	f := parseControlledWorkflow(t)
+
+	// lint defaults to true; docs has no default so it is skipped and ship
+	// only waits on lint.
+	if got := f.ReadySteps(map[string]bool{"build": true}); strings.Join(got, ",") != "lint" {
+		t.Errorf("ReadySteps after build = %v, want [lint]", got)
+	}
+	if got := f.ReadySteps(map[string]bool{"build": true, "lint": true}); strings.Join(got, ",") != "ship" {
+		t.Errorf("ReadySteps after lint = %v, want [ship]", got)
+	}
+
+	// Runtime vars override defaults.
+	state := &ExecutionState{
+		Vars:      map[string]string{"lint": "false", "docs": "yes", "dry_run": "1"},
+		Completed: map[string]bool{"build": true},
+	}
+	if got := f.ReadyStepsFor(state); strings.Join(got, ",") != "docs" {
+		t.Errorf("ReadyStepsFor = %v, want [docs]", got)
+	}
+	state.Completed["docs"] = true
+	states := f.StepStates(state)
+	if states["lint"] != StepSkipped || states["ship"] != StepSkipped {
+		t.Errorf("states = %v, want lint and ship skipped", states)
+	}
+}
+
+func TestStepStates_RetriesAndTimeout(t *testing.T) {
+	f := parseControlledWorkflow(t)
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	// Two failures with retries = 2 still allows a third attempt.
+	state := &ExecutionState{Failures: map[string]int{"build": 2}, Now: now}
+	if got := f.ReadyStepsFor(state); strings.Join(got, ",") != "build" {
+		t.Errorf("ReadyStepsFor with retries left = %v, want [build]", got)
+	}
+
+	// A third attempt running past the timeout exhausts retries.
+	state.Started = map[string]time.Time{"build": now.Add(-46 * time.Minute)}
+	states := f.StepStates(state)
+	if states["build"] != StepTimedOut {
+		t.Errorf("build state = %q, want %q", states["build"], StepTimedOut)
+	}
+	if states["lint"] != StepPending {
+		t.Errorf("lint state = %q, want pending behind timed-out build", states["lint"])
+	}
+	if got := f.ReadyStepsFor(state); len(got) != 0 {
+		t.Errorf("ReadyStepsFor = %v, want none", got)
+	}
+
+	// Three plain failures mark the step failed.
+	state = &ExecutionState{Failures: map[string]int{"build": 3}, Now: now}
+	if got := f.StepStates(state)["build"]; got != StepFailed {
+		t.Errorf("build state = %q, want %q", got, StepFailed)
+	}
+}
+
+func TestParallelReadyStepsFor_Conditional(t *testing.T) {
+	f, err := Parse([]byte(`
+formula = "fanout"
+
+[[steps]]
+id = "setup"
+
+[[steps]]
+id = "unit"
+needs = ["setup"]
+parallel = true
+
+[[steps]]
+id = "e2e"
+needs = ["setup"]
+parallel = true
+when = "e2e"
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	completed := map[string]bool{"setup": true}
+	parallel, _ := f.ParallelReadySteps(completed)
+	if strings.Join(parallel, ",") != "unit" {
+		t.Errorf("ParallelReadySteps = %v, want [unit]", parallel)
+	}
+	parallel, _ = f.ParallelReadyStepsFor(&ExecutionState{Completed: completed, Vars: map[string]string{"e2e": "true"}})
+	if strings.Join(parallel, ",") != "unit,e2e" {
+		t.Errorf("ParallelReadyStepsFor = %v, want [unit e2e]", parallel)
+	}
+}
@@ -77,20 +77,26 @@ func loadOverlayFile(path string) (*FormulaOverlay, error) {
 		return nil, fmt.Errorf("parsing overlay TOML: %w", err)
 	}
 
-	// Validate overrides.
-	for i, so := range overlay.StepOverrides {
+	if err := overlay.validate(); err != nil {
+		return nil, err
+	}
+	return &overlay, nil
+}
+
+// validate checks that every override names a step and a known mode.
+func (o *FormulaOverlay) validate() error {
+	for i, so := range o.StepOverrides {
 		if so.StepID == "" {
-			return nil, fmt.Errorf("step-overrides[%d]: step_id is required", i)
+			return fmt.Errorf("step-overrides[%d]: step_id is required", i)
 		}
 		switch so.Mode {
 		case ModeReplace, ModeAppend, ModeSkip:
 			// valid
 		default:
-			return nil, fmt.Errorf("step-overrides[%d] (step_id=%q): invalid mode %q (must be replace, append, or skip)", i, so.StepID, so.Mode)
+			return fmt.Errorf("step-overrides[%d] (step_id=%q): invalid mode %q (must be replace, append, or skip)", i, so.StepID, so.Mode)
 		}
 	}
-
-	return &overlay, nil
+	return nil
 }
 
 // ApplyOverlays modifies formula steps in place according to the overlay.
@@ -51,7 +51,7 @@ func (f *Formula) inferType() {
 		f.Type = TypeConvoy
 	} else if len(f.Template) > 0 {
 		f.Type = TypeExpansion
-	} else if len(f.Aspects) > 0 {
+	} else if len(f.Aspects) > 0 || len(f.Advice) > 0 {
 		f.Type = TypeAspect
 	}
 }
@@ -147,6 +147,13 @@ func (f *Formula) validateWorkflow() error {
 		}
 	}
 
+	// Validate execution controls (when/unless/retries/timeout)
+	for _, step := range f.Steps {
+		if err := step.validateControls(); err != nil {
+			return err
+		}
+	}
+
 	// Check for cycles
 	if err := f.checkCycles(); err != nil {
 		return err
@@ -190,8 +197,8 @@ func (f *Formula) validateExpansion() error {
 }
 
 func (f *Formula) validateAspect() error {
-	if len(f.Aspects) == 0 {
-		return fmt.Errorf("aspect formula requires at least one aspect")
+	if len(f.Aspects) == 0 && len(f.Advice) == 0 {
+		return fmt.Errorf("aspect formula requires at least one aspect or advice rule")
 	}
 
 	// Check aspect IDs are unique
@@ -206,7 +213,7 @@ func (f *Formula) validateAspect() error {
 		seen[aspect.ID] = true
 	}
 
-	return nil
+	return f.validateAdvice()
 }
 
 // checkCycles detects circular dependencies in steps.
@@ -362,26 +369,14 @@ func (f *Formula) TopologicalSort() ([]string, error) {
 
 // ReadySteps returns steps that have no unmet dependencies.
 // completed is a set of step IDs that have been completed.
+// For workflow formulas, when/unless conditions are evaluated against the
+// var defaults; use ReadyStepsFor to supply runtime vars and attempt history.
 func (f *Formula) ReadySteps(completed map[string]bool) []string {
 	var ready []string
 
 	switch f.Type {
 	case TypeWorkflow:
-		for _, step := range f.Steps {
-			if completed[step.ID] {
-				continue
-			}
-			allMet := true
-			for _, need := range step.Needs {
-				if !completed[need] {
-					allMet = false
-					break
-				}
-			}
-			if allMet {
-				ready = append(ready, step.ID)
-			}
-		}
+		return f.ReadyStepsFor(&ExecutionState{Completed: completed})
 	case TypeExpansion:
 		for _, tmpl := range f.Template {
 			if completed[tmpl.ID] {
@@ -433,7 +428,18 @@ func (f *Formula) GetStep(id string) *Step {
 // - sequentialStep: the first non-parallel ready step, or nil if all are parallel
 // If multiple parallel steps are ready, they should all be executed concurrently.
 func (f *Formula) ParallelReadySteps(completed map[string]bool) (parallel []string, sequential string) {
-	ready := f.ReadySteps(completed)
+	return f.ParallelReadyStepsFor(&ExecutionState{Completed: completed})
+}
+
+// ParallelReadyStepsFor is ParallelReadySteps driven by a full execution state,
+// so skipped, failed and timed-out steps are taken into account.
+func (f *Formula) ParallelReadyStepsFor(state *ExecutionState) (parallel []string, sequential string) {
+	var ready []string
+	if f.Type == TypeWorkflow {
+		ready = f.ReadyStepsFor(state)
+	} else {
+		ready = f.ReadySteps(state.completed())
+	}
 	if len(ready) == 0 {
 		return nil, ""
 	}
@@ -499,7 +505,8 @@ func (f *Formula) GetAspect(id string) *Aspect {
 }
 
 // Resolve processes the extends and compose rules of a formula, returning a new
-// formula with all inherited steps merged and expansion rules applied.
+// formula with all inherited steps merged, expansion rules applied, and
+// aspect advice woven in.
 //
 // Parent formulas named in extends are loaded from the embedded formula FS first,
 // then from any additional searchPaths (in order). searchPaths may be nil.
@@ -589,7 +596,13 @@ func resolveChain(formula *Formula, searchPaths []string, chain []string) (*Form
 			}
 			merged.Steps = expanded
 		}
-		// compose.aspects is recorded but not yet acted upon (future work).
+		for _, aspectName := range formula.Compose.Aspects {
+			woven, err := applyAspect(merged.Steps, aspectName, searchPaths)
+			if err != nil {
+				return nil, fmt.Errorf("compose aspect %q: %w", aspectName, err)
+			}
+			merged.Steps = woven
+		}
 	}
 
 	if err := merged.Validate(); err != nil {
@@ -678,17 +691,7 @@ func applyExpandRule(steps []Step, rule *ExpandRule, searchPaths []string) ([]St
 			continue
 		}
 		// Rewrite any needs that referenced the replaced target.
-		updated := false
-		for j, need := range step.Needs {
-			if need == rule.Target {
-				if !updated {
-					step.Needs = append([]string(nil), step.Needs...)
-					updated = true
-				}
-				step.Needs[j] = lastExpanded
-			}
-		}
-		result = append(result, step)
+		result = append(result, rewireNeeds(step, rule.Target, lastExpanded))
 	}
 	return result, nil
 }
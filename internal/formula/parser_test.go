@@ -588,7 +588,7 @@ func TestResolve_ShinyEnterprise(t *testing.T) {
 }
 
 // TestResolve_ShinySecure verifies that shiny-secure (extends shiny, aspects only)
-// resolves to the shiny steps without error.
+// weaves the security-audit advice around implement and submit.
 func TestResolve_ShinySecure(t *testing.T) {
 	data, err := GetEmbeddedFormulaContent("shiny-secure")
 	if err != nil {
@@ -605,8 +605,18 @@ func TestResolve_ShinySecure(t *testing.T) {
 		t.Fatalf("Resolve: %v", err)
 	}
 
-	// shiny-secure has no expand rules; it should produce the same 5 steps as shiny.
-	wantIDs := []string{"design", "implement", "review", "test", "submit"}
+	// security-audit wraps implement and submit with prescan/postscan steps.
+	wantIDs := []string{
+		"design",
+		"implement-security-prescan",
+		"implement",
+		"implement-security-postscan",
+		"review",
+		"test",
+		"submit-security-prescan",
+		"submit",
+		"submit-security-postscan",
+	}
 	if len(resolved.Steps) != len(wantIDs) {
 		t.Fatalf("got %d steps, want %d: %v", len(resolved.Steps), len(wantIDs), stepIDs(resolved))
 	}
@@ -615,6 +625,28 @@ func TestResolve_ShinySecure(t *testing.T) {
 			t.Errorf("step[%d] = %q, want %q", i, got, want)
 		}
 	}
+
+	wantNeeds := map[string][]string{
+		"implement-security-prescan":  {"design"},
+		"implement":                   {"implement-security-prescan"},
+		"implement-security-postscan": {"implement"},
+		"review":                      {"implement-security-postscan"},
+		"submit-security-prescan":     {"test"},
+		"submit":                      {"submit-security-prescan"},
+		"submit-security-postscan":    {"submit"},
+	}
+	for id, want := range wantNeeds {
+		step := resolved.GetStep(id)
+		if step == nil {
+			t.Fatalf("step %q not found", id)
+		}
+		if strings.Join(step.Needs, ",") != strings.Join(want, ",") {
+			t.Errorf("%s.Needs = %v, want %v", id, step.Needs, want)
+		}
+	}
+	if got := resolved.GetStep("implement-security-prescan").Title; got != "Security prescan for implement" {
+		t.Errorf("prescan title = %q, want placeholder expanded", got)
+	}
 }
 
 // TestResolve_CycleDetection verifies that circular extends chains are rejected.
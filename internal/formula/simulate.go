@@ -0,0 +1,204 @@
+package formula
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Outcome is the scripted result of one simulated step attempt.
+type Outcome string
+
+const (
+	// OutcomeOK means the attempt succeeded and the step is done.
+	OutcomeOK Outcome = "ok"
+	// OutcomeFail means the attempt failed; the step is retried while it has
+	// retries left.
+	OutcomeFail Outcome = "fail"
+	// OutcomeTimeout means the attempt ran past the step's timeout. It uses
+	// up an attempt like a failure.
+	OutcomeTimeout Outcome = "timeout"
+)
+
+// SimAttempt is one step attempt in a simulated execution.
+type SimAttempt struct {
+	Round   int     `json:"round"`   // 1-based scheduling round; attempts in one round run concurrently
+	Step    string  `json:"step"`    // step ID
+	Attempt int     `json:"attempt"` // 1-based attempt number for this step
+	Outcome Outcome `json:"outcome"`
+}
+
+// String renders the attempt as a trace entry: the step ID, with ":fail" or
+// ":timeout" appended for unsuccessful attempts.
+func (a SimAttempt) String() string {
+	if a.Outcome == OutcomeOK {
+		return a.Step
+	}
+	return a.Step + ":" + string(a.Outcome)
+}
+
+// Simulation result values.
+const (
+	SimComplete = "complete" // every step is done or skipped
+	SimFailed   = "failed"   // a step failed or timed out with no retries left
+	SimStuck    = "stuck"    // steps remain that can never become ready
+)
+
+// Simulation is the outcome of driving a workflow formula to completion with
+// scripted step results.
+type Simulation struct {
+	Trace  []SimAttempt         `json:"trace"`
+	States map[string]StepState `json:"states"`
+	Result string               `json:"result"` // SimComplete, SimFailed or SimStuck
+}
+
+// TraceStrings returns the trace as trace entries (see SimAttempt.String).
+func (s *Simulation) TraceStrings() []string {
+	out := make([]string, len(s.Trace))
+	for i, a := range s.Trace {
+		out[i] = a.String()
+	}
+	return out
+}
+
+// Rounds returns the trace entries grouped by scheduling round.
+func (s *Simulation) Rounds() [][]string {
+	var rounds [][]string
+	for _, a := range s.Trace {
+		for len(rounds) < a.Round {
+			rounds = append(rounds, nil)
+		}
+		rounds[a.Round-1] = append(rounds[a.Round-1], a.String())
+	}
+	return rounds
+}
+
+// StepsIn returns the IDs of steps that ended in state, sorted.
+func (s *Simulation) StepsIn(state StepState) []string {
+	var ids []string
+	for id, st := range s.States {
+		if st == state {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Simulate runs a workflow formula the way agents would work through it:
+// each round takes the steps ParallelReadyStepsFor offers, completes them
+// with the scripted outcome for that attempt (OutcomeOK once the script runs
+// out), and repeats until no step is ready. vars feed when/unless conditions
+// as in ExecutionState.Vars.
+func (f *Formula) Simulate(vars map[string]string, script map[string][]Outcome) (*Simulation, error) {
+	if f.Type != TypeWorkflow {
+		return nil, fmt.Errorf("only workflow formulas can be simulated, %q is %s", f.Name, f.Type)
+	}
+	for id, outcomes := range script {
+		if f.GetStep(id) == nil {
+			return nil, fmt.Errorf("script references unknown step %q", id)
+		}
+		for _, o := range outcomes {
+			switch o {
+			case OutcomeOK, OutcomeFail, OutcomeTimeout:
+			default:
+				return nil, fmt.Errorf("step %q: invalid outcome %q (must be ok, fail, or timeout)", id, o)
+			}
+		}
+	}
+
+	state := &ExecutionState{
+		Vars:      vars,
+		Completed: make(map[string]bool),
+		Failures:  make(map[string]int),
+	}
+	sim := &Simulation{}
+	attempts := make(map[string]int)
+	timedOut := make(map[string]bool)
+
+	// Every round completes or fails at least one attempt, and each step
+	// has at most MaxAttempts attempts, so this bound is never reached by
+	// a well-formed formula.
+	maxRounds := 1
+	for i := range f.Steps {
+		maxRounds += f.Steps[i].MaxAttempts()
+	}
+	for round := 1; ; round++ {
+		if round > maxRounds {
+			return nil, fmt.Errorf("simulation did not settle after %d rounds", maxRounds)
+		}
+		parallel, sequential := f.ParallelReadyStepsFor(state)
+		batch := parallel
+		if sequential != "" {
+			batch = []string{sequential}
+		}
+		if len(batch) == 0 {
+			break
+		}
+		for _, id := range batch {
+			n := attempts[id]
+			attempts[id]++
+			outcome := OutcomeOK
+			if n < len(script[id]) {
+				outcome = script[id][n]
+			}
+			sim.Trace = append(sim.Trace, SimAttempt{Round: round, Step: id, Attempt: n + 1, Outcome: outcome})
+			if outcome == OutcomeOK {
+				state.Completed[id] = true
+			} else {
+				state.Failures[id]++
+				timedOut[id] = outcome == OutcomeTimeout
+			}
+		}
+	}
+
+	// The simulation has no clock, so a final timeout shows up as a plain
+	// failure in StepStates; report it as the timeout it was.
+	sim.States = f.StepStates(state)
+	for id, st := range sim.States {
+		if st == StepFailed && timedOut[id] {
+			sim.States[id] = StepTimedOut
+		}
+	}
+
+	sim.Result = SimComplete
+	for _, st := range sim.States {
+		switch st {
+		case StepFailed, StepTimedOut:
+			sim.Result = SimFailed
+		case StepPending, StepReady:
+			if sim.Result == SimComplete {
+				sim.Result = SimStuck
+			}
+		}
+	}
+	return sim, nil
+}
+
+// SubstituteVars replaces {{name}} placeholders in text with vars[name].
+// Placeholders for names without a value are left as they are.
+func SubstituteVars(text string, vars map[string]string) string {
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+	return variablePattern.ReplaceAllStringFunc(text, func(m string) string {
+		name := variablePattern.FindStringSubmatch(m)[1]
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		return m
+	})
+}
+
+// MissingRequiredVars returns the required vars that have no value in vars,
+// sorted.
+func (f *Formula) MissingRequiredVars(vars map[string]string) []string {
+	var missing []string
+	for name, v := range f.Vars {
+		if v.Required && vars[name] == "" {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
@@ -0,0 +1,86 @@
+package formula
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSimulate_RetriesParallelAndConditions(t *testing.T) {
+	f, err := Parse([]byte(`
+formula = "sim"
+type = "workflow"
+
+[vars]
+lint = "false"
+
+[[steps]]
+id = "setup"
+
+[[steps]]
+id = "unit"
+needs = ["setup"]
+parallel = true
+retries = 1
+
+[[steps]]
+id = "integration"
+needs = ["setup"]
+parallel = true
+
+[[steps]]
+id = "lint"
+needs = ["setup"]
+when = "lint"
+
+[[steps]]
+id = "ship"
+needs = ["unit", "integration", "lint"]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sim, err := f.Simulate(nil, map[string][]Outcome{"unit": {OutcomeFail}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(sim.TraceStrings(), " "); got != "setup unit:fail integration unit ship" {
+		t.Errorf("trace = %s", got)
+	}
+	if got := sim.Rounds(); len(got) != 4 || !slices.Equal(got[1], []string{"unit:fail", "integration"}) {
+		t.Errorf("rounds = %v", got)
+	}
+	if sim.Result != SimComplete || !slices.Equal(sim.StepsIn(StepSkipped), []string{"lint"}) {
+		t.Errorf("result = %s, skipped = %v", sim.Result, sim.StepsIn(StepSkipped))
+	}
+
+	sim, err = f.Simulate(map[string]string{"lint": "true"}, map[string][]Outcome{"lint": {OutcomeTimeout}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sim.Result != SimFailed || sim.States["lint"] != StepTimedOut || sim.States["ship"] != StepPending {
+		t.Errorf("result = %s, states = %v", sim.Result, sim.States)
+	}
+}
+
+func TestSimulate_RejectsBadScript(t *testing.T) {
+	f := &Formula{Name: "x", Type: TypeWorkflow, Steps: []Step{{ID: "a"}}}
+	if _, err := f.Simulate(nil, map[string][]Outcome{"nope": {OutcomeOK}}); err == nil {
+		t.Error("expected error for unknown step")
+	}
+	if _, err := f.Simulate(nil, map[string][]Outcome{"a": {"maybe"}}); err == nil {
+		t.Error("expected error for invalid outcome")
+	}
+	convoy := &Formula{Name: "c", Type: TypeConvoy}
+	if _, err := convoy.Simulate(nil, nil); err == nil {
+		t.Error("expected error for non-workflow formula")
+	}
+}
+
+func TestSubstituteVars(t *testing.T) {
+	got := SubstituteVars("Implement {{feature}} for {{who}} {{#if x}}", map[string]string{"feature": "login"})
+	if got != "Implement login for {{who}} {{#if x}}" {
+		t.Errorf("SubstituteVars = %q", got)
+	}
+}
@@ -0,0 +1,217 @@
+package formula
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// TestFileSuffix is the file suffix of formula test files. A test file for
+// formula "shiny" is named shiny.formula.test.toml and usually sits next to
+// the formula.
+const TestFileSuffix = ".formula.test.toml"
+
+// TestFile is a parsed *.formula.test.toml file: a formula to resolve, shared
+// vars and overlay, and cases that each script step outcomes and assert the
+// resulting execution.
+//
+//	formula = "shiny"
+//
+//	[vars]
+//	feature = "login"
+//
+//	[[step-overrides]]          # optional, same format as formula-overlays/*.toml
+//	step_id = "review"
+//	mode = "skip"
+//
+//	[[cases]]
+//	name = "failing tests stop the run"
+//	script = { test = ["fail"] }
+//	trace = ["design", "implement", "test:fail"]
+//	result = "failed"
+type TestFile struct {
+	Formula string            `toml:"formula"`
+	Vars    map[string]string `toml:"vars"`
+	FormulaOverlay
+	Cases []TestCase `toml:"cases"`
+}
+
+// TestCase is one scripted run of the formula and its expectations. Empty
+// expectations are not checked.
+type TestCase struct {
+	Name string            `toml:"name"`
+	Vars map[string]string `toml:"vars"` // Overrides the file's vars
+
+	// Script lists the outcome of each attempt of a step: "ok", "fail" or
+	// "timeout". Attempts past the end of the list succeed.
+	Script map[string][]Outcome `toml:"script"`
+
+	Trace    []string          `toml:"trace"`    // Expected attempts in order (see SimAttempt.String)
+	Rounds   [][]string        `toml:"rounds"`   // Expected attempts grouped into concurrent rounds
+	Skipped  []string          `toml:"skipped"`  // Expected steps skipped by when/unless, any order
+	Result   string            `toml:"result"`   // Expected "complete", "failed" or "stuck"
+	Contains map[string]string `toml:"contains"` // Step ID → text its title or description must contain after var substitution
+}
+
+// CaseResult is the outcome of running one TestCase.
+type CaseResult struct {
+	Name       string      `json:"name"`
+	Failures   []string    `json:"failures,omitempty"`
+	Simulation *Simulation `json:"simulation,omitempty"`
+}
+
+// Passed reports whether every expectation of the case held.
+func (r *CaseResult) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// ParseTestFile reads and validates a formula test file.
+func ParseTestFile(path string) (*TestFile, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is given by the user
+	if err != nil {
+		return nil, fmt.Errorf("reading formula test file: %w", err)
+	}
+	return ParseTest(data)
+}
+
+// ParseTest parses formula test TOML content from bytes.
+func ParseTest(data []byte) (*TestFile, error) {
+	var t TestFile
+	if _, err := toml.Decode(string(data), &t); err != nil {
+		return nil, fmt.Errorf("parsing TOML: %w", err)
+	}
+	if t.Formula == "" {
+		return nil, fmt.Errorf("formula is required")
+	}
+	if len(t.Cases) == 0 {
+		return nil, fmt.Errorf("no [[cases]] defined")
+	}
+	if err := t.FormulaOverlay.validate(); err != nil {
+		return nil, err
+	}
+	for i, c := range t.Cases {
+		if c.Name == "" {
+			t.Cases[i].Name = fmt.Sprintf("case %d", i+1)
+		}
+		switch c.Result {
+		case "", SimComplete, SimFailed, SimStuck:
+		default:
+			return nil, fmt.Errorf("case %q: invalid result %q (must be complete, failed, or stuck)", t.Cases[i].Name, c.Result)
+		}
+	}
+	return &t, nil
+}
+
+// Run runs every case against f, which should already be resolved (see
+// Resolve). f is not modified.
+func (t *TestFile) Run(f *Formula) []CaseResult {
+	results := make([]CaseResult, len(t.Cases))
+	for i, c := range t.Cases {
+		results[i] = t.runCase(f, c)
+	}
+	return results
+}
+
+func (t *TestFile) runCase(f *Formula, c TestCase) CaseResult {
+	res := CaseResult{Name: c.Name}
+	fail := func(format string, args ...any) {
+		res.Failures = append(res.Failures, fmt.Sprintf(format, args...))
+	}
+
+	g := f.clone()
+	for _, w := range ApplyOverlays(g, &t.FormulaOverlay) {
+		fail("overlay: %s", w)
+	}
+
+	overrides := make(map[string]string, len(t.Vars)+len(c.Vars))
+	for k, v := range t.Vars {
+		overrides[k] = v
+	}
+	for k, v := range c.Vars {
+		overrides[k] = v
+	}
+	vars := g.VarValues(overrides)
+	if missing := g.MissingRequiredVars(vars); len(missing) > 0 {
+		fail("missing required vars: %s", strings.Join(missing, ", "))
+	}
+	for i := range g.Steps {
+		s := &g.Steps[i]
+		s.Title = SubstituteVars(s.Title, vars)
+		s.Description = SubstituteVars(s.Description, vars)
+		s.Acceptance = SubstituteVars(s.Acceptance, vars)
+	}
+
+	sim, err := g.Simulate(vars, c.Script)
+	if err != nil {
+		fail("%v", err)
+		return res
+	}
+	res.Simulation = sim
+
+	if c.Trace != nil {
+		if got := sim.TraceStrings(); !slices.Equal(got, c.Trace) {
+			fail("trace:\n    want %s\n    got  %s", strings.Join(c.Trace, " → "), strings.Join(got, " → "))
+		}
+	}
+	if c.Rounds != nil {
+		got := sim.Rounds()
+		if !slices.EqualFunc(got, c.Rounds, slices.Equal[[]string]) {
+			fail("rounds:\n    want %s\n    got  %s", formatRounds(c.Rounds), formatRounds(got))
+		}
+	}
+	if c.Skipped != nil {
+		want := slices.Clone(c.Skipped)
+		sort.Strings(want)
+		if got := sim.StepsIn(StepSkipped); !slices.Equal(got, want) {
+			fail("skipped: want [%s], got [%s]", strings.Join(want, ", "), strings.Join(got, ", "))
+		}
+	}
+	if c.Result != "" && sim.Result != c.Result {
+		fail("result: want %s, got %s", c.Result, sim.Result)
+	}
+
+	ids := make([]string, 0, len(c.Contains))
+	for id := range c.Contains {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		step := g.GetStep(id)
+		if step == nil {
+			fail("contains: unknown step %q", id)
+			continue
+		}
+		if want := c.Contains[id]; !strings.Contains(step.Title+"\n"+step.Description, want) {
+			fail("contains: step %q does not contain %q", id, want)
+		}
+	}
+	return res
+}
+
+func formatRounds(rounds [][]string) string {
+	parts := make([]string, len(rounds))
+	for i, r := range rounds {
+		parts[i] = "[" + strings.Join(r, ", ") + "]"
+	}
+	return strings.Join(parts, " ")
+}
+
+// clone returns a copy of f whose steps and vars can be modified without
+// affecting f.
+func (f *Formula) clone() *Formula {
+	g := *f
+	g.Steps = make([]Step, len(f.Steps))
+	for i, s := range f.Steps {
+		s.Needs = slices.Clone(s.Needs)
+		g.Steps[i] = s
+	}
+	g.Vars = make(map[string]Var, len(f.Vars))
+	for k, v := range f.Vars {
+		g.Vars[k] = v
+	}
+	return &g
+}
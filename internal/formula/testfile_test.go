@@ -0,0 +1,89 @@
+package formula
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTestFile_RunResolvedFormula(t *testing.T) {
+	// shiny-enterprise extends shiny and expands implement with rule-of-five,
+	// so this exercises resolution, overlays and substitution end to end.
+	tf, err := ParseTest([]byte(`
+formula = "shiny-enterprise"
+
+[vars]
+feature = "login"
+
+[[step-overrides]]
+step_id = "review"
+mode = "skip"
+
+[[cases]]
+name = "happy path"
+result = "complete"
+trace = ["design", "implement.draft", "implement.refine-1", "implement.refine-2",
+         "implement.refine-3", "implement.refine-4", "test", "submit"]
+
+[cases.contains]
+design = "Design login"
+
+[[cases]]
+name = "tests fail"
+script = { test = ["fail"] }
+result = "failed"
+trace = ["design", "implement.draft", "implement.refine-1", "implement.refine-2",
+         "implement.refine-3", "implement.refine-4", "test:fail"]
+
+[[cases]]
+name = "wrong expectations"
+vars = { feature = "" }
+result = "stuck"
+trace = ["design"]
+contains = { design = "signup", nope = "x" }
+`))
+	if err != nil {
+		t.Fatalf("ParseTest: %v", err)
+	}
+	parsed, err := loadFormulaByName(tf.Formula, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := Resolve(parsed, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := tf.Run(f)
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	for _, r := range results[:2] {
+		if !r.Passed() {
+			t.Errorf("%s failed: %v", r.Name, r.Failures)
+		}
+	}
+	failures := strings.Join(results[2].Failures, "\n")
+	for _, want := range []string{"missing required vars: feature", "trace:", "result: want stuck, got complete", `"signup"`, `unknown step "nope"`} {
+		if !strings.Contains(failures, want) {
+			t.Errorf("failures missing %q:\n%s", want, failures)
+		}
+	}
+	if f.GetStep("review") == nil {
+		t.Error("Run modified the formula: review step removed")
+	}
+}
+
+func TestParseTest_Errors(t *testing.T) {
+	tests := map[string]string{
+		"no formula":   `[[cases]]` + "\n" + `name = "x"`,
+		"no cases":     `formula = "shiny"`,
+		"bad result":   "formula = \"shiny\"\n[[cases]]\nresult = \"done\"",
+		"bad overlay":  "formula = \"shiny\"\n[[step-overrides]]\nstep_id = \"x\"\nmode = \"drop\"\n[[cases]]\nname = \"x\"",
+		"invalid toml": `formula = `,
+	}
+	for name, data := range tests {
+		if _, err := ParseTest([]byte(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
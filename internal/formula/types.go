@@ -55,6 +55,10 @@ type Formula struct {
 
 	// Aspect-specific (similar to convoy but for analysis)
 	Aspects []Aspect `toml:"aspects"`
+
+	// Aspect weaving (applied to other formulas via compose.aspects)
+	Advice    []*AdviceRule `toml:"advice"`
+	Pointcuts []*Pointcut   `toml:"pointcuts"`
 }
 
 // ComposeRules defines how a formula can be composed with others.
@@ -62,8 +66,8 @@ type ComposeRules struct {
 	// Expand replaces a single target step with an expansion formula's template steps.
 	Expand []*ExpandRule `toml:"expand"`
 
-	// Aspects lists aspect formula names to apply to this formula.
-	// (Reserved for future implementation.)
+	// Aspects lists aspect formula names whose advice is woven into this
+	// formula's steps, in order, after expand rules have been applied.
 	Aspects []string `toml:"aspects"`
 }
 
@@ -76,6 +80,40 @@ type ExpandRule struct {
 	With string `toml:"with"`
 }
 
+// AdviceRule weaves additional steps before, after, or around every step whose
+// ID matches Target. Target is an exact step ID or a glob pattern
+// (path.Match syntax, e.g. "implement.*").
+type AdviceRule struct {
+	Target string        `toml:"target"`
+	Before *AdviceStep   `toml:"before"`
+	After  *AdviceStep   `toml:"after"`
+	Around *AroundAdvice `toml:"around"`
+}
+
+// AroundAdvice wraps a target step with steps on both sides.
+// Around steps are outermost: around.before, before, target, after, around.after.
+type AroundAdvice struct {
+	Before []*AdviceStep `toml:"before"`
+	After  []*AdviceStep `toml:"after"`
+}
+
+// AdviceStep is a step template inserted by advice. The placeholders
+// {step.id}, {step.title} and {step.description} are replaced with the
+// matched target step's values.
+type AdviceStep struct {
+	ID          string `toml:"id"`
+	Title       string `toml:"title"`
+	Description string `toml:"description"`
+	Acceptance  string `toml:"acceptance"`
+}
+
+// Pointcut restricts which steps an aspect's advice may apply to.
+// When an aspect declares pointcuts, a step must match at least one of them
+// in addition to the advice target.
+type Pointcut struct {
+	Glob string `toml:"glob"`
+}
+
 // Aspect represents a parallel analysis aspect in an aspect formula.
 type Aspect struct {
 	ID          string `toml:"id"`
@@ -125,6 +163,10 @@ type Step struct {
 	Needs       []string `toml:"needs"`
 	Parallel    bool     `toml:"parallel"`   // If true, this step can run concurrently with other parallel steps that share the same needs
 	Acceptance  string   `toml:"acceptance"` // Exit criteria for this step (used by Ralph loop mode)
+	When        string   `toml:"when"`       // Run only if this condition over formula vars holds (see EvalCondition)
+	Unless      string   `toml:"unless"`     // Skip if this condition over formula vars holds
+	Retries     int      `toml:"retries"`    // Extra attempts allowed after a failed or timed-out attempt
+	Timeout     string   `toml:"timeout"`    // Max duration of a single attempt (e.g. "45m"); empty means no limit
 }
 
 // Template represents a template step in an expansion formula.
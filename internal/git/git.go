@@ -248,6 +248,12 @@ func (g *Git) Clone(url, dest string) error {
 	return g.cloneInternal(url, dest, cloneOptions{singleBranch: true, depth: 1})
 }
 
+// CloneFull clones a repository with full history, so any commit can be
+// checked out afterwards.
+func (g *Git) CloneFull(url, dest string) error {
+	return g.cloneInternal(url, dest, cloneOptions{})
+}
+
 // CloneWithReference clones a repository using a local repo as an object reference.
 // This saves disk by sharing objects without changing remotes.
 // Uses --single-branch --depth 1 for efficiency on repos with many branches.
@@ -807,6 +813,32 @@ func (g *Git) ListRemoteRefs(remote, prefix string) ([]string, error) {
 	return refs, nil
 }
 
+// ResolveRemoteRef returns the commit that ref (a branch, a tag, or "HEAD")
+// points to on remote, which may be a URL. Annotated tags are peeled to the
+// commit they tag.
+func (g *Git) ResolveRemoteRef(remote, ref string) (string, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	out, err := g.run("ls-remote", remote, ref)
+	if err != nil {
+		return "", err
+	}
+	found := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.Fields(line)
+		if len(parts) >= 2 {
+			found[parts[1]] = parts[0]
+		}
+	}
+	for _, name := range []string{ref, "refs/heads/" + ref, "refs/tags/" + ref + "^{}", "refs/tags/" + ref} {
+		if sha, ok := found[name]; ok {
+			return sha, nil
+		}
+	}
+	return "", fmt.Errorf("ref %q not found on %s", ref, remote)
+}
+
 // ListPushRemoteRefs lists remote refs from the push URL when it differs from
 // the fetch URL. With a fork-based workflow (pushurl configured), branches are
 // pushed to the fork but ls-remote reads from the fetch URL (upstream). This
@@ -0,0 +1,301 @@
+package guard
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Call is a tool call as seen by a PreToolUse hook.
+type Call struct {
+	// Tool is the tool name (e.g., Bash, Write, Edit).
+	Tool string
+
+	// Command is the shell command of a Bash call.
+	Command string
+
+	// Path is the file a file tool operates on.
+	Path string
+
+	// Cwd is the agent's working directory.
+	Cwd string
+}
+
+// ParseHookInput builds a Call from Claude Code PreToolUse hook input.
+// Returns nil if the input is not a tool call.
+func ParseHookInput(input []byte) *Call {
+	if len(input) == 0 {
+		return nil
+	}
+	var hookInput struct {
+		ToolName  string `json:"tool_name"`
+		Cwd       string `json:"cwd"`
+		ToolInput struct {
+			Command      string `json:"command"`
+			FilePath     string `json:"file_path"`
+			NotebookPath string `json:"notebook_path"`
+		} `json:"tool_input"`
+	}
+	if err := json.Unmarshal(input, &hookInput); err != nil || hookInput.ToolName == "" {
+		return nil
+	}
+	c := &Call{
+		Tool:    hookInput.ToolName,
+		Command: hookInput.ToolInput.Command,
+		Path:    hookInput.ToolInput.FilePath,
+		Cwd:     hookInput.Cwd,
+	}
+	if c.Path == "" {
+		c.Path = hookInput.ToolInput.NotebookPath
+	}
+	return c
+}
+
+// Decision is the outcome of evaluating a rule set against a call.
+type Decision struct {
+	// Matched lists every rule that matched any part of the call, in rule
+	// set order.
+	Matched []*Rule
+
+	// Rule is the rule that decides the call. Each command of a Bash call
+	// is decided on its own by the last rule that matched it, so more
+	// specific files override less specific ones; the call then goes to
+	// the most restrictive of those decisions (block or escalate, then
+	// warn, then allow). Nil if no rule matched.
+	Rule *Rule
+
+	// Commands are the decisions for each command of a Bash call, in
+	// order. Nil for other tools.
+	Commands []CommandDecision
+}
+
+// CommandDecision is the decision for one command of a Bash call.
+type CommandDecision struct {
+	// Command is the command as split by SplitCommand.
+	Command string
+
+	// Rule is the last rule that matched the command, or nil.
+	Rule *Rule
+}
+
+// Allowed reports whether an allow rule decides the command.
+func (cd CommandDecision) Allowed() bool {
+	return cd.Rule != nil && cd.Rule.Action == ActionAllow
+}
+
+// Action returns the deciding rule's action, or "" if no rule matched.
+func (d *Decision) Action() Action {
+	if d == nil || d.Rule == nil {
+		return ""
+	}
+	return d.Rule.Action
+}
+
+// Lifts reports whether allow rules lift a built-in guard for a Bash call:
+// every command that guarded objects to must be decided by an allow rule.
+// An allow rule never lifts the guard for the other commands of the call,
+// and a call where guarded objects to no single command is not lifted.
+func (d *Decision) Lifts(guarded func(command string) bool) bool {
+	if d == nil {
+		return false
+	}
+	found := false
+	for _, cd := range d.Commands {
+		if !guarded(cd.Command) {
+			continue
+		}
+		if !cd.Allowed() {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// Evaluate matches every rule against the call. Relative path globs are
+// resolved against townRoot.
+func (rs *RuleSet) Evaluate(townRoot string, c *Call) *Decision {
+	d := &Decision{}
+	if rs == nil || c == nil {
+		return d
+	}
+
+	matched := make(map[*Rule]bool)
+	decide := func(command string) *Rule {
+		var last *Rule
+		for _, r := range rs.Rules {
+			if r.Disabled || !r.matches(townRoot, c, command) {
+				continue
+			}
+			matched[r] = true
+			last = r
+		}
+		return last
+	}
+
+	if c.Tool == "Bash" {
+		commands := SplitCommand(c.Command)
+		if len(commands) == 0 {
+			commands = []string{""} // rules without a pattern still apply
+		}
+		for _, command := range commands {
+			r := decide(command)
+			d.Commands = append(d.Commands, CommandDecision{Command: command, Rule: r})
+			if r != nil && (d.Rule == nil || severity(r.Action) > severity(d.Rule.Action)) {
+				d.Rule = r
+			}
+		}
+	} else {
+		d.Rule = decide("")
+	}
+
+	for _, r := range rs.Rules {
+		if matched[r] {
+			d.Matched = append(d.Matched, r)
+		}
+	}
+	return d
+}
+
+// severity orders actions from least to most restrictive.
+func severity(a Action) int {
+	switch {
+	case a.Blocks():
+		return 3
+	case a == ActionWarn:
+		return 2
+	case a == ActionAllow:
+		return 1
+	}
+	return 0
+}
+
+// matches reports whether the rule applies to the call. A Bash call is
+// matched one command at a time: pattern is matched against command, not
+// the whole command line.
+func (r *Rule) matches(townRoot string, c *Call, command string) bool {
+	if !matchTool(r.ToolName(), c.Tool) {
+		return false
+	}
+
+	target := c.Path
+	if c.Tool == "Bash" {
+		target = c.Cwd
+	}
+	if len(r.Paths) > 0 && !inScope(r.Paths, townRoot, target) {
+		return false
+	}
+
+	if r.Pattern == "" {
+		return true
+	}
+	if c.Tool == "Bash" {
+		return command != "" && globMatch(r.Pattern, command)
+	}
+	if c.Path == "" {
+		return false
+	}
+	return globMatch(r.Pattern, c.Path) || globMatch(r.Pattern, relToTown(townRoot, c.Path))
+}
+
+// matchTool matches a tool name against "|"-separated glob alternatives.
+func matchTool(pattern, tool string) bool {
+	for _, alt := range strings.Split(pattern, "|") {
+		if globMatch(strings.TrimSpace(alt), tool) {
+			return true
+		}
+	}
+	return false
+}
+
+// inScope reports whether p, or one of its parent directories, matches one
+// of the globs.
+func inScope(globs []string, townRoot, p string) bool {
+	if p == "" {
+		return false
+	}
+	if !filepath.IsAbs(p) && townRoot != "" {
+		p = filepath.Join(townRoot, p)
+	}
+	p = filepath.Clean(p)
+	for _, glob := range globs {
+		absolute := filepath.IsAbs(glob)
+		for dir := p; ; dir = filepath.Dir(dir) {
+			candidate := dir
+			if !absolute {
+				candidate = relToTown(townRoot, dir)
+			}
+			if candidate != "" && globMatch(filepath.Clean(glob), candidate) {
+				return true
+			}
+			if parent := filepath.Dir(dir); parent == dir {
+				break
+			}
+		}
+	}
+	return false
+}
+
+// relToTown returns p relative to the town root, or "" if p is outside it.
+func relToTown(townRoot, p string) string {
+	if townRoot == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(townRoot, p)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	return rel
+}
+
+// commandSeparators splits a shell command line into individual commands.
+var commandSeparators = regexp.MustCompile(`&&|\|\||[;|\n]`)
+
+// envAssignment matches a leading VAR=value prefix on a command.
+var envAssignment = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=\S*\s+`)
+
+// SplitCommand splits a shell command line on &&, ||, ;, | and newlines and
+// strips leading environment assignments and sudo, so that a pattern like
+// "terraform apply*" matches "cd infra && TF_LOG=debug terraform apply".
+func SplitCommand(command string) []string {
+	var segments []string
+	for _, seg := range commandSeparators.Split(command, -1) {
+		seg = strings.TrimSpace(seg)
+		for {
+			if loc := envAssignment.FindStringIndex(seg); loc != nil {
+				seg = seg[loc[1]:]
+				continue
+			}
+			if rest, ok := strings.CutPrefix(seg, "sudo "); ok {
+				seg = strings.TrimSpace(rest)
+				continue
+			}
+			break
+		}
+		if seg != "" {
+			segments = append(segments, seg)
+		}
+	}
+	return segments
+}
+
+// globMatch matches s against a glob in which * matches any run of
+// characters (including /) and ? matches exactly one.
+func globMatch(glob, s string) bool {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	return err == nil && re.MatchString(s)
+}
@@ -0,0 +1,125 @@
+package guard
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseHookInput(t *testing.T) {
+	c := ParseHookInput([]byte(`{"tool_name":"Bash","cwd":"/town/gastown/crew/max","tool_input":{"command":"npm publish"}}`))
+	if c == nil || c.Tool != "Bash" || c.Command != "npm publish" || c.Cwd != "/town/gastown/crew/max" {
+		t.Errorf("ParseHookInput(Bash) = %+v", c)
+	}
+	c = ParseHookInput([]byte(`{"tool_name":"Write","tool_input":{"file_path":"/town/gastown/.env","content":"x"}}`))
+	if c == nil || c.Tool != "Write" || c.Path != "/town/gastown/.env" {
+		t.Errorf("ParseHookInput(Write) = %+v", c)
+	}
+	for _, input := range []string{"", "not json", `{"tool_input":{"command":"ls"}}`} {
+		if c := ParseHookInput([]byte(input)); c != nil {
+			t.Errorf("ParseHookInput(%q) = %+v, want nil", input, c)
+		}
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"terraform apply", "terraform apply"},
+		{"cd infra && TF_LOG=debug terraform apply -auto-approve", "cd infra|terraform apply -auto-approve"},
+		{"make build; sudo npm publish || echo failed", "make build|npm publish|echo failed"},
+		{"kubectl get pods | grep api\nkubectl delete pod api-1", "kubectl get pods|grep api|kubectl delete pod api-1"},
+		{"  ", ""},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(SplitCommand(tt.command), "|"); got != tt.want {
+			t.Errorf("SplitCommand(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	town := "/town"
+	rs := &RuleSet{Rules: []*Rule{
+		{Name: "kubectl-delete", Pattern: "kubectl delete*", Action: ActionEscalate},
+		{Name: "tf-apply", Pattern: "terraform apply*", Action: ActionBlock, Paths: []string{"*/crew/*"}},
+		{Name: "npm-publish", Pattern: "npm publish*", Action: ActionWarn},
+		{Name: "kubectl-delete-pod", Pattern: "kubectl delete pod *", Action: ActionAllow},
+		{Name: "env-files", Tool: "Write|Edit", Pattern: "*.env", Action: ActionBlock},
+		{Name: "vendor", Tool: "*", Paths: []string{"gastown/vendor"}, Action: ActionWarn},
+	}}
+
+	tests := []struct {
+		name    string
+		call    Call
+		rule    string
+		matched int
+	}{
+		{"no match", Call{Tool: "Bash", Command: "go test ./...", Cwd: "/town/gastown/crew/max"}, "", 0},
+		{"escalate", Call{Tool: "Bash", Command: "kubectl delete deployment api", Cwd: "/town"}, "kubectl-delete", 1},
+		{"later allow wins", Call{Tool: "Bash", Command: "kubectl delete pod api-1", Cwd: "/town"}, "kubectl-delete-pod", 2},
+		{"in path scope", Call{Tool: "Bash", Command: "cd infra && terraform apply", Cwd: "/town/gastown/crew/max/infra"}, "tf-apply", 1},
+		{"out of path scope", Call{Tool: "Bash", Command: "terraform apply", Cwd: "/town/gastown/polecats/toast"}, "", 0},
+		{"wrong tool", Call{Tool: "Read", Path: "/town/gastown/.env"}, "", 0},
+		{"file pattern", Call{Tool: "Edit", Path: "/town/gastown/crew/max/.env"}, "env-files", 1},
+		{"paths only", Call{Tool: "Write", Path: "/town/gastown/vendor/lib/x.go"}, "vendor", 1},
+		{"bash cwd in paths", Call{Tool: "Bash", Command: "npm publish", Cwd: "/town/gastown/vendor"}, "vendor", 2},
+		{"allow is per command", Call{Tool: "Bash", Command: "kubectl delete pod api-1 && kubectl delete deployment api", Cwd: "/town"}, "kubectl-delete", 2},
+		{"most restrictive command wins", Call{Tool: "Bash", Command: "npm publish; kubectl delete deployment api", Cwd: "/town"}, "kubectl-delete", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := rs.Evaluate(town, &tt.call)
+			got := ""
+			if d.Rule != nil {
+				got = d.Rule.Name
+			}
+			if got != tt.rule || len(d.Matched) != tt.matched {
+				t.Errorf("Evaluate = %q (%d matched), want %q (%d matched)", got, len(d.Matched), tt.rule, tt.matched)
+			}
+		})
+	}
+}
+
+func TestDecisionLifts(t *testing.T) {
+	rs := &RuleSet{Rules: []*Rule{
+		{Name: "reset", Pattern: "git reset --hard*", Action: ActionAllow},
+	}}
+	guarded := func(command string) bool {
+		return strings.HasPrefix(command, "git reset --hard") || strings.HasPrefix(command, "rm -rf /")
+	}
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{"git reset --hard HEAD", true},
+		{"git status && git reset --hard", true},
+		{"git reset --hard && rm -rf /", false},
+		{"rm -rf / && git reset --hard", false},
+		{"go test ./...", false},
+	}
+	for _, tt := range tests {
+		d := rs.Evaluate("/town", &Call{Tool: "Bash", Command: tt.command, Cwd: "/town"})
+		if got := d.Lifts(guarded); got != tt.want {
+			t.Errorf("Lifts(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestInScope_AbsoluteGlob(t *testing.T) {
+	if !inScope([]string{"/srv/prod*"}, "/town", "/srv/production/app") {
+		t.Error("expected absolute glob to match parent directory")
+	}
+	if inScope([]string{"prod"}, "/town", "/srv/prod") {
+		t.Error("relative glob should not match outside the town")
+	}
+}
+
+func TestActionBlocks(t *testing.T) {
+	for a, want := range map[Action]bool{ActionBlock: true, ActionEscalate: true, ActionWarn: false, ActionAllow: false} {
+		if a.Blocks() != want {
+			t.Errorf("%s.Blocks() = %v, want %v", a, !want, want)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+// Package guard provides configurable guard rules for Gas Town's PreToolUse
+// hooks.
+//
+// Rules live in JSON files at town, rig and role level and are merged from
+// least to most specific, so a rig or role can add rules, replace a town rule
+// of the same name, or disable it:
+//
+//	<town>/settings/guard-rules.json
+//	<town>/settings/guard-rules/<role>.json
+//	<town>/<rig>/settings/guard-rules.json
+//	<town>/<rig>/settings/guard-rules/<role>.json
+//
+// 'gt tap guard rules' evaluates the merged rules for every tool call it is
+// wired to; 'gt tap guard test' shows which rule fires for a command.
+package guard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileType is the "type" recorded in guard rules files.
+const FileType = "guard-rules"
+
+// CurrentVersion is the current schema version for guard rules files.
+const CurrentVersion = 1
+
+// Action is what a guard does when a rule fires.
+type Action string
+
+const (
+	// ActionBlock blocks the tool call.
+	ActionBlock Action = "block"
+
+	// ActionWarn lets the tool call run and prints the rule's message.
+	ActionWarn Action = "warn"
+
+	// ActionEscalate blocks the tool call and tells the agent to escalate
+	// so a human can approve or run it.
+	ActionEscalate Action = "require-escalation"
+
+	// ActionAllow lets the tool call run. It is used to carve exceptions
+	// out of broader rules, including the built-in guards.
+	ActionAllow Action = "allow"
+)
+
+// Blocks reports whether the action stops the tool call.
+func (a Action) Blocks() bool {
+	return a == ActionBlock || a == ActionEscalate
+}
+
+func (a Action) valid() bool {
+	switch a {
+	case ActionBlock, ActionWarn, ActionEscalate, ActionAllow:
+		return true
+	}
+	return false
+}
+
+// Rule is a single guard rule.
+type Rule struct {
+	// Name identifies the rule. A more specific file replaces a rule with
+	// the same name.
+	Name string `json:"name"`
+
+	// Tool is the tool the rule applies to (e.g., Bash, Write). Alternatives
+	// are separated by "|" and may use * and ? wildcards. Defaults to Bash.
+	Tool string `json:"tool,omitempty"`
+
+	// Pattern is matched against each command in a Bash call (split on
+	// &&, ||, ;, | and newlines) or against the file path of other tools.
+	// * matches any run of characters and ? matches one. An empty pattern
+	// matches every call of the tool.
+	Pattern string `json:"pattern,omitempty"`
+
+	// Paths limits the rule to calls whose working directory (Bash) or file
+	// path (other tools) is at or below one of these globs. Relative globs
+	// are resolved against the town root. Empty means everywhere.
+	Paths []string `json:"paths,omitempty"`
+
+	// Action is block, warn, require-escalation or allow.
+	Action Action `json:"action,omitempty"`
+
+	// Message is shown to the agent when the rule fires.
+	Message string `json:"message,omitempty"`
+
+	// Disabled removes a rule of the same name from a less specific file.
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Source is the file the rule was loaded from.
+	Source string `json:"-"`
+}
+
+// ToolName returns the rule's tool, defaulting to Bash.
+func (r *Rule) ToolName() string {
+	if r.Tool == "" {
+		return "Bash"
+	}
+	return r.Tool
+}
+
+// RuleSet is the contents of a guard rules file, or the merge of several.
+type RuleSet struct {
+	Type    string  `json:"type,omitempty"`
+	Version int     `json:"version,omitempty"`
+	Rules   []*Rule `json:"rules"`
+}
+
+// Scope identifies whose rules apply: the town, and optionally a rig and a
+// role (crew, polecat, witness, refinery, mayor, deacon, dog, boot).
+type Scope struct {
+	TownRoot string
+	Rig      string
+	Role     string
+}
+
+// Files returns the rules files that apply to the scope, from least to most
+// specific. Files that do not exist are included; Load skips them.
+func (s Scope) Files() []string {
+	dirs := []string{filepath.Join(s.TownRoot, "settings")}
+	if s.Rig != "" {
+		dirs = append(dirs, filepath.Join(s.TownRoot, s.Rig, "settings"))
+	}
+	var files []string
+	for _, dir := range dirs {
+		files = append(files, filepath.Join(dir, "guard-rules.json"))
+		if s.Role != "" {
+			files = append(files, filepath.Join(dir, "guard-rules", s.Role+".json"))
+		}
+	}
+	return files
+}
+
+// Load loads and merges every rules file that applies to the scope. It
+// also returns the files that were found. A scope with no rules files
+// yields an empty rule set.
+func Load(s Scope) (*RuleSet, []string, error) {
+	merged := &RuleSet{Type: FileType, Version: CurrentVersion}
+	var found []string
+	for _, path := range s.Files() {
+		rs, err := LoadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, found, err
+		}
+		found = append(found, path)
+		merged = Merge(merged, rs)
+	}
+	return merged, found, nil
+}
+
+// LoadFile loads and validates a single rules file. Returns an error
+// wrapping os.ErrNotExist if the file does not exist.
+func LoadFile(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		return nil, err
+	}
+	var rs RuleSet
+	if err := json.Unmarshal(data, &rs); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if err := rs.Validate(); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for _, r := range rs.Rules {
+		r.Source = path
+	}
+	return &rs, nil
+}
+
+// Validate checks the file header and that every rule is well formed.
+func (rs *RuleSet) Validate() error {
+	if rs.Type != "" && rs.Type != FileType {
+		return fmt.Errorf("expected type %q, got %q", FileType, rs.Type)
+	}
+	if rs.Version > CurrentVersion {
+		return fmt.Errorf("unsupported version %d (max %d)", rs.Version, CurrentVersion)
+	}
+	seen := make(map[string]bool)
+	for i, r := range rs.Rules {
+		if r == nil || r.Name == "" {
+			return fmt.Errorf("rule %d: name is required", i+1)
+		}
+		if seen[r.Name] {
+			return fmt.Errorf("duplicate rule %q", r.Name)
+		}
+		seen[r.Name] = true
+		if r.Disabled {
+			continue
+		}
+		if !r.Action.valid() {
+			return fmt.Errorf("rule %q: action must be block, warn, require-escalation or allow, got %q", r.Name, r.Action)
+		}
+		if r.Pattern == "" && len(r.Paths) == 0 {
+			return fmt.Errorf("rule %q: needs a pattern or paths", r.Name)
+		}
+	}
+	return nil
+}
+
+// Merge merges an override rule set into a base rule set by rule name:
+//   - Same name: the override rule replaces the base rule in place
+//   - New name: the override rule is appended
+//   - Disabled override: the base rule of that name is removed
+//
+// Neither input is modified.
+func Merge(base, override *RuleSet) *RuleSet {
+	result := &RuleSet{Type: FileType, Version: CurrentVersion}
+	if base != nil {
+		result.Rules = append(result.Rules, base.Rules...)
+	}
+	if override == nil {
+		return result
+	}
+
+	index := make(map[string]int, len(result.Rules))
+	for i, r := range result.Rules {
+		index[r.Name] = i
+	}
+	removed := make(map[string]bool)
+	for _, r := range override.Rules {
+		i, exists := index[r.Name]
+		switch {
+		case r.Disabled:
+			if exists {
+				removed[r.Name] = true
+			}
+		case exists:
+			result.Rules[i] = r
+			delete(removed, r.Name)
+		default:
+			index[r.Name] = len(result.Rules)
+			result.Rules = append(result.Rules, r)
+		}
+	}
+	if len(removed) > 0 {
+		kept := result.Rules[:0:0]
+		for _, r := range result.Rules {
+			if !removed[r.Name] {
+				kept = append(kept, r)
+			}
+		}
+		result.Rules = kept
+	}
+	return result
+}
@@ -0,0 +1,137 @@
+package guard
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeRules(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func ruleNames(rs *RuleSet) string {
+	var names []string
+	for _, r := range rs.Rules {
+		names = append(names, r.Name+":"+string(r.Action))
+	}
+	return strings.Join(names, ",")
+}
+
+func TestScopeFiles(t *testing.T) {
+	got := Scope{TownRoot: "/town", Rig: "gastown", Role: "crew"}.Files()
+	want := []string{
+		"/town/settings/guard-rules.json",
+		"/town/settings/guard-rules/crew.json",
+		"/town/gastown/settings/guard-rules.json",
+		"/town/gastown/settings/guard-rules/crew.json",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Files() = %v, want %v", got, want)
+	}
+
+	got = Scope{TownRoot: "/town", Role: "mayor"}.Files()
+	if len(got) != 2 || got[1] != "/town/settings/guard-rules/mayor.json" {
+		t.Errorf("Files() for mayor = %v", got)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := &RuleSet{Rules: []*Rule{
+		{Name: "tf-apply", Pattern: "terraform apply*", Action: ActionBlock},
+		{Name: "npm-publish", Pattern: "npm publish*", Action: ActionBlock},
+		{Name: "kubectl-delete", Pattern: "kubectl delete*", Action: ActionEscalate},
+	}}
+	override := &RuleSet{Rules: []*Rule{
+		{Name: "npm-publish", Pattern: "npm publish*", Action: ActionWarn},
+		{Name: "kubectl-delete", Disabled: true},
+		{Name: "helm", Pattern: "helm uninstall*", Action: ActionBlock},
+	}}
+
+	got := Merge(base, override)
+	if names := ruleNames(got); names != "tf-apply:block,npm-publish:warn,helm:block" {
+		t.Errorf("Merge = %s", names)
+	}
+	if len(base.Rules) != 3 || base.Rules[1].Action != ActionBlock {
+		t.Error("Merge modified its base")
+	}
+}
+
+func TestLoad_MergesTownRigAndRole(t *testing.T) {
+	town := t.TempDir()
+	writeRules(t, filepath.Join(town, "settings", "guard-rules.json"), `{
+  "type": "guard-rules",
+  "version": 1,
+  "rules": [
+    {"name": "tf-apply", "pattern": "terraform apply*", "action": "block", "message": "Infra changes go through CI"},
+    {"name": "npm-publish", "pattern": "npm publish*", "action": "block"}
+  ]
+}`)
+	writeRules(t, filepath.Join(town, "gastown", "settings", "guard-rules.json"), `{
+  "rules": [{"name": "npm-publish", "pattern": "npm publish*", "action": "require-escalation"}]
+}`)
+	writeRules(t, filepath.Join(town, "gastown", "settings", "guard-rules", "crew.json"), `{
+  "rules": [{"name": "tf-apply", "disabled": true}]
+}`)
+
+	rs, found, err := Load(Scope{TownRoot: town, Rig: "gastown", Role: "crew"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 3 {
+		t.Errorf("found = %v, want 3 files", found)
+	}
+	if names := ruleNames(rs); names != "npm-publish:require-escalation" {
+		t.Errorf("crew rules = %s", names)
+	}
+	if !strings.HasSuffix(rs.Rules[0].Source, filepath.Join("gastown", "settings", "guard-rules.json")) {
+		t.Errorf("source = %q", rs.Rules[0].Source)
+	}
+
+	// Polecats in the same rig keep the town's terraform rule.
+	rs, _, err = Load(Scope{TownRoot: town, Rig: "gastown", Role: "polecat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := ruleNames(rs); names != "tf-apply:block,npm-publish:require-escalation" {
+		t.Errorf("polecat rules = %s", names)
+	}
+}
+
+func TestLoad_NoFiles(t *testing.T) {
+	rs, found, err := Load(Scope{TownRoot: t.TempDir(), Rig: "gastown", Role: "crew"})
+	if err != nil || len(found) != 0 || len(rs.Rules) != 0 {
+		t.Errorf("Load with no files = %+v, %v, %v", rs, found, err)
+	}
+}
+
+func TestLoadFile_Invalid(t *testing.T) {
+	tests := []struct {
+		name, content, want string
+	}{
+		{"bad json", `{"rules": [`, "parsing"},
+		{"wrong type", `{"type": "escalation", "rules": []}`, "expected type"},
+		{"future version", `{"version": 99, "rules": []}`, "unsupported version"},
+		{"missing name", `{"rules": [{"pattern": "x", "action": "block"}]}`, "name is required"},
+		{"duplicate", `{"rules": [{"name": "a", "pattern": "x", "action": "block"}, {"name": "a", "pattern": "y", "action": "warn"}]}`, "duplicate rule"},
+		{"bad action", `{"rules": [{"name": "a", "pattern": "x", "action": "deny"}]}`, "action must be"},
+		{"no pattern", `{"rules": [{"name": "a", "action": "block"}]}`, "needs a pattern or paths"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "guard-rules.json")
+			writeRules(t, path, tt.content)
+			_, err := LoadFile(path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadFile error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
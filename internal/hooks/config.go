@@ -856,6 +856,22 @@ func DefaultBase() *HooksConfig {
 					Command: fmt.Sprintf("%s && gt tap guard dangerous-command", pathSetup),
 				}},
 			},
+			{
+				// Configured guard rules (settings/guard-rules.json). Exits
+				// immediately when no rules files exist.
+				Matcher: "Bash",
+				Hooks: []Hook{{
+					Type:    "command",
+					Command: fmt.Sprintf("%s && gt tap guard rules", pathSetup),
+				}},
+			},
+			{
+				Matcher: "Edit|MultiEdit|Write",
+				Hooks: []Hook{{
+					Type:    "command",
+					Command: fmt.Sprintf("%s && gt tap guard rules", pathSetup),
+				}},
+			},
 		},
 		SessionStart: []HookEntry{
 			{
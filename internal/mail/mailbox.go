@@ -108,8 +108,30 @@ func (m *Mailbox) lockLegacy() (*flock.Flock, error) {
 	return fl, nil
 }
 
-// List returns all open messages in the mailbox.
+// List returns all open messages in the mailbox. Scheduled and snoozed
+// messages are hidden until they are due (see ListScheduled).
 func (m *Mailbox) List() ([]*Message, error) {
+	all, err := m.listAll()
+	if err != nil {
+		return nil, err
+	}
+	due, _ := splitDue(all, time.Now())
+	return due, nil
+}
+
+// ListScheduled returns open messages that are scheduled or snoozed and not
+// yet due, soonest first.
+func (m *Mailbox) ListScheduled() ([]*Message, error) {
+	all, err := m.listAll()
+	if err != nil {
+		return nil, err
+	}
+	_, pending := splitDue(all, time.Now())
+	return pending, nil
+}
+
+// listAll returns every open message, including ones not yet due.
+func (m *Mailbox) listAll() ([]*Message, error) {
 	if m.legacy {
 		return m.listLegacy()
 	}
@@ -513,7 +535,7 @@ func (m *Mailbox) getFromDir(id, beadsDir string) (*Message, error) {
 }
 
 func (m *Mailbox) getLegacy(id string) (*Message, error) {
-	messages, err := m.List()
+	messages, err := m.listLegacy()
 	if err != nil {
 		return nil, err
 	}
@@ -582,7 +604,7 @@ func (m *Mailbox) markReadLegacy(id string) error {
 	}
 	defer func() { _ = fl.Unlock() }()
 
-	messages, err := m.List()
+	messages, err := m.listLegacy()
 	if err != nil {
 		return err
 	}
@@ -749,7 +771,7 @@ func (m *Mailbox) markUnreadLegacy(id string) error {
 	}
 	defer func() { _ = fl.Unlock() }()
 
-	messages, err := m.List()
+	messages, err := m.listLegacy()
 	if err != nil {
 		return err
 	}
@@ -784,7 +806,7 @@ func (m *Mailbox) deleteLegacy(id string) error {
 	}
 	defer func() { _ = fl.Unlock() }()
 
-	messages, err := m.List()
+	messages, err := m.listLegacy()
 	if err != nil {
 		return err
 	}
@@ -251,6 +251,7 @@ func (r *Router) buildLabels(msg *Message) []string {
 		ccIdentity := AddressToIdentity(cc)
 		labels = append(labels, "cc:"+ccIdentity)
 	}
+	labels = append(labels, scheduleLabels(msg)...)
 	return labels
 }
 
@@ -826,6 +827,10 @@ func (r *Router) queryAgentsFromDir(beadsDir string) ([]*agentBead, error) {
 // - Message.Wisp is explicitly set
 // - Subject matches lifecycle message patterns (POLECAT_*, NUDGE, etc.)
 func (r *Router) shouldBeWisp(msg *Message) bool {
+	// Scheduled mail must outlive wisp cleanup until it is delivered.
+	if isScheduled(msg) {
+		return false
+	}
 	if msg.Wisp {
 		return true
 	}
@@ -859,6 +864,11 @@ func (r *Router) shouldBeWisp(msg *Message) bool {
 // - Queues (queue:name) - stores single message for worker claiming
 // - Announces (announce:name) - bulletin board, no claiming, retention-limited
 func (r *Router) Send(msg *Message) error {
+	// Scheduled delivery is per-recipient; shared mailboxes have no recipient to hold it for.
+	if isScheduled(msg) && (isQueueAddress(msg.To) || isAnnounceAddress(msg.To) || isChannelAddress(msg.To)) {
+		return fmt.Errorf("scheduled delivery is not supported for %s", msg.To)
+	}
+
 	// Check for mailing list address
 	if isListAddress(msg.To) {
 		return r.sendToList(msg)
@@ -1123,6 +1133,21 @@ func (r *Router) sendToSingle(msg *Message) error {
 		return fmt.Errorf("invalid recipient %q: %w", msg.To, err)
 	}
 
+	// Apply the recipient's mail rules (may re-prioritize msg). Scheduled
+	// mail is filtered by DeliverDue when it matures, not now.
+	var rules *RuleOutcome
+	if !msg.SkipRules && !isScheduled(msg) {
+		rules = r.applyMailRules(toIdentity, msg)
+	}
+	if rules != nil && rules.Nudge {
+		// Converted to a nudge: nothing is stored for the recipient.
+		if err := r.nudgeByRule(msg); err != nil {
+			return err
+		}
+		r.forwardByRulesBestEffort(msg, rules.Forward)
+		return nil
+	}
+
 	// Build labels for type, from/thread/reply-to/cc
 	labels := r.buildLabels(msg)
 
@@ -1157,6 +1182,12 @@ func (r *Router) sendToSingle(msg *Message) error {
 		args = append(args, "--ephemeral")
 	}
 
+	// Archive and hook rules act on the created bead, so ask bd for its ID.
+	needID := rules != nil && (rules.Archive || rules.Hook)
+	if needID {
+		args = append(args, "--json")
+	}
+
 	// End flag parsing with --, then add subject as positional argument.
 	// This prevents subjects like "--help" or "--json" from being parsed as flags.
 	args = append(args, "--", msg.Subject)
@@ -1167,7 +1198,7 @@ func (r *Router) sendToSingle(msg *Message) error {
 	}
 	ctx, cancel := bdWriteCtx()
 	defer cancel()
-	_, err := runBdCommand(ctx, args, filepath.Dir(beadsDir), beadsDir)
+	out, err := runBdCommand(ctx, args, filepath.Dir(beadsDir), beadsDir)
 	telemetry.RecordMailMessage(context.Background(), "send", telemetry.MailMessageInfo{
 		ID:       msg.ID,
 		From:     msg.From,
@@ -1182,13 +1213,42 @@ func (r *Router) sendToSingle(msg *Message) error {
 		return fmt.Errorf("sending message: %w", err)
 	}
 
+	// The message is stored now. Returning an error from here on would make
+	// the caller retry and deliver it twice, so a failed archive or hook
+	// removes the bead first, and anything that cannot be undone is reported
+	// as a warning.
+	filed := false
+	if rules != nil {
+		if needID {
+			var created struct {
+				ID string `json:"id"`
+			}
+			if err := json.Unmarshal(out, &created); err != nil || created.ID == "" {
+				fmt.Fprintf(os.Stderr, "Warning: mail rules not applied: could not parse created message ID: %v\n", err)
+			} else if err := r.finishRuleDelivery(ctx, created.ID, toIdentity, msg, rules); err != nil {
+				if delErr := r.deleteMessageBead(created.ID); delErr != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %v (message %s delivered unfiltered; cleanup failed: %v)\n", err, created.ID, delErr)
+				} else {
+					return err
+				}
+			} else {
+				filed = rules.Archive
+			}
+		}
+		r.forwardByRulesBestEffort(msg, rules.Forward)
+		if filed {
+			return nil // Filed away: don't notify
+		}
+	}
+
 	// Notify recipient if they have an active session (best-effort notification).
 	// Skip when the caller explicitly suppressed notification (--no-notify)
 	// or for self-mail (handoffs to future-self don't need present-self notified).
 	// Notification is async: the durable write is complete, so the caller
 	// doesn't block on idle probing (up to 1s per recipient in fan-out).
 	// Callers that exit soon after Send should call WaitPendingNotifications.
-	if !msg.SuppressNotify && !isSelfMail(msg.From, msg.To) {
+	// Scheduled mail is announced by DeliverDue when it matures.
+	if !msg.SuppressNotify && !isSelfMail(msg.From, msg.To) && !isScheduled(msg) {
 		msgCopy := *msg // copy to avoid data race if caller mutates msg
 		r.notifyWg.Add(1)
 		go func() {
@@ -0,0 +1,324 @@
+package mail
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/nudge"
+)
+
+// RuleOutcome is the combined effect of the mail rules that matched a message.
+type RuleOutcome struct {
+	// Matched names the rules that matched, in evaluation order.
+	Matched []string `json:"matched"`
+
+	// Archive delivers the message straight to the archive.
+	Archive bool `json:"archive,omitempty"`
+
+	// Hook attaches the message to the recipient's hook if it is empty.
+	Hook bool `json:"hook,omitempty"`
+
+	// Nudge replaces the mail with a nudge; no message is stored.
+	Nudge bool `json:"nudge,omitempty"`
+
+	// Forward lists addresses that also receive a copy.
+	Forward []string `json:"forward,omitempty"`
+
+	// Priority is the new priority, or empty when unchanged.
+	Priority Priority `json:"priority,omitempty"`
+}
+
+// EvaluateRules applies rules to a message addressed to recipient and returns
+// what they decided. labels are the message's routing labels (see
+// buildLabels). A priority action re-prioritizes the message for the rules
+// after it; evaluation ends at the first matching rule with Stop set.
+func EvaluateRules(rules []config.MailRule, recipient string, msg *Message, labels []string) *RuleOutcome {
+	out := &RuleOutcome{}
+	priority := msg.Priority
+	for i, rule := range rules {
+		if rule.Identity != "" && !matchRuleAddress(rule.Identity, recipient) {
+			continue
+		}
+		if !ruleMatches(rule.Match, msg, priority, labels) {
+			continue
+		}
+
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		out.Matched = append(out.Matched, name)
+
+		switch rule.Action {
+		case config.MailRuleArchive:
+			out.Archive = true
+		case config.MailRuleHook:
+			out.Hook = true
+		case config.MailRuleNudge:
+			out.Nudge = true
+		case config.MailRuleForward:
+			out.Forward = append(out.Forward, rule.Target)
+		case config.MailRulePriority:
+			priority = ParsePriority(rule.Priority)
+			out.Priority = priority
+		}
+		if rule.Stop {
+			break
+		}
+	}
+	return out
+}
+
+// matchRuleAddress matches an address against a rule pattern. Both sides are
+// compared in canonical form, so "gastown/polecats/Toast" and "gastown/Toast"
+// are the same recipient.
+func matchRuleAddress(pattern, address string) bool {
+	return matchPattern(AddressToIdentity(pattern), AddressToIdentity(address))
+}
+
+func ruleMatches(m config.MailRuleMatch, msg *Message, priority Priority, labels []string) bool {
+	if m.From != "" && !matchRuleAddress(m.From, msg.From) {
+		return false
+	}
+	if m.Type != "" && m.Type != string(msg.Type) {
+		return false
+	}
+	if m.Priority != "" && m.Priority != string(priority) {
+		return false
+	}
+	if m.Subject != "" {
+		re, err := regexp.Compile("(?i)" + m.Subject)
+		if err != nil || !re.MatchString(msg.Subject) {
+			return false
+		}
+	}
+	for _, want := range m.Labels {
+		found := false
+		for _, l := range labels {
+			if l == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// loadMailRules returns the town's mail rules. A missing messaging config
+// means no rules.
+func (r *Router) loadMailRules() ([]config.MailRule, error) {
+	if r.townRoot == "" {
+		return nil, nil
+	}
+	cfg, err := config.LoadMessagingConfig(config.MessagingConfigPath(r.townRoot))
+	if errors.Is(err, config.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading messaging config: %w", err)
+	}
+	return cfg.Rules, nil
+}
+
+// EvaluateRules reports what the town's mail rules would do with msg if it
+// were delivered to msg.To. Nothing is changed.
+func (r *Router) EvaluateRules(msg *Message) (*RuleOutcome, error) {
+	rules, err := r.loadMailRules()
+	if err != nil {
+		return nil, err
+	}
+	recipient := r.resolveCrewShorthand(AddressToIdentity(msg.To))
+	return EvaluateRules(rules, recipient, msg, r.buildLabels(msg)), nil
+}
+
+// applyMailRules evaluates the recipient's rules at routing time and applies
+// any re-prioritization to msg. A broken messaging config never blocks
+// delivery: the message is delivered unfiltered.
+func (r *Router) applyMailRules(recipient string, msg *Message) *RuleOutcome {
+	rules, err := r.loadMailRules()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: mail rules not applied: %v\n", err)
+		return nil
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	out := EvaluateRules(rules, recipient, msg, r.buildLabels(msg))
+	if len(out.Matched) == 0 {
+		return nil
+	}
+	if out.Priority != "" {
+		msg.Priority = out.Priority
+	}
+	return out
+}
+
+// applyDeliveryRules applies the recipient's mail rules to a scheduled
+// message as it matures, the way sendToSingle does for mail sent for
+// immediate delivery. Failures are warnings: the message stays in the inbox.
+// Reports whether the recipient should still be notified.
+func (r *Router) applyDeliveryRules(msg *Message) bool {
+	identity := r.resolveCrewShorthand(AddressToIdentity(msg.To))
+	original := msg.Priority
+	rules := r.applyMailRules(identity, msg)
+	if rules == nil {
+		return true
+	}
+
+	ctx, cancel := bdWriteCtx()
+	defer cancel()
+	beadsDir := r.resolveBeadsDir()
+	if msg.Priority != original {
+		args := []string{"update", msg.ID, "--priority", fmt.Sprintf("%d", PriorityToBeads(msg.Priority))}
+		if _, err := runBdCommand(ctx, args, filepath.Dir(beadsDir), beadsDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: mail rules: re-prioritizing %s: %v\n", msg.ID, err)
+		}
+	}
+	r.forwardByRulesBestEffort(msg, rules.Forward)
+
+	if rules.Nudge {
+		if err := r.nudgeByRule(msg); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			return true
+		}
+		if err := r.deleteMessageBead(msg.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: mail rules: %v\n", err)
+		}
+		return false
+	}
+	if err := r.finishRuleDelivery(ctx, msg.ID, identity, msg, rules); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return true
+	}
+	return !rules.Archive
+}
+
+// forwardByRules sends a copy of msg to each forward target. Copies skip
+// rules so two forwarding rules cannot bounce a message back and forth.
+func (r *Router) forwardByRules(msg *Message, targets []string) error {
+	var errs []string
+	for _, target := range targets {
+		fwd := *msg
+		fwd.ID = ""
+		fwd.To = target
+		fwd.SkipRules = true
+		if err := r.Send(&fwd); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", target, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("forwarding by mail rule: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// forwardByRulesBestEffort forwards msg after the original has been
+// delivered. Failures are warnings: the original must not be re-sent.
+func (r *Router) forwardByRulesBestEffort(msg *Message, targets []string) {
+	if err := r.forwardByRules(msg, targets); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// deleteMessageBead removes a message bead whose rule actions failed, so the
+// send can be retried without leaving a duplicate behind.
+func (r *Router) deleteMessageBead(beadID string) error {
+	beadsDir := r.resolveBeadsDir()
+	ctx, cancel := bdWriteCtx()
+	defer cancel()
+	if _, err := runBdCommand(ctx, []string{"delete", beadID, "--hard", "--force"}, filepath.Dir(beadsDir), beadsDir); err != nil {
+		return fmt.Errorf("deleting %s: %w", beadID, err)
+	}
+	return nil
+}
+
+// nudgeByRule delivers msg as a queued nudge instead of mail.
+func (r *Router) nudgeByRule(msg *Message) error {
+	sessionIDs := AddressToSessionIDs(msg.To)
+	if r.townRoot == "" || len(sessionIDs) == 0 {
+		return fmt.Errorf("cannot nudge %s: no session for address", msg.To)
+	}
+	text := fmt.Sprintf("📨 %s (from %s)", msg.Subject, msg.From)
+	if msg.Body != "" {
+		text += ": " + msg.Body
+	}
+	return nudge.Enqueue(r.townRoot, sessionIDs[0], nudge.QueuedNudge{
+		Sender:   msg.From,
+		Message:  text,
+		Priority: nudgePriorityForMailPriority(msg.Priority),
+		Kind:     nudgeKindForMessage(msg),
+		ThreadID: msg.ThreadID,
+		Severity: prioritySeverityLabel(msg.Priority),
+	})
+}
+
+// hookByRule attaches a delivered message bead to the recipient's hook,
+// unless something is already hooked there.
+func (r *Router) hookByRule(ctx context.Context, beadID, identity string) error {
+	agentID := r.hookAgentID(identity)
+	beadsDir := r.resolveBeadsDir()
+	workDir := filepath.Dir(beadsDir)
+
+	out, err := runBdCommand(ctx, []string{"list", "--status=hooked", "--assignee=" + agentID, "--json", "--limit=1"}, workDir, beadsDir)
+	if err != nil {
+		return fmt.Errorf("checking hook: %w", err)
+	}
+	var hooked []struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(out, &hooked); err == nil && len(hooked) > 0 {
+		return nil // Never displace existing work
+	}
+
+	if _, err := runBdCommand(ctx, []string{"update", beadID, "--status=hooked", "--assignee=" + agentID}, workDir, beadsDir); err != nil {
+		return fmt.Errorf("hooking %s: %w", beadID, err)
+	}
+	return nil
+}
+
+// hookAgentID expands a canonical "rig/name" mail identity to the agent ID
+// hooks are assigned to ("rig/crew/name" or "rig/polecats/name").
+func (r *Router) hookAgentID(identity string) string {
+	parts := strings.Split(identity, "/")
+	if r.townRoot == "" || len(parts) != 2 || parts[1] == "" {
+		return identity
+	}
+	for _, roleDir := range []string{constants.RoleCrew, "polecats"} {
+		dir := filepath.Join(r.townRoot, parts[0], roleDir, parts[1])
+		if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
+			return parts[0] + "/" + roleDir + "/" + parts[1]
+		}
+	}
+	return identity
+}
+
+// finishRuleDelivery applies archive and hook rules to a just-created
+// message bead.
+func (r *Router) finishRuleDelivery(ctx context.Context, beadID, identity string, msg *Message, rules *RuleOutcome) error {
+	if rules.Hook {
+		if err := r.hookByRule(ctx, beadID, identity); err != nil {
+			return fmt.Errorf("mail rules: %w", err)
+		}
+	}
+	if rules.Archive {
+		mailbox, err := r.GetMailbox(msg.To)
+		if err != nil {
+			return fmt.Errorf("mail rules: %w", err)
+		}
+		if err := mailbox.Archive(beadID); err != nil {
+			return fmt.Errorf("mail rules: archiving %s: %w", beadID, err)
+		}
+	}
+	return nil
+}
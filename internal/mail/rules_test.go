@@ -0,0 +1,178 @@
+package mail
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+)
+
+func TestEvaluateRules(t *testing.T) {
+	rules := []config.MailRule{
+		{
+			Name:     "patrol-noise",
+			Identity: "mayor/",
+			Match:    config.MailRuleMatch{From: "*/witness", Subject: "^patrol"},
+			Action:   config.MailRuleArchive,
+		},
+		{
+			Name:     "bump-tasks",
+			Identity: "gastown/polecats/*",
+			Match:    config.MailRuleMatch{Type: "task"},
+			Action:   config.MailRulePriority,
+			Priority: "high",
+		},
+		{
+			Name:   "hook-high-tasks",
+			Match:  config.MailRuleMatch{Type: "task", Priority: "high"},
+			Action: config.MailRuleHook,
+			Stop:   true,
+		},
+		{
+			Name:   "page-oncall",
+			Match:  config.MailRuleMatch{Labels: []string{"gt:escalation"}},
+			Action: config.MailRuleForward,
+			Target: "list:oncall",
+		},
+	}
+
+	tests := []struct {
+		name      string
+		recipient string
+		msg       *Message
+		labels    []string
+		want      *RuleOutcome
+	}{
+		{
+			name:      "no match",
+			recipient: "mayor/",
+			msg:       &Message{From: "gastown/witness", Subject: "Help", Priority: PriorityNormal, Type: TypeNotification},
+			want:      &RuleOutcome{},
+		},
+		{
+			name:      "subject is case-insensitive",
+			recipient: "mayor/",
+			msg:       &Message{From: "gastown/witness", Subject: "PATROL complete", Priority: PriorityNormal, Type: TypeNotification},
+			want:      &RuleOutcome{Matched: []string{"patrol-noise"}, Archive: true},
+		},
+		{
+			name:      "identity scopes the rule",
+			recipient: "deacon/",
+			msg:       &Message{From: "gastown/witness", Subject: "patrol complete", Priority: PriorityNormal, Type: TypeNotification},
+			want:      &RuleOutcome{},
+		},
+		{
+			name:      "re-prioritized message matches later rules and stop ends evaluation",
+			recipient: "gastown/Toast",
+			msg:       &Message{From: "mayor/", Subject: "Fix it", Priority: PriorityNormal, Type: TypeTask},
+			labels:    []string{"gt:message", "gt:escalation"},
+			want:      &RuleOutcome{Matched: []string{"bump-tasks", "hook-high-tasks"}, Hook: true, Priority: PriorityHigh},
+		},
+		{
+			name:      "labels",
+			recipient: "deacon/",
+			msg:       &Message{From: "gastown/witness", Subject: "Stuck", Priority: PriorityUrgent, Type: TypeEscalation},
+			labels:    []string{"gt:message", "gt:escalation"},
+			want:      &RuleOutcome{Matched: []string{"page-oncall"}, Forward: []string{"list:oncall"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EvaluateRules(rules, tt.recipient, tt.msg, tt.labels)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EvaluateRules() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateRules_UnnamedRules(t *testing.T) {
+	rules := []config.MailRule{
+		{Match: config.MailRuleMatch{From: "mayor/"}, Action: config.MailRuleNudge},
+	}
+	got := EvaluateRules(rules, "gastown/Toast", &Message{From: "mayor", Subject: "ping"}, nil)
+	if !got.Nudge || len(got.Matched) != 1 || got.Matched[0] != "#1" {
+		t.Errorf("EvaluateRules() = %+v, want nudge by rule #1", got)
+	}
+}
+
+// setupRulesTown creates a town whose messaging config holds rules and puts
+// a bd stub running script on PATH. Every bd invocation is appended to the
+// returned log, one line of arguments per call.
+func setupRulesTown(t *testing.T, rules []config.MailRule, script string) (townRoot, logPath string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses a bash bd stub")
+	}
+
+	tmpDir := t.TempDir()
+	townRoot = filepath.Join(tmpDir, "town")
+	townBeadsDir := filepath.Join(townRoot, ".beads")
+	for _, dir := range []string{filepath.Join(townRoot, "mayor"), townBeadsDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(townRoot, "mayor", "town.json"), []byte(`{"name":"test"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	typesList := strings.Join(constants.BeadsCustomTypesList(), ",")
+	if err := os.WriteFile(filepath.Join(townBeadsDir, ".gt-types-configured"), []byte(typesList+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.NewMessagingConfig()
+	cfg.Rules = rules
+	if err := config.SaveMessagingConfig(config.MessagingConfigPath(townRoot), cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	binDir := filepath.Join(tmpDir, "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	logPath = filepath.Join(tmpDir, "bd.log")
+	stub := "#!/usr/bin/env bash\necho \"$*\" >> \"" + logPath + "\"\n" + script
+	if err := os.WriteFile(filepath.Join(binDir, "bd"), []byte(stub), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return townRoot, logPath
+}
+
+func readBdLog(t *testing.T, logPath string) string {
+	t.Helper()
+	data, err := os.ReadFile(logPath)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// A rule action that fails after the message bead exists must remove the
+// bead before reporting the error, so a retried send does not duplicate it.
+func TestSend_FailedRuleActionDeletesBead(t *testing.T) {
+	townRoot, logPath := setupRulesTown(t,
+		[]config.MailRule{{Name: "hook-all", Identity: "mayor/", Action: config.MailRuleHook}}, `
+case "$1" in
+  create) echo '{"id":"hq-rule-1"}' ;;
+  list) echo "[]" ;;
+  update) echo "update failed" >&2; exit 1 ;;
+esac
+`)
+
+	r := NewRouterWithTownRoot(townRoot, townRoot)
+	msg := &Message{From: "gastown/witness", To: "mayor/", Subject: "Work", Body: "x", SuppressNotify: true}
+	if err := r.Send(msg); err == nil {
+		t.Fatal("Send succeeded, want the hook failure reported")
+	}
+	if log := readBdLog(t, logPath); !strings.Contains(log, "delete hq-rule-1 --hard --force") {
+		t.Errorf("created bead was not deleted; bd calls:\n%s", log)
+	}
+}
@@ -0,0 +1,340 @@
+package mail
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// Scheduling labels. gt:scheduled marks every message that carries a
+// deliver-at or snoozed-until label, so due messages can be found with a
+// single label query.
+const (
+	labelScheduled    = "gt:scheduled"
+	labelDeliverAt    = "deliver-at:"
+	labelSnoozedUntil = "snoozed-until:"
+)
+
+// HiddenUntil returns when a scheduled or snoozed message becomes visible,
+// or the zero time if it is not held back.
+func (m *Message) HiddenUntil() time.Time {
+	var until time.Time
+	if m.DeliverAt != nil && m.DeliverAt.After(until) {
+		until = *m.DeliverAt
+	}
+	if m.SnoozedUntil != nil && m.SnoozedUntil.After(until) {
+		until = *m.SnoozedUntil
+	}
+	return until
+}
+
+// IsDue reports whether the message should be visible at now.
+func (m *Message) IsDue(now time.Time) bool {
+	return !now.Before(m.HiddenUntil())
+}
+
+// splitDue partitions messages into those visible at now and those still
+// held back. The held-back messages are sorted soonest first.
+func splitDue(messages []*Message, now time.Time) (due, pending []*Message) {
+	due = make([]*Message, 0, len(messages))
+	for _, msg := range messages {
+		if msg.IsDue(now) {
+			due = append(due, msg)
+		} else {
+			pending = append(pending, msg)
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].HiddenUntil().Before(pending[j].HiddenUntil())
+	})
+	return due, pending
+}
+
+func formatScheduleLabel(prefix string, t time.Time) string {
+	return prefix + t.UTC().Format(time.RFC3339)
+}
+
+func parseScheduleLabel(label, prefix string) *time.Time {
+	t, err := time.Parse(time.RFC3339, strings.TrimPrefix(label, prefix))
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// scheduleLabels returns the labels that hold a message back until
+// msg.DeliverAt, or nil when it should be delivered now.
+func scheduleLabels(msg *Message) []string {
+	if msg.DeliverAt == nil || !msg.DeliverAt.After(time.Now()) {
+		return nil
+	}
+	return []string{labelScheduled, formatScheduleLabel(labelDeliverAt, *msg.DeliverAt)}
+}
+
+// isScheduled reports whether msg is held back for later delivery.
+func isScheduled(msg *Message) bool {
+	return len(scheduleLabels(msg)) > 0
+}
+
+// Snooze hides a message until the given time. The message is marked unread
+// so it resurfaces as new mail when it comes back.
+func (m *Mailbox) Snooze(id string, until time.Time) error {
+	if m.legacy {
+		return m.snoozeLegacy(id, until)
+	}
+
+	msg, err := m.Get(id)
+	if err != nil {
+		return err
+	}
+	if msg.SnoozedUntil != nil {
+		_ = m.removeLabel(id, formatScheduleLabel(labelSnoozedUntil, *msg.SnoozedUntil))
+	}
+	for _, label := range []string{labelScheduled, formatScheduleLabel(labelSnoozedUntil, until)} {
+		if err := m.addLabel(id, label); err != nil {
+			return fmt.Errorf("snoozing %s: %w", id, err)
+		}
+	}
+	return m.MarkUnreadOnly(id)
+}
+
+func (m *Mailbox) snoozeLegacy(id string, until time.Time) error {
+	fl, err := m.lockLegacy()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = fl.Unlock() }()
+
+	messages, err := m.listLegacy()
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, msg := range messages {
+		if msg.ID == id {
+			t := until.UTC()
+			msg.SnoozedUntil = &t
+			msg.Read = false
+			found = true
+		}
+	}
+	if !found {
+		return ErrMessageNotFound
+	}
+	return m.rewriteLegacy(messages)
+}
+
+func (m *Mailbox) addLabel(id, label string) error {
+	if m.store != nil {
+		ctx, cancel := mailStoreCtx()
+		defer cancel()
+		return m.store.AddLabel(ctx, id, label, "")
+	}
+	ctx, cancel := bdWriteCtx()
+	defer cancel()
+	_, err := runBdCommand(ctx, []string{"label", "add", id, label}, m.workDir, beads.ResolveBeadsDirForID(m.beadsDir, id))
+	return err
+}
+
+func (m *Mailbox) removeLabel(id, label string) error {
+	if m.store != nil {
+		ctx, cancel := mailStoreCtx()
+		defer cancel()
+		return m.store.RemoveLabel(ctx, id, label, "")
+	}
+	ctx, cancel := bdWriteCtx()
+	defer cancel()
+	_, err := runBdCommand(ctx, []string{"label", "remove", id, label}, m.workDir, beads.ResolveBeadsDirForID(m.beadsDir, id))
+	return err
+}
+
+// listScheduledBeads returns open messages carrying gt:scheduled in the
+// mailbox's beads database, for every recipient. Wisps are included.
+func (m *Mailbox) listScheduledBeads() ([]BeadsMessage, error) {
+	ctx, cancel := bdReadCtx()
+	stdout, err := runBdCommand(ctx, []string{"list", "--label", labelScheduled, "--json", "--limit", "0"}, m.workDir, m.beadsDir)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	var msgs []BeadsMessage
+	if isJSON(stdout) {
+		if err := json.Unmarshal(stdout, &msgs); err != nil {
+			return nil, err
+		}
+	}
+
+	query := fmt.Sprintf(
+		"SELECT w.id, w.title, w.description, w.status, w.priority, w.assignee, w.created_at, w.updated_at, "+
+			"GROUP_CONCAT(al.label) as labels_csv "+
+			"FROM wisps w "+
+			"JOIN wisp_labels l ON w.id = l.issue_id "+
+			"JOIN wisp_labels al ON w.id = al.issue_id "+
+			"WHERE l.label = '%s' AND w.status IN ('open', 'hooked') "+
+			"GROUP BY w.id, w.title, w.description, w.status, w.priority, w.assignee, w.created_at, w.updated_at",
+		escapeSQLString(labelScheduled))
+	msgs = append(msgs, m.runWispSQL(m.beadsDir, query)...)
+	return msgs, nil
+}
+
+// DeliverDue releases scheduled and snoozed messages whose time has come.
+// Due messages already appear in inboxes; this clears their scheduling
+// labels, applies the recipients' mail rules to newly delivered scheduled
+// mail and notifies the recipients, so a sleeping agent is woken when its
+// mail matures. Every mailbox store is scanned: the town beads, each routed
+// rig's beads (mailboxes opened from a rig snooze there) and legacy JSONL
+// inboxes. A store that cannot be read does not stop the others; the
+// released messages are returned along with the errors.
+func (r *Router) DeliverDue(now time.Time) ([]*Message, error) {
+	var released []*Message
+	var errs []error
+	townBeadsDir := r.resolveBeadsDir()
+	for _, beadsDir := range r.mailBeadsDirs() {
+		mb := NewMailboxWithBeadsDir("", filepath.Dir(beadsDir), beadsDir)
+		msgs, err := r.deliverDueBeads(mb, now, beadsDir == townBeadsDir)
+		released = append(released, msgs...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("listing scheduled mail in %s: %w", beadsDir, err))
+		}
+	}
+	for _, path := range r.legacyInboxes() {
+		msgs, err := NewMailbox(filepath.Dir(path)).releaseDueLegacy(now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("releasing snoozed mail in %s: %w", path, err))
+			continue
+		}
+		for _, msg := range msgs {
+			released = append(released, msg)
+			if !isSelfMail(msg.From, msg.To) {
+				_ = r.notifyRecipient(msg)
+			}
+		}
+	}
+	return released, errors.Join(errs...)
+}
+
+// deliverDueBeads releases the due messages in one beads database. Mail
+// rules act on the router's own database, so they are only applied when
+// withRules is set.
+func (r *Router) deliverDueBeads(mb *Mailbox, now time.Time, withRules bool) ([]*Message, error) {
+	pending, err := mb.listScheduledBeads()
+	if err != nil {
+		return nil, err
+	}
+
+	var released []*Message
+	for i := range pending {
+		bm := &pending[i]
+		if bm.Status != "open" && bm.Status != "hooked" {
+			continue
+		}
+		msg := bm.ToMessage()
+		if !msg.IsDue(now) {
+			continue
+		}
+		if err := releaseScheduled(mb, bm); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: releasing scheduled mail %s: %v\n", bm.ID, err)
+			continue
+		}
+		released = append(released, msg)
+		// Snoozed mail was filtered when it first arrived; scheduled mail
+		// meets the recipient's rules only now.
+		if withRules && msg.DeliverAt != nil && !r.applyDeliveryRules(msg) {
+			continue
+		}
+		if !isSelfMail(msg.From, msg.To) {
+			_ = r.notifyRecipient(msg)
+		}
+	}
+	return released, nil
+}
+
+// mailBeadsDirs returns the beads directories mail can live in: the town
+// beads first, then the beads of every rig in the town's routes.
+func (r *Router) mailBeadsDirs() []string {
+	townBeadsDir := r.resolveBeadsDir()
+	dirs := []string{townBeadsDir}
+	if r.townRoot == "" {
+		return dirs
+	}
+	routes, err := beads.LoadRoutes(townBeadsDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: loading routes for scheduled mail: %v\n", err)
+		return dirs
+	}
+	seen := map[string]bool{townBeadsDir: true}
+	for _, route := range routes {
+		if route.Path == "." || route.Path == "" {
+			continue
+		}
+		dir := beads.ResolveBeadsDir(filepath.Join(r.townRoot, route.Path))
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// legacyInboxes returns the legacy JSONL inboxes in the town: <agent>/mail,
+// <rig>/<role>/mail and <rig>/<role>/<name>/mail.
+func (r *Router) legacyInboxes() []string {
+	if r.townRoot == "" {
+		return nil
+	}
+	var paths []string
+	for _, pattern := range []string{"*/mail/inbox.jsonl", "*/*/mail/inbox.jsonl", "*/*/*/mail/inbox.jsonl"} {
+		matches, _ := filepath.Glob(filepath.Join(r.townRoot, pattern))
+		paths = append(paths, matches...)
+	}
+	return paths
+}
+
+// releaseDueLegacy clears the schedule of due scheduled and snoozed messages
+// in a legacy mailbox and returns them.
+func (m *Mailbox) releaseDueLegacy(now time.Time) ([]*Message, error) {
+	fl, err := m.lockLegacy()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = fl.Unlock() }()
+
+	messages, err := m.listLegacy()
+	if err != nil {
+		return nil, err
+	}
+	var released []*Message
+	for _, msg := range messages {
+		if msg.HiddenUntil().IsZero() || !msg.IsDue(now) {
+			continue
+		}
+		msg.DeliverAt, msg.SnoozedUntil = nil, nil
+		released = append(released, msg)
+	}
+	if len(released) == 0 {
+		return nil, nil
+	}
+	return released, m.rewriteLegacy(messages)
+}
+
+// releaseScheduled removes the scheduling labels from a due message.
+func releaseScheduled(mb *Mailbox, bm *BeadsMessage) error {
+	for _, label := range bm.Labels {
+		if label == labelScheduled || strings.HasPrefix(label, labelDeliverAt) || strings.HasPrefix(label, labelSnoozedUntil) {
+			if err := mb.removeLabel(bm.ID, label); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package mail
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestMessageIsDue(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+
+	tests := []struct {
+		name string
+		msg  Message
+		want bool
+	}{
+		{"plain", Message{}, true},
+		{"deliver at past", Message{DeliverAt: &past}, true},
+		{"deliver at future", Message{DeliverAt: &future}, false},
+		{"snoozed", Message{SnoozedUntil: &future}, false},
+		{"snooze expired", Message{SnoozedUntil: &past}, true},
+		{"later of the two wins", Message{DeliverAt: &past, SnoozedUntil: &future}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.msg.IsDue(now); got != tt.want {
+				t.Errorf("IsDue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleLabelsRoundTrip(t *testing.T) {
+	at := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+	labels := scheduleLabels(&Message{DeliverAt: &at})
+	if len(labels) != 2 || labels[0] != labelScheduled {
+		t.Fatalf("scheduleLabels() = %v", labels)
+	}
+
+	snoozed := formatScheduleLabel(labelSnoozedUntil, at.Add(time.Hour))
+	bm := &BeadsMessage{ID: "hq-1", Labels: append([]string{"gt:message", "from:mayor/"}, append(labels, snoozed)...)}
+	msg := bm.ToMessage()
+	if msg.DeliverAt == nil || !msg.DeliverAt.Equal(at) {
+		t.Errorf("DeliverAt = %v, want %v", msg.DeliverAt, at)
+	}
+	if msg.SnoozedUntil == nil || !msg.SnoozedUntil.Equal(at.Add(time.Hour)) {
+		t.Errorf("SnoozedUntil = %v, want %v", msg.SnoozedUntil, at.Add(time.Hour))
+	}
+
+	past := time.Now().Add(-time.Minute)
+	if labels := scheduleLabels(&Message{DeliverAt: &past}); labels != nil {
+		t.Errorf("past DeliverAt should deliver now, got labels %v", labels)
+	}
+}
+
+func TestMailboxLegacySnoozeHidesUntilDue(t *testing.T) {
+	m := NewMailbox(t.TempDir())
+	later := time.Now().Add(time.Hour)
+	for _, msg := range []*Message{
+		{ID: "msg-001", Subject: "Now", Timestamp: time.Now()},
+		{ID: "msg-002", Subject: "Later", Timestamp: time.Now(), DeliverAt: &later},
+		{ID: "msg-003", Subject: "Snooze me", Timestamp: time.Now(), Read: true},
+	} {
+		if err := m.Append(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := m.Snooze("msg-003", time.Now().Add(2*time.Hour)); err != nil {
+		t.Fatalf("Snooze error: %v", err)
+	}
+	if err := m.Snooze("msg-404", later); err != ErrMessageNotFound {
+		t.Errorf("Snooze(missing) = %v, want ErrMessageNotFound", err)
+	}
+
+	listed, err := m.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 1 || listed[0].ID != "msg-001" {
+		t.Errorf("List() = %v, want only msg-001", messageIDs(listed))
+	}
+
+	scheduled, err := m.ListScheduled()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := messageIDs(scheduled); len(ids) != 2 || ids[0] != "msg-002" || ids[1] != "msg-003" {
+		t.Errorf("ListScheduled() = %v, want [msg-002 msg-003] soonest first", ids)
+	}
+	if scheduled[1].Read {
+		t.Error("snoozed message should come back unread")
+	}
+
+	// Rewrites from other operations keep hidden messages.
+	if err := m.MarkRead("msg-001"); err != nil {
+		t.Fatal(err)
+	}
+	if scheduled, _ := m.ListScheduled(); len(scheduled) != 2 {
+		t.Errorf("hidden messages lost on rewrite: %v", messageIDs(scheduled))
+	}
+}
+
+func messageIDs(msgs []*Message) []string {
+	ids := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		ids = append(ids, msg.ID)
+	}
+	return ids
+}
+
+// Mail rules see a scheduled message when it is delivered, so a rule cannot
+// file it away before the recipient was ever meant to have it.
+func TestScheduledMail_RulesApplyAtDelivery(t *testing.T) {
+	due := formatScheduleLabel(labelDeliverAt, time.Now().Add(-time.Minute))
+	townRoot, logPath := setupRulesTown(t,
+		[]config.MailRule{{Name: "hook-all", Identity: "mayor/", Action: config.MailRuleHook}}, `
+case "$1" in
+  create) echo "hq-sched-1" ;;
+  list)
+    if [[ "$*" == *"--label gt:scheduled"* ]]; then
+      echo '[{"id":"hq-sched-1","title":"Later","status":"open","priority":2,"assignee":"mayor/","labels":["gt:message","from:mayor/","gt:scheduled","`+due+`"]}]'
+    else
+      echo "[]"
+    fi ;;
+esac
+`)
+	r := NewRouterWithTownRoot(townRoot, townRoot)
+
+	later := time.Now().Add(time.Hour)
+	msg := &Message{From: "mayor/", To: "mayor/", Subject: "Later", Body: "x", DeliverAt: &later}
+	if err := r.Send(msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if log := readBdLog(t, logPath); strings.Contains(log, "--status=hooked") {
+		t.Fatalf("rules applied at schedule time; bd calls:\n%s", log)
+	}
+
+	released, err := r.DeliverDue(time.Now())
+	if err != nil {
+		t.Fatalf("DeliverDue: %v", err)
+	}
+	if len(released) != 1 || released[0].ID != "hq-sched-1" {
+		t.Fatalf("released = %+v, want hq-sched-1", released)
+	}
+	if log := readBdLog(t, logPath); !strings.Contains(log, "update hq-sched-1 --status=hooked") {
+		t.Errorf("rules not applied at delivery; bd calls:\n%s", log)
+	}
+}
+
+// Mail snoozed in a rig's beads or in a legacy inbox is released too, not
+// just mail in the town beads.
+func TestDeliverDue_ScansRigBeadsAndLegacyInboxes(t *testing.T) {
+	due := formatScheduleLabel(labelSnoozedUntil, time.Now().Add(-time.Minute))
+	townRoot, logPath := setupRulesTown(t, nil, `
+case "$1" in
+  list)
+    if [[ "$*" == *"--label gt:scheduled"* && "$BEADS_DIR" == *gastown* ]]; then
+      echo '[{"id":"gt-snz-1","title":"Back","status":"open","priority":2,"assignee":"gastown/witness","labels":["gt:message","from:gastown/witness","gt:scheduled","`+due+`"]}]'
+    else
+      echo "[]"
+    fi ;;
+esac
+`)
+	rigBeads := filepath.Join(townRoot, "gastown", "mayor", "rig", ".beads")
+	if err := os.MkdirAll(rigBeads, 0755); err != nil {
+		t.Fatal(err)
+	}
+	routes := `{"prefix":"hq-","path":"."}` + "\n" + `{"prefix":"gt-","path":"gastown/mayor/rig"}` + "\n"
+	if err := os.WriteFile(filepath.Join(townRoot, ".beads", "routes.jsonl"), []byte(routes), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	inboxDir := filepath.Join(townRoot, "gastown", "refinery", "mail")
+	if err := os.MkdirAll(inboxDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	past, later := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+	legacy := NewMailbox(inboxDir)
+	for _, msg := range []*Message{
+		{ID: "msg-due", From: "gastown/refinery", To: "gastown/refinery", Subject: "Due", Timestamp: time.Now(), SnoozedUntil: &past},
+		{ID: "msg-later", From: "gastown/refinery", To: "gastown/refinery", Subject: "Later", Timestamp: time.Now(), SnoozedUntil: &later},
+	} {
+		if err := legacy.Append(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	released, err := NewRouterWithTownRoot(townRoot, townRoot).DeliverDue(time.Now())
+	if err != nil {
+		t.Fatalf("DeliverDue: %v", err)
+	}
+	if ids := messageIDs(released); len(ids) != 2 || ids[0] != "gt-snz-1" || ids[1] != "msg-due" {
+		t.Fatalf("released = %v, want [gt-snz-1 msg-due]", ids)
+	}
+	if log := readBdLog(t, logPath); !strings.Contains(log, "label remove gt-snz-1 gt:scheduled") {
+		t.Errorf("rig mail not released; bd calls:\n%s", log)
+	}
+	scheduled, err := legacy.ListScheduled()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := messageIDs(scheduled); len(ids) != 1 || ids[0] != "msg-later" {
+		t.Errorf("legacy scheduled = %v, want only msg-later", ids)
+	}
+}
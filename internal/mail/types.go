@@ -133,10 +133,22 @@ type Message struct {
 	// DeliveryAckedAt is when receipt was acknowledged.
 	DeliveryAckedAt *time.Time `json:"delivery_acked_at,omitempty"`
 
+	// DeliverAt holds a scheduled message back until the given time.
+	// Until then it is hidden from List and ListUnread.
+	DeliverAt *time.Time `json:"deliver_at,omitempty"`
+
+	// SnoozedUntil hides a delivered message until the given time.
+	SnoozedUntil *time.Time `json:"snoozed_until,omitempty"`
+
 	// SuppressNotify tells the router to skip all recipient notification
 	// (no nudge, no banner). Set by the CLI when --no-notify is passed.
 	// In-memory only — not serialized.
 	SuppressNotify bool `json:"-"`
+
+	// SkipRules tells the router not to apply the recipient's mail rules.
+	// Set on copies produced by a forward rule so rules cannot loop.
+	// In-memory only — not serialized.
+	SkipRules bool `json:"-"`
 }
 
 // NewMessage creates a new message with a generated ID and thread ID.
@@ -304,7 +316,7 @@ type BeadsMessage struct {
 	Priority    int       `json:"priority"`    // 0=urgent, 1=high, 2=normal, 3=low
 	Status      string    `json:"status"`      // open=unread, closed=read
 	CreatedAt   time.Time `json:"created_at"`
-	Labels      []string  `json:"labels"` // Metadata labels (from:X, thread:X, reply-to:X, msg-type:X, cc:X, queue:X, channel:X, claimed-by:X, claimed-at:X)
+	Labels      []string  `json:"labels"` // Metadata labels (from:X, thread:X, reply-to:X, msg-type:X, cc:X, queue:X, channel:X, claimed-by:X, claimed-at:X, deliver-at:X, snoozed-until:X)
 	Pinned      bool      `json:"pinned,omitempty"`
 	Wisp        bool      `json:"wisp,omitempty"` // Ephemeral message (not synced to git)
 
@@ -322,6 +334,9 @@ type BeadsMessage struct {
 	deliveryState   string
 	deliveryAckedBy string
 	deliveryAckedAt *time.Time
+	// Scheduling metadata
+	deliverAt    *time.Time
+	snoozedUntil *time.Time
 }
 
 // ParseLabels extracts metadata from the labels array.
@@ -339,6 +354,8 @@ func (bm *BeadsMessage) ParseLabels() {
 	bm.deliveryState = ""
 	bm.deliveryAckedBy = ""
 	bm.deliveryAckedAt = nil
+	bm.deliverAt = nil
+	bm.snoozedUntil = nil
 
 	for _, label := range bm.Labels {
 		if strings.HasPrefix(label, "from:") {
@@ -362,6 +379,10 @@ func (bm *BeadsMessage) ParseLabels() {
 			if t, err := time.Parse(time.RFC3339, ts); err == nil {
 				bm.claimedAt = &t
 			}
+		} else if strings.HasPrefix(label, labelDeliverAt) {
+			bm.deliverAt = parseScheduleLabel(label, labelDeliverAt)
+		} else if strings.HasPrefix(label, labelSnoozedUntil) {
+			bm.snoozedUntil = parseScheduleLabel(label, labelSnoozedUntil)
 		}
 	}
 
@@ -435,6 +456,8 @@ func (bm *BeadsMessage) ToMessage() *Message {
 		DeliveryState:   bm.deliveryState,
 		DeliveryAckedBy: bm.deliveryAckedBy,
 		DeliveryAckedAt: bm.deliveryAckedAt,
+		DeliverAt:       bm.deliverAt,
+		SnoozedUntil:    bm.snoozedUntil,
 	}
 }
 
@@ -139,7 +139,7 @@ func (m *Manager) StartTMUX(agentOverride string) error {
 		return ErrAlreadyRunning
 	}
 
-	t := tmux.NewTmux()
+	t := session.NewBackend(m.townRoot)
 	sessionID := m.SessionName()
 
 	// Kill any existing zombie session (tmux alive but agent dead).
@@ -324,7 +324,7 @@ func (m *Manager) StartACP(ctx context.Context, agentOverride, rigName string) e
 
 // Stop stops the mayor session.
 func (m *Manager) Stop() error {
-	t := tmux.NewTmux()
+	t := session.NewBackend(m.townRoot)
 	sessionID := m.SessionName()
 
 	// Check if session exists
@@ -350,13 +350,13 @@ func (m *Manager) Stop() error {
 
 // IsRunning checks if the mayor session is active in TMUX mode.
 func (m *Manager) IsRunning() (bool, error) {
-	t := tmux.NewTmux()
+	t := session.NewBackend(m.townRoot)
 	return t.HasSession(m.SessionName())
 }
 
 // Status returns information about the mayor session.
 func (m *Manager) Status() (*tmux.SessionInfo, error) {
-	t := tmux.NewTmux()
+	t := session.NewBackend(m.townRoot)
 	sessionID := m.SessionName()
 
 	running, err := t.HasSession(sessionID)
@@ -0,0 +1,448 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// LockFileName is the file in the town plugins directory that pins plugins
+// installed from git to a commit and content hash.
+const LockFileName = "plugins.lock"
+
+// ErrPluginModified is returned by Verify when an installed plugin's
+// contents no longer match the hash recorded in plugins.lock.
+var ErrPluginModified = errors.New("plugin contents do not match plugins.lock")
+
+// validPluginName restricts installed plugin names to safe directory names.
+var validPluginName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// shaPattern matches a full or abbreviated commit hash.
+var shaPattern = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// Lock is the contents of plugins.lock.
+type Lock struct {
+	Version int                   `json:"version"`
+	Plugins map[string]*LockEntry `json:"plugins"`
+}
+
+// LockEntry pins one installed plugin.
+type LockEntry struct {
+	// Name is the plugin name (its directory under plugins/).
+	Name string `json:"name"`
+
+	// Rig is set for plugins installed into <rig>/plugins/.
+	Rig string `json:"rig,omitempty"`
+
+	// Source is the git URL the plugin was installed from.
+	Source string `json:"source"`
+
+	// Ref is the requested branch, tag or commit ("" for the default branch).
+	Ref string `json:"ref,omitempty"`
+
+	// Subdir is the plugin's directory within the repository.
+	Subdir string `json:"subdir,omitempty"`
+
+	// Commit is the commit Ref resolved to at install time.
+	Commit string `json:"commit"`
+
+	// Hash is the content hash of the installed plugin directory (see DirHash).
+	Hash string `json:"hash"`
+
+	// InstalledAt is when the plugin was last installed or updated.
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// LockPath returns the path of the town's plugins.lock.
+func LockPath(townRoot string) string {
+	return filepath.Join(townRoot, "plugins", LockFileName)
+}
+
+// LoadLock reads plugins.lock, returning an empty lock if there is none.
+func LoadLock(townRoot string) (*Lock, error) {
+	return readLockFile(LockPath(townRoot))
+}
+
+func readLockFile(path string) (*Lock, error) {
+	lock := &Lock{Version: 1, Plugins: make(map[string]*LockEntry)}
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is the town's lock file
+	if os.IsNotExist(err) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", LockFileName, err)
+	}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", LockFileName, err)
+	}
+	if lock.Plugins == nil {
+		lock.Plugins = make(map[string]*LockEntry)
+	}
+	return lock, nil
+}
+
+// Save writes plugins.lock atomically.
+func (l *Lock) Save(townRoot string) error {
+	path := LockPath(townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating plugins directory: %w", err)
+	}
+	return util.AtomicWriteJSON(path, l)
+}
+
+// lockKey identifies a plugin in the lock: its name, prefixed by the rig
+// for rig-level plugins.
+func lockKey(rig, name string) string {
+	if rig == "" {
+		return name
+	}
+	return rig + "/" + name
+}
+
+// Get returns the entry for a plugin, or nil if it is not pinned.
+func (l *Lock) Get(rig, name string) *LockEntry {
+	return l.Plugins[lockKey(rig, name)]
+}
+
+// Put adds or replaces an entry.
+func (l *Lock) Put(e *LockEntry) {
+	l.Plugins[lockKey(e.Rig, e.Name)] = e
+}
+
+// Remove drops a plugin from the lock.
+func (l *Lock) Remove(rig, name string) {
+	delete(l.Plugins, lockKey(rig, name))
+}
+
+// Entries returns the lock entries sorted by rig, then name.
+func (l *Lock) Entries() []*LockEntry {
+	entries := make([]*LockEntry, 0, len(l.Plugins))
+	for _, e := range l.Plugins {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Rig != entries[j].Rig {
+			return entries[i].Rig < entries[j].Rig
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+// Dir returns the directory the entry's plugin is installed in.
+func (e *LockEntry) Dir(townRoot string) string {
+	return filepath.Join(pluginsDir(townRoot, e.Rig), e.Name)
+}
+
+// PinnedToCommit reports whether Ref names a commit rather than a branch or
+// tag, so the plugin can never be outdated.
+func (e *LockEntry) PinnedToCommit() bool {
+	return shaPattern.MatchString(e.Ref) && strings.HasPrefix(e.Commit, e.Ref)
+}
+
+func pluginsDir(townRoot, rig string) string {
+	if rig == "" {
+		return filepath.Join(townRoot, "plugins")
+	}
+	return filepath.Join(townRoot, rig, "plugins")
+}
+
+// ParseSource splits an install spec of the form <git-url>[@ref]. An @ is
+// only taken as the ref separator after the last / or :, so
+// git@github.com:org/repo.git has no ref.
+func ParseSource(spec string) (url, ref string) {
+	at := strings.LastIndex(spec, "@")
+	if at <= 0 || at < strings.LastIndexAny(spec, "/:") {
+		return spec, ""
+	}
+	return spec[:at], spec[at+1:]
+}
+
+// InstallOptions configures Install.
+type InstallOptions struct {
+	// Rig installs into <rig>/plugins/ instead of the town plugins directory.
+	Rig string
+
+	// Subdir is the path within the repository to install from: a plugin
+	// directory, or a directory of plugin directories. Defaults to the
+	// repository root.
+	Subdir string
+}
+
+// Installed describes one plugin written by Install or Update.
+type Installed struct {
+	Entry  *LockEntry
+	Plugin *Plugin
+
+	// Previous is the entry it replaced (nil for a new install).
+	Previous *LockEntry
+}
+
+// Install clones source at ref, copies the plugins it contains into the
+// plugins directory and pins them in plugins.lock.
+func Install(townRoot, source, ref string, opts InstallOptions) ([]*Installed, error) {
+	checkout, commit, cleanup, err := fetchSource(source, ref)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	root := filepath.Join(checkout, filepath.FromSlash(opts.Subdir))
+	dirs, err := findPluginDirs(root)
+	if err != nil {
+		return nil, err
+	}
+	if len(dirs) == 0 {
+		where := source
+		if opts.Subdir != "" {
+			where += " (" + opts.Subdir + ")"
+		}
+		return nil, fmt.Errorf("no plugin.md found in %s", where)
+	}
+
+	lock, err := LoadLock(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	var out []*Installed
+	var installErr error
+	for _, dir := range dirs {
+		subdir, err := filepath.Rel(checkout, dir)
+		if err != nil {
+			installErr = err
+			break
+		}
+		if subdir == "." {
+			subdir = ""
+		}
+		inst, err := installDir(townRoot, dir, &LockEntry{
+			Rig:    opts.Rig,
+			Source: source,
+			Ref:    ref,
+			Subdir: filepath.ToSlash(subdir),
+			Commit: commit,
+		}, "", lock)
+		if err != nil {
+			installErr = err
+			break
+		}
+		out = append(out, inst)
+	}
+	// Pin whatever was installed, even if a later plugin failed.
+	if len(out) > 0 {
+		if err := lock.Save(townRoot); err != nil {
+			return out, fmt.Errorf("writing %s: %w", LockFileName, err)
+		}
+	}
+	return out, installErr
+}
+
+// Update re-resolves a pinned plugin's ref and reinstalls it if the commit
+// moved or the installed copy was modified. Returns nil if it was current.
+func Update(townRoot string, e *LockEntry) (*Installed, error) {
+	checkout, commit, cleanup, err := fetchSource(e.Source, e.Ref)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	if commit == e.Commit && DirHash(e.Dir(townRoot)) == e.Hash {
+		return nil, nil
+	}
+
+	lock, err := LoadLock(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(checkout, filepath.FromSlash(e.Subdir))
+	if _, err := os.Stat(filepath.Join(dir, "plugin.md")); err != nil {
+		return nil, fmt.Errorf("%s no longer has a plugin at %q", e.Source, e.Subdir)
+	}
+	next := *e
+	next.Commit = commit
+	inst, err := installDir(townRoot, dir, &next, e.Name, lock)
+	if err != nil {
+		return nil, err
+	}
+	if err := lock.Save(townRoot); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", LockFileName, err)
+	}
+	return inst, nil
+}
+
+// installDir copies the plugin in dir into place and records entry (with
+// Name, Hash and InstalledAt filled in) in lock. If wantName is set the
+// plugin must still be called that.
+func installDir(townRoot, dir string, entry *LockEntry, wantName string, lock *Lock) (*Installed, error) {
+	content, err := os.ReadFile(filepath.Join(dir, "plugin.md")) //nolint:gosec // G304: path is inside our own checkout
+	if err != nil {
+		return nil, fmt.Errorf("reading plugin.md: %w", err)
+	}
+	p, err := parsePluginMD(content, dir, LocationTown, entry.Rig)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", entry.Subdir, err)
+	}
+	if !validPluginName.MatchString(p.Name) {
+		return nil, fmt.Errorf("invalid plugin name %q", p.Name)
+	}
+	if wantName != "" && p.Name != wantName {
+		return nil, fmt.Errorf("plugin %s was renamed to %s upstream; reinstall it", wantName, p.Name)
+	}
+	if p.IsExecWrapper() {
+		// Exec wrappers sit in front of every agent session; installing one
+		// from a remote source is too easy to do by accident.
+		return nil, fmt.Errorf("plugin %s is an exec-wrapper and cannot be installed from git; copy it into plugins/ by hand", p.Name)
+	}
+
+	entry.Name = p.Name
+	prev := lock.Get(entry.Rig, entry.Name)
+	target := entry.Dir(townRoot)
+	if prev == nil {
+		if _, err := os.Stat(target); err == nil {
+			return nil, fmt.Errorf("plugin %s already exists at %s and was not installed from git; remove it first", p.Name, target)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return nil, fmt.Errorf("creating plugins directory: %w", err)
+	}
+	if err := copyDir(dir, target); err != nil {
+		return nil, fmt.Errorf("installing %s: %w", p.Name, err)
+	}
+
+	entry.Hash = DirHash(target)
+	entry.InstalledAt = time.Now().UTC()
+	lock.Put(entry)
+
+	p.Path = target
+	if entry.Rig != "" {
+		p.Location = LocationRig
+	}
+	return &Installed{Entry: entry, Plugin: p, Previous: prev}, nil
+}
+
+// fetchSource clones source at ref into a temporary directory without its
+// .git metadata, returning the checkout, the resolved commit and a cleanup
+// function.
+func fetchSource(source, ref string) (string, string, func(), error) {
+	tmp, err := os.MkdirTemp("", "gt-plugin-*")
+	if err != nil {
+		return "", "", nil, fmt.Errorf("creating temp dir: %w", err)
+	}
+	cleanup := func() { _ = os.RemoveAll(tmp) }
+	checkout := filepath.Join(tmp, "src")
+
+	g := git.NewGit(tmp)
+	switch {
+	case ref == "":
+		err = g.Clone(source, checkout)
+	default:
+		// Branches and tags clone shallowly; anything else (a commit)
+		// needs full history to check out.
+		if err = g.CloneBranch(source, checkout, ref); err != nil {
+			if err = g.CloneFull(source, checkout); err == nil {
+				err = git.NewGit(checkout).Checkout(ref)
+			}
+		}
+	}
+	if err != nil {
+		cleanup()
+		return "", "", nil, fmt.Errorf("fetching %s: %w", describeSource(source, ref), err)
+	}
+
+	commit, err := git.NewGit(checkout).Rev("HEAD")
+	if err != nil {
+		cleanup()
+		return "", "", nil, fmt.Errorf("resolving %s: %w", describeSource(source, ref), err)
+	}
+	if err := os.RemoveAll(filepath.Join(checkout, ".git")); err != nil {
+		cleanup()
+		return "", "", nil, fmt.Errorf("removing git metadata: %w", err)
+	}
+	return checkout, commit, cleanup, nil
+}
+
+func describeSource(source, ref string) string {
+	if ref == "" {
+		return source
+	}
+	return source + "@" + ref
+}
+
+// findPluginDirs returns root if it is a plugin directory, otherwise its
+// immediate subdirectories that are.
+func findPluginDirs(root string) ([]string, error) {
+	if _, err := os.Stat(filepath.Join(root, "plugin.md")); err == nil {
+		return []string{root}, nil
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", root, err)
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		dir := filepath.Join(root, entry.Name())
+		if _, err := os.Stat(filepath.Join(dir, "plugin.md")); err == nil {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs, nil
+}
+
+// Outdated describes a pinned plugin whose ref has moved or whose installed
+// copy has been modified.
+type Outdated struct {
+	Entry *LockEntry
+
+	// Latest is the commit the entry's ref currently resolves to.
+	Latest string
+
+	// Modified is true if the installed copy no longer matches its hash.
+	Modified bool
+}
+
+// CheckOutdated resolves e's ref on its remote without cloning. It returns
+// nil if the plugin is current.
+func CheckOutdated(townRoot string, e *LockEntry) (*Outdated, error) {
+	latest := e.Commit
+	if !e.PinnedToCommit() {
+		sha, err := git.NewGit("").ResolveRemoteRef(e.Source, e.Ref)
+		if err != nil {
+			return nil, fmt.Errorf("checking %s: %w", describeSource(e.Source, e.Ref), err)
+		}
+		latest = sha
+	}
+	modified := DirHash(e.Dir(townRoot)) != e.Hash
+	if latest == e.Commit && !modified {
+		return nil, nil
+	}
+	return &Outdated{Entry: e, Latest: latest, Modified: modified}, nil
+}
+
+// Verify checks a plugin against plugins.lock before it is dispatched.
+// Plugins that were not installed from git are not pinned and always pass.
+func Verify(townRoot string, p *Plugin) error {
+	lock, err := LoadLock(townRoot)
+	if err != nil {
+		return err
+	}
+	e := lock.Get(p.RigName, p.Name)
+	if e == nil {
+		return nil
+	}
+	if DirHash(p.Path) != e.Hash {
+		return fmt.Errorf("%s: %w (run 'gt plugin update %s' to restore it)", p.Name, ErrPluginModified, p.Name)
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+package plugin
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runGit is a test helper that runs git commands and fails the test on error.
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test",
+		"GIT_AUTHOR_EMAIL=test@test.com",
+		"GIT_COMMITTER_NAME=Test",
+		"GIT_COMMITTER_EMAIL=test@test.com",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v (dir=%s) failed: %v\n%s", args, dir, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// testPluginRepo creates a bare repo holding a notify plugin and returns
+// its URL and a work tree for pushing further commits.
+func testPluginRepo(t *testing.T) (bare, work string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	bare = filepath.Join(root, "plugins.git")
+	work = filepath.Join(root, "work")
+	runGit(t, root, "init", "--bare", "-b", "main", bare)
+	runGit(t, root, "clone", bare, work)
+
+	writeTestFile(t, filepath.Join(work, "notify", "plugin.md"), `+++
+name = "notify"
+description = "Notify on merges"
+
+[gate]
+type = "event"
+on = "merged"
+
+[permissions]
+rigs = ["gastown"]
+commands = ["gh"]
++++
+
+Post to the channel.
+`)
+	writeTestFile(t, filepath.Join(work, "notify", "run.sh"), "echo v1\n")
+	writeTestFile(t, filepath.Join(work, "README.md"), "plugins\n")
+	commitAndPush(t, work, "v1")
+	runGit(t, work, "tag", "v1")
+	runGit(t, work, "push", "-q", "origin", "v1")
+	return bare, work
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func commitAndPush(t *testing.T, work, msg string) string {
+	t.Helper()
+	runGit(t, work, "add", "-A")
+	runGit(t, work, "commit", "-q", "-m", msg)
+	runGit(t, work, "push", "-q", "origin", "HEAD:main")
+	return runGit(t, work, "rev-parse", "HEAD")
+}
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		spec, url, ref string
+	}{
+		{"https://github.com/acme/plugins.git", "https://github.com/acme/plugins.git", ""},
+		{"https://github.com/acme/plugins.git@v1.2.0", "https://github.com/acme/plugins.git", "v1.2.0"},
+		{"git@github.com:acme/plugins.git", "git@github.com:acme/plugins.git", ""},
+		{"git@github.com:acme/plugins.git@main", "git@github.com:acme/plugins.git", "main"},
+		{"/srv/git/plugins.git@abc1234", "/srv/git/plugins.git", "abc1234"},
+	}
+	for _, tt := range tests {
+		url, ref := ParseSource(tt.spec)
+		if url != tt.url || ref != tt.ref {
+			t.Errorf("ParseSource(%q) = %q, %q, want %q, %q", tt.spec, url, ref, tt.url, tt.ref)
+		}
+	}
+}
+
+func TestInstall_PinsAndVerifies(t *testing.T) {
+	bare, _ := testPluginRepo(t)
+	townRoot := t.TempDir()
+
+	installed, err := Install(townRoot, bare, "v1", InstallOptions{})
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if len(installed) != 1 || installed[0].Entry.Name != "notify" {
+		t.Fatalf("installed = %+v", installed)
+	}
+	e := installed[0].Entry
+	if e.Subdir != "notify" || e.Ref != "v1" || len(e.Commit) != 40 || e.Hash == "" {
+		t.Errorf("lock entry = %+v", e)
+	}
+	if _, err := os.Stat(filepath.Join(townRoot, "plugins", "notify", ".git")); !os.IsNotExist(err) {
+		t.Error("installed plugin should not contain git metadata")
+	}
+
+	lock, err := LoadLock(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := lock.Get("", "notify"); got == nil || got.Commit != e.Commit {
+		t.Fatalf("lock entry after reload = %+v", got)
+	}
+
+	scanner := NewScanner(townRoot, nil)
+	p, err := scanner.GetPlugin("notify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Permissions == nil || p.Permissions.Describe() != "rigs: gastown; commands: gh" {
+		t.Errorf("permissions = %+v", p.Permissions)
+	}
+	if err := Verify(townRoot, p); err != nil {
+		t.Errorf("Verify on fresh install: %v", err)
+	}
+
+	// Editing the installed copy fails verification.
+	writeTestFile(t, filepath.Join(p.Path, "run.sh"), "echo tampered\n")
+	if err := Verify(townRoot, p); !errors.Is(err, ErrPluginModified) {
+		t.Errorf("Verify after edit = %v, want ErrPluginModified", err)
+	}
+
+	// Plugins that were not installed from git are not pinned.
+	writeTestFile(t, filepath.Join(townRoot, "plugins", "local", "plugin.md"), "+++\nname = \"local\"\n+++\n")
+	local, err := scanner.GetPlugin("local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(townRoot, local); err != nil {
+		t.Errorf("Verify on unpinned plugin: %v", err)
+	}
+}
+
+func TestInstall_RefusesToClobberUnpinnedPlugin(t *testing.T) {
+	bare, _ := testPluginRepo(t)
+	townRoot := t.TempDir()
+	writeTestFile(t, filepath.Join(townRoot, "plugins", "notify", "plugin.md"), "+++\nname = \"notify\"\n+++\n")
+
+	if _, err := Install(townRoot, bare, "", InstallOptions{}); err == nil {
+		t.Fatal("expected install over a hand-made plugin to fail")
+	}
+}
+
+func TestOutdatedAndUpdate(t *testing.T) {
+	bare, work := testPluginRepo(t)
+	townRoot := t.TempDir()
+
+	if _, err := Install(townRoot, bare, "main", InstallOptions{Subdir: "notify"}); err != nil {
+		t.Fatal(err)
+	}
+	lock, _ := LoadLock(townRoot)
+	e := lock.Get("", "notify")
+	if e.Subdir != "notify" {
+		t.Errorf("subdir = %q, want notify", e.Subdir)
+	}
+
+	if o, err := CheckOutdated(townRoot, e); err != nil || o != nil {
+		t.Fatalf("CheckOutdated on current plugin = %+v, %v", o, err)
+	}
+
+	writeTestFile(t, filepath.Join(work, "notify", "run.sh"), "echo v2\n")
+	v2 := commitAndPush(t, work, "v2")
+
+	o, err := CheckOutdated(townRoot, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o == nil || o.Latest != v2 || o.Modified {
+		t.Fatalf("CheckOutdated after push = %+v", o)
+	}
+
+	inst, err := Update(townRoot, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inst == nil || inst.Entry.Commit != v2 || inst.Previous.Commit != e.Commit {
+		t.Fatalf("Update = %+v", inst)
+	}
+	data, _ := os.ReadFile(filepath.Join(townRoot, "plugins", "notify", "run.sh"))
+	if string(data) != "echo v2\n" {
+		t.Errorf("run.sh after update = %q", data)
+	}
+
+	lock, _ = LoadLock(townRoot)
+	e = lock.Get("", "notify")
+	if inst, err := Update(townRoot, e); err != nil || inst != nil {
+		t.Errorf("second Update = %+v, %v, want no change", inst, err)
+	}
+
+	// A local edit is reported and restored.
+	writeTestFile(t, filepath.Join(townRoot, "plugins", "notify", "run.sh"), "echo tampered\n")
+	if o, _ := CheckOutdated(townRoot, e); o == nil || !o.Modified {
+		t.Errorf("CheckOutdated after edit = %+v, want modified", o)
+	}
+	if inst, err := Update(townRoot, e); err != nil || inst == nil {
+		t.Fatalf("Update after edit = %+v, %v", inst, err)
+	}
+	data, _ = os.ReadFile(filepath.Join(townRoot, "plugins", "notify", "run.sh"))
+	if string(data) != "echo v2\n" {
+		t.Errorf("run.sh after restore = %q", data)
+	}
+}
+
+func TestInstall_CommitRefNeverOutdated(t *testing.T) {
+	bare, work := testPluginRepo(t)
+	townRoot := t.TempDir()
+	v1 := runGit(t, work, "rev-parse", "HEAD")
+
+	writeTestFile(t, filepath.Join(work, "notify", "run.sh"), "echo v2\n")
+	commitAndPush(t, work, "v2")
+
+	installed, err := Install(townRoot, bare, v1[:10], InstallOptions{Rig: "gastown"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := installed[0].Entry
+	if e.Commit != v1 || !e.PinnedToCommit() {
+		t.Fatalf("entry = %+v, want pinned to %s", e, v1)
+	}
+	if _, err := os.Stat(filepath.Join(townRoot, "gastown", "plugins", "notify", "plugin.md")); err != nil {
+		t.Errorf("expected rig-level install: %v", err)
+	}
+	if o, err := CheckOutdated(townRoot, e); err != nil || o != nil {
+		t.Errorf("CheckOutdated on commit pin = %+v, %v", o, err)
+	}
+}
+
+func TestSyncPlugins_SkipsPinned(t *testing.T) {
+	bare, _ := testPluginRepo(t)
+	townRoot := t.TempDir()
+	if _, err := Install(townRoot, bare, "", InstallOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	src := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "notify", "plugin.md"), "+++\nname = \"notify\"\n+++\nother\n")
+	writeTestFile(t, filepath.Join(src, "rebuild-gt", "plugin.md"), "+++\nname = \"rebuild-gt\"\n+++\n")
+
+	result, err := SyncPlugins(src, filepath.Join(townRoot, "plugins"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Pinned) != 1 || result.Pinned[0] != "notify" {
+		t.Errorf("pinned = %v, want [notify]", result.Pinned)
+	}
+	p, err := NewScanner(townRoot, nil).GetPlugin("notify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(townRoot, p); err != nil {
+		t.Errorf("sync clobbered pinned plugin: %v", err)
+	}
+}
+
+func TestSyncPlugins_SkipsPinnedInRig(t *testing.T) {
+	bare, _ := testPluginRepo(t)
+	townRoot := t.TempDir()
+	if _, err := Install(townRoot, bare, "", InstallOptions{Rig: "gastown"}); err != nil {
+		t.Fatal(err)
+	}
+	rigPlugins := filepath.Join(townRoot, "gastown", "plugins")
+	pinnedHash := DirHash(filepath.Join(rigPlugins, "notify"))
+
+	src := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "notify", "plugin.md"), "+++\nname = \"notify\"\n+++\nother\n")
+
+	result, err := SyncPlugins(src, rigPlugins, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Pinned) != 1 || result.Pinned[0] != "notify" || len(result.Copied) != 0 {
+		t.Errorf("result = %+v, want notify pinned", result)
+	}
+	if got := DirHash(filepath.Join(rigPlugins, "notify")); got != pinnedHash {
+		t.Error("sync clobbered plugin pinned for the rig")
+	}
+
+	// The town's own plugins are keyed without a rig, so the rig pin does
+	// not apply there.
+	result, err = SyncPlugins(src, filepath.Join(townRoot, "plugins"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Copied) != 1 || len(result.Pinned) != 0 {
+		t.Errorf("town result = %+v, want notify copied", result)
+	}
+}
+
+func TestDetectDrift_ReportsPinned(t *testing.T) {
+	bare, _ := testPluginRepo(t)
+	townRoot := t.TempDir()
+	if _, err := Install(townRoot, bare, "", InstallOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	src := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "notify", "plugin.md"), "+++\nname = \"notify\"\n+++\nother\n")
+	writeTestFile(t, filepath.Join(src, "rebuild-gt", "plugin.md"), "+++\nname = \"rebuild-gt\"\n+++\n")
+
+	report, err := DetectDrift(src, filepath.Join(townRoot, "plugins"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Pinned) != 1 || report.Pinned[0] != "notify" {
+		t.Errorf("pinned = %v, want [notify]", report.Pinned)
+	}
+	if len(report.Drifted) != 0 || len(report.Extra) != 0 {
+		t.Errorf("drifted = %v, extra = %v; pinned plugins should be neither", report.Drifted, report.Extra)
+	}
+	if len(report.Missing) != 1 || report.Missing[0] != "rebuild-gt" {
+		t.Errorf("missing = %v, want [rebuild-gt]", report.Missing)
+	}
+}
@@ -195,6 +195,7 @@ func parsePluginMD(content []byte, pluginDir string, location Location, rigName
 		Gate:         fm.Gate,
 		Tracking:     fm.Tracking,
 		Execution:    fm.Execution,
+		Permissions:  fm.Permissions,
 		Instructions: body,
 	}
 
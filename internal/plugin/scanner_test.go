@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestParsePluginMD(t *testing.T) {
@@ -824,3 +825,63 @@ func TestFormatMailBody_WithoutRunScript(t *testing.T) {
 		t.Error("expected mail body to NOT contain run.sh command")
 	}
 }
+
+func TestParsePluginMD_EventGate(t *testing.T) {
+	content := []byte(`+++
+name = "post-merge"
+description = "Runs after merges"
+version = 1
+
+[gate]
+type = "event"
+on = ["merged", "channel.refinery"]
+debounce = "30s"
+max_concurrent = 2
+
+[gate.match]
+rig = "gastown"
++++
+
+# Post Merge
+`)
+	plugin, err := parsePluginMD(content, "/test/path", LocationTown, "")
+	if err != nil {
+		t.Fatalf("parsePluginMD failed: %v", err)
+	}
+	g := plugin.Gate
+	if g == nil || g.Type != GateEvent {
+		t.Fatalf("expected event gate, got %+v", g)
+	}
+	if len(g.On) != 2 || g.On[0] != "merged" || g.On[1] != "channel.refinery" {
+		t.Errorf("On = %v", g.On)
+	}
+	if g.Match["rig"] != "gastown" {
+		t.Errorf("Match = %v", g.Match)
+	}
+	if d, err := g.DebounceDuration(); err != nil || d != 30*time.Second {
+		t.Errorf("DebounceDuration = %v, %v", d, err)
+	}
+	if g.ConcurrencyLimit() != 2 {
+		t.Errorf("ConcurrencyLimit = %d, want 2", g.ConcurrencyLimit())
+	}
+
+	// A single event may be given as a plain string.
+	single := []byte(`+++
+name = "on-startup"
+
+[gate]
+type = "event"
+on = "startup"
++++
+`)
+	plugin, err = parsePluginMD(single, "/test/path", LocationTown, "")
+	if err != nil {
+		t.Fatalf("parsePluginMD failed: %v", err)
+	}
+	if len(plugin.Gate.On) != 1 || plugin.Gate.On[0] != EventStartup {
+		t.Errorf("On = %v, want [startup]", plugin.Gate.On)
+	}
+	if plugin.Gate.ConcurrencyLimit() != 1 {
+		t.Errorf("default ConcurrencyLimit = %d, want 1", plugin.Gate.ConcurrencyLimit())
+	}
+}
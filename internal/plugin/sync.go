@@ -15,11 +15,15 @@ type SyncResult struct {
 	Copied  []string // plugin names that were copied/updated
 	Removed []string // plugin names that were removed (clean mode)
 	Skipped []string // plugin names that were already up-to-date
+	Pinned  []string // plugin names left alone because plugins.lock pins them
 	Errors  []string // errors encountered
 }
 
 // SyncPlugins copies plugin directories from source to target.
 // If clean is true, removes plugins from target that don't exist in source.
+// Plugins pinned in plugins.lock (installed from git) are neither
+// overwritten nor removed; use gt plugin update for those. See targetLock
+// for how the lock is found for town and rig targets.
 func SyncPlugins(sourceDir, targetDir string, clean bool) (*SyncResult, error) {
 	result := &SyncResult{}
 
@@ -35,6 +39,11 @@ func SyncPlugins(sourceDir, targetDir string, clean bool) (*SyncResult, error) {
 		return nil, fmt.Errorf("creating target directory: %w", err)
 	}
 
+	lock, rig, err := targetLock(targetDir)
+	if err != nil {
+		return nil, err
+	}
+
 	srcEntries, err := os.ReadDir(sourceDir)
 	if err != nil {
 		return nil, fmt.Errorf("reading source directory: %w", err)
@@ -50,6 +59,10 @@ func SyncPlugins(sourceDir, targetDir string, clean bool) (*SyncResult, error) {
 			continue // Not a plugin directory
 		}
 		srcPlugins[entry.Name()] = true
+		if lock.Get(rig, entry.Name()) != nil {
+			result.Pinned = append(result.Pinned, entry.Name())
+			continue
+		}
 
 		srcPluginDir := filepath.Join(sourceDir, entry.Name())
 		dstPluginDir := filepath.Join(targetDir, entry.Name())
@@ -73,7 +86,7 @@ func SyncPlugins(sourceDir, targetDir string, clean bool) (*SyncResult, error) {
 				if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
 					continue
 				}
-				if !srcPlugins[entry.Name()] {
+				if !srcPlugins[entry.Name()] && lock.Get(rig, entry.Name()) == nil {
 					dstPath := filepath.Join(targetDir, entry.Name())
 					if err := os.RemoveAll(dstPath); err != nil {
 						result.Errors = append(result.Errors, fmt.Sprintf("removing %s: %v", entry.Name(), err))
@@ -88,6 +101,24 @@ func SyncPlugins(sourceDir, targetDir string, clean bool) (*SyncResult, error) {
 	return result, nil
 }
 
+// targetLock returns the plugins.lock that pins plugins in targetDir, and
+// the rig its entries for targetDir are keyed by. The lock lives in the town
+// plugins directory: a target holding it is the town's ("" rig), and a
+// <town>/<rig>/plugins target uses the town's lock under "<rig>/<name>".
+// A target with no lock either way gets an empty one.
+func targetLock(targetDir string) (*Lock, string, error) {
+	if _, err := os.Stat(filepath.Join(targetDir, LockFileName)); err != nil && filepath.Base(targetDir) == "plugins" {
+		rigDir := filepath.Dir(targetDir)
+		townLock := LockPath(filepath.Dir(rigDir))
+		if _, err := os.Stat(townLock); err == nil {
+			lock, err := readLockFile(townLock)
+			return lock, filepath.Base(rigDir), err
+		}
+	}
+	lock, err := readLockFile(filepath.Join(targetDir, LockFileName))
+	return lock, "", err
+}
+
 // dirsMatch checks if two plugin directories have identical contents.
 func dirsMatch(src, dst string) bool {
 	srcHash := DirHash(src)
@@ -257,10 +288,11 @@ func hasPlugins(dir string) bool {
 // DriftReport describes differences between source and runtime plugins.
 type DriftReport struct {
 	Source  string       `json:"source"`
-	Target string       `json:"target"`
+	Target  string       `json:"target"`
 	Drifted []DriftEntry `json:"drifted,omitempty"`
 	Missing []string     `json:"missing,omitempty"` // in source but not target
 	Extra   []string     `json:"extra,omitempty"`   // in target but not source
+	Pinned  []string     `json:"pinned,omitempty"`  // pinned in plugins.lock; sync leaves them alone
 }
 
 // DriftEntry describes a single plugin that differs between source and runtime.
@@ -271,12 +303,19 @@ type DriftEntry struct {
 }
 
 // DetectDrift compares plugin directories between source and target.
+// Plugins pinned in plugins.lock are reported as Pinned rather than as
+// drifted, missing or extra, since SyncPlugins would not touch them.
 func DetectDrift(sourceDir, targetDir string) (*DriftReport, error) {
 	report := &DriftReport{
 		Source: sourceDir,
 		Target: targetDir,
 	}
 
+	lock, rig, err := targetLock(targetDir)
+	if err != nil {
+		return nil, err
+	}
+
 	srcEntries, err := os.ReadDir(sourceDir)
 	if err != nil {
 		return nil, fmt.Errorf("reading source: %w", err)
@@ -298,6 +337,11 @@ func DetectDrift(sourceDir, targetDir string) (*DriftReport, error) {
 		if _, err := os.Stat(filepath.Join(sourceDir, entry.Name(), "plugin.md")); err != nil {
 			continue
 		}
+		if lock.Get(rig, entry.Name()) != nil {
+			report.Pinned = append(report.Pinned, entry.Name())
+			delete(tgtPlugins, entry.Name())
+			continue
+		}
 
 		srcDir := filepath.Join(sourceDir, entry.Name())
 		dstDir := filepath.Join(targetDir, entry.Name())
@@ -320,6 +364,9 @@ func DetectDrift(sourceDir, targetDir string) (*DriftReport, error) {
 	}
 
 	for name := range tgtPlugins {
+		if lock.Get(rig, name) != nil {
+			continue
+		}
 		if _, err := os.Stat(filepath.Join(targetDir, name, "plugin.md")); err == nil {
 			report.Extra = append(report.Extra, name)
 		}
@@ -0,0 +1,177 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/eventbus"
+)
+
+// EventStartup is the event type an event gate uses to run once each time
+// the daemon starts. It is synthesized by the daemon, not published.
+const EventStartup = "startup"
+
+// Trigger is an event that fired an event-gated plugin. It is what run.sh
+// receives as JSON.
+type Trigger struct {
+	// Seq is the event bus sequence number (0 for synthesized events).
+	Seq uint64 `json:"seq,omitempty"`
+
+	// Topic is the bus topic the event was published on
+	// (e.g., activity.done, channel.refinery).
+	Topic string `json:"topic"`
+
+	// Type is the event type (e.g., done, MERGE_READY).
+	Type string `json:"type"`
+
+	// Time is when the event was published.
+	Time time.Time `json:"ts"`
+
+	// Event is the event as published: an activity event from
+	// ~/gt/.events.jsonl or a channel event.
+	Event json.RawMessage `json:"event"`
+
+	// Coalesced counts earlier matching events folded into this trigger
+	// by the gate's debounce window.
+	Coalesced int `json:"coalesced,omitempty"`
+}
+
+// Describe returns a one-line summary of the trigger for mail and logs.
+func (t *Trigger) Describe() string {
+	s := fmt.Sprintf("%s event", t.Type)
+	if t.Seq > 0 {
+		s += fmt.Sprintf(" #%d", t.Seq)
+	}
+	if t.Coalesced > 0 {
+		s += fmt.Sprintf(" (+%d coalesced)", t.Coalesced)
+	}
+	return s
+}
+
+// eventFields is the part of activity and channel events that gates look
+// at. Both carry a type and a payload; activity events add actor and source,
+// channel events add channel.
+type eventFields struct {
+	Type    string                 `json:"type"`
+	Actor   string                 `json:"actor"`
+	Source  string                 `json:"source"`
+	Channel string                 `json:"channel"`
+	Payload map[string]interface{} `json:"payload"`
+}
+
+// NewTrigger builds a trigger from an event bus message, or returns nil if
+// the message is not an event.
+func NewTrigger(m eventbus.Message) *Trigger {
+	var ev eventFields
+	if err := json.Unmarshal(m.Data, &ev); err != nil || ev.Type == "" {
+		return nil
+	}
+	return &Trigger{Seq: m.Seq, Topic: m.Topic, Type: ev.Type, Time: m.Time, Event: m.Data}
+}
+
+// Fires reports whether the event in t should trigger a plugin with this
+// gate: the gate must be an event gate, one of its On entries must name the
+// event type or match the topic, and every Match filter must hold.
+func (g *Gate) Fires(t *Trigger) bool {
+	if g == nil || g.Type != GateEvent || t == nil {
+		return false
+	}
+	on := false
+	for _, want := range g.On {
+		if want == t.Type || (strings.Contains(want, ".") && eventbus.Match(want, t.Topic)) {
+			on = true
+			break
+		}
+	}
+	if !on {
+		return false
+	}
+	if len(g.Match) == 0 {
+		return true
+	}
+
+	var ev eventFields
+	if err := json.Unmarshal(t.Event, &ev); err != nil {
+		return false
+	}
+	for key, pattern := range g.Match {
+		value, ok := ev.field(key)
+		if !ok {
+			return false
+		}
+		if matched, err := path.Match(pattern, value); err != nil || !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// field looks key up in the payload, then in the top-level fields.
+func (ev *eventFields) field(key string) (string, bool) {
+	if v, ok := ev.Payload[key]; ok && v != nil {
+		if s, ok := v.(string); ok {
+			return s, true
+		}
+		return fmt.Sprint(v), true
+	}
+	switch key {
+	case "type":
+		return ev.Type, true
+	case "actor":
+		return ev.Actor, ev.Actor != ""
+	case "source":
+		return ev.Source, ev.Source != ""
+	case "channel":
+		return ev.Channel, ev.Channel != ""
+	}
+	return "", false
+}
+
+// DebounceDuration parses the gate's debounce window (0 if unset).
+func (g *Gate) DebounceDuration() (time.Duration, error) {
+	if g == nil || g.Debounce == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(g.Debounce)
+	if err != nil {
+		return 0, fmt.Errorf("invalid debounce %q: %w", g.Debounce, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid debounce %q: must not be negative", g.Debounce)
+	}
+	return d, nil
+}
+
+// maxWaitFactor is the default MaxWait as a multiple of Debounce.
+const maxWaitFactor = 10
+
+// MaxWaitDuration parses the gate's max_wait, defaulting to ten times the
+// debounce window (0 if there is no debounce).
+func (g *Gate) MaxWaitDuration() (time.Duration, error) {
+	debounce, err := g.DebounceDuration()
+	if err != nil || debounce == 0 {
+		return 0, err
+	}
+	if g.MaxWait == "" {
+		return maxWaitFactor * debounce, nil
+	}
+	d, err := time.ParseDuration(g.MaxWait)
+	if err != nil {
+		return 0, fmt.Errorf("invalid max_wait %q: %w", g.MaxWait, err)
+	}
+	if d < debounce {
+		return 0, fmt.Errorf("invalid max_wait %q: must not be shorter than debounce %q", g.MaxWait, g.Debounce)
+	}
+	return d, nil
+}
+
+// ConcurrencyLimit returns how many dogs may run the plugin at once.
+func (g *Gate) ConcurrencyLimit() int {
+	if g == nil || g.MaxConcurrent <= 0 {
+		return 1
+	}
+	return g.MaxConcurrent
+}
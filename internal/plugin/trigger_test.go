@@ -0,0 +1,121 @@
+package plugin
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/eventbus"
+)
+
+func TestNewTrigger(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	trig := NewTrigger(eventbus.Message{
+		Seq:   7,
+		Topic: "activity.done",
+		Time:  ts,
+		Data:  json.RawMessage(`{"type":"done","actor":"gastown/polecats/nux","payload":{"bead":"gt-1"}}`),
+	})
+	if trig == nil || trig.Type != "done" || trig.Seq != 7 || !trig.Time.Equal(ts) {
+		t.Fatalf("NewTrigger = %+v", trig)
+	}
+	if NewTrigger(eventbus.Message{Topic: "activity.x", Data: json.RawMessage(`"not an event"`)}) != nil {
+		t.Error("expected nil trigger for non-event data")
+	}
+}
+
+func TestGate_Fires(t *testing.T) {
+	done := &Trigger{
+		Topic: "activity.done",
+		Type:  "done",
+		Event: json.RawMessage(`{"type":"done","actor":"gastown/polecats/nux","payload":{"bead":"gt-1","rig":"gastown","count":3}}`),
+	}
+	mergeReady := &Trigger{
+		Topic: "channel.refinery",
+		Type:  "MERGE_READY",
+		Event: json.RawMessage(`{"type":"MERGE_READY","channel":"refinery","payload":{"polecat":"nux"}}`),
+	}
+
+	tests := []struct {
+		name string
+		gate *Gate
+		trig *Trigger
+		want bool
+	}{
+		{"nil gate", nil, done, false},
+		{"cooldown gate", &Gate{Type: GateCooldown, On: EventList{"done"}}, done, false},
+		{"type match", &Gate{Type: GateEvent, On: EventList{"sling", "done"}}, done, true},
+		{"type mismatch", &Gate{Type: GateEvent, On: EventList{"sling"}}, done, false},
+		{"topic pattern", &Gate{Type: GateEvent, On: EventList{"channel.refinery"}}, mergeReady, true},
+		{"topic wildcard", &Gate{Type: GateEvent, On: EventList{"activity.*"}}, done, true},
+		{"channel event type", &Gate{Type: GateEvent, On: EventList{"MERGE_READY"}}, mergeReady, true},
+		{"payload match", &Gate{Type: GateEvent, On: EventList{"done"}, Match: map[string]string{"rig": "gastown"}}, done, true},
+		{"payload mismatch", &Gate{Type: GateEvent, On: EventList{"done"}, Match: map[string]string{"rig": "beads"}}, done, false},
+		{"missing key", &Gate{Type: GateEvent, On: EventList{"done"}, Match: map[string]string{"convoy": "*"}}, done, false},
+		{"actor glob", &Gate{Type: GateEvent, On: EventList{"done"}, Match: map[string]string{"actor": "gastown/polecats/*"}}, done, true},
+		{"number value", &Gate{Type: GateEvent, On: EventList{"done"}, Match: map[string]string{"count": "3"}}, done, true},
+		{"channel field", &Gate{Type: GateEvent, On: EventList{"MERGE_READY"}, Match: map[string]string{"channel": "refinery"}}, mergeReady, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.gate.Fires(tt.trig); got != tt.want {
+				t.Errorf("Fires = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGate_DebounceDurationInvalid(t *testing.T) {
+	if _, err := (&Gate{Debounce: "soon"}).DebounceDuration(); err == nil {
+		t.Error("expected error for invalid debounce")
+	}
+	if _, err := (&Gate{Debounce: "-1s"}).DebounceDuration(); err == nil {
+		t.Error("expected error for negative debounce")
+	}
+}
+
+func TestGate_MaxWaitDuration(t *testing.T) {
+	tests := []struct {
+		gate    Gate
+		want    time.Duration
+		wantErr bool
+	}{
+		{Gate{}, 0, false},
+		{Gate{MaxWait: "1m"}, 0, false},
+		{Gate{Debounce: "30s"}, 5 * time.Minute, false},
+		{Gate{Debounce: "30s", MaxWait: "2m"}, 2 * time.Minute, false},
+		{Gate{Debounce: "30s", MaxWait: "10s"}, 0, true},
+		{Gate{Debounce: "30s", MaxWait: "later"}, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := tt.gate.MaxWaitDuration()
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%+v: MaxWaitDuration = %v, %v; want %v (error %v)", tt.gate, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestFormatTriggeredMailBody(t *testing.T) {
+	trig := &Trigger{Seq: 42, Topic: "activity.convoy_closed", Type: "convoy_closed", Coalesced: 2,
+		Event: json.RawMessage(`{"type":"convoy_closed","payload":{"convoy":"hq-cv-1"}}`)}
+
+	script := &Plugin{Name: "notify", Path: "/gt/plugins/notify", HasRunScript: true}
+	body := script.FormatTriggeredMailBody(trig, "/gt/daemon/plugin-events/notify-1.json")
+	if !strings.Contains(body, "cd /gt/plugins/notify && GT_PLUGIN_EVENT=/gt/daemon/plugin-events/notify-1.json bash run.sh < /gt/daemon/plugin-events/notify-1.json") {
+		t.Errorf("script body missing event plumbing:\n%s", body)
+	}
+	body = script.FormatTriggeredMailBody(trig, "/my gt/daemon/plugin-events/notify-1.json")
+	if !strings.Contains(body, "GT_PLUGIN_EVENT='/my gt/daemon/plugin-events/notify-1.json' bash run.sh < '/my gt/daemon/plugin-events/notify-1.json'") {
+		t.Errorf("event file path not quoted:\n%s", body)
+	}
+	if !strings.Contains(body, "convoy_closed event #42 (+2 coalesced)") {
+		t.Errorf("script body missing trigger summary:\n%s", body)
+	}
+
+	agent := &Plugin{Name: "notify", Instructions: "Tell the mayor."}
+	body = agent.FormatTriggeredMailBody(trig, "/tmp/ev.json")
+	if !strings.Contains(body, "## Triggering Event") || !strings.Contains(body, `"hq-cv-1"`) {
+		t.Errorf("agent body missing event JSON:\n%s", body)
+	}
+}
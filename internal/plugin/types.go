@@ -9,8 +9,11 @@
 package plugin
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
 )
 
 // Plugin represents a discovered plugin definition.
@@ -42,6 +45,9 @@ type Plugin struct {
 	// Execution defines timeout and notification settings.
 	Execution *Execution `json:"execution,omitempty"`
 
+	// Permissions declares what the plugin may touch.
+	Permissions *Permissions `json:"permissions,omitempty"`
+
 	// Instructions is the markdown body (after frontmatter).
 	Instructions string `json:"instructions,omitempty"`
 
@@ -76,8 +82,57 @@ type Gate struct {
 	// Check is for condition gates (command that returns exit 0 to run).
 	Check string `json:"check,omitempty" toml:"check,omitempty"`
 
-	// On is for event gates (e.g., "startup").
-	On string `json:"on,omitempty" toml:"on,omitempty"`
+	// On is for event gates: the event types that trigger the plugin
+	// (e.g., "startup", "done", "convoy_closed", "MERGED"). An entry
+	// containing a dot is matched as an event bus topic pattern instead
+	// (e.g., "channel.refinery", "activity.*"). Accepts a string or a list.
+	On EventList `json:"on,omitempty" toml:"on,omitempty"`
+
+	// Match filters event gates by payload: every key must be present in
+	// the event payload (or its top-level fields such as actor) with a
+	// value matching the glob (e.g., rig = "gastown", actor = "*/witness").
+	Match map[string]string `json:"match,omitempty" toml:"match,omitempty"`
+
+	// Debounce coalesces bursts for event gates: the plugin is dispatched
+	// once the matching events have been quiet this long (e.g., "30s").
+	// Without it, every matching event is dispatched on its own.
+	Debounce string `json:"debounce,omitempty" toml:"debounce,omitempty"`
+
+	// MaxWait bounds how long Debounce may hold back the first event of a
+	// burst, so a steady stream of events still dispatches the plugin at
+	// least this often. Defaults to ten times Debounce.
+	MaxWait string `json:"max_wait,omitempty" toml:"max_wait,omitempty"`
+
+	// MaxConcurrent caps how many dogs may run this plugin at once for
+	// event gates. Zero means 1; triggers over the cap wait for a dog to
+	// finish.
+	MaxConcurrent int `json:"max_concurrent,omitempty" toml:"max_concurrent,omitempty"`
+}
+
+// EventList is the list of events an event gate triggers on.
+type EventList []string
+
+// UnmarshalTOML allows EventList to be decoded from either a single string
+// (on = "startup") or a list of strings.
+func (l *EventList) UnmarshalTOML(data any) error {
+	switch val := data.(type) {
+	case string:
+		*l = EventList{val}
+		return nil
+	case []any:
+		out := make(EventList, 0, len(val))
+		for _, v := range val {
+			s, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("expected string in event list, got %T", v)
+			}
+			out = append(out, s)
+		}
+		*l = out
+		return nil
+	default:
+		return fmt.Errorf("expected string or list for on, got %T", data)
+	}
 }
 
 // GateType is the type of gate that controls plugin execution.
@@ -93,7 +148,8 @@ const (
 	// GateCondition runs if a check command returns exit 0.
 	GateCondition GateType = "condition"
 
-	// GateEvent runs on specific events (startup, etc).
+	// GateEvent runs when a matching Gas Town event is published
+	// (startup, sling, done, convoy_closed, ...).
 	GateEvent GateType = "event"
 
 	// GateManual never auto-runs, must be triggered explicitly.
@@ -147,14 +203,41 @@ type Execution struct {
 	Wrapper []string `json:"wrapper,omitempty" toml:"wrapper,omitempty"`
 }
 
+// Permissions declares the rigs a plugin may touch and the commands it may
+// run. They are shown before install and in gt plugin show so a reviewer
+// knows what a third-party plugin will do; dogs are told to stay within them.
+type Permissions struct {
+	// Rigs the plugin may modify ("*" for any rig).
+	Rigs []string `json:"rigs,omitempty" toml:"rigs,omitempty"`
+
+	// Commands the plugin may run (e.g., "git", "gh", "bd").
+	Commands []string `json:"commands,omitempty" toml:"commands,omitempty"`
+}
+
+// Describe summarizes the permissions on one line, e.g.
+// "rigs: gastown; commands: git, gh". Undeclared fields read "none declared".
+func (p *Permissions) Describe() string {
+	list := func(items []string) string {
+		if len(items) == 0 {
+			return "none declared"
+		}
+		return strings.Join(items, ", ")
+	}
+	if p == nil {
+		return "rigs: none declared; commands: none declared"
+	}
+	return fmt.Sprintf("rigs: %s; commands: %s", list(p.Rigs), list(p.Commands))
+}
+
 // PluginFrontmatter represents the TOML frontmatter in plugin.md files.
 type PluginFrontmatter struct {
-	Name        string     `toml:"name"`
-	Description string     `toml:"description"`
-	Version     int        `toml:"version"`
-	Gate        *Gate      `toml:"gate,omitempty"`
-	Tracking    *Tracking  `toml:"tracking,omitempty"`
-	Execution   *Execution `toml:"execution,omitempty"`
+	Name        string       `toml:"name"`
+	Description string       `toml:"description"`
+	Version     int          `toml:"version"`
+	Gate        *Gate        `toml:"gate,omitempty"`
+	Tracking    *Tracking    `toml:"tracking,omitempty"`
+	Execution   *Execution   `toml:"execution,omitempty"`
+	Permissions *Permissions `toml:"permissions,omitempty"`
 }
 
 // IsExecWrapper returns true if this plugin is an exec-wrapper type.
@@ -211,18 +294,40 @@ func (p *Plugin) Summary() PluginSummary {
 // This is the canonical formatting used by both the daemon dispatcher
 // and the gt dog dispatch command.
 func (p *Plugin) FormatMailBody() string {
+	return p.formatMailBody(nil, "")
+}
+
+// FormatTriggeredMailBody formats the plugin for a dog worker dispatched by
+// an event gate. eventFile holds the trigger as JSON: run.sh receives it on
+// stdin and in $GT_PLUGIN_EVENT, and agent plugins get it inline.
+func (p *Plugin) FormatTriggeredMailBody(t *Trigger, eventFile string) string {
+	return p.formatMailBody(t, eventFile)
+}
+
+func (p *Plugin) formatMailBody(t *Trigger, eventFile string) string {
 	if p.HasRunScript {
+		run := "bash run.sh"
+		details := ""
+		if p.Permissions != nil {
+			details += fmt.Sprintf("**Permissions**: %s\n", p.Permissions.Describe())
+		}
+		if t != nil {
+			quoted := config.ShellQuote(eventFile)
+			run = fmt.Sprintf("GT_PLUGIN_EVENT=%s bash run.sh < %s", quoted, quoted)
+			details += fmt.Sprintf("**Triggered by**: %s\n", t.Describe())
+		}
 		return fmt.Sprintf(
 			"Execute the following plugin script:\n\n"+
 				"**Plugin**: %s\n"+
-				"**Description**: %s\n\n"+
-				"```bash\ncd %s && bash run.sh\n```\n\n"+
+				"**Description**: %s\n"+
+				"%s\n"+
+				"```bash\ncd %s && %s\n```\n\n"+
 				"Run this command EXACTLY. Do NOT interpret the plugin.md instructions.\n"+
 				"Do NOT write your own implementation. Just run the script and report the output.\n\n"+
 				"After completion:\n"+
 				"1. Create a wisp to record the result (success/failure)\n"+
 				"2. Run `gt dog done` — this clears your work and auto-terminates the session\n",
-			p.Name, p.Description, p.Path)
+			p.Name, p.Description, details, p.Path, run)
 	}
 
 	var sb strings.Builder
@@ -236,9 +341,24 @@ func (p *Plugin) FormatMailBody() string {
 	if p.Execution != nil && p.Execution.Timeout != "" {
 		sb.WriteString(fmt.Sprintf("**Timeout**: %s\n", p.Execution.Timeout))
 	}
+	if p.Permissions != nil {
+		sb.WriteString(fmt.Sprintf("**Permissions**: %s (do not touch other rigs or run other commands)\n", p.Permissions.Describe()))
+	}
+	if t != nil {
+		sb.WriteString(fmt.Sprintf("**Triggered by**: %s\n", t.Describe()))
+	}
 	sb.WriteString("\n---\n\n")
 	sb.WriteString("## Instructions\n\n")
 	sb.WriteString(p.Instructions)
+	if t != nil {
+		sb.WriteString("\n\n## Triggering Event\n\n")
+		sb.WriteString(fmt.Sprintf("Also saved to %s.\n\n", eventFile))
+		if data, err := json.MarshalIndent(t, "", "  "); err == nil {
+			sb.WriteString("```json\n")
+			sb.Write(data)
+			sb.WriteString("\n```")
+		}
+	}
 	sb.WriteString("\n\n---\n\n")
 	sb.WriteString("After completion:\n")
 	sb.WriteString("1. Create a wisp to record the result (success/failure)\n")
@@ -40,14 +40,15 @@ var (
 
 // SessionManager handles polecat session lifecycle.
 type SessionManager struct {
-	tmux *tmux.Tmux
+	tmux session.SessionBackend
 	rig  *rig.Rig
 }
 
 // NewSessionManager creates a new polecat session manager for a rig.
+// Sessions run on t unless the town's settings select another session backend.
 func NewSessionManager(t *tmux.Tmux, r *rig.Rig) *SessionManager {
 	return &SessionManager{
-		tmux: t,
+		tmux: session.ResolveBackend(filepath.Dir(r.Path), t),
 		rig:  r,
 	}
 }
@@ -413,17 +414,23 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 
 	// Apply theme (non-fatal)
 	theme := tmux.ResolveSessionTheme(townRoot, m.rig.Name, "polecat")
-	debugSession("ConfigureGasTownSession", m.tmux.ConfigureGasTownSession(sessionID, theme, m.rig.Name, polecat, "polecat"))
+	if themer, ok := m.tmux.(session.Themer); ok {
+		debugSession("ConfigureGasTownSession", themer.ConfigureGasTownSession(sessionID, theme, m.rig.Name, polecat, "polecat"))
+	}
 
 	// Set pane-died hook for crash detection (non-fatal)
 	agentID := fmt.Sprintf("%s/%s", m.rig.Name, polecat)
-	debugSession("SetPaneDiedHook", m.tmux.SetPaneDiedHook(sessionID, agentID))
+	if hooker, ok := m.tmux.(session.PaneDiedHooker); ok {
+		debugSession("SetPaneDiedHook", hooker.SetPaneDiedHook(sessionID, agentID))
+	}
 
 	// Wait for Claude to start (non-fatal)
 	debugSession("WaitForCommand", m.tmux.WaitForCommand(sessionID, constants.SupportedShells, constants.ClaudeStartTimeout))
 
 	// Accept startup dialogs (workspace trust + bypass permissions) if they appear
-	debugSession("AcceptStartupDialogs", m.tmux.AcceptStartupDialogs(sessionID))
+	if acceptor, ok := m.tmux.(session.DialogAcceptor); ok {
+		debugSession("AcceptStartupDialogs", acceptor.AcceptStartupDialogs(sessionID))
+	}
 
 	// Wait for runtime to be fully ready at the prompt (not just started).
 	// Uses prompt-based polling for agents with ReadyPromptPrefix (e.g., Claude "❯ "),
@@ -496,7 +503,7 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 
 	// Stream polecat's Claude Code JSONL conversation log to VictoriaLogs (opt-in).
 	if os.Getenv("GT_LOG_AGENT_OUTPUT") == "true" && os.Getenv("GT_OTEL_LOGS_URL") != "" {
-		if err := session.ActivateAgentLogging(sessionID, workDir, runID); err != nil {
+		if err := session.ActivateAgentLogging(m.tmux, sessionID, workDir, runID); err != nil {
 			// Non-fatal: observability failure must never block agent startup.
 			debugSession("ActivateAgentLogging", err)
 		}
@@ -668,7 +675,11 @@ func (m *SessionManager) Attach(polecat string) error {
 		return ErrSessionNotFound
 	}
 
-	return m.tmux.AttachSession(sessionID)
+	attacher, ok := m.tmux.(session.Attacher)
+	if !ok {
+		return fmt.Errorf("session backend does not support attach; use 'gt peek' to view output")
+	}
+	return attacher.AttachSession(sessionID)
 }
 
 // Capture returns the recent output from a polecat session.
@@ -0,0 +1,245 @@
+package proxy
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// certStoreRetention is how long a record is kept after its certificate
+// expires. An expired certificate fails chain verification on its own, so
+// its revocation no longer needs to be enforced.
+const certStoreRetention = 7 * 24 * time.Hour
+
+// CertRecord describes a polecat certificate issued by the server, or a
+// revoked serial the server did not issue itself.
+type CertRecord struct {
+	// Serial is the certificate serial number in lowercase hexadecimal.
+	Serial string `json:"serial"`
+	// CN is the certificate Common Name (e.g. "gt-gastown-rust").
+	// Empty for serials revoked without the server having issued them.
+	CN        string    `json:"cn,omitempty"`
+	NotBefore time.Time `json:"not_before,omitzero"`
+	NotAfter  time.Time `json:"not_after,omitzero"`
+	// RenewedFrom is the serial of the certificate this one replaced, if it
+	// was issued by /v1/cert/renew.
+	RenewedFrom string `json:"renewed_from,omitempty"`
+	// RevokedAt is when the serial was added to the deny list.
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Status returns "revoked", "expired" or "valid" as of now.
+func (r CertRecord) Status(now time.Time) string {
+	switch {
+	case r.RevokedAt != nil:
+		return "revoked"
+	case !r.NotAfter.IsZero() && now.After(r.NotAfter):
+		return "expired"
+	default:
+		return "valid"
+	}
+}
+
+// CertStore is a thread-safe registry of issued polecat certificates and
+// revocations, persisted as JSON so that revocations survive restarts.
+// A store with an empty path is kept in memory only.
+type CertStore struct {
+	mu    sync.Mutex
+	path  string
+	certs map[string]*CertRecord
+}
+
+// Persistent reports whether the store is saved to disk.
+func (s *CertStore) Persistent() bool {
+	return s.path != ""
+}
+
+// certStoreFile is the on-disk layout of a CertStore.
+type certStoreFile struct {
+	Certs []*CertRecord `json:"certs"`
+}
+
+// OpenCertStore loads the store at path, or returns an empty store if the
+// file does not exist yet. Records whose certificates expired more than
+// certStoreRetention ago are dropped.
+func OpenCertStore(path string) (*CertStore, error) {
+	s := &CertStore{path: path, certs: make(map[string]*CertRecord)}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // path is from trusted server config
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read cert store: %w", err)
+	}
+	var f certStoreFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse cert store %s: %w", path, err)
+	}
+
+	cutoff := time.Now().Add(-certStoreRetention)
+	for _, rec := range f.Certs {
+		if rec == nil || rec.Serial == "" {
+			continue
+		}
+		if !rec.NotAfter.IsZero() && rec.NotAfter.Before(cutoff) {
+			continue
+		}
+		s.certs[rec.Serial] = rec
+	}
+	return s, nil
+}
+
+// ErrRenewedFromRevoked is returned by Add for a renewal of a certificate
+// that has been revoked. The renewal is recorded as revoked.
+var ErrRenewedFromRevoked = errors.New("certificate was renewed from a revoked certificate")
+
+// Add records an issued certificate. renewedFrom is the serial of the
+// certificate it replaces, or nil. A renewal of a revoked certificate (one
+// that raced its revocation) is recorded as revoked, and Add returns
+// ErrRenewedFromRevoked.
+func (s *CertStore) Add(leaf *x509.Certificate, renewedFrom *big.Int) error {
+	rec := &CertRecord{
+		Serial:    leaf.SerialNumber.Text(16),
+		CN:        leaf.Subject.CommonName,
+		NotBefore: leaf.NotBefore.UTC(),
+		NotAfter:  leaf.NotAfter.UTC(),
+	}
+	if renewedFrom != nil {
+		rec.RenewedFrom = renewedFrom.Text(16)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var parentErr error
+	if parent := s.certs[rec.RenewedFrom]; rec.RenewedFrom != "" && parent != nil && parent.RevokedAt != nil {
+		at := time.Now().UTC()
+		rec.RevokedAt = &at
+		parentErr = ErrRenewedFromRevoked
+	}
+	s.certs[rec.Serial] = rec
+	if err := s.saveLocked(); err != nil {
+		return err
+	}
+	return parentErr
+}
+
+// Revoke marks a serial as revoked, together with every certificate renewed
+// from it (directly or through earlier renewals), and persists it. Revoking
+// a serial that the store has no record of creates a bare record, so that
+// certificates issued offline (e.g. directly from the CA) can still be
+// revoked. Revoking an already-revoked serial keeps its revocation time.
+func (s *CertStore) Revoke(serial *big.Int, at time.Time) error {
+	key := serial.Text(16)
+	at = at.UTC()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.certs[key] == nil {
+		s.certs[key] = &CertRecord{Serial: key}
+	}
+	changed := false
+	for _, rec := range s.renewalChainLocked(key) {
+		if rec.RevokedAt == nil {
+			rec.RevokedAt = &at
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return s.saveLocked()
+}
+
+// renewalChainLocked returns the record for key and the records of every
+// certificate renewed from it, transitively. Callers must hold s.mu.
+func (s *CertStore) renewalChainLocked(key string) []*CertRecord {
+	chain := []*CertRecord{s.certs[key]}
+	seen := map[string]bool{key: true}
+	for i := 0; i < len(chain); i++ {
+		for _, rec := range s.certs {
+			if rec.RenewedFrom == chain[i].Serial && !seen[rec.Serial] {
+				seen[rec.Serial] = true
+				chain = append(chain, rec)
+			}
+		}
+	}
+	return chain
+}
+
+// Revoked returns the serial numbers of all revoked certificates.
+func (s *CertStore) Revoked() []*big.Int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []*big.Int
+	for key, rec := range s.certs {
+		if rec.RevokedAt == nil {
+			continue
+		}
+		if serial, ok := new(big.Int).SetString(key, 16); ok {
+			out = append(out, serial)
+		}
+	}
+	return out
+}
+
+// List returns a copy of every record, oldest first.
+func (s *CertStore) List() []CertRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]CertRecord, 0, len(s.certs))
+	for _, rec := range s.certs {
+		out = append(out, *rec)
+	}
+	sortCertRecords(out)
+	return out
+}
+
+// saveLocked writes the store to disk atomically. Callers must hold s.mu.
+func (s *CertStore) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+	f := certStoreFile{Certs: make([]*CertRecord, 0, len(s.certs))}
+	for _, rec := range s.certs {
+		f.Certs = append(f.Certs, rec)
+	}
+	sort.Slice(f.Certs, func(i, j int) bool { return f.Certs[i].Serial < f.Certs[j].Serial })
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode cert store: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("create cert store dir: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("write cert store: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("rename cert store: %w", err)
+	}
+	return nil
+}
+
+// sortCertRecords orders records by issue time, with bare revocation
+// records (no issue time) first.
+func sortCertRecords(recs []CertRecord) {
+	sort.Slice(recs, func(i, j int) bool {
+		if !recs[i].NotBefore.Equal(recs[j].NotBefore) {
+			return recs[i].NotBefore.Before(recs[j].NotBefore)
+		}
+		return recs[i].Serial < recs[j].Serial
+	})
+}
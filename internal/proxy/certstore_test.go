@@ -0,0 +1,129 @@
+package proxy
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLeaf(serial int64, cn string, notAfter time.Time) *x509.Certificate {
+	return &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    notAfter.Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+}
+
+func TestCertStore(t *testing.T) {
+	t.Run("missing file returns empty store", func(t *testing.T) {
+		s, err := OpenCertStore(filepath.Join(t.TempDir(), "certs.json"))
+		require.NoError(t, err)
+		assert.Empty(t, s.List())
+		assert.Empty(t, s.Revoked())
+	})
+
+	t.Run("issued certs and revocations survive reopen", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "ca", "certs.json")
+		s, err := OpenCertStore(path)
+		require.NoError(t, err)
+
+		expiry := time.Now().Add(time.Hour)
+		require.NoError(t, s.Add(testLeaf(0xa1, "gt-gastown-alice", expiry), nil))
+		require.NoError(t, s.Add(testLeaf(0xb2, "gt-gastown-alice", expiry), big.NewInt(0xa1)))
+		require.NoError(t, s.Revoke(big.NewInt(0xa1), time.Now()))
+		// Revoking a serial the store never issued is recorded too.
+		require.NoError(t, s.Revoke(big.NewInt(0xc3), time.Now()))
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		reopened, err := OpenCertStore(path)
+		require.NoError(t, err)
+		recs := reopened.List()
+		require.Len(t, recs, 3)
+
+		byserial := make(map[string]CertRecord)
+		for _, r := range recs {
+			byserial[r.Serial] = r
+		}
+		assert.Equal(t, "revoked", byserial["a1"].Status(time.Now()))
+		assert.Equal(t, "revoked", byserial["b2"].Status(time.Now()), "renewal revoked with its predecessor")
+		assert.Equal(t, "a1", byserial["b2"].RenewedFrom)
+		assert.Equal(t, "gt-gastown-alice", byserial["b2"].CN)
+		assert.Empty(t, byserial["c3"].CN)
+		assert.ElementsMatch(t, []string{"a1", "b2", "c3"}, serialsHex(reopened.Revoked()))
+	})
+
+	t.Run("revocation cascades down the renewal chain", func(t *testing.T) {
+		s, err := OpenCertStore("")
+		require.NoError(t, err)
+		expiry := time.Now().Add(time.Hour)
+		require.NoError(t, s.Add(testLeaf(1, "gt-gastown-bob", expiry), nil))
+		require.NoError(t, s.Add(testLeaf(2, "gt-gastown-bob", expiry), big.NewInt(1)))
+		require.NoError(t, s.Add(testLeaf(3, "gt-gastown-bob", expiry), big.NewInt(2)))
+		require.NoError(t, s.Add(testLeaf(4, "gt-gastown-carol", expiry), nil))
+
+		require.NoError(t, s.Revoke(big.NewInt(2), time.Now()))
+		assert.ElementsMatch(t, []string{"2", "3"}, serialsHex(s.Revoked()))
+
+		// A renewal that raced the revocation is revoked on arrival.
+		err = s.Add(testLeaf(5, "gt-gastown-bob", expiry), big.NewInt(3))
+		require.ErrorIs(t, err, ErrRenewedFromRevoked)
+		assert.ElementsMatch(t, []string{"2", "3", "5"}, serialsHex(s.Revoked()))
+	})
+
+	t.Run("revoking twice keeps the first revocation time", func(t *testing.T) {
+		s, err := OpenCertStore("")
+		require.NoError(t, err)
+		first := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		require.NoError(t, s.Revoke(big.NewInt(7), first))
+		require.NoError(t, s.Revoke(big.NewInt(7), first.Add(time.Hour)))
+		recs := s.List()
+		require.Len(t, recs, 1)
+		assert.True(t, recs[0].RevokedAt.Equal(first))
+	})
+
+	t.Run("long-expired records are dropped on open", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "certs.json")
+		old := time.Now().Add(-certStoreRetention - time.Hour)
+		recent := time.Now().Add(-time.Hour)
+		data, err := json.Marshal(certStoreFile{Certs: []*CertRecord{
+			{Serial: "1", CN: "gt-gastown-old", NotAfter: old},
+			{Serial: "2", CN: "gt-gastown-recent", NotAfter: recent},
+		}})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, data, 0600))
+
+		s, err := OpenCertStore(path)
+		require.NoError(t, err)
+		recs := s.List()
+		require.Len(t, recs, 1)
+		assert.Equal(t, "2", recs[0].Serial)
+		assert.Equal(t, "expired", recs[0].Status(time.Now()))
+	})
+
+	t.Run("corrupt file is an error", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "certs.json")
+		require.NoError(t, os.WriteFile(path, []byte("{not json"), 0600))
+		_, err := OpenCertStore(path)
+		assert.Error(t, err)
+	})
+}
+
+func serialsHex(serials []*big.Int) []string {
+	out := make([]string, len(serials))
+	for i, s := range serials {
+		out[i] = s.Text(16)
+	}
+	return out
+}
@@ -10,7 +10,9 @@ import (
 // which is unique per RFC 5280 within a single CA's issued certificates.
 //
 // The deny list is checked during the TLS handshake via VerifyPeerCertificate.
-// Entries are never removed (TTLs are short; the whole CA rotates periodically).
+// Entries are never removed while the server runs. The deny list itself is not
+// persisted: Server seeds it from its CertStore at startup, which records every
+// revocation and drops it once the revoked cert has long expired.
 type DenyList struct {
 	mu     sync.RWMutex
 	denied map[string]bool
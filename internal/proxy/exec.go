@@ -66,6 +66,25 @@ func (s *Server) handleExec(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Argument-level policy: identity, positional args and flags.
+	if s.cfg.Policy != nil {
+		d := s.cfg.Policy.Evaluate(PolicyRequest{
+			Identity: identity,
+			Argv:     req.Argv,
+			Prefix:   s.rigPrefix(identity),
+		})
+		// Audit rules are opt-in per command, so log the (truncated) argv.
+		for _, rule := range d.Audit {
+			s.log.Info("exec audit", "rule", rule, "identity", identity, "argv", argvForLog(req.Argv))
+		}
+		if d.Effect == EffectDeny {
+			s.log.Warn("exec denied by policy", "rule", d.Rule, "identity", identity,
+				"cmd", cmd0, "sub", subForLog(req.Argv))
+			writeDenied(w, d)
+			return
+		}
+	}
+
 	// Build argv as a copy of req.Argv to avoid mutating the decoded request.
 	argv := append([]string(nil), req.Argv...)
 	// Use the resolved absolute binary path to prevent PATH hijacking after startup.
@@ -124,6 +143,58 @@ func (s *Server) handleExec(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// deniedResponse is the JSON body of a 403 from the exec policy, so clients
+// can tell the user which rule denied the command and why.
+type deniedResponse struct {
+	Error  string `json:"error"`
+	Rule   string `json:"rule,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func writeDenied(w http.ResponseWriter, d Decision) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	_ = json.NewEncoder(w).Encode(deniedResponse{
+		Error:  "denied by policy",
+		Rule:   d.Rule,
+		Reason: d.Reason,
+	})
+}
+
+// rigPrefix returns the beads prefix of the identity's rig, or "" if the
+// identity is empty.
+func (s *Server) rigPrefix(identity string) string {
+	rig, _, ok := strings.Cut(identity, "/")
+	if !ok {
+		return ""
+	}
+	if s.cfg.RigPrefix != nil {
+		return s.cfg.RigPrefix(rig)
+	}
+	return rig
+}
+
+// argvForLog returns argv with each element truncated like subForLog and at
+// most 32 elements, for audit logging.
+func argvForLog(argv []string) []string {
+	const maxArgs = 32
+	n := len(argv)
+	if n > maxArgs {
+		n = maxArgs
+	}
+	out := make([]string, n)
+	for i := range out {
+		out[i] = argv[i]
+		if len(out[i]) > 128 {
+			out[i] = out[i][:128] + "..."
+		}
+	}
+	if len(argv) > maxArgs {
+		out = append(out, fmt.Sprintf("... (%d more)", len(argv)-maxArgs))
+	}
+	return out
+}
+
 // subForLog returns a truncated argv[1] if present, otherwise "".
 // Used for audit logging to capture the subcommand without logging full argv.
 // Truncates to 128 bytes to prevent oversized log lines from exceeding
@@ -0,0 +1,452 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Effect is the outcome of a policy rule.
+type Effect string
+
+const (
+	// EffectAllow permits the command; later rules are not evaluated.
+	EffectAllow Effect = "allow"
+	// EffectDeny rejects the command with the rule's reason; later rules are
+	// not evaluated.
+	EffectDeny Effect = "deny"
+	// EffectAudit logs the command and continues with the next rule.
+	EffectAudit Effect = "audit"
+)
+
+// Policy is an argument-level exec policy, evaluated by handleExec after the
+// AllowedCommands and AllowedSubcommands checks. Rules are evaluated in order:
+// the first matching allow or deny rule decides, audit rules only log. If no
+// allow or deny rule matches, Default decides.
+//
+// Patterns are globs where "*" matches any run of characters (including "/")
+// and "?" matches one character. A leading "!" negates a pattern. Patterns
+// may reference the caller's identity: ${rig}, ${name}, ${identity}
+// ("<rig>/<name>") and ${prefix} (the rig's beads prefix).
+type Policy struct {
+	// Default is the effect when no allow or deny rule matches: "allow"
+	// (the default) or "deny".
+	Default Effect `json:"default,omitempty"`
+	// ValueFlags lists flags that take their value as a separate argument
+	// ("--status closed"), without dashes. Their values are not treated as
+	// positional arguments. "--flag=value" is always understood. Any other
+	// flag followed by a non-flag token is ambiguous: rules are matched
+	// both with and without the token as its value, and fail closed.
+	ValueFlags []string `json:"value_flags,omitempty"`
+	// Rules are evaluated in order.
+	Rules []PolicyRule `json:"rules"`
+	// Tests are example requests and their expected effect, run by
+	// "gt-proxy-server policy test".
+	Tests []PolicyTest `json:"tests,omitempty"`
+}
+
+// PolicyRule matches a request by identity, positional arguments and flags.
+// Every non-empty field must match for the rule to apply.
+type PolicyRule struct {
+	// Name identifies the rule in deny responses and logs.
+	Name   string `json:"name"`
+	Effect Effect `json:"effect"`
+	// Identity is a pattern matched against "<rig>/<name>" from the client
+	// cert CN (e.g. "gastown/*").
+	Identity string `json:"identity,omitempty"`
+	// Argv are patterns matched position by position against the leading
+	// positional arguments, starting with the command ("bd", "update", ...).
+	// There must be at least as many positional arguments as patterns.
+	Argv []string `json:"argv,omitempty"`
+	// AnyArg is a pattern matched against the positional arguments after
+	// those covered by Argv; it matches if any of them does.
+	AnyArg string `json:"any_arg,omitempty"`
+	// Flags maps flag names (without dashes; alternatives separated by "|",
+	// e.g. "status|s") to value patterns. A flag matches if any occurrence
+	// has a matching value, given as "--flag=value" or "--flag value".
+	// An empty pattern matches any occurrence of the flag.
+	Flags map[string]string `json:"flags,omitempty"`
+	// Exact requires the positional arguments to be exactly those covered
+	// by Argv, and every flag to be named in Flags or AllowedFlags. Without
+	// it, a rule ignores extra arguments and unlisted flags, which an allow
+	// rule should rarely do.
+	Exact bool `json:"exact,omitempty"`
+	// AllowedFlags lists further flags (without dashes; "a|b" for
+	// alternatives) an Exact rule accepts with any value.
+	AllowedFlags []string `json:"allowed_flags,omitempty"`
+	// Reason is the human-readable explanation returned when the rule denies.
+	Reason string `json:"reason,omitempty"`
+}
+
+// PolicyTest is an example request with its expected effect.
+type PolicyTest struct {
+	// As is the caller identity, "<rig>/<name>".
+	As     string   `json:"as"`
+	Argv   []string `json:"argv"`
+	Expect Effect   `json:"expect"`
+}
+
+// PolicyRequest is the input to Policy.Evaluate.
+type PolicyRequest struct {
+	// Identity is "<rig>/<name>", or "" if the client cert CN is not a
+	// polecat CN.
+	Identity string
+	Argv     []string
+	// Prefix is the beads prefix of the caller's rig, for ${prefix}.
+	Prefix string
+}
+
+// Decision is the result of evaluating a policy.
+type Decision struct {
+	// Effect is EffectAllow or EffectDeny.
+	Effect Effect `json:"effect"`
+	// Rule is the name of the deciding rule, or "" if Default applied.
+	Rule   string `json:"rule,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	// Audit lists the audit rules that matched before the decision.
+	Audit []string `json:"audit,omitempty"`
+}
+
+// LoadPolicy reads and validates a policy file.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is from trusted server config
+	if err != nil {
+		return nil, err
+	}
+	var p Policy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse policy %s: %w", path, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("policy %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// Validate checks effects and patterns, and names unnamed rules after their
+// position ("rule 3").
+func (p *Policy) Validate() error {
+	switch p.Default {
+	case "":
+		p.Default = EffectAllow
+	case EffectAllow, EffectDeny:
+	default:
+		return fmt.Errorf("default must be %q or %q, got %q", EffectAllow, EffectDeny, p.Default)
+	}
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		switch r.Effect {
+		case EffectAllow, EffectDeny, EffectAudit:
+		default:
+			return fmt.Errorf("%s: effect must be allow, deny or audit, got %q", r.Name, r.Effect)
+		}
+		for _, pat := range append(append([]string{r.Identity, r.AnyArg}, r.Argv...), flagPatterns(r.Flags)...) {
+			if err := validatePattern(pat); err != nil {
+				return fmt.Errorf("%s: %w", r.Name, err)
+			}
+		}
+		if len(r.AllowedFlags) > 0 && !r.Exact {
+			return fmt.Errorf("%s: allowed_flags requires exact", r.Name)
+		}
+		for _, name := range append(slices.Collect(maps.Keys(r.Flags)), r.AllowedFlags...) {
+			for _, alt := range strings.Split(name, "|") {
+				if alt == "" || strings.HasPrefix(alt, "-") {
+					return fmt.Errorf("%s: invalid flag name %q (omit the dashes)", r.Name, name)
+				}
+			}
+		}
+	}
+	for i, tc := range p.Tests {
+		switch tc.Expect {
+		case EffectAllow, EffectDeny:
+		default:
+			return fmt.Errorf("test %d: expect must be allow or deny, got %q", i+1, tc.Expect)
+		}
+	}
+	return nil
+}
+
+func flagPatterns(flags map[string]string) []string {
+	out := make([]string, 0, len(flags))
+	for _, v := range flags {
+		out = append(out, v)
+	}
+	return out
+}
+
+// validatePattern rejects patterns with unknown ${...} variables.
+func validatePattern(pat string) error {
+	rest := pat
+	for {
+		i := strings.Index(rest, "${")
+		if i < 0 {
+			return nil
+		}
+		j := strings.Index(rest[i:], "}")
+		if j < 0 {
+			return fmt.Errorf("unterminated variable in pattern %q", pat)
+		}
+		switch rest[i+2 : i+j] {
+		case "rig", "name", "identity", "prefix":
+		default:
+			return fmt.Errorf("unknown variable %q in pattern %q", rest[i:i+j+1], pat)
+		}
+		rest = rest[i+j+1:]
+	}
+}
+
+// Evaluate decides whether the request is allowed.
+func (p *Policy) Evaluate(req PolicyRequest) Decision {
+	parses := p.parseArgs(req.Argv)
+	vars := policyVars(req)
+
+	var audit []string
+	for _, r := range p.Rules {
+		if !r.applies(req.Identity, parses, vars) {
+			continue
+		}
+		if r.Effect == EffectAudit {
+			audit = append(audit, r.Name)
+			continue
+		}
+		return Decision{Effect: r.Effect, Rule: r.Name, Reason: r.Reason, Audit: audit}
+	}
+	d := Decision{Effect: p.Default, Audit: audit}
+	if d.Effect == "" {
+		d.Effect = EffectAllow
+	}
+	if d.Effect == EffectDeny {
+		d.Reason = "no policy rule allows this command"
+	}
+	return d
+}
+
+// parsedArgs is argv split into positional arguments and flag names, plus
+// the raw tokens for flag matching.
+type parsedArgs struct {
+	positional []string
+	// flags are the names of the flags given, without dashes or values.
+	flags []string
+	// tokens is the raw argv, so "--flag value" can be matched even if the
+	// flag is not in ValueFlags.
+	tokens []string
+}
+
+// maxAmbiguousFlags bounds how many ambiguous flags parseArgs expands into
+// alternative parses; later ones are read as taking no value.
+const maxAmbiguousFlags = 6
+
+// parseArgs splits argv into positional arguments and flags. A flag that is
+// not in ValueFlags, given without "=" and followed by a non-flag token, may
+// or may not take that token as its value, so every combination is
+// returned: the first parse reads all such flags as taking no value.
+func (p *Policy) parseArgs(argv []string) []parsedArgs {
+	valueFlags := make(map[string]bool, len(p.ValueFlags))
+	for _, f := range p.ValueFlags {
+		valueFlags[strings.TrimLeft(f, "-")] = true
+	}
+
+	// ambiguous[i] is the bit for the flag at argv[i] in the parse mask.
+	ambiguous := make(map[int]int)
+	for i, tok := range argv {
+		if tok == "--" {
+			break
+		}
+		if !isFlag(tok) || i+1 >= len(argv) || isFlag(argv[i+1]) || argv[i+1] == "--" {
+			continue
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(tok, "-"), "=")
+		if !hasValue && !valueFlags[name] && len(ambiguous) < maxAmbiguousFlags {
+			ambiguous[i] = len(ambiguous)
+		}
+	}
+
+	parses := make([]parsedArgs, 0, 1<<len(ambiguous))
+	for mask := 0; mask < 1<<len(ambiguous); mask++ {
+		pa := parsedArgs{tokens: argv}
+		for i := 0; i < len(argv); i++ {
+			tok := argv[i]
+			if tok == "--" {
+				pa.positional = append(pa.positional, argv[i+1:]...)
+				break
+			}
+			if isFlag(tok) {
+				name, _, hasValue := strings.Cut(strings.TrimLeft(tok, "-"), "=")
+				pa.flags = append(pa.flags, name)
+				bit, amb := ambiguous[i]
+				if !hasValue && (valueFlags[name] || amb && mask&(1<<bit) != 0) {
+					i++ // skip the flag's value
+				}
+				continue
+			}
+			pa.positional = append(pa.positional, tok)
+		}
+		parses = append(parses, pa)
+	}
+	return parses
+}
+
+// isFlag reports whether tok is a flag ("-x", "--name", "--name=value").
+func isFlag(tok string) bool {
+	return len(tok) > 1 && strings.HasPrefix(tok, "-")
+}
+
+// flagValues returns the values of every occurrence of the named flag (any
+// of the "|"-separated alternatives). A flag given without "=" contributes
+// the following token, if any, and also "" so that bare flags match an
+// empty pattern.
+func (pa parsedArgs) flagValues(names string) []string {
+	alts := strings.Split(names, "|")
+	var values []string
+	for i, tok := range pa.tokens {
+		if tok == "--" {
+			break
+		}
+		if len(tok) < 2 || tok[0] != '-' {
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(tok, "-"), "=")
+		if !slices.Contains(alts, name) {
+			continue
+		}
+		if hasValue {
+			values = append(values, value)
+			continue
+		}
+		values = append(values, "")
+		if i+1 < len(pa.tokens) {
+			values = append(values, pa.tokens[i+1])
+		}
+	}
+	return values
+}
+
+// applies reports whether the rule matches the request under the parses of
+// its argv. It fails closed: deny and audit rules apply if any parse
+// matches, allow rules only if every parse does.
+func (r PolicyRule) applies(identity string, parses []parsedArgs, vars map[string]string) bool {
+	for _, args := range parses {
+		matched := r.matches(identity, args, vars)
+		if r.Effect == EffectAllow && !matched {
+			return false
+		}
+		if r.Effect != EffectAllow && matched {
+			return true
+		}
+	}
+	return r.Effect == EffectAllow
+}
+
+func (r PolicyRule) matches(identity string, args parsedArgs, vars map[string]string) bool {
+	if r.Identity != "" && !matchPattern(r.Identity, identity, vars) {
+		return false
+	}
+	if len(args.positional) < len(r.Argv) {
+		return false
+	}
+	if r.Exact && (len(args.positional) > len(r.Argv) || !r.flagsAllowed(args.flags)) {
+		return false
+	}
+	for i, pat := range r.Argv {
+		if !matchPattern(pat, args.positional[i], vars) {
+			return false
+		}
+	}
+	if r.AnyArg != "" {
+		found := false
+		for _, arg := range args.positional[len(r.Argv):] {
+			if matchPattern(r.AnyArg, arg, vars) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for name, pat := range r.Flags {
+		found := false
+		for _, v := range args.flagValues(name) {
+			if pat == "" || matchPattern(pat, v, vars) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// flagsAllowed reports whether every flag is named in Flags or AllowedFlags.
+func (r PolicyRule) flagsAllowed(flags []string) bool {
+	allowed := make(map[string]bool)
+	for _, names := range append(slices.Collect(maps.Keys(r.Flags)), r.AllowedFlags...) {
+		for _, alt := range strings.Split(names, "|") {
+			allowed[alt] = true
+		}
+	}
+	for _, f := range flags {
+		if !allowed[f] {
+			return false
+		}
+	}
+	return true
+}
+
+func policyVars(req PolicyRequest) map[string]string {
+	rig, name, _ := strings.Cut(req.Identity, "/")
+	return map[string]string{
+		"rig":      rig,
+		"name":     name,
+		"identity": req.Identity,
+		"prefix":   req.Prefix,
+	}
+}
+
+// matchPattern reports whether s matches pat after expanding variables.
+// A leading "!" negates the match.
+func matchPattern(pat, s string, vars map[string]string) bool {
+	negate := strings.HasPrefix(pat, "!")
+	if negate {
+		pat = pat[1:]
+	}
+	for k, v := range vars {
+		pat = strings.ReplaceAll(pat, "${"+k+"}", v)
+	}
+	return globMatch(pat, s) != negate
+}
+
+// globMatch matches s against a glob where "*" matches any run of
+// characters (including "/") and "?" matches exactly one.
+func globMatch(pat, s string) bool {
+	// Iterative matcher with single-star backtracking.
+	p, i := 0, 0
+	starP, starI := -1, 0
+	for i < len(s) {
+		switch {
+		case p < len(pat) && (pat[p] == '?' || pat[p] == s[i]):
+			p++
+			i++
+		case p < len(pat) && pat[p] == '*':
+			starP, starI = p, i
+			p++
+		case starP >= 0:
+			starI++
+			p, i = starP+1, starI
+		default:
+			return false
+		}
+	}
+	for p < len(pat) && pat[p] == '*' {
+		p++
+	}
+	return p == len(pat)
+}
@@ -0,0 +1,219 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// examplePolicy is the policy from docs/proxy-server.md.
+const examplePolicy = `{
+  "default": "allow",
+  "value_flags": ["status", "s", "subject", "m", "message"],
+  "rules": [
+    {
+      "name": "close-own-rig-beads-only",
+      "effect": "deny",
+      "argv": ["bd", "update"],
+      "any_arg": "!${prefix}-*",
+      "flags": {"status|s": "closed"},
+      "reason": "polecats may only close beads in their own rig"
+    },
+    {
+      "name": "audit-mail",
+      "effect": "audit",
+      "argv": ["gt", "mail", "send"]
+    },
+    {
+      "name": "mail-own-witness",
+      "effect": "allow",
+      "argv": ["gt", "mail", "send", "${rig}/witness"],
+      "exact": true,
+      "allowed_flags": ["subject|s", "message|m"]
+    },
+    {
+      "name": "mail-witness-only",
+      "effect": "deny",
+      "argv": ["gt", "mail", "send"],
+      "reason": "polecats may only mail their rig's witness"
+    }
+  ]
+}`
+
+func loadExamplePolicy(t *testing.T) *Policy {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.json")
+	require.NoError(t, os.WriteFile(path, []byte(examplePolicy), 0644))
+	p, err := LoadPolicy(path)
+	require.NoError(t, err)
+	return p
+}
+
+func TestPolicyEvaluate(t *testing.T) {
+	p := loadExamplePolicy(t)
+	as := func(argv ...string) Decision {
+		return p.Evaluate(PolicyRequest{Identity: "gastown/toast", Argv: argv, Prefix: "gt"})
+	}
+
+	tests := []struct {
+		name   string
+		argv   []string
+		effect Effect
+		rule   string
+	}{
+		{"close own bead", []string{"bd", "update", "gt-abc", "--status=closed"}, EffectAllow, ""},
+		{"close foreign bead", []string{"bd", "update", "bd-xyz", "--status=closed"}, EffectDeny, "close-own-rig-beads-only"},
+		{"close foreign bead, separate value", []string{"bd", "update", "bd-xyz", "--status", "closed"}, EffectDeny, "close-own-rig-beads-only"},
+		{"close foreign bead, short flag", []string{"bd", "update", "-s", "closed", "bd-xyz"}, EffectDeny, "close-own-rig-beads-only"},
+		{"one foreign among several", []string{"bd", "update", "gt-1", "bd-2", "--status=closed"}, EffectDeny, "close-own-rig-beads-only"},
+		{"update foreign bead without closing", []string{"bd", "update", "bd-xyz", "--status=in_progress"}, EffectAllow, ""},
+		{"mail own witness", []string{"gt", "mail", "send", "gastown/witness", "-s", "done"}, EffectAllow, "mail-own-witness"},
+		{"mail own witness, flags first", []string{"gt", "mail", "send", "--subject", "done", "gastown/witness"}, EffectAllow, "mail-own-witness"},
+		{"mail other witness", []string{"gt", "mail", "send", "beads/witness"}, EffectDeny, "mail-witness-only"},
+		{"mail mayor", []string{"gt", "mail", "send", "mayor/"}, EffectDeny, "mail-witness-only"},
+		{"mail own witness, cc mayor", []string{"gt", "mail", "send", "gastown/witness", "--cc", "mayor/"}, EffectDeny, "mail-witness-only"},
+		{"mail own witness, cc= mayor", []string{"gt", "mail", "send", "gastown/witness", "--cc=mayor/"}, EffectDeny, "mail-witness-only"},
+		{"mail own witness and mayor", []string{"gt", "mail", "send", "gastown/witness", "mayor/"}, EffectDeny, "mail-witness-only"},
+		{"mail own witness, message", []string{"gt", "mail", "send", "gastown/witness", "-s", "done", "--message=ok"}, EffectAllow, "mail-own-witness"},
+		{"unrelated command", []string{"gt", "hook"}, EffectAllow, ""},
+		{"unknown flag before subcommand", []string{"bd", "--db", "x", "update", "bd-xyz", "--status=closed"}, EffectDeny, "close-own-rig-beads-only"},
+		{"unknown flag before bead", []string{"bd", "update", "--actor", "bd-xyz", "--status=closed"}, EffectDeny, "close-own-rig-beads-only"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := as(tt.argv...)
+			assert.Equal(t, tt.effect, d.Effect)
+			assert.Equal(t, tt.rule, d.Rule)
+		})
+	}
+
+	t.Run("audit rules are reported and do not decide", func(t *testing.T) {
+		d := as("gt", "mail", "send", "gastown/witness")
+		assert.Equal(t, []string{"audit-mail"}, d.Audit)
+	})
+
+	t.Run("deny reason is returned", func(t *testing.T) {
+		d := as("gt", "mail", "send", "mayor/")
+		assert.Equal(t, "polecats may only mail their rig's witness", d.Reason)
+	})
+}
+
+func TestPolicyIdentityAndDefault(t *testing.T) {
+	p := &Policy{
+		Default: EffectDeny,
+		Rules: []PolicyRule{
+			{Name: "gastown-only", Effect: EffectAllow, Identity: "gastown/*", Argv: []string{"gt"}},
+		},
+	}
+	require.NoError(t, p.Validate())
+
+	assert.Equal(t, EffectAllow, p.Evaluate(PolicyRequest{Identity: "gastown/toast", Argv: []string{"gt", "hook"}}).Effect)
+	d := p.Evaluate(PolicyRequest{Identity: "beads/nux", Argv: []string{"gt", "hook"}})
+	assert.Equal(t, EffectDeny, d.Effect)
+	assert.Empty(t, d.Rule)
+	assert.NotEmpty(t, d.Reason)
+}
+
+func TestPolicyAmbiguousFlags(t *testing.T) {
+	p := &Policy{
+		Default: EffectDeny,
+		Rules: []PolicyRule{
+			{Name: "show", Effect: EffectAllow, Argv: []string{"bd", "show"}},
+		},
+	}
+	require.NoError(t, p.Validate())
+	eval := func(argv ...string) Effect {
+		return p.Evaluate(PolicyRequest{Identity: "gastown/toast", Argv: argv}).Effect
+	}
+
+	assert.Equal(t, EffectAllow, eval("bd", "show", "gt-abc", "--json"))
+	// "show" may be the value of --db, making "update" the subcommand.
+	assert.Equal(t, EffectDeny, eval("bd", "--db", "show", "update", "gt-abc"))
+	assert.Equal(t, EffectDeny, eval("bd", "--json", "show", "gt-abc"))
+	p.ValueFlags = []string{"db"}
+	assert.Equal(t, EffectAllow, eval("bd", "--db", "x", "show", "gt-abc"))
+}
+
+func TestPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+	}{
+		{"bad default", Policy{Default: "audit"}},
+		{"bad effect", Policy{Rules: []PolicyRule{{Effect: "maybe"}}}},
+		{"unknown variable", Policy{Rules: []PolicyRule{{Effect: EffectDeny, Argv: []string{"${user}"}}}}},
+		{"unterminated variable", Policy{Rules: []PolicyRule{{Effect: EffectDeny, AnyArg: "${rig"}}}},
+		{"dashed flag name", Policy{Rules: []PolicyRule{{Effect: EffectDeny, Flags: map[string]string{"--status": "closed"}}}}},
+		{"dashed allowed flag", Policy{Rules: []PolicyRule{{Effect: EffectAllow, Exact: true, AllowedFlags: []string{"-s"}}}}},
+		{"allowed flags without exact", Policy{Rules: []PolicyRule{{Effect: EffectAllow, AllowedFlags: []string{"s"}}}}},
+		{"bad test expectation", Policy{Tests: []PolicyTest{{As: "a/b", Argv: []string{"gt"}, Expect: EffectAudit}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, tt.policy.Validate())
+		})
+	}
+
+	t.Run("unnamed rules are named by position", func(t *testing.T) {
+		p := Policy{Rules: []PolicyRule{{Effect: EffectAllow}, {Effect: EffectDeny}}}
+		require.NoError(t, p.Validate())
+		assert.Equal(t, "rule 2", p.Rules[1].Name)
+		assert.Equal(t, EffectAllow, p.Default)
+	})
+}
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pat, s string
+		want   bool
+	}{
+		{"*", "", true},
+		{"gt-*", "gt-abc", true},
+		{"gt-*", "bd-abc", false},
+		{"gastown/*", "gastown/polecats/toast", true},
+		{"?x", "ax", true},
+		{"?x", "x", false},
+		{"a*b*c", "aXXbYYc", true},
+		{"a*b*c", "aXXbYY", false},
+		{"exact", "exact", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, globMatch(tt.pat, tt.s), "globMatch(%q, %q)", tt.pat, tt.s)
+	}
+}
+
+// TestHandleExecPolicy verifies that handleExec enforces the policy and
+// returns a structured deny reason.
+func TestHandleExecPolicy(t *testing.T) {
+	srv := newExecTestServer(t, Config{
+		AllowedCommands: []string{"echo"},
+		Policy: &Policy{Rules: []PolicyRule{{
+			Name:     "no-secrets",
+			Effect:   EffectDeny,
+			Identity: "${rig}/*",
+			Argv:     []string{"echo", "secret*"},
+			Reason:   "no secrets",
+		}}},
+		RigPrefix: func(rig string) string { return "gt" },
+	})
+
+	rec := httptest.NewRecorder()
+	srv.handleExec(rec, makeFakeRequest("POST", "/v1/exec", `{"argv":["echo","secret-stuff"]}`, "gt-gastown-toast"))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var denied deniedResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&denied))
+	assert.Equal(t, "denied by policy", denied.Error)
+	assert.Equal(t, "no-secrets", denied.Rule)
+	assert.Equal(t, "no secrets", denied.Reason)
+
+	rec = httptest.NewRecorder()
+	srv.handleExec(rec, makeFakeRequest("POST", "/v1/exec", `{"argv":["echo","hello"]}`, "gt-gastown-toast"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
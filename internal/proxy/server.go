@@ -6,6 +6,7 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math/big"
@@ -58,6 +59,22 @@ type Config struct {
 	// ExecTimeout is the maximum duration a single exec subprocess may run.
 	// 0 uses the default (60s). Use a negative value to disable the timeout.
 	ExecTimeout time.Duration
+	// CertStorePath is the JSON file recording issued polecat certs and
+	// revocations. Revocations in it are loaded into the deny list by New, so
+	// a revoked cert stays revoked across restarts. If empty, issued certs and
+	// revocations are kept in memory only.
+	CertStorePath string
+	// PolecatCertTTL is the validity of polecat certs issued via the admin API
+	// when the request gives no TTL, and the maximum validity of renewed certs.
+	// 0 uses the default (720h).
+	PolecatCertTTL time.Duration
+	// Policy is the argument-level exec policy evaluated after the
+	// AllowedCommands and AllowedSubcommands checks. nil allows everything
+	// those checks allow.
+	Policy *Policy
+	// RigPrefix returns the beads prefix of a rig, for ${prefix} in policy
+	// patterns. nil uses the rig name.
+	RigPrefix func(rig string) string
 }
 
 // Server is an mTLS HTTP proxy server.
@@ -69,6 +86,8 @@ type Server struct {
 	resolvedPaths map[string]string
 	log           *slog.Logger
 	denyList      *DenyList
+	certs         *CertStore
+	certTTL       time.Duration
 
 	// execSem is a semaphore limiting global concurrent exec subprocesses.
 	execSem chan struct{}
@@ -88,7 +107,8 @@ type Server struct {
 // It logs a warning if AllowedCommands is empty, since no commands would be
 // permitted — a safe default but almost certainly a misconfiguration.
 // Any AllowedCommands entries containing "/" or "\" are rejected and removed.
-// Returns an error if Config.TownRoot is empty or not an absolute path.
+// Returns an error if Config.TownRoot is empty or not an absolute path, or if
+// Config.CertStorePath cannot be loaded.
 func New(cfg Config, ca *CA) (*Server, error) {
 	if cfg.TownRoot == "" {
 		return nil, fmt.Errorf("Config.TownRoot must be non-empty")
@@ -154,6 +174,23 @@ func New(cfg Config, ca *CA) (*Server, error) {
 	if et == 0 {
 		et = 60 * time.Second
 	}
+	ttl := cfg.PolecatCertTTL
+	if ttl <= 0 {
+		ttl = 720 * time.Hour
+	}
+
+	certs, err := OpenCertStore(cfg.CertStorePath)
+	if err != nil {
+		return nil, err
+	}
+	denyList := NewDenyList()
+	revoked := certs.Revoked()
+	for _, serial := range revoked {
+		denyList.Deny(serial)
+	}
+	if len(revoked) > 0 {
+		l.Info("loaded revoked certs", "count", len(revoked), "path", cfg.CertStorePath)
+	}
 
 	return &Server{
 		cfg:           cfg,
@@ -162,7 +199,9 @@ func New(cfg Config, ca *CA) (*Server, error) {
 		allowedSubs:   allowedSubs,
 		resolvedPaths: resolvedPaths,
 		log:           l,
-		denyList:      NewDenyList(),
+		denyList:      denyList,
+		certs:         certs,
+		certTTL:       ttl,
 		execSem:       make(chan struct{}, maxConcurrent),
 		execTimeout:   et,
 		rateLimit:     rate.Limit(rl),
@@ -194,11 +233,20 @@ func (s *Server) AdminAddr() net.Addr {
 	return s.adminLn.Addr()
 }
 
-// DenyCert adds a certificate serial number to the server's deny list.
-// Any active or future TLS connection presenting a cert with this serial will be
-// rejected at the TLS handshake. This method is safe for concurrent use.
-func (s *Server) DenyCert(serial *big.Int) {
+// DenyCert adds a certificate serial number to the server's deny list,
+// together with every cert renewed from it, so a polecat cannot escape a
+// revocation by renewing first. Any active or future TLS connection
+// presenting one of these certs will be rejected at the TLS handshake. The
+// revocation is recorded in the cert store; an error means it took effect
+// but could not be persisted, so it will not survive a restart. This method
+// is safe for concurrent use.
+func (s *Server) DenyCert(serial *big.Int) error {
 	s.denyList.Deny(serial)
+	err := s.certs.Revoke(serial, time.Now())
+	for _, revoked := range s.certs.Revoked() {
+		s.denyList.Deny(revoked)
+	}
+	return err
 }
 
 // Start begins listening and serving. Blocks until ctx is canceled.
@@ -232,6 +280,7 @@ func (s *Server) Start(ctx context.Context) error {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/v1/exec", s.handleExec)
 	mux.HandleFunc("/v1/git/", s.handleGit)
+	mux.HandleFunc("/v1/cert/renew", s.handleRenewCert)
 
 	srv := &http.Server{
 		Addr:        s.cfg.ListenAddr,
@@ -288,6 +337,7 @@ func (s *Server) Start(ctx context.Context) error {
 		adminMux := http.NewServeMux()
 		adminMux.HandleFunc("/v1/admin/deny-cert", s.handleDenyCert)
 		adminMux.HandleFunc("/v1/admin/issue-cert", s.handleIssueCert)
+		adminMux.HandleFunc("/v1/admin/certs", s.handleListCerts)
 
 		adminSrv = &http.Server{
 			Addr:         s.cfg.AdminListenAddr,
@@ -395,11 +445,13 @@ type issueCertRequest struct {
 	Rig string `json:"rig"`
 	// Name is the polecat name (e.g. "rust").
 	Name string `json:"name"`
-	// TTL is the certificate validity duration (e.g. "720h"). Defaults to 720h (30 days).
+	// TTL is the certificate validity duration (e.g. "24h").
+	// Defaults to Config.PolecatCertTTL.
 	TTL string `json:"ttl"`
 }
 
-// issueCertResponse is the JSON response for POST /v1/admin/issue-cert.
+// issueCertResponse is the JSON response for POST /v1/admin/issue-cert and
+// POST /v1/cert/renew.
 type issueCertResponse struct {
 	CN        string `json:"cn"`
 	Cert      string `json:"cert"`
@@ -432,7 +484,7 @@ func (s *Server) handleIssueCert(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ttl := 720 * time.Hour // 30 days
+	ttl := s.certTTL
 	if req.TTL != "" {
 		parsed, err := time.ParseDuration(req.TTL)
 		if err != nil {
@@ -447,34 +499,144 @@ func (s *Server) handleIssueCert(w http.ResponseWriter, r *http.Request) {
 	}
 
 	cn := "gt-" + req.Rig + "-" + req.Name
-	certPEM, keyPEM, err := s.ca.IssuePolecat(cn, ttl)
+	if cnToIdentity(cn) == "" {
+		http.Error(w, fmt.Sprintf("bad request: invalid polecat CN %q", cn), http.StatusBadRequest)
+		return
+	}
+	resp, leaf, err := s.issuePolecat(cn, ttl, nil)
 	if err != nil {
-		http.Error(w, "failed to issue certificate: "+err.Error(), http.StatusBadRequest)
+		http.Error(w, "failed to issue certificate: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	s.log.Info("cert issued via admin API", "cn", cn, "serial", leaf.SerialNumber.Text(16))
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// issuePolecat issues a polecat cert and records it in the cert store.
+// renewedFrom is the serial of the cert being replaced, or nil.
+func (s *Server) issuePolecat(cn string, ttl time.Duration, renewedFrom *big.Int) (issueCertResponse, *x509.Certificate, error) {
+	certPEM, keyPEM, err := s.ca.IssuePolecat(cn, ttl)
+	if err != nil {
+		return issueCertResponse{}, nil, err
+	}
+
 	// Parse the cert to extract serial and expiry for the response.
 	block, _ := pem.Decode(certPEM)
 	if block == nil || block.Type != "CERTIFICATE" {
-		http.Error(w, "internal error: failed to decode issued certificate PEM", http.StatusInternalServerError)
-		return
+		return issueCertResponse{}, nil, fmt.Errorf("failed to decode issued certificate PEM")
 	}
 	leaf, err := x509.ParseCertificate(block.Bytes)
 	if err != nil {
-		http.Error(w, "internal error: "+err.Error(), http.StatusInternalServerError)
-		return
+		return issueCertResponse{}, nil, err
 	}
 
-	s.log.Info("cert issued via admin API", "cn", cn, "serial", leaf.SerialNumber.Text(16))
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(issueCertResponse{
+	// A cert that is not recorded cannot be listed, but it is still valid:
+	// log the failure rather than withholding the cert. A renewal whose
+	// predecessor was revoked meanwhile is revoked with it.
+	if err := s.certs.Add(leaf, renewedFrom); errors.Is(err, ErrRenewedFromRevoked) {
+		s.denyList.Deny(leaf.SerialNumber)
+		return issueCertResponse{}, nil, err
+	} else if err != nil {
+		s.log.Error("failed to record issued cert", "cn", cn, "serial", leaf.SerialNumber.Text(16), "err", err)
+	}
+
+	return issueCertResponse{
 		CN:        cn,
 		Cert:      string(certPEM),
 		Key:       string(keyPEM),
 		CA:        string(s.ca.CertPEM),
 		Serial:    leaf.SerialNumber.Text(16),
 		ExpiresAt: leaf.NotAfter.UTC().Format(time.RFC3339),
-	})
+	}, leaf, nil
+}
+
+// handleRenewCert handles POST /v1/cert/renew on the mTLS server.
+// It issues a fresh cert for the CN of the client cert presented on the
+// connection, so gt-proxy-client can replace its cert before it expires.
+// The TLS handshake has already rejected expired and revoked certs, so only
+// a polecat holding a currently valid cert can renew it. The new cert is
+// valid for the presented cert's lifetime, capped at Config.PolecatCertTTL.
+func (s *Server) handleRenewCert(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		http.Error(w, "client certificate required", http.StatusUnauthorized)
+		return
+	}
+	old := r.TLS.PeerCertificates[0]
+	cn := old.Subject.CommonName
+	identity := cnToIdentity(cn)
+	if identity == "" {
+		http.Error(w, fmt.Sprintf("not a polecat certificate: %q", cn), http.StatusForbidden)
+		return
+	}
+	if !s.limiterFor(identity).Allow() {
+		s.log.Warn("renew rate limit exceeded", "identity", identity)
+		http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+	// The handshake check only covers revocations made before this
+	// connection was opened; a kept-alive connection must not outlive one.
+	if s.denyList.IsDenied(old.SerialNumber) {
+		http.Error(w, "certificate has been revoked", http.StatusForbidden)
+		return
+	}
+
+	// NotBefore is backdated by a minute at issue time; don't let renewals
+	// creep the lifetime up by that minute each time.
+	ttl := old.NotAfter.Sub(old.NotBefore) - time.Minute
+	if ttl <= 0 || ttl > s.certTTL {
+		ttl = s.certTTL
+	}
+
+	resp, leaf, err := s.issuePolecat(cn, ttl, old.SerialNumber)
+	if errors.Is(err, ErrRenewedFromRevoked) {
+		http.Error(w, "certificate has been revoked", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, "failed to issue certificate: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.log.Info("cert renewed", "identity", identity,
+		"old_serial", old.SerialNumber.Text(16), "serial", leaf.SerialNumber.Text(16))
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// certInfo is one entry of the GET /v1/admin/certs response.
+type certInfo struct {
+	CertRecord
+	Status string `json:"status"`
+}
+
+// listCertsResponse is the JSON response for GET /v1/admin/certs.
+type listCertsResponse struct {
+	Certs []certInfo `json:"certs"`
+}
+
+// handleListCerts handles GET /v1/admin/certs on the local admin server.
+// It lists the polecat certs issued by this server and all revoked serials,
+// oldest first, with their status (valid, expired or revoked).
+func (s *Server) handleListCerts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	now := time.Now()
+	records := s.certs.List()
+	resp := listCertsResponse{Certs: make([]certInfo, 0, len(records))}
+	for _, rec := range records {
+		resp.Certs = append(resp.Certs, certInfo{CertRecord: rec, Status: rec.Status(now)})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
 }
 
 // denyCertRequest is the JSON body for POST /v1/admin/deny-cert.
@@ -483,9 +645,18 @@ type denyCertRequest struct {
 	Serial string `json:"serial"`
 }
 
+// denyCertResponse is the JSON response for POST /v1/admin/deny-cert when the
+// revocation succeeded but will not survive a restart.
+type denyCertResponse struct {
+	Warning string `json:"warning"`
+}
+
 // handleDenyCert handles POST /v1/admin/deny-cert on the local admin server.
 // It adds the given certificate serial number to the server's deny list so that
 // any subsequent TLS handshake presenting that certificate is rejected.
+// It replies 204 once the revocation is persisted, 500 if persisting it
+// failed, and 200 with a warning if there is no cert store to persist it to;
+// in the last two cases the serial is denied until the server restarts.
 //
 // The admin server is local-only (bound to 127.0.0.1), so no additional
 // authentication is required beyond having local access to the host.
@@ -512,7 +683,18 @@ func (s *Server) handleDenyCert(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.denyList.Deny(serial)
+	if err := s.DenyCert(serial); err != nil {
+		// The serial is denied for the life of this process regardless.
+		s.log.Error("cert revoked but not persisted", "serial", req.Serial, "err", err)
+		http.Error(w, "revoked until restart; failed to persist revocation: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !s.certs.Persistent() {
+		s.log.Warn("cert revoked in memory only: no cert store configured", "serial", req.Serial)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(denyCertResponse{Warning: "revoked until restart: no cert store configured"})
+		return
+	}
 	s.log.Info("cert revoked via admin API", "serial", req.Serial)
 	w.WriteHeader(http.StatusNoContent)
 }
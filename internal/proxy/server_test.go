@@ -7,9 +7,12 @@ import (
 	"encoding/json"
 	"io"
 	"log/slog"
+	"math/big"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -552,6 +555,7 @@ func TestAdminDenyCertEndpoint(t *testing.T) {
 		AdminListenAddr: "127.0.0.1:0",
 		AllowedCommands: []string{"echo"},
 		TownRoot:        t.TempDir(),
+		CertStorePath:   filepath.Join(dir, "certs.json"),
 		Logger:          discardLogger(),
 	}, ca)
 	require.NoError(t, err)
@@ -677,6 +681,47 @@ func TestAdminDenyCertEndpoint(t *testing.T) {
 	})
 }
 
+// TestAdminDenyCertUnpersisted verifies that the deny-cert endpoint does not
+// report success when the revocation will not survive a restart.
+func TestAdminDenyCertUnpersisted(t *testing.T) {
+	dir := t.TempDir()
+	ca, err := GenerateCA(dir)
+	require.NoError(t, err)
+
+	deny := func(t *testing.T, srv *Server, serial string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/deny-cert", strings.NewReader(`{"serial":"`+serial+`"}`))
+		rec := httptest.NewRecorder()
+		srv.handleDenyCert(rec, req)
+		return rec
+	}
+
+	t.Run("persist failure returns 500", func(t *testing.T) {
+		storePath := filepath.Join(t.TempDir(), "certs.json")
+		srv, err := New(Config{ListenAddr: "127.0.0.1:0", TownRoot: t.TempDir(), CertStorePath: storePath, Logger: discardLogger()}, ca)
+		require.NoError(t, err)
+		// A directory in the way of the temp file makes every save fail.
+		require.NoError(t, os.Mkdir(storePath+".tmp", 0700))
+
+		rec := deny(t, srv, "3f2a1b")
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Contains(t, rec.Body.String(), "revoked until restart")
+		assert.True(t, srv.denyList.IsDenied(big.NewInt(0x3f2a1b)), "serial must still be denied in memory")
+	})
+
+	t.Run("no cert store returns a warning", func(t *testing.T) {
+		srv, err := New(Config{ListenAddr: "127.0.0.1:0", TownRoot: t.TempDir(), Logger: discardLogger()}, ca)
+		require.NoError(t, err)
+
+		rec := deny(t, srv, "3f2a1c")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var resp denyCertResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Contains(t, resp.Warning, "revoked until restart")
+		assert.True(t, srv.denyList.IsDenied(big.NewInt(0x3f2a1c)))
+	})
+}
+
 // TestAdminIssueCertEndpoint verifies the local admin HTTP endpoint for issuing polecat certs.
 func TestAdminIssueCertEndpoint(t *testing.T) {
 	dir := t.TempDir()
@@ -834,3 +879,166 @@ func TestAdminIssueCertEndpoint(t *testing.T) {
 		assert.WithinDuration(t, expectedExpiry, expiry, 5*time.Minute)
 	})
 }
+
+// TestRevocationPersistsAcrossRestart verifies that a revocation recorded in
+// the cert store is loaded into the deny list by a new server.
+func TestRevocationPersistsAcrossRestart(t *testing.T) {
+	ca, err := GenerateCA(t.TempDir())
+	require.NoError(t, err)
+	storePath := filepath.Join(t.TempDir(), "certs.json")
+	cfg := Config{TownRoot: t.TempDir(), CertStorePath: storePath, Logger: discardLogger()}
+
+	certPEM, keyPEM, err := ca.IssuePolecat("gt-gastown-dave", time.Hour)
+	require.NoError(t, err)
+	tlsCert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(tlsCert.Certificate[0])
+	require.NoError(t, err)
+
+	srv, err := New(cfg, ca)
+	require.NoError(t, err)
+	require.NoError(t, srv.DenyCert(leaf.SerialNumber))
+
+	restarted, err := New(cfg, ca)
+	require.NoError(t, err)
+	assert.True(t, restarted.denyList.IsDenied(leaf.SerialNumber), "revocation should survive restart")
+
+	t.Run("unreadable store fails New", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(storePath, []byte("{not json"), 0600))
+		_, err := New(cfg, ca)
+		assert.Error(t, err)
+	})
+}
+
+// TestCertRenewAndList verifies that a polecat can renew its cert over mTLS,
+// that the renewed cert works, and that the admin API lists both certs.
+func TestCertRenewAndList(t *testing.T) {
+	ca, err := GenerateCA(t.TempDir())
+	require.NoError(t, err)
+
+	srv, err := New(Config{
+		ListenAddr:      "127.0.0.1:0",
+		AdminListenAddr: "127.0.0.1:0",
+		AllowedCommands: []string{"echo"},
+		TownRoot:        t.TempDir(),
+		CertStorePath:   filepath.Join(t.TempDir(), "certs.json"),
+		PolecatCertTTL:  2 * time.Hour,
+		Logger:          discardLogger(),
+	}, ca)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() { srv.Start(ctx) }() //nolint:errcheck
+
+	var mainAddr, adminAddr string
+	require.Eventually(t, func() bool {
+		if a := srv.Addr(); a != nil {
+			mainAddr = a.String()
+		}
+		if a := srv.AdminAddr(); a != nil {
+			adminAddr = a.String()
+		}
+		return mainAddr != "" && adminAddr != ""
+	}, 5*time.Second, 10*time.Millisecond)
+	waitForServer(t, mainAddr, 5*time.Second)
+	waitForServer(t, adminAddr, 5*time.Second)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+	mTLSClient := func(cert tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			RootCAs:      pool,
+		}}}
+	}
+
+	// Issue through the admin API so the cert is recorded; the default TTL
+	// comes from PolecatCertTTL.
+	resp, err := http.Post("http://"+adminAddr+"/v1/admin/issue-cert", "application/json",
+		strings.NewReader(`{"rig":"gastown","name":"erin"}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var issued issueCertResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&issued))
+	expiry, err := time.Parse(time.RFC3339, issued.ExpiresAt)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(2*time.Hour), expiry, 5*time.Minute)
+
+	cert, err := tls.X509KeyPair([]byte(issued.Cert), []byte(issued.Key))
+	require.NoError(t, err)
+
+	t.Run("GET renew returns 405", func(t *testing.T) {
+		resp, err := mTLSClient(cert).Get("https://" + mainAddr + "/v1/cert/renew")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+
+	resp2, err := mTLSClient(cert).Post("https://"+mainAddr+"/v1/cert/renew", "application/json", nil)
+	require.NoError(t, err)
+	defer resp2.Body.Close()
+	require.Equal(t, http.StatusOK, resp2.StatusCode)
+	var renewed issueCertResponse
+	require.NoError(t, json.NewDecoder(resp2.Body).Decode(&renewed))
+	assert.Equal(t, "gt-gastown-erin", renewed.CN)
+	assert.NotEqual(t, issued.Serial, renewed.Serial)
+
+	newCert, err := tls.X509KeyPair([]byte(renewed.Cert), []byte(renewed.Key))
+	require.NoError(t, err)
+	renewedClient := mTLSClient(newCert)
+	execResp, err := renewedClient.Post("https://"+mainAddr+"/v1/exec", "application/json",
+		strings.NewReader(`{"argv":["echo","renewed"]}`))
+	require.NoError(t, err)
+	_, _ = io.Copy(io.Discard, execResp.Body)
+	execResp.Body.Close()
+	assert.Equal(t, http.StatusOK, execResp.StatusCode)
+
+	// Revoke the original cert: the cert renewed from it goes with it.
+	require.NoError(t, srv.DenyCert(mustSerial(t, issued.Serial)))
+
+	t.Run("renewed child of a revoked cert cannot renew", func(t *testing.T) {
+		// On the connection opened before the revocation, the handler
+		// refuses; a new connection fails the handshake.
+		resp, err := renewedClient.Post("https://"+mainAddr+"/v1/cert/renew", "application/json", nil)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		_, err = mTLSClient(newCert).Post("https://"+mainAddr+"/v1/cert/renew", "application/json", nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("POST list returns 405", func(t *testing.T) {
+		resp, err := http.Post("http://"+adminAddr+"/v1/admin/certs", "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+
+	listResp, err := http.Get("http://" + adminAddr + "/v1/admin/certs")
+	require.NoError(t, err)
+	defer listResp.Body.Close()
+	require.Equal(t, http.StatusOK, listResp.StatusCode)
+	var list listCertsResponse
+	require.NoError(t, json.NewDecoder(listResp.Body).Decode(&list))
+	require.Len(t, list.Certs, 2)
+
+	status := make(map[string]certInfo)
+	for _, c := range list.Certs {
+		status[c.Serial] = c
+	}
+	assert.Equal(t, "revoked", status[issued.Serial].Status)
+	assert.NotNil(t, status[issued.Serial].RevokedAt)
+	assert.Equal(t, "revoked", status[renewed.Serial].Status)
+	assert.Equal(t, issued.Serial, status[renewed.Serial].RenewedFrom)
+	assert.Equal(t, "gt-gastown-erin", status[renewed.Serial].CN)
+}
+
+func mustSerial(t *testing.T, hex string) *big.Int {
+	t.Helper()
+	serial, ok := new(big.Int).SetString(hex, 16)
+	require.True(t, ok, "invalid serial %q", hex)
+	return serial
+}
@@ -0,0 +1,338 @@
+package ptyhost
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// ErrHostNotRunning is returned when the daemon's session host is unreachable.
+var ErrHostNotRunning = errors.New("headless session host not running (start it with 'gt daemon start')")
+
+// dialTimeout bounds how long a client waits to reach the host.
+const dialTimeout = 2 * time.Second
+
+// Client talks to the daemon's Host over its unix socket. Its method set
+// mirrors the subset of tmux.Tmux used to manage agent sessions, so it can
+// stand in for tmux as a session backend.
+type Client struct {
+	socketPath string
+}
+
+// NewClient creates a client for the host listening on socketPath.
+func NewClient(socketPath string) *Client {
+	return &Client{socketPath: socketPath}
+}
+
+func (c *Client) call(req request) (response, error) {
+	conn, err := net.DialTimeout("unix", c.socketPath, dialTimeout)
+	if err != nil {
+		return response{}, ErrHostNotRunning
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(time.Minute))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return response{}, fmt.Errorf("sending %s request: %w", req.Op, err)
+	}
+	var resp response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return response{}, fmt.Errorf("reading %s response: %w", req.Op, err)
+	}
+	if resp.NotFound {
+		return resp, fmt.Errorf("%w: %s", tmux.ErrSessionNotFound, req.Session)
+	}
+	if resp.Error != "" {
+		return resp, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+// NewSessionWithCommand starts command in workDir as a new headless session.
+func (c *Client) NewSessionWithCommand(name, workDir, command string) error {
+	_, err := c.call(request{Op: "new", Session: name, WorkDir: workDir, Command: command})
+	return err
+}
+
+// HasSession reports whether a session exists. An unreachable host has no
+// sessions, matching tmux's behavior when no server is running.
+func (c *Client) HasSession(name string) (bool, error) {
+	resp, err := c.call(request{Op: "has", Session: name})
+	if errors.Is(err, ErrHostNotRunning) {
+		return false, nil
+	}
+	return resp.Bool, err
+}
+
+// ListSessions returns the names of all headless sessions.
+func (c *Client) ListSessions() ([]string, error) {
+	resp, err := c.call(request{Op: "list"})
+	if errors.Is(err, ErrHostNotRunning) {
+		return nil, nil
+	}
+	return resp.Strings, err
+}
+
+// KillSession terminates a session.
+func (c *Client) KillSession(name string) error {
+	_, err := c.call(request{Op: "kill", Session: name})
+	return err
+}
+
+// KillSessionWithProcesses terminates a session and its whole process group.
+// Headless sessions always run in their own process group, so this is the
+// same as KillSession.
+func (c *Client) KillSessionWithProcesses(name string) error {
+	return c.KillSession(name)
+}
+
+// SetEnvironment records a variable in the session's environment table.
+func (c *Client) SetEnvironment(session, key, value string) error {
+	_, err := c.call(request{Op: "setenv", Session: session, Key: key, Value: value})
+	return err
+}
+
+// GetEnvironment reads a variable from the session's environment table.
+func (c *Client) GetEnvironment(session, key string) (string, error) {
+	resp, err := c.call(request{Op: "getenv", Session: session, Key: key})
+	return resp.String, err
+}
+
+// SetRemainOnExit keeps the session (and its scrollback) after the process exits.
+func (c *Client) SetRemainOnExit(session string, on bool) error {
+	_, err := c.call(request{Op: "remain", Session: session, On: on})
+	return err
+}
+
+// SendKeysRaw sends tmux-style key names ("C-c", "Enter", "Escape") or
+// literal text to the session.
+func (c *Client) SendKeysRaw(session, keys string) error {
+	_, err := c.call(request{Op: "write", Session: session, Data: keyBytes(keys)})
+	return err
+}
+
+// NudgeSession types message into the session and submits it.
+func (c *Client) NudgeSession(session, message string) error {
+	_, err := c.call(request{Op: "write", Session: session, Data: []byte(message + "\r")})
+	return err
+}
+
+// SendKeysDebounced types keys literally, waits debounceMs, then presses Enter.
+func (c *Client) SendKeysDebounced(session, keys string, debounceMs int) error {
+	if _, err := c.call(request{Op: "write", Session: session, Data: []byte(keys)}); err != nil {
+		return err
+	}
+	if debounceMs > 0 {
+		time.Sleep(time.Duration(debounceMs) * time.Millisecond)
+	}
+	_, err := c.call(request{Op: "write", Session: session, Data: []byte("\r")})
+	return err
+}
+
+// IsIdle reports whether the session's last output line is the agent's
+// ready prompt.
+func (c *Client) IsIdle(session string) bool {
+	out, err := c.CapturePane(session, 5)
+	if err != nil {
+		return false
+	}
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	last := strings.TrimSpace(lines[len(lines)-1])
+	return strings.HasPrefix(last, strings.TrimSpace(tmux.DefaultReadyPromptPrefix))
+}
+
+// CheckSessionHealth classifies a session the same way tmux does: missing
+// session, dead process, no output for longer than maxInactivity, or healthy.
+func (c *Client) CheckSessionHealth(session string, maxInactivity time.Duration) tmux.ZombieStatus {
+	resp, err := c.call(request{Op: "info", Session: session})
+	if err != nil {
+		return tmux.SessionDead
+	}
+	if !resp.Info.Alive {
+		return tmux.AgentDead
+	}
+	if maxInactivity > 0 && !resp.Info.LastActivity.IsZero() && time.Since(resp.Info.LastActivity) > maxInactivity {
+		return tmux.AgentHung
+	}
+	return tmux.SessionHealthy
+}
+
+// CapturePane returns the last lines of the session's scrollback.
+func (c *Client) CapturePane(session string, lines int) (string, error) {
+	resp, err := c.call(request{Op: "capture", Session: session, Lines: lines})
+	return resp.String, err
+}
+
+// CapturePaneAll returns the session's entire retained scrollback.
+func (c *Client) CapturePaneAll(session string) (string, error) {
+	return c.CapturePane(session, 0)
+}
+
+// IsAgentAlive reports whether the session's process is still running.
+// The headless host runs the agent command directly, so process liveness is
+// agent liveness.
+func (c *Client) IsAgentAlive(session string) bool {
+	resp, err := c.call(request{Op: "alive", Session: session})
+	return err == nil && resp.Bool
+}
+
+// GetPanePID returns the PID of the session's process.
+func (c *Client) GetPanePID(session string) (string, error) {
+	resp, err := c.call(request{Op: "pid", Session: session})
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(resp.Int), nil
+}
+
+// GetPaneID returns a stable identifier for the session's terminal.
+func (c *Client) GetPaneID(session string) (string, error) {
+	resp, err := c.call(request{Op: "pid", Session: session})
+	if err != nil {
+		return "", err
+	}
+	return PaneID(resp.Int), nil
+}
+
+// GetSessionInfo returns session details in tmux's format.
+func (c *Client) GetSessionInfo(name string) (*tmux.SessionInfo, error) {
+	resp, err := c.call(request{Op: "info", Session: name})
+	if err != nil {
+		return nil, err
+	}
+	return &tmux.SessionInfo{
+		Name:     resp.Info.Name,
+		Windows:  1,
+		Created:  resp.Info.Created.Format("2006-01-02 15:04:05"),
+		Activity: strconv.FormatInt(resp.Info.LastActivity.Unix(), 10),
+	}, nil
+}
+
+// GetSessionCreatedUnix returns when the session was created, in unix seconds.
+func (c *Client) GetSessionCreatedUnix(session string) (int64, error) {
+	resp, err := c.call(request{Op: "info", Session: session})
+	if err != nil {
+		return 0, err
+	}
+	return resp.Info.Created.Unix(), nil
+}
+
+// GetSessionActivity returns when the session last produced output.
+func (c *Client) GetSessionActivity(session string) (time.Time, error) {
+	resp, err := c.call(request{Op: "info", Session: session})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return resp.Info.LastActivity, nil
+}
+
+// GetPaneWorkDir returns the directory the session was started in.
+func (c *Client) GetPaneWorkDir(session string) (string, error) {
+	resp, err := c.call(request{Op: "info", Session: session})
+	if err != nil {
+		return "", err
+	}
+	return resp.Info.WorkDir, nil
+}
+
+// DismissStartupDialogsBlind sends the same key sequence as tmux's blind
+// dismissal: Enter for the workspace trust dialog, then Down+Enter for the
+// bypass permissions warning.
+func (c *Client) DismissStartupDialogsBlind(session string) error {
+	if err := c.SendKeysRaw(session, "Enter"); err != nil {
+		return fmt.Errorf("sending Enter for trust dialog: %w", err)
+	}
+	time.Sleep(500 * time.Millisecond)
+	if err := c.SendKeysRaw(session, "Down"); err != nil {
+		return fmt.Errorf("sending Down for bypass dialog: %w", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if err := c.SendKeysRaw(session, "Enter"); err != nil {
+		return fmt.Errorf("sending Enter for bypass dialog: %w", err)
+	}
+	return nil
+}
+
+// WaitForCommand waits until the session's process is running. Headless
+// sessions exec the agent command directly, so there is no shell to wait past.
+func (c *Client) WaitForCommand(session string, _ []string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if c.IsAgentAlive(session) {
+			return nil
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	return fmt.Errorf("timeout waiting for command in session %s", session)
+}
+
+// WaitForRuntimeReady waits for the runtime's ready prompt to appear in the
+// scrollback, or for its fixed ready delay when it has no prompt detection.
+func (c *Client) WaitForRuntimeReady(session string, rc *config.RuntimeConfig, timeout time.Duration) error {
+	if rc == nil || rc.Tmux == nil {
+		return nil
+	}
+	prefix := strings.TrimSpace(rc.Tmux.ReadyPromptPrefix)
+	if prefix == "" {
+		if rc.Tmux.ReadyDelayMs <= 0 {
+			return nil
+		}
+		delay := time.Duration(rc.Tmux.ReadyDelayMs) * time.Millisecond
+		if delay > timeout {
+			delay = timeout
+		}
+		time.Sleep(delay)
+		return nil
+	}
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if out, err := c.CapturePane(session, 10); err == nil {
+			for _, line := range strings.Split(out, "\n") {
+				if strings.HasPrefix(strings.TrimSpace(line), prefix) {
+					return nil
+				}
+			}
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	return fmt.Errorf("timeout waiting for runtime prompt")
+}
+
+// keyBytes translates tmux key names into terminal input. Anything that is
+// not a recognised key name is sent literally.
+func keyBytes(keys string) []byte {
+	switch keys {
+	case "Enter", "C-m":
+		return []byte("\r")
+	case "Escape":
+		return []byte("\x1b")
+	case "Tab":
+		return []byte("\t")
+	case "BSpace":
+		return []byte("\x7f")
+	case "Space":
+		return []byte(" ")
+	case "Up":
+		return []byte("\x1b[A")
+	case "Down":
+		return []byte("\x1b[B")
+	case "Right":
+		return []byte("\x1b[C")
+	case "Left":
+		return []byte("\x1b[D")
+	}
+	if len(keys) == 3 && strings.HasPrefix(keys, "C-") {
+		if ch := keys[2]; ch >= 'a' && ch <= 'z' {
+			return []byte{ch - 'a' + 1}
+		}
+	}
+	return []byte(keys)
+}
@@ -0,0 +1,323 @@
+// Package ptyhost runs agent sessions on pseudo-terminals without tmux.
+//
+// A Host owns the sessions in-process (the daemon runs one), keeps a bounded
+// scrollback of each session's output for peeking, and accepts keystrokes.
+// Other gt processes reach the daemon's Host through a Client over a unix
+// socket. Together they back the "headless" session backend.
+package ptyhost
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRows = 50
+	defaultCols = 200
+
+	// scrollbackBytes bounds how much output is retained per session.
+	scrollbackBytes = 1 << 20
+
+	// killGrace is how long processes get to exit after SIGTERM.
+	killGrace = 2 * time.Second
+)
+
+var (
+	// ErrSessionNotFound is returned for operations on unknown sessions.
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrSessionExists is returned when creating a session whose name is taken.
+	ErrSessionExists = errors.New("duplicate session")
+)
+
+// SessionInfo describes a hosted session.
+type SessionInfo struct {
+	Name         string    `json:"name"`
+	PID          int       `json:"pid"`
+	WorkDir      string    `json:"work_dir"`
+	Created      time.Time `json:"created"`
+	LastActivity time.Time `json:"last_activity"`
+	Alive        bool      `json:"alive"`
+}
+
+// hostedSession is one process running on a PTY.
+type hostedSession struct {
+	name    string
+	workDir string
+	created time.Time
+
+	cmd    *exec.Cmd
+	master *os.File
+	done   chan struct{} // closed when the process exits
+
+	mu           sync.Mutex
+	env          map[string]string
+	scrollback   []byte
+	lastActivity time.Time
+	remainOnExit bool
+}
+
+// Host manages headless PTY sessions.
+type Host struct {
+	mu       sync.Mutex
+	sessions map[string]*hostedSession
+	shell    string
+}
+
+// NewHost creates an empty session host. Commands run under /bin/sh -c.
+func NewHost() *Host {
+	return &Host{sessions: make(map[string]*hostedSession), shell: "/bin/sh"}
+}
+
+// NewSession starts command in workDir on a new PTY under the given name.
+func (h *Host) NewSession(name, workDir, command string) error {
+	if name == "" {
+		return fmt.Errorf("session name is required")
+	}
+	h.mu.Lock()
+	if _, ok := h.sessions[name]; ok {
+		h.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrSessionExists, name)
+	}
+	// Reserve the name while the process starts.
+	s := &hostedSession{name: name, workDir: workDir, created: time.Now(), env: map[string]string{}, done: make(chan struct{})}
+	h.sessions[name] = s
+	h.mu.Unlock()
+
+	cmd := exec.Command(h.shell, "-c", command) //nolint:gosec // G204: command comes from gt's own session config
+	cmd.Dir = workDir
+	cmd.Env = append(os.Environ(), "TERM=xterm-256color", "GT_SESSION_BACKEND=headless")
+	master, err := startWithPTY(cmd)
+	if err != nil {
+		h.mu.Lock()
+		delete(h.sessions, name)
+		h.mu.Unlock()
+		return fmt.Errorf("starting session %s: %w", name, err)
+	}
+	h.mu.Lock()
+	s.cmd, s.master = cmd, master
+	s.lastActivity = time.Now()
+	h.mu.Unlock()
+
+	go s.pump()
+	go h.reap(s)
+	return nil
+}
+
+// pump copies PTY output into the session scrollback.
+func (s *hostedSession) pump() {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := s.master.Read(buf)
+		if n > 0 {
+			s.mu.Lock()
+			s.scrollback = append(s.scrollback, buf[:n]...)
+			if over := len(s.scrollback) - scrollbackBytes; over > 0 {
+				s.scrollback = append([]byte(nil), s.scrollback[over:]...)
+			}
+			s.lastActivity = time.Now()
+			s.mu.Unlock()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// reap waits for the session process and removes the session unless it is
+// marked remain-on-exit.
+func (h *Host) reap(s *hostedSession) {
+	_ = s.cmd.Wait()
+	close(s.done)
+
+	s.mu.Lock()
+	keep := s.remainOnExit
+	s.mu.Unlock()
+	if keep {
+		return
+	}
+	h.remove(s)
+}
+
+func (h *Host) remove(s *hostedSession) {
+	h.mu.Lock()
+	if h.sessions[s.name] == s {
+		delete(h.sessions, s.name)
+	}
+	h.mu.Unlock()
+	_ = s.master.Close()
+}
+
+func (h *Host) get(name string) (*hostedSession, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.sessions[name]
+	if !ok || s.cmd == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, name)
+	}
+	return s, nil
+}
+
+// HasSession reports whether a session exists.
+func (h *Host) HasSession(name string) bool {
+	_, err := h.get(name)
+	return err == nil
+}
+
+// ListSessions returns the names of all sessions, sorted.
+func (h *Host) ListSessions() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	names := make([]string, 0, len(h.sessions))
+	for name, s := range h.sessions {
+		if s.cmd != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// KillSession terminates a session's process group and removes the session.
+func (h *Host) KillSession(name string) error {
+	s, err := h.get(name)
+	if err != nil {
+		return err
+	}
+	if !s.exited() {
+		killProcessGroup(s.cmd.Process.Pid, func() {
+			select {
+			case <-s.done:
+			case <-time.After(killGrace):
+			}
+		})
+		<-s.done
+	}
+	h.remove(s)
+	return nil
+}
+
+// Alive reports whether the session's process is still running.
+func (h *Host) Alive(name string) bool {
+	s, err := h.get(name)
+	return err == nil && !s.exited()
+}
+
+func (s *hostedSession) exited() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// PID returns the PID of the session's process.
+func (h *Host) PID(name string) (int, error) {
+	s, err := h.get(name)
+	if err != nil {
+		return 0, err
+	}
+	return s.cmd.Process.Pid, nil
+}
+
+// SetEnv records an environment variable in the session's environment table.
+func (h *Host) SetEnv(name, key, value string) error {
+	s, err := h.get(name)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.env[key] = value
+	s.mu.Unlock()
+	return nil
+}
+
+// GetEnv returns a variable from the session's environment table.
+func (h *Host) GetEnv(name, key string) (string, error) {
+	s, err := h.get(name)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.env[key]
+	if !ok {
+		return "", fmt.Errorf("unknown variable: %s", key)
+	}
+	return v, nil
+}
+
+// SetRemainOnExit controls whether a session is kept after its process exits.
+func (h *Host) SetRemainOnExit(name string, on bool) error {
+	s, err := h.get(name)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.remainOnExit = on
+	s.mu.Unlock()
+	return nil
+}
+
+// Write sends raw input to the session's terminal.
+func (h *Host) Write(name string, data []byte) error {
+	s, err := h.get(name)
+	if err != nil {
+		return err
+	}
+	if s.exited() {
+		return fmt.Errorf("session %s has exited", name)
+	}
+	_, err = s.master.Write(data)
+	return err
+}
+
+// Capture returns the last lines of the session's rendered scrollback
+// (all retained lines if lines <= 0).
+func (h *Host) Capture(name string, lines int) (string, error) {
+	s, err := h.get(name)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	raw := append([]byte(nil), s.scrollback...)
+	s.mu.Unlock()
+	return lastLines(renderText(raw), lines), nil
+}
+
+// Info returns details about a session.
+func (h *Host) Info(name string) (*SessionInfo, error) {
+	s, err := h.get(name)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &SessionInfo{
+		Name:         s.name,
+		PID:          s.cmd.Process.Pid,
+		WorkDir:      s.workDir,
+		Created:      s.created,
+		LastActivity: s.lastActivity,
+		Alive:        !s.exited(),
+	}, nil
+}
+
+// Close kills every session. The daemon calls this on shutdown.
+func (h *Host) Close() {
+	for _, name := range h.ListSessions() {
+		_ = h.KillSession(name)
+	}
+}
+
+// PaneID returns the identifier the headless backend reports in place of a
+// tmux pane ID.
+func PaneID(pid int) string {
+	return "pty:" + strconv.Itoa(pid)
+}
@@ -0,0 +1,181 @@
+//go:build linux
+
+package ptyhost
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// waitFor polls cond until it holds or the deadline passes.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestHost_SessionLifecycle(t *testing.T) {
+	h := NewHost()
+	defer h.Close()
+
+	if err := h.NewSession("gt-test", t.TempDir(), "echo started; cat"); err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	if err := h.NewSession("gt-test", "", "true"); !errors.Is(err, ErrSessionExists) {
+		t.Errorf("duplicate NewSession error = %v, want ErrSessionExists", err)
+	}
+	if !h.HasSession("gt-test") || !h.Alive("gt-test") {
+		t.Fatal("session should exist and be alive")
+	}
+	if got := h.ListSessions(); len(got) != 1 || got[0] != "gt-test" {
+		t.Errorf("ListSessions() = %v", got)
+	}
+
+	waitFor(t, "startup output", func() bool {
+		out, _ := h.Capture("gt-test", 0)
+		return strings.Contains(out, "started")
+	})
+
+	if err := h.Write("gt-test", []byte("ping\r")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	waitFor(t, "echoed input", func() bool {
+		out, _ := h.Capture("gt-test", 0)
+		return strings.Count(out, "ping") >= 2 // terminal echo + cat output
+	})
+
+	if err := h.SetEnv("gt-test", "GT_AGENT", "claude"); err != nil {
+		t.Fatalf("SetEnv: %v", err)
+	}
+	if v, err := h.GetEnv("gt-test", "GT_AGENT"); err != nil || v != "claude" {
+		t.Errorf("GetEnv = %q, %v", v, err)
+	}
+	if _, err := h.GetEnv("gt-test", "MISSING"); err == nil {
+		t.Error("GetEnv of unset variable should fail")
+	}
+
+	if err := h.KillSession("gt-test"); err != nil {
+		t.Fatalf("KillSession: %v", err)
+	}
+	if h.HasSession("gt-test") {
+		t.Error("session should be gone after KillSession")
+	}
+	if err := h.KillSession("gt-test"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("second KillSession error = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestHost_ExitedSessionIsRemoved(t *testing.T) {
+	h := NewHost()
+	defer h.Close()
+
+	if err := h.NewSession("gt-short", "", "true"); err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	waitFor(t, "session removal", func() bool { return !h.HasSession("gt-short") })
+}
+
+func TestHost_RemainOnExitKeepsScrollback(t *testing.T) {
+	h := NewHost()
+	defer h.Close()
+
+	if err := h.NewSession("gt-remain", "", "read x; echo last words"); err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	if err := h.SetRemainOnExit("gt-remain", true); err != nil {
+		t.Fatalf("SetRemainOnExit: %v", err)
+	}
+	if err := h.Write("gt-remain", []byte("\r")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	waitFor(t, "process exit", func() bool { return !h.Alive("gt-remain") })
+
+	if !h.HasSession("gt-remain") {
+		t.Fatal("remain-on-exit session should be kept")
+	}
+	out, err := h.Capture("gt-remain", 1)
+	if err != nil || out != "last words" {
+		t.Errorf("Capture = %q, %v", out, err)
+	}
+}
+
+func TestClient_RoundTrip(t *testing.T) {
+	dir, err := os.MkdirTemp("", "ptyhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "host.sock")
+
+	h := NewHost()
+	defer h.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = Serve(ctx, h, socket) }()
+
+	c := NewClient(socket)
+	waitFor(t, "socket", func() bool {
+		_, err := c.ListSessions()
+		_, statErr := os.Stat(socket)
+		return err == nil && statErr == nil
+	})
+
+	if err := c.NewSessionWithCommand("gt-rt", dir, "cat"); err != nil {
+		t.Fatalf("NewSessionWithCommand: %v", err)
+	}
+	if ok, err := c.HasSession("gt-rt"); err != nil || !ok {
+		t.Fatalf("HasSession = %v, %v", ok, err)
+	}
+	if err := c.NudgeSession("gt-rt", "hello host"); err != nil {
+		t.Fatalf("NudgeSession: %v", err)
+	}
+	waitFor(t, "nudge output", func() bool {
+		out, _ := c.CapturePaneAll("gt-rt")
+		return strings.Count(out, "hello host") >= 2
+	})
+
+	pane, err := c.GetPaneID("gt-rt")
+	if err != nil || !strings.HasPrefix(pane, "pty:") {
+		t.Errorf("GetPaneID = %q, %v", pane, err)
+	}
+	if got := c.CheckSessionHealth("gt-rt", 0); got != tmux.SessionHealthy {
+		t.Errorf("CheckSessionHealth = %v, want healthy", got)
+	}
+	info, err := c.GetSessionInfo("gt-rt")
+	if err != nil || info.Name != "gt-rt" {
+		t.Errorf("GetSessionInfo = %+v, %v", info, err)
+	}
+
+	if err := c.KillSession("gt-rt"); err != nil {
+		t.Fatalf("KillSession: %v", err)
+	}
+	if _, err := c.CapturePane("gt-rt", 10); !errors.Is(err, tmux.ErrSessionNotFound) {
+		t.Errorf("CapturePane after kill error = %v, want tmux.ErrSessionNotFound", err)
+	}
+	if got := c.CheckSessionHealth("gt-rt", 0); got != tmux.SessionDead {
+		t.Errorf("CheckSessionHealth after kill = %v, want dead", got)
+	}
+}
+
+func TestClient_HostNotRunning(t *testing.T) {
+	c := NewClient(filepath.Join(t.TempDir(), "missing.sock"))
+	if ok, err := c.HasSession("gt-any"); ok || err != nil {
+		t.Errorf("HasSession = %v, %v; want false, nil", ok, err)
+	}
+	if err := c.NewSessionWithCommand("gt-any", "", "true"); !errors.Is(err, ErrHostNotRunning) {
+		t.Errorf("NewSessionWithCommand error = %v, want ErrHostNotRunning", err)
+	}
+}
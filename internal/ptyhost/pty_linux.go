@@ -0,0 +1,54 @@
+//go:build linux
+
+package ptyhost
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// startWithPTY starts cmd attached to a new pseudo-terminal as its controlling
+// terminal, in its own session and process group. Returns the PTY master.
+func startWithPTY(cmd *exec.Cmd) (*os.File, error) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("opening /dev/ptmx: %w", err)
+	}
+	if err := unix.IoctlSetPointerInt(int(master.Fd()), unix.TIOCSPTLCK, 0); err != nil {
+		_ = master.Close()
+		return nil, fmt.Errorf("unlocking pty: %w", err)
+	}
+	n, err := unix.IoctlGetUint32(int(master.Fd()), unix.TIOCGPTN)
+	if err != nil {
+		_ = master.Close()
+		return nil, fmt.Errorf("getting pty number: %w", err)
+	}
+	slave, err := os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		_ = master.Close()
+		return nil, fmt.Errorf("opening pty slave: %w", err)
+	}
+	defer func() { _ = slave.Close() }()
+
+	_ = unix.IoctlSetWinsize(int(master.Fd()), unix.TIOCSWINSZ, &unix.Winsize{Row: defaultRows, Col: defaultCols})
+
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = slave, slave, slave
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 0}
+	if err := cmd.Start(); err != nil {
+		_ = master.Close()
+		return nil, err
+	}
+	return master, nil
+}
+
+// killProcessGroup terminates the process group led by pid: SIGTERM, then
+// SIGKILL for anything still running after grace.
+func killProcessGroup(pid int, grace func()) {
+	_ = syscall.Kill(-pid, syscall.SIGTERM)
+	grace()
+	_ = syscall.Kill(-pid, syscall.SIGKILL)
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package ptyhost
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// startWithPTY is only implemented on Linux.
+func startWithPTY(_ *exec.Cmd) (*os.File, error) {
+	return nil, errors.New("headless session backend is only supported on Linux")
+}
+
+func killProcessGroup(_ int, _ func()) {}
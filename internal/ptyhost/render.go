@@ -0,0 +1,100 @@
+package ptyhost
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// renderText turns raw terminal output into plain text lines, roughly as
+// tmux capture-pane would show them: escape sequences are dropped, a carriage
+// return restarts the current line, and backspace erases one character.
+func renderText(raw []byte) string {
+	var out strings.Builder
+	var line []rune
+	flush := func() {
+		out.WriteString(strings.TrimRight(string(line), " "))
+		out.WriteByte('\n')
+		line = line[:0]
+	}
+
+	s := string(raw)
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == 0x1b:
+			i += escapeLen(s[i:])
+			continue
+		case c == '\n':
+			flush()
+		case c == '\r':
+			if i+1 < len(s) && s[i+1] == '\n' {
+				break
+			}
+			line = line[:0]
+		case c == '\b':
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+			}
+		case c == '\t':
+			line = append(line, ' ')
+		case c < 0x20 || c == 0x7f:
+			// Other control characters are not rendered.
+		default:
+			r, size := utf8.DecodeRuneInString(s[i:])
+			line = append(line, r)
+			i += size
+			continue
+		}
+		i++
+	}
+	if len(line) > 0 {
+		flush()
+	}
+	return out.String()
+}
+
+// escapeLen returns the length of the escape sequence at the start of s.
+func escapeLen(s string) int {
+	if len(s) < 2 {
+		return len(s)
+	}
+	switch s[1] {
+	case '[': // CSI: ESC [ params final-byte
+		for j := 2; j < len(s); j++ {
+			if s[j] >= 0x40 && s[j] <= 0x7e {
+				return j + 1
+			}
+		}
+		return len(s)
+	case ']': // OSC: ESC ] ... BEL or ESC \
+		for j := 2; j < len(s); j++ {
+			if s[j] == 0x07 {
+				return j + 1
+			}
+			if s[j] == 0x1b && j+1 < len(s) && s[j+1] == '\\' {
+				return j + 2
+			}
+		}
+		return len(s)
+	case '(', ')': // charset selection takes one more byte
+		if len(s) >= 3 {
+			return 3
+		}
+		return len(s)
+	default:
+		return 2
+	}
+}
+
+// lastLines returns the final n lines of text (all of it when n <= 0),
+// without trailing blank lines.
+func lastLines(text string, n int) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if n > 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
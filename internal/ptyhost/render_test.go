@@ -0,0 +1,57 @@
+package ptyhost
+
+import "testing"
+
+func TestRenderText(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"plain", "hello\nworld\n", "hello\nworld\n"},
+		{"crlf", "a\r\nb\r\n", "a\nb\n"},
+		{"color codes", "\x1b[1;32mok\x1b[0m done\n", "ok done\n"},
+		{"osc title", "\x1b]0;title\x07text\n", "text\n"},
+		{"carriage return overwrite", "50%\r100%\n", "100%\n"},
+		{"backspace", "abx\bc\n", "abc\n"},
+		{"charset", "\x1b(Bline\n", "line\n"},
+		{"unterminated line", "prompt> ", "prompt>\n"},
+		{"utf8", "❯ ready\n", "❯ ready\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderText([]byte(tt.raw)); got != tt.want {
+				t.Errorf("renderText(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLastLines(t *testing.T) {
+	text := "one\ntwo\nthree\n\n\n"
+	if got := lastLines(text, 2); got != "two\nthree" {
+		t.Errorf("lastLines(2) = %q", got)
+	}
+	if got := lastLines(text, 0); got != "one\ntwo\nthree" {
+		t.Errorf("lastLines(0) = %q", got)
+	}
+	if got := lastLines(text, 10); got != "one\ntwo\nthree" {
+		t.Errorf("lastLines(10) = %q", got)
+	}
+}
+
+func TestKeyBytes(t *testing.T) {
+	tests := map[string]string{
+		"Enter":  "\r",
+		"C-c":    "\x03",
+		"Escape": "\x1b",
+		"Up":     "\x1b[A",
+		"hello":  "hello",
+		"C-":     "C-",
+	}
+	for in, want := range tests {
+		if got := string(keyBytes(in)); got != want {
+			t.Errorf("keyBytes(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
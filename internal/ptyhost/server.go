@@ -0,0 +1,130 @@
+package ptyhost
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SocketPath returns the unix socket the daemon's Host listens on.
+func SocketPath(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "ptyhost.sock")
+}
+
+// request is one client call. Each connection carries a single request and
+// its response, encoded as JSON lines.
+type request struct {
+	Op      string `json:"op"`
+	Session string `json:"session,omitempty"`
+	WorkDir string `json:"work_dir,omitempty"`
+	Command string `json:"command,omitempty"`
+	Key     string `json:"key,omitempty"`
+	Value   string `json:"value,omitempty"`
+	Data    []byte `json:"data,omitempty"`
+	Lines   int    `json:"lines,omitempty"`
+	On      bool   `json:"on,omitempty"`
+}
+
+type response struct {
+	Error    string       `json:"error,omitempty"`
+	NotFound bool         `json:"not_found,omitempty"`
+	Bool     bool         `json:"bool,omitempty"`
+	String   string       `json:"string,omitempty"`
+	Strings  []string     `json:"strings,omitempty"`
+	Int      int          `json:"int,omitempty"`
+	Info     *SessionInfo `json:"info,omitempty"`
+}
+
+// Serve accepts client connections on socketPath until ctx is cancelled.
+// A stale socket file from a previous daemon is replaced.
+func Serve(ctx context.Context, h *Host, socketPath string) error {
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0o755); err != nil {
+		return fmt.Errorf("creating socket directory: %w", err)
+	}
+	_ = os.Remove(socketPath)
+	ln, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", socketPath, err)
+	}
+	if err := os.Chmod(socketPath, 0o600); err != nil {
+		_ = ln.Close()
+		return fmt.Errorf("securing socket: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = ln.Close()
+		_ = os.Remove(socketPath)
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			continue
+		}
+		go h.handleConn(conn)
+	}
+}
+
+func (h *Host) handleConn(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	var req request
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&req); err != nil {
+		_ = json.NewEncoder(conn).Encode(response{Error: "invalid request: " + err.Error()})
+		return
+	}
+	_ = json.NewEncoder(conn).Encode(h.dispatch(req))
+}
+
+// dispatch executes a request against the host.
+func (h *Host) dispatch(req request) response {
+	var resp response
+	var err error
+	switch req.Op {
+	case "new":
+		err = h.NewSession(req.Session, req.WorkDir, req.Command)
+	case "has":
+		resp.Bool = h.HasSession(req.Session)
+	case "list":
+		resp.Strings = h.ListSessions()
+	case "kill":
+		err = h.KillSession(req.Session)
+	case "alive":
+		resp.Bool = h.Alive(req.Session)
+	case "pid":
+		resp.Int, err = h.PID(req.Session)
+	case "setenv":
+		err = h.SetEnv(req.Session, req.Key, req.Value)
+	case "getenv":
+		resp.String, err = h.GetEnv(req.Session, req.Key)
+	case "remain":
+		err = h.SetRemainOnExit(req.Session, req.On)
+	case "write":
+		err = h.Write(req.Session, req.Data)
+	case "capture":
+		resp.String, err = h.Capture(req.Session, req.Lines)
+	case "info":
+		resp.Info, err = h.Info(req.Session)
+	default:
+		err = fmt.Errorf("unknown op %q", req.Op)
+	}
+	if err != nil {
+		resp.Error = err.Error()
+		resp.NotFound = errors.Is(err, ErrSessionNotFound)
+	}
+	return resp
+}
@@ -23,6 +23,16 @@ type BatchConfig struct {
 	// bisecting when tests fail. This avoids blaming an innocent MR for a
 	// flaky test. Default: true.
 	RetryBatchOnFlaky bool `json:"retry_batch_on_flaky"`
+
+	// Speculative replaces batch-then-bisect with speculative pipelines:
+	// gates run concurrently on each stack prefix (MR1, MR1+MR2, ...) in
+	// separate worktrees and the longest green prefix lands. Trades CPU for
+	// latency when gates are slow. Default: false.
+	Speculative bool `json:"speculative"`
+
+	// SpeculationDepth is how many stack prefixes to gate concurrently in
+	// speculative mode. 0 means MaxBatchSize. Default: 3.
+	SpeculationDepth int `json:"speculation_depth"`
 }
 
 // DefaultBatchConfig returns sensible defaults for batch processing.
@@ -31,6 +41,7 @@ func DefaultBatchConfig() *BatchConfig {
 		MaxBatchSize:      5,
 		BatchWaitTime:     30 * time.Second,
 		RetryBatchOnFlaky: true,
+		SpeculationDepth:  3,
 	}
 }
 
@@ -50,6 +61,15 @@ type BatchResult struct {
 
 	// Error is set if the batch processing encountered an infrastructure error.
 	Error error
+
+	// Speculations holds per-stack gate results in speculative mode.
+	Speculations []SpeculationResult
+
+	// Requeued is the set of MRs that were neither merged nor rejected and
+	// stay in the queue for the next cycle: in speculative mode, MRs beyond
+	// the speculation depth and MRs stacked after a failed stack. Their IDs
+	// are logged so the refinery output shows why they did not land.
+	Requeued []*MRInfo
 }
 
 // AssembleBatch selects up to MaxBatchSize MRs from the ready queue.
@@ -196,10 +216,15 @@ func (e *Engineer) getMergeMessage(mr *MRInfo) string {
 //  4. If red and RetryBatchOnFlaky: retry the full batch once
 //  5. If still red: bisect to isolate the culprit
 //  6. Re-batch good MRs for the next cycle
+//
+// When batchCfg.Speculative is set, the batch is handed to ProcessSpeculative.
 func (e *Engineer) ProcessBatch(ctx context.Context, batch []*MRInfo, target string, batchCfg *BatchConfig) *BatchResult {
 	if batchCfg == nil {
 		batchCfg = DefaultBatchConfig()
 	}
+	if batchCfg.Speculative {
+		return e.ProcessSpeculative(ctx, batch, target, batchCfg)
+	}
 
 	result := &BatchResult{}
 
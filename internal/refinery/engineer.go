@@ -83,6 +83,12 @@ type GateConfig struct {
 	// Post-squash gates run after the squash merge on the combined result,
 	// before pushing. On post-squash failure, the merge is reset.
 	Phase GatePhase `json:"phase"`
+
+	// Retry re-runs the gate's failures GateFlakeRetries times even before
+	// the gate ledger has seen it flake. Gates without it are only retried
+	// once their history shows a flake, so a real failure is reported
+	// after a single run.
+	Retry bool `json:"retry"`
 }
 
 // GateResult holds the outcome of a single gate execution.
@@ -91,6 +97,19 @@ type GateResult struct {
 	Success bool
 	Error   string
 	Elapsed time.Duration
+
+	// Attempts is how many times the gate ran (more than 1 when a
+	// historically flaky or retry-enabled gate was retried).
+	Attempts int
+
+	// Classification is GateFailureFlaky when the gate failed and then passed
+	// on retry, or GateFailureDeterministic when every attempt failed.
+	// Empty for gates that passed first time.
+	Classification string
+
+	// Quarantined is set when the gate's flake rate put it in quarantine:
+	// its failures are reported but do not fail the merge.
+	Quarantined bool
 }
 
 // MergeQueueConfig holds configuration for the merge queue processor.
@@ -141,6 +160,18 @@ type MergeQueueConfig struct {
 	// When true, all gates start simultaneously; any failure = overall failure.
 	GatesParallel bool `json:"gates_parallel"`
 
+	// GateFlakeRetries is how many times a failing gate is re-run when its
+	// history shows it has flaked before or it sets GateConfig.Retry. Other
+	// gates are not re-run. A pass on retry is classified as a flake and
+	// does not fail the merge. Zero disables retries.
+	GateFlakeRetries int `json:"gate_flake_retries"`
+
+	// GateQuarantineRate is the flake rate (0-1, fraction of recent commits on
+	// which the gate flaked) at which a gate is quarantined: its failures are
+	// still recorded and reported but no longer block merges. Zero disables
+	// quarantine.
+	GateQuarantineRate float64 `json:"gate_quarantine_rate"`
+
 	// StaleClaimWarningAfter is how long a claimed MR can sit without updates
 	// before it triggers a "warning" severity anomaly.
 	StaleClaimWarningAfter time.Duration `json:"stale_claim_warning_after"`
@@ -173,6 +204,7 @@ func DefaultMergeQueueConfig() *MergeQueueConfig {
 		TestCommand:             "",
 		DeleteMergedBranches:    true,
 		GatesParallel:           true, // gt-8b2i: run gates concurrently (~2x speedup)
+		GateFlakeRetries:        2,
 		RetryFlakyTests:         1,
 		PollInterval:            30 * time.Second,
 		MaxConcurrent:           1,
@@ -252,6 +284,8 @@ type Engineer struct {
 	mergeSlotRelease      func(holder string) error
 	mergeSlotMaxRetries   int           // Max retries for slot acquisition (0 = no retry)
 	mergeSlotRetryBackoff time.Duration // Initial backoff between retries
+	worktreeMu            sync.Mutex    // Serializes git worktree add/remove during speculation
+	gateHistory           *GateHistory  // Per-gate pass/fail ledger for flake detection
 }
 
 // NewEngineer creates a new Engineer for the given rig.
@@ -268,13 +302,14 @@ func NewEngineer(r *rig.Rig) *Engineer {
 	beadsClient := beads.New(r.Path)
 
 	return &Engineer{
-		rig:     r,
-		beads:   beadsClient,
-		git:     git.NewGit(gitDir),
-		config:  cfg,
-		workDir: gitDir,
-		output:  os.Stdout,
-		router:  mail.NewRouter(r.Path),
+		rig:         r,
+		beads:       beadsClient,
+		git:         git.NewGit(gitDir),
+		config:      cfg,
+		workDir:     gitDir,
+		output:      os.Stdout,
+		router:      mail.NewRouter(r.Path),
+		gateHistory: NewGateHistory(GateHistoryPath(r.Path)),
 		mergeSlotEnsureExists: func() (string, error) {
 			return beadsClient.MergeSlotEnsureExists()
 		},
@@ -334,7 +369,10 @@ func (e *Engineer) LoadConfig() error {
 		StaleClaimTimeout    *string                    `json:"stale_claim_timeout"`
 		Gates                map[string]*gateConfigRaw  `json:"gates"`
 		GatesParallel        *bool                      `json:"gates_parallel"`
+		GateFlakeRetries     *int                       `json:"gate_flake_retries"`
+		GateQuarantineRate   *float64                   `json:"gate_quarantine_rate"`
 		AutoPush             *bool                      `json:"auto_push"`
+		Batch                *batchConfigRaw            `json:"batch"`
 	}
 
 	if err := json.Unmarshal(rawConfig.MergeQueue, &mqRaw); err != nil {
@@ -385,7 +423,7 @@ func (e *Engineer) LoadConfig() error {
 	if mqRaw.Gates != nil {
 		e.config.Gates = make(map[string]*GateConfig, len(mqRaw.Gates))
 		for name, raw := range mqRaw.Gates {
-			gc := &GateConfig{Cmd: raw.Cmd}
+			gc := &GateConfig{Cmd: raw.Cmd, Retry: raw.Retry}
 			if raw.Timeout != "" {
 				dur, err := time.ParseDuration(raw.Timeout)
 				if err != nil {
@@ -410,19 +448,77 @@ func (e *Engineer) LoadConfig() error {
 	if mqRaw.GatesParallel != nil {
 		e.config.GatesParallel = *mqRaw.GatesParallel
 	}
+	if mqRaw.GateFlakeRetries != nil {
+		if *mqRaw.GateFlakeRetries < 0 {
+			return fmt.Errorf("gate_flake_retries must not be negative, got %d", *mqRaw.GateFlakeRetries)
+		}
+		e.config.GateFlakeRetries = *mqRaw.GateFlakeRetries
+	}
+	if mqRaw.GateQuarantineRate != nil {
+		if *mqRaw.GateQuarantineRate < 0 || *mqRaw.GateQuarantineRate > 1 {
+			return fmt.Errorf("gate_quarantine_rate must be between 0 and 1, got %v", *mqRaw.GateQuarantineRate)
+		}
+		e.config.GateQuarantineRate = *mqRaw.GateQuarantineRate
+	}
 	if mqRaw.AutoPush != nil {
 		e.config.AutoPush = *mqRaw.AutoPush
 	}
+	if mqRaw.Batch != nil {
+		batch, err := mqRaw.Batch.toConfig()
+		if err != nil {
+			return err
+		}
+		e.config.Batch = batch
+	}
 
 	return nil
 }
 
+// batchConfigRaw is the JSON-friendly representation of a batch config
+// with batch_wait_time as a string duration.
+type batchConfigRaw struct {
+	MaxBatchSize      *int   `json:"max_batch_size"`
+	BatchWaitTime     string `json:"batch_wait_time"`
+	RetryBatchOnFlaky *bool  `json:"retry_batch_on_flaky"`
+	Speculative       *bool  `json:"speculative"`
+	SpeculationDepth  *int   `json:"speculation_depth"`
+}
+
+// toConfig applies the raw values over DefaultBatchConfig.
+func (raw *batchConfigRaw) toConfig() (*BatchConfig, error) {
+	cfg := DefaultBatchConfig()
+	if raw.MaxBatchSize != nil {
+		cfg.MaxBatchSize = *raw.MaxBatchSize
+	}
+	if raw.BatchWaitTime != "" {
+		dur, err := time.ParseDuration(raw.BatchWaitTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid batch_wait_time %q: %w", raw.BatchWaitTime, err)
+		}
+		cfg.BatchWaitTime = dur
+	}
+	if raw.RetryBatchOnFlaky != nil {
+		cfg.RetryBatchOnFlaky = *raw.RetryBatchOnFlaky
+	}
+	if raw.Speculative != nil {
+		cfg.Speculative = *raw.Speculative
+	}
+	if raw.SpeculationDepth != nil {
+		if *raw.SpeculationDepth < 0 {
+			return nil, fmt.Errorf("speculation_depth must not be negative, got %d", *raw.SpeculationDepth)
+		}
+		cfg.SpeculationDepth = *raw.SpeculationDepth
+	}
+	return cfg, nil
+}
+
 // gateConfigRaw is the JSON-friendly representation of a gate config
 // with timeout as a string duration.
 type gateConfigRaw struct {
 	Cmd     string `json:"cmd"`
 	Timeout string `json:"timeout"`
 	Phase   string `json:"phase"`
+	Retry   bool   `json:"retry"`
 }
 
 // Config returns the current merge queue configuration.
@@ -850,6 +946,102 @@ func (e *Engineer) runGate(ctx context.Context, name string, gate *GateConfig) G
 	}
 }
 
+// runTrackedGate runs a gate, records every attempt in the gate ledger, and
+// retries failures GateFlakeRetries times for gates whose history shows
+// they flake or that opt in with GateConfig.Retry. A pass on retry
+// classifies the earlier failure as flaky; failing every attempt classifies
+// it as deterministic.
+func (e *Engineer) runTrackedGate(ctx context.Context, name string, gate *GateConfig, phase GatePhase, commit string, stats GateStats) GateResult {
+	retries := 0
+	if stats.Flaky() || gate.Retry {
+		retries = e.config.GateFlakeRetries
+	}
+
+	var elapsed time.Duration
+	var result GateResult
+	for attempt := 1; attempt <= retries+1; attempt++ {
+		if attempt > 1 {
+			if ctx.Err() != nil {
+				break
+			}
+			if stats.Flaky() {
+				_, _ = fmt.Fprintf(e.output, "[Engineer] Gate %q: failed, retrying (attempt %d/%d, %d flake(s) in last %d commits)\n",
+					name, attempt, retries+1, stats.FlakyCommits, stats.Commits)
+			} else {
+				_, _ = fmt.Fprintf(e.output, "[Engineer] Gate %q: failed, retrying (attempt %d/%d, retry enabled for this gate)\n",
+					name, attempt, retries+1)
+			}
+		}
+		result = e.runGate(ctx, name, gate)
+		elapsed += result.Elapsed
+		result.Attempts = attempt
+		e.recordGateRun(GateRun{
+			Gate:    name,
+			Phase:   phase,
+			Commit:  commit,
+			Attempt: attempt,
+			Passed:  result.Success,
+			Error:   result.Error,
+			Elapsed: result.Elapsed,
+			At:      time.Now().UTC(),
+		})
+		if result.Success {
+			break
+		}
+	}
+	result.Elapsed = elapsed
+
+	switch {
+	case result.Success && result.Attempts > 1:
+		result.Classification = GateFailureFlaky
+	case !result.Success:
+		result.Classification = GateFailureDeterministic
+		result.Quarantined = stats.Quarantined
+	}
+	return result
+}
+
+// gateStats returns recent per-gate stats from the ledger, keyed by gate name.
+func (e *Engineer) gateStats() map[string]GateStats {
+	stats := make(map[string]GateStats)
+	if e.gateHistory == nil {
+		return stats
+	}
+	runs, err := e.gateHistory.Load()
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to load gate history: %v\n", err)
+		return stats
+	}
+	summary := SummarizeGateRuns(runs, gateHistoryWindow)
+	ClassifyGates(summary, e.config.GateQuarantineRate)
+	for _, s := range summary {
+		stats[s.Gate] = s
+	}
+	return stats
+}
+
+// recordGateRun appends a run to the gate ledger. Failures are logged, not fatal.
+func (e *Engineer) recordGateRun(run GateRun) {
+	if e.gateHistory == nil {
+		return
+	}
+	if err := e.gateHistory.Record(run); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to record gate run: %v\n", err)
+	}
+}
+
+// GateReport summarizes the rig's recorded gate history, classifying gates
+// with the configured quarantine threshold.
+func (e *Engineer) GateReport() ([]GateStats, error) {
+	runs, err := e.gateHistory.Load()
+	if err != nil {
+		return nil, err
+	}
+	stats := SummarizeGateRuns(runs, gateHistoryWindow)
+	ClassifyGates(stats, e.config.GateQuarantineRate)
+	return stats, nil
+}
+
 // runGates executes all pre-merge gates (backward-compatible entry point).
 func (e *Engineer) runGates(ctx context.Context) ProcessResult {
 	return e.runGatesForPhase(ctx, GatePhasePreMerge)
@@ -857,7 +1049,8 @@ func (e *Engineer) runGates(ctx context.Context) ProcessResult {
 
 // runGatesForPhase executes gates matching the given phase.
 // Gates run in parallel if GatesParallel is true; otherwise sequentially.
-// Any single gate failure means overall failure.
+// Any single gate failure means overall failure, except for gates that are
+// quarantined for flaking (see GateQuarantineRate).
 func (e *Engineer) runGatesForPhase(ctx context.Context, phase GatePhase) ProcessResult {
 	// Filter gates for this phase. Empty phase is treated as pre-merge (default).
 	gates := make(map[string]*GateConfig)
@@ -884,6 +1077,12 @@ func (e *Engineer) runGatesForPhase(ctx context.Context, phase GatePhase) Proces
 	parallel := e.config.GatesParallel && phase == GatePhasePreMerge // post-squash always sequential
 	_, _ = fmt.Fprintf(e.output, "[Engineer] Running %d %s gate(s) (parallel=%v)\n", len(names), phase, parallel)
 
+	history := e.gateStats()
+	commit := ""
+	if sha, err := git.NewGit(e.workDir).Rev("HEAD"); err == nil {
+		commit = sha
+	}
+
 	var results []GateResult
 
 	if parallel {
@@ -894,29 +1093,37 @@ func (e *Engineer) runGatesForPhase(ctx context.Context, phase GatePhase) Proces
 			go func(idx int, gateName string) {
 				defer wg.Done()
 				_, _ = fmt.Fprintf(e.output, "[Engineer] Gate %q: starting (%s)\n", gateName, gates[gateName].Cmd)
-				results[idx] = e.runGate(ctx, gateName, gates[gateName])
+				results[idx] = e.runTrackedGate(ctx, gateName, gates[gateName], phase, commit, history[gateName])
 			}(i, name)
 		}
 		wg.Wait()
 	} else {
 		for _, name := range names {
 			_, _ = fmt.Fprintf(e.output, "[Engineer] Gate %q: starting (%s)\n", name, gates[name].Cmd)
-			result := e.runGate(ctx, name, gates[name])
+			result := e.runTrackedGate(ctx, name, gates[name], phase, commit, history[name])
 			results = append(results, result)
-			if !result.Success {
+			if !result.Success && !result.Quarantined {
 				// Sequential mode: stop on first failure
 				break
 			}
 		}
 	}
+	if e.gateHistory != nil {
+		_ = e.gateHistory.Compact(gateHistoryLimit)
+	}
 
 	// Report results
 	var failures []string
 	for _, r := range results {
-		if r.Success {
+		switch {
+		case r.Success && r.Classification == GateFailureFlaky:
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Gate %q: passed on attempt %d (%v) - earlier failure classified as flaky\n", r.Name, r.Attempts, r.Elapsed.Truncate(time.Millisecond))
+		case r.Success:
 			_, _ = fmt.Fprintf(e.output, "[Engineer] Gate %q: passed (%v)\n", r.Name, r.Elapsed.Truncate(time.Millisecond))
-		} else {
-			_, _ = fmt.Fprintf(e.output, "[Engineer] Gate %q: FAILED (%v) - %s\n", r.Name, r.Elapsed.Truncate(time.Millisecond), r.Error)
+		case r.Quarantined:
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Gate %q: FAILED but quarantined as flaky, not blocking (%v) - %s\n", r.Name, r.Elapsed.Truncate(time.Millisecond), r.Error)
+		default:
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Gate %q: FAILED (%v, %s) - %s\n", r.Name, r.Elapsed.Truncate(time.Millisecond), r.Classification, r.Error)
 			failures = append(failures, fmt.Sprintf("%s: %s", r.Name, r.Error))
 		}
 	}
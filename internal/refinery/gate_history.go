@@ -0,0 +1,284 @@
+package refinery
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Gate failure classifications.
+const (
+	// GateFailureFlaky means the gate failed but passed when re-run on the
+	// same commit.
+	GateFailureFlaky = "flaky"
+
+	// GateFailureDeterministic means the gate failed on every attempt.
+	GateFailureDeterministic = "deterministic"
+)
+
+const (
+	// gateHistoryWindow is how many recent runs of a gate are considered when
+	// deciding whether it is flaky.
+	gateHistoryWindow = 50
+
+	// gateHistoryLimit caps the ledger size; older runs are dropped on compaction.
+	gateHistoryLimit = 2000
+
+	// gateQuarantineMinCommits is the minimum number of distinct commits a gate
+	// must have run on before it can be quarantined.
+	gateQuarantineMinCommits = 10
+
+	// gateErrorMaxLen caps the error text stored per run.
+	gateErrorMaxLen = 200
+)
+
+// GateRun is one execution of a quality gate, as recorded in the gate ledger.
+type GateRun struct {
+	Gate    string        `json:"gate"`
+	Phase   GatePhase     `json:"phase,omitempty"`
+	Commit  string        `json:"commit,omitempty"`
+	Attempt int           `json:"attempt"`
+	Passed  bool          `json:"passed"`
+	Error   string        `json:"error,omitempty"`
+	Elapsed time.Duration `json:"elapsed"`
+	At      time.Time     `json:"at"`
+}
+
+// GateStats summarizes a gate's recent history.
+type GateStats struct {
+	Gate     string    `json:"gate"`
+	Runs     int       `json:"runs"`
+	Passes   int       `json:"passes"`
+	Failures int       `json:"failures"`
+	Retries  int       `json:"retries"`
+	LastRun  time.Time `json:"last_run"`
+
+	// Commits is the number of distinct commits the gate ran on.
+	Commits int `json:"commits"`
+
+	// FlakyCommits counts commits on which the gate both failed and passed.
+	FlakyCommits int `json:"flaky_commits"`
+
+	// BrokenCommits counts commits on which the gate only ever failed.
+	BrokenCommits int `json:"broken_commits"`
+
+	// AvgElapsed is the mean run time of the gate.
+	AvgElapsed time.Duration `json:"avg_elapsed"`
+
+	// Quarantined is set by Classify when the flake rate crosses the
+	// configured quarantine threshold.
+	Quarantined bool `json:"quarantined"`
+}
+
+// FlakeRate is the fraction of commits on which the gate flaked.
+func (s GateStats) FlakeRate() float64 {
+	if s.Commits == 0 {
+		return 0
+	}
+	return float64(s.FlakyCommits) / float64(s.Commits)
+}
+
+// Flaky reports whether the gate has flaked at least once recently.
+func (s GateStats) Flaky() bool {
+	return s.FlakyCommits > 0
+}
+
+// Status is a one-word summary for reports: "quarantined", "flaky" or "stable".
+func (s GateStats) Status() string {
+	switch {
+	case s.Quarantined:
+		return "quarantined"
+	case s.Flaky():
+		return "flaky"
+	default:
+		return "stable"
+	}
+}
+
+// GateHistory is an append-only JSONL ledger of gate runs for one rig.
+// It lives outside the refinery worktree so merges never see it.
+type GateHistory struct {
+	path string
+	mu   sync.Mutex
+}
+
+// GateHistoryPath returns the gate ledger location for a rig.
+func GateHistoryPath(rigPath string) string {
+	return filepath.Join(rigPath, ".runtime", "refinery", "gate-history.jsonl")
+}
+
+// NewGateHistory returns a ledger backed by the file at path.
+func NewGateHistory(path string) *GateHistory {
+	return &GateHistory{path: path}
+}
+
+// Record appends a run to the ledger.
+func (h *GateHistory) Record(run GateRun) error {
+	if len(run.Error) > gateErrorMaxLen {
+		run.Error = run.Error[:gateErrorMaxLen] + "..."
+	}
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return fmt.Errorf("creating gate history directory: %w", err)
+	}
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("opening gate history: %w", err)
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// Load returns all recorded runs, oldest first. A missing ledger is empty.
+// Malformed lines (e.g. a torn write) are skipped.
+func (h *GateHistory) Load() ([]GateRun, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.load()
+}
+
+func (h *GateHistory) load() ([]GateRun, error) {
+	f, err := os.Open(h.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening gate history: %w", err)
+	}
+	defer f.Close()
+
+	var runs []GateRun
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var run GateRun
+		if json.Unmarshal([]byte(line), &run) != nil {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	return runs, scanner.Err()
+}
+
+// Compact rewrites the ledger keeping only the newest limit runs.
+func (h *GateHistory) Compact(limit int) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	runs, err := h.load()
+	if err != nil || len(runs) <= limit {
+		return err
+	}
+	runs = runs[len(runs)-limit:]
+
+	var sb strings.Builder
+	for _, run := range runs {
+		data, err := json.Marshal(run)
+		if err != nil {
+			return err
+		}
+		sb.Write(data)
+		sb.WriteByte('\n')
+	}
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("writing gate history: %w", err)
+	}
+	return os.Rename(tmp, h.path)
+}
+
+// SummarizeGateRuns computes per-gate stats over each gate's most recent
+// window runs (all runs when window <= 0), sorted by gate name.
+//
+// Runs are grouped by commit: a commit on which the gate both failed and
+// passed is a flake, since the code under test did not change between runs.
+// Runs with no recorded commit are counted on their own.
+func SummarizeGateRuns(runs []GateRun, window int) []GateStats {
+	byGate := make(map[string][]GateRun)
+	for _, run := range runs {
+		byGate[run.Gate] = append(byGate[run.Gate], run)
+	}
+
+	stats := make([]GateStats, 0, len(byGate))
+	for gate, gateRuns := range byGate {
+		if window > 0 && len(gateRuns) > window {
+			gateRuns = gateRuns[len(gateRuns)-window:]
+		}
+		s := GateStats{Gate: gate}
+		type outcome struct{ passed, failed bool }
+		commits := make(map[string]*outcome)
+		var total time.Duration
+		for i, run := range gateRuns {
+			s.Runs++
+			total += run.Elapsed
+			if run.Passed {
+				s.Passes++
+			} else {
+				s.Failures++
+			}
+			if run.Attempt > 1 {
+				s.Retries++
+			}
+			if run.At.After(s.LastRun) {
+				s.LastRun = run.At
+			}
+			key := run.Commit
+			if key == "" {
+				key = fmt.Sprintf("#%d", i)
+			}
+			o := commits[key]
+			if o == nil {
+				o = &outcome{}
+				commits[key] = o
+			}
+			if run.Passed {
+				o.passed = true
+			} else {
+				o.failed = true
+			}
+		}
+		s.Commits = len(commits)
+		for _, o := range commits {
+			switch {
+			case o.passed && o.failed:
+				s.FlakyCommits++
+			case o.failed:
+				s.BrokenCommits++
+			}
+		}
+		if s.Runs > 0 {
+			s.AvgElapsed = total / time.Duration(s.Runs)
+		}
+		stats = append(stats, s)
+	}
+
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Gate < stats[j].Gate })
+	return stats
+}
+
+// ClassifyGates marks gates whose flake rate reaches quarantineRate as
+// quarantined. A zero rate disables quarantine. Gates need at least
+// gateQuarantineMinCommits commits of history before they can be quarantined.
+func ClassifyGates(stats []GateStats, quarantineRate float64) {
+	for i := range stats {
+		stats[i].Quarantined = quarantineRate > 0 &&
+			stats[i].Commits >= gateQuarantineMinCommits &&
+			stats[i].FlakeRate() >= quarantineRate
+	}
+}
@@ -0,0 +1,323 @@
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func gateRun(gate, commit string, attempt int, passed bool) GateRun {
+	return GateRun{Gate: gate, Commit: commit, Attempt: attempt, Passed: passed, Elapsed: time.Second, At: time.Now()}
+}
+
+// seedFlakes records n commits on which gate failed once and then passed.
+func seedFlakes(t *testing.T, h *GateHistory, gate string, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		commit := fmt.Sprintf("flaky-%d", i)
+		for _, run := range []GateRun{gateRun(gate, commit, 1, false), gateRun(gate, commit, 2, true)} {
+			if err := h.Record(run); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestSummarizeGateRuns(t *testing.T) {
+	runs := []GateRun{
+		gateRun("test", "c1", 1, true),
+		gateRun("test", "c2", 1, false),
+		gateRun("test", "c2", 2, true),
+		gateRun("test", "c3", 1, false),
+		gateRun("test", "c3", 2, false),
+		gateRun("lint", "c1", 1, true),
+		gateRun("lint", "", 1, false),
+		gateRun("lint", "", 1, true),
+	}
+
+	stats := SummarizeGateRuns(runs, 0)
+	if len(stats) != 2 || stats[0].Gate != "lint" || stats[1].Gate != "test" {
+		t.Fatalf("stats = %+v, want lint and test sorted", stats)
+	}
+
+	test := stats[1]
+	if test.Runs != 5 || test.Passes != 2 || test.Failures != 3 || test.Retries != 2 {
+		t.Errorf("test counts = %+v", test)
+	}
+	if test.Commits != 3 || test.FlakyCommits != 1 || test.BrokenCommits != 1 {
+		t.Errorf("test commits = %d flaky = %d broken = %d, want 3/1/1", test.Commits, test.FlakyCommits, test.BrokenCommits)
+	}
+	if !test.Flaky() || test.Status() != "flaky" {
+		t.Errorf("test should be flaky, status %q", test.Status())
+	}
+
+	// Runs without a commit never pair up into a flake.
+	lint := stats[0]
+	if lint.Commits != 3 || lint.FlakyCommits != 0 || lint.Flaky() {
+		t.Errorf("lint = %+v, want 3 commits and no flakes", lint)
+	}
+}
+
+func TestSummarizeGateRuns_Window(t *testing.T) {
+	runs := []GateRun{
+		gateRun("test", "old", 1, false),
+		gateRun("test", "old", 2, true),
+	}
+	for i := 0; i < 4; i++ {
+		runs = append(runs, gateRun("test", fmt.Sprintf("new-%d", i), 1, true))
+	}
+
+	if s := SummarizeGateRuns(runs, 0)[0]; !s.Flaky() {
+		t.Error("full history should include the old flake")
+	}
+	if s := SummarizeGateRuns(runs, 4)[0]; s.Flaky() || s.Runs != 4 {
+		t.Errorf("windowed stats = %+v, want 4 clean runs", s)
+	}
+}
+
+func TestClassifyGates(t *testing.T) {
+	stats := []GateStats{
+		{Gate: "noisy", Commits: 10, FlakyCommits: 5},
+		{Gate: "new", Commits: 2, FlakyCommits: 2},
+		{Gate: "rare", Commits: 20, FlakyCommits: 1},
+	}
+
+	ClassifyGates(stats, 0)
+	for _, s := range stats {
+		if s.Quarantined {
+			t.Errorf("%s quarantined with quarantine disabled", s.Gate)
+		}
+	}
+
+	ClassifyGates(stats, 0.25)
+	want := map[string]bool{"noisy": true, "new": false, "rare": false}
+	for _, s := range stats {
+		if s.Quarantined != want[s.Gate] {
+			t.Errorf("%s quarantined = %v, want %v", s.Gate, s.Quarantined, want[s.Gate])
+		}
+	}
+	if stats[0].Status() != "quarantined" {
+		t.Errorf("status = %q, want quarantined", stats[0].Status())
+	}
+}
+
+func TestGateHistory_RecordLoadCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "gate-history.jsonl")
+	h := NewGateHistory(path)
+
+	runs, err := h.Load()
+	if err != nil || len(runs) != 0 {
+		t.Fatalf("missing ledger: runs=%v err=%v", runs, err)
+	}
+
+	for i := 0; i < 5; i++ {
+		run := gateRun("test", fmt.Sprintf("c%d", i), 1, true)
+		run.Error = strings.Repeat("x", gateErrorMaxLen*2)
+		if err := h.Record(run); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A torn trailing write is skipped.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"gate":"te`)
+	_ = f.Close()
+
+	runs, err = h.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 5 {
+		t.Fatalf("loaded %d runs, want 5", len(runs))
+	}
+	if len(runs[0].Error) > gateErrorMaxLen+3 {
+		t.Errorf("error not truncated: %d bytes", len(runs[0].Error))
+	}
+
+	if err := h.Compact(2); err != nil {
+		t.Fatal(err)
+	}
+	runs, _ = h.Load()
+	if len(runs) != 2 || runs[0].Commit != "c3" || runs[1].Commit != "c4" {
+		t.Errorf("compacted runs = %+v, want c3 and c4", runs)
+	}
+}
+
+// failOnceGateCmd fails the first time it runs and passes afterwards, like a
+// gate tripping over infrastructure noise.
+func failOnceGateCmd(t *testing.T) string {
+	marker := filepath.Join(t.TempDir(), "ran")
+	return fmt.Sprintf("if [ -f %q ]; then exit 0; fi; touch %q; exit 1", marker, marker)
+}
+
+func TestRunGatesForPhase_RetriesHistoricallyFlakyGate(t *testing.T) {
+	workDir, g, cleanup := testGitRepo(t)
+	defer cleanup()
+
+	e := newTestEngineer(t, workDir, g)
+	e.config.Gates = map[string]*GateConfig{"test": {Cmd: failOnceGateCmd(t)}}
+	seedFlakes(t, e.gateHistory, "test", 1)
+
+	result := e.runGatesForPhase(context.Background(), GatePhasePreMerge)
+	if !result.Success {
+		t.Fatalf("expected flaky gate to pass on retry, got %q", result.Error)
+	}
+	if out := e.output.(*bytes.Buffer).String(); !strings.Contains(out, "classified as flaky") {
+		t.Errorf("output should report the flake:\n%s", out)
+	}
+
+	runs, _ := e.gateHistory.Load()
+	head, _ := g.Rev("HEAD")
+	var recorded []GateRun
+	for _, run := range runs {
+		if run.Commit == head {
+			recorded = append(recorded, run)
+		}
+	}
+	if len(recorded) != 2 || recorded[0].Passed || !recorded[1].Passed || recorded[1].Attempt != 2 {
+		t.Errorf("recorded runs for HEAD = %+v, want fail then pass", recorded)
+	}
+}
+
+func TestRunGatesForPhase_RetryOptInRecordsFirstFlake(t *testing.T) {
+	workDir, g, cleanup := testGitRepo(t)
+	defer cleanup()
+
+	e := newTestEngineer(t, workDir, g)
+	e.config.Gates = map[string]*GateConfig{"test": {Cmd: failOnceGateCmd(t), Retry: true}}
+
+	// No flake history, but the gate opted in: the failure is retried and
+	// the pass on retry is recorded as the gate's first flake.
+	result := e.runGatesForPhase(context.Background(), GatePhasePreMerge)
+	if !result.Success {
+		t.Fatalf("expected gate to pass on retry, got %q", result.Error)
+	}
+	if out := e.output.(*bytes.Buffer).String(); !strings.Contains(out, "classified as flaky") {
+		t.Errorf("output should report the flake:\n%s", out)
+	}
+	stats, err := e.GateReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 1 || stats[0].Runs != 2 || stats[0].FlakyCommits != 1 || !stats[0].Flaky() {
+		t.Errorf("stats = %+v, want one flaky commit from two runs", stats)
+	}
+}
+
+func TestRunGatesForPhase_StableGateFailsDeterministically(t *testing.T) {
+	workDir, g, cleanup := testGitRepo(t)
+	defer cleanup()
+
+	e := newTestEngineer(t, workDir, g)
+	e.config.Gates = map[string]*GateConfig{"test": {Cmd: "exit 1"}}
+
+	// No flake history and no opt-in: not retried, failed as deterministic.
+	result := e.runGatesForPhase(context.Background(), GatePhasePreMerge)
+	if result.Success || !result.TestsFailed {
+		t.Fatalf("expected gate failure, got %+v", result)
+	}
+	if out := e.output.(*bytes.Buffer).String(); !strings.Contains(out, GateFailureDeterministic) {
+		t.Errorf("output should classify the failure as deterministic:\n%s", out)
+	}
+	if runs, _ := e.gateHistory.Load(); len(runs) != 1 {
+		t.Errorf("recorded %d runs, want 1", len(runs))
+	}
+}
+
+func TestRunGatesForPhase_FirstFailureNotRetriedWithoutOptIn(t *testing.T) {
+	workDir, g, cleanup := testGitRepo(t)
+	defer cleanup()
+
+	e := newTestEngineer(t, workDir, g)
+	e.config.Gates = map[string]*GateConfig{"test": {Cmd: failOnceGateCmd(t)}}
+
+	// A gate that would pass on retry still fails: only gates in the flake
+	// ledger or with retry set are re-run.
+	if result := e.runGatesForPhase(context.Background(), GatePhasePreMerge); result.Success {
+		t.Fatal("expected failure without flake history or retry opt-in")
+	}
+	if out := e.output.(*bytes.Buffer).String(); strings.Contains(out, "retrying") {
+		t.Errorf("gate should not be retried:\n%s", out)
+	}
+}
+
+func TestRunGatesForPhase_ZeroRetriesDisablesRetry(t *testing.T) {
+	workDir, g, cleanup := testGitRepo(t)
+	defer cleanup()
+
+	e := newTestEngineer(t, workDir, g)
+	e.config.Gates = map[string]*GateConfig{"test": {Cmd: failOnceGateCmd(t), Retry: true}}
+	e.config.GateFlakeRetries = 0
+
+	if result := e.runGatesForPhase(context.Background(), GatePhasePreMerge); result.Success {
+		t.Fatal("expected failure with retries disabled")
+	}
+	if runs, _ := e.gateHistory.Load(); len(runs) != 1 {
+		t.Errorf("recorded %d runs, want 1", len(runs))
+	}
+}
+
+func TestRunGatesForPhase_QuarantinedGateDoesNotBlock(t *testing.T) {
+	workDir, g, cleanup := testGitRepo(t)
+	defer cleanup()
+
+	e := newTestEngineer(t, workDir, g)
+	e.config.Gates = map[string]*GateConfig{"e2e": {Cmd: "exit 1"}}
+	e.config.GateQuarantineRate = 0.5
+	seedFlakes(t, e.gateHistory, "e2e", gateQuarantineMinCommits)
+
+	result := e.runGatesForPhase(context.Background(), GatePhasePreMerge)
+	if !result.Success {
+		t.Fatalf("quarantined gate should not block, got %q", result.Error)
+	}
+	if out := e.output.(*bytes.Buffer).String(); !strings.Contains(out, "quarantined") {
+		t.Errorf("output should mention quarantine:\n%s", out)
+	}
+
+	// Still retried and recorded like any flaky gate.
+	stats, err := e.GateReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats[0].Runs != 2*gateQuarantineMinCommits+1+e.config.GateFlakeRetries {
+		t.Errorf("runs = %d, want seeded runs plus %d attempts", stats[0].Runs, 1+e.config.GateFlakeRetries)
+	}
+}
+
+func TestEngineer_LoadConfig_GateFlakeSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		mq      string
+		wantErr bool
+	}{
+		{"valid", `{"gate_flake_retries": 3, "gate_quarantine_rate": 0.2}`, false},
+		{"negative retries", `{"gate_flake_retries": -1}`, true},
+		{"rate above one", `{"gate_quarantine_rate": 1.5}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			data := []byte(`{"type":"rig","version":1,"name":"test-rig","merge_queue":` + tt.mq + `}`)
+			if err := os.WriteFile(filepath.Join(tmpDir, "config.json"), data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			e := NewEngineer(&rig.Rig{Name: "test-rig", Path: tmpDir})
+			err := e.LoadConfig()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (e.config.GateFlakeRetries != 3 || e.config.GateQuarantineRate != 0.2) {
+				t.Errorf("config = retries %d rate %v", e.config.GateFlakeRetries, e.config.GateQuarantineRate)
+			}
+		})
+	}
+}
@@ -71,7 +71,7 @@ func (m *Manager) SessionName() string {
 // ZFC: tmux session existence is the source of truth for session state,
 // but agent liveness determines if the session is actually functional.
 func (m *Manager) IsRunning() (bool, error) {
-	t := tmux.NewTmux()
+	t := m.sessions()
 	sessionName := m.SessionName()
 	status := t.CheckSessionHealth(sessionName, 0)
 	return status == tmux.SessionHealthy, nil
@@ -83,14 +83,14 @@ func (m *Manager) IsRunning() (bool, error) {
 // Returns the detailed ZombieStatus for callers that need to distinguish
 // between different failure modes.
 func (m *Manager) IsHealthy(maxInactivity time.Duration) tmux.ZombieStatus {
-	t := tmux.NewTmux()
+	t := m.sessions()
 	return t.CheckSessionHealth(m.SessionName(), maxInactivity)
 }
 
 // Status returns information about the refinery session.
 // ZFC-compliant: tmux session is the source of truth.
 func (m *Manager) Status() (*tmux.SessionInfo, error) {
-	t := tmux.NewTmux()
+	t := m.sessions()
 	sessionID := m.SessionName()
 
 	running, err := t.HasSession(sessionID)
@@ -104,13 +104,18 @@ func (m *Manager) Status() (*tmux.SessionInfo, error) {
 	return t.GetSessionInfo(sessionID)
 }
 
+// sessions returns the session backend the town is configured to use.
+func (m *Manager) sessions() session.SessionBackend {
+	return session.NewBackend(filepath.Dir(m.rig.Path))
+}
+
 // Start starts the refinery.
 // If foreground is true, returns an error (foreground mode deprecated).
 // Otherwise, spawns a Claude agent in a tmux session to process the merge queue.
 // The agentOverride parameter allows specifying an agent alias to use instead of the town default.
 // ZFC-compliant: no state file, tmux session is source of truth.
 func (m *Manager) Start(foreground bool, agentOverride string) error {
-	t := tmux.NewTmux()
+	t := m.sessions()
 	sessionID := m.SessionName()
 
 	if foreground {
@@ -225,12 +230,16 @@ func (m *Manager) Start(foreground bool, agentOverride string) error {
 	_ = t.SetEnvironment(sessionID, "GT_RUN", runID)
 
 	// Apply theme (non-fatal: theming failure doesn't affect operation)
-	theme := tmux.ResolveSessionTheme(townRoot, m.rig.Name, "refinery")
-	_ = t.ConfigureGasTownSession(sessionID, theme, m.rig.Name, "refinery", "refinery")
+	if themer, ok := t.(session.Themer); ok {
+		theme := tmux.ResolveSessionTheme(townRoot, m.rig.Name, "refinery")
+		_ = themer.ConfigureGasTownSession(sessionID, theme, m.rig.Name, "refinery", "refinery")
+	}
 
 	// Accept startup dialogs (workspace trust + bypass permissions) if they appear.
 	// Must be before WaitForRuntimeReady to avoid race where dialog blocks prompt detection.
-	_ = t.AcceptStartupDialogs(sessionID)
+	if acceptor, ok := t.(session.DialogAcceptor); ok {
+		_ = acceptor.AcceptStartupDialogs(sessionID)
+	}
 
 	// Wait for Claude to start and show its prompt - fatal if Claude fails to launch
 	// WaitForRuntimeReady waits for the runtime to be ready
@@ -257,7 +266,7 @@ func (m *Manager) Start(foreground bool, agentOverride string) error {
 
 	// Stream refinery's Claude Code JSONL conversation log to VictoriaLogs (opt-in).
 	if os.Getenv("GT_LOG_AGENT_OUTPUT") == "true" && os.Getenv("GT_OTEL_LOGS_URL") != "" {
-		if err := session.ActivateAgentLogging(sessionID, refineryRigDir, runID); err != nil {
+		if err := session.ActivateAgentLogging(t, sessionID, refineryRigDir, runID); err != nil {
 			log.Printf("warning: agent log watcher setup failed for %s: %v", sessionID, err)
 		}
 	}
@@ -309,7 +318,7 @@ func (m *Manager) repairRefineryWorktree(refineryRigDir string) error {
 // Stop stops the refinery.
 // ZFC-compliant: tmux session is the source of truth.
 func (m *Manager) Stop() error {
-	t := tmux.NewTmux()
+	t := m.sessions()
 	sessionID := m.SessionName()
 
 	// Check if tmux session exists
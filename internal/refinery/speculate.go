@@ -0,0 +1,318 @@
+package refinery
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// Speculation outcomes recorded on MR beads (speculation_result field).
+const (
+	SpeculationPassed = "passed"
+	SpeculationFailed = "failed"
+	SpeculationError  = "error"
+)
+
+// SpeculationResult is the outcome of running gates on one speculative stack.
+// Stack k holds the first k MRs of the batch: target ← MR1 ← ... ← MRk.
+type SpeculationResult struct {
+	// Depth is the number of MRs in the stack.
+	Depth int
+
+	// MRs are the IDs of the stacked MRs, in merge order.
+	MRs []string
+
+	// Result is SpeculationPassed, SpeculationFailed, or SpeculationError.
+	Result string
+
+	// Error describes the failure, if any.
+	Error string
+
+	// Elapsed is the wall-clock time spent preparing the worktree and running gates.
+	Elapsed time.Duration
+}
+
+// Passed reports whether the stack's gates were green.
+func (s SpeculationResult) Passed() bool {
+	return s.Result == SpeculationPassed
+}
+
+// speculationDepth returns how many stacks to gate concurrently.
+func (c *BatchConfig) speculationDepth() int {
+	if c.SpeculationDepth > 0 {
+		return c.SpeculationDepth
+	}
+	if c.MaxBatchSize > 0 {
+		return c.MaxBatchSize
+	}
+	return DefaultBatchConfig().SpeculationDepth
+}
+
+// ProcessSpeculative processes a batch with speculative parallel pipelines.
+//
+// Algorithm:
+//  1. Build the rebase stack (target ← MR1 ← MR2 ← ... ← MRn), dropping conflicts
+//  2. Check out each prefix (MR1, MR1+MR2, ...) up to SpeculationDepth in its
+//     own detached worktree and run gates in all of them concurrently
+//  3. Fast-forward the target to the longest green prefix: the deepest stack
+//     that passed together with every stack below it
+//  4. The MR right after that prefix is the culprit if its stack failed gates;
+//     MRs after it stay queued for the next cycle
+//
+// Only the first SpeculationDepth MRs of the batch are stacked; the rest are
+// returned in BatchResult.Requeued without being touched, as are MRs stacked
+// after a failing stack.
+//
+// Per-speculation timing is recorded on each MR bead so `gt mq status` can
+// show how long the stack ending at that MR took to gate.
+func (e *Engineer) ProcessSpeculative(ctx context.Context, batch []*MRInfo, target string, batchCfg *BatchConfig) *BatchResult {
+	if batchCfg == nil {
+		batchCfg = DefaultBatchConfig()
+	}
+
+	result := &BatchResult{}
+	if len(batch) == 0 {
+		return result
+	}
+
+	// Every stacked MR must be gated, so never stack more than we speculate on.
+	var excess []*MRInfo
+	if depth := batchCfg.speculationDepth(); len(batch) > depth {
+		batch, excess = batch[:depth], batch[depth:]
+		_, _ = fmt.Fprintf(e.output, "[Speculate] Requeuing %d MR(s) beyond speculation depth %d\n", len(excess), depth)
+	}
+	if len(batch) == 1 {
+		result = e.processSingleMR(ctx, batch[0], target)
+		result.Requeued = append(result.Requeued, excess...)
+		return result
+	}
+	result.Requeued = excess
+
+	_, _ = fmt.Fprintf(e.output, "[Speculate] Processing batch of %d MRs targeting %s\n", len(batch), target)
+
+	stacked, conflicts, err := e.BuildRebaseStack(ctx, batch, target)
+	if err != nil {
+		result.Error = fmt.Errorf("build rebase stack: %w", err)
+		return result
+	}
+	result.Conflicts = conflicts
+	if len(stacked) == 0 {
+		_, _ = fmt.Fprintln(e.output, "[Speculate] No MRs could be stacked (all conflicted)")
+		return result
+	}
+
+	depth := len(stacked)
+
+	// Each stacked MR is exactly one squash commit on top of the target, so
+	// stack k is HEAD~(n-k).
+	tips := make([]string, depth)
+	for k := 1; k <= depth; k++ {
+		sha, revErr := e.git.Rev(fmt.Sprintf("HEAD~%d", len(stacked)-k))
+		if revErr != nil {
+			result.Error = fmt.Errorf("resolve stack %d: %w", k, revErr)
+			return result
+		}
+		tips[k-1] = sha
+	}
+
+	specs := e.runSpeculations(ctx, stacked, tips)
+	result.Speculations = specs
+	for _, s := range specs {
+		_, _ = fmt.Fprintf(e.output, "[Speculate] Stack %d %v: %s (%v)\n", s.Depth, s.MRs, s.Result, s.Elapsed.Truncate(time.Second))
+	}
+	e.recordSpeculations(stacked, specs)
+
+	green := longestGreenPrefix(specs)
+	if green < depth {
+		failed := specs[green]
+		rest := green
+		if failed.Result == SpeculationFailed {
+			result.Culprits = []*MRInfo{stacked[green]}
+			rest++
+		} else if green == 0 {
+			result.Error = fmt.Errorf("speculation for %s: %s", stacked[0].ID, failed.Error)
+		}
+		result.Requeued = append(append([]*MRInfo{}, stacked[rest:]...), excess...)
+	}
+	if len(result.Requeued) > 0 {
+		_, _ = fmt.Fprintf(e.output, "[Speculate] Left in queue for next cycle: %s\n", strings.Join(mrIDs(result.Requeued), ", "))
+	}
+	if green == 0 {
+		_, _ = fmt.Fprintln(e.output, "[Speculate] No green prefix, nothing to merge")
+		if resetErr := e.git.ResetHard("origin/" + target); resetErr != nil {
+			_, _ = fmt.Fprintf(e.output, "[Speculate] Warning: failed to reset %s: %v\n", target, resetErr)
+		}
+		return result
+	}
+
+	_, _ = fmt.Fprintf(e.output, "[Speculate] Fast-forwarding %d MR(s) to %s\n", green, target)
+	if resetErr := e.git.ResetHard(tips[green-1]); resetErr != nil {
+		result.Error = fmt.Errorf("reset to green prefix: %w", resetErr)
+		return result
+	}
+	return e.fastForwardBatch(ctx, stacked[:green], target, result)
+}
+
+// runSpeculations gates each stack tip in its own worktree, concurrently.
+// stacked[k-1] is the MR that stack k adds; tips[k-1] is stack k's commit.
+func (e *Engineer) runSpeculations(ctx context.Context, stacked []*MRInfo, tips []string) []SpeculationResult {
+	root, err := os.MkdirTemp("", "gt-speculate-")
+	if err != nil {
+		specs := make([]SpeculationResult, len(tips))
+		for i := range specs {
+			specs[i] = SpeculationResult{Depth: i + 1, MRs: mrIDs(stacked[:i+1]), Result: SpeculationError, Error: err.Error()}
+		}
+		return specs
+	}
+	defer func() {
+		_ = os.RemoveAll(root)
+		_ = e.git.WorktreePrune()
+	}()
+
+	out := &lockedWriter{w: e.output}
+	specs := make([]SpeculationResult, len(tips))
+	var wg sync.WaitGroup
+	for i := range tips {
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			specs[k-1] = e.runSpeculation(ctx, k, mrIDs(stacked[:k]), tips[k-1], filepath.Join(root, fmt.Sprintf("stack-%d", k)), out)
+		}(i + 1)
+	}
+	wg.Wait()
+	return specs
+}
+
+// runSpeculation checks out one stack tip and runs gates there.
+func (e *Engineer) runSpeculation(ctx context.Context, depth int, ids []string, tip, dir string, out io.Writer) SpeculationResult {
+	start := time.Now()
+	spec := SpeculationResult{Depth: depth, MRs: ids}
+
+	e.worktreeMu.Lock()
+	addErr := e.git.WorktreeAddDetached(dir, tip)
+	e.worktreeMu.Unlock()
+	if addErr != nil {
+		spec.Result = SpeculationError
+		spec.Error = fmt.Sprintf("create worktree: %v", addErr)
+		spec.Elapsed = time.Since(start)
+		return spec
+	}
+	defer func() {
+		e.worktreeMu.Lock()
+		_ = e.git.WorktreeRemove(dir, true)
+		e.worktreeMu.Unlock()
+	}()
+
+	gates := e.inWorkDir(dir, &prefixWriter{w: out, prefix: fmt.Sprintf("[Stack %d] ", depth)})
+	gateResult := gates.runBatchGates(ctx)
+	spec.Elapsed = time.Since(start)
+	switch {
+	case gateResult.Success:
+		spec.Result = SpeculationPassed
+	case gateResult.TestsFailed:
+		spec.Result = SpeculationFailed
+		spec.Error = gateResult.Error
+	default:
+		spec.Result = SpeculationError
+		spec.Error = gateResult.Error
+	}
+	return spec
+}
+
+// inWorkDir returns a copy of the Engineer that runs gates in dir and writes
+// to out. Only gate execution uses the copy; git state stays with e.
+func (e *Engineer) inWorkDir(dir string, out io.Writer) *Engineer {
+	return &Engineer{
+		rig:         e.rig,
+		beads:       e.beads,
+		git:         e.git,
+		config:      e.config,
+		workDir:     dir,
+		output:      out,
+		router:      e.router,
+		gateHistory: e.gateHistory,
+	}
+}
+
+// longestGreenPrefix returns the depth of the deepest stack that passed
+// along with every shallower stack. A green stack above a red one does not
+// count: landing it would merge an MR whose own stack failed gates.
+func longestGreenPrefix(specs []SpeculationResult) int {
+	green := 0
+	for _, s := range specs {
+		if !s.Passed() {
+			break
+		}
+		green = s.Depth
+	}
+	return green
+}
+
+// recordSpeculations writes each stack's outcome and timing to the bead of
+// the MR that stack ends at. Failures are logged, not fatal.
+func (e *Engineer) recordSpeculations(stacked []*MRInfo, specs []SpeculationResult) {
+	for _, s := range specs {
+		mr := stacked[s.Depth-1]
+		if mr.ID == "" {
+			continue
+		}
+		issue, err := e.beads.Show(mr.ID)
+		if err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Speculate] Warning: failed to fetch MR bead %s: %v\n", mr.ID, err)
+			continue
+		}
+		fields := beads.ParseMRFields(issue)
+		if fields == nil {
+			fields = &beads.MRFields{}
+		}
+		fields.SpeculationDepth = s.Depth
+		fields.SpeculationResult = s.Result
+		fields.SpeculationElapsed = s.Elapsed.Truncate(time.Second).String()
+		fields.SpeculatedAt = time.Now().UTC().Format(time.RFC3339)
+		desc := beads.SetMRFields(issue, fields)
+		if err := e.beads.Update(mr.ID, beads.UpdateOptions{Description: &desc}); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Speculate] Warning: failed to record speculation on %s: %v\n", mr.ID, err)
+		}
+	}
+}
+
+// lockedWriter serializes writes from concurrent speculations.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// prefixWriter tags each line with the stack it came from.
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	lines := strings.SplitAfter(string(b), "\n")
+	var sb strings.Builder
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		sb.WriteString(p.prefix)
+		sb.WriteString(line)
+	}
+	if _, err := io.WriteString(p.w, sb.String()); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
@@ -0,0 +1,277 @@
+package refinery
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func speculativeConfig(depth int) *BatchConfig {
+	return &BatchConfig{
+		MaxBatchSize:     5,
+		Speculative:      true,
+		SpeculationDepth: depth,
+	}
+}
+
+func TestProcessSpeculative_AllGreen(t *testing.T) {
+	workDir, g, cleanup := testGitRepo(t)
+	defer cleanup()
+
+	createFeatureBranch(t, workDir, "feature-a", "a.txt", "hello a\n")
+	createFeatureBranch(t, workDir, "feature-b", "b.txt", "hello b\n")
+	createFeatureBranch(t, workDir, "feature-c", "c.txt", "hello c\n")
+
+	e := newTestEngineer(t, workDir, g)
+	e.config.Gates = map[string]*GateConfig{
+		"check": {Cmd: failMarkerGateCmd()},
+	}
+
+	batch := []*MRInfo{
+		makeMR("mr-a", "feature-a", "main"),
+		makeMR("mr-b", "feature-b", "main"),
+		makeMR("mr-c", "feature-c", "main"),
+	}
+
+	// ProcessBatch dispatches to the speculative path when configured.
+	result := e.ProcessBatch(context.Background(), batch, "main", speculativeConfig(3))
+	if result.Error != nil {
+		t.Fatalf("unexpected error: %v", result.Error)
+	}
+	if got := stackedIDs(result.Merged); len(got) != 3 {
+		t.Fatalf("expected 3 merged, got %v", got)
+	}
+	if len(result.Speculations) != 3 {
+		t.Fatalf("expected 3 speculations, got %d", len(result.Speculations))
+	}
+	for i, s := range result.Speculations {
+		if s.Depth != i+1 || !s.Passed() {
+			t.Errorf("speculation %d = %+v, want depth %d passed", i, s, i+1)
+		}
+		if len(s.MRs) != s.Depth {
+			t.Errorf("speculation %d MRs = %v, want %d entries", i, s.MRs, s.Depth)
+		}
+	}
+
+	verifyDir := filepath.Join(filepath.Dir(workDir), "verify")
+	bareDir := filepath.Join(filepath.Dir(workDir), "origin.git")
+	run(t, filepath.Dir(workDir), "git", "clone", bareDir, verifyDir)
+	for _, f := range []string{"a.txt", "b.txt", "c.txt"} {
+		if _, err := os.Stat(filepath.Join(verifyDir, f)); os.IsNotExist(err) {
+			t.Errorf("expected %s in cloned repo after push", f)
+		}
+	}
+
+	// Speculation worktrees are cleaned up.
+	if out := run(t, workDir, "git", "worktree", "list"); len(strings.Split(strings.TrimSpace(out), "\n")) != 1 {
+		t.Errorf("expected only the main worktree, got:\n%s", out)
+	}
+}
+
+func TestProcessSpeculative_LandsLongestGreenPrefix(t *testing.T) {
+	workDir, g, cleanup := testGitRepo(t)
+	defer cleanup()
+
+	createFeatureBranch(t, workDir, "feature-a", "a.txt", "hello a\n")
+	createFeatureBranch(t, workDir, "feature-b", "FAIL_MARKER", "this causes test failure\n")
+	createFeatureBranch(t, workDir, "feature-c", "c.txt", "hello c\n")
+
+	e := newTestEngineer(t, workDir, g)
+	e.config.Gates = map[string]*GateConfig{
+		"check": {Cmd: failMarkerGateCmd()},
+	}
+
+	batch := []*MRInfo{
+		makeMR("mr-a", "feature-a", "main"),
+		makeMR("mr-b", "feature-b", "main"),
+		makeMR("mr-c", "feature-c", "main"),
+	}
+
+	result := e.ProcessSpeculative(context.Background(), batch, "main", speculativeConfig(3))
+	if result.Error != nil {
+		t.Fatalf("unexpected error: %v", result.Error)
+	}
+	if got := stackedIDs(result.Merged); len(got) != 1 || got[0] != "mr-a" {
+		t.Errorf("merged = %v, want [mr-a]", got)
+	}
+	if got := stackedIDs(result.Culprits); len(got) != 1 || got[0] != "mr-b" {
+		t.Errorf("culprits = %v, want [mr-b]", got)
+	}
+	if got := stackedIDs(result.Requeued); len(got) != 1 || got[0] != "mr-c" {
+		t.Errorf("requeued = %v, want [mr-c]", got)
+	}
+	want := []string{SpeculationPassed, SpeculationFailed, SpeculationFailed}
+	for i, s := range result.Speculations {
+		if s.Result != want[i] {
+			t.Errorf("stack %d result = %s, want %s", s.Depth, s.Result, want[i])
+		}
+	}
+
+	// The target is left at the landed prefix, not the failing stack.
+	if _, err := os.Stat(filepath.Join(workDir, "FAIL_MARKER")); !os.IsNotExist(err) {
+		t.Error("FAIL_MARKER should not be on the target after landing the green prefix")
+	}
+}
+
+func TestProcessSpeculative_DoesNotLandFailedStack(t *testing.T) {
+	workDir, g, cleanup := testGitRepo(t)
+	defer cleanup()
+
+	createFeatureBranch(t, workDir, "feature-a", "a.txt", "hello a\n")
+	createFeatureBranch(t, workDir, "feature-b", "b.txt", "hello b\n")
+
+	e := newTestEngineer(t, workDir, g)
+	// mr-a alone fails; stacked with mr-b it passes.
+	e.config.Gates = map[string]*GateConfig{
+		"check": {Cmd: "test ! -f a.txt || test -f b.txt"},
+	}
+
+	batch := []*MRInfo{
+		makeMR("mr-a", "feature-a", "main"),
+		makeMR("mr-b", "feature-b", "main"),
+	}
+
+	result := e.ProcessSpeculative(context.Background(), batch, "main", speculativeConfig(2))
+	if result.Error != nil {
+		t.Fatalf("unexpected error: %v", result.Error)
+	}
+	if s := result.Speculations; len(s) != 2 || s[0].Passed() || !s[1].Passed() {
+		t.Fatalf("speculations = %+v, want stack 1 failed and stack 2 passed", s)
+	}
+	if len(result.Merged) != 0 {
+		t.Errorf("merged = %v, want none: mr-a's own stack failed", stackedIDs(result.Merged))
+	}
+	if got := stackedIDs(result.Culprits); len(got) != 1 || got[0] != "mr-a" {
+		t.Errorf("culprits = %v, want [mr-a]", got)
+	}
+	if got := stackedIDs(result.Requeued); len(got) != 1 || got[0] != "mr-b" {
+		t.Errorf("requeued = %v, want [mr-b]", got)
+	}
+	if _, err := os.Stat(filepath.Join(workDir, "a.txt")); !os.IsNotExist(err) {
+		t.Error("a.txt should not be on the target")
+	}
+}
+
+func TestProcessSpeculative_DepthLimitsStacks(t *testing.T) {
+	workDir, g, cleanup := testGitRepo(t)
+	defer cleanup()
+
+	for _, name := range []string{"a", "b", "c", "d"} {
+		createFeatureBranch(t, workDir, "feature-"+name, name+".txt", "hello "+name+"\n")
+	}
+
+	e := newTestEngineer(t, workDir, g)
+	batch := []*MRInfo{
+		makeMR("mr-a", "feature-a", "main"),
+		makeMR("mr-b", "feature-b", "main"),
+		makeMR("mr-c", "feature-c", "main"),
+		makeMR("mr-d", "feature-d", "main"),
+	}
+
+	result := e.ProcessSpeculative(context.Background(), batch, "main", speculativeConfig(2))
+	if result.Error != nil {
+		t.Fatalf("unexpected error: %v", result.Error)
+	}
+	if len(result.Speculations) != 2 {
+		t.Errorf("expected 2 speculations, got %d", len(result.Speculations))
+	}
+	// MRs beyond the speculation depth stay queued for the next cycle.
+	if got := stackedIDs(result.Merged); len(got) != 2 || got[1] != "mr-b" {
+		t.Errorf("merged = %v, want [mr-a mr-b]", got)
+	}
+	if len(result.Culprits) != 0 {
+		t.Errorf("unexpected culprits: %v", stackedIDs(result.Culprits))
+	}
+	if got := stackedIDs(result.Requeued); len(got) != 2 || got[0] != "mr-c" || got[1] != "mr-d" {
+		t.Errorf("requeued = %v, want [mr-c mr-d]", got)
+	}
+
+	// The requeued MRs were never stacked onto the target.
+	for _, name := range []string{"c.txt", "d.txt"} {
+		if _, err := os.Stat(filepath.Join(workDir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should not be on the target", name)
+		}
+	}
+}
+
+func TestLongestGreenPrefix(t *testing.T) {
+	spec := func(depth int, result string) SpeculationResult {
+		return SpeculationResult{Depth: depth, Result: result}
+	}
+	tests := []struct {
+		name  string
+		specs []SpeculationResult
+		want  int
+	}{
+		{"none", nil, 0},
+		{"all red", []SpeculationResult{spec(1, SpeculationFailed), spec(2, SpeculationFailed)}, 0},
+		{"first only", []SpeculationResult{spec(1, SpeculationPassed), spec(2, SpeculationFailed)}, 1},
+		{"all green", []SpeculationResult{spec(1, SpeculationPassed), spec(2, SpeculationPassed)}, 2},
+		{"green above red", []SpeculationResult{spec(1, SpeculationFailed), spec(2, SpeculationPassed)}, 0},
+		{"green above red in the middle", []SpeculationResult{spec(1, SpeculationPassed), spec(2, SpeculationFailed), spec(3, SpeculationPassed)}, 1},
+		{"deep error", []SpeculationResult{spec(1, SpeculationPassed), spec(2, SpeculationError)}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := longestGreenPrefix(tt.specs); got != tt.want {
+				t.Errorf("longestGreenPrefix() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngineer_LoadConfig_Batch(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := map[string]interface{}{
+		"type":    "rig",
+		"version": 1,
+		"name":    "test-rig",
+		"merge_queue": map[string]interface{}{
+			"batch": map[string]interface{}{
+				"max_batch_size":    8,
+				"batch_wait_time":   "1m",
+				"speculative":       true,
+				"speculation_depth": 4,
+			},
+		},
+	}
+	data, _ := json.MarshalIndent(config, "", "  ")
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: tmpDir})
+	if err := e.LoadConfig(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b := e.config.Batch
+	if b == nil {
+		t.Fatal("expected batch config to be loaded")
+	}
+	if b.MaxBatchSize != 8 || b.BatchWaitTime != time.Minute || !b.Speculative || b.SpeculationDepth != 4 {
+		t.Errorf("batch config = %+v", b)
+	}
+	if !b.RetryBatchOnFlaky {
+		t.Error("unset retry_batch_on_flaky should keep its default (true)")
+	}
+}
+
+func TestEngineer_LoadConfig_BatchInvalidWaitTime(t *testing.T) {
+	tmpDir := t.TempDir()
+	data := []byte(`{"type":"rig","version":1,"name":"test-rig","merge_queue":{"batch":{"batch_wait_time":"soon"}}}`)
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: tmpDir})
+	if err := e.LoadConfig(); err == nil {
+		t.Error("expected error for invalid batch_wait_time")
+	}
+}
@@ -12,7 +12,6 @@ import (
 	"github.com/steveyegge/gastown/internal/hooks"
 	"github.com/steveyegge/gastown/internal/hookutil"
 	"github.com/steveyegge/gastown/internal/templates/commands"
-	"github.com/steveyegge/gastown/internal/tmux"
 )
 
 // EnsureSettingsForRole provisions all agent-specific configuration for a role.
@@ -103,8 +102,13 @@ func StartupFallbackCommands(role string, rc *config.RuntimeConfig) []string {
 	return []string{command}
 }
 
-// RunStartupFallback sends the startup fallback commands via tmux.
-func RunStartupFallback(t *tmux.Tmux, sessionID, role string, rc *config.RuntimeConfig) error {
+// Nudger delivers a message to an agent session.
+type Nudger interface {
+	NudgeSession(session, message string) error
+}
+
+// RunStartupFallback sends the startup fallback commands to the session.
+func RunStartupFallback(t Nudger, sessionID, role string, rc *config.RuntimeConfig) error {
 	commands := StartupFallbackCommands(role, rc)
 	for _, cmd := range commands {
 		if err := t.NudgeSession(sessionID, cmd); err != nil {
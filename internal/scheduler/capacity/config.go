@@ -13,6 +13,10 @@ import "time"
 //   -1 (default): direct dispatch — gt sling works as before, near-zero overhead
 //    0:           direct dispatch (same as -1)
 //    N > 0:       deferred dispatch — labels/metadata applied, daemon dispatches
+//
+// In deferred mode the MaxPolecats slots are shared between rigs by weight
+// (see Rigs and PlanFairDispatch), so a rig with a large convoy cannot starve
+// the others.
 type SchedulerConfig struct {
 	// MaxPolecats is the max concurrent polecats across ALL rigs.
 	// Includes both scheduler-dispatched and directly-slung polecats.
@@ -28,8 +32,36 @@ type SchedulerConfig struct {
 	// SpawnDelay is the delay between spawns to prevent Dolt lock contention.
 	// Default: "0s".
 	SpawnDelay string `json:"spawn_delay,omitempty"`
+
+	// Rigs sets per-rig fair-share parameters, keyed by rig name.
+	// Rigs not listed get weight 1 and no min/max.
+	Rigs map[string]*RigSchedulerConfig `json:"rigs,omitempty"`
+
+	// AgingInterval is how long a scheduled bead waits before its effective
+	// priority is raised by one level, so low-priority work eventually runs.
+	// Default: "1h". "0s" disables aging.
+	AgingInterval string `json:"aging_interval,omitempty"`
+}
+
+// RigSchedulerConfig holds one rig's fair-share parameters.
+type RigSchedulerConfig struct {
+	// Weight is the rig's relative share of MaxPolecats. Default: 1.
+	Weight int `json:"weight,omitempty"`
+
+	// MinPolecats is the number of polecats the rig is guaranteed: while it
+	// has fewer running, that many free slots are held back from rigs at or
+	// above their own minimum, and it is dispatched to first when it has
+	// ready work. Slots already taken are not preempted. 0 = no guarantee.
+	MinPolecats int `json:"min_polecats,omitempty"`
+
+	// MaxPolecats caps the rig's concurrent polecats. 0 = no per-rig cap
+	// (the town-wide MaxPolecats still applies).
+	MaxPolecats int `json:"max_polecats,omitempty"`
 }
 
+// defaultAgingInterval is the default AgingInterval.
+const defaultAgingInterval = time.Hour
+
 // DefaultSchedulerConfig returns a SchedulerConfig with sensible defaults.
 // MaxPolecats=-1 means direct dispatch (no scheduler overhead).
 func DefaultSchedulerConfig() *SchedulerConfig {
@@ -66,6 +98,27 @@ func (c *SchedulerConfig) GetSpawnDelay() time.Duration {
 	return ParseDurationOrDefault(c.SpawnDelay, 0)
 }
 
+// GetRig returns the fair-share parameters for rig, with defaults applied.
+func (c *SchedulerConfig) GetRig(rig string) RigSchedulerConfig {
+	var rc RigSchedulerConfig
+	if c != nil && c.Rigs[rig] != nil {
+		rc = *c.Rigs[rig]
+	}
+	if rc.Weight <= 0 {
+		rc.Weight = 1
+	}
+	return rc
+}
+
+// GetAgingInterval returns AgingInterval as a duration, defaulting to 1h.
+// Zero disables aging.
+func (c *SchedulerConfig) GetAgingInterval() time.Duration {
+	if c == nil {
+		return defaultAgingInterval
+	}
+	return ParseDurationOrDefault(c.AgingInterval, defaultAgingInterval)
+}
+
 // IsDeferred returns true when the scheduler is configured for deferred dispatch
 // (max_polecats > 0). Returns false for direct dispatch (-1) and disabled (0).
 func (c *SchedulerConfig) IsDeferred() bool {
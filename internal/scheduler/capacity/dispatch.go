@@ -35,6 +35,10 @@ type DispatchCycle struct {
 	// OnFailure is called after failed dispatch.
 	OnFailure func(PendingBead, error)
 
+	// FairShare, if set, returns the per-rig state used to order and limit
+	// the plan with PlanFairDispatch. Nil keeps the order of QueryPending.
+	FairShare func() (FairShare, error)
+
 	// BatchSize caps items dispatched per cycle.
 	BatchSize int
 
@@ -47,7 +51,7 @@ type DispatchReport struct {
 	Dispatched int
 	Failed     int
 	Skipped    int
-	Reason     string // "capacity" | "batch" | "ready" | "none"
+	Reason     string // "capacity" | "batch" | "ready" | "rig-limit" | "reserved" | "none"
 }
 
 // Plan returns the dispatch plan without executing. Used for dry-run.
@@ -62,6 +66,13 @@ func (c *DispatchCycle) Plan() (DispatchPlan, error) {
 		return DispatchPlan{}, fmt.Errorf("querying pending: %w", err)
 	}
 
+	if c.FairShare != nil {
+		fs, err := c.FairShare()
+		if err != nil {
+			return DispatchPlan{}, fmt.Errorf("loading fair-share state: %w", err)
+		}
+		return PlanFairDispatch(cap, c.BatchSize, pending, fs), nil
+	}
 	return PlanDispatch(cap, c.BatchSize, pending), nil
 }
 
@@ -0,0 +1,302 @@
+package capacity
+
+import (
+	"sort"
+	"time"
+)
+
+// FairShare is the per-rig state PlanFairDispatch needs beyond the free
+// capacity and batch size.
+type FairShare struct {
+	// Config supplies per-rig weights, min/max and the aging interval.
+	Config *SchedulerConfig
+	// Active is the number of running polecats per rig.
+	Active map[string]int
+	// Now is the time aging is measured against.
+	Now time.Time
+}
+
+// EffectivePriority returns b's priority after aging: one level higher
+// (numerically lower) per interval waited since the bead was scheduled.
+// It may drop below 0, so aged work eventually overtakes a steady stream
+// of fresh P0 beads. interval <= 0 disables aging.
+func EffectivePriority(b PendingBead, interval time.Duration, now time.Time) int {
+	if interval <= 0 || b.Context == nil {
+		return b.Priority
+	}
+	enqueued, err := time.Parse(time.RFC3339, b.Context.EnqueuedAt)
+	if err != nil || !now.After(enqueued) {
+		return b.Priority
+	}
+	return b.Priority - int(now.Sub(enqueued)/interval)
+}
+
+// rigQueue is one rig's ready beads in dispatch order.
+type rigQueue struct {
+	rig   string
+	cfg   RigSchedulerConfig
+	used  int // active + planned this cycle
+	beads []PendingBead
+	prios []int
+}
+
+func (q *rigQueue) belowMin() bool { return q.used < q.cfg.MinPolecats }
+
+func (q *rigQueue) atMax() bool { return q.cfg.MaxPolecats > 0 && q.used >= q.cfg.MaxPolecats }
+
+// before reports whether q should be dispatched to before o: rigs below
+// their guaranteed minimum first, then the rig using the least of its
+// weighted share, then the rig whose next bead has the higher effective
+// priority or has waited longer.
+func (q *rigQueue) before(o *rigQueue) bool {
+	if q.belowMin() != o.belowMin() {
+		return q.belowMin()
+	}
+	// used/weight compared without division.
+	if l, r := q.used*o.cfg.Weight, o.used*q.cfg.Weight; l != r {
+		return l < r
+	}
+	if q.prios[0] != o.prios[0] {
+		return q.prios[0] < o.prios[0]
+	}
+	if qa, oa := enqueuedAt(q.beads[0]), enqueuedAt(o.beads[0]); qa != oa {
+		return qa < oa
+	}
+	return q.rig < o.rig
+}
+
+func enqueuedAt(b PendingBead) string {
+	if b.Context == nil {
+		return ""
+	}
+	return b.Context.EnqueuedAt
+}
+
+// PlanFairDispatch is PlanDispatch with the free slots shared between rigs.
+// Within a rig, beads are ordered by effective priority (see
+// EffectivePriority), then by how long they have been scheduled. Across
+// rigs, each slot goes to the rig that is furthest below its guaranteed
+// minimum or, failing that, has the fewest polecats (running plus planned)
+// relative to its weight. Rigs at their max_polecats are skipped; when that
+// leaves slots unused the reason is "rig-limit".
+//
+// Free capacity that configured rigs need to reach their min_polecats is
+// reserved for them even when they have no ready work this cycle: a rig at
+// or above its own minimum only gets a slot if enough remain for those
+// guarantees. Slots left unused for that reason give "reserved".
+func PlanFairDispatch(availableCapacity, batchSize int, ready []PendingBead, fs FairShare) DispatchPlan {
+	if len(ready) == 0 {
+		return DispatchPlan{Reason: "none"}
+	}
+	if availableCapacity <= 0 {
+		return DispatchPlan{
+			Skipped: len(ready),
+			Reason:  "capacity",
+		}
+	}
+
+	slots := batchSize
+	if availableCapacity < slots {
+		slots = availableCapacity
+	}
+
+	interval := fs.Config.GetAgingInterval()
+	byRig := make(map[string]*rigQueue)
+	var queues []*rigQueue
+	for _, b := range ready {
+		q := byRig[b.TargetRig]
+		if q == nil {
+			q = &rigQueue{
+				rig:  b.TargetRig,
+				cfg:  fs.Config.GetRig(b.TargetRig),
+				used: fs.Active[b.TargetRig],
+			}
+			byRig[b.TargetRig] = q
+			queues = append(queues, q)
+		}
+		q.beads = append(q.beads, b)
+		q.prios = append(q.prios, EffectivePriority(b, interval, fs.Now))
+	}
+	for _, q := range queues {
+		sort.Stable(byEffectivePriority{q})
+	}
+
+	// reserved is the number of polecats configured rigs still need to
+	// reach their min_polecats.
+	reserved := func() int {
+		n := 0
+		if fs.Config == nil {
+			return 0
+		}
+		for rig, rc := range fs.Config.Rigs {
+			if rc == nil {
+				continue
+			}
+			used := fs.Active[rig]
+			if q := byRig[rig]; q != nil {
+				used = q.used
+			}
+			if rc.MinPolecats > used {
+				n += rc.MinPolecats - used
+			}
+		}
+		return n
+	}
+
+	var toDispatch []PendingBead
+	heldBack := false
+	for len(toDispatch) < slots {
+		// Rigs at or above their minimum may only take a slot that
+		// leaves the outstanding minimums covered.
+		unreserved := availableCapacity-len(toDispatch)-1 >= reserved()
+		var next *rigQueue
+		for _, q := range queues {
+			if len(q.beads) == 0 || q.atMax() {
+				continue
+			}
+			if !unreserved && !q.belowMin() {
+				heldBack = true
+				continue
+			}
+			if next == nil || q.before(next) {
+				next = q
+			}
+		}
+		if next == nil {
+			break
+		}
+		toDispatch = append(toDispatch, next.beads[0])
+		next.beads, next.prios = next.beads[1:], next.prios[1:]
+		next.used++
+	}
+
+	reason := "batch"
+	if availableCapacity < batchSize && availableCapacity < len(ready) {
+		reason = "capacity"
+	}
+	if len(ready) < batchSize && len(ready) < availableCapacity {
+		reason = "ready"
+	}
+	if len(toDispatch) < slots && len(toDispatch) < len(ready) {
+		reason = "rig-limit"
+		if heldBack {
+			reason = "reserved"
+		}
+	}
+
+	return DispatchPlan{
+		ToDispatch: toDispatch,
+		Skipped:    len(ready) - len(toDispatch),
+		Reason:     reason,
+	}
+}
+
+// byEffectivePriority sorts a rigQueue's beads by effective priority, then
+// by enqueue time.
+type byEffectivePriority struct{ q *rigQueue }
+
+func (s byEffectivePriority) Len() int { return len(s.q.beads) }
+func (s byEffectivePriority) Less(i, j int) bool {
+	if s.q.prios[i] != s.q.prios[j] {
+		return s.q.prios[i] < s.q.prios[j]
+	}
+	return enqueuedAt(s.q.beads[i]) < enqueuedAt(s.q.beads[j])
+}
+func (s byEffectivePriority) Swap(i, j int) {
+	s.q.beads[i], s.q.beads[j] = s.q.beads[j], s.q.beads[i]
+	s.q.prios[i], s.q.prios[j] = s.q.prios[j], s.q.prios[i]
+}
+
+// RigUsage is one rig's fair share of the town-wide MaxPolecats next to
+// what it is actually using, for gt scheduler status.
+type RigUsage struct {
+	Rig         string  `json:"rig"`
+	Weight      int     `json:"weight"`
+	MinPolecats int     `json:"min_polecats,omitempty"`
+	MaxPolecats int     `json:"max_polecats,omitempty"`
+	Share       float64 `json:"share"`
+	Active      int     `json:"active"`
+	Queued      int     `json:"queued"`
+}
+
+// RigUsages computes each rig's share of MaxPolecats: rigs with running or
+// queued work split the slots by weight, clamped to their min and max, with
+// the remainder redistributed among the others. Idle rigs have a share of 0.
+// The result covers configured rigs and every rig in active or queued,
+// sorted by name.
+func RigUsages(cfg *SchedulerConfig, active, queued map[string]int) []RigUsage {
+	names := make(map[string]bool)
+	if cfg != nil {
+		for rig := range cfg.Rigs {
+			names[rig] = true
+		}
+	}
+	for rig := range active {
+		names[rig] = true
+	}
+	for rig := range queued {
+		names[rig] = true
+	}
+
+	usages := make([]RigUsage, 0, len(names))
+	for rig := range names {
+		rc := cfg.GetRig(rig)
+		usages = append(usages, RigUsage{
+			Rig:         rig,
+			Weight:      rc.Weight,
+			MinPolecats: rc.MinPolecats,
+			MaxPolecats: rc.MaxPolecats,
+			Active:      active[rig],
+			Queued:      queued[rig],
+		})
+	}
+	sort.Slice(usages, func(i, j int) bool { return usages[i].Rig < usages[j].Rig })
+
+	total := cfg.GetMaxPolecats()
+	if total <= 0 {
+		return usages
+	}
+
+	// Water-fill: split what is left by weight among the unclamped rigs,
+	// clamp the first rig that falls outside its min/max, and repeat.
+	clamped := make(map[int]bool)
+	for {
+		remaining := float64(total)
+		weights := 0
+		for i, u := range usages {
+			if u.Active == 0 && u.Queued == 0 {
+				continue
+			}
+			if clamped[i] {
+				remaining -= usages[i].Share
+			} else {
+				weights += u.Weight
+			}
+		}
+		if weights == 0 {
+			return usages
+		}
+		again := false
+		for i := range usages {
+			u := &usages[i]
+			if (u.Active == 0 && u.Queued == 0) || clamped[i] {
+				continue
+			}
+			u.Share = remaining * float64(u.Weight) / float64(weights)
+			switch {
+			case u.MaxPolecats > 0 && u.Share > float64(u.MaxPolecats):
+				u.Share = float64(u.MaxPolecats)
+			case u.Share < float64(u.MinPolecats):
+				u.Share = float64(u.MinPolecats)
+			default:
+				continue
+			}
+			clamped[i] = true
+			again = true
+			break
+		}
+		if !again {
+			return usages
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package capacity
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+var fairNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// fairBead returns a pending bead for rig with the given priority, scheduled
+// age ago.
+func fairBead(id, rig string, priority int, age time.Duration) PendingBead {
+	return PendingBead{
+		ID:         id,
+		WorkBeadID: "w-" + id,
+		TargetRig:  rig,
+		Priority:   priority,
+		Context:    &SlingContextFields{EnqueuedAt: fairNow.Add(-age).Format(time.RFC3339)},
+	}
+}
+
+func dispatchedIDs(plan DispatchPlan) string {
+	ids := make([]string, len(plan.ToDispatch))
+	for i, b := range plan.ToDispatch {
+		ids[i] = b.ID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestEffectivePriority(t *testing.T) {
+	tests := []struct {
+		name     string
+		priority int
+		age      time.Duration
+		interval time.Duration
+		want     int
+	}{
+		{"fresh", 3, 0, time.Hour, 3},
+		{"just under one interval", 3, 59 * time.Minute, time.Hour, 3},
+		{"two intervals", 3, 2 * time.Hour, time.Hour, 1},
+		{"ages past P0", 1, 5 * time.Hour, time.Hour, -4},
+		{"aging disabled", 3, 10 * time.Hour, 0, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := fairBead("a", "r", tt.priority, tt.age)
+			if got := EffectivePriority(b, tt.interval, fairNow); got != tt.want {
+				t.Errorf("EffectivePriority = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("unparseable enqueue time", func(t *testing.T) {
+		b := PendingBead{Priority: 2, Context: &SlingContextFields{EnqueuedAt: "yesterday"}}
+		if got := EffectivePriority(b, time.Hour, fairNow); got != 2 {
+			t.Errorf("EffectivePriority = %d, want 2", got)
+		}
+	})
+}
+
+func TestPlanFairDispatch_LargeRigDoesNotStarveOthers(t *testing.T) {
+	var ready []PendingBead
+	// A big convoy scheduled first on one rig...
+	for i := 0; i < 20; i++ {
+		ready = append(ready, fairBead(fmt.Sprintf("big%02d", i), "big", 2, 2*time.Minute))
+	}
+	// ...and a little work on two others.
+	ready = append(ready,
+		fairBead("a1", "alpha", 2, time.Minute),
+		fairBead("a2", "alpha", 2, time.Minute),
+		fairBead("b1", "beta", 2, time.Minute),
+	)
+
+	fs := FairShare{Config: DefaultSchedulerConfig(), Now: fairNow}
+	plan := PlanFairDispatch(10, 4, ready, fs)
+
+	counts := make(map[string]int)
+	for _, b := range plan.ToDispatch {
+		counts[b.TargetRig]++
+	}
+	if counts["alpha"] == 0 || counts["beta"] == 0 {
+		t.Errorf("small rigs starved: dispatched %s", dispatchedIDs(plan))
+	}
+	if len(plan.ToDispatch) != 4 || plan.Skipped != 19 || plan.Reason != "batch" {
+		t.Errorf("plan = %d dispatched, %d skipped, %q; want 4, 19, batch",
+			len(plan.ToDispatch), plan.Skipped, plan.Reason)
+	}
+}
+
+func TestPlanFairDispatch_WeightsAndActive(t *testing.T) {
+	ready := []PendingBead{
+		fairBead("h1", "heavy", 2, time.Minute),
+		fairBead("h2", "heavy", 2, time.Minute),
+		fairBead("h3", "heavy", 2, time.Minute),
+		fairBead("l1", "light", 2, time.Minute),
+		fairBead("l2", "light", 2, time.Minute),
+	}
+	cfg := DefaultSchedulerConfig()
+	cfg.Rigs = map[string]*RigSchedulerConfig{"heavy": {Weight: 3}}
+
+	t.Run("weight 3 gets three slots for each one", func(t *testing.T) {
+		plan := PlanFairDispatch(4, 4, ready, FairShare{Config: cfg, Now: fairNow})
+		if got := dispatchedIDs(plan); got != "h1,l1,h2,h3" {
+			t.Errorf("dispatched %s, want h1,l1,h2,h3", got)
+		}
+	})
+
+	t.Run("running polecats count against the share", func(t *testing.T) {
+		fs := FairShare{Config: cfg, Active: map[string]int{"heavy": 6}, Now: fairNow}
+		plan := PlanFairDispatch(2, 2, ready, fs)
+		if got := dispatchedIDs(plan); got != "l1,l2" {
+			t.Errorf("dispatched %s, want l1,l2", got)
+		}
+	})
+}
+
+func TestPlanFairDispatch_MinMax(t *testing.T) {
+	ready := []PendingBead{
+		fairBead("a1", "alpha", 0, time.Hour),
+		fairBead("a2", "alpha", 0, time.Hour),
+		fairBead("a3", "alpha", 0, time.Hour),
+		fairBead("b1", "beta", 4, time.Minute),
+		fairBead("b2", "beta", 4, time.Minute),
+	}
+
+	t.Run("below min goes first", func(t *testing.T) {
+		cfg := DefaultSchedulerConfig()
+		cfg.Rigs = map[string]*RigSchedulerConfig{"beta": {MinPolecats: 2}}
+		// beta is past its minimum, so alpha wins the tie on priority.
+		fs := FairShare{Config: cfg, Active: map[string]int{"alpha": 3, "beta": 3}, Now: fairNow}
+		plan := PlanFairDispatch(1, 1, ready, fs)
+		if got := dispatchedIDs(plan); got != "a1" {
+			t.Errorf("at min: dispatched %s, want a1", got)
+		}
+
+		// Below its minimum, beta goes first despite its lower priority.
+		fs.Active = map[string]int{"alpha": 1, "beta": 1}
+		plan = PlanFairDispatch(2, 2, ready, fs)
+		if got := dispatchedIDs(plan); got != "b1,a1" {
+			t.Errorf("below min: dispatched %s, want b1,a1", got)
+		}
+	})
+
+	t.Run("min reserves slots", func(t *testing.T) {
+		cfg := DefaultSchedulerConfig()
+		cfg.Rigs = map[string]*RigSchedulerConfig{"gamma": {MinPolecats: 2}}
+		// gamma has nothing ready but is 2 below its minimum, so only one
+		// of the three free slots may go to the other rigs.
+		fs := FairShare{Config: cfg, Active: map[string]int{"alpha": 1}, Now: fairNow}
+		plan := PlanFairDispatch(3, 3, ready, fs)
+		if got := dispatchedIDs(plan); got != "b1" {
+			t.Errorf("dispatched %s, want b1", got)
+		}
+		if plan.Reason != "reserved" || plan.Skipped != 4 {
+			t.Errorf("Reason = %q, Skipped = %d; want reserved, 4", plan.Reason, plan.Skipped)
+		}
+
+		// Once gamma is at its minimum the slots are free again.
+		fs.Active = map[string]int{"alpha": 1, "gamma": 2}
+		plan = PlanFairDispatch(3, 3, ready, fs)
+		if got := dispatchedIDs(plan); got != "b1,a1,b2" {
+			t.Errorf("at min: dispatched %s, want b1,a1,b2", got)
+		}
+	})
+
+	t.Run("max caps the rig", func(t *testing.T) {
+		cfg := DefaultSchedulerConfig()
+		cfg.Rigs = map[string]*RigSchedulerConfig{"alpha": {MaxPolecats: 2}}
+		fs := FairShare{Config: cfg, Active: map[string]int{"alpha": 1}, Now: fairNow}
+		plan := PlanFairDispatch(10, 10, ready, fs)
+		if got := dispatchedIDs(plan); got != "b1,a1,b2" {
+			t.Errorf("dispatched %s, want b1,a1,b2", got)
+		}
+		if plan.Reason != "rig-limit" || plan.Skipped != 2 {
+			t.Errorf("Reason = %q, Skipped = %d; want rig-limit, 2", plan.Reason, plan.Skipped)
+		}
+	})
+}
+
+func TestPlanFairDispatch_PriorityAndAging(t *testing.T) {
+	ready := []PendingBead{
+		fairBead("old-low", "r", 4, 3*time.Hour),
+		fairBead("mid", "r", 2, 10*time.Minute),
+		fairBead("urgent", "r", 0, time.Minute),
+		fairBead("ancient-low", "r", 4, 6*time.Hour),
+	}
+
+	t.Run("aging disabled orders by priority", func(t *testing.T) {
+		cfg := DefaultSchedulerConfig()
+		cfg.AgingInterval = "0s"
+		plan := PlanFairDispatch(10, 10, ready, FairShare{Config: cfg, Now: fairNow})
+		if got := dispatchedIDs(plan); got != "urgent,mid,ancient-low,old-low" {
+			t.Errorf("dispatched %s", got)
+		}
+	})
+
+	t.Run("aged work overtakes fresh work", func(t *testing.T) {
+		// Default 1h interval: ancient-low is now -2, old-low is 1.
+		plan := PlanFairDispatch(10, 10, ready, FairShare{Config: DefaultSchedulerConfig(), Now: fairNow})
+		if got := dispatchedIDs(plan); got != "ancient-low,urgent,old-low,mid" {
+			t.Errorf("dispatched %s", got)
+		}
+	})
+}
+
+func TestPlanFairDispatch_Limits(t *testing.T) {
+	fs := FairShare{Config: DefaultSchedulerConfig(), Now: fairNow}
+	if plan := PlanFairDispatch(5, 3, nil, fs); plan.Reason != "none" {
+		t.Errorf("no beads: Reason = %q, want none", plan.Reason)
+	}
+	ready := []PendingBead{fairBead("a", "r", 2, 0), fairBead("b", "s", 2, 0)}
+	if plan := PlanFairDispatch(0, 3, ready, fs); plan.Reason != "capacity" || plan.Skipped != 2 {
+		t.Errorf("no capacity: Reason = %q, Skipped = %d", plan.Reason, plan.Skipped)
+	}
+	if plan := PlanFairDispatch(1, 3, ready, fs); plan.Reason != "capacity" || len(plan.ToDispatch) != 1 {
+		t.Errorf("capacity 1: Reason = %q, dispatched %d", plan.Reason, len(plan.ToDispatch))
+	}
+	if plan := PlanFairDispatch(5, 3, ready, fs); plan.Reason != "ready" || len(plan.ToDispatch) != 2 {
+		t.Errorf("few ready: Reason = %q, dispatched %d", plan.Reason, len(plan.ToDispatch))
+	}
+}
+
+func TestRigUsages(t *testing.T) {
+	max := 10
+	cfg := &SchedulerConfig{
+		MaxPolecats: &max,
+		Rigs: map[string]*RigSchedulerConfig{
+			"alpha": {Weight: 2},
+			"beta":  {MaxPolecats: 1},
+			"idle":  {Weight: 5, MinPolecats: 1},
+		},
+	}
+	usages := RigUsages(cfg,
+		map[string]int{"alpha": 4, "beta": 1},
+		map[string]int{"alpha": 3, "gamma": 2},
+	)
+
+	want := map[string]float64{"alpha": 6, "beta": 1, "gamma": 3, "idle": 0}
+	if len(usages) != len(want) {
+		t.Fatalf("got %d rigs, want %d: %+v", len(usages), len(want), usages)
+	}
+	for i, u := range usages {
+		if i > 0 && usages[i-1].Rig >= u.Rig {
+			t.Errorf("rigs not sorted: %s before %s", usages[i-1].Rig, u.Rig)
+		}
+		if math.Abs(u.Share-want[u.Rig]) > 1e-9 {
+			t.Errorf("%s share = %.2f, want %.2f", u.Rig, u.Share, want[u.Rig])
+		}
+	}
+	if usages[0].Active != 4 || usages[0].Queued != 3 || usages[0].Weight != 2 {
+		t.Errorf("alpha = %+v", usages[0])
+	}
+
+	t.Run("direct dispatch has no shares", func(t *testing.T) {
+		for _, u := range RigUsages(DefaultSchedulerConfig(), map[string]int{"a": 2}, nil) {
+			if u.Share != 0 {
+				t.Errorf("%s share = %.2f, want 0", u.Rig, u.Share)
+			}
+		}
+	})
+}
@@ -10,6 +10,7 @@ type PendingBead struct {
 	TargetRig   string
 	Description string
 	Labels      []string
+	Priority    int                 // Work bead priority (0 = highest, 4 = lowest)
 	Context     *SlingContextFields // Parsed sling params from context bead
 }
 
@@ -44,7 +45,7 @@ const LabelSlingContext = "gt:sling-context"
 type DispatchPlan struct {
 	ToDispatch []PendingBead
 	Skipped    int
-	Reason     string // "capacity" | "batch" | "ready" | "none"
+	Reason     string // "capacity" | "batch" | "ready" | "rig-limit" | "reserved" | "none"
 }
 
 // FailureAction indicates what to do after a dispatch failure.
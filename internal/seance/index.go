@@ -0,0 +1,382 @@
+// Package seance maintains a local full-text index over predecessor agent
+// transcripts, so gt seance can find the session that did a piece of work
+// ("which polecat touched the auth middleware last week?").
+//
+// The index lives in <town>/.runtime/seance-index/ and is updated
+// incrementally: JSONL transcripts are append-only, so each update reads only
+// the lines written since the last one. Every indexed event is stored as a
+// Doc keyed by session, agent, rig, role, bead and time. Documents older
+// than the retention period are dropped when the index is compacted, at most
+// once a day.
+//
+// state.json records how many bytes of docs.jsonl it covers. Documents past
+// that length were written by an update that crashed before saving its
+// state; they are truncated away and indexed again, so a crash never leaves
+// duplicates behind.
+package seance
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/agentlog"
+)
+
+const (
+	indexDirName  = "seance-index"
+	stateFileName = "state.json"
+	docsFileName  = "docs.jsonl"
+
+	// stateVersion is bumped when the Doc layout changes; an index with
+	// another version is rebuilt from scratch.
+	stateVersion = 2
+
+	// maxDocText caps the text stored per event; tool results are capped
+	// harder since they are mostly file contents and command output.
+	maxDocText        = 2000
+	maxToolResultText = 500
+
+	// retention is how long documents are kept, and compactInterval how
+	// often the index is rewritten to drop older ones.
+	retention       = 90 * 24 * time.Hour
+	compactInterval = 24 * time.Hour
+
+	lockTimeout = 10 * time.Second
+)
+
+// Transcript is an agent transcript to index, described by the session_start
+// event that discovered it.
+type Transcript struct {
+	SessionID string
+	Agent     string // agent address, e.g. "gastown/polecats/toast"
+	Rig       string
+	Role      string
+	Format    string // agentlog adapter name, e.g. "claudecode"
+	Path      string
+}
+
+// Doc is one indexed transcript event.
+type Doc struct {
+	SessionID string    `json:"session"`
+	Agent     string    `json:"agent,omitempty"`
+	Rig       string    `json:"rig,omitempty"`
+	Role      string    `json:"role,omitempty"`
+	Bead      string    `json:"bead,omitempty"`
+	Time      time.Time `json:"ts"`
+	Kind      string    `json:"kind"` // user, assistant, thinking, tool_use, tool_result
+	Text      string    `json:"text"`
+}
+
+// fileState records how far a transcript has been indexed.
+type fileState struct {
+	SessionID string `json:"session"`
+	Offset    int64  `json:"offset"`
+}
+
+type indexState struct {
+	Version     int                   `json:"version"`
+	DocsSize    int64                 `json:"docs_size"` // committed length of docs.jsonl
+	CompactedAt time.Time             `json:"compacted_at,omitempty"`
+	Files       map[string]*fileState `json:"files"`
+}
+
+// Index is the seance transcript index of one town.
+type Index struct {
+	dir string
+}
+
+// Open returns the index for townRoot. The index directory is created on
+// the first Update.
+func Open(townRoot string) *Index {
+	return &Index{dir: filepath.Join(townRoot, ".runtime", indexDirName)}
+}
+
+func (ix *Index) statePath() string { return filepath.Join(ix.dir, stateFileName) }
+func (ix *Index) docsPath() string  { return filepath.Join(ix.dir, docsFileName) }
+
+// Update indexes what has been written to each transcript since the last
+// update and returns the number of documents added. beadAt, if non-nil,
+// names the bead an agent had hooked at a time. Transcripts that are missing
+// or in a format that cannot be read incrementally are skipped.
+//
+// The new documents only count once the state recording them is saved, so
+// an interrupted Update is redone in full by the next one.
+func (ix *Index) Update(transcripts []Transcript, beadAt func(agent string, t time.Time) string) (int, error) {
+	if err := os.MkdirAll(ix.dir, 0755); err != nil {
+		return 0, fmt.Errorf("creating index dir: %w", err)
+	}
+	lock := flock.New(filepath.Join(ix.dir, ".lock"))
+	ctx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+	defer cancel()
+	locked, err := lock.TryLockContext(ctx, 100*time.Millisecond)
+	if err != nil {
+		return 0, fmt.Errorf("locking seance index: %w", err)
+	}
+	if !locked {
+		return 0, fmt.Errorf("locking seance index: still held after %s", lockTimeout)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	st, err := ix.loadState()
+	if err != nil {
+		return 0, err
+	}
+
+	// A transcript shorter than its indexed offset was rewritten; drop its
+	// documents and index it again from the start.
+	purge := make(map[string]bool)
+	for _, tr := range transcripts {
+		fs := st.Files[tr.Path]
+		if fs == nil {
+			continue
+		}
+		if info, err := os.Stat(tr.Path); err == nil && info.Size() < fs.Offset {
+			purge[fs.SessionID] = true
+			delete(st.Files, tr.Path)
+		}
+	}
+	if len(purge) > 0 {
+		if err := ix.rewrite(st, func(doc *Doc) bool { return !purge[doc.SessionID] }); err != nil {
+			return 0, err
+		}
+	}
+	if now := time.Now(); now.Sub(st.CompactedAt) >= compactInterval {
+		if err := ix.compact(st, now); err != nil {
+			return 0, err
+		}
+	}
+
+	f, err := os.OpenFile(ix.docsPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("opening index: %w", err)
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+
+	added := 0
+	for _, tr := range transcripts {
+		fs := st.Files[tr.Path]
+		if fs == nil {
+			fs = &fileState{SessionID: tr.SessionID}
+		}
+		events, offset, err := agentlog.ReadLog(tr.Format, tr.Path, fs.Offset)
+		if err != nil {
+			continue
+		}
+		for _, ev := range events {
+			doc, ok := docFor(tr, ev)
+			if !ok {
+				continue
+			}
+			if beadAt != nil && tr.Agent != "" {
+				doc.Bead = beadAt(tr.Agent, ev.Timestamp)
+			}
+			if err := enc.Encode(doc); err != nil {
+				return added, fmt.Errorf("writing index: %w", err)
+			}
+			added++
+		}
+		fs.Offset = offset
+		st.Files[tr.Path] = fs
+	}
+	if err := w.Flush(); err != nil {
+		return added, fmt.Errorf("writing index: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return added, fmt.Errorf("writing index: %w", err)
+	}
+	st.DocsSize = info.Size()
+	return added, ix.saveState(st)
+}
+
+// compact drops documents older than the retention period, and the state of
+// transcripts that no longer exist.
+func (ix *Index) compact(st *indexState, now time.Time) error {
+	for path := range st.Files {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			delete(st.Files, path)
+		}
+	}
+	cutoff := now.Add(-retention)
+	if err := ix.rewrite(st, func(doc *Doc) bool { return !doc.Time.Before(cutoff) }); err != nil {
+		return err
+	}
+	st.CompactedAt = now
+	return ix.saveState(st)
+}
+
+// docFor converts a transcript event into a document, skipping events with
+// no searchable text (e.g. token usage).
+func docFor(tr Transcript, ev agentlog.AgentEvent) (Doc, bool) {
+	text := strings.TrimSpace(ev.Content)
+	if text == "" {
+		return Doc{}, false
+	}
+	kind := ev.EventType
+	limit := maxDocText
+	switch ev.EventType {
+	case "text":
+		kind = ev.Role
+	case "thinking", "tool_use":
+	case "tool_result":
+		limit = maxToolResultText
+	default:
+		return Doc{}, false
+	}
+	return Doc{
+		SessionID: tr.SessionID,
+		Agent:     tr.Agent,
+		Rig:       tr.Rig,
+		Role:      tr.Role,
+		Time:      ev.Timestamp,
+		Kind:      kind,
+		Text:      truncate(text, limit),
+	}, true
+}
+
+// truncate cuts s to at most n bytes without splitting a UTF-8 sequence.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+func (ix *Index) loadState() (*indexState, error) {
+	data, err := os.ReadFile(ix.statePath())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading index state: %w", err)
+	}
+	var st indexState
+	if err == nil {
+		if jsonErr := json.Unmarshal(data, &st); jsonErr != nil {
+			st = indexState{}
+		}
+	}
+	var size int64
+	if info, err := os.Stat(ix.docsPath()); err == nil {
+		size = info.Size()
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading index: %w", err)
+	}
+	switch {
+	case st.Version != stateVersion || size < st.DocsSize:
+		// New, incompatible or damaged index: start over.
+		if err := os.Remove(ix.docsPath()); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("resetting index: %w", err)
+		}
+		st = indexState{Version: stateVersion}
+	case size > st.DocsSize:
+		// An update crashed between writing documents and saving the state
+		// that records them; drop them so they are indexed once, again.
+		if err := os.Truncate(ix.docsPath(), st.DocsSize); err != nil {
+			return nil, fmt.Errorf("truncating index: %w", err)
+		}
+	}
+	if st.Files == nil {
+		st.Files = make(map[string]*fileState)
+	}
+	return &st, nil
+}
+
+// committedSize returns the length of docs.jsonl covered by the saved
+// state, or false if there is no usable state.
+func (ix *Index) committedSize() (int64, bool) {
+	data, err := os.ReadFile(ix.statePath())
+	if err != nil {
+		return 0, false
+	}
+	var st indexState
+	if json.Unmarshal(data, &st) != nil || st.Version != stateVersion {
+		return 0, false
+	}
+	return st.DocsSize, true
+}
+
+func (ix *Index) saveState(st *indexState) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := ix.statePath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("writing index state: %w", err)
+	}
+	return os.Rename(tmp, ix.statePath())
+}
+
+// rewrite replaces the document file with the documents keep accepts and
+// saves st with the new length. If it is interrupted between the two, the
+// next loadState finds the file shorter than recorded and rebuilds the index.
+func (ix *Index) rewrite(st *indexState, keep func(doc *Doc) bool) error {
+	in, err := os.Open(ix.docsPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("opening index: %w", err)
+	}
+	defer in.Close()
+
+	tmp := ix.docsPath() + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("rewriting index: %w", err)
+	}
+	w := bufio.NewWriter(out)
+	var size int64
+	err = scanDocs(in, func(line []byte, doc *Doc) bool {
+		if keep(doc) {
+			_, _ = w.Write(line)
+			_ = w.WriteByte('\n')
+			size += int64(len(line)) + 1
+		}
+		return true
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("rewriting index: %w", err)
+	}
+	if err := os.Rename(tmp, ix.docsPath()); err != nil {
+		return fmt.Errorf("rewriting index: %w", err)
+	}
+	st.DocsSize = size
+	return ix.saveState(st)
+}
+
+// scanDocs calls fn for each document in r until fn returns false.
+// Malformed lines are skipped.
+func scanDocs(r io.Reader, fn func(line []byte, doc *Doc) bool) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var doc Doc
+		if err := json.Unmarshal(sc.Bytes(), &doc); err != nil {
+			continue
+		}
+		if !fn(sc.Bytes(), &doc) {
+			break
+		}
+	}
+	return sc.Err()
+}
@@ -0,0 +1,215 @@
+package seance
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ccLine(ts, role, text string) string {
+	return fmt.Sprintf(`{"type":%q,"timestamp":%q,"message":{"role":%q,"content":[{"type":"text","text":%q}]}}`, role, ts, role, text) + "\n"
+}
+
+func ccToolLine(ts, name, input string) string {
+	return fmt.Sprintf(`{"type":"assistant","timestamp":%q,"message":{"role":"assistant","content":[{"type":"tool_use","name":%q,"input":%s}]}}`, ts, name, input) + "\n"
+}
+
+func writeTranscript(t *testing.T, dir, id string, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(dir, id+".jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestIndexUpdateAndSearch(t *testing.T) {
+	town := t.TempDir()
+	logs := t.TempDir()
+	toast := writeTranscript(t, logs, "sess-toast",
+		ccLine("2026-03-01T10:00:00Z", "assistant", "Looking at the auth middleware now."),
+		ccToolLine("2026-03-01T10:01:00Z", "Edit", `{"file_path":"internal/web/auth_middleware.go"}`),
+	)
+	max := writeTranscript(t, logs, "sess-max",
+		ccLine("2026-03-05T09:00:00Z", "assistant", "Refactored the Auth layer."),
+		ccLine("2026-03-05T09:05:00Z", "assistant", "Unrelated: fixed the mail router."),
+	)
+	transcripts := []Transcript{
+		{SessionID: "sess-toast", Agent: "gastown/polecats/toast", Rig: "gastown", Role: "polecat", Format: "claudecode", Path: toast},
+		{SessionID: "sess-max", Agent: "beads/crew/max", Rig: "beads", Role: "crew", Format: "claudecode", Path: max},
+		{SessionID: "sess-gone", Format: "claudecode", Path: filepath.Join(logs, "missing.jsonl")},
+	}
+	beadAt := func(agent string, ts time.Time) string {
+		if agent == "gastown/polecats/toast" {
+			return "gt-auth"
+		}
+		return ""
+	}
+
+	ix := Open(town)
+	added, err := ix.Update(transcripts, beadAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 4 {
+		t.Errorf("first Update added %d docs, want 4", added)
+	}
+
+	// Incremental: only the appended line is indexed.
+	f, _ := os.OpenFile(max, os.O_APPEND|os.O_WRONLY, 0644)
+	_, _ = f.WriteString(ccLine("2026-03-06T09:00:00Z", "user", "check the auth middleware tests"))
+	f.Close()
+	if added, err = ix.Update(transcripts, beadAt); err != nil || added != 1 {
+		t.Fatalf("second Update = %d, %v; want 1, nil", added, err)
+	}
+
+	hits, err := ix.Search(Query{Terms: ParseTerms("auth middleware")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 2 {
+		t.Fatalf("got %d hits, want 2: %+v", len(hits), hits)
+	}
+	if hits[0].SessionID != "sess-toast" || hits[0].Matches != 2 || hits[0].Bead != "gt-auth" {
+		t.Errorf("top hit = %+v, want sess-toast with 2 matches on gt-auth", hits[0])
+	}
+	if !strings.Contains(strings.ToLower(hits[0].Snippet), "auth") {
+		t.Errorf("snippet %q lacks the match", hits[0].Snippet)
+	}
+
+	filtered, _ := ix.Search(Query{Terms: []string{"auth"}, Rig: "beads", Since: time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)})
+	if len(filtered) != 1 || filtered[0].SessionID != "sess-max" || filtered[0].Kind != "user" {
+		t.Errorf("filtered search = %+v, want the later sess-max user message", filtered)
+	}
+	if byBead, _ := ix.Search(Query{Terms: []string{"auth"}, Bead: "gt-auth"}); len(byBead) != 1 {
+		t.Errorf("bead search = %d hits, want 1", len(byBead))
+	}
+	if phrase, _ := ix.Search(Query{Terms: ParseTerms(`"mail router"`)}); len(phrase) != 1 {
+		t.Errorf("phrase search = %d hits, want 1", len(phrase))
+	}
+}
+
+func TestIndexReindexesRewrittenTranscript(t *testing.T) {
+	town := t.TempDir()
+	logs := t.TempDir()
+	path := writeTranscript(t, logs, "s1",
+		ccLine("2026-03-01T10:00:00Z", "assistant", "alpha beta gamma delta"),
+		ccLine("2026-03-01T10:01:00Z", "assistant", "epsilon"),
+	)
+	trs := []Transcript{{SessionID: "s1", Format: "claudecode", Path: path}}
+	ix := Open(town)
+	if _, err := ix.Update(trs, nil); err != nil {
+		t.Fatal(err)
+	}
+	writeTranscript(t, logs, "s1", ccLine("2026-03-02T10:00:00Z", "assistant", "zeta"))
+	if _, err := ix.Update(trs, nil); err != nil {
+		t.Fatal(err)
+	}
+	if hits, _ := ix.Search(Query{Terms: []string{"alpha"}}); len(hits) != 0 {
+		t.Errorf("stale documents survived a rewrite: %+v", hits)
+	}
+	if hits, _ := ix.Search(Query{Terms: []string{"zeta"}}); len(hits) != 1 {
+		t.Errorf("rewritten transcript not reindexed")
+	}
+}
+
+func TestIndexRecoversFromCrashedUpdate(t *testing.T) {
+	town := t.TempDir()
+	logs := t.TempDir()
+	path := writeTranscript(t, logs, "s1", ccLine("2026-03-01T10:00:00Z", "assistant", "alpha"))
+	trs := []Transcript{{SessionID: "s1", Format: "claudecode", Path: path}}
+	ix := Open(town)
+	if _, err := ix.Update(trs, nil); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := os.ReadFile(ix.statePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Crash after the documents are written but before the state is saved.
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	_, _ = f.WriteString(ccLine("2026-03-01T10:05:00Z", "assistant", "omega"))
+	f.Close()
+	if _, err := ix.Update(trs, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(ix.statePath(), saved, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if hits, _ := ix.Search(Query{Terms: []string{"omega"}}); len(hits) != 0 {
+		t.Errorf("search saw uncommitted documents: %+v", hits)
+	}
+
+	if added, err := ix.Update(trs, nil); err != nil || added != 1 {
+		t.Fatalf("Update after crash = %d, %v; want 1, nil", added, err)
+	}
+	if hits, _ := ix.Search(Query{Terms: []string{"omega"}}); len(hits) != 1 || hits[0].Matches != 1 {
+		t.Errorf("after recovery got %+v, want one match", hits)
+	}
+}
+
+func TestIndexCompactDropsExpiredDocs(t *testing.T) {
+	town := t.TempDir()
+	logs := t.TempDir()
+	recent := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	path := writeTranscript(t, logs, "s1",
+		ccLine("2020-01-01T10:00:00Z", "assistant", "ancient history"),
+		ccLine(recent, "assistant", "recent history"),
+	)
+	gone := writeTranscript(t, logs, "s2", ccLine(recent, "assistant", "deleted later"))
+	trs := []Transcript{
+		{SessionID: "s1", Format: "claudecode", Path: path},
+		{SessionID: "s2", Format: "claudecode", Path: gone},
+	}
+	ix := Open(town)
+	if _, err := ix.Update(trs, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(gone); err != nil {
+		t.Fatal(err)
+	}
+
+	// Make the next Update compact.
+	st, err := ix.loadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.CompactedAt = time.Time{}
+	if err := ix.saveState(st); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ix.Update(trs[:1], nil); err != nil {
+		t.Fatal(err)
+	}
+
+	hits, _ := ix.Search(Query{Terms: []string{"history"}})
+	if len(hits) != 1 || hits[0].Matches != 1 || !strings.Contains(hits[0].Text+hits[0].Snippet, "recent") {
+		t.Errorf("after compaction got %+v, want only the recent document", hits)
+	}
+	if st, _ = ix.loadState(); st.Files[gone] != nil || st.Files[path] == nil {
+		t.Errorf("state files = %v, want only %s", st.Files, path)
+	}
+}
+
+func TestParseTerms(t *testing.T) {
+	got := ParseTerms(`Auth  "Rate   Limit" middleware ""`)
+	want := []string{"auth", "rate limit", "middleware"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("ParseTerms = %q, want %q", got, want)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	long := strings.Repeat("x ", 100) + "needle" + strings.Repeat(" y", 100)
+	s := Snippet(long, []string{"needle"})
+	if !strings.HasPrefix(s, "…") || !strings.HasSuffix(s, "…") || !strings.Contains(s, "needle") {
+		t.Errorf("Snippet = %q", s)
+	}
+	if got := Snippet("short needle", []string{"needle"}); got != "short needle" {
+		t.Errorf("Snippet(short) = %q", got)
+	}
+}
@@ -0,0 +1,182 @@
+package seance
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// snippetRadius is how much text is kept on each side of the first match.
+const snippetRadius = 80
+
+// Query selects documents. Every term must occur in a document's text
+// (case-insensitively); the other fields filter on document keys and are
+// ignored when empty.
+type Query struct {
+	Terms []string
+	Rig   string
+	Role  string
+	Bead  string
+	Agent string // substring of the agent address
+	Since time.Time
+	Until time.Time
+	Limit int // maximum sessions returned; 0 means no limit
+}
+
+// Hit is the best-matching document of one session.
+type Hit struct {
+	Doc
+	Snippet string `json:"snippet"`
+	Matches int    `json:"matches"` // matching documents in the session
+	Score   int    `json:"score"`   // term occurrences across those documents
+}
+
+// ParseTerms splits a query into lower-cased terms. Double-quoted phrases
+// are kept as one term.
+func ParseTerms(query string) []string {
+	var terms []string
+	for i, part := range strings.Split(query, `"`) {
+		part = strings.ToLower(part)
+		if i%2 == 1 {
+			if p := strings.Join(strings.Fields(part), " "); p != "" {
+				terms = append(terms, p)
+			}
+			continue
+		}
+		terms = append(terms, strings.Fields(part)...)
+	}
+	return terms
+}
+
+// Search returns the sessions with documents matching q, best first: most
+// term occurrences, then most recent match.
+func (ix *Index) Search(q Query) ([]Hit, error) {
+	if len(q.Terms) == 0 {
+		return nil, fmt.Errorf("empty query")
+	}
+	f, err := os.Open(ix.docsPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening index: %w", err)
+	}
+	defer f.Close()
+	// Skip documents an in-progress or crashed Update has not committed.
+	var r io.Reader = f
+	if n, ok := ix.committedSize(); ok {
+		r = io.LimitReader(f, n)
+	}
+
+	bySession := make(map[string]*Hit)
+	best := make(map[string]int)
+	err = scanDocs(r, func(_ []byte, doc *Doc) bool {
+		if !q.matchesKeys(doc) {
+			return true
+		}
+		score := termScore(doc.Text, q.Terms)
+		if score == 0 {
+			return true
+		}
+		h := bySession[doc.SessionID]
+		if h == nil {
+			h = &Hit{}
+			bySession[doc.SessionID] = h
+		}
+		h.Matches++
+		h.Score += score
+		if score > best[doc.SessionID] || (score == best[doc.SessionID] && doc.Time.After(h.Time)) {
+			best[doc.SessionID] = score
+			h.Doc = *doc
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading index: %w", err)
+	}
+
+	hits := make([]Hit, 0, len(bySession))
+	for _, h := range bySession {
+		h.Snippet = Snippet(h.Text, q.Terms)
+		h.Text = ""
+		hits = append(hits, *h)
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Time.After(hits[j].Time)
+	})
+	if q.Limit > 0 && len(hits) > q.Limit {
+		hits = hits[:q.Limit]
+	}
+	return hits, nil
+}
+
+func (q Query) matchesKeys(doc *Doc) bool {
+	switch {
+	case q.Rig != "" && !strings.EqualFold(doc.Rig, q.Rig):
+		return false
+	case q.Role != "" && !strings.EqualFold(doc.Role, q.Role):
+		return false
+	case q.Bead != "" && doc.Bead != q.Bead:
+		return false
+	case q.Agent != "" && !strings.Contains(strings.ToLower(doc.Agent), strings.ToLower(q.Agent)):
+		return false
+	case !q.Since.IsZero() && doc.Time.Before(q.Since):
+		return false
+	case !q.Until.IsZero() && doc.Time.After(q.Until):
+		return false
+	}
+	return true
+}
+
+// termScore returns the total occurrences of terms in text, or 0 unless
+// every term occurs.
+func termScore(text string, terms []string) int {
+	lower := strings.ToLower(text)
+	score := 0
+	for _, t := range terms {
+		n := strings.Count(lower, t)
+		if n == 0 {
+			return 0
+		}
+		score += n
+	}
+	return score
+}
+
+// Snippet returns the text around the first occurrence of any term, on one
+// line, with ellipses where it was cut.
+func Snippet(text string, terms []string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	lower := strings.ToLower(text)
+	at := -1
+	for _, t := range terms {
+		if i := strings.Index(lower, t); i >= 0 && (at < 0 || i < at) {
+			at = i
+		}
+	}
+	if at < 0 || at > len(text) {
+		at = 0 // no match, or case folding changed byte offsets
+	}
+	start, end := at-snippetRadius, at+snippetRadius
+	prefix, suffix := "…", "…"
+	if start <= 0 {
+		start, prefix = 0, ""
+	}
+	if end >= len(text) {
+		end, suffix = len(text), ""
+	}
+	// Don't split UTF-8 sequences.
+	for start > 0 && start < len(text) && text[start]&0xC0 == 0x80 {
+		start--
+	}
+	for end < len(text) && text[end]&0xC0 == 0x80 {
+		end++
+	}
+	return prefix + text[start:end] + suffix
+}
@@ -10,10 +10,13 @@ import (
 	"strings"
 	"syscall"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
 )
 
 // ActivateAgentLogging spawns a detached `gt agent-log` process to stream the
-// session's Claude Code JSONL conversation log to VictoriaLogs.
+// session's conversation log (Claude Code JSONL by default, or the LogFormat
+// of the session's GT_AGENT preset) to VictoriaLogs.
 //
 // The process is started with Setsid so it survives the parent's exit.
 // A PID file at /tmp/gt-agentlog-<session>.pid ensures only one watcher
@@ -27,8 +30,18 @@ import (
 // It is passed to the agent-log subprocess so every agent.event it emits
 // carries the same run.id for waterfall correlation. Pass "" to omit.
 //
+// t is the backend the session runs on; its GT_AGENT is read from there.
+//
 // Opt-in: caller must check GT_LOG_AGENT_OUTPUT=true before calling.
-func ActivateAgentLogging(sessionID, workDir, runID string) error {
+func ActivateAgentLogging(t SessionBackend, sessionID, workDir, runID string) error {
+	// The session's GT_AGENT selects the log adapter; agents that do not
+	// declare a log format have nothing to stream.
+	agentName, _ := t.GetEnvironment(sessionID, "GT_AGENT")
+	logFormat := config.GetLogFormat(strings.TrimSpace(agentName))
+	if logFormat == "" {
+		return nil
+	}
+
 	exe, err := os.Executable()
 	if err != nil {
 		return fmt.Errorf("resolving executable: %w", err)
@@ -51,6 +64,7 @@ func ActivateAgentLogging(sessionID, workDir, runID string) error {
 		"--session", sessionID,
 		"--work-dir", workDir,
 		"--since", since,
+		"--agent", logFormat,
 	}
 	if runID != "" {
 		args = append(args, "--run-id", runID)
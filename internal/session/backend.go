@@ -0,0 +1,121 @@
+package session
+
+import (
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/ptyhost"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// SessionBackend is the set of operations Gas Town needs to start, nudge,
+// peek at and stop agent sessions. *tmux.Tmux is the default implementation;
+// *ptyhost.Client runs sessions on daemon-managed PTYs for hosts without tmux.
+//
+// Backend-specific extras (themes, respawn hooks, dialog acceptance) are
+// optional: see the capability interfaces below.
+type SessionBackend interface {
+	NewSessionWithCommand(name, workDir, command string) error
+	HasSession(name string) (bool, error)
+	ListSessions() ([]string, error)
+	KillSession(name string) error
+	KillSessionWithProcesses(name string) error
+
+	SetEnvironment(session, key, value string) error
+	GetEnvironment(session, key string) (string, error)
+
+	SendKeysRaw(session, keys string) error
+	SendKeysDebounced(session, keys string, debounceMs int) error
+	NudgeSession(session, message string) error
+	CapturePane(session string, lines int) (string, error)
+	CapturePaneAll(session string) (string, error)
+
+	IsAgentAlive(session string) bool
+	IsIdle(session string) bool
+	CheckSessionHealth(session string, maxInactivity time.Duration) tmux.ZombieStatus
+	GetPaneID(session string) (string, error)
+	GetPanePID(session string) (string, error)
+	GetPaneWorkDir(session string) (string, error)
+	GetSessionInfo(name string) (*tmux.SessionInfo, error)
+	GetSessionCreatedUnix(session string) (int64, error)
+	GetSessionActivity(session string) (time.Time, error)
+	DismissStartupDialogsBlind(session string) error
+
+	WaitForCommand(session string, excludeCommands []string, timeout time.Duration) error
+	WaitForRuntimeReady(session string, rc *config.RuntimeConfig, timeout time.Duration) error
+}
+
+// Optional backend capabilities. Callers type-assert for them and skip the
+// step when the backend does not provide it.
+type (
+	// RemainOnExitSetter keeps a session around after its process exits.
+	RemainOnExitSetter interface {
+		SetRemainOnExit(pane string, on bool) error
+	}
+	// Themer applies Gas Town status-bar theming.
+	Themer interface {
+		ConfigureGasTownSession(session string, theme *tmux.Theme, rig, worker, role string) error
+	}
+	// AutoRespawner restarts the agent when its process dies.
+	AutoRespawner interface {
+		SetAutoRespawnHook(session string) error
+	}
+	// DialogAcceptor dismisses agent startup dialogs.
+	DialogAcceptor interface {
+		AcceptStartupDialogs(session string) error
+	}
+	// PaneDiedHooker reports agent crashes via a backend hook.
+	PaneDiedHooker interface {
+		SetPaneDiedHook(session, agentID string) error
+	}
+	// EnvSessionCreator creates a session whose environment is set before
+	// the command starts.
+	EnvSessionCreator interface {
+		NewSessionWithCommandAndEnv(name, workDir, command string, env map[string]string) error
+	}
+	// CycleBinder installs key bindings for cycling between crew sessions.
+	CycleBinder interface {
+		SetCrewCycleBindings(session string) error
+	}
+	// Attacher connects the user's terminal to a session.
+	Attacher interface {
+		AttachSession(session string) error
+	}
+)
+
+var (
+	_ SessionBackend = (*tmux.Tmux)(nil)
+	_ SessionBackend = (*ptyhost.Client)(nil)
+)
+
+// BackendName returns the session backend configured for a town
+// (config.SessionBackendTmux unless settings select another).
+func BackendName(townRoot string) string {
+	if townRoot == "" {
+		return config.SessionBackendTmux
+	}
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil || settings.SessionBackend == "" {
+		return config.SessionBackendTmux
+	}
+	return settings.SessionBackend
+}
+
+// NewBackend returns the session backend configured in the town's settings.
+func NewBackend(townRoot string) SessionBackend {
+	return ResolveBackend(townRoot, nil)
+}
+
+// ResolveBackend returns t for tmux towns and the configured backend
+// otherwise. Managers that are handed a *tmux.Tmux use it so their sessions
+// follow the town's session_backend setting; code that creates, checks or
+// kills agent sessions without one should use NewBackend.
+func ResolveBackend(townRoot string, t *tmux.Tmux) SessionBackend {
+	if BackendName(townRoot) == config.SessionBackendHeadless {
+		return ptyhost.NewClient(ptyhost.SocketPath(townRoot))
+	}
+	if t == nil {
+		return tmux.NewTmux()
+	}
+	return t
+}
@@ -0,0 +1,67 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/ptyhost"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+func writeSessionBackendSetting(t *testing.T, townRoot, backend string) {
+	t.Helper()
+	settings := config.NewTownSettings()
+	settings.SessionBackend = backend
+	if err := config.SaveTownSettings(config.TownSettingsPath(townRoot), settings); err != nil {
+		t.Fatalf("saving settings: %v", err)
+	}
+}
+
+func TestBackendName(t *testing.T) {
+	if got := BackendName(""); got != config.SessionBackendTmux {
+		t.Errorf("BackendName(\"\") = %q, want tmux", got)
+	}
+
+	townRoot := t.TempDir()
+	if got := BackendName(townRoot); got != config.SessionBackendTmux {
+		t.Errorf("BackendName without settings = %q, want tmux", got)
+	}
+
+	writeSessionBackendSetting(t, townRoot, config.SessionBackendHeadless)
+	if got := BackendName(townRoot); got != config.SessionBackendHeadless {
+		t.Errorf("BackendName = %q, want headless", got)
+	}
+}
+
+func TestBackendName_UnreadableSettingsFallsBackToTmux(t *testing.T) {
+	townRoot := t.TempDir()
+	path := config.TownSettingsPath(townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := BackendName(townRoot); got != config.SessionBackendTmux {
+		t.Errorf("BackendName = %q, want tmux", got)
+	}
+}
+
+func TestResolveBackend(t *testing.T) {
+	townRoot := t.TempDir()
+	tm := tmux.NewTmux()
+
+	if got := ResolveBackend(townRoot, tm); got != SessionBackend(tm) {
+		t.Errorf("tmux town should keep the given *tmux.Tmux, got %T", got)
+	}
+	if _, ok := NewBackend(townRoot).(*tmux.Tmux); !ok {
+		t.Errorf("NewBackend for tmux town = %T, want *tmux.Tmux", NewBackend(townRoot))
+	}
+
+	writeSessionBackendSetting(t, townRoot, config.SessionBackendHeadless)
+	if _, ok := ResolveBackend(townRoot, tm).(*ptyhost.Client); !ok {
+		t.Errorf("headless town should resolve to *ptyhost.Client, got %T", ResolveBackend(townRoot, tm))
+	}
+}
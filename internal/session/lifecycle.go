@@ -126,7 +126,8 @@ type StartResult struct {
 	RunID string
 }
 
-// StartSession creates a tmux session following the standard Gas Town lifecycle.
+// StartSession creates an agent session following the standard Gas Town lifecycle.
+// t is normally *tmux.Tmux; see SessionBackend and NewBackend.
 //
 // The lifecycle handles:
 //  1. Resolve runtime config for the role
@@ -141,7 +142,7 @@ type StartResult struct {
 // Role-specific concerns (issue validation, fallback nudges, pane-died hooks,
 // crew cycle bindings, etc.) should be handled by the caller before/after
 // calling StartSession.
-func StartSession(t *tmux.Tmux, cfg SessionConfig) (_ *StartResult, retErr error) {
+func StartSession(t SessionBackend, cfg SessionConfig) (_ *StartResult, retErr error) {
 	// Generate the GASTA run ID — the root identifier for all telemetry emitted
 	// by this agent session and its subprocesses (bd, mail, …).
 	runID := uuid.New().String()
@@ -197,14 +198,14 @@ func StartSession(t *tmux.Tmux, cfg SessionConfig) (_ *StartResult, retErr error
 	extraWithRun["GT_RUN"] = runID
 	command = config.PrependEnv(command, extraWithRun)
 
-	// 4. Create tmux session with command.
+	// 4. Create the session with command.
 	if err := t.NewSessionWithCommand(cfg.SessionID, cfg.WorkDir, command); err != nil {
 		return nil, fmt.Errorf("creating session: %w", err)
 	}
 
 	// 5. Set remain-on-exit immediately if requested (before anything else can fail).
-	if cfg.RemainOnExit {
-		_ = t.SetRemainOnExit(cfg.SessionID, true)
+	if r, ok := t.(RemainOnExitSetter); ok && cfg.RemainOnExit {
+		_ = r.SetRemainOnExit(cfg.SessionID, true)
 	}
 
 	// 6. Set environment variables.
@@ -228,8 +229,8 @@ func StartSession(t *tmux.Tmux, cfg SessionConfig) (_ *StartResult, retErr error
 	}
 
 	// 7. Apply theme.
-	if cfg.Theme != nil {
-		_ = t.ConfigureGasTownSession(cfg.SessionID, cfg.Theme, cfg.RigName, cfg.AgentName, cfg.Role)
+	if th, ok := t.(Themer); ok && cfg.Theme != nil {
+		_ = th.ConfigureGasTownSession(cfg.SessionID, cfg.Theme, cfg.RigName, cfg.AgentName, cfg.Role)
 	}
 
 	// 8. Wait for agent to start.
@@ -243,15 +244,15 @@ func StartSession(t *tmux.Tmux, cfg SessionConfig) (_ *StartResult, retErr error
 	}
 
 	// 9. Auto-respawn hook.
-	if cfg.AutoRespawn {
-		if err := t.SetAutoRespawnHook(cfg.SessionID); err != nil {
+	if r, ok := t.(AutoRespawner); ok && cfg.AutoRespawn {
+		if err := r.SetAutoRespawnHook(cfg.SessionID); err != nil {
 			fmt.Printf("warning: failed to set auto-respawn hook for %s: %v\n", cfg.Role, err)
 		}
 	}
 
 	// 10. Accept startup dialogs (workspace trust + bypass permissions).
-	if cfg.AcceptBypass {
-		_ = t.AcceptStartupDialogs(cfg.SessionID)
+	if d, ok := t.(DialogAcceptor); ok && cfg.AcceptBypass {
+		_ = d.AcceptStartupDialogs(cfg.SessionID)
 	}
 
 	// 11. Ready delay: wait for agent to be fully ready at the prompt.
@@ -292,7 +293,7 @@ func StartSession(t *tmux.Tmux, cfg SessionConfig) (_ *StartResult, retErr error
 	// Reads ~/.claude/projects/<hash>/<session>.jsonl and emits agent.event logs.
 	// Non-fatal: observability failures must never block agent startup.
 	if os.Getenv("GT_LOG_AGENT_OUTPUT") == "true" && os.Getenv("GT_OTEL_LOGS_URL") != "" {
-		if err := ActivateAgentLogging(cfg.SessionID, cfg.WorkDir, runID); err != nil {
+		if err := ActivateAgentLogging(t, cfg.SessionID, cfg.WorkDir, runID); err != nil {
 			fmt.Fprintf(os.Stderr, "warning: agent log watcher setup failed for %s: %v\n", cfg.SessionID, err)
 		}
 	}
@@ -337,11 +338,11 @@ func RecordAgentInstantiateFromDir(ctx context.Context, runID, resolvedAgent, ro
 	})
 }
 
-// StopSession stops a tmux session with optional graceful shutdown.
+// StopSession stops a session with optional graceful shutdown.
 //
 // If graceful is true, sends Ctrl-C first and waits for the session to exit
 // before force-killing. This allows the agent to clean up.
-func StopSession(t *tmux.Tmux, sessionID string, graceful bool) error {
+func StopSession(t SessionBackend, sessionID string, graceful bool) error {
 	running, err := t.HasSession(sessionID)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
@@ -424,7 +425,7 @@ func MergeRuntimeLivenessEnv(envVars map[string]string, runtimeConfig *config.Ru
 // If checkAlive is true, only kills zombie sessions (tmux alive but agent dead).
 // If the session exists and the agent is alive, returns ErrAlreadyRunning.
 // If checkAlive is false, kills any existing session unconditionally.
-func KillExistingSession(t *tmux.Tmux, sessionID string, checkAlive bool) (bool, error) {
+func KillExistingSession(t SessionBackend, sessionID string, checkAlive bool) (bool, error) {
 	running, err := t.HasSession(sessionID)
 	if err != nil {
 		return false, fmt.Errorf("checking session: %w", err)
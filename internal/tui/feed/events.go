@@ -14,6 +14,7 @@ import (
 
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/eventbus"
 )
 
 // EventSource represents a source of events
@@ -340,6 +341,56 @@ func (s *GtEventsSource) Close() error {
 	return s.file.Close()
 }
 
+// BusEventsSource receives gt activity events from the daemon's event bus.
+// It carries the same events as GtEventsSource without polling the file.
+type BusEventsSource struct {
+	events chan Event
+	cancel context.CancelFunc
+}
+
+// NewBusEventsSource subscribes to activity events on the town's event bus,
+// starting with the most recent 200 for initial display. It fails if the
+// daemon is not running.
+func NewBusEventsSource(townRoot string) (*BusEventsSource, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	msgs, err := eventbus.NewClient(eventbus.SocketPath(townRoot)).Subscribe(ctx, eventbus.SubscribeOptions{
+		Topics: []string{eventbus.TopicActivity + ".>"},
+		Last:   200,
+	})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	source := &BusEventsSource{
+		events: make(chan Event, 200),
+		cancel: cancel,
+	}
+	go func() {
+		defer close(source.events)
+		for m := range msgs {
+			if event := parseGtEventLine(string(m.Data)); event != nil {
+				select {
+				case source.events <- *event:
+				default:
+				}
+			}
+		}
+	}()
+	return source, nil
+}
+
+// Events returns the event channel
+func (s *BusEventsSource) Events() <-chan Event {
+	return s.events
+}
+
+// Close stops the source
+func (s *BusEventsSource) Close() error {
+	s.cancel()
+	return nil
+}
+
 // parseGtEventLine parses a line from .events.jsonl
 func parseGtEventLine(line string) *Event {
 	if strings.TrimSpace(line) == "" {
@@ -181,6 +181,8 @@ var (
 		"merged":        "✓",
 		"merge_failed":  "✗",
 		"merge_skipped": "⊘",
+		// Convoy events
+		"convoy_closed": "🚚",
 		// General gt events
 		"sling":   "🎯",
 		"hook":    "🪝",
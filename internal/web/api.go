@@ -10,13 +10,16 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/eventbus"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/workspace"
 )
 
 // CommandRequest is the JSON request body for /api/run.
@@ -61,6 +64,8 @@ type APIHandler struct {
 	cmdSem chan struct{}
 	// csrfToken is validated on POST requests to prevent cross-site request forgery.
 	csrfToken string
+	// townRoot locates the daemon's event bus for /api/events. Empty outside a town.
+	townRoot string
 }
 
 const optionsCacheTTL = 30 * time.Second
@@ -77,9 +82,11 @@ func NewAPIHandler(defaultRunTimeout, maxRunTimeout time.Duration, csrfToken str
 	// Use PATH lookup for gt binary. Do NOT use os.Executable() here - during
 	// tests it returns the test binary, causing fork bombs when executed.
 	workDir, _ := os.Getwd()
+	townRoot, _ := workspace.Find(workDir)
 	return &APIHandler{
 		gtPath:            "gt",
 		workDir:           workDir,
+		townRoot:          townRoot,
 		defaultRunTimeout: defaultRunTimeout,
 		maxRunTimeout:     maxRunTimeout,
 		cmdSem:            make(chan struct{}, maxConcurrentCommands),
@@ -2058,10 +2065,22 @@ func parseCommandArgs(command string) []string {
 	return args
 }
 
+// sseDebounce is the minimum spacing of dashboard-update events driven by
+// the event bus, so a burst of events causes one re-render.
+const sseDebounce = time.Second
+
+// ssePollInterval is how often handleSSE hashes dashboard state.
+const ssePollInterval = 2 * time.Second
+
 // handleSSE streams Server-Sent Events to the dashboard client.
-// It polls key dashboard state every 2 seconds and sends an event when
-// changes are detected, allowing the client to trigger a re-render.
-// Falls through gracefully if the client disconnects.
+// It polls key dashboard state every 2 seconds and sends a dashboard-update
+// when changes are detected, allowing the client to trigger a re-render.
+// With the daemon's event bus running, every bus message is also forwarded
+// as a gt-event (with its sequence number as the SSE id, so a reconnecting
+// client resumes via Last-Event-ID) and followed by a debounced
+// dashboard-update. Polling continues alongside the bus, since not every
+// state change is published there, and carries on alone if the bus goes
+// away. Falls through gracefully if the client disconnects.
 func (h *APIHandler) handleSSE(w http.ResponseWriter, r *http.Request) {
 	flusher, ok := w.(http.Flusher)
 	if !ok {
@@ -2080,14 +2099,19 @@ func (h *APIHandler) handleSSE(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "event: connected\ndata: ok\n\n")
 	flusher.Flush()
 
+	msgs := h.subscribeBus(ctx, r.Header.Get("Last-Event-ID"))
+
 	var lastHash string
-	ticker := time.NewTicker(2 * time.Second)
+	ticker := time.NewTicker(ssePollInterval)
 	defer ticker.Stop()
 
 	// Send keepalive comment every 15 seconds to prevent connection timeouts
 	keepalive := time.NewTicker(15 * time.Second)
 	defer keepalive.Stop()
 
+	var update <-chan time.Time
+	var lastSeq uint64
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -2102,10 +2126,47 @@ func (h *APIHandler) handleSSE(w http.ResponseWriter, r *http.Request) {
 				fmt.Fprintf(w, "event: dashboard-update\ndata: %s\n\n", hash)
 				flusher.Flush()
 			}
+		case m, ok := <-msgs:
+			if !ok {
+				msgs = nil // bus went away; keep polling
+				continue
+			}
+			data, err := json.Marshal(m)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "id: %d\nevent: gt-event\ndata: %s\n\n", m.Seq, data)
+			flusher.Flush()
+			lastSeq = m.Seq
+			if update == nil {
+				update = time.After(sseDebounce)
+			}
+		case <-update:
+			update = nil
+			fmt.Fprintf(w, "event: dashboard-update\ndata: %d\n\n", lastSeq)
+			flusher.Flush()
 		}
 	}
 }
 
+// subscribeBus subscribes to the daemon's event bus, resuming after
+// lastEventID if it is a sequence number. It returns nil if the bus is
+// unreachable; receiving from a nil channel blocks, so handleSSE just polls.
+func (h *APIHandler) subscribeBus(ctx context.Context, lastEventID string) <-chan eventbus.Message {
+	if h.townRoot == "" {
+		return nil
+	}
+	opts := eventbus.SubscribeOptions{}
+	if seq, err := strconv.ParseUint(lastEventID, 10, 64); err == nil && seq > 0 {
+		opts.From = seq + 1
+	}
+	msgs, err := eventbus.NewClient(eventbus.SocketPath(h.townRoot)).Subscribe(ctx, opts)
+	if err != nil {
+		return nil
+	}
+	return msgs
+}
+
 // computeDashboardHash generates a lightweight hash of key dashboard state.
 // It runs quick commands in parallel and hashes their output to detect changes.
 func (h *APIHandler) computeDashboardHash(ctx context.Context) string {
@@ -6,11 +6,15 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/steveyegge/gastown/internal/eventbus"
 	"github.com/steveyegge/gastown/internal/session"
 )
 
@@ -878,6 +882,75 @@ func TestAPIHandler_SSE_ContentType(t *testing.T) {
 	}
 }
 
+// With the event bus running, /api/events forwards bus messages and still
+// polls dashboard state, since not every change is published on the bus.
+func TestAPIHandler_SSE_BusAndPolling(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as gt")
+	}
+	// Unix socket paths are length-limited, so avoid t.TempDir's long names.
+	townRoot, err := os.MkdirTemp("", "sse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(townRoot) })
+
+	b, err := eventbus.Open(eventbus.Dir(townRoot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = b.Close() })
+	busCtx, cancelBus := context.WithCancel(context.Background())
+	t.Cleanup(cancelBus)
+	socket := eventbus.SocketPath(townRoot)
+	go func() { _ = eventbus.Serve(busCtx, b, socket) }()
+	c := eventbus.NewClient(socket)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := c.Publish("activity.boot", map[string]string{"rig": "gastown"}); err == nil {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("bus never came up: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if _, err := c.Publish("activity.sling", map[string]string{"rig": "gastown"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A gt whose output always changes, so every poll sees new state.
+	gt := filepath.Join(t.TempDir(), "gt")
+	if err := os.WriteFile(gt, []byte("#!/bin/sh\ndate +%s%N\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	h := &APIHandler{
+		gtPath:            gt,
+		workDir:           townRoot,
+		townRoot:          townRoot,
+		defaultRunTimeout: 5 * time.Second,
+		maxRunTimeout:     10 * time.Second,
+		cmdSem:            make(chan struct{}, maxConcurrentCommands),
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/events", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	ctx, cancel := context.WithTimeout(req.Context(), ssePollInterval+600*time.Millisecond)
+	defer cancel()
+	w := httptest.NewRecorder()
+	h.handleSSE(w, req.WithContext(ctx))
+
+	body := w.Body.String()
+	if !strings.Contains(body, "id: 2\nevent: gt-event\n") {
+		t.Errorf("bus message 2 not forwarded:\n%s", body)
+	}
+	if !strings.Contains(body, "event: dashboard-update\ndata: 2\n") {
+		t.Errorf("no dashboard-update after the bus message:\n%s", body)
+	}
+	if n := strings.Count(body, "event: dashboard-update\n"); n < 2 {
+		t.Errorf("got %d dashboard-update events, want the polled one too:\n%s", n, body)
+	}
+}
+
 // TestOptionsCacheConcurrentAccess verifies that concurrent cache reads and
 // writes don't race. The read lock is held through serialization so a
 // concurrent writer can't replace the cached pointer mid-encode.
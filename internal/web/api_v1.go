@@ -0,0 +1,454 @@
+package web
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIVersion is the version of the typed JSON API served under /api/v1.
+const APIVersion = "v1"
+
+// openAPIv1 is the OpenAPI document describing the /api/v1 surface.
+//
+//go:embed openapi_v1.json
+var openAPIv1 []byte
+
+// ErrNotFound is returned by V1Backend implementations when the requested
+// resource does not exist. Handlers map it to HTTP 404.
+var ErrNotFound = errors.New("not found")
+
+// ErrInvalidRequest is returned by V1Backend implementations when request
+// parameters are malformed. Handlers map it to HTTP 400.
+var ErrInvalidRequest = errors.New("invalid request")
+
+// V1Error is the error envelope returned by every /api/v1 endpoint.
+type V1Error struct {
+	Error  string `json:"error"`
+	Status int    `json:"status"`
+}
+
+// V1MailMessage is a mail message as returned by /api/v1/mail.
+type V1MailMessage struct {
+	ID        string    `json:"id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Read      bool      `json:"read"`
+	Priority  string    `json:"priority"`
+	Type      string    `json:"type"`
+	ThreadID  string    `json:"thread_id,omitempty"`
+	ReplyTo   string    `json:"reply_to,omitempty"`
+	Pinned    bool      `json:"pinned,omitempty"`
+}
+
+// V1MailList is the response for GET /api/v1/mail.
+type V1MailList struct {
+	Address     string          `json:"address"`
+	Messages    []V1MailMessage `json:"messages"`
+	Total       int             `json:"total"`
+	UnreadCount int             `json:"unread_count"`
+}
+
+// V1MailSendRequest is the request body for POST /api/v1/mail.
+type V1MailSendRequest struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Subject  string `json:"subject"`
+	Body     string `json:"body"`
+	Priority string `json:"priority,omitempty"`
+	Type     string `json:"type,omitempty"`
+	ReplyTo  string `json:"reply_to,omitempty"`
+}
+
+// V1MailSendResponse is the response for POST /api/v1/mail.
+type V1MailSendResponse struct {
+	ID string `json:"id"`
+}
+
+// V1IssueQuery holds the filters accepted by GET /api/v1/issues.
+type V1IssueQuery struct {
+	Rig      string // Rig whose beads to query (empty = town beads)
+	Status   string // open, closed, in_progress, all (empty = open)
+	Label    string // e.g. "gt:task"
+	Assignee string
+	Limit    int
+}
+
+// V1Dependency is a dependency edge on an issue.
+type V1Dependency struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	Type   string `json:"type,omitempty"`
+}
+
+// V1Issue is a bead as returned by /api/v1/issues.
+type V1Issue struct {
+	ID           string         `json:"id"`
+	Title        string         `json:"title"`
+	Description  string         `json:"description,omitempty"`
+	Status       string         `json:"status"`
+	Priority     int            `json:"priority"`
+	Type         string         `json:"type"`
+	Assignee     string         `json:"assignee,omitempty"`
+	Labels       []string       `json:"labels"`
+	Parent       string         `json:"parent,omitempty"`
+	CreatedAt    string         `json:"created_at"`
+	UpdatedAt    string         `json:"updated_at"`
+	ClosedAt     string         `json:"closed_at,omitempty"`
+	Dependencies []V1Dependency `json:"dependencies,omitempty"`
+	Dependents   []V1Dependency `json:"dependents,omitempty"`
+}
+
+// V1IssueList is the response for GET /api/v1/issues.
+type V1IssueList struct {
+	Issues []V1Issue `json:"issues"`
+	Total  int       `json:"total"`
+}
+
+// V1Rig is a rig as returned by /api/v1/rigs.
+type V1Rig struct {
+	Name   string `json:"name"`
+	GitURL string `json:"git_url,omitempty"`
+}
+
+// V1RigList is the response for GET /api/v1/rigs.
+type V1RigList struct {
+	Rigs []V1Rig `json:"rigs"`
+}
+
+// V1MergeRequest is a merge-queue entry as returned by the refinery.
+type V1MergeRequest struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Branch      string    `json:"branch"`
+	Target      string    `json:"target"`
+	SourceIssue string    `json:"source_issue,omitempty"`
+	Worker      string    `json:"worker,omitempty"`
+	Priority    int       `json:"priority"`
+	State       string    `json:"state"` // ready, blocked, claimed
+	Assignee    string    `json:"assignee,omitempty"`
+	BlockedBy   string    `json:"blocked_by,omitempty"`
+	ConvoyID    string    `json:"convoy_id,omitempty"`
+	RetryCount  int       `json:"retry_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// V1MergeQueue is the response for GET /api/v1/rigs/{rig}/merge-queue.
+type V1MergeQueue struct {
+	Rig           string           `json:"rig"`
+	MergeRequests []V1MergeRequest `json:"merge_requests"`
+}
+
+// V1Polecat is a polecat worker as returned by /api/v1/rigs/{rig}/polecats.
+type V1Polecat struct {
+	Name      string    `json:"name"`
+	Rig       string    `json:"rig"`
+	State     string    `json:"state"`
+	Branch    string    `json:"branch,omitempty"`
+	Issue     string    `json:"issue,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// V1PolecatList is the response for GET /api/v1/rigs/{rig}/polecats.
+type V1PolecatList struct {
+	Rig      string      `json:"rig"`
+	Polecats []V1Polecat `json:"polecats"`
+}
+
+// V1TrackedIssue is an issue tracked by a convoy.
+type V1TrackedIssue struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Status   string `json:"status"`
+	Assignee string `json:"assignee,omitempty"`
+}
+
+// V1Convoy is a convoy as returned by /api/v1/convoys.
+type V1Convoy struct {
+	ID        string           `json:"id"`
+	Title     string           `json:"title"`
+	Status    string           `json:"status"`
+	CreatedAt string           `json:"created_at"`
+	Total     int              `json:"total"`
+	Completed int              `json:"completed"`
+	Tracked   []V1TrackedIssue `json:"tracked,omitempty"`
+}
+
+// V1ConvoyList is the response for GET /api/v1/convoys.
+type V1ConvoyList struct {
+	Convoys []V1Convoy `json:"convoys"`
+}
+
+// V1Backend supplies the data behind /api/v1. The live implementation calls
+// the mail, beads, refinery and polecat packages in-process; tests substitute
+// a fake.
+type V1Backend interface {
+	ListMail(address string, unreadOnly bool) ([]V1MailMessage, error)
+	GetMail(address, id string) (*V1MailMessage, error)
+	SendMail(req V1MailSendRequest) (string, error)
+	ListIssues(q V1IssueQuery) ([]V1Issue, error)
+	GetIssue(rig, id string) (*V1Issue, error)
+	ListRigs() ([]V1Rig, error)
+	ListMergeQueue(rig string) ([]V1MergeRequest, error)
+	ListPolecats(rig string) ([]V1Polecat, error)
+	ListConvoys(status string) ([]V1Convoy, error)
+	GetConvoy(id string) (*V1Convoy, error)
+}
+
+// APIv1Handler serves the typed, versioned JSON API under /api/v1.
+type APIv1Handler struct {
+	backend   V1Backend
+	csrfToken string
+	mux       *http.ServeMux
+}
+
+// NewAPIv1Handler creates a /api/v1 handler backed by backend.
+// Every request must carry csrfToken in the X-Dashboard-Token header.
+func NewAPIv1Handler(backend V1Backend, csrfToken string) *APIv1Handler {
+	h := &APIv1Handler{backend: backend, csrfToken: csrfToken, mux: http.NewServeMux()}
+	h.mux.HandleFunc("GET /api/v1/openapi.json", h.handleOpenAPI)
+	h.mux.HandleFunc("GET /api/v1/mail", h.handleMailList)
+	h.mux.HandleFunc("POST /api/v1/mail", h.handleMailSend)
+	h.mux.HandleFunc("GET /api/v1/mail/{id}", h.handleMailGet)
+	h.mux.HandleFunc("GET /api/v1/issues", h.handleIssueList)
+	h.mux.HandleFunc("GET /api/v1/issues/{id}", h.handleIssueGet)
+	h.mux.HandleFunc("GET /api/v1/rigs", h.handleRigList)
+	h.mux.HandleFunc("GET /api/v1/rigs/{rig}/merge-queue", h.handleMergeQueue)
+	h.mux.HandleFunc("GET /api/v1/rigs/{rig}/polecats", h.handlePolecats)
+	h.mux.HandleFunc("GET /api/v1/convoys", h.handleConvoyList)
+	h.mux.HandleFunc("GET /api/v1/convoys/{id}", h.handleConvoyGet)
+	return h
+}
+
+// ServeHTTP enforces CSRF protection and dispatches to the v1 routes.
+//
+// One policy covers the whole prefix, reads included, so that a new route
+// cannot be exposed by forgetting to protect it: the request must come from
+// the dashboard's own origin and carry the token embedded in the dashboard
+// page.
+func (h *APIv1Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !sameOrigin(r) {
+		h.sendError(w, "cross-origin requests are not allowed", http.StatusForbidden)
+		return
+	}
+	if h.csrfToken != "" {
+		if r.Header.Get("X-Dashboard-Token") != h.csrfToken {
+			h.sendError(w, "invalid or missing dashboard token", http.StatusForbidden)
+			return
+		}
+	}
+
+	// Unknown paths get the JSON error envelope rather than ServeMux's
+	// plain-text 404.
+	if _, pattern := h.mux.Handler(r); pattern == "" {
+		h.sendError(w, "no such endpoint: "+r.Method+" "+r.URL.Path, http.StatusNotFound)
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// sameOrigin reports whether r was issued by a page on the dashboard's own
+// origin. Browsers send Sec-Fetch-Site and Origin on cross-site requests;
+// requests without either (curl, scripts) are not browser-initiated and are
+// left to the token check.
+func sameOrigin(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "", "same-origin", "none":
+	default:
+		return false
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+func (h *APIv1Handler) handleOpenAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(openAPIv1)
+}
+
+func (h *APIv1Handler) handleMailList(w http.ResponseWriter, r *http.Request) {
+	address := r.URL.Query().Get("address")
+	if address == "" {
+		h.sendError(w, "address query parameter is required", http.StatusBadRequest)
+		return
+	}
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
+	messages, err := h.backend.ListMail(address, unreadOnly)
+	if err != nil {
+		h.sendBackendError(w, err)
+		return
+	}
+	resp := V1MailList{Address: address, Messages: nonNil(messages), Total: len(messages)}
+	for _, m := range messages {
+		if !m.Read {
+			resp.UnreadCount++
+		}
+	}
+	h.sendJSON(w, http.StatusOK, resp)
+}
+
+func (h *APIv1Handler) handleMailGet(w http.ResponseWriter, r *http.Request) {
+	address := r.URL.Query().Get("address")
+	if address == "" {
+		h.sendError(w, "address query parameter is required", http.StatusBadRequest)
+		return
+	}
+	msg, err := h.backend.GetMail(address, r.PathValue("id"))
+	if err != nil {
+		h.sendBackendError(w, err)
+		return
+	}
+	h.sendJSON(w, http.StatusOK, msg)
+}
+
+func (h *APIv1Handler) handleMailSend(w http.ResponseWriter, r *http.Request) {
+	var req V1MailSendRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		h.sendError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.From == "" || req.To == "" || req.Subject == "" {
+		h.sendError(w, "from, to and subject are required", http.StatusBadRequest)
+		return
+	}
+	id, err := h.backend.SendMail(req)
+	if err != nil {
+		h.sendBackendError(w, err)
+		return
+	}
+	h.sendJSON(w, http.StatusCreated, V1MailSendResponse{ID: id})
+}
+
+func (h *APIv1Handler) handleIssueList(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := V1IssueQuery{
+		Rig:      q.Get("rig"),
+		Status:   q.Get("status"),
+		Label:    q.Get("label"),
+		Assignee: q.Get("assignee"),
+	}
+	if limit := q.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			h.sendError(w, "limit must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		query.Limit = n
+	}
+
+	issues, err := h.backend.ListIssues(query)
+	if err != nil {
+		h.sendBackendError(w, err)
+		return
+	}
+	h.sendJSON(w, http.StatusOK, V1IssueList{Issues: nonNil(issues), Total: len(issues)})
+}
+
+func (h *APIv1Handler) handleIssueGet(w http.ResponseWriter, r *http.Request) {
+	issue, err := h.backend.GetIssue(r.URL.Query().Get("rig"), r.PathValue("id"))
+	if err != nil {
+		h.sendBackendError(w, err)
+		return
+	}
+	h.sendJSON(w, http.StatusOK, issue)
+}
+
+func (h *APIv1Handler) handleRigList(w http.ResponseWriter, _ *http.Request) {
+	rigs, err := h.backend.ListRigs()
+	if err != nil {
+		h.sendBackendError(w, err)
+		return
+	}
+	h.sendJSON(w, http.StatusOK, V1RigList{Rigs: nonNil(rigs)})
+}
+
+func (h *APIv1Handler) handleMergeQueue(w http.ResponseWriter, r *http.Request) {
+	rig := r.PathValue("rig")
+	mrs, err := h.backend.ListMergeQueue(rig)
+	if err != nil {
+		h.sendBackendError(w, err)
+		return
+	}
+	h.sendJSON(w, http.StatusOK, V1MergeQueue{Rig: rig, MergeRequests: nonNil(mrs)})
+}
+
+func (h *APIv1Handler) handlePolecats(w http.ResponseWriter, r *http.Request) {
+	rig := r.PathValue("rig")
+	polecats, err := h.backend.ListPolecats(rig)
+	if err != nil {
+		h.sendBackendError(w, err)
+		return
+	}
+	h.sendJSON(w, http.StatusOK, V1PolecatList{Rig: rig, Polecats: nonNil(polecats)})
+}
+
+func (h *APIv1Handler) handleConvoyList(w http.ResponseWriter, r *http.Request) {
+	convoys, err := h.backend.ListConvoys(r.URL.Query().Get("status"))
+	if err != nil {
+		h.sendBackendError(w, err)
+		return
+	}
+	h.sendJSON(w, http.StatusOK, V1ConvoyList{Convoys: nonNil(convoys)})
+}
+
+func (h *APIv1Handler) handleConvoyGet(w http.ResponseWriter, r *http.Request) {
+	convoy, err := h.backend.GetConvoy(r.PathValue("id"))
+	if err != nil {
+		h.sendBackendError(w, err)
+		return
+	}
+	h.sendJSON(w, http.StatusOK, convoy)
+}
+
+// sendJSON writes v as a JSON response with the given status.
+func (h *APIv1Handler) sendJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-API-Version", APIVersion)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("api/v1: encoding response: %v", err)
+	}
+}
+
+// sendError writes the V1Error envelope.
+func (h *APIv1Handler) sendError(w http.ResponseWriter, message string, status int) {
+	h.sendJSON(w, status, V1Error{Error: message, Status: status})
+}
+
+// sendBackendError maps backend errors onto HTTP status codes.
+func (h *APIv1Handler) sendBackendError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		h.sendError(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrInvalidRequest):
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.sendError(w, strings.TrimSpace(err.Error()), http.StatusInternalServerError)
+	}
+}
+
+// nonNil returns s, or an empty slice if s is nil, so list fields encode as []
+// instead of null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
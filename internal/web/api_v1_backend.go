@@ -0,0 +1,363 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// LiveV1Backend implements V1Backend by calling Gas Town packages in-process
+// against a town root, instead of shelling out to gt/bd and parsing text.
+type LiveV1Backend struct {
+	townRoot string
+}
+
+// NewLiveV1Backend creates a backend for the town at townRoot.
+func NewLiveV1Backend(townRoot string) *LiveV1Backend {
+	return &LiveV1Backend{townRoot: townRoot}
+}
+
+// ListMail returns the messages in address's mailbox, newest first.
+func (b *LiveV1Backend) ListMail(address string, unreadOnly bool) ([]V1MailMessage, error) {
+	mailbox, err := mail.NewRouter(b.townRoot).GetMailbox(address)
+	if err != nil {
+		return nil, fmt.Errorf("%w: mailbox %q: %v", ErrInvalidRequest, address, err)
+	}
+	var messages []*mail.Message
+	if unreadOnly {
+		messages, err = mailbox.ListUnread()
+	} else {
+		messages, err = mailbox.List()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("listing mail: %w", err)
+	}
+
+	result := make([]V1MailMessage, 0, len(messages))
+	for _, m := range messages {
+		v := toV1MailMessage(m)
+		v.Body = "" // List responses omit bodies; fetch a message for its body.
+		result = append(result, v)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Timestamp.After(result[j].Timestamp)
+	})
+	return result, nil
+}
+
+// GetMail returns a single message, including its body.
+func (b *LiveV1Backend) GetMail(address, id string) (*V1MailMessage, error) {
+	mailbox, err := mail.NewRouter(b.townRoot).GetMailbox(address)
+	if err != nil {
+		return nil, fmt.Errorf("%w: mailbox %q: %v", ErrInvalidRequest, address, err)
+	}
+	msg, err := mailbox.Get(id)
+	if errors.Is(err, mail.ErrMessageNotFound) {
+		return nil, fmt.Errorf("%w: message %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading message %s: %w", id, err)
+	}
+	v := toV1MailMessage(msg)
+	return &v, nil
+}
+
+// SendMail delivers a message through the mail router and returns its ID.
+func (b *LiveV1Backend) SendMail(req V1MailSendRequest) (string, error) {
+	msg := mail.NewMessage(req.From, req.To, req.Subject, req.Body)
+	if req.Priority != "" {
+		msg.Priority = mail.ParsePriority(req.Priority)
+	}
+	if req.Type != "" {
+		msg.Type = mail.ParseMessageType(req.Type)
+	}
+	msg.ReplyTo = req.ReplyTo
+	if err := msg.Validate(); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	if err := mail.NewRouter(b.townRoot).Send(msg); err != nil {
+		return "", fmt.Errorf("sending mail: %w", err)
+	}
+	return msg.ID, nil
+}
+
+// ListIssues lists beads from the town database, or a rig's when q.Rig is set.
+func (b *LiveV1Backend) ListIssues(q V1IssueQuery) ([]V1Issue, error) {
+	bd, err := b.beadsFor(q.Rig)
+	if err != nil {
+		return nil, err
+	}
+	status := q.Status
+	if status == "" {
+		status = "open"
+	}
+	issues, err := bd.List(beads.ListOptions{
+		Status:   status,
+		Label:    q.Label,
+		Assignee: q.Assignee,
+		Priority: -1,
+		Limit:    q.Limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing issues: %w", err)
+	}
+	result := make([]V1Issue, 0, len(issues))
+	for _, issue := range issues {
+		result = append(result, toV1Issue(issue))
+	}
+	return result, nil
+}
+
+// GetIssue returns a single bead with its dependencies.
+func (b *LiveV1Backend) GetIssue(rigName, id string) (*V1Issue, error) {
+	bd, err := b.beadsFor(rigName)
+	if err != nil {
+		return nil, err
+	}
+	issue, err := bd.Show(id)
+	if errors.Is(err, beads.ErrNotFound) {
+		return nil, fmt.Errorf("%w: issue %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("showing issue %s: %w", id, err)
+	}
+	v := toV1Issue(issue)
+	return &v, nil
+}
+
+// ListRigs returns the registered rigs, sorted by name.
+func (b *LiveV1Backend) ListRigs() ([]V1Rig, error) {
+	rigs, err := b.rigManager().DiscoverRigs()
+	if err != nil {
+		return nil, fmt.Errorf("discovering rigs: %w", err)
+	}
+	result := make([]V1Rig, 0, len(rigs))
+	for _, r := range rigs {
+		result = append(result, V1Rig{Name: r.Name, GitURL: r.GitURL})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// ListMergeQueue returns the open merge requests for a rig from the refinery.
+func (b *LiveV1Backend) ListMergeQueue(rigName string) ([]V1MergeRequest, error) {
+	r, err := b.getRig(rigName)
+	if err != nil {
+		return nil, err
+	}
+	eng := refinery.NewEngineer(r)
+	eng.SetOutput(io.Discard)
+	mrs, err := eng.ListAllOpenMRs()
+	if err != nil {
+		return nil, fmt.Errorf("listing merge queue: %w", err)
+	}
+	result := make([]V1MergeRequest, 0, len(mrs))
+	for _, mr := range mrs {
+		result = append(result, toV1MergeRequest(mr))
+	}
+	return result, nil
+}
+
+// ListPolecats returns the polecats in a rig.
+func (b *LiveV1Backend) ListPolecats(rigName string) ([]V1Polecat, error) {
+	r, err := b.getRig(rigName)
+	if err != nil {
+		return nil, err
+	}
+	mgr := polecat.NewManager(r, git.NewGit(r.Path), tmux.NewTmux())
+	polecats, err := mgr.List()
+	if err != nil {
+		return nil, fmt.Errorf("listing polecats: %w", err)
+	}
+	result := make([]V1Polecat, 0, len(polecats))
+	for _, p := range polecats {
+		result = append(result, V1Polecat{
+			Name:      p.Name,
+			Rig:       p.Rig,
+			State:     string(p.State),
+			Branch:    p.Branch,
+			Issue:     p.Issue,
+			CreatedAt: p.CreatedAt,
+			UpdatedAt: p.UpdatedAt,
+		})
+	}
+	return result, nil
+}
+
+// ListConvoys returns convoys in the town beads with their progress.
+func (b *LiveV1Backend) ListConvoys(status string) ([]V1Convoy, error) {
+	if status == "" {
+		status = "open"
+	}
+	bd := beads.New(b.townRoot)
+	issues, err := bd.List(beads.ListOptions{Status: status, Type: "convoy", Priority: -1})
+	if err != nil {
+		return nil, fmt.Errorf("listing convoys: %w", err)
+	}
+	result := make([]V1Convoy, 0, len(issues))
+	for _, issue := range issues {
+		convoy, err := b.GetConvoy(issue.ID)
+		if err != nil {
+			return nil, err
+		}
+		convoy.Tracked = nil // List responses carry counts only.
+		result = append(result, *convoy)
+	}
+	return result, nil
+}
+
+// GetConvoy returns a convoy and the issues it tracks.
+func (b *LiveV1Backend) GetConvoy(id string) (*V1Convoy, error) {
+	bd := beads.New(b.townRoot)
+	issue, err := bd.Show(id)
+	if errors.Is(err, beads.ErrNotFound) {
+		return nil, fmt.Errorf("%w: convoy %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("showing convoy %s: %w", id, err)
+	}
+
+	convoy := &V1Convoy{
+		ID:        issue.ID,
+		Title:     issue.Title,
+		Status:    issue.Status,
+		CreatedAt: issue.CreatedAt,
+		Tracked:   []V1TrackedIssue{},
+	}
+	var trackedIDs []string
+	for _, dep := range issue.Dependencies {
+		if dep.DependencyType == "tracks" {
+			trackedIDs = append(trackedIDs, beads.ExtractIssueID(dep.ID))
+		}
+	}
+	if len(trackedIDs) == 0 {
+		return convoy, nil
+	}
+
+	details, err := bd.ShowMultiple(trackedIDs)
+	if err != nil {
+		return nil, fmt.Errorf("fetching tracked issues for %s: %w", id, err)
+	}
+	for _, tid := range trackedIDs {
+		t := V1TrackedIssue{ID: tid, Status: "unknown"}
+		if d, ok := details[tid]; ok {
+			t.Title, t.Status, t.Assignee = d.Title, d.Status, d.Assignee
+		}
+		if t.Status == "closed" {
+			convoy.Completed++
+		}
+		convoy.Tracked = append(convoy.Tracked, t)
+	}
+	convoy.Total = len(convoy.Tracked)
+	return convoy, nil
+}
+
+// beadsFor returns the beads client for a rig, or the town beads if rigName is empty.
+func (b *LiveV1Backend) beadsFor(rigName string) (*beads.Beads, error) {
+	if rigName == "" {
+		return beads.NewWithBeadsDir(b.townRoot, filepath.Join(b.townRoot, ".beads")), nil
+	}
+	r, err := b.getRig(rigName)
+	if err != nil {
+		return nil, err
+	}
+	return beads.NewWithBeadsDir(r.Path, beads.ResolveBeadsDir(r.Path)), nil
+}
+
+func (b *LiveV1Backend) rigManager() *rig.Manager {
+	rigsConfig, err := config.LoadRigsConfig(constants.MayorRigsPath(b.townRoot))
+	if err != nil {
+		rigsConfig = &config.RigsConfig{Rigs: make(map[string]config.RigEntry)}
+	}
+	return rig.NewManager(b.townRoot, rigsConfig, git.NewGit(b.townRoot))
+}
+
+func (b *LiveV1Backend) getRig(name string) (*rig.Rig, error) {
+	r, err := b.rigManager().GetRig(name)
+	if errors.Is(err, rig.ErrRigNotFound) {
+		return nil, fmt.Errorf("%w: rig %q", ErrNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading rig %q: %w", name, err)
+	}
+	return r, nil
+}
+
+func toV1MailMessage(m *mail.Message) V1MailMessage {
+	return V1MailMessage{
+		ID:        m.ID,
+		From:      m.From,
+		To:        m.To,
+		Subject:   m.Subject,
+		Body:      m.Body,
+		Timestamp: m.Timestamp,
+		Read:      m.Read,
+		Priority:  string(m.Priority),
+		Type:      string(m.Type),
+		ThreadID:  m.ThreadID,
+		ReplyTo:   m.ReplyTo,
+		Pinned:    m.Pinned,
+	}
+}
+
+func toV1Issue(issue *beads.Issue) V1Issue {
+	v := V1Issue{
+		ID:          issue.ID,
+		Title:       issue.Title,
+		Description: issue.Description,
+		Status:      issue.Status,
+		Priority:    issue.Priority,
+		Type:        issue.Type,
+		Assignee:    issue.Assignee,
+		Labels:      nonNil(issue.Labels),
+		Parent:      issue.Parent,
+		CreatedAt:   issue.CreatedAt,
+		UpdatedAt:   issue.UpdatedAt,
+		ClosedAt:    issue.ClosedAt,
+	}
+	for _, d := range issue.Dependencies {
+		v.Dependencies = append(v.Dependencies, V1Dependency{ID: d.ID, Title: d.Title, Status: d.Status, Type: d.DependencyType})
+	}
+	for _, d := range issue.Dependents {
+		v.Dependents = append(v.Dependents, V1Dependency{ID: d.ID, Title: d.Title, Status: d.Status, Type: d.DependencyType})
+	}
+	return v
+}
+
+func toV1MergeRequest(mr *refinery.MRInfo) V1MergeRequest {
+	state := "ready"
+	switch {
+	case mr.BlockedBy != "":
+		state = "blocked"
+	case mr.Assignee != "":
+		state = "claimed"
+	}
+	return V1MergeRequest{
+		ID:          mr.ID,
+		Title:       mr.Title,
+		Branch:      mr.Branch,
+		Target:      mr.Target,
+		SourceIssue: mr.SourceIssue,
+		Worker:      mr.Worker,
+		Priority:    mr.Priority,
+		State:       state,
+		Assignee:    mr.Assignee,
+		BlockedBy:   mr.BlockedBy,
+		ConvoyID:    mr.ConvoyID,
+		RetryCount:  mr.RetryCount,
+		CreatedAt:   mr.CreatedAt,
+		UpdatedAt:   mr.UpdatedAt,
+	}
+}
@@ -0,0 +1,369 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/refinery"
+)
+
+// fakeV1Backend is an in-memory V1Backend for handler tests.
+type fakeV1Backend struct {
+	mail     []V1MailMessage
+	issues   []V1Issue
+	convoys  []V1Convoy
+	sent     []V1MailSendRequest
+	lastIQ   V1IssueQuery
+	queueErr error
+}
+
+func (f *fakeV1Backend) ListMail(address string, unreadOnly bool) ([]V1MailMessage, error) {
+	var out []V1MailMessage
+	for _, m := range f.mail {
+		if m.To == address && (!unreadOnly || !m.Read) {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeV1Backend) GetMail(address, id string) (*V1MailMessage, error) {
+	for _, m := range f.mail {
+		if m.ID == id && m.To == address {
+			return &m, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: message %s", ErrNotFound, id)
+}
+
+func (f *fakeV1Backend) SendMail(req V1MailSendRequest) (string, error) {
+	f.sent = append(f.sent, req)
+	return "hq-msg-1", nil
+}
+
+func (f *fakeV1Backend) ListIssues(q V1IssueQuery) ([]V1Issue, error) {
+	f.lastIQ = q
+	return f.issues, nil
+}
+
+func (f *fakeV1Backend) GetIssue(_, id string) (*V1Issue, error) {
+	for _, i := range f.issues {
+		if i.ID == id {
+			return &i, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: issue %s", ErrNotFound, id)
+}
+
+func (f *fakeV1Backend) ListRigs() ([]V1Rig, error) {
+	return []V1Rig{{Name: "gastown"}}, nil
+}
+
+func (f *fakeV1Backend) ListMergeQueue(rig string) ([]V1MergeRequest, error) {
+	if f.queueErr != nil {
+		return nil, f.queueErr
+	}
+	return nil, nil
+}
+
+func (f *fakeV1Backend) ListPolecats(rig string) ([]V1Polecat, error) {
+	return []V1Polecat{{Name: "Toast", Rig: rig, State: "working"}}, nil
+}
+
+func (f *fakeV1Backend) ListConvoys(string) ([]V1Convoy, error) {
+	return f.convoys, nil
+}
+
+func (f *fakeV1Backend) GetConvoy(id string) (*V1Convoy, error) {
+	for _, c := range f.convoys {
+		if c.ID == id {
+			return &c, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: convoy %s", ErrNotFound, id)
+}
+
+func newTestV1Handler() (*APIv1Handler, *fakeV1Backend) {
+	backend := &fakeV1Backend{
+		mail: []V1MailMessage{
+			{ID: "m1", From: "gastown/Toast", To: "mayor/", Subject: "done", Read: true, Priority: "normal", Type: "notification"},
+			{ID: "m2", From: "gastown/Nux", To: "mayor/", Subject: "help", Body: "stuck", Priority: "high", Type: "task"},
+		},
+		issues: []V1Issue{
+			{ID: "gt-1", Title: "Fix it", Status: "open", Type: "task", Labels: []string{}},
+		},
+		convoys: []V1Convoy{
+			{ID: "hq-cv-1", Title: "Auth", Status: "open", Total: 2, Completed: 1},
+		},
+	}
+	return NewAPIv1Handler(backend, "test-token"), backend
+}
+
+func doV1(t *testing.T, h http.Handler, method, path string, body []byte, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	if token != "" {
+		req.Header.Set("X-Dashboard-Token", token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAPIv1_MailList(t *testing.T) {
+	h, _ := newTestV1Handler()
+
+	rec := doV1(t, h, http.MethodGet, "/api/v1/mail?address=mayor/", nil, "test-token")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("X-API-Version"); got != APIVersion {
+		t.Errorf("X-API-Version = %q, want %q", got, APIVersion)
+	}
+	var resp V1MailList
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Total != 2 || resp.UnreadCount != 1 {
+		t.Errorf("total = %d, unread = %d; want 2, 1", resp.Total, resp.UnreadCount)
+	}
+
+	rec = doV1(t, h, http.MethodGet, "/api/v1/mail?address=nobody/", nil, "test-token")
+	if !strings.Contains(rec.Body.String(), `"messages":[]`) {
+		t.Errorf("empty mailbox should encode messages as [], got %s", rec.Body.String())
+	}
+
+	rec = doV1(t, h, http.MethodGet, "/api/v1/mail", nil, "test-token")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("missing address: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestAPIv1_MailGet(t *testing.T) {
+	h, _ := newTestV1Handler()
+
+	rec := doV1(t, h, http.MethodGet, "/api/v1/mail/m2?address=mayor/", nil, "test-token")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var msg V1MailMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &msg); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if msg.Body != "stuck" || msg.Priority != "high" {
+		t.Errorf("message = %+v", msg)
+	}
+
+	rec = doV1(t, h, http.MethodGet, "/api/v1/mail/nope?address=mayor/", nil, "test-token")
+	assertV1Error(t, rec, http.StatusNotFound)
+}
+
+func TestAPIv1_MailRequiresTokenAndSameOrigin(t *testing.T) {
+	h, _ := newTestV1Handler()
+
+	for _, path := range []string{"/api/v1/mail?address=mayor/", "/api/v1/mail/m2?address=mayor/"} {
+		assertV1Error(t, doV1(t, h, http.MethodGet, path, nil, ""), http.StatusForbidden)
+		assertV1Error(t, doV1(t, h, http.MethodGet, path, nil, "wrong"), http.StatusForbidden)
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   int
+	}{
+		{"same origin", "Origin", "http://example.com", http.StatusOK},
+		{"foreign origin", "Origin", "http://evil.test", http.StatusForbidden},
+		{"cross-site fetch", "Sec-Fetch-Site", "cross-site", http.StatusForbidden},
+		{"same-origin fetch", "Sec-Fetch-Site", "same-origin", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/mail?address=mayor/", nil)
+			req.Header.Set("X-Dashboard-Token", "test-token")
+			req.Header.Set(tt.header, tt.value)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+
+}
+
+func TestAPIv1_AuthCoversEveryRoute(t *testing.T) {
+	h, _ := newTestV1Handler()
+	for _, path := range []string{"/api/v1/openapi.json", "/api/v1/rigs", "/api/v1/issues/gt-1", "/api/v1/convoys", "/api/v1/nope"} {
+		assertV1Error(t, doV1(t, h, http.MethodGet, path, nil, ""), http.StatusForbidden)
+
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Dashboard-Token", "test-token")
+		req.Header.Set("Sec-Fetch-Site", "cross-site")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		assertV1Error(t, rec, http.StatusForbidden)
+	}
+	if rec := doV1(t, h, http.MethodGet, "/api/v1/rigs", nil, "test-token"); rec.Code != http.StatusOK {
+		t.Errorf("GET /api/v1/rigs with token: status = %d, want 200", rec.Code)
+	}
+}
+
+func TestAPIv1_MailSend(t *testing.T) {
+	h, backend := newTestV1Handler()
+	body := []byte(`{"from":"overseer","to":"mayor/","subject":"hi","body":"hello"}`)
+
+	rec := doV1(t, h, http.MethodPost, "/api/v1/mail", body, "")
+	assertV1Error(t, rec, http.StatusForbidden)
+
+	rec = doV1(t, h, http.MethodPost, "/api/v1/mail", body, "test-token")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if len(backend.sent) != 1 || backend.sent[0].Subject != "hi" {
+		t.Errorf("sent = %+v", backend.sent)
+	}
+
+	rec = doV1(t, h, http.MethodPost, "/api/v1/mail", []byte(`{"to":"mayor/"}`), "test-token")
+	assertV1Error(t, rec, http.StatusBadRequest)
+}
+
+func TestAPIv1_Issues(t *testing.T) {
+	h, backend := newTestV1Handler()
+
+	rec := doV1(t, h, http.MethodGet, "/api/v1/issues?rig=gastown&status=all&label=gt:task&limit=5", nil, "test-token")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	want := V1IssueQuery{Rig: "gastown", Status: "all", Label: "gt:task", Limit: 5}
+	if backend.lastIQ != want {
+		t.Errorf("query = %+v, want %+v", backend.lastIQ, want)
+	}
+
+	rec = doV1(t, h, http.MethodGet, "/api/v1/issues?limit=-1", nil, "test-token")
+	assertV1Error(t, rec, http.StatusBadRequest)
+
+	rec = doV1(t, h, http.MethodGet, "/api/v1/issues/gt-1", nil, "test-token")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"labels":[]`) {
+		t.Errorf("issue show: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	rec = doV1(t, h, http.MethodGet, "/api/v1/issues/gt-missing", nil, "test-token")
+	assertV1Error(t, rec, http.StatusNotFound)
+}
+
+func TestAPIv1_RigsAndConvoys(t *testing.T) {
+	h, backend := newTestV1Handler()
+
+	rec := doV1(t, h, http.MethodGet, "/api/v1/rigs/gastown/merge-queue", nil, "test-token")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"merge_requests":[]`) {
+		t.Errorf("merge queue: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	backend.queueErr = fmt.Errorf("%w: rig %q", ErrNotFound, "nope")
+	rec = doV1(t, h, http.MethodGet, "/api/v1/rigs/nope/merge-queue", nil, "test-token")
+	assertV1Error(t, rec, http.StatusNotFound)
+
+	rec = doV1(t, h, http.MethodGet, "/api/v1/rigs/gastown/polecats", nil, "test-token")
+	var polecats V1PolecatList
+	if err := json.Unmarshal(rec.Body.Bytes(), &polecats); err != nil || len(polecats.Polecats) != 1 {
+		t.Errorf("polecats = %s (err %v)", rec.Body.String(), err)
+	}
+
+	rec = doV1(t, h, http.MethodGet, "/api/v1/convoys/hq-cv-1", nil, "test-token")
+	var convoy V1Convoy
+	if err := json.Unmarshal(rec.Body.Bytes(), &convoy); err != nil || convoy.Completed != 1 {
+		t.Errorf("convoy = %s (err %v)", rec.Body.String(), err)
+	}
+}
+
+func TestAPIv1_UnknownRoute(t *testing.T) {
+	h, _ := newTestV1Handler()
+	assertV1Error(t, doV1(t, h, http.MethodGet, "/api/v1/nope", nil, "test-token"), http.StatusNotFound)
+	assertV1Error(t, doV1(t, h, http.MethodDelete, "/api/v1/mail", nil, "test-token"), http.StatusNotFound)
+}
+
+// TestAPIv1_OpenAPICoversRoutes keeps the OpenAPI document in sync with the
+// routes registered in NewAPIv1Handler.
+func TestAPIv1_OpenAPICoversRoutes(t *testing.T) {
+	h, _ := newTestV1Handler()
+	rec := doV1(t, h, http.MethodGet, "/api/v1/openapi.json", nil, "test-token")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+
+	var doc struct {
+		OpenAPI string                                `json:"openapi"`
+		Info    struct{ Version string }              `json:"info"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	}
+	if doc.Info.Version != APIVersion {
+		t.Errorf("info.version = %q, want %q", doc.Info.Version, APIVersion)
+	}
+
+	routes := []struct{ method, path string }{
+		{"get", "/openapi.json"},
+		{"get", "/mail"},
+		{"post", "/mail"},
+		{"get", "/mail/{id}"},
+		{"get", "/issues"},
+		{"get", "/issues/{id}"},
+		{"get", "/rigs"},
+		{"get", "/rigs/{rig}/merge-queue"},
+		{"get", "/rigs/{rig}/polecats"},
+		{"get", "/convoys"},
+		{"get", "/convoys/{id}"},
+	}
+	for _, r := range routes {
+		if _, ok := doc.Paths[r.path][r.method]; !ok {
+			t.Errorf("openapi.json missing %s %s", strings.ToUpper(r.method), r.path)
+		}
+		// Every documented route must be served.
+		concrete := strings.NewReplacer("{id}", "x", "{rig}", "gastown").Replace(r.path)
+		req := httptest.NewRequest(strings.ToUpper(r.method), "/api/v1"+concrete, nil)
+		if _, pattern := h.mux.Handler(req); pattern == "" {
+			t.Errorf("no handler registered for %s /api/v1%s", strings.ToUpper(r.method), r.path)
+		}
+	}
+	if len(doc.Paths) != 10 {
+		t.Errorf("openapi.json documents %d paths; update this test when adding routes", len(doc.Paths))
+	}
+}
+
+func TestToV1MergeRequest_State(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		mr   refinery.MRInfo
+		want string
+	}{
+		{refinery.MRInfo{ID: "a", CreatedAt: now}, "ready"},
+		{refinery.MRInfo{ID: "b", Assignee: "gastown/refinery"}, "claimed"},
+		{refinery.MRInfo{ID: "c", Assignee: "gastown/refinery", BlockedBy: "gt-9"}, "blocked"},
+	}
+	for _, tt := range tests {
+		if got := toV1MergeRequest(&tt.mr).State; got != tt.want {
+			t.Errorf("toV1MergeRequest(%s).State = %q, want %q", tt.mr.ID, got, tt.want)
+		}
+	}
+}
+
+func assertV1Error(t *testing.T, rec *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if rec.Code != status {
+		t.Errorf("status = %d, want %d (body %s)", rec.Code, status, rec.Body.String())
+		return
+	}
+	var e V1Error
+	if err := json.Unmarshal(rec.Body.Bytes(), &e); err != nil || e.Status != status || e.Error == "" {
+		t.Errorf("error envelope = %s (err %v)", rec.Body.String(), err)
+	}
+}
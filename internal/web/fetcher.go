@@ -1807,7 +1807,7 @@ func eventCategory(eventType string) string {
 	switch eventType {
 	case "spawn", "kill", "session_start", "session_end", "session_death", "mass_death", "nudge", "handoff":
 		return "agent"
-	case "sling", "hook", "unhook", "done", "merge_started", "merged", "merge_failed":
+	case "sling", "hook", "unhook", "done", "merge_started", "merged", "merge_failed", "convoy_closed":
 		return "work"
 	case "mail", "escalation_sent", "escalation_acked", "escalation_closed":
 		return "comms"
@@ -1854,6 +1854,7 @@ func eventIcon(eventType string) string {
 		"merge_started":     "🔀",
 		"merged":            "✨",
 		"merge_failed":      "❌",
+		"convoy_closed":     "🚚",
 		"boot":              "🚀",
 		"halt":              "🛑",
 	}
@@ -1895,6 +1896,9 @@ func eventSummary(eventType, actor string, payload map[string]interface{}) strin
 	case "merged":
 		branch, _ := payload["branch"].(string)
 		return fmt.Sprintf("merged %s", branch)
+	case "convoy_closed":
+		convoyID, _ := payload["convoy"].(string)
+		return fmt.Sprintf("convoy %s landed", convoyID)
 	case "merge_failed":
 		reason, _ := payload["reason"].(string)
 		if len(reason) > 30 {
@@ -480,7 +480,7 @@ func TestNewAPIHandler_StoresTimeouts(t *testing.T) {
 
 func TestNewDashboardMux_NilConfig(t *testing.T) {
 	mock := &MockConvoyFetcher{}
-	mux, err := NewDashboardMux(mock, nil)
+	mux, err := NewDashboardMux(mock, "", nil)
 	if err != nil {
 		t.Fatalf("NewDashboardMux(nil config): %v", err)
 	}
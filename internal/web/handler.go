@@ -455,8 +455,9 @@ func generateCSRFToken() string {
 }
 
 // NewDashboardMux creates an HTTP handler that serves both the dashboard and API.
-// webCfg may be nil, in which case defaults are used.
-func NewDashboardMux(fetcher ConvoyFetcher, webCfg *config.WebTimeoutsConfig) (http.Handler, error) {
+// townRoot is the workspace the typed v1 API reads from; if empty, only the
+// legacy endpoints are served. webCfg may be nil, in which case defaults are used.
+func NewDashboardMux(fetcher ConvoyFetcher, townRoot string, webCfg *config.WebTimeoutsConfig) (http.Handler, error) {
 	if webCfg == nil {
 		webCfg = config.DefaultWebTimeoutsConfig()
 	}
@@ -481,6 +482,11 @@ func NewDashboardMux(fetcher ConvoyFetcher, webCfg *config.WebTimeoutsConfig) (h
 	staticHandler := http.FileServer(http.FS(staticFS))
 
 	mux := http.NewServeMux()
+	// The typed v1 API calls Gas Town packages in-process, so it needs a
+	// town root; without one only the legacy endpoints are served.
+	if townRoot != "" {
+		mux.Handle("/api/v1/", NewAPIv1Handler(NewLiveV1Backend(townRoot), csrfToken))
+	}
 	mux.Handle("/api/", apiHandler)
 	mux.Handle("/static/", http.StripPrefix("/static/", staticHandler))
 	mux.Handle("/", convoyHandler)
@@ -3134,7 +3134,7 @@
         fetch('/api/run', {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
-            body: JSON.stringify({ command: 'convoy status ' + convoyId + ' --json' })
+            body: JSON.stringify({ command: 'convoy status ' + convoyId + ' --json --forecast' })
         })
         .then(function(r) { return r.json(); })
         .then(function(data) {
@@ -3162,6 +3162,12 @@
             return;
         }
 
+        var forecast = data.forecast || null;
+        var forecastBeads = {};
+        if (forecast && forecast.beads) {
+            forecast.beads.forEach(function(b) { forecastBeads[b.id] = b; });
+        }
+
         var html = '<div class="tracked-issues">';
         html += '<table class="tracked-issues-table">';
         html += '<thead><tr><th>Status</th><th>ID</th><th>Title</th><th>Assignee</th><th>Progress</th></tr></thead>';
@@ -3205,9 +3211,19 @@
                 }
             }
 
+            // Forecast: critical path marker and remaining time
+            var fb = forecastBeads[issue.id];
+            var critical = '';
+            if (fb && fb.critical) {
+                critical = ' <span class="badge badge-yellow" title="On the critical path: delaying it delays the convoy">★ critical</span>';
+            }
+            if (fb && issue.status !== 'closed' && fb.remaining_minutes > 0) {
+                progress += ' <span class="convoy-progress-age" title="Median remaining time from similar beads (' + escapeHtml(fb.basis || '') + ')">~' + formatForecastMinutes(fb.remaining_minutes) + ' left</span>';
+            }
+
             html += '<tr class="tracked-issue-row tracked-issue-' + escapeHtml(issue.status) + '">' +
                 '<td>' + statusBadge + '</td>' +
-                '<td><span class="issue-id">' + escapeHtml(issue.id) + '</span></td>' +
+                '<td><span class="issue-id">' + escapeHtml(issue.id) + '</span>' + critical + '</td>' +
                 '<td class="tracked-issue-title">' + escapeHtml(issue.title) + '</td>' +
                 '<td class="tracked-issue-assignee">' + escapeHtml(assignee) + '</td>' +
                 '<td class="tracked-issue-progress">' + progress + '</td>' +
@@ -3225,8 +3241,50 @@
         html += '<span class="tracked-issues-progress-text">' + completed + '/' + total + ' completed (' + pct + '%)</span>';
         html += '</div>';
 
+        if (forecast) {
+            html += renderConvoyForecast(forecast);
+        }
+
         html += '</div>';
         cell.innerHTML = html;
     }
 
+    // Render the ETA, per-wave forecast and critical path from
+    // `gt convoy status --forecast --json`.
+    function renderConvoyForecast(forecast) {
+        var now = Date.now();
+        var html = '<div class="tracked-issues-summary convoy-forecast">';
+        html += '<span class="tracked-issues-progress-text"><strong>ETA:</strong> ' + escapeHtml(formatForecastTime(forecast.finish, now)) +
+            ' <span class="convoy-progress-age">(80%: ' + escapeHtml(formatForecastTime(forecast.finish_low, now)) +
+            ' – ' + escapeHtml(formatForecastTime(forecast.finish_high, now)) + ')</span></span>';
+        if (forecast.critical_path && forecast.critical_path.length > 0) {
+            html += '<div><strong>Critical path:</strong> ' + forecast.critical_path.map(escapeHtml).join(' → ') + '</div>';
+        }
+        (forecast.waves || []).forEach(function(w) {
+            html += '<div>Wave ' + w.number + ' (' + w.tasks.length + ' beads): ' + escapeHtml(formatForecastTime(w.finish, now)) +
+                ' <span class="convoy-progress-age">(80%: ' + escapeHtml(formatForecastTime(w.finish_low, now)) +
+                ' – ' + escapeHtml(formatForecastTime(w.finish_high, now)) + ')</span></div>';
+        });
+        if (!forecast.samples) {
+            html += '<div class="convoy-progress-age">No finished beads with work events yet; using default estimates.</div>';
+        }
+        html += '</div>';
+        return html;
+    }
+
+    function formatForecastTime(iso, now) {
+        var t = new Date(iso).getTime();
+        if (isNaN(t)) return '?';
+        var mins = Math.round((t - now) / 60000);
+        if (mins <= 0) return 'done';
+        return 'in ' + formatForecastMinutes(mins);
+    }
+
+    function formatForecastMinutes(mins) {
+        if (mins < 60) return mins + 'm';
+        var h = Math.floor(mins / 60);
+        if (h < 24) return h + 'h' + (mins % 60 ? ' ' + (mins % 60) + 'm' : '');
+        return Math.floor(h / 24) + 'd ' + (h % 24) + 'h';
+    }
+
 })();
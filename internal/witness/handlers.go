@@ -22,7 +22,6 @@ import (
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/session"
-	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/util"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -216,7 +215,7 @@ func HandlePolecatDoneFromBead(bd *BdCli, workDir, rigName, polecatName string,
 			mayorMsg := fmt.Sprintf("PUSH_FAILED: polecat=%s branch=%s issue=%s — branch not on origin, possible work loss",
 				polecatName, payload.Branch, payload.IssueID)
 			mayorSession := session.MayorSessionName()
-			t := tmux.NewTmux()
+			t := session.NewBackend(townRoot)
 			if running, err := t.HasSession(mayorSession); err == nil && running {
 				_ = t.NudgeSession(mayorSession, mayorMsg)
 			}
@@ -456,7 +455,7 @@ func HandleMergeFailed(workDir, rigName string, msg *mail.Message, router *mail.
 	sessionName := session.PolecatSessionName(session.PrefixFor(rigName), payload.PolecatName)
 	nudgeMsg := fmt.Sprintf("MERGE_FAILED: branch=%s issue=%s type=%s error=%s — fix and resubmit with 'gt done'",
 		payload.Branch, payload.IssueID, payload.FailureType, payload.Error)
-	t := tmux.NewTmux()
+	t := session.NewBackend(workDirToTownRoot(workDir))
 	if err := t.NudgeSession(sessionName, nudgeMsg); err != nil {
 		result.Error = fmt.Errorf("nudging polecat about failure: %w", err)
 		return result
@@ -678,7 +677,7 @@ func nudgeRefinery(townRoot, rigName string) error {
 	sessionName := session.RefinerySessionName(session.PrefixFor(rigName))
 
 	// Check if refinery is running
-	t := tmux.NewTmux()
+	t := session.NewBackend(townRoot)
 	running, err := t.HasSession(sessionName)
 	if err != nil {
 		return fmt.Errorf("checking refinery session: %w", err)
@@ -718,7 +717,7 @@ func notifyMayorSlotOpen(workDir, rigName, polecatName, exitType string) {
 
 	// Try nudge first — lightweight, no Dolt commit.
 	mayorSession := session.MayorSessionName()
-	t := tmux.NewTmux()
+	t := session.NewBackend(townRoot)
 	if running, err := t.HasSession(mayorSession); err == nil && running {
 		msg := fmt.Sprintf("SLOT_OPEN: %s/%s completed (exit=%s) — slot available. Run `gt polecat list` to verify and sling next bead.", rigName, polecatName, exitType)
 		if err := t.NudgeSession(mayorSession, msg); err == nil {
@@ -755,7 +754,7 @@ func EscalateRecoveryNeeded(workDir, rigName string, payload *RecoveryPayload) (
 	sessionName := session.DeaconSessionName()
 	nudgeMsg := fmt.Sprintf("RECOVERY_NEEDED: %s/%s cleanup_status=%s branch=%s issue=%s detected=%s — coordinate recovery before authorizing cleanup",
 		rigName, payload.PolecatName, payload.CleanupStatus, payload.Branch, payload.IssueID, payload.DetectedAt.Format(time.RFC3339))
-	t := tmux.NewTmux()
+	t := session.NewBackend(workDirToTownRoot(workDir))
 	if err := t.NudgeSession(sessionName, nudgeMsg); err != nil {
 		return "", fmt.Errorf("nudging deacon about recovery: %w", err)
 	}
@@ -849,7 +848,7 @@ func NukePolecat(bd *BdCli, workDir, rigName, polecatName string) error {
 	// session due to rig loading issues or race conditions with IsRunning checks.
 	// See: gt-g9ft5 - sessions were piling up because nuke wasn't killing them.
 	sessionName := session.PolecatSessionName(session.PrefixFor(rigName), polecatName)
-	t := tmux.NewTmux()
+	t := session.NewBackend(townRoot)
 
 	// Check if session exists and kill it
 	if running, _ := t.HasSession(sessionName); running {
@@ -1074,7 +1073,7 @@ func DetectZombiePolecats(bd *BdCli, workDir, rigName string, router *mail.Route
 		return result
 	}
 
-	t := tmux.NewTmux()
+	t := session.NewBackend(townRoot)
 
 	for _, entry := range entries {
 		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
@@ -1159,7 +1158,7 @@ func DetectZombiePolecats(bd *BdCli, workDir, rigName string, router *mail.Route
 //
 // gt-dsgp: Uses restart-first policy. Instead of nuking polecats, restarts their
 // sessions to preserve worktrees and branches.
-func detectZombieLiveSession(bd *BdCli, workDir, townRoot, rigName, polecatName, sessionName string, t *tmux.Tmux, doneIntent *DoneIntent, witCfg *config.WitnessThresholds, snap *agentBeadSnapshot) (ZombieResult, bool) {
+func detectZombieLiveSession(bd *BdCli, workDir, townRoot, rigName, polecatName, sessionName string, t session.SessionBackend, doneIntent *DoneIntent, witCfg *config.WitnessThresholds, snap *agentBeadSnapshot) (ZombieResult, bool) {
 	// gt-2gra: Agent state and hook bead are read from the pre-fetched snapshot
 	// instead of calling getAgentBeadState multiple times per code path.
 	snapState, snapHook := "", ""
@@ -1279,7 +1278,7 @@ func detectZombieLiveSession(bd *BdCli, workDir, townRoot, rigName, polecatName,
 //
 // gt-dsgp: Uses restart-first policy. Instead of nuking polecats with dead sessions,
 // restarts them to preserve worktrees and branches.
-func detectZombieDeadSession(bd *BdCli, workDir, townRoot, rigName, polecatName, sessionName string, t *tmux.Tmux, doneIntent *DoneIntent, detectedAt time.Time, witCfg *config.WitnessThresholds, snap *agentBeadSnapshot) (ZombieResult, bool) {
+func detectZombieDeadSession(bd *BdCli, workDir, townRoot, rigName, polecatName, sessionName string, t session.SessionBackend, doneIntent *DoneIntent, detectedAt time.Time, witCfg *config.WitnessThresholds, snap *agentBeadSnapshot) (ZombieResult, bool) {
 	// gt-2gra: Agent state and hook bead are read from the pre-fetched snapshot.
 	snapState, snapHook := "", ""
 	snapActiveMR := ""
@@ -1574,7 +1573,7 @@ func DetectStalledPolecats(workDir, rigName string) *DetectStalledPolecatsResult
 		return result // No polecats directory
 	}
 
-	t := tmux.NewTmux()
+	t := session.NewBackend(workDirToTownRoot(workDir))
 	now := time.Now()
 
 	for _, entry := range entries {
@@ -1784,7 +1783,7 @@ func processDiscoveredCompletion(bd *BdCli, workDir, rigName string, payload *Po
 			mayorMsg := fmt.Sprintf("PUSH_FAILED: polecat=%s branch=%s issue=%s — branch not on origin, possible work loss",
 				payload.PolecatName, payload.Branch, payload.IssueID)
 			mayorSession := session.MayorSessionName()
-			t := tmux.NewTmux()
+			t := session.NewBackend(townRoot)
 			if running, err := t.HasSession(mayorSession); err == nil && running {
 				_ = t.NudgeSession(mayorSession, mayorMsg)
 			}
@@ -2085,7 +2084,7 @@ then either close the bead or reset the respawn counter.`,
 			if err := router.Send(msg); err != nil {
 				fmt.Fprintf(os.Stderr, "witness: failed to send SPAWN_BLOCKED mail for %s: %v, attempting nudge fallback\n", hookBead, err)
 				// Nudge mayor as fallback — nudges are more reliable than mail
-				t := tmux.NewTmux()
+				t := session.NewBackend(workDirToTownRoot(workDir))
 				nudgeMsg := fmt.Sprintf("SPAWN_BLOCKED %s (respawn limit reached) from %s/%s — mail send failed, investigate spawn storm",
 					hookBead, rigName, polecatName)
 				if nudgeErr := t.NudgeSession(session.MayorSessionName(), nudgeMsg); nudgeErr != nil {
@@ -2136,7 +2135,7 @@ Please re-dispatch to an available polecat.`,
 		if err := router.Send(msg); err != nil {
 			fmt.Fprintf(os.Stderr, "witness: failed to send RECOVERED_BEAD mail for %s: %v, attempting nudge fallback\n", hookBead, err)
 			// Nudge deacon as fallback — nudges are more reliable than mail
-			t := tmux.NewTmux()
+			t := session.NewBackend(workDirToTownRoot(workDir))
 			nudgeMsg := fmt.Sprintf("RECOVERED_BEAD %s from %s/%s (status=%s, respawns=%d) — mail send failed, please re-dispatch",
 				hookBead, rigName, polecatName, status, respawnCount)
 			if nudgeErr := t.NudgeSession(session.DeaconSessionName(), nudgeMsg); nudgeErr != nil {
@@ -2204,7 +2203,7 @@ func DetectOrphanedBeads(bd *BdCli, workDir, rigName string, router *mail.Router
 		beadList = append(beadList, batch...)
 	}
 
-	t := tmux.NewTmux()
+	t := session.NewBackend(townRoot)
 
 	for _, bead := range beadList {
 		if bead.Assignee == "" {
@@ -2346,7 +2345,7 @@ func DetectOrphanedMolecules(bd *BdCli, workDir, rigName string, router *mail.Ro
 
 	// Step 2: Check each polecat-assigned bead
 	polecatPrefix := rigName + "/polecats/"
-	t := tmux.NewTmux()
+	t := session.NewBackend(townRoot)
 	polecatsDir := filepath.Join(townRoot, rigName, "polecats")
 
 	for _, b := range allBeads {
@@ -2577,22 +2576,22 @@ func getAgentBeadLabels(bd *BdCli, workDir, agentBeadID string) []string {
 	return issues[0].Labels
 }
 
-// sessionRecreated checks whether a tmux session was (re)created after the
+// sessionRecreated checks whether a session was (re)created after the
 // given timestamp. Returns true if the session exists and was created after
 // detectedAt, indicating a new session replaced the dead one (TOCTOU guard).
-func sessionRecreated(t *tmux.Tmux, sessionName string, detectedAt time.Time) bool {
+func sessionRecreated(t session.SessionBackend, sessionName string, detectedAt time.Time) bool {
 	alive, err := t.HasSession(sessionName)
 	if err != nil || !alive {
 		return false // Still dead — not recreated
 	}
 	// Session exists now. Check if it was created after our detection.
-	createdAt, err := session.SessionCreatedAt(sessionName)
+	createdUnix, err := t.GetSessionCreatedUnix(sessionName)
 	if err != nil {
 		// Can't determine creation time — assume recreated to be safe.
 		// Better to skip a real zombie than kill a live session.
 		return true
 	}
-	return !createdAt.Before(detectedAt)
+	return !time.Unix(createdUnix, 0).Before(detectedAt)
 }
 
 // findAnyCleanupWisp checks if any cleanup wisp already exists for a polecat,
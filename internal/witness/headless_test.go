@@ -0,0 +1,182 @@
+//go:build linux
+
+package witness
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/ptyhost"
+	"github.com/steveyegge/gastown/internal/session"
+)
+
+// startHeadlessTown configures a town for the headless session backend and
+// serves a PTY host on its socket, as the daemon would.
+func startHeadlessTown(t *testing.T, thresholds *config.WitnessThresholds) (string, *ptyhost.Host) {
+	t.Helper()
+	// Unix socket paths are length-limited, so avoid t.TempDir's long names.
+	townRoot, err := os.MkdirTemp("", "wit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(townRoot) })
+
+	settings := config.NewTownSettings()
+	settings.SessionBackend = config.SessionBackendHeadless
+	settings.Operational = &config.OperationalConfig{Witness: thresholds}
+	if err := config.SaveTownSettings(config.TownSettingsPath(townRoot), settings); err != nil {
+		t.Fatal(err)
+	}
+
+	h := ptyhost.NewHost()
+	t.Cleanup(h.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	socket := ptyhost.SocketPath(townRoot)
+	go func() { _ = ptyhost.Serve(ctx, h, socket) }()
+
+	c := ptyhost.NewClient(socket)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		// ListSessions reports no sessions, not an error, until the host is up.
+		if _, statErr := os.Stat(socket); statErr == nil {
+			if _, err := c.ListSessions(); err == nil {
+				break
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for ptyhost socket")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return townRoot, h
+}
+
+func TestDetectStalledPolecats_HeadlessBackend(t *testing.T) {
+	townRoot, h := startHeadlessTown(t, &config.WitnessThresholds{
+		StartupStallThreshold: "1ms",
+		StartupActivityGrace:  "1ms",
+	})
+	rigName := "testrig"
+	for _, name := range []string{"alpha", "bravo"} {
+		if err := os.MkdirAll(filepath.Join(townRoot, rigName, "polecats", name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	initRegistryFromTownRoot(townRoot)
+
+	// alpha runs on the headless host and never produces output; bravo has
+	// no session at all.
+	alpha := session.PolecatSessionName(session.PrefixFor(rigName), "alpha")
+	if err := h.NewSession(alpha, townRoot, "sleep 30"); err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	time.Sleep(1100 * time.Millisecond) // session age has one-second resolution
+
+	result := DetectStalledPolecats(townRoot, rigName)
+	if result.Checked != 2 {
+		t.Errorf("Checked = %d, want 2", result.Checked)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("Errors = %v, want none", result.Errors)
+	}
+	if len(result.Stalled) != 1 || result.Stalled[0].PolecatName != "alpha" {
+		t.Fatalf("Stalled = %+v, want alpha only", result.Stalled)
+	}
+	if result.Stalled[0].Action != "auto-dismissed" {
+		t.Errorf("Action = %q, want auto-dismissed (err %v)", result.Stalled[0].Action, result.Stalled[0].Error)
+	}
+
+	// Once the session is gone the witness must see it as dead, not stalled.
+	if err := h.KillSession(alpha); err != nil {
+		t.Fatal(err)
+	}
+	result = DetectStalledPolecats(townRoot, rigName)
+	if len(result.Stalled) != 0 || len(result.Errors) != 0 {
+		t.Errorf("after kill: Stalled = %+v, Errors = %v", result.Stalled, result.Errors)
+	}
+}
+
+func TestSessionRecreated_HeadlessBackend(t *testing.T) {
+	townRoot, h := startHeadlessTown(t, nil)
+	backend := session.NewBackend(townRoot)
+	if _, ok := backend.(*ptyhost.Client); !ok {
+		t.Fatalf("NewBackend = %T, want *ptyhost.Client", backend)
+	}
+
+	detectedAt := time.Now().Add(-time.Hour)
+	if sessionRecreated(backend, "gt-testrig-alpha", detectedAt) {
+		t.Error("missing session reported as recreated")
+	}
+	if err := h.NewSession("gt-testrig-alpha", townRoot, "sleep 30"); err != nil {
+		t.Fatal(err)
+	}
+	if !sessionRecreated(backend, "gt-testrig-alpha", detectedAt) {
+		t.Error("session created after detection not reported as recreated")
+	}
+	if sessionRecreated(backend, "gt-testrig-alpha", time.Now().Add(time.Hour)) {
+		t.Error("session created before detection reported as recreated")
+	}
+}
+
+func TestDetectThrashingPolecats_HeadlessBackend(t *testing.T) {
+	townRoot, h := startHeadlessTown(t, nil)
+	rigName := "testrig"
+	alphaDir := filepath.Join(townRoot, rigName, "polecats", "alpha")
+	bravoDir := filepath.Join(townRoot, rigName, "polecats", "bravo")
+	for _, dir := range []string{alphaDir, bravoDir} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	initRegistryFromTownRoot(townRoot)
+
+	// alpha is a Claude agent rerunning the same failing test; bravo runs
+	// Gemini, whose log cannot be read incrementally.
+	alpha := session.PolecatSessionName(session.PrefixFor(rigName), "alpha")
+	bravo := session.PolecatSessionName(session.PrefixFor(rigName), "bravo")
+	if err := h.NewSession(alpha, alphaDir, "sleep 30"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.NewSession(bravo, bravoDir, "sleep 30"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.SetEnv(bravo, "GT_AGENT", "gemini"); err != nil {
+		t.Fatal(err)
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	projectDir := filepath.Join(home, ".claude", "projects", strings.ReplaceAll(alphaDir, "/", "-"))
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	var transcript strings.Builder
+	at := time.Now().Add(time.Second).UTC().Format(time.RFC3339)
+	for i := 0; i < 8; i++ {
+		transcript.WriteString(`{"type":"assistant","timestamp":"` + at + `","message":{"role":"assistant","content":[{"type":"tool_use","name":"Bash","input":{"command":"go test ./foo/"}}]}}` + "\n")
+		transcript.WriteString(`{"type":"user","timestamp":"` + at + `","message":{"role":"user","content":[{"type":"tool_result","content":"--- FAIL: TestFoo (0.01s)"}]}}` + "\n")
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, "session.jsonl"), []byte(transcript.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := DetectThrashingPolecats(townRoot, rigName)
+	if result.Checked != 2 {
+		t.Errorf("Checked = %d, want 2", result.Checked)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("Errors = %v, want none", result.Errors)
+	}
+	if len(result.Thrashing) != 1 || result.Thrashing[0].PolecatName != "alpha" {
+		t.Fatalf("Thrashing = %+v, want alpha only", result.Thrashing)
+	}
+	if th := result.Thrashing[0]; th.Action != "nudged" || th.Error != nil {
+		t.Errorf("alpha: Action = %q, Error = %v, want nudged", th.Action, th.Error)
+	}
+}
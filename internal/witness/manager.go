@@ -48,7 +48,7 @@ func NewManager(r *rig.Rig) *Manager {
 // ZFC: tmux session existence is the source of truth for session state,
 // but agent liveness determines if the session is actually functional.
 func (m *Manager) IsRunning() (bool, error) {
-	t := tmux.NewTmux()
+	t := m.sessions()
 	status := t.CheckSessionHealth(m.SessionName(), 0)
 	return status == tmux.SessionHealthy, nil
 }
@@ -59,7 +59,7 @@ func (m *Manager) IsRunning() (bool, error) {
 // Returns the detailed ZombieStatus for callers that need to distinguish
 // between different failure modes.
 func (m *Manager) IsHealthy(maxInactivity time.Duration) tmux.ZombieStatus {
-	t := tmux.NewTmux()
+	t := m.sessions()
 	return t.CheckSessionHealth(m.SessionName(), maxInactivity)
 }
 
@@ -71,7 +71,7 @@ func (m *Manager) SessionName() string {
 // Status returns information about the witness session.
 // ZFC-compliant: tmux session is the source of truth.
 func (m *Manager) Status() (*tmux.SessionInfo, error) {
-	t := tmux.NewTmux()
+	t := m.sessions()
 	sessionID := m.SessionName()
 
 	running, err := t.HasSession(sessionID)
@@ -108,7 +108,7 @@ func (m *Manager) witnessDir() string {
 // envOverrides are KEY=VALUE pairs that override all other env var sources.
 // ZFC-compliant: no state file, tmux session is source of truth.
 func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []string) error {
-	t := tmux.NewTmux()
+	t := m.sessions()
 	sessionID := m.SessionName()
 
 	if foreground {
@@ -227,8 +227,10 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 	}
 
 	// Apply Gas Town theming (non-fatal: theming failure doesn't affect operation)
-	theme := tmux.ResolveSessionTheme(townRoot, m.rig.Name, "witness")
-	_ = t.ConfigureGasTownSession(sessionID, theme, m.rig.Name, "witness", "witness")
+	if themer, ok := t.(session.Themer); ok {
+		theme := tmux.ResolveSessionTheme(townRoot, m.rig.Name, "witness")
+		_ = themer.ConfigureGasTownSession(sessionID, theme, m.rig.Name, "witness", "witness")
+	}
 
 	// Wait for Claude to start - fatal if Claude fails to launch
 	if err := t.WaitForCommand(sessionID, constants.SupportedShells, constants.ClaudeStartTimeout); err != nil {
@@ -238,8 +240,10 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 	}
 
 	// Accept startup dialogs (workspace trust + bypass permissions) if they appear.
-	if err := t.AcceptStartupDialogs(sessionID); err != nil {
-		log.Printf("warning: accepting startup dialogs for %s: %v", sessionID, err)
+	if acceptor, ok := t.(session.DialogAcceptor); ok {
+		if err := acceptor.AcceptStartupDialogs(sessionID); err != nil {
+			log.Printf("warning: accepting startup dialogs for %s: %v", sessionID, err)
+		}
 	}
 
 	// Track PID for defense-in-depth orphan cleanup (non-fatal)
@@ -264,7 +268,7 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 
 	// Stream witness's Claude Code JSONL conversation log to VictoriaLogs (opt-in).
 	if os.Getenv("GT_LOG_AGENT_OUTPUT") == "true" && os.Getenv("GT_OTEL_LOGS_URL") != "" {
-		if err := session.ActivateAgentLogging(sessionID, witnessDir, runID); err != nil {
+		if err := session.ActivateAgentLogging(t, sessionID, witnessDir, runID); err != nil {
 			log.Printf("warning: agent log watcher setup failed for %s: %v", sessionID, err)
 		}
 	}
@@ -293,6 +297,11 @@ func (m *Manager) roleConfig() (*beads.RoleConfig, error) {
 	}, nil
 }
 
+// sessions returns the session backend the town is configured to use.
+func (m *Manager) sessions() session.SessionBackend {
+	return session.NewBackend(m.townRoot())
+}
+
 func (m *Manager) townRoot() string {
 	townRoot, err := workspace.Find(m.rig.Path)
 	if err != nil || townRoot == "" {
@@ -368,7 +377,7 @@ func isBuiltinClaudeStartCommand(cmd string) bool {
 // Stop stops the witness.
 // ZFC-compliant: tmux session is the source of truth.
 func (m *Manager) Stop() error {
-	t := tmux.NewTmux()
+	t := m.sessions()
 	sessionID := m.SessionName()
 
 	// Check if tmux session exists
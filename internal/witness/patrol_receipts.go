@@ -8,6 +8,10 @@ type PatrolVerdict string
 const (
 	PatrolVerdictStale  PatrolVerdict = "stale"
 	PatrolVerdictOrphan PatrolVerdict = "orphan"
+
+	// PatrolVerdictThrashing: the agent is alive and busy but looping
+	// (see AnalyzeThrash).
+	PatrolVerdictThrashing PatrolVerdict = "thrashing"
 )
 
 // PatrolReceiptEvidence captures the primary evidence fields for a verdict.
@@ -17,6 +21,7 @@ type PatrolReceiptEvidence struct {
 	HookBead       string               `json:"hook_bead,omitempty"`
 	BeadRecovered  bool                 `json:"bead_recovered"`
 	Error          string               `json:"error,omitempty"`
+	Thrash         []ThrashFinding      `json:"thrash,omitempty"` // Repeated calls/errors/reverts behind a thrashing verdict
 }
 
 // PatrolReceipt is a machine-readable witness patrol verdict with recommended action.
@@ -83,3 +88,39 @@ func BuildPatrolReceipts(rigName string, result *DetectZombiePolecatsResult) []P
 	}
 	return receipts
 }
+
+// BuildThrashReceipt projects a thrash detection into a patrol receipt.
+func BuildThrashReceipt(rigName string, r ThrashResult) PatrolReceipt {
+	action := strings.TrimSpace(r.Action)
+	if action == "" {
+		action = "investigate"
+	}
+
+	receipt := PatrolReceipt{
+		Rig:               rigName,
+		Polecat:           r.PolecatName,
+		Verdict:           PatrolVerdictThrashing,
+		RecommendedAction: action,
+		Evidence: PatrolReceiptEvidence{
+			Thrash: r.Findings,
+		},
+	}
+
+	if r.Error != nil {
+		receipt.Evidence.Error = r.Error.Error()
+	}
+
+	return receipt
+}
+
+// BuildThrashReceipts returns machine-readable patrol verdicts for all thrashing polecats.
+func BuildThrashReceipts(rigName string, result *DetectThrashingPolecatsResult) []PatrolReceipt {
+	if result == nil || len(result.Thrashing) == 0 {
+		return nil
+	}
+	receipts := make([]PatrolReceipt, 0, len(result.Thrashing))
+	for _, r := range result.Thrashing {
+		receipts = append(receipts, BuildThrashReceipt(rigName, r))
+	}
+	return receipts
+}
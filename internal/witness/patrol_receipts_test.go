@@ -181,3 +181,29 @@ func TestBuildPatrolReceipts_DeterministicStaleOrphanOrdering(t *testing.T) {
 		t.Fatalf("second receipt = %+v, want polecat=echo verdict=%q", receipts[1], PatrolVerdictOrphan)
 	}
 }
+
+func TestBuildThrashReceipts(t *testing.T) {
+	t.Parallel()
+	receipts := BuildThrashReceipts("gastown", &DetectThrashingPolecatsResult{
+		Thrashing: []ThrashResult{{
+			PolecatName: "toast",
+			Action:      "escalated",
+			Findings:    []ThrashFinding{{Kind: ThrashToolLoop, Count: 9, Evidence: "Bash: go test ./..."}},
+			Error:       errors.New("escalate failed"),
+		}},
+	})
+
+	if len(receipts) != 1 {
+		t.Fatalf("got %d receipts, want 1", len(receipts))
+	}
+	r := receipts[0]
+	if r.Verdict != PatrolVerdictThrashing || r.RecommendedAction != "escalated" || r.Polecat != "toast" {
+		t.Fatalf("receipt = %+v", r)
+	}
+	if len(r.Evidence.Thrash) != 1 || r.Evidence.Error != "escalate failed" {
+		t.Fatalf("Evidence = %+v", r.Evidence)
+	}
+	if BuildThrashReceipts("gastown", nil) != nil {
+		t.Fatal("nil result should yield no receipts")
+	}
+}
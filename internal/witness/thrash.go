@@ -0,0 +1,554 @@
+package witness
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/agentlog"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/lock"
+	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// ThrashKind names a pattern of busy-but-unproductive agent behavior.
+// Inactivity-based detection (zombies, stalls) cannot see these: the agent
+// produces output constantly, it just never gets anywhere.
+type ThrashKind string
+
+const (
+	// ThrashToolLoop: the same tool call with the same input, over and over
+	// with no other tool call in between (e.g. re-running one failing test
+	// without touching the code).
+	ThrashToolLoop ThrashKind = "tool-loop"
+
+	// ThrashRepeatedError: tool results keep reporting the same error.
+	ThrashRepeatedError ThrashKind = "repeated-error"
+
+	// ThrashEditRevert: edits that undo an earlier edit to the same file.
+	ThrashEditRevert ThrashKind = "edit-revert"
+)
+
+// thrashEvidenceMax caps the length of the repeated call or error quoted in
+// findings, nudges and receipts.
+const thrashEvidenceMax = 120
+
+// ThrashThresholds are the repeat counts at which each pattern is reported.
+// A zero or negative threshold disables that pattern.
+type ThrashThresholds struct {
+	ToolRepeats  int
+	ErrorRepeats int
+	EditReverts  int
+}
+
+// ThrashFinding is one detected pattern with the evidence behind it.
+type ThrashFinding struct {
+	Kind     ThrashKind `json:"kind"`
+	Count    int        `json:"count"`
+	Evidence string     `json:"evidence"` // the repeated call, error line, or reverted file
+	First    time.Time  `json:"first"`
+	Last     time.Time  `json:"last"`
+}
+
+// String describes the finding for nudges, escalations and receipts.
+func (f ThrashFinding) String() string {
+	switch f.Kind {
+	case ThrashToolLoop:
+		return fmt.Sprintf("ran %q %d times", f.Evidence, f.Count)
+	case ThrashRepeatedError:
+		return fmt.Sprintf("hit %q %d times", f.Evidence, f.Count)
+	case ThrashEditRevert:
+		return fmt.Sprintf("reverted its own edit to %s %d times", f.Evidence, f.Count)
+	default:
+		return fmt.Sprintf("%s: %s (%d)", f.Kind, f.Evidence, f.Count)
+	}
+}
+
+// thrashCounter accumulates occurrences of one key.
+type thrashCounter struct {
+	evidence    string
+	count       int
+	first, last time.Time
+}
+
+func (c *thrashCounter) add(at time.Time) {
+	if c.count == 0 || at.Before(c.first) {
+		c.first = at
+	}
+	if at.After(c.last) {
+		c.last = at
+	}
+	c.count++
+}
+
+// thrashEdit is a file edit extracted from a tool call.
+type thrashEdit struct {
+	file, old, new string
+}
+
+// AnalyzeThrash looks for tool-call loops, repeated identical errors and
+// edit/revert cycles in events, which should be one agent's recent log.
+// A tool loop is a run of identical calls: any other tool call in between
+// (an edit, a read) ends the run, so re-running a test after each fix is not
+// a loop. Each call is reported with its longest run. Findings are ordered
+// by kind, then by count (highest first).
+func AnalyzeThrash(events []agentlog.AgentEvent, th ThrashThresholds) []ThrashFinding {
+	tools := make(map[string]*thrashCounter)
+	errs := make(map[string]*thrashCounter)
+	reverts := make(map[string]*thrashCounter)
+	var edits []thrashEdit
+
+	// run is the current run of identical tool calls.
+	var run *thrashCounter
+	endRun := func() {
+		if run == nil {
+			return
+		}
+		if best := tools[run.evidence]; best == nil || run.count > best.count {
+			tools[run.evidence] = run
+		}
+		run = nil
+	}
+
+	count := func(m map[string]*thrashCounter, key, evidence string, at time.Time) {
+		c, ok := m[key]
+		if !ok {
+			c = &thrashCounter{evidence: evidence}
+			m[key] = c
+		}
+		c.add(at)
+	}
+
+	for _, ev := range events {
+		switch ev.EventType {
+		case "tool_use":
+			call := collapseSpace(ev.Content)
+			if call == "" {
+				continue
+			}
+			if run == nil || run.evidence != call {
+				endRun()
+				run = &thrashCounter{evidence: call}
+			}
+			run.add(ev.Timestamp)
+			if e, ok := parseThrashEdit(ev.Content); ok {
+				for _, prev := range edits {
+					if prev.file == e.file && prev.old == e.new && prev.new == e.old {
+						count(reverts, e.file, e.file, ev.Timestamp)
+						break
+					}
+				}
+				edits = append(edits, e)
+			}
+		case "tool_result":
+			if sig, ok := errorSignature(ev.Content); ok {
+				count(errs, sig, sig, ev.Timestamp)
+			}
+		}
+	}
+	endRun()
+
+	var findings []ThrashFinding
+	findings = appendThrashFindings(findings, ThrashToolLoop, tools, th.ToolRepeats)
+	findings = appendThrashFindings(findings, ThrashRepeatedError, errs, th.ErrorRepeats)
+	findings = appendThrashFindings(findings, ThrashEditRevert, reverts, th.EditReverts)
+	return findings
+}
+
+func appendThrashFindings(findings []ThrashFinding, kind ThrashKind, counters map[string]*thrashCounter, threshold int) []ThrashFinding {
+	if threshold <= 0 {
+		return findings
+	}
+	var found []ThrashFinding
+	for _, c := range counters {
+		if c.count < threshold {
+			continue
+		}
+		found = append(found, ThrashFinding{
+			Kind:     kind,
+			Count:    c.count,
+			Evidence: truncateEvidence(c.evidence),
+			First:    c.first,
+			Last:     c.last,
+		})
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Count != found[j].Count {
+			return found[i].Count > found[j].Count
+		}
+		return found[i].Evidence < found[j].Evidence
+	})
+	return append(findings, found...)
+}
+
+// parseThrashEdit extracts a string-replacement edit from a tool call of the
+// form "Name: {json input}". Claude Code's Edit tool uses file_path,
+// old_string and new_string; Copilot's str_replace uses path, old_str and
+// new_str. Other tools are not edits.
+func parseThrashEdit(content string) (thrashEdit, bool) {
+	_, input, ok := strings.Cut(content, ": ")
+	if !ok || !strings.HasPrefix(input, "{") {
+		return thrashEdit{}, false
+	}
+	var in struct {
+		FilePath  string `json:"file_path"`
+		Path      string `json:"path"`
+		OldString string `json:"old_string"`
+		NewString string `json:"new_string"`
+		OldStr    string `json:"old_str"`
+		NewStr    string `json:"new_str"`
+	}
+	if err := json.Unmarshal([]byte(input), &in); err != nil {
+		return thrashEdit{}, false
+	}
+	e := thrashEdit{file: in.FilePath, old: in.OldString, new: in.NewString}
+	if e.file == "" {
+		e.file = in.Path
+	}
+	if e.old == "" && e.new == "" {
+		e.old, e.new = in.OldStr, in.NewStr
+	}
+	if e.file == "" || e.old == e.new {
+		return thrashEdit{}, false
+	}
+	return e, true
+}
+
+var (
+	errorLineRe = regexp.MustCompile(`(?i)\b(error|fail(ed|ure)?|panic|exception|traceback|fatal)\b`)
+	exitCodeRe  = regexp.MustCompile(`(?i)^exit (code|status) \d+$`)
+	digitsRe    = regexp.MustCompile(`\d+`)
+)
+
+// errorSignature returns the first error-looking line of a tool result with
+// numbers normalized, so reruns that differ only in durations, line numbers
+// or PIDs compare equal. A bare "Exit code N" line says nothing about which
+// error occurred and is skipped.
+func errorSignature(content string) (string, bool) {
+	for _, line := range strings.Split(content, "\n") {
+		line = collapseSpace(line)
+		if line == "" || exitCodeRe.MatchString(line) || !errorLineRe.MatchString(line) {
+			continue
+		}
+		return digitsRe.ReplaceAllString(line, "N"), true
+	}
+	return "", false
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func truncateEvidence(s string) string {
+	if len(s) <= thrashEvidenceMax {
+		return s
+	}
+	return s[:thrashEvidenceMax-3] + "..."
+}
+
+// ThrashResult is a polecat whose recent activity shows thrashing.
+type ThrashResult struct {
+	PolecatName string
+	Findings    []ThrashFinding
+	Action      string // "nudged", "escalated", "already-escalated"
+	Error       error
+}
+
+// DetectThrashingPolecatsResult contains the results of thrash detection.
+type DetectThrashingPolecatsResult struct {
+	Checked   int
+	Thrashing []ThrashResult
+	Errors    []error
+}
+
+// thrashMu serializes in-process access to the thrash state file.
+var thrashMu sync.Mutex
+
+// thrashRecord remembers the last thrash nudge sent to a session so a loop
+// that continues after the nudge is escalated instead of nudged again.
+type thrashRecord struct {
+	Fingerprint string    `json:"fingerprint"`
+	NudgedAt    time.Time `json:"nudged_at"`
+	Escalated   bool      `json:"escalated,omitempty"`
+}
+
+// thrashState holds thrash records for all sessions.
+type thrashState struct {
+	Sessions    map[string]*thrashRecord `json:"sessions"`
+	LastUpdated time.Time                `json:"last_updated"`
+}
+
+func thrashStateFile(townRoot string) string {
+	return filepath.Join(townRoot, "witness", "thrash-nudges.json")
+}
+
+func loadThrashState(townRoot string) *thrashState {
+	data, err := os.ReadFile(thrashStateFile(townRoot)) //nolint:gosec // G304: path from trusted townRoot
+	if err != nil {
+		return &thrashState{Sessions: make(map[string]*thrashRecord)}
+	}
+	var state thrashState
+	if err := json.Unmarshal(data, &state); err != nil || state.Sessions == nil {
+		return &thrashState{Sessions: make(map[string]*thrashRecord)}
+	}
+	return &state
+}
+
+func saveThrashState(townRoot string, state *thrashState) error {
+	stateFile := thrashStateFile(townRoot)
+	if err := os.MkdirAll(filepath.Dir(stateFile), 0755); err != nil {
+		return fmt.Errorf("creating witness dir: %w", err)
+	}
+	state.LastUpdated = time.Now().UTC()
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling thrash state: %w", err)
+	}
+	return os.WriteFile(stateFile, data, 0600)
+}
+
+// thrashFingerprint identifies what a polecat is thrashing on, ignoring how
+// many times it has happened so far: one "kind|evidence" line per finding.
+func thrashFingerprint(findings []ThrashFinding) string {
+	keys := make([]string, len(findings))
+	for i, f := range findings {
+		keys[i] = string(f.Kind) + "|" + f.Evidence
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "\n")
+}
+
+// thrashOverlaps reports whether any of findings is part of fingerprint, so
+// a loop that keeps going while new findings appear beside it is still the
+// loop that was nudged.
+func thrashOverlaps(fingerprint string, findings []ThrashFinding) bool {
+	if fingerprint == "" {
+		return false
+	}
+	nudged := make(map[string]bool)
+	for _, key := range strings.Split(fingerprint, "\n") {
+		nudged[key] = true
+	}
+	for _, f := range findings {
+		if nudged[string(f.Kind)+"|"+f.Evidence] {
+			return true
+		}
+	}
+	return false
+}
+
+// nextThrashAction decides what to do about a thrashing session given the
+// record of the last nudge: nudge thrashing that shares nothing with what
+// was nudged (overlaps is false), escalate nudged thrashing that continued
+// after the nudge (persisted), and otherwise leave it alone. Returns
+// "nudge", "escalate", "already-escalated" or "wait".
+func nextThrashAction(rec *thrashRecord, overlaps, persisted bool) string {
+	switch {
+	case rec == nil || !overlaps:
+		return "nudge"
+	case rec.Escalated:
+		return "already-escalated"
+	case persisted:
+		return "escalate"
+	default:
+		return "wait" // nudged; give the agent a chance to change course
+	}
+}
+
+// DetectThrashingPolecats reads the recent agent log of each live polecat
+// and looks for tool-call loops, repeated identical errors and edit/revert
+// cycles (see AnalyzeThrash). These agents look healthy to zombie and stall
+// detection because they are busy; they are just not making progress.
+//
+// The first detection nudges the polecat with the evidence. If any of the
+// nudged thrashing continues after the nudge, the next patrol escalates it with
+// gt escalate. Nudge state lives in <town>/witness/thrash-nudges.json.
+//
+// Thresholds come from the witness operational config (thrash_window,
+// thrash_tool_repeats, thrash_error_repeats, thrash_edit_reverts). Agents
+// whose logs cannot be read incrementally (see agentlog.ReadsIncrementally)
+// are counted as checked but not analysed.
+func DetectThrashingPolecats(workDir, rigName string) *DetectThrashingPolecatsResult {
+	result := &DetectThrashingPolecatsResult{}
+
+	townRoot, err := workspace.Find(workDir)
+	if err != nil || townRoot == "" {
+		townRoot = workDir
+	}
+	initRegistryFromTownRoot(townRoot)
+
+	witCfg := config.LoadOperationalConfig(townRoot).GetWitnessConfig()
+	window := witCfg.ThrashWindowD()
+	if window <= 0 {
+		return result // Disabled
+	}
+	th := ThrashThresholds{
+		ToolRepeats:  witCfg.ThrashToolRepeatsV(),
+		ErrorRepeats: witCfg.ThrashErrorRepeatsV(),
+		EditReverts:  witCfg.ThrashEditRevertsV(),
+	}
+
+	polecatsDir := filepath.Join(townRoot, rigName, "polecats")
+	entries, err := os.ReadDir(polecatsDir)
+	if err != nil {
+		return result // No polecats directory
+	}
+
+	thrashMu.Lock()
+	defer thrashMu.Unlock()
+	unlock, flockErr := lock.FlockAcquire(thrashStateFile(townRoot) + ".flock")
+	if flockErr == nil {
+		defer unlock()
+	}
+	state := loadThrashState(townRoot)
+
+	t := session.NewBackend(townRoot)
+	now := time.Now()
+	live := make(map[string]bool)
+
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		polecatName := entry.Name()
+		sessionName := session.PolecatSessionName(session.PrefixFor(rigName), polecatName)
+		result.Checked++
+
+		sessionAlive, err := t.HasSession(sessionName)
+		if err != nil {
+			result.Errors = append(result.Errors,
+				fmt.Errorf("checking session %s: %w", sessionName, err))
+			continue
+		}
+		if !sessionAlive || !t.IsAgentAlive(sessionName) {
+			continue // Dead session or agent — zombie detection handles this
+		}
+		live[sessionName] = true
+
+		agentName, _ := t.GetEnvironment(sessionName, "GT_AGENT")
+		logFormat := config.GetLogFormat(strings.TrimSpace(agentName))
+		if logFormat == "" || !agentlog.ReadsIncrementally(logFormat) {
+			continue // Agent keeps no log we can read incrementally
+		}
+		agentDir, err := t.GetPaneWorkDir(sessionName)
+		if err != nil {
+			agentDir = filepath.Join(polecatsDir, polecatName, rigName)
+		}
+
+		// Only read what this session wrote: an earlier session in the same
+		// worktree may have thrashed on something that is long resolved.
+		since := now.Add(-window)
+		if created, err := t.GetSessionCreatedUnix(sessionName); err == nil {
+			if start := time.Unix(created, 0); start.After(since) {
+				since = start
+			}
+		}
+		events, err := agentlog.RecentEvents(logFormat, agentDir, since)
+		if err != nil {
+			result.Errors = append(result.Errors,
+				fmt.Errorf("reading agent log for %s: %w", sessionName, err))
+			continue
+		}
+
+		findings := AnalyzeThrash(events, th)
+		if len(findings) == 0 {
+			delete(state.Sessions, sessionName)
+			continue
+		}
+
+		// Persistence is judged against what was nudged, not the current
+		// findings: a loop that continues while a new error shows up beside
+		// it must still escalate rather than earn a fresh nudge.
+		rec := state.Sessions[sessionName]
+		overlaps, persisted := false, false
+		if rec != nil {
+			overlaps = thrashOverlaps(rec.Fingerprint, findings)
+			if overlaps {
+				persisted = thrashOverlaps(rec.Fingerprint, AnalyzeThrash(eventsAfter(events, rec.NudgedAt), th))
+			}
+		}
+
+		thrash := ThrashResult{PolecatName: polecatName, Findings: findings}
+		switch nextThrashAction(rec, overlaps, persisted) {
+		case "nudge":
+			thrash.Action = "nudged"
+			if err := t.NudgeSession(sessionName, thrashNudgeMessage(findings, window)); err != nil {
+				thrash.Error = fmt.Errorf("nudging %s: %w", sessionName, err)
+			} else {
+				state.Sessions[sessionName] = &thrashRecord{Fingerprint: thrashFingerprint(findings), NudgedAt: now.UTC()}
+			}
+		case "escalate":
+			thrash.Action = "escalated"
+			if err := escalateThrashing(townRoot, rigName, polecatName, findings, window); err != nil {
+				thrash.Error = err
+			} else {
+				rec.Escalated = true
+			}
+		case "already-escalated":
+			thrash.Action = "already-escalated"
+		default:
+			continue
+		}
+		result.Thrashing = append(result.Thrashing, thrash)
+	}
+
+	// Forget sessions of this rig that are gone so a new session with the
+	// same name starts clean.
+	prefix := session.PolecatSessionName(session.PrefixFor(rigName), "")
+	for name := range state.Sessions {
+		if strings.HasPrefix(name, prefix) && !live[name] {
+			delete(state.Sessions, name)
+		}
+	}
+	_ = saveThrashState(townRoot, state) // Non-fatal: worst case the next patrol nudges again
+
+	return result
+}
+
+// eventsAfter returns the events strictly after t.
+func eventsAfter(events []agentlog.AgentEvent, t time.Time) []agentlog.AgentEvent {
+	var after []agentlog.AgentEvent
+	for _, ev := range events {
+		if ev.Timestamp.After(t) {
+			after = append(after, ev)
+		}
+	}
+	return after
+}
+
+// thrashNudgeMessage tells a thrashing polecat what the witness saw.
+func thrashNudgeMessage(findings []ThrashFinding, window time.Duration) string {
+	var parts []string
+	for _, f := range findings {
+		parts = append(parts, f.String())
+	}
+	return fmt.Sprintf("THRASH_DETECTED: in the last %s you %s — this is not converging. Stop repeating it: re-read the error, try a different approach, or run 'gt escalate' if you are blocked.",
+		window, strings.Join(parts, "; "))
+}
+
+// escalateThrashing escalates a polecat that kept thrashing after a nudge.
+func escalateThrashing(townRoot, rigName, polecatName string, findings []ThrashFinding, window time.Duration) error {
+	var lines []string
+	for _, f := range findings {
+		lines = append(lines, fmt.Sprintf("- %s (%s – %s)", f.String(),
+			f.First.Format(time.RFC3339), f.Last.Format(time.RFC3339)))
+	}
+	reason := fmt.Sprintf("Still thrashing after a witness nudge. In the last %s:\n%s", window, strings.Join(lines, "\n"))
+	desc := fmt.Sprintf("Polecat %s/%s is thrashing: %s", rigName, polecatName, findings[0].String())
+
+	cmd := exec.Command("gt", "escalate", "--severity", "medium", "--source", "patrol:witness", "--reason", reason, desc) //nolint:gosec // G204: args are constructed internally
+	cmd.Dir = townRoot
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("escalating thrashing polecat %s: %w (%s)", polecatName, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package witness
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/agentlog"
+)
+
+var thrashStart = time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+// thrashLog builds a log of events one minute apart.
+func thrashLog(specs ...[2]string) []agentlog.AgentEvent {
+	events := make([]agentlog.AgentEvent, len(specs))
+	for i, s := range specs {
+		events[i] = agentlog.AgentEvent{
+			EventType: s[0],
+			Content:   s[1],
+			Timestamp: thrashStart.Add(time.Duration(i) * time.Minute),
+		}
+	}
+	return events
+}
+
+func repeatSpecs(n int, specs ...[2]string) [][2]string {
+	var out [][2]string
+	for i := 0; i < n; i++ {
+		out = append(out, specs...)
+	}
+	return out
+}
+
+var thrashDefaults = ThrashThresholds{ToolRepeats: 8, ErrorRepeats: 5, EditReverts: 2}
+
+func TestAnalyzeThrash_ToolLoopAndRepeatedError(t *testing.T) {
+	t.Parallel()
+	specs := repeatSpecs(8,
+		[2]string{"tool_use", `Bash: {"command":"go test ./internal/foo/"}`},
+		[2]string{"tool_result", "Exit code 1\n--- FAIL: TestFoo (0.02s)\n    foo_test.go:12: got 1, want 2\nFAIL"},
+	)
+	// Reruns that differ only in timing or whitespace are the same loop.
+	specs[2][1] = `Bash:  {"command":"go test ./internal/foo/"}`
+	specs[3][1] = "Exit code 1\n--- FAIL: TestFoo (1.37s)\nFAIL"
+	findings := AnalyzeThrash(thrashLog(specs...), thrashDefaults)
+
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want 2: %+v", len(findings), findings)
+	}
+	if f := findings[0]; f.Kind != ThrashToolLoop || f.Count != 8 || !strings.Contains(f.Evidence, "go test") {
+		t.Errorf("tool loop = %+v", f)
+	}
+	if f := findings[1]; f.Kind != ThrashRepeatedError || f.Count != 8 || f.Evidence != "--- FAIL: TestFoo (N.Ns)" {
+		t.Errorf("repeated error = %+v", f)
+	}
+	if f := findings[0]; !f.First.Equal(thrashStart) || !f.Last.Equal(thrashStart.Add(14*time.Minute)) {
+		t.Errorf("tool loop spans %v – %v", f.First, f.Last)
+	}
+}
+
+func TestAnalyzeThrash_ToolLoopNeedsConsecutiveCalls(t *testing.T) {
+	t.Parallel()
+	test := `Bash: {"command":"go test ./internal/foo/"}`
+	// Fixing the code between runs is progress, not a loop.
+	specs := repeatSpecs(10,
+		[2]string{"tool_use", test},
+		[2]string{"tool_result", "ok"},
+		[2]string{"tool_use", `Edit: {"file_path":"/w/foo.go","old_string":"a","new_string":"b"}`},
+	)
+	if findings := AnalyzeThrash(thrashLog(specs...), thrashDefaults); len(findings) != 0 {
+		t.Errorf("findings = %+v, want none", findings)
+	}
+
+	// The longest unbroken run is what counts.
+	specs = append(repeatSpecs(3, [2]string{"tool_use", test}),
+		[2]string{"tool_use", `Read: {"file_path":"/w/foo.go"}`})
+	specs = append(specs, repeatSpecs(9, [2]string{"tool_use", test})...)
+	findings := AnalyzeThrash(thrashLog(specs...), thrashDefaults)
+	if len(findings) != 1 || findings[0].Count != 9 || !findings[0].First.Equal(thrashStart.Add(4*time.Minute)) {
+		t.Errorf("findings = %+v, want one 9-call run from minute 4", findings)
+	}
+}
+
+func TestAnalyzeThrash_BelowThresholds(t *testing.T) {
+	t.Parallel()
+	specs := repeatSpecs(4,
+		[2]string{"tool_use", `Bash: {"command":"go build ./..."}`},
+		[2]string{"tool_result", "Exit code 2\nerror: cannot find package"},
+		[2]string{"tool_result", "ok"},
+	)
+	if findings := AnalyzeThrash(thrashLog(specs...), thrashDefaults); len(findings) != 0 {
+		t.Errorf("findings = %+v, want none", findings)
+	}
+	// Disabled thresholds never report.
+	specs = repeatSpecs(20, [2]string{"tool_use", `Bash: {"command":"make"}`})
+	if findings := AnalyzeThrash(thrashLog(specs...), ThrashThresholds{}); len(findings) != 0 {
+		t.Errorf("disabled: findings = %+v, want none", findings)
+	}
+}
+
+func TestAnalyzeThrash_EditRevert(t *testing.T) {
+	t.Parallel()
+	forward := `Edit: {"file_path":"/w/foo.go","old_string":"x := 1","new_string":"x := 2"}`
+	back := `Edit: {"file_path":"/w/foo.go","old_string":"x := 2","new_string":"x := 1"}`
+	other := `str_replace: {"path":"/w/bar.go","old_str":"a","new_str":"b"}`
+	findings := AnalyzeThrash(thrashLog(
+		[2]string{"tool_use", forward},
+		[2]string{"tool_use", back},
+		[2]string{"tool_use", other},
+		[2]string{"tool_use", forward},
+		[2]string{"tool_use", back},
+	), thrashDefaults)
+
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1: %+v", len(findings), findings)
+	}
+	// The second forward edit undoes the first revert, so it counts too.
+	if f := findings[0]; f.Kind != ThrashEditRevert || f.Evidence != "/w/foo.go" || f.Count != 3 {
+		t.Errorf("edit/revert = %+v", f)
+	}
+}
+
+func TestErrorSignature(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in     string
+		want   string
+		wantOK bool
+	}{
+		{"Exit code 1\npanic: runtime error: index out of range [3]", "panic: runtime error: index out of range [N]", true},
+		{"Exit code 1", "", false},
+		{"all tests passed", "", false},
+		{"   Error:   no such file  ", "Error: no such file", true},
+		{"errors.go compiled", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := errorSignature(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("errorSignature(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestNextThrashAction(t *testing.T) {
+	t.Parallel()
+	nudged := &thrashRecord{Fingerprint: "fp", NudgedAt: thrashStart}
+	escalated := &thrashRecord{Fingerprint: "fp", NudgedAt: thrashStart, Escalated: true}
+	tests := []struct {
+		name      string
+		rec       *thrashRecord
+		overlaps  bool
+		persisted bool
+		want      string
+	}{
+		{"first detection", nil, false, false, "nudge"},
+		{"different thrashing", nudged, false, true, "nudge"},
+		{"nudged, not repeated since", nudged, true, false, "wait"},
+		{"nudged, still going", nudged, true, true, "escalate"},
+		{"escalated", escalated, true, true, "already-escalated"},
+	}
+	for _, tt := range tests {
+		if got := nextThrashAction(tt.rec, tt.overlaps, tt.persisted); got != tt.want {
+			t.Errorf("%s: nextThrashAction = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestThrashOverlaps(t *testing.T) {
+	t.Parallel()
+	loop := ThrashFinding{Kind: ThrashToolLoop, Evidence: "Bash: make test"}
+	newErr := ThrashFinding{Kind: ThrashRepeatedError, Evidence: "Error: boom"}
+	fp := thrashFingerprint([]ThrashFinding{loop})
+
+	// A new finding beside the nudged loop must not reset persistence.
+	if !thrashOverlaps(fp, []ThrashFinding{loop, newErr}) {
+		t.Error("nudged loop plus a new error should overlap")
+	}
+	if thrashOverlaps(fp, []ThrashFinding{newErr}) {
+		t.Error("unrelated finding should not overlap")
+	}
+	if thrashOverlaps("", []ThrashFinding{loop}) {
+		t.Error("empty fingerprint should not overlap")
+	}
+}
+
+func TestThrashNudgeMessage(t *testing.T) {
+	t.Parallel()
+	msg := thrashNudgeMessage([]ThrashFinding{
+		{Kind: ThrashToolLoop, Count: 9, Evidence: "Bash: make test"},
+		{Kind: ThrashEditRevert, Count: 2, Evidence: "main.go"},
+	}, 30*time.Minute)
+	for _, want := range []string{"THRASH_DETECTED", "30m0s", `ran "Bash: make test" 9 times`, "reverted its own edit to main.go 2 times"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("nudge %q missing %q", msg, want)
+		}
+	}
+}